@@ -4,7 +4,10 @@ export function setupUploadButton() {
 
   let client = new Uppy().use(XHRUpload, {
     endpoint: '/browser/upload',
-    allowMultipleUploadBatches: true
+    allowMultipleUploadBatches: true,
+    getResponseError(responseText, response) {
+      return new Error(responseText || 'Upload error')
+    },
   })
 
   const folderPath = document.getElementById("folder-path-meta")
@@ -14,19 +17,32 @@ export function setupUploadButton() {
   client.setMeta({ spaceID: spaceID.value })
   client.use(StatusBar, { target: '#status-bar' });
 
+  keepModificationTime(client)
+
   client.on('complete', (result) => {
     htmx.trigger("body", "refreshFolder");
   });
 
-  document.getElementById('upload-file-btn').
+  setupFileButton(client)
+
+  document.getElementById('upload-folder-btn').
     addEventListener("click", (e) => {
       var input = document.createElement('input');
       input.type = 'file';
       input.multiple = true
+      input.webkitdirectory = true
+      input.mozdirectory = true
+      input.directory = true
 
       input.onchange = e => {
         for (const file of e.target.files) {
-          client.addFile(file)
+          client.addFile({
+            name: file.webkitRelativePath,
+            data: file,
+            type: file.type,
+            source: 'Local',
+            isRemote: false,
+          })
         }
 
         client.upload()
@@ -34,25 +50,48 @@ export function setupUploadButton() {
 
       input.click();
     })
+}
 
-  document.getElementById('upload-folder-btn').
+// setupShareUploadButton sets the upload button of an upload only share
+// link. The files are sent to the url set inside the "upload-url-meta" input.
+export function setupShareUploadButton() {
+  const uploadURL = document.getElementById("upload-url-meta")
+
+  let client = new Uppy().use(XHRUpload, {
+    endpoint: uploadURL.value,
+    allowMultipleUploadBatches: true,
+    getResponseError(responseText, response) {
+      return new Error(responseText || 'Upload error')
+    },
+  })
+
+  client.use(StatusBar, { target: '#status-bar' });
+
+  keepModificationTime(client)
+
+  setupFileButton(client)
+}
+
+// keepModificationTime sends the modification time of each file so the
+// server keeps it instead of the upload time.
+function keepModificationTime(client) {
+  client.on('file-added', (file) => {
+    if (file.data && file.data.lastModified) {
+      client.setFileMeta(file.id, { lastModified: file.data.lastModified })
+    }
+  })
+}
+
+function setupFileButton(client) {
+  document.getElementById('upload-file-btn').
     addEventListener("click", (e) => {
       var input = document.createElement('input');
       input.type = 'file';
       input.multiple = true
-      input.webkitdirectory = true
-      input.mozdirectory = true
-      input.directory = true
 
       input.onchange = e => {
         for (const file of e.target.files) {
-          client.addFile({
-            name: file.webkitRelativePath,
-            data: file,
-            type: file.type,
-            source: 'Local',
-            isRemote: false,
-          })
+          client.addFile(file)
         }
 
         client.upload()
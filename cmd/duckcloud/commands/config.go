@@ -23,6 +23,8 @@ import (
 	"github.com/spf13/viper"
 	"github.com/theduckcompany/duckcloud/assets"
 	"github.com/theduckcompany/duckcloud/internal/server"
+	"github.com/theduckcompany/duckcloud/internal/service/dav"
+	"github.com/theduckcompany/duckcloud/internal/service/dav/webdav"
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/logger"
 	"github.com/theduckcompany/duckcloud/internal/tools/response"
@@ -32,8 +34,9 @@ import (
 )
 
 var (
-	ErrConflictTLSConfig = errors.New("can't use --self-signed-cert and --tls-key at the same time")
-	ErrDevFlagRequire    = errors.New("this flag require the --dev flag setup")
+	ErrConflictTLSConfig    = errors.New("can't use --self-signed-cert and --tls-key at the same time")
+	ErrDevFlagRequire       = errors.New("this flag require the --dev flag setup")
+	ErrInvalidInfiniteDepth = errors.New(`must be "allow", "refuse" or a positive number`)
 )
 
 type Config struct {
@@ -49,6 +52,7 @@ type Config struct {
 	Debug          bool     `mapstructure:"debug"`
 	Dev            bool     `mapstructure:"dev"`
 	HotReload      bool     `mapstructure:"hot-reload"`
+	InfiniteDepth  string   `mapstructure:"webdav-infinite-depth"`
 }
 
 func NewConfigFromCmd(cmd *cobra.Command) (server.Config, error) {
@@ -88,6 +92,11 @@ func NewConfigFromCmd(cmd *cobra.Command) (server.Config, error) {
 		logLevel = slog.LevelDebug
 	}
 
+	propfindCfg, err := parseInfiniteDepth(cfg.InfiniteDepth)
+	if err != nil {
+		return server.Config{}, fmt.Errorf("--webdav-infinite-depth: %w", err)
+	}
+
 	var fs afero.Fs
 	var storagePath string
 	if cfg.MemoryFS {
@@ -146,9 +155,28 @@ func NewConfigFromCmd(cmd *cobra.Command) (server.Config, error) {
 			PrettyRender: cfg.Dev,
 			HotReload:    cfg.HotReload,
 		},
+		DAV: dav.Config{
+			Propfind: propfindCfg,
+		},
 	}, nil
 }
 
+func parseInfiniteDepth(value string) (webdav.PropfindConfig, error) {
+	switch strings.ToLower(value) {
+	case "", "allow":
+		return webdav.PropfindConfig{}, nil
+	case "refuse":
+		return webdav.PropfindConfig{RefuseInfiniteDepth: true}, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return webdav.PropfindConfig{}, ErrInvalidInfiniteDepth
+	}
+
+	return webdav.PropfindConfig{InfiniteDepthLimit: limit}, nil
+}
+
 func generateSelfSignedCertificate(hostnames []string, folderPath string, fs afero.Fs) (string, string, error) {
 	sslfolder := path.Join(folderPath, "ssl")
 	certificatePath := path.Join(sslfolder, "cert.pem")
@@ -68,5 +68,7 @@ func NewRunCmd(_ string) *cobra.Command {
 	flags.Int("http-port", 5764, "Web server port number.")
 	flags.IP("http-host", net.IPv4(0, 0, 0, 0), "Web server IP address")
 
+	flags.String("webdav-infinite-depth", "allow", `WebDAV PROPFIND with "Depth: infinity" ("allow", "refuse" or the max number of resources listed)`)
+
 	return &cmd
 }
@@ -168,4 +168,16 @@ func Test_NewRunCmd(t *testing.T) {
 
 		require.EqualError(t, err, ErrConflictTLSConfig.Error())
 	})
+
+	t.Run("with an invalid --webdav-infinite-depth should failed", func(t *testing.T) {
+		cmd := NewRunCmd("duckcloud-test")
+
+		cmd.SetErr(io.Discard)
+		cmd.SetOut(io.Discard)
+
+		cmd.SetArgs([]string{"--webdav-infinite-depth=-3", "--memory-fs", "--dev", "--folder=/foobar"})
+		err := cmd.Execute()
+
+		require.ErrorIs(t, err, ErrInvalidInfiniteDepth)
+	})
 }
@@ -0,0 +1 @@
+ALTER TABLE spaces ADD COLUMN "trash_retention" INTEGER NOT NULL DEFAULT 2592000;
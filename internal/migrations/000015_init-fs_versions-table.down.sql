@@ -0,0 +1,8 @@
+ALTER TABLE spaces DROP COLUMN "versions_retention";
+ALTER TABLE spaces DROP COLUMN "max_versions";
+
+DROP TABLE IF EXISTS fs_versions;
+
+DROP INDEX IF EXISTS idx_fs_versions_id;
+DROP INDEX IF EXISTS idx_fs_versions_inode_id;
+DROP INDEX IF EXISTS idx_fs_versions_file_id;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS fs_versions (
+  "id" TEXT NOT NULL,
+  "inode_id" TEXT NOT NULL,
+  "file_id" TEXT NOT NULL,
+  "size" INTEGER NOT NULL,
+  "modified_at" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL,
+  "created_by" TEXT NOT NULL,
+  FOREIGN KEY(inode_id) REFERENCES fs_inodes(id) ON UPDATE RESTRICT ON DELETE RESTRICT,
+  FOREIGN KEY(file_id) REFERENCES files(id) ON UPDATE RESTRICT ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fs_versions_id ON fs_versions(id);
+CREATE INDEX IF NOT EXISTS idx_fs_versions_inode_id ON fs_versions(inode_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_fs_versions_file_id ON fs_versions(file_id);
+
+ALTER TABLE spaces ADD COLUMN "max_versions" INTEGER NOT NULL DEFAULT 10;
+ALTER TABLE spaces ADD COLUMN "versions_retention" INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE spaces DROP COLUMN "quota";
@@ -0,0 +1 @@
+ALTER TABLE spaces ADD COLUMN "quota" INTEGER NOT NULL DEFAULT 0;
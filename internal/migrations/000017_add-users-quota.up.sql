@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN "quota" INTEGER NOT NULL DEFAULT 0;
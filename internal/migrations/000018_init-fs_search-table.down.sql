@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS fs_search_content_ai;
+DROP TRIGGER IF EXISTS fs_search_content_au;
+DROP TRIGGER IF EXISTS fs_search_content_bd;
+DROP TRIGGER IF EXISTS fs_search_content_bu;
+DROP TABLE IF EXISTS fs_search;
+DROP TABLE IF EXISTS fs_search_content;
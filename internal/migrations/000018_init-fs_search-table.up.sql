@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS fs_search_content (
+  "docid" INTEGER PRIMARY KEY,
+  "inode_id" TEXT NOT NULL,
+  "space_id" TEXT NOT NULL,
+  "name" TEXT NOT NULL,
+  "path" TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fs_search_content_inode_id ON fs_search_content(inode_id);
+CREATE INDEX IF NOT EXISTS idx_fs_search_content_space_id_path ON fs_search_content(space_id, path);
+
+-- FTS5 is only compiled by the sqlite driver with the "sqlite_fts5" build tag.
+CREATE VIRTUAL TABLE IF NOT EXISTS fs_search USING fts5(name, path, content="fs_search_content", content_rowid="docid", tokenize="unicode61");
+
+CREATE TRIGGER IF NOT EXISTS fs_search_content_bu BEFORE UPDATE ON fs_search_content BEGIN
+  INSERT INTO fs_search(fs_search, rowid, name, path) VALUES('delete', old.docid, old.name, old.path);
+END;
+
+CREATE TRIGGER IF NOT EXISTS fs_search_content_bd BEFORE DELETE ON fs_search_content BEGIN
+  INSERT INTO fs_search(fs_search, rowid, name, path) VALUES('delete', old.docid, old.name, old.path);
+END;
+
+CREATE TRIGGER IF NOT EXISTS fs_search_content_au AFTER UPDATE ON fs_search_content BEGIN
+  INSERT INTO fs_search(rowid, name, path) VALUES(new.docid, new.name, new.path);
+END;
+
+CREATE TRIGGER IF NOT EXISTS fs_search_content_ai AFTER INSERT ON fs_search_content BEGIN
+  INSERT INTO fs_search(rowid, name, path) VALUES(new.docid, new.name, new.path);
+END;
+
+-- Index all the existing inodes, the roots excepted.
+INSERT INTO fs_search_content(inode_id, space_id, name, path)
+WITH RECURSIVE tree(id, space_id, name, path) AS (
+  SELECT id, space_id, name, '' FROM fs_inodes WHERE parent IS NULL AND deleted_at IS NULL
+  UNION ALL
+  SELECT c.id, c.space_id, c.name, t.path || '/' || c.name
+    FROM fs_inodes c JOIN tree t ON c.parent = t.id
+    WHERE c.deleted_at IS NULL
+)
+SELECT id, space_id, name, path FROM tree WHERE path != '';
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS fs_search_text_content_ai;
+DROP TRIGGER IF EXISTS fs_search_text_content_au;
+DROP TRIGGER IF EXISTS fs_search_text_content_bd;
+DROP TRIGGER IF EXISTS fs_search_text_content_bu;
+DROP TABLE IF EXISTS fs_search_text;
+DROP TABLE IF EXISTS fs_search_text_content;
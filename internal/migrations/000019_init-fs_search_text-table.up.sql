@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS fs_search_text_content (
+  "docid" INTEGER PRIMARY KEY,
+  "file_id" TEXT NOT NULL,
+  "content" TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fs_search_text_content_file_id ON fs_search_text_content(file_id);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS fs_search_text USING fts5(content, content="fs_search_text_content", content_rowid="docid", tokenize="unicode61");
+
+CREATE TRIGGER IF NOT EXISTS fs_search_text_content_bu BEFORE UPDATE ON fs_search_text_content BEGIN
+  INSERT INTO fs_search_text(fs_search_text, rowid, content) VALUES('delete', old.docid, old.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS fs_search_text_content_bd BEFORE DELETE ON fs_search_text_content BEGIN
+  INSERT INTO fs_search_text(fs_search_text, rowid, content) VALUES('delete', old.docid, old.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS fs_search_text_content_au AFTER UPDATE ON fs_search_text_content BEGIN
+  INSERT INTO fs_search_text(rowid, content) VALUES(new.docid, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS fs_search_text_content_ai AFTER INSERT ON fs_search_text_content BEGIN
+  INSERT INTO fs_search_text(rowid, content) VALUES(new.docid, new.content);
+END;
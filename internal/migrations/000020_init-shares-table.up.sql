@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS shares (
+  "id" TEXT NOT NULL,
+  "token" TEXT NOT NULL,
+  "space_id" TEXT NOT NULL,
+  "inode_id" TEXT NOT NULL,
+  "password" TEXT NOT NULL,
+  "max_downloads" INTEGER NOT NULL,
+  "downloads" INTEGER NOT NULL,
+  "expires_at" TEXT DEFAULT NULL,
+  "created_at" TEXT NOT NULL,
+  "created_by" TEXT NOT NULL,
+  FOREIGN KEY(space_id) REFERENCES spaces(id) ON UPDATE RESTRICT ON DELETE RESTRICT,
+  FOREIGN KEY(created_by) REFERENCES users(id) ON UPDATE RESTRICT ON DELETE RESTRICT
+) STRICT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shares_id ON shares(id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shares_token ON shares(token);
+CREATE INDEX IF NOT EXISTS idx_shares_inode_id ON shares(inode_id);
+CREATE INDEX IF NOT EXISTS idx_shares_created_by ON shares(created_by);
@@ -0,0 +1,5 @@
+ALTER TABLE shares DROP COLUMN "uploaded_size";
+ALTER TABLE shares DROP COLUMN "max_upload_size";
+ALTER TABLE shares DROP COLUMN "uploads";
+ALTER TABLE shares DROP COLUMN "max_uploads";
+ALTER TABLE shares DROP COLUMN "kind";
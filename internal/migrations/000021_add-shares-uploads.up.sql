@@ -0,0 +1,5 @@
+ALTER TABLE shares ADD COLUMN "kind" TEXT NOT NULL DEFAULT 'download';
+ALTER TABLE shares ADD COLUMN "max_uploads" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE shares ADD COLUMN "uploads" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE shares ADD COLUMN "max_upload_size" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE shares ADD COLUMN "uploaded_size" INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+ALTER TABLE spaces ADD COLUMN "owners" TEXT NOT NULL DEFAULT '';
+
+UPDATE spaces SET owners = COALESCE((SELECT group_concat(user_id) FROM space_members WHERE space_members.space_id = spaces.id), '');
+
+DROP TABLE IF EXISTS space_members;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS space_members (
+  "space_id" TEXT NOT NULL,
+  "user_id" TEXT NOT NULL,
+  "role" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL,
+  "created_by" TEXT NOT NULL,
+  FOREIGN KEY(space_id) REFERENCES spaces(id) ON UPDATE RESTRICT ON DELETE RESTRICT,
+  FOREIGN KEY(user_id) REFERENCES users(id) ON UPDATE RESTRICT ON DELETE RESTRICT
+) STRICT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_space_members_space_id_user_id ON space_members(space_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_space_members_user_id ON space_members(user_id);
+
+-- The previous owners had every rights on their spaces so they become managers.
+WITH RECURSIVE split(space_id, created_at, created_by, user_id, rest) AS (
+  SELECT id, created_at, created_by, '', owners || ',' FROM spaces
+  UNION ALL
+  SELECT space_id, created_at, created_by, substr(rest, 1, instr(rest, ',') - 1), substr(rest, instr(rest, ',') + 1)
+  FROM split WHERE rest <> ''
+)
+INSERT OR IGNORE INTO space_members (space_id, user_id, role, created_at, created_by)
+SELECT space_id, user_id, 'manager', created_at, created_by FROM split
+WHERE user_id IN (SELECT id FROM users);
+
+ALTER TABLE spaces DROP COLUMN "owners";
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS fs_grants (
+  "id" TEXT NOT NULL,
+  "space_id" TEXT NOT NULL,
+  "inode_id" TEXT NOT NULL,
+  "user_id" TEXT NOT NULL,
+  "permission" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL,
+  "created_by" TEXT NOT NULL,
+  FOREIGN KEY(inode_id) REFERENCES fs_inodes(id) ON UPDATE RESTRICT ON DELETE RESTRICT,
+  FOREIGN KEY(user_id) REFERENCES users(id) ON UPDATE RESTRICT ON DELETE RESTRICT
+) STRICT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fs_grants_id ON fs_grants(id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fs_grants_inode_id_user_id ON fs_grants(inode_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_fs_grants_user_id ON fs_grants(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_fs_grants_space_id ON fs_grants(space_id);
@@ -0,0 +1 @@
+ALTER TABLE dav_sessions DROP COLUMN "grant_id";
@@ -0,0 +1 @@
+ALTER TABLE dav_sessions ADD COLUMN "grant_id" TEXT DEFAULT NULL;
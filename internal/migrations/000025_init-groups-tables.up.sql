@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS groups (
+  "id" TEXT NOT NULL,
+  "name" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL,
+  "created_by" TEXT NOT NULL
+) STRICT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_id ON groups(id);
+
+CREATE TABLE IF NOT EXISTS group_members (
+  "group_id" TEXT NOT NULL,
+  "user_id" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL,
+  "created_by" TEXT NOT NULL,
+  FOREIGN KEY(group_id) REFERENCES groups(id) ON UPDATE RESTRICT ON DELETE RESTRICT,
+  FOREIGN KEY(user_id) REFERENCES users(id) ON UPDATE RESTRICT ON DELETE RESTRICT
+) STRICT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_members_group_id_user_id ON group_members(group_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_group_members_user_id ON group_members(user_id);
+
+CREATE TABLE IF NOT EXISTS space_groups (
+  "space_id" TEXT NOT NULL,
+  "group_id" TEXT NOT NULL,
+  "role" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL,
+  "created_by" TEXT NOT NULL,
+  FOREIGN KEY(space_id) REFERENCES spaces(id) ON UPDATE RESTRICT ON DELETE RESTRICT,
+  FOREIGN KEY(group_id) REFERENCES groups(id) ON UPDATE RESTRICT ON DELETE RESTRICT
+) STRICT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_space_groups_space_id_group_id ON space_groups(space_id, group_id);
+CREATE INDEX IF NOT EXISTS idx_space_groups_group_id ON space_groups(group_id);
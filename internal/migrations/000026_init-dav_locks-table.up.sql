@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS dav_locks (
+  "token" TEXT NOT NULL,
+  "space_id" TEXT NOT NULL,
+  "path" TEXT NOT NULL,
+  "scope" TEXT NOT NULL,
+  "depth" TEXT NOT NULL,
+  "owner" TEXT NOT NULL,
+  "timeout" INTEGER NOT NULL,
+  "expires_at" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL,
+  "created_by" TEXT NOT NULL,
+  FOREIGN KEY(created_by) REFERENCES users(id) ON UPDATE RESTRICT ON DELETE RESTRICT
+) STRICT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dav_locks_token ON dav_locks(token);
+CREATE INDEX IF NOT EXISTS idx_dav_locks_space_id ON dav_locks(space_id);
+CREATE INDEX IF NOT EXISTS idx_dav_locks_created_by ON dav_locks(created_by);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS fs_props (
+  "inode_id" TEXT NOT NULL,
+  "namespace" TEXT NOT NULL,
+  "name" TEXT NOT NULL,
+  "lang" TEXT NOT NULL,
+  "value" TEXT NOT NULL,
+  FOREIGN KEY(inode_id) REFERENCES fs_inodes(id) ON UPDATE RESTRICT ON DELETE RESTRICT
+) STRICT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fs_props_inode_id_namespace_name ON fs_props(inode_id, namespace, name);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS dav_sessions_old (
+  "id" TEXT NOT NULL,
+  "username" TEXT NOT NULL,
+  "name" TEXT NOT NULL,
+  "password" TEXT NOT NULL,
+  "user_id" TEXT NOT NULL,
+  "space_id" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL,
+  "grant_id" TEXT DEFAULT NULL,
+  FOREIGN KEY(user_id) REFERENCES users(id) ON UPDATE RESTRICT ON DELETE RESTRICT
+  FOREIGN KEY(space_id) REFERENCES spaces(id) ON UPDATE RESTRICT ON DELETE RESTRICT
+) STRICT;
+
+-- The sessions giving an access to all the spaces can't be kept.
+INSERT INTO dav_sessions_old (id, username, name, password, user_id, space_id, created_at, grant_id)
+  SELECT id, username, name, password, user_id, space_id, created_at, grant_id FROM dav_sessions WHERE all_spaces = 0;
+
+DROP TABLE dav_sessions;
+
+ALTER TABLE dav_sessions_old RENAME TO dav_sessions;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dav_sessions_id ON dav_sessions(id);
+CREATE INDEX IF NOT EXISTS idx_dav_sessions_user_id ON dav_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_dav_sessions_username_password ON dav_sessions(username, password);
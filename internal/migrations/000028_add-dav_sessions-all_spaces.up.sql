@@ -0,0 +1,26 @@
+-- SQLite can't remove the NOT NULL constraint of space_id so the table is
+-- rebuilt. The sessions giving an access to all the spaces have no space_id.
+CREATE TABLE IF NOT EXISTS dav_sessions_new (
+  "id" TEXT NOT NULL,
+  "username" TEXT NOT NULL,
+  "name" TEXT NOT NULL,
+  "password" TEXT NOT NULL,
+  "user_id" TEXT NOT NULL,
+  "space_id" TEXT DEFAULT NULL,
+  "grant_id" TEXT DEFAULT NULL,
+  "all_spaces" INTEGER NOT NULL DEFAULT 0,
+  "created_at" TEXT NOT NULL,
+  FOREIGN KEY(user_id) REFERENCES users(id) ON UPDATE RESTRICT ON DELETE RESTRICT
+  FOREIGN KEY(space_id) REFERENCES spaces(id) ON UPDATE RESTRICT ON DELETE RESTRICT
+) STRICT;
+
+INSERT INTO dav_sessions_new (id, username, name, password, user_id, space_id, grant_id, created_at)
+  SELECT id, username, name, password, user_id, space_id, grant_id, created_at FROM dav_sessions;
+
+DROP TABLE dav_sessions;
+
+ALTER TABLE dav_sessions_new RENAME TO dav_sessions;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dav_sessions_id ON dav_sessions(id);
+CREATE INDEX IF NOT EXISTS idx_dav_sessions_user_id ON dav_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_dav_sessions_username_password ON dav_sessions(username, password);
@@ -0,0 +1,5 @@
+ALTER TABLE dav_sessions DROP COLUMN "last_used_ip";
+ALTER TABLE dav_sessions DROP COLUMN "last_used_at";
+ALTER TABLE dav_sessions DROP COLUMN "expires_at";
+ALTER TABLE dav_sessions DROP COLUMN "root_path";
+ALTER TABLE dav_sessions DROP COLUMN "read_only";
@@ -0,0 +1,5 @@
+ALTER TABLE dav_sessions ADD COLUMN "read_only" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE dav_sessions ADD COLUMN "root_path" TEXT NOT NULL DEFAULT '/';
+ALTER TABLE dav_sessions ADD COLUMN "expires_at" TEXT DEFAULT NULL;
+ALTER TABLE dav_sessions ADD COLUMN "last_used_at" TEXT DEFAULT NULL;
+ALTER TABLE dav_sessions ADD COLUMN "last_used_ip" TEXT DEFAULT NULL;
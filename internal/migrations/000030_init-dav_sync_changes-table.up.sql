@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS dav_sync_changes (
+  "token" INTEGER PRIMARY KEY,
+  "collection_id" TEXT NOT NULL,
+  "name" TEXT NOT NULL,
+  "deleted" INTEGER NOT NULL,
+  "changed_at" TEXT NOT NULL
+) STRICT;
+
+CREATE INDEX IF NOT EXISTS idx_dav_sync_changes_collection_id ON dav_sync_changes(collection_id, token);
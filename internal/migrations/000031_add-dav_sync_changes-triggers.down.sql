@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_dav_sync_changes_changed_at;
+DROP TRIGGER IF EXISTS dav_sync_changes_fs_inodes_au;
+DROP TRIGGER IF EXISTS dav_sync_changes_fs_inodes_ai;
@@ -0,0 +1,27 @@
+-- The changes of the files are recorded whatever the way they are made:
+-- CardDAV, CalDAV, WebDAV, the browser or the tasks. The collection is the
+-- parent directory of the file.
+CREATE TRIGGER IF NOT EXISTS dav_sync_changes_fs_inodes_ai AFTER INSERT ON fs_inodes
+WHEN new.file_id IS NOT NULL AND new.parent IS NOT NULL AND new.deleted_at IS NULL BEGIN
+  INSERT INTO dav_sync_changes(collection_id, name, deleted, changed_at)
+    VALUES(new.parent, new.name, 0, strftime('%Y-%m-%dT%H:%M:%fZ', 'now'));
+END;
+
+-- A file moved, renamed or trashed is deleted from its previous collection.
+-- A file moved, renamed, restored or modified is changed inside its new
+-- collection. The deletion is recorded first.
+CREATE TRIGGER IF NOT EXISTS dav_sync_changes_fs_inodes_au AFTER UPDATE ON fs_inodes
+WHEN new.file_id IS NOT NULL OR old.file_id IS NOT NULL BEGIN
+  INSERT INTO dav_sync_changes(collection_id, name, deleted, changed_at)
+    SELECT old.parent, old.name, 1, strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+    WHERE old.file_id IS NOT NULL AND old.parent IS NOT NULL AND old.deleted_at IS NULL
+      AND (new.deleted_at IS NOT NULL OR new.parent IS NOT old.parent OR new.name IS NOT old.name);
+
+  INSERT INTO dav_sync_changes(collection_id, name, deleted, changed_at)
+    SELECT new.parent, new.name, 0, strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+    WHERE new.file_id IS NOT NULL AND new.parent IS NOT NULL AND new.deleted_at IS NULL
+      AND (old.deleted_at IS NOT NULL OR new.parent IS NOT old.parent OR new.name IS NOT old.name
+        OR new.file_id IS NOT old.file_id OR new.last_modified_at IS NOT old.last_modified_at);
+END;
+
+CREATE INDEX IF NOT EXISTS idx_dav_sync_changes_changed_at ON dav_sync_changes(changed_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_fs_inodes_created_by;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_fs_inodes_created_by ON fs_inodes(created_by);
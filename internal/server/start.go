@@ -13,15 +13,19 @@ import (
 	"github.com/theduckcompany/duckcloud/internal/migrations"
 	"github.com/theduckcompany/duckcloud/internal/service/config"
 	"github.com/theduckcompany/duckcloud/internal/service/dav"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/davsync"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/groups"
 	"github.com/theduckcompany/duckcloud/internal/service/masterkey"
 	"github.com/theduckcompany/duckcloud/internal/service/oauth2"
 	"github.com/theduckcompany/duckcloud/internal/service/oauthclients"
 	"github.com/theduckcompany/duckcloud/internal/service/oauthcodes"
 	"github.com/theduckcompany/duckcloud/internal/service/oauthconsents"
 	"github.com/theduckcompany/duckcloud/internal/service/oauthsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/stats"
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/runner"
@@ -55,6 +59,7 @@ type Config struct {
 	Listener router.Config
 	HTML     html.Config
 	Assets   assets.Config
+	DAV      dav.Config
 }
 
 // AsRoute annotates the given constructor to state that
@@ -112,7 +117,12 @@ func start(ctx context.Context, cfg Config, invoke fx.Option) *fx.App {
 			fx.Annotate(websessions.Init, fx.As(new(websessions.Service))),
 			fx.Annotate(oauth2.Init, fx.As(new(oauth2.Service))),
 			fx.Annotate(davsessions.Init, fx.As(new(davsessions.Service))),
+			fx.Annotate(davlocks.Init, fx.As(new(davlocks.Service))),
+			davsync.Init,
+			fx.Annotate(shares.Init, fx.As(new(shares.Service))),
+			func(svc shares.Service) dfs.SpaceMover { return svc },
 			fx.Annotate(spaces.Init, fx.As(new(spaces.Service))),
+			fx.Annotate(groups.Init, fx.As(new(groups.Service))),
 			fx.Annotate(scheduler.Init, fx.As(new(scheduler.Service))),
 			fx.Annotate(stats.Init, fx.As(new(stats.Service))),
 			fx.Annotate(masterkey.Init, fx.As(new(masterkey.Service))),
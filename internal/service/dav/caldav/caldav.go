@@ -0,0 +1,133 @@
+// Package caldav provides a CalDAV server (RFC 4791) storing the calendar
+// objects as iCalendar files.
+//
+// The calendars are the folders inside the "Calendars" folder of the
+// session space, each event or task being a ".ics" file inside them. The
+// sync tokens track the changes made by any client, CalDAV or not.
+package caldav
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dav/internal/davcol"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
+	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/davsync"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+)
+
+const (
+	// HomeFolder is the folder containing the calendars, at the root of
+	// the session space.
+	HomeFolder = "Calendars"
+	// DefaultCalendar is created inside an empty home folder.
+	DefaultCalendar = "Personal"
+)
+
+type Handler struct {
+	// FileSystem stores the calendar objects.
+	FileSystem dfs.Service
+	// Sessions handle the users sessions used for authentification.
+	Sessions davsessions.Service
+	Spaces   spaces.Service
+	Users    users.Service
+	// Sync returns the changes of the calendars for the sync-collection
+	// reports.
+	Sync davsync.Service
+	// Locks are the WebDAV locks honoured by the writes.
+	Locks davlocks.Service
+	// Logger is an optional error logger. If non-nil, it will be called
+	// for all HTTP requests.
+	Logger func(*http.Request, error)
+	// Prefix is the URL path prefix of the home.
+	Prefix string
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv := davcol.Server{
+		FileSystem: h.FileSystem,
+		Sessions:   h.Sessions,
+		Spaces:     h.Spaces,
+		Users:      h.Users,
+		Sync:       h.Sync,
+		Locks:      h.Locks,
+		Logger:     h.Logger,
+		Prefix:     h.Prefix,
+		Collection: calendars{},
+	}
+
+	srv.ServeHTTP(w, r)
+}
+
+var config = davcol.Config{
+	Realm:             "calendars",
+	HomeFolder:        HomeFolder,
+	DefaultCollection: DefaultCalendar,
+	Namespace:         nsCalDAV,
+	Class:             "calendar-access",
+	MkcolMethod:       "MKCALENDAR",
+	ContentType:       "text/calendar; charset=utf-8",
+	MediaTypes:        []string{"text/calendar"},
+	ResourceType:      "<C:calendar/>",
+	HomeSet:           "calendar-home-set",
+	DataProp:          "calendar-data",
+	SupportedData:     "supported-calendar-data",
+	ValidData:         "valid-calendar-data",
+	Reports:           []string{"calendar-query", "calendar-multiget"},
+	Props: []davcol.Prop{
+		{
+			Name:  xml.Name{Space: nsCalDAV, Local: "supported-calendar-data"},
+			Inner: `<C:calendar-data content-type="text/calendar" version="2.0"/>`,
+		},
+		{
+			Name:  xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"},
+			Inner: supportedComponentSet(),
+		},
+	},
+}
+
+func supportedComponentSet() string {
+	var buf strings.Builder
+	for _, comp := range supportedComponents {
+		buf.WriteString(`<C:comp name="` + comp + `"/>`)
+	}
+
+	return buf.String()
+}
+
+// calendars are the collections of the CalDAV server.
+type calendars struct{}
+
+func (calendars) Config() *davcol.Config { return &config }
+
+func (calendars) Validate(content []byte) error {
+	cal, err := parseCalendar(content)
+	if err == nil {
+		_, err = cal.objectType()
+	}
+	if errors.Is(err, errUnsupportedComponent) {
+		return &davcol.ConditionError{Name: xml.Name{Space: nsCalDAV, Local: "supported-calendar-component"}, Err: err}
+	}
+
+	return err
+}
+
+func (calendars) UID(content []byte) string {
+	cal, err := parseCalendar(content)
+	if err != nil {
+		return ""
+	}
+
+	return cal.uid()
+}
+
+// Data returns the whole calendar object. The calendar-data content,
+// asking for a subset of the components, is ignored.
+func (calendars) Data(content []byte, _ *davcol.PropNames) []byte {
+	return content
+}
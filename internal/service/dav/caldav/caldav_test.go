@@ -0,0 +1,271 @@
+package caldav
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/davsync"
+	"github.com/theduckcompany/duckcloud/internal/tools/startutils"
+)
+
+const shoppingTask = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Calendar//EN\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:shopping@example.com\r\n" +
+	"DTSTAMP:20240101T090000Z\r\n" +
+	"DUE:20240220T180000Z\r\n" +
+	"SUMMARY:Shopping\r\n" +
+	"END:VTODO\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestCalDAV(t *testing.T) {
+	ctx := context.Background()
+
+	serv := startutils.NewServer(t)
+
+	userSpaces, err := serv.SpacesSvc.GetAllUserSpaces(ctx, serv.User.ID(), nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, userSpaces)
+	space := userSpaces[0]
+
+	h := &Handler{
+		Prefix:     "/caldav",
+		FileSystem: serv.DFSSvc,
+		Sessions:   serv.DavSessionsSvc,
+		Spaces:     serv.SpacesSvc,
+		Users:      serv.UsersSvc,
+		Sync:       davsync.Init(serv.Tools, serv.DB).Service,
+		Locks:      serv.DavLocksSvc,
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	_, token, err := serv.DavSessionsSvc.Create(ctx, &davsessions.CreateCmd{
+		Name:     "calendars",
+		Username: serv.User.Username(),
+		UserID:   serv.User.ID(),
+		SpaceID:  space.ID(),
+	})
+	require.NoError(t, err)
+
+	_, readOnlyToken, err := serv.DavSessionsSvc.Create(ctx, &davsessions.CreateCmd{
+		Name:     "read only calendars",
+		Username: serv.User.Username(),
+		UserID:   serv.User.ID(),
+		SpaceID:  space.ID(),
+		ReadOnly: true,
+	})
+	require.NoError(t, err)
+
+	do := func(password, method, name, content string, headers ...string) (*http.Response, string) {
+		req, err := http.NewRequest(method, srv.URL+name, strings.NewReader(content))
+		require.NoError(t, err)
+		req.SetBasicAuth(serv.User.Username(), password)
+
+		for len(headers) >= 2 {
+			req.Header.Add(headers[0], headers[1])
+			headers = headers[2:]
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		return res, string(body)
+	}
+
+	hrefs := func(body string) []string {
+		res := []string{}
+		for _, match := range regexp.MustCompile(`<D:href>([^<]*)</D:href>`).FindAllStringSubmatch(body, -1) {
+			res = append(res, match[1])
+		}
+		return res
+	}
+
+	syncToken := func(body string) string {
+		match := regexp.MustCompile(`<D:sync-token>([^<]*)</D:sync-token>`).FindStringSubmatch(body)
+		require.Len(t, match, 2, body)
+		return match[1]
+	}
+
+	calendarQuery := func(compFilter string) string {
+		return `<?xml version="1.0"?>` +
+			`<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">` +
+			`<D:prop><D:getetag/><C:calendar-data/></D:prop>` +
+			`<C:filter><C:comp-filter name="VCALENDAR">` + compFilter + `</C:comp-filter></C:filter>` +
+			`</C:calendar-query>`
+	}
+
+	t.Run("the home is created with a default calendar", func(t *testing.T) {
+		res, body := do(token, "PROPFIND", "/caldav/", `<?xml version="1.0"?>`+
+			`<D:propfind xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><D:prop>`+
+			`<D:current-user-principal/><C:calendar-home-set/><D:resourcetype/><C:supported-calendar-component-set/>`+
+			`</D:prop></D:propfind>`, "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode, body)
+
+		assert.Contains(t, body, "<D:current-user-principal><D:href>/caldav/</D:href></D:current-user-principal>")
+		assert.Contains(t, body, "<C:calendar-home-set><D:href>/caldav/</D:href></C:calendar-home-set>")
+		assert.Contains(t, body, "<D:resourcetype><D:collection/><C:calendar/></D:resourcetype>")
+		assert.Contains(t, body, `<C:supported-calendar-component-set><C:comp name="VEVENT"/><C:comp name="VTODO"/><C:comp name="VJOURNAL"/></C:supported-calendar-component-set>`)
+		assert.Contains(t, body, "<D:href>/caldav/Personal/</D:href>")
+	})
+
+	t.Run("OPTIONS advertises the calendar access", func(t *testing.T) {
+		res, _ := do(token, "OPTIONS", "/caldav/Personal/", "")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "1, 3, calendar-access", res.Header.Get("DAV"))
+		assert.Contains(t, res.Header.Get("Allow"), "MKCALENDAR")
+	})
+
+	t.Run("MKCALENDAR", func(t *testing.T) {
+		res, _ := do(token, "MKCALENDAR", "/caldav/Family/", `<?xml version="1.0"?>`+
+			`<C:mkcalendar xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">`+
+			`<D:set><D:prop><D:displayname>Family</D:displayname></D:prop></D:set></C:mkcalendar>`)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		res, body := do(token, "MKCALENDAR", "/caldav/Family/", "")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Contains(t, body, "<D:resource-must-be-null/>")
+
+		res, _ = do(readOnlyToken, "MKCALENDAR", "/caldav/Work/", "")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("PUT and GET an event", func(t *testing.T) {
+		res, _ := do(token, "PUT", "/caldav/Family/meeting.ics", meetingEvent, "Content-Type", "text/calendar", "If-None-Match", "*")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		etag := res.Header.Get("ETag")
+		require.NotEmpty(t, etag)
+
+		res, body := do(token, "GET", "/caldav/Family/meeting.ics", "")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, meetingEvent, body)
+		assert.Equal(t, etag, res.Header.Get("ETag"))
+		assert.Equal(t, "text/calendar; charset=utf-8", res.Header.Get("Content-Type"))
+
+		res, _ = do(token, "PUT", "/caldav/Family/meeting.ics", meetingEvent, "If-None-Match", "*")
+		require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+		res, _ = do(token, "PUT", "/caldav/Family/meeting.ics", meetingEvent, "If-Match", etag)
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		res, _ = do(token, "PUT", "/caldav/Family/shopping.ics", shoppingTask)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+	})
+
+	t.Run("PUT an invalid calendar object", func(t *testing.T) {
+		res, body := do(token, "PUT", "/caldav/Family/invalid.ics", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:foo\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Contains(t, body, "<C:valid-calendar-data/>")
+
+		res, body = do(token, "PUT", "/caldav/Family/invalid.ics", meetingEvent, "Content-Type", "text/vcard")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Contains(t, body, "<C:supported-calendar-data/>")
+
+		res, _ = do(token, "PUT", "/caldav/Unknown/meeting.ics", meetingEvent)
+		require.Equal(t, http.StatusConflict, res.StatusCode)
+	})
+
+	t.Run("REPORT calendar-query with a time range", func(t *testing.T) {
+		res, body := do(token, "REPORT", "/caldav/Family/", calendarQuery(
+			`<C:comp-filter name="VEVENT"><C:time-range start="20240115T000000Z" end="20240116T000000Z"/></C:comp-filter>`,
+		), "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+
+		assert.Equal(t, []string{"/caldav/Family/meeting.ics"}, hrefs(body))
+		assert.Contains(t, body, "SUMMARY:Family meet")
+
+		res, body = do(token, "REPORT", "/caldav/Family/", calendarQuery(
+			`<C:comp-filter name="VEVENT"><C:time-range start="20240201T000000Z" end="20240301T000000Z"/></C:comp-filter>`,
+		), "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		assert.Empty(t, hrefs(body))
+	})
+
+	t.Run("REPORT calendar-query for the tasks", func(t *testing.T) {
+		res, body := do(token, "REPORT", "/caldav/Family/", calendarQuery(`<C:comp-filter name="VTODO"/>`), "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+
+		assert.Equal(t, []string{"/caldav/Family/shopping.ics"}, hrefs(body))
+	})
+
+	t.Run("REPORT calendar-query with an invalid filter", func(t *testing.T) {
+		res, body := do(token, "REPORT", "/caldav/Family/", calendarQuery(
+			`<C:comp-filter name="VEVENT"><C:time-range start="yesterday"/></C:comp-filter>`,
+		), "Depth", "1")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Contains(t, body, "<C:valid-filter/>")
+	})
+
+	t.Run("REPORT calendar-multiget", func(t *testing.T) {
+		res, body := do(token, "REPORT", "/caldav/Family/", `<?xml version="1.0"?>`+
+			`<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">`+
+			`<D:prop><D:getetag/><C:calendar-data/></D:prop>`+
+			`<D:href>/caldav/Family/shopping.ics</D:href>`+
+			`<D:href>/caldav/Family/unknown.ics</D:href>`+
+			`</C:calendar-multiget>`, "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+
+		assert.Equal(t, []string{"/caldav/Family/shopping.ics", "/caldav/Family/unknown.ics"}, hrefs(body))
+		assert.Contains(t, body, "SUMMARY:Shopping")
+		assert.Contains(t, body, "<D:status>HTTP/1.1 404 Not Found</D:status>")
+	})
+
+	t.Run("REPORT sync-collection", func(t *testing.T) {
+		syncReport := func(token string) string {
+			return `<?xml version="1.0"?><D:sync-collection xmlns:D="DAV:">` +
+				`<D:sync-token>` + token + `</D:sync-token><D:sync-level>1</D:sync-level>` +
+				`<D:prop><D:getetag/></D:prop></D:sync-collection>`
+		}
+
+		res, body := do(token, "REPORT", "/caldav/Family/", syncReport(""))
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		assert.Equal(t, []string{"/caldav/Family/meeting.ics", "/caldav/Family/shopping.ics"}, hrefs(body))
+		firstToken := syncToken(body)
+
+		res, body = do(token, "REPORT", "/caldav/Family/", syncReport(firstToken))
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		assert.Empty(t, hrefs(body))
+		assert.Equal(t, firstToken, syncToken(body))
+
+		res, _ = do(token, "DELETE", "/caldav/Family/shopping.ics", "")
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		res, body = do(token, "REPORT", "/caldav/Family/", syncReport(firstToken))
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		assert.Equal(t, []string{"/caldav/Family/shopping.ics"}, hrefs(body))
+		assert.Contains(t, body, "<D:status>HTTP/1.1 404 Not Found</D:status>")
+		assert.NotEqual(t, firstToken, syncToken(body))
+	})
+
+	t.Run("PUT a calendar object with an UID already used", func(t *testing.T) {
+		res, body := do(token, "PUT", "/caldav/Family/other.ics", meetingEvent)
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Contains(t, body, "<C:no-uid-conflict><D:href>/caldav/Family/meeting.ics</D:href></C:no-uid-conflict>")
+	})
+
+	t.Run("the read only sessions can't write", func(t *testing.T) {
+		res, _ := do(readOnlyToken, "PUT", "/caldav/Family/other.ics", shoppingTask)
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		res, _ = do(readOnlyToken, "GET", "/caldav/Family/meeting.ics", "")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		res, _ := do("invalid", "PROPFIND", "/caldav/", "", "Depth", "0")
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
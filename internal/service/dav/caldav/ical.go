@@ -0,0 +1,566 @@
+package caldav
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errInvalidCalendar      = errors.New("caldav: invalid iCalendar")
+	errUnsupportedComponent = errors.New("caldav: unsupported calendar component")
+	errUnsupportedCollation = errors.New("caldav: unsupported collation")
+	errInvalidFilter        = errors.New("caldav: invalid filter")
+)
+
+// supportedComponents are the component types accepted inside the calendars.
+var supportedComponents = []string{"VEVENT", "VTODO", "VJOURNAL"}
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405"
+	utcFormat      = "20060102T150405Z"
+)
+
+var durationRegexp = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// component is an iCalendar component (RFC 5545) with its properties and its
+// sub-components. The values are kept as is, only the names and the
+// parameters are parsed for the filters.
+type component struct {
+	name     string
+	props    []icalProp
+	children []*component
+}
+
+type icalProp struct {
+	// name is the upper-cased property name.
+	name   string
+	params map[string][]string
+	value  string
+}
+
+// parseCalendar parses a single VCALENDAR object.
+func parseCalendar(data []byte) (*component, error) {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	lines := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	var root *component
+	stack := []*component{}
+	for _, line := range lines {
+		prop, err := parseContentLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			if len(stack) == 0 && root != nil {
+				// Only one VCALENDAR is accepted by resource.
+				return nil, errInvalidCalendar
+			}
+
+			comp := &component{name: strings.ToUpper(prop.value)}
+			if len(stack) == 0 {
+				root = comp
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, comp)
+			}
+			stack = append(stack, comp)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].name != strings.ToUpper(prop.value) {
+				return nil, errInvalidCalendar
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, errInvalidCalendar
+			}
+			stack[len(stack)-1].props = append(stack[len(stack)-1].props, *prop)
+		}
+	}
+
+	if root == nil || len(stack) > 0 || root.name != "VCALENDAR" {
+		return nil, errInvalidCalendar
+	}
+
+	return root, nil
+}
+
+// parseContentLine parses a "name;param=value:value" line. The colons and
+// semicolons inside the quoted parameter values are ignored.
+func parseContentLine(line string) (*icalProp, error) {
+	inQuotes := false
+	fields := []string{}
+	start := 0
+	valueIdx := -1
+	for i, c := range line {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ';' && !inQuotes:
+			fields = append(fields, line[start:i])
+			start = i + 1
+		case c == ':' && !inQuotes:
+			valueIdx = i
+		}
+
+		if valueIdx != -1 {
+			break
+		}
+	}
+
+	if valueIdx == -1 {
+		return nil, errInvalidCalendar
+	}
+
+	fields = append(fields, line[start:valueIdx])
+
+	if fields[0] == "" {
+		return nil, errInvalidCalendar
+	}
+
+	params := map[string][]string{}
+	for _, param := range fields[1:] {
+		key, values, found := strings.Cut(param, "=")
+		if !found {
+			return nil, errInvalidCalendar
+		}
+
+		key = strings.ToUpper(key)
+		for _, value := range strings.Split(values, ",") {
+			params[key] = append(params[key], strings.Trim(value, `"`))
+		}
+	}
+
+	return &icalProp{
+		name:   strings.ToUpper(fields[0]),
+		params: params,
+		value:  line[valueIdx+1:],
+	}, nil
+}
+
+// objectType checks the calendar object resource restrictions (RFC 4791
+// section 4.1) and returns the type of its components: all the components,
+// except the time zones, must have the same type and the same UID.
+func (c *component) objectType() (string, error) {
+	var compType, uid string
+	for _, child := range c.children {
+		if child.name == "VTIMEZONE" {
+			continue
+		}
+
+		childUID, ok := child.get("UID")
+		if !ok || childUID.value == "" {
+			return "", errInvalidCalendar
+		}
+
+		if compType == "" {
+			compType, uid = child.name, childUID.value
+		}
+
+		if child.name != compType || childUID.value != uid {
+			return "", errInvalidCalendar
+		}
+	}
+
+	if compType == "" {
+		return "", errInvalidCalendar
+	}
+
+	for _, supported := range supportedComponents {
+		if compType == supported {
+			return compType, nil
+		}
+	}
+
+	return "", errUnsupportedComponent
+}
+
+// uid returns the UID shared by the components of a calendar object, the
+// time zones excepted. It returns an empty string if there is none.
+func (c *component) uid() string {
+	for _, child := range c.children {
+		if child.name == "VTIMEZONE" {
+			continue
+		}
+
+		if childUID, ok := child.get("UID"); ok {
+			return childUID.value
+		}
+	}
+
+	return ""
+}
+
+// get returns the first property with the given name.
+func (c *component) get(name string) (*icalProp, bool) {
+	for i := range c.props {
+		if c.props[i].name == name {
+			return &c.props[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// match returns true if the calendar object matches the filter of a
+// calendar-query report (RFC 4791 section 9.7). All the conditions of a
+// filter must match.
+func (c *component) match(f *filter) (bool, error) {
+	return matchCompFilter([]*component{c}, &f.CompFilter)
+}
+
+// matchCompFilter returns true if one of the components with the filter
+// name matches the filter.
+func matchCompFilter(comps []*component, f *compFilter) (bool, error) {
+	candidates := []*component{}
+	for _, comp := range comps {
+		if comp.name == strings.ToUpper(f.Name) {
+			candidates = append(candidates, comp)
+		}
+	}
+
+	if f.IsNotDefined != nil {
+		return len(candidates) == 0, nil
+	}
+
+	for _, comp := range candidates {
+		ok, err := comp.matchComp(f)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
+
+func (c *component) matchComp(f *compFilter) (bool, error) {
+	if f.TimeRange != nil {
+		start, end, err := f.TimeRange.parse()
+		if err != nil {
+			return false, err
+		}
+
+		if !c.overlaps(start, end) {
+			return false, nil
+		}
+	}
+
+	for _, propFilter := range f.PropFilters {
+		ok, err := c.matchProp(&propFilter)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	for _, compFilter := range f.CompFilters {
+		ok, err := matchCompFilter(c.children, &compFilter)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (c *component) matchProp(f *propFilter) (bool, error) {
+	props := []*icalProp{}
+	for i := range c.props {
+		if c.props[i].name == strings.ToUpper(f.Name) {
+			props = append(props, &c.props[i])
+		}
+	}
+
+	if f.IsNotDefined != nil {
+		return len(props) == 0, nil
+	}
+
+	// The filter matches if one of the instances of the property matches.
+	for _, prop := range props {
+		ok, err := prop.match(f)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
+
+func (p *icalProp) match(f *propFilter) (bool, error) {
+	if f.TimeRange != nil {
+		start, end, err := f.TimeRange.parse()
+		if err != nil {
+			return false, err
+		}
+
+		t, _, err := p.time()
+		if err != nil || !inRange(t, t, start, end) {
+			return false, nil
+		}
+	}
+
+	if f.TextMatch != nil {
+		ok, err := f.TextMatch.match(p.value)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	for _, paramFilter := range f.ParamFilters {
+		ok, err := p.matchParam(&paramFilter)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (p *icalProp) matchParam(f *paramFilter) (bool, error) {
+	values, ok := p.params[strings.ToUpper(f.Name)]
+
+	switch {
+	case f.IsNotDefined != nil:
+		return !ok, nil
+	case !ok:
+		return false, nil
+	case f.TextMatch == nil:
+		return true, nil
+	}
+
+	for _, value := range values {
+		ok, err := f.TextMatch.match(value)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
+
+// overlaps returns true if the component overlaps the time range following
+// the rules of RFC 4791 section 9.9. A zero start or end leaves the range
+// open on this side.
+//
+// The recurrence rules are not expanded: a recurring component matches
+// every range ending after its first occurrence.
+func (c *component) overlaps(start, end time.Time) bool {
+	var compStart, compEnd time.Time
+	var isDate bool
+	var err error
+
+	dtstart, hasStart := c.get("DTSTART")
+	if hasStart {
+		compStart, isDate, err = dtstart.time()
+		if err != nil {
+			return false
+		}
+	}
+
+	switch c.name {
+	case "VEVENT":
+		if !hasStart {
+			return false
+		}
+
+		compEnd = compStart
+		if dtend, ok := c.get("DTEND"); ok {
+			compEnd, _, err = dtend.time()
+		} else if duration, ok := c.get("DURATION"); ok {
+			var d time.Duration
+			d, err = parseDuration(duration.value)
+			compEnd = compStart.Add(d)
+		} else if isDate {
+			compEnd = compStart.AddDate(0, 0, 1)
+		}
+	case "VTODO":
+		due, hasDue := c.get("DUE")
+		switch {
+		case hasStart && hasDue:
+			compEnd, _, err = due.time()
+		case hasStart:
+			compEnd = compStart
+			if duration, ok := c.get("DURATION"); ok {
+				var d time.Duration
+				d, err = parseDuration(duration.value)
+				compEnd = compStart.Add(d)
+			}
+		case hasDue:
+			compStart, _, err = due.time()
+			compEnd = compStart
+		default:
+			// A task without any date matches all the ranges.
+			return true
+		}
+	case "VJOURNAL":
+		if !hasStart {
+			return false
+		}
+
+		compEnd = compStart
+		if isDate {
+			compEnd = compStart.AddDate(0, 0, 1)
+		}
+	default:
+		return true
+	}
+
+	if err != nil {
+		return false
+	}
+
+	if _, ok := c.get("RRULE"); ok {
+		compEnd = time.Time{}
+	} else if _, ok := c.get("RDATE"); ok {
+		compEnd = time.Time{}
+	}
+
+	return inRange(compStart, compEnd, start, end)
+}
+
+// inRange returns true if the [compStart, compEnd] period overlaps the
+// [start, end] range. A zero compEnd means a period without end.
+func inRange(compStart, compEnd, start, end time.Time) bool {
+	if !end.IsZero() && !compStart.Before(end) {
+		return false
+	}
+
+	switch {
+	case start.IsZero():
+		return true
+	case compEnd.IsZero():
+		return true
+	case compEnd.Equal(compStart):
+		// The instants match if they are inside the range.
+		return !compStart.Before(start)
+	default:
+		return compEnd.After(start)
+	}
+}
+
+func (r *timeRange) parse() (time.Time, time.Time, error) {
+	var start, end time.Time
+	var err error
+
+	if r.Start != "" {
+		start, err = time.Parse(utcFormat, r.Start)
+		if err != nil {
+			return start, end, errInvalidFilter
+		}
+	}
+
+	if r.End != "" {
+		end, err = time.Parse(utcFormat, r.End)
+		if err != nil {
+			return start, end, errInvalidFilter
+		}
+	}
+
+	return start, end, nil
+}
+
+// time parses a DATE or a DATE-TIME value. It returns true for the DATE
+// values. The floating times and the unknown time zones are read as UTC.
+func (p *icalProp) time() (time.Time, bool, error) {
+	if values := p.params["VALUE"]; (len(values) > 0 && strings.EqualFold(values[0], "DATE")) || len(p.value) == len(dateFormat) {
+		t, err := time.Parse(dateFormat, p.value)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(p.value, "Z") {
+		t, err := time.Parse(utcFormat, p.value)
+		return t, false, err
+	}
+
+	loc := time.UTC
+	if tzids := p.params["TZID"]; len(tzids) > 0 {
+		tzLoc, err := time.LoadLocation(strings.TrimPrefix(tzids[0], "/"))
+		if err == nil {
+			loc = tzLoc
+		}
+	}
+
+	t, err := time.ParseInLocation(dateTimeFormat, p.value, loc)
+	return t, false, err
+}
+
+// parseDuration parses an iCalendar duration (RFC 5545 section 3.3.6).
+func parseDuration(s string) (time.Duration, error) {
+	matches := durationRegexp.FindStringSubmatch(s)
+	if matches == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, errInvalidCalendar
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	var res time.Duration
+	for i, unit := range units {
+		if matches[i+2] == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(matches[i+2])
+		if err != nil {
+			return 0, errInvalidCalendar
+		}
+
+		res += time.Duration(n) * unit
+	}
+
+	if matches[1] == "-" {
+		res = -res
+	}
+
+	return res, nil
+}
+
+// match applies the text-match to the given value with its collation
+// (RFC 4791 section 9.7.5). The values match if they contain the text.
+func (t *textMatch) match(value string) (bool, error) {
+	expected := t.Value
+
+	switch t.Collation {
+	case "", "i;ascii-casemap":
+		value, expected = asciiLower(value), asciiLower(expected)
+	case "i;unicode-casemap":
+		value, expected = strings.ToLower(value), strings.ToLower(expected)
+	case "i;octet":
+	default:
+		return false, errUnsupportedCollation
+	}
+
+	res := strings.Contains(value, expected)
+	if t.NegateCondition == "yes" {
+		return !res, nil
+	}
+
+	return res, nil
+}
+
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+
+		return r
+	}, s)
+}
@@ -0,0 +1,239 @@
+package caldav
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const meetingEvent = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Calendar//EN\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Europe/Paris\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:meeting@example.com\r\n" +
+	"DTSTAMP:20240101T090000Z\r\n" +
+	"DTSTART;TZID=Europe/Paris:20240115T100000\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"SUMMARY:Family meet\r\n" +
+	" ing\r\n" +
+	"ATTENDEE;CN=\"Doe; John\":mailto:john@example.com\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"TRIGGER:-PT15M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseCalendar(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		cal, err := parseCalendar([]byte(meetingEvent))
+		require.NoError(t, err)
+
+		require.Len(t, cal.children, 2)
+		event := cal.children[1]
+		assert.Equal(t, "VEVENT", event.name)
+
+		summary, ok := event.get("SUMMARY")
+		require.True(t, ok)
+		assert.Equal(t, "Family meeting", summary.value)
+
+		attendee, ok := event.get("ATTENDEE")
+		require.True(t, ok)
+		assert.Equal(t, "mailto:john@example.com", attendee.value)
+		assert.Equal(t, []string{"Doe; John"}, attendee.params["CN"])
+
+		require.Len(t, event.children, 1)
+		assert.Equal(t, "VALARM", event.children[0].name)
+
+		objectType, err := cal.objectType()
+		require.NoError(t, err)
+		assert.Equal(t, "VEVENT", objectType)
+
+		// The time zone, without UID, is skipped.
+		assert.Equal(t, "meeting@example.com", cal.uid())
+	})
+
+	t.Run("with two calendars", func(t *testing.T) {
+		_, err := parseCalendar([]byte(meetingEvent + meetingEvent))
+		require.ErrorIs(t, err, errInvalidCalendar)
+	})
+
+	t.Run("with an unclosed component", func(t *testing.T) {
+		_, err := parseCalendar([]byte("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:foo\r\nEND:VCALENDAR\r\n"))
+		require.ErrorIs(t, err, errInvalidCalendar)
+	})
+
+	t.Run("not an iCalendar", func(t *testing.T) {
+		_, err := parseCalendar([]byte("some text"))
+		require.ErrorIs(t, err, errInvalidCalendar)
+	})
+}
+
+func TestObjectType(t *testing.T) {
+	t.Run("without UID", func(t *testing.T) {
+		cal, err := parseCalendar([]byte("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:foo\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+		require.NoError(t, err)
+
+		_, err = cal.objectType()
+		require.ErrorIs(t, err, errInvalidCalendar)
+	})
+
+	t.Run("with two UIDs", func(t *testing.T) {
+		cal, err := parseCalendar([]byte("BEGIN:VCALENDAR\r\n" +
+			"BEGIN:VEVENT\r\nUID:foo\r\nEND:VEVENT\r\n" +
+			"BEGIN:VEVENT\r\nUID:bar\r\nEND:VEVENT\r\n" +
+			"END:VCALENDAR\r\n"))
+		require.NoError(t, err)
+
+		_, err = cal.objectType()
+		require.ErrorIs(t, err, errInvalidCalendar)
+	})
+
+	t.Run("with an event and a task", func(t *testing.T) {
+		cal, err := parseCalendar([]byte("BEGIN:VCALENDAR\r\n" +
+			"BEGIN:VEVENT\r\nUID:foo\r\nEND:VEVENT\r\n" +
+			"BEGIN:VTODO\r\nUID:foo\r\nEND:VTODO\r\n" +
+			"END:VCALENDAR\r\n"))
+		require.NoError(t, err)
+
+		_, err = cal.objectType()
+		require.ErrorIs(t, err, errInvalidCalendar)
+	})
+
+	t.Run("with a recurrence exception", func(t *testing.T) {
+		cal, err := parseCalendar([]byte("BEGIN:VCALENDAR\r\n" +
+			"BEGIN:VEVENT\r\nUID:foo\r\nRRULE:FREQ=DAILY\r\nEND:VEVENT\r\n" +
+			"BEGIN:VEVENT\r\nUID:foo\r\nRECURRENCE-ID:20240102T100000Z\r\nEND:VEVENT\r\n" +
+			"END:VCALENDAR\r\n"))
+		require.NoError(t, err)
+
+		objectType, err := cal.objectType()
+		require.NoError(t, err)
+		assert.Equal(t, "VEVENT", objectType)
+	})
+
+	t.Run("with an unsupported component", func(t *testing.T) {
+		cal, err := parseCalendar([]byte("BEGIN:VCALENDAR\r\nBEGIN:VFREEBUSY\r\nUID:foo\r\nEND:VFREEBUSY\r\nEND:VCALENDAR\r\n"))
+		require.NoError(t, err)
+
+		_, err = cal.objectType()
+		require.ErrorIs(t, err, errUnsupportedComponent)
+	})
+}
+
+func TestCalendarMatch(t *testing.T) {
+	cal, err := parseCalendar([]byte(meetingEvent))
+	require.NoError(t, err)
+
+	eventFilter := func(f compFilter) *filter {
+		f.Name = "VEVENT"
+		return &filter{CompFilter: compFilter{Name: "VCALENDAR", CompFilters: []compFilter{f}}}
+	}
+
+	tests := []struct {
+		name     string
+		filter   *filter
+		expected bool
+	}{
+		{"all the calendars", &filter{CompFilter: compFilter{Name: "VCALENDAR"}}, true},
+		{"all the events", eventFilter(compFilter{}), true},
+		{"all the tasks", &filter{CompFilter: compFilter{Name: "VCALENDAR", CompFilters: []compFilter{{Name: "VTODO"}}}}, false},
+		{"without task", &filter{CompFilter: compFilter{Name: "VCALENDAR", CompFilters: []compFilter{{Name: "VTODO", IsNotDefined: &struct{}{}}}}}, true},
+		// The event is from 09:00 to 10:30 UTC.
+		{"overlapping range", eventFilter(compFilter{TimeRange: &timeRange{Start: "20240115T100000Z", End: "20240115T110000Z"}}), true},
+		{"range before", eventFilter(compFilter{TimeRange: &timeRange{Start: "20240115T080000Z", End: "20240115T090000Z"}}), false},
+		{"range after", eventFilter(compFilter{TimeRange: &timeRange{Start: "20240115T103000Z"}}), false},
+		{"range without start", eventFilter(compFilter{TimeRange: &timeRange{End: "20240116T000000Z"}}), true},
+		{"summary contains", eventFilter(compFilter{PropFilters: []propFilter{{Name: "SUMMARY", TextMatch: &textMatch{Value: "MEETING"}}}}), true},
+		{"summary doesn't contain", eventFilter(compFilter{PropFilters: []propFilter{{Name: "SUMMARY", TextMatch: &textMatch{Value: "MEETING", NegateCondition: "yes"}}}}), false},
+		{"summary with octet collation", eventFilter(compFilter{PropFilters: []propFilter{{Name: "SUMMARY", TextMatch: &textMatch{Value: "MEETING", Collation: "i;octet"}}}}), false},
+		{"undefined location", eventFilter(compFilter{PropFilters: []propFilter{{Name: "LOCATION", IsNotDefined: &struct{}{}}}}), true},
+		{"attendee param", eventFilter(compFilter{PropFilters: []propFilter{{Name: "ATTENDEE", ParamFilters: []paramFilter{{Name: "CN", TextMatch: &textMatch{Value: "john"}}}}}}), true},
+		{"with an alarm", eventFilter(compFilter{CompFilters: []compFilter{{Name: "VALARM"}}}), true},
+		{"prop time range", eventFilter(compFilter{PropFilters: []propFilter{{Name: "DTSTAMP", TimeRange: &timeRange{Start: "20240101T000000Z", End: "20240102T000000Z"}}}}), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ok, err := cal.match(test.filter)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, ok)
+		})
+	}
+
+	t.Run("invalid time range", func(t *testing.T) {
+		_, err := cal.match(eventFilter(compFilter{TimeRange: &timeRange{Start: "2024-01-15"}}))
+		require.ErrorIs(t, err, errInvalidFilter)
+	})
+
+	t.Run("unsupported collation", func(t *testing.T) {
+		_, err := cal.match(eventFilter(compFilter{PropFilters: []propFilter{{Name: "SUMMARY", TextMatch: &textMatch{Value: "foo", Collation: "i;unknown"}}}}))
+		require.ErrorIs(t, err, errUnsupportedCollation)
+	})
+}
+
+func TestComponentOverlaps(t *testing.T) {
+	day := func(s string) time.Time {
+		res, err := time.Parse(dateFormat, s)
+		require.NoError(t, err)
+		return res
+	}
+
+	parse := func(comp string) *component {
+		cal, err := parseCalendar([]byte("BEGIN:VCALENDAR\r\n" + comp + "END:VCALENDAR\r\n"))
+		require.NoError(t, err)
+		return cal.children[0]
+	}
+
+	t.Run("all day event", func(t *testing.T) {
+		event := parse("BEGIN:VEVENT\r\nUID:foo\r\nDTSTART;VALUE=DATE:20240115\r\nEND:VEVENT\r\n")
+
+		assert.True(t, event.overlaps(day("20240115"), day("20240116")))
+		assert.False(t, event.overlaps(day("20240116"), day("20240117")))
+	})
+
+	t.Run("recurring event", func(t *testing.T) {
+		event := parse("BEGIN:VEVENT\r\nUID:foo\r\nDTSTART:20240115T100000Z\r\nDTEND:20240115T110000Z\r\nRRULE:FREQ=WEEKLY\r\nEND:VEVENT\r\n")
+
+		assert.True(t, event.overlaps(day("20240301"), day("20240302")))
+		assert.False(t, event.overlaps(day("20240101"), day("20240102")))
+	})
+
+	t.Run("task without dates", func(t *testing.T) {
+		task := parse("BEGIN:VTODO\r\nUID:foo\r\nEND:VTODO\r\n")
+
+		assert.True(t, task.overlaps(day("20240101"), day("20240102")))
+	})
+
+	t.Run("task with a due date", func(t *testing.T) {
+		task := parse("BEGIN:VTODO\r\nUID:foo\r\nDUE:20240115T100000Z\r\nEND:VTODO\r\n")
+
+		assert.True(t, task.overlaps(day("20240115"), day("20240116")))
+		assert.False(t, task.overlaps(day("20240116"), day("20240117")))
+	})
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"PT1H30M": 90 * time.Minute,
+		"P1W":     7 * 24 * time.Hour,
+		"-P1DT2S": -(24*time.Hour + 2*time.Second),
+		"+PT15M":  15 * time.Minute,
+	}
+
+	for input, expected := range tests {
+		res, err := parseDuration(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, res, input)
+	}
+
+	for _, input := range []string{"P", "PT", "1H", "P1H"} {
+		_, err := parseDuration(input)
+		require.ErrorIs(t, err, errInvalidCalendar, input)
+	}
+}
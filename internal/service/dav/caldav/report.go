@@ -0,0 +1,72 @@
+package caldav
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dav/internal/davcol"
+)
+
+func (calendars) NewReport(root xml.Name) any {
+	switch root {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		return new(calendarQuery)
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		return new(calendarMultiget)
+	}
+
+	return nil
+}
+
+func (calendars) Report(ctx context.Context, s *davcol.Server, mw *davcol.MultistatusWriter, m *davcol.Mount, calendar *davcol.Resource, report any) error {
+	switch report := report.(type) {
+	case *calendarQuery:
+		err := reportQuery(ctx, s, mw, m, calendar, report)
+		switch {
+		case errors.Is(err, errUnsupportedCollation):
+			return &davcol.ConditionError{Name: xml.Name{Space: nsCalDAV, Local: "supported-collation"}, Err: err}
+		case errors.Is(err, errInvalidFilter):
+			return &davcol.ConditionError{Name: xml.Name{Space: nsCalDAV, Local: "valid-filter"}, Err: err}
+		}
+		return err
+	case *calendarMultiget:
+		return s.ReportMultiget(ctx, mw, m, calendar, report.Hrefs, report.Prop)
+	}
+
+	return fmt.Errorf("unexpected report %T", report)
+}
+
+// reportQuery writes the calendar objects of the calendar matching the
+// filter (RFC 4791 section 7.8).
+func reportQuery(ctx context.Context, s *davcol.Server, mw *davcol.MultistatusWriter, m *davcol.Mount, calendar *davcol.Resource, report *calendarQuery) error {
+	if report.Filter.CompFilter.Name != "VCALENDAR" {
+		return errInvalidFilter
+	}
+
+	return s.WalkChildren(ctx, m, calendar, func(res *davcol.Resource) error {
+		content, err := s.LoadContent(ctx, res)
+		if err != nil {
+			return err
+		}
+
+		cal, err := parseCalendar(content)
+		if err != nil {
+			// The invalid files added outside of CalDAV are ignored.
+			return nil
+		}
+
+		ok, err := cal.match(&report.Filter)
+		if err != nil || !ok {
+			return err
+		}
+
+		pstats, err := s.Propstats(ctx, m, res, report.Prop)
+		if err != nil {
+			return err
+		}
+
+		return mw.Write(&davcol.Response{Href: davcol.EscapeHref(res.Href), Propstats: pstats})
+	})
+}
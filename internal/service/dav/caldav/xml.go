@@ -0,0 +1,68 @@
+package caldav
+
+import (
+	"encoding/xml"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dav/internal/davcol"
+)
+
+const nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+
+// https://www.rfc-editor.org/rfc/rfc4791#section-9.5
+type calendarQuery struct {
+	XMLName xml.Name          `xml:"urn:ietf:params:xml:ns:caldav calendar-query"`
+	Prop    *davcol.PropNames `xml:"DAV: prop"`
+	Allprop *struct{}         `xml:"DAV: allprop"`
+	Filter  filter            `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+// https://www.rfc-editor.org/rfc/rfc4791#section-9.10
+type calendarMultiget struct {
+	XMLName xml.Name          `xml:"urn:ietf:params:xml:ns:caldav calendar-multiget"`
+	Prop    *davcol.PropNames `xml:"DAV: prop"`
+	Allprop *struct{}         `xml:"DAV: allprop"`
+	Hrefs   []string          `xml:"DAV: href"`
+}
+
+// https://www.rfc-editor.org/rfc/rfc4791#section-9.7
+type filter struct {
+	CompFilter compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// https://www.rfc-editor.org/rfc/rfc4791#section-9.7.1
+type compFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	PropFilters  []propFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+	CompFilters  []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// https://www.rfc-editor.org/rfc/rfc4791#section-9.7.2
+type propFilter struct {
+	Name         string        `xml:"name,attr"`
+	IsNotDefined *struct{}     `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange    `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	TextMatch    *textMatch    `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+	ParamFilters []paramFilter `xml:"urn:ietf:params:xml:ns:caldav param-filter"`
+}
+
+// https://www.rfc-editor.org/rfc/rfc4791#section-9.7.3
+type paramFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *struct{}  `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TextMatch    *textMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+// https://www.rfc-editor.org/rfc/rfc4791#section-9.7.5
+type textMatch struct {
+	Collation       string `xml:"collation,attr"`
+	NegateCondition string `xml:"negate-condition,attr"`
+	Value           string `xml:",chardata"`
+}
+
+// https://www.rfc-editor.org/rfc/rfc4791#section-9.9
+type timeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
@@ -0,0 +1,118 @@
+// Package carddav provides a CardDAV server (RFC 6352) storing the vCards as
+// files.
+//
+// The address books are the folders inside the "Contacts" folder of the
+// session space, each vCard being a file inside them. The sync tokens track
+// the changes made by any client, CardDAV or not.
+package carddav
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dav/internal/davcol"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
+	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/davsync"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+)
+
+const (
+	// HomeFolder is the folder containing the address books, at the root
+	// of the session space.
+	HomeFolder = "Contacts"
+	// DefaultAddressBook is created inside an empty home folder.
+	DefaultAddressBook = "Personal"
+)
+
+type Handler struct {
+	// FileSystem stores the vCards.
+	FileSystem dfs.Service
+	// Sessions handle the users sessions used for authentification.
+	Sessions davsessions.Service
+	Spaces   spaces.Service
+	Users    users.Service
+	// Sync returns the changes of the address books for the sync-collection
+	// reports.
+	Sync davsync.Service
+	// Locks are the WebDAV locks honoured by the writes.
+	Locks davlocks.Service
+	// Logger is an optional error logger. If non-nil, it will be called
+	// for all HTTP requests.
+	Logger func(*http.Request, error)
+	// Prefix is the URL path prefix of the home.
+	Prefix string
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv := davcol.Server{
+		FileSystem: h.FileSystem,
+		Sessions:   h.Sessions,
+		Spaces:     h.Spaces,
+		Users:      h.Users,
+		Sync:       h.Sync,
+		Locks:      h.Locks,
+		Logger:     h.Logger,
+		Prefix:     h.Prefix,
+		Collection: addressBooks{},
+	}
+
+	srv.ServeHTTP(w, r)
+}
+
+var config = davcol.Config{
+	Realm:             "contacts",
+	HomeFolder:        HomeFolder,
+	DefaultCollection: DefaultAddressBook,
+	Namespace:         nsCardDAV,
+	Class:             "addressbook",
+	ContentType:       "text/vcard; charset=utf-8",
+	MediaTypes:        []string{"text/vcard", "text/x-vcard"},
+	ResourceType:      "<C:addressbook/>",
+	HomeSet:           "addressbook-home-set",
+	DataProp:          "address-data",
+	SupportedData:     "supported-address-data",
+	ValidData:         "valid-address-data",
+	Reports:           []string{"addressbook-query", "addressbook-multiget"},
+	Props: []davcol.Prop{{
+		Name: xml.Name{Space: nsCardDAV, Local: "supported-address-data"},
+		Inner: `<C:address-data-type content-type="text/vcard" version="3.0"/>` +
+			`<C:address-data-type content-type="text/vcard" version="4.0"/>`,
+	}},
+}
+
+// addressBooks are the collections of the CardDAV server.
+type addressBooks struct{}
+
+func (addressBooks) Config() *davcol.Config { return &config }
+
+func (addressBooks) Validate(content []byte) error {
+	_, err := parseCard(content)
+	return err
+}
+
+func (addressBooks) UID(content []byte) string {
+	c, err := parseCard(content)
+	if err != nil {
+		return ""
+	}
+
+	return c.get("UID")[0].value
+}
+
+// Data returns the vCard with only the properties listed by the
+// address-data element.
+func (addressBooks) Data(content []byte, pn *davcol.PropNames) []byte {
+	if len(pn.Data) == 0 {
+		return content
+	}
+
+	c, err := parseCard(content)
+	if err != nil {
+		return content
+	}
+
+	return c.partial(pn.Data)
+}
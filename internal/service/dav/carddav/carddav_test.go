@@ -0,0 +1,299 @@
+package carddav
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
+	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/davsync"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/tools/startutils"
+)
+
+const bobCard = "BEGIN:VCARD\r\n" +
+	"VERSION:3.0\r\n" +
+	"UID:b0b\r\n" +
+	"FN:Bob Morane\r\n" +
+	"EMAIL:bob@example.com\r\n" +
+	"END:VCARD\r\n"
+
+func TestCardDAV(t *testing.T) {
+	ctx := context.Background()
+
+	serv := startutils.NewServer(t)
+
+	userSpaces, err := serv.SpacesSvc.GetAllUserSpaces(ctx, serv.User.ID(), nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, userSpaces)
+	space := userSpaces[0]
+
+	h := &Handler{
+		Prefix:     "/carddav",
+		FileSystem: serv.DFSSvc,
+		Sessions:   serv.DavSessionsSvc,
+		Spaces:     serv.SpacesSvc,
+		Users:      serv.UsersSvc,
+		Sync:       davsync.Init(serv.Tools, serv.DB).Service,
+		Locks:      serv.DavLocksSvc,
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	_, token, err := serv.DavSessionsSvc.Create(ctx, &davsessions.CreateCmd{
+		Name:     "contacts",
+		Username: serv.User.Username(),
+		UserID:   serv.User.ID(),
+		SpaceID:  space.ID(),
+	})
+	require.NoError(t, err)
+
+	_, readOnlyToken, err := serv.DavSessionsSvc.Create(ctx, &davsessions.CreateCmd{
+		Name:     "read only contacts",
+		Username: serv.User.Username(),
+		UserID:   serv.User.ID(),
+		SpaceID:  space.ID(),
+		ReadOnly: true,
+	})
+	require.NoError(t, err)
+
+	do := func(password, method, name, content string, headers ...string) (*http.Response, string) {
+		req, err := http.NewRequest(method, srv.URL+name, strings.NewReader(content))
+		require.NoError(t, err)
+		req.SetBasicAuth(serv.User.Username(), password)
+
+		for len(headers) >= 2 {
+			req.Header.Add(headers[0], headers[1])
+			headers = headers[2:]
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		return res, string(body)
+	}
+
+	hrefs := func(body string) []string {
+		res := []string{}
+		for _, match := range regexp.MustCompile(`<D:href>([^<]*)</D:href>`).FindAllStringSubmatch(body, -1) {
+			res = append(res, match[1])
+		}
+		return res
+	}
+
+	syncToken := func(body string) string {
+		match := regexp.MustCompile(`<D:sync-token>([^<]*)</D:sync-token>`).FindStringSubmatch(body)
+		require.Len(t, match, 2, body)
+		return match[1]
+	}
+
+	t.Run("the home is created with a default address book", func(t *testing.T) {
+		res, body := do(token, "PROPFIND", "/carddav/", `<?xml version="1.0"?>`+
+			`<D:propfind xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:carddav"><D:prop>`+
+			`<D:current-user-principal/><C:addressbook-home-set/><D:resourcetype/>`+
+			`</D:prop></D:propfind>`, "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode, body)
+
+		assert.Contains(t, body, "<D:current-user-principal><D:href>/carddav/</D:href></D:current-user-principal>")
+		assert.Contains(t, body, "<C:addressbook-home-set><D:href>/carddav/</D:href></C:addressbook-home-set>")
+		assert.Contains(t, body, "<D:resourcetype><D:collection/><C:addressbook/></D:resourcetype>")
+		assert.Contains(t, body, "<D:href>/carddav/Personal/</D:href>")
+	})
+
+	t.Run("the infinite depth is refused", func(t *testing.T) {
+		res, body := do(token, "PROPFIND", "/carddav/", "", "Depth", "infinity")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Contains(t, body, "<D:propfind-finite-depth/>")
+	})
+
+	t.Run("PUT and GET a vCard", func(t *testing.T) {
+		res, _ := do(token, "PUT", "/carddav/Personal/alice.vcf", aliceCard, "Content-Type", "text/vcard", "If-None-Match", "*")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		etag := res.Header.Get("ETag")
+		require.NotEmpty(t, etag)
+
+		res, body := do(token, "GET", "/carddav/Personal/alice.vcf", "")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, aliceCard, body)
+		assert.Equal(t, etag, res.Header.Get("ETag"))
+		assert.Equal(t, "text/vcard; charset=utf-8", res.Header.Get("Content-Type"))
+
+		res, _ = do(token, "PUT", "/carddav/Personal/alice.vcf", aliceCard, "If-None-Match", "*")
+		require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+		res, _ = do(token, "PUT", "/carddav/Personal/alice.vcf", aliceCard, "If-Match", etag)
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		res, _ = do(token, "PUT", "/carddav/Personal/bob.vcf", bobCard)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+	})
+
+	t.Run("PUT an invalid vCard", func(t *testing.T) {
+		res, body := do(token, "PUT", "/carddav/Personal/invalid.vcf", "BEGIN:VCARD\r\nFN:foo\r\nEND:VCARD\r\n")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Contains(t, body, "<C:valid-address-data/>")
+
+		res, _ = do(token, "PUT", "/carddav/Unknown/alice.vcf", aliceCard)
+		require.Equal(t, http.StatusConflict, res.StatusCode)
+	})
+
+	t.Run("PUT a vCard with an UID already used", func(t *testing.T) {
+		res, body := do(token, "PUT", "/carddav/Personal/carol.vcf", bobCard)
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Contains(t, body, "<C:no-uid-conflict><D:href>/carddav/Personal/bob.vcf</D:href></C:no-uid-conflict>")
+
+		res, _ = do(token, "GET", "/carddav/Personal/carol.vcf", "")
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("PUT and DELETE a vCard locked by a WebDAV client", func(t *testing.T) {
+		lock, err := serv.DavLocksSvc.Create(ctx, &davlocks.CreateCmd{
+			User:  serv.User,
+			Path:  dfs.NewPathCmd(&space, "/Contacts/Personal/bob.vcf"),
+			Scope: davlocks.ScopeExclusive,
+			Depth: davlocks.DepthZero,
+		})
+		require.NoError(t, err)
+
+		res, _ := do(token, "PUT", "/carddav/Personal/bob.vcf", bobCard)
+		require.Equal(t, http.StatusLocked, res.StatusCode)
+
+		res, _ = do(token, "DELETE", "/carddav/Personal/bob.vcf", "")
+		require.Equal(t, http.StatusLocked, res.StatusCode)
+
+		res, _ = do(token, "DELETE", "/carddav/Personal/", "")
+		require.Equal(t, http.StatusLocked, res.StatusCode)
+
+		res, _ = do(token, "PUT", "/carddav/Personal/bob.vcf", bobCard, "If", "(<"+lock.Token()+">)")
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		err = serv.DavLocksSvc.Unlock(ctx, &davlocks.UnlockCmd{
+			User:  serv.User,
+			Path:  dfs.NewPathCmd(&space, "/Contacts/Personal/bob.vcf"),
+			Token: lock.Token(),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("the read only sessions can't write", func(t *testing.T) {
+		res, _ := do(readOnlyToken, "PUT", "/carddav/Personal/carol.vcf", bobCard)
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		res, _ = do(readOnlyToken, "GET", "/carddav/Personal/bob.vcf", "")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("PROPFIND an address book", func(t *testing.T) {
+		res, body := do(token, "PROPFIND", "/carddav/Personal/", `<?xml version="1.0"?>`+
+			`<D:propfind xmlns:D="DAV:"><D:prop><D:getetag/><D:getcontenttype/></D:prop></D:propfind>`, "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+
+		assert.Equal(t, []string{"/carddav/Personal/", "/carddav/Personal/alice.vcf", "/carddav/Personal/bob.vcf"}, hrefs(body))
+		assert.Equal(t, 2, strings.Count(body, "<D:getcontenttype>text/vcard; charset=utf-8</D:getcontenttype>"))
+	})
+
+	t.Run("REPORT addressbook-query", func(t *testing.T) {
+		res, body := do(token, "REPORT", "/carddav/Personal/", `<?xml version="1.0"?>`+
+			`<C:addressbook-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:carddav">`+
+			`<D:prop><D:getetag/><C:address-data><C:prop name="EMAIL"/></C:address-data></D:prop>`+
+			`<C:filter><C:prop-filter name="FN"><C:text-match match-type="starts-with">bob</C:text-match></C:prop-filter></C:filter>`+
+			`</C:addressbook-query>`, "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+
+		assert.Equal(t, []string{"/carddav/Personal/bob.vcf"}, hrefs(body))
+		assert.Contains(t, body, "EMAIL:bob@example.com")
+		assert.NotContains(t, body, "FN:Bob Morane")
+	})
+
+	t.Run("REPORT addressbook-query with a limit", func(t *testing.T) {
+		res, body := do(token, "REPORT", "/carddav/Personal/", `<?xml version="1.0"?>`+
+			`<C:addressbook-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:carddav">`+
+			`<D:prop><D:getetag/></D:prop><C:filter/><C:limit><C:nresults>1</C:nresults></C:limit>`+
+			`</C:addressbook-query>`, "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+
+		assert.Equal(t, []string{"/carddav/Personal/alice.vcf", "/carddav/Personal/"}, hrefs(body))
+		assert.Contains(t, body, "<D:number-of-matches-within-limits/>")
+	})
+
+	t.Run("REPORT addressbook-multiget", func(t *testing.T) {
+		res, body := do(token, "REPORT", "/carddav/Personal/", `<?xml version="1.0"?>`+
+			`<C:addressbook-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:carddav">`+
+			`<D:prop><D:getetag/><C:address-data/></D:prop>`+
+			`<D:href>/carddav/Personal/alice.vcf</D:href>`+
+			`<D:href>/carddav/Personal/unknown.vcf</D:href>`+
+			`</C:addressbook-multiget>`, "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+
+		assert.Equal(t, []string{"/carddav/Personal/alice.vcf", "/carddav/Personal/unknown.vcf"}, hrefs(body))
+		assert.Contains(t, body, "FN:Alice Liddell")
+		assert.Contains(t, body, "<D:status>HTTP/1.1 404 Not Found</D:status>")
+	})
+
+	t.Run("REPORT sync-collection", func(t *testing.T) {
+		syncReport := func(token string) string {
+			return `<?xml version="1.0"?><D:sync-collection xmlns:D="DAV:">` +
+				`<D:sync-token>` + token + `</D:sync-token><D:sync-level>1</D:sync-level>` +
+				`<D:prop><D:getetag/></D:prop></D:sync-collection>`
+		}
+
+		res, body := do(token, "REPORT", "/carddav/Personal/", syncReport(""))
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		assert.Equal(t, []string{"/carddav/Personal/alice.vcf", "/carddav/Personal/bob.vcf"}, hrefs(body))
+		firstToken := syncToken(body)
+
+		res, body = do(token, "REPORT", "/carddav/Personal/", syncReport(firstToken))
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		assert.Empty(t, hrefs(body))
+		assert.Equal(t, firstToken, syncToken(body))
+
+		res, _ = do(token, "DELETE", "/carddav/Personal/alice.vcf", "")
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+		res, _ = do(token, "PUT", "/carddav/Personal/bob.vcf", strings.Replace(bobCard, "Morane", "Dylan", 1))
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		res, body = do(token, "REPORT", "/carddav/Personal/", syncReport(firstToken))
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		assert.Equal(t, []string{"/carddav/Personal/alice.vcf", "/carddav/Personal/bob.vcf"}, hrefs(body))
+		assert.Contains(t, body, "<D:status>HTTP/1.1 404 Not Found</D:status>")
+		assert.NotEqual(t, firstToken, syncToken(body))
+
+		res, body = do(token, "REPORT", "/carddav/Personal/", syncReport("invalid-token"))
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Contains(t, body, "<D:valid-sync-token/>")
+
+		res, body = do(token, "REPORT", "/carddav/Personal/", syncReport(firstToken+"000"))
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Contains(t, body, "<D:valid-sync-token/>")
+	})
+
+	t.Run("MKCOL and DELETE an address book", func(t *testing.T) {
+		res, _ := do(token, "MKCOL", "/carddav/Work/", "")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		res, _ = do(token, "MKCOL", "/carddav/Work/", "")
+		require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+
+		res, _ = do(token, "DELETE", "/carddav/Work/", "")
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		res, _ = do(token, "PROPFIND", "/carddav/Work/", "", "Depth", "0")
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		res, _ := do("invalid", "PROPFIND", "/carddav/", "", "Depth", "0")
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
@@ -0,0 +1,89 @@
+package carddav
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dav/internal/davcol"
+)
+
+var errLimitReached = errors.New("carddav: limit reached")
+
+func (addressBooks) NewReport(root xml.Name) any {
+	switch root {
+	case xml.Name{Space: nsCardDAV, Local: "addressbook-query"}:
+		return new(addressbookQuery)
+	case xml.Name{Space: nsCardDAV, Local: "addressbook-multiget"}:
+		return new(addressbookMultiget)
+	}
+
+	return nil
+}
+
+func (addressBooks) Report(ctx context.Context, s *davcol.Server, mw *davcol.MultistatusWriter, m *davcol.Mount, book *davcol.Resource, report any) error {
+	switch report := report.(type) {
+	case *addressbookQuery:
+		err := reportQuery(ctx, s, mw, m, book, report)
+		if errors.Is(err, errUnsupportedCollation) {
+			return &davcol.ConditionError{Name: xml.Name{Space: nsCardDAV, Local: "supported-collation"}, Err: err}
+		}
+		return err
+	case *addressbookMultiget:
+		return s.ReportMultiget(ctx, mw, m, book, report.Hrefs, report.Prop)
+	}
+
+	return fmt.Errorf("unexpected report %T", report)
+}
+
+// reportQuery writes the vCards of the address book matching the filter
+// (RFC 6352 section 8.6).
+func reportQuery(ctx context.Context, s *davcol.Server, mw *davcol.MultistatusWriter, m *davcol.Mount, book *davcol.Resource, report *addressbookQuery) error {
+	limit := 0
+	if report.Limit != nil {
+		limit = report.Limit.NResults
+	}
+
+	nbResults := 0
+
+	err := s.WalkChildren(ctx, m, book, func(res *davcol.Resource) error {
+		content, err := s.LoadContent(ctx, res)
+		if err != nil {
+			return err
+		}
+
+		c, err := parseCard(content)
+		if err != nil {
+			// The invalid files added outside of CardDAV are ignored.
+			return nil
+		}
+
+		ok, err := c.match(&report.Filter)
+		if err != nil || !ok {
+			return err
+		}
+
+		if limit > 0 && nbResults >= limit {
+			return errLimitReached
+		}
+		nbResults++
+
+		pstats, err := s.Propstats(ctx, m, res, report.Prop)
+		if err != nil {
+			return err
+		}
+
+		return mw.Write(&davcol.Response{Href: davcol.EscapeHref(res.Href), Propstats: pstats})
+	})
+	if errors.Is(err, errLimitReached) {
+		return mw.Write(&davcol.Response{
+			Href:                davcol.EscapeHref(book.Href),
+			Status:              davcol.StatusLine(davcol.StatusInsufficientStorage),
+			Error:               &davcol.XMLError{Inner: "<D:number-of-matches-within-limits/>"},
+			ResponseDescription: fmt.Sprintf("Only the first %d vCards are returned", limit),
+		})
+	}
+
+	return err
+}
@@ -0,0 +1,313 @@
+package carddav
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dav/internal/davcol"
+)
+
+var (
+	errInvalidCard          = errors.New("carddav: invalid vCard")
+	errUnsupportedCollation = errors.New("carddav: unsupported collation")
+)
+
+// card is a vCard (RFC 6350) split into its content lines. The values are
+// kept as is, only the names and the parameters are parsed for the filters.
+type card struct {
+	props []cardProp
+}
+
+type cardProp struct {
+	// name is the upper-cased property name without its group.
+	name   string
+	params map[string][]string
+	value  string
+	// line is the unfolded content line.
+	line string
+}
+
+// parseCard parses a single vCard. It must have a VERSION and an UID as
+// required by RFC 6352 section 5.1.
+func parseCard(data []byte) (*card, error) {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	lines := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	res := card{props: make([]cardProp, 0, len(lines))}
+	for _, line := range lines {
+		prop, err := parseContentLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		res.props = append(res.props, *prop)
+	}
+
+	if len(res.props) < 2 ||
+		res.props[0].name != "BEGIN" || !strings.EqualFold(res.props[0].value, "VCARD") ||
+		res.props[len(res.props)-1].name != "END" || !strings.EqualFold(res.props[len(res.props)-1].value, "VCARD") {
+		return nil, errInvalidCard
+	}
+
+	for _, prop := range res.props[1 : len(res.props)-1] {
+		if prop.name == "BEGIN" || prop.name == "END" {
+			// Only one vCard is accepted by resource.
+			return nil, errInvalidCard
+		}
+	}
+
+	if len(res.get("VERSION")) == 0 || len(res.get("UID")) == 0 {
+		return nil, errInvalidCard
+	}
+
+	return &res, nil
+}
+
+// parseContentLine parses a "group.name;param=value:value" line. The
+// colons and semicolons inside the quoted parameter values are ignored.
+func parseContentLine(line string) (*cardProp, error) {
+	inQuotes := false
+	fields := []string{}
+	start := 0
+	valueIdx := -1
+	for i, c := range line {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ';' && !inQuotes:
+			fields = append(fields, line[start:i])
+			start = i + 1
+		case c == ':' && !inQuotes:
+			valueIdx = i
+		}
+
+		if valueIdx != -1 {
+			break
+		}
+	}
+
+	if valueIdx == -1 {
+		return nil, errInvalidCard
+	}
+
+	fields = append(fields, line[start:valueIdx])
+
+	name := fields[0]
+	if idx := strings.LastIndexByte(name, '.'); idx != -1 {
+		name = name[idx+1:]
+	}
+
+	if name == "" {
+		return nil, errInvalidCard
+	}
+
+	params := map[string][]string{}
+	for _, param := range fields[1:] {
+		key, values, found := strings.Cut(param, "=")
+		if !found {
+			// vCard 2.1 allows the TYPE values without their name.
+			key, values = "TYPE", key
+		}
+
+		key = strings.ToUpper(key)
+		for _, value := range strings.Split(values, ",") {
+			params[key] = append(params[key], strings.Trim(value, `"`))
+		}
+	}
+
+	return &cardProp{
+		name:   strings.ToUpper(name),
+		params: params,
+		value:  line[valueIdx+1:],
+		line:   line,
+	}, nil
+}
+
+// get returns all the properties with the given name.
+func (c *card) get(name string) []cardProp {
+	res := []cardProp{}
+	for _, prop := range c.props {
+		if prop.name == strings.ToUpper(name) {
+			res = append(res, prop)
+		}
+	}
+
+	return res
+}
+
+// partial returns the vCard with only the given properties, as asked by
+// the address-data elements listing some props. The BEGIN, END, VERSION
+// and UID properties are always kept.
+func (c *card) partial(names []string) []byte {
+	keep := map[string]bool{"BEGIN": true, "END": true, "VERSION": true, "UID": true}
+	for _, name := range names {
+		keep[strings.ToUpper(name)] = true
+	}
+
+	var buf bytes.Buffer
+	for _, prop := range c.props {
+		if keep[prop.name] {
+			buf.WriteString(prop.line)
+			buf.WriteString("\r\n")
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// match returns true if the vCard matches the filter of an
+// addressbook-query report (RFC 6352 section 10.5).
+func (c *card) match(f *filter) (bool, error) {
+	if len(f.PropFilters) == 0 {
+		return true, nil
+	}
+
+	for _, propFilter := range f.PropFilters {
+		ok, err := c.matchProp(&propFilter)
+		if err != nil {
+			return false, err
+		}
+
+		if ok && !f.isAllOf() {
+			return true, nil
+		}
+
+		if !ok && f.isAllOf() {
+			return false, nil
+		}
+	}
+
+	return f.isAllOf(), nil
+}
+
+func (c *card) matchProp(f *propFilter) (bool, error) {
+	props := c.get(f.Name)
+
+	if f.IsNotDefined != nil {
+		return len(props) == 0, nil
+	}
+
+	if len(f.TextMatches) == 0 && len(f.ParamFilters) == 0 {
+		return len(props) > 0, nil
+	}
+
+	// The filter matches if one of the instances of the property matches.
+	for _, prop := range props {
+		ok, err := prop.match(f)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
+
+func (p *cardProp) match(f *propFilter) (bool, error) {
+	tests := make([]func() (bool, error), 0, len(f.TextMatches)+len(f.ParamFilters))
+	for _, textMatch := range f.TextMatches {
+		tests = append(tests, func() (bool, error) { return textMatch.match(p.value) })
+	}
+
+	for _, paramFilter := range f.ParamFilters {
+		tests = append(tests, func() (bool, error) { return p.matchParam(&paramFilter) })
+	}
+
+	for _, test := range tests {
+		ok, err := test()
+		if err != nil {
+			return false, err
+		}
+
+		if ok && !f.isAllOf() {
+			return true, nil
+		}
+
+		if !ok && f.isAllOf() {
+			return false, nil
+		}
+	}
+
+	return f.isAllOf(), nil
+}
+
+func (p *cardProp) matchParam(f *paramFilter) (bool, error) {
+	values, ok := p.params[strings.ToUpper(f.Name)]
+
+	switch {
+	case f.IsNotDefined != nil:
+		return !ok, nil
+	case !ok:
+		return false, nil
+	case f.TextMatch == nil:
+		return true, nil
+	}
+
+	for _, value := range values {
+		ok, err := f.TextMatch.match(value)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
+
+// match applies the text-match to the given value with its collation and
+// match type (RFC 6352 section 10.5.4).
+func (t *textMatch) match(value string) (bool, error) {
+	expected := t.Value
+
+	switch t.Collation {
+	case "", "i;unicode-casemap":
+		value, expected = strings.ToLower(value), strings.ToLower(expected)
+	case "i;ascii-casemap":
+		value, expected = asciiLower(value), asciiLower(expected)
+	case "i;octet":
+	default:
+		return false, errUnsupportedCollation
+	}
+
+	var res bool
+	switch t.MatchType {
+	case "equals":
+		res = value == expected
+	case "", "contains":
+		res = strings.Contains(value, expected)
+	case "starts-with":
+		res = strings.HasPrefix(value, expected)
+	case "ends-with":
+		res = strings.HasSuffix(value, expected)
+	default:
+		return false, davcol.ErrInvalidRequest
+	}
+
+	if t.NegateCondition == "yes" {
+		return !res, nil
+	}
+
+	return res, nil
+}
+
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+
+		return r
+	}, s)
+}
@@ -0,0 +1,164 @@
+package carddav
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const aliceCard = "BEGIN:VCARD\r\n" +
+	"VERSION:4.0\r\n" +
+	"UID:urn:uuid:4fbe8971-0bc3-424c-9c26-36c3e1eff6b1\r\n" +
+	"FN:Alice Liddell\r\n" +
+	"N:Liddell;Alice;;;\r\n" +
+	"item1.EMAIL;TYPE=work,pref:alice@example.com\r\n" +
+	"TEL;TYPE=\"cell,voice\":+33 6 00 00 00 00\r\n" +
+	"NOTE:A very long note fol\r\n" +
+	" ded on two lines\r\n" +
+	"END:VCARD\r\n"
+
+func TestParseCard(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		c, err := parseCard([]byte(aliceCard))
+		require.NoError(t, err)
+
+		require.Len(t, c.get("EMAIL"), 1)
+		assert.Equal(t, "alice@example.com", c.get("email")[0].value)
+		assert.Equal(t, []string{"work", "pref"}, c.get("EMAIL")[0].params["TYPE"])
+		assert.Equal(t, []string{"cell", "voice"}, c.get("TEL")[0].params["TYPE"])
+		assert.Equal(t, "A very long note folded on two lines", c.get("NOTE")[0].value)
+	})
+
+	t.Run("without UID", func(t *testing.T) {
+		_, err := parseCard([]byte("BEGIN:VCARD\r\nVERSION:4.0\r\nFN:Alice\r\nEND:VCARD\r\n"))
+		require.ErrorIs(t, err, errInvalidCard)
+	})
+
+	t.Run("with two vCards", func(t *testing.T) {
+		_, err := parseCard([]byte(aliceCard + aliceCard))
+		require.ErrorIs(t, err, errInvalidCard)
+	})
+
+	t.Run("with an invalid line", func(t *testing.T) {
+		_, err := parseCard([]byte("BEGIN:VCARD\r\nVERSION:4.0\r\nUID:foo\r\ninvalid\r\nEND:VCARD\r\n"))
+		require.ErrorIs(t, err, errInvalidCard)
+	})
+
+	t.Run("not a vCard", func(t *testing.T) {
+		_, err := parseCard([]byte("some text"))
+		require.ErrorIs(t, err, errInvalidCard)
+	})
+}
+
+func TestCardPartial(t *testing.T) {
+	c, err := parseCard([]byte(aliceCard))
+	require.NoError(t, err)
+
+	assert.Equal(t, "BEGIN:VCARD\r\n"+
+		"VERSION:4.0\r\n"+
+		"UID:urn:uuid:4fbe8971-0bc3-424c-9c26-36c3e1eff6b1\r\n"+
+		"FN:Alice Liddell\r\n"+
+		"END:VCARD\r\n", string(c.partial([]string{"fn"})))
+}
+
+func TestCardMatch(t *testing.T) {
+	c, err := parseCard([]byte(aliceCard))
+	require.NoError(t, err)
+
+	newTextMatch := func(value, matchType, collation string, negate bool) textMatch {
+		res := textMatch{Value: value, MatchType: matchType, Collation: collation}
+		if negate {
+			res.NegateCondition = "yes"
+		}
+		return res
+	}
+
+	testCases := []struct {
+		desc   string
+		filter filter
+		want   bool
+		err    error
+	}{{
+		desc:   "empty filter",
+		filter: filter{},
+		want:   true,
+	}, {
+		desc:   "defined property",
+		filter: filter{PropFilters: []propFilter{{Name: "EMAIL"}}},
+		want:   true,
+	}, {
+		desc:   "undefined property",
+		filter: filter{PropFilters: []propFilter{{Name: "BDAY"}}},
+		want:   false,
+	}, {
+		desc:   "is-not-defined",
+		filter: filter{PropFilters: []propFilter{{Name: "BDAY", IsNotDefined: &struct{}{}}}},
+		want:   true,
+	}, {
+		desc:   "contains with the default collation",
+		filter: filter{PropFilters: []propFilter{{Name: "FN", TextMatches: []textMatch{newTextMatch("ALICE", "", "", false)}}}},
+		want:   true,
+	}, {
+		desc:   "octet collation",
+		filter: filter{PropFilters: []propFilter{{Name: "FN", TextMatches: []textMatch{newTextMatch("ALICE", "", "i;octet", false)}}}},
+		want:   false,
+	}, {
+		desc:   "equals",
+		filter: filter{PropFilters: []propFilter{{Name: "FN", TextMatches: []textMatch{newTextMatch("alice liddell", "equals", "i;ascii-casemap", false)}}}},
+		want:   true,
+	}, {
+		desc:   "starts-with",
+		filter: filter{PropFilters: []propFilter{{Name: "EMAIL", TextMatches: []textMatch{newTextMatch("alice@", "starts-with", "", false)}}}},
+		want:   true,
+	}, {
+		desc:   "ends-with negated",
+		filter: filter{PropFilters: []propFilter{{Name: "EMAIL", TextMatches: []textMatch{newTextMatch("example.com", "ends-with", "", true)}}}},
+		want:   false,
+	}, {
+		desc: "param-filter",
+		filter: filter{PropFilters: []propFilter{{Name: "TEL", ParamFilters: []paramFilter{{
+			Name:      "TYPE",
+			TextMatch: &textMatch{Value: "cell", MatchType: "equals"},
+		}}}}},
+		want: true,
+	}, {
+		desc: "anyof",
+		filter: filter{PropFilters: []propFilter{
+			{Name: "BDAY"},
+			{Name: "FN", TextMatches: []textMatch{newTextMatch("alice", "", "", false)}},
+		}},
+		want: true,
+	}, {
+		desc: "allof",
+		filter: filter{Test: "allof", PropFilters: []propFilter{
+			{Name: "BDAY"},
+			{Name: "FN", TextMatches: []textMatch{newTextMatch("alice", "", "", false)}},
+		}},
+		want: false,
+	}, {
+		desc: "allof inside a prop-filter",
+		filter: filter{PropFilters: []propFilter{{Name: "FN", Test: "allof", TextMatches: []textMatch{
+			newTextMatch("alice", "", "", false),
+			newTextMatch("bob", "", "", false),
+		}}}},
+		want: false,
+	}, {
+		desc:   "unsupported collation",
+		filter: filter{PropFilters: []propFilter{{Name: "FN", TextMatches: []textMatch{newTextMatch("alice", "", "i;foo", false)}}}},
+		err:    errUnsupportedCollation,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, err := c.match(&tc.filter)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package carddav
+
+import (
+	"encoding/xml"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dav/internal/davcol"
+)
+
+const nsCardDAV = "urn:ietf:params:xml:ns:carddav"
+
+// https://www.rfc-editor.org/rfc/rfc6352#section-10.3
+type addressbookQuery struct {
+	XMLName xml.Name          `xml:"urn:ietf:params:xml:ns:carddav addressbook-query"`
+	Prop    *davcol.PropNames `xml:"DAV: prop"`
+	Allprop *struct{}         `xml:"DAV: allprop"`
+	Filter  filter            `xml:"urn:ietf:params:xml:ns:carddav filter"`
+	Limit   *struct {
+		NResults int `xml:"urn:ietf:params:xml:ns:carddav nresults"`
+	} `xml:"urn:ietf:params:xml:ns:carddav limit"`
+}
+
+// https://www.rfc-editor.org/rfc/rfc6352#section-8.7
+type addressbookMultiget struct {
+	XMLName xml.Name          `xml:"urn:ietf:params:xml:ns:carddav addressbook-multiget"`
+	Prop    *davcol.PropNames `xml:"DAV: prop"`
+	Allprop *struct{}         `xml:"DAV: allprop"`
+	Hrefs   []string          `xml:"DAV: href"`
+}
+
+// https://www.rfc-editor.org/rfc/rfc6352#section-10.5
+type filter struct {
+	Test        string       `xml:"test,attr"`
+	PropFilters []propFilter `xml:"urn:ietf:params:xml:ns:carddav prop-filter"`
+}
+
+func (f *filter) isAllOf() bool { return f.Test == "allof" }
+
+type propFilter struct {
+	Name         string        `xml:"name,attr"`
+	Test         string        `xml:"test,attr"`
+	IsNotDefined *struct{}     `xml:"urn:ietf:params:xml:ns:carddav is-not-defined"`
+	TextMatches  []textMatch   `xml:"urn:ietf:params:xml:ns:carddav text-match"`
+	ParamFilters []paramFilter `xml:"urn:ietf:params:xml:ns:carddav param-filter"`
+}
+
+func (f *propFilter) isAllOf() bool { return f.Test == "allof" }
+
+type paramFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *struct{}  `xml:"urn:ietf:params:xml:ns:carddav is-not-defined"`
+	TextMatch    *textMatch `xml:"urn:ietf:params:xml:ns:carddav text-match"`
+}
+
+type textMatch struct {
+	Collation       string `xml:"collation,attr"`
+	NegateCondition string `xml:"negate-condition,attr"`
+	MatchType       string `xml:"match-type,attr"`
+	Value           string `xml:",chardata"`
+}
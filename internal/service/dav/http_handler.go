@@ -4,8 +4,12 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/dav/caldav"
+	"github.com/theduckcompany/duckcloud/internal/service/dav/carddav"
 	"github.com/theduckcompany/duckcloud/internal/service/dav/webdav"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/davsync"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/files"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
@@ -15,13 +19,36 @@ import (
 	"github.com/theduckcompany/duckcloud/internal/tools/router"
 )
 
+// Config configures the WebDAV server.
+type Config struct {
+	Propfind webdav.PropfindConfig
+}
+
 // HTTPHandler serve files via the Webdav protocol over http.
 type HTTPHandler struct {
-	webdavHandler *webdav.Handler
+	webdavHandler  *webdav.Handler
+	carddavHandler *carddav.Handler
+	caldavHandler  *caldav.Handler
 }
 
 // NewHTTPHandler builds a new EchoHandler.
-func NewHTTPHandler(tools tools.Tools, fs dfs.Service, files files.Service, spaces spaces.Service, davSessions davsessions.Service, users users.Service) *HTTPHandler {
+func NewHTTPHandler(
+	cfg Config,
+	tools tools.Tools,
+	fs dfs.Service,
+	files files.Service,
+	spaces spaces.Service,
+	davSessions davsessions.Service,
+	users users.Service,
+	locks davlocks.Service,
+	davSync davsync.Service,
+) *HTTPHandler {
+	logError := func(r *http.Request, err error) {
+		if err != nil {
+			logger.LogEntrySetError(r.Context(), err)
+		}
+	}
+
 	return &HTTPHandler{
 		webdavHandler: &webdav.Handler{
 			Prefix:     "/webdav",
@@ -30,22 +57,50 @@ func NewHTTPHandler(tools tools.Tools, fs dfs.Service, files files.Service, spac
 			Users:      users,
 			Files:      files,
 			Sessions:   davSessions,
-			Logger: func(r *http.Request, err error) {
-				if err != nil {
-					logger.LogEntrySetError(r.Context(), err)
-				}
-			},
+			Locks:      locks,
+			Propfind:   cfg.Propfind,
+			Logger:     logError,
+		},
+		carddavHandler: &carddav.Handler{
+			Prefix:     "/carddav",
+			FileSystem: fs,
+			Sessions:   davSessions,
+			Spaces:     spaces,
+			Users:      users,
+			Sync:       davSync,
+			Locks:      locks,
+			Logger:     logError,
+		},
+		caldavHandler: &caldav.Handler{
+			Prefix:     "/caldav",
+			FileSystem: fs,
+			Sessions:   davSessions,
+			Spaces:     spaces,
+			Users:      users,
+			Sync:       davSync,
+			Locks:      locks,
+			Logger:     logError,
 		},
 	}
 }
 
 func (h *HTTPHandler) Register(r chi.Router, mids *router.Middlewares) {
 	if mids != nil {
-		r = r.With(mids.StripSlashed, mids.Logger)
+		r = r.With(mids.RealIP, mids.StripSlashed, mids.Logger)
 	}
 
 	r.HandleFunc("/webdav", h.handleWebdavCollections)
 	r.Handle("/webdav/*", h.webdavHandler)
+
+	// The contacts and calendar applications look for the CardDAV and
+	// CalDAV servers with the well-known URIs (RFC 6764).
+	r.Handle("/.well-known/carddav", http.RedirectHandler("/carddav/", http.StatusMovedPermanently))
+	r.Handle("/carddav", h.carddavHandler)
+	r.Handle("/carddav/*", h.carddavHandler)
+
+	r.Handle("/.well-known/caldav", http.RedirectHandler("/caldav/", http.StatusMovedPermanently))
+	r.Handle("/caldav", h.caldavHandler)
+	r.Handle("/caldav/*", h.caldavHandler)
 }
 
 func (h *HTTPHandler) handleWebdavCollections(w http.ResponseWriter, r *http.Request) {
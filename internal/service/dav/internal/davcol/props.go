@@ -0,0 +1,382 @@
+package davcol
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+)
+
+const syncTokenPrefix = "urn:duckcloud:sync:"
+
+// batchSize is the number of children read at once while listing a
+// collection.
+const batchSize = 100
+
+type ResourceKind int
+
+const (
+	KindHome ResourceKind = iota
+	KindCollection
+	KindObject
+)
+
+// Resource is the home, a collection or an object.
+type Resource struct {
+	Kind ResourceKind
+	Href string
+	Info *dfs.INode
+
+	path *dfs.PathCmd
+	// content is the object, it's only loaded for the data property and the
+	// filters.
+	content []byte
+}
+
+var (
+	propResourceType     = xml.Name{Space: NamespaceDAV, Local: "resourcetype"}
+	propDisplayName      = xml.Name{Space: NamespaceDAV, Local: "displayname"}
+	propGetETag          = xml.Name{Space: NamespaceDAV, Local: "getetag"}
+	propGetContentType   = xml.Name{Space: NamespaceDAV, Local: "getcontenttype"}
+	propGetContentLength = xml.Name{Space: NamespaceDAV, Local: "getcontentlength"}
+	propGetLastModified  = xml.Name{Space: NamespaceDAV, Local: "getlastmodified"}
+	propCurrentPrincipal = xml.Name{Space: NamespaceDAV, Local: "current-user-principal"}
+	propPrincipalURL     = xml.Name{Space: NamespaceDAV, Local: "principal-URL"}
+	propPrivilegeSet     = xml.Name{Space: NamespaceDAV, Local: "current-user-privilege-set"}
+	propSupportedReports = xml.Name{Space: NamespaceDAV, Local: "supported-report-set"}
+	propSyncToken        = xml.Name{Space: NamespaceDAV, Local: "sync-token"}
+	propCTag             = xml.Name{Space: NamespaceCalendarServer, Local: "getctag"}
+)
+
+// allProps returns the names of the properties returned for the allprop
+// requests and for the requests without any body.
+func (s *Server) allProps(kind ResourceKind) []xml.Name {
+	cfg := s.Collection.Config()
+
+	switch kind {
+	case KindHome:
+		return []xml.Name{
+			propResourceType, propDisplayName, propCurrentPrincipal, propPrincipalURL,
+			{Space: cfg.Namespace, Local: cfg.HomeSet}, propPrivilegeSet,
+		}
+	case KindCollection:
+		res := []xml.Name{
+			propResourceType, propDisplayName, propCurrentPrincipal, propPrivilegeSet, propSupportedReports,
+			propSyncToken, propCTag,
+		}
+		for _, prop := range cfg.Props {
+			res = append(res, prop.Name)
+		}
+		return append(res, xml.Name{Space: cfg.Namespace, Local: "max-resource-size"})
+	default:
+		return []xml.Name{propResourceType, propGetETag, propGetContentType, propGetContentLength, propGetLastModified, propPrivilegeSet}
+	}
+}
+
+// Propstats returns the asked properties of the resource. The unknown
+// properties are returned with a 404 status. A nil pn returns all the
+// properties of the resource.
+func (s *Server) Propstats(ctx context.Context, m *Mount, res *Resource, pn *PropNames) ([]Propstat, error) {
+	if pn == nil {
+		pn = &PropNames{Names: s.allProps(res.Kind)}
+	}
+
+	found := Propstat{Status: StatusLine(http.StatusOK)}
+	notFound := Propstat{Status: StatusLine(http.StatusNotFound)}
+
+	for _, name := range pn.Names {
+		value, ok, err := s.findProp(ctx, m, res, name, pn)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			found.Props = append(found.Props, property{name: s.prefixedName(name), inner: value})
+		} else {
+			notFound.Props = append(notFound.Props, property{name: s.prefixedName(name)})
+		}
+	}
+
+	result := []Propstat{}
+	if len(found.Props) > 0 {
+		result = append(result, found)
+	}
+	if len(notFound.Props) > 0 {
+		result = append(result, notFound)
+	}
+
+	return result, nil
+}
+
+// findProp returns the inner XML of a property. It returns false if the
+// property is not defined for this kind of resource.
+func (s *Server) findProp(ctx context.Context, m *Mount, res *Resource, name xml.Name, pn *PropNames) (string, bool, error) {
+	cfg := s.Collection.Config()
+
+	switch {
+	case name == propResourceType:
+		switch res.Kind {
+		case KindHome:
+			return "<D:collection/>", true, nil
+		case KindCollection:
+			return "<D:collection/>" + cfg.ResourceType, true, nil
+		default:
+			return "", true, nil
+		}
+	case name == propDisplayName && res.Kind == KindHome:
+		return Escape(m.user.Username()), true, nil
+	case name == propDisplayName && res.Kind == KindCollection:
+		return Escape(res.Info.Name()), true, nil
+	case name == propCurrentPrincipal:
+		return "<D:href>" + Escape(EscapeHref(s.homeHref())) + "</D:href>", true, nil
+	case (name == propPrincipalURL || name == xml.Name{Space: cfg.Namespace, Local: cfg.HomeSet}) && res.Kind == KindHome:
+		return "<D:href>" + Escape(EscapeHref(s.homeHref())) + "</D:href>", true, nil
+	case name == propPrivilegeSet:
+		if m.writable {
+			return "<D:privilege><D:read/></D:privilege><D:privilege><D:all/></D:privilege>", true, nil
+		}
+		return "<D:privilege><D:read/></D:privilege>", true, nil
+	case name == propSupportedReports && res.Kind == KindCollection:
+		var buf strings.Builder
+		for _, report := range cfg.Reports {
+			buf.WriteString("<D:supported-report><D:report><C:" + report + "/></D:report></D:supported-report>")
+		}
+		buf.WriteString("<D:supported-report><D:report><D:sync-collection/></D:report></D:supported-report>")
+		return buf.String(), true, nil
+	case (name == propSyncToken || name == propCTag) && res.Kind == KindCollection:
+		token, err := s.syncToken(ctx, res.Info)
+		if err != nil {
+			return "", false, err
+		}
+		return Escape(token), true, nil
+	case name == xml.Name{Space: cfg.Namespace, Local: "max-resource-size"} && res.Kind == KindCollection:
+		return strconv.Itoa(MaxResourceSize), true, nil
+	case res.Kind == KindCollection:
+		for _, prop := range cfg.Props {
+			if prop.Name == name {
+				return prop.Inner, true, nil
+			}
+		}
+	case name == propGetETag && res.Kind == KindObject:
+		return Escape(res.Info.ETag()), true, nil
+	case name == propGetContentType && res.Kind == KindObject:
+		return cfg.ContentType, true, nil
+	case name == propGetContentLength && res.Kind == KindObject:
+		return strconv.FormatUint(res.Info.Size(), 10), true, nil
+	case name == propGetLastModified && res.Kind == KindObject:
+		return res.Info.LastModifiedAt().UTC().Format(http.TimeFormat), true, nil
+	case name == xml.Name{Space: cfg.Namespace, Local: cfg.DataProp} && res.Kind == KindObject:
+		content, err := s.LoadContent(ctx, res)
+		if err != nil {
+			return "", false, err
+		}
+		return Escape(string(s.Collection.Data(content, pn))), true, nil
+	}
+
+	return "", false, nil
+}
+
+// LoadContent returns the object content, it's downloaded only once.
+func (s *Server) LoadContent(ctx context.Context, res *Resource) ([]byte, error) {
+	if res.content != nil {
+		return res.content, nil
+	}
+
+	f, err := s.FileSystem.Download(ctx, res.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the object: %w", err)
+	}
+	defer f.Close()
+
+	res.content, err = io.ReadAll(io.LimitReader(f, MaxResourceSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the object: %w", err)
+	}
+
+	return res.content, nil
+}
+
+func (s *Server) syncToken(ctx context.Context, collection *dfs.INode) (string, error) {
+	token, err := s.Sync.GetToken(ctx, collection.ID())
+	if err != nil {
+		return "", fmt.Errorf("failed to GetToken: %w", err)
+	}
+
+	return syncTokenPrefix + strconv.FormatInt(token, 10), nil
+}
+
+func parseSyncToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, ok := strings.CutPrefix(token, syncTokenPrefix)
+	if !ok {
+		return 0, errInvalidSyncToken
+	}
+
+	res, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || res < 0 {
+		return 0, errInvalidSyncToken
+	}
+
+	return res, nil
+}
+
+// Escape escapes the text to use it inside an XML element.
+func Escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func (s *Server) handlePropfind(w http.ResponseWriter, r *http.Request, m *Mount, t *target) (int, error) {
+	ctx := r.Context()
+
+	depth := 1
+	switch r.Header.Get("Depth") {
+	case "0":
+		depth = 0
+	case "1":
+	default:
+		s.writeError(w, http.StatusForbidden, xml.Name{Space: NamespaceDAV, Local: "propfind-finite-depth"})
+		return 0, errInvalidDepth
+	}
+
+	var pf propfind
+	err := xml.NewDecoder(r.Body).Decode(&pf)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return http.StatusBadRequest, ErrInvalidRequest
+	}
+
+	res, status, err := s.getResource(ctx, m, t)
+	if err != nil {
+		return status, err
+	}
+
+	mw := s.newMultistatusWriter(w)
+
+	writeResource := func(res *Resource) error {
+		if pf.Propname != nil {
+			pstat := Propstat{Status: StatusLine(http.StatusOK)}
+			for _, name := range s.allProps(res.Kind) {
+				pstat.Props = append(pstat.Props, property{name: s.prefixedName(name)})
+			}
+
+			return mw.Write(&Response{Href: EscapeHref(res.Href), Propstats: []Propstat{pstat}})
+		}
+
+		pstats, err := s.Propstats(ctx, m, res, pf.Prop)
+		if err != nil {
+			return err
+		}
+
+		return mw.Write(&Response{Href: EscapeHref(res.Href), Propstats: pstats})
+	}
+
+	err = writeResource(res)
+	if err == nil && depth == 1 && res.Kind != KindObject {
+		err = s.WalkChildren(ctx, m, res, writeResource)
+	}
+
+	closeErr := mw.close("")
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if closeErr != nil {
+		return http.StatusInternalServerError, closeErr
+	}
+
+	return 0, nil
+}
+
+// getResource returns the resource targeted by the request. The home is
+// created with its default collection on the first access.
+func (s *Server) getResource(ctx context.Context, m *Mount, t *target) (*Resource, int, error) {
+	switch {
+	case t.collection == "":
+		info, err := s.ensureHome(ctx, m)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		if info == nil {
+			return nil, http.StatusNotFound, errs.ErrNotFound
+		}
+
+		return &Resource{Kind: KindHome, Href: s.homeHref(), path: m.home, Info: info}, http.StatusOK, nil
+	case t.object == "":
+		info, status, err := s.getCollection(ctx, m, t.collection)
+		if err != nil {
+			return nil, status, err
+		}
+
+		return &Resource{Kind: KindCollection, Href: s.collectionHref(t.collection), path: s.collectionPath(m, t.collection), Info: info}, http.StatusOK, nil
+	default:
+		objectPath := s.objectPath(m, t.collection, t.object)
+		info, err := s.FileSystem.Get(ctx, objectPath)
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		if info.IsDir() {
+			return nil, http.StatusNotFound, errNotAnObject
+		}
+
+		return &Resource{Kind: KindObject, Href: s.objectHref(t.collection, t.object), path: objectPath, Info: info}, http.StatusOK, nil
+	}
+}
+
+// WalkChildren calls fn for each collection of the home or for each object
+// of a collection. The folders are read by pages of batchSize children.
+func (s *Server) WalkChildren(ctx context.Context, m *Mount, parent *Resource, fn func(res *Resource) error) error {
+	paginateCmd := sqlstorage.PaginateCmd{
+		StartAfter: map[string]string{"name": ""},
+		Limit:      batchSize,
+	}
+
+	for {
+		children, err := s.FileSystem.ListDir(ctx, parent.path, &paginateCmd)
+		if err != nil {
+			return fmt.Errorf("failed to ListDir: %w", err)
+		}
+
+		for i := range children {
+			child := &children[i]
+
+			var res *Resource
+			switch {
+			case parent.Kind == KindHome && child.IsDir():
+				res = &Resource{Kind: KindCollection, Href: s.collectionHref(child.Name()), path: s.collectionPath(m, child.Name()), Info: child}
+			case parent.Kind == KindCollection && !child.IsDir():
+				res = &Resource{Kind: KindObject, Href: s.objectHref(parent.Info.Name(), child.Name()), path: s.objectPath(m, parent.Info.Name(), child.Name()), Info: child}
+			default:
+				// Only the folders are collections and only the files are
+				// objects.
+				continue
+			}
+
+			err = fn(res)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(children) < batchSize {
+			return nil
+		}
+
+		paginateCmd.StartAfter["name"] = children[len(children)-1].Name()
+	}
+}
@@ -0,0 +1,190 @@
+package davcol
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/theduckcompany/duckcloud/internal/service/davsync"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+)
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request, m *Mount, t *target) (int, error) {
+	if t.collection == "" || t.object != "" {
+		return http.StatusMethodNotAllowed, errUnsupportedReport
+	}
+
+	ctx := r.Context()
+
+	report, err := s.readReport(r.Body)
+	if errors.Is(err, errUnsupportedReport) {
+		s.writeError(w, http.StatusForbidden, xml.Name{Space: NamespaceDAV, Local: "supported-report"})
+		return 0, err
+	}
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	collection, status, err := s.getResource(ctx, m, t)
+	if err != nil {
+		return status, err
+	}
+
+	mw := s.newMultistatusWriter(w)
+	var syncToken string
+
+	switch report := report.(type) {
+	case *syncCollection:
+		syncToken, err = s.reportSync(ctx, mw, m, collection, report)
+	default:
+		err = s.Collection.Report(ctx, s, mw, m, collection, report)
+	}
+
+	if mw.enc == nil {
+		// Nothing is sent yet, the errors can have their own status.
+		var condErr *ConditionError
+		switch {
+		case errors.As(err, &condErr):
+			s.writeError(w, http.StatusForbidden, condErr.Name)
+			return 0, err
+		case errors.Is(err, errInvalidSyncToken):
+			s.writeError(w, http.StatusForbidden, xml.Name{Space: NamespaceDAV, Local: "valid-sync-token"})
+			return 0, err
+		case errors.Is(err, ErrInvalidRequest):
+			return http.StatusBadRequest, err
+		}
+	}
+
+	closeErr := mw.close(syncToken)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if closeErr != nil {
+		return http.StatusInternalServerError, closeErr
+	}
+
+	return 0, nil
+}
+
+// ReportMultiget writes the objects listed by their href (RFC 6352 section
+// 8.7 and RFC 4791 section 7.9).
+func (s *Server) ReportMultiget(ctx context.Context, mw *MultistatusWriter, m *Mount, collection *Resource, hrefs []string, pn *PropNames) error {
+	for _, href := range hrefs {
+		res, err := s.resolveObjectHref(ctx, m, collection, href)
+		if errors.Is(err, errs.ErrNotFound) || errors.Is(err, errInvalidPath) {
+			err = mw.Write(&Response{Href: href, Status: StatusLine(http.StatusNotFound)})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		pstats, err := s.Propstats(ctx, m, res, pn)
+		if err != nil {
+			return err
+		}
+
+		err = mw.Write(&Response{Href: EscapeHref(res.Href), Propstats: pstats})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reportSync writes the objects modified or deleted since the given token
+// (RFC 6578). All the objects are returned without token. It returns the
+// new sync token.
+func (s *Server) reportSync(ctx context.Context, mw *MultistatusWriter, m *Mount, collection *Resource, report *syncCollection) (string, error) {
+	since, err := parseSyncToken(report.SyncToken)
+	if err != nil {
+		return "", err
+	}
+
+	// The token is read before the changes so a change made during the
+	// report is returned again by the next one.
+	syncToken, err := s.syncToken(ctx, collection.Info)
+	if err != nil {
+		return "", err
+	}
+
+	if report.SyncToken == "" {
+		err = s.WalkChildren(ctx, m, collection, func(res *Resource) error {
+			pstats, err := s.Propstats(ctx, m, res, report.Prop)
+			if err != nil {
+				return err
+			}
+
+			return mw.Write(&Response{Href: EscapeHref(res.Href), Propstats: pstats})
+		})
+
+		return syncToken, err
+	}
+
+	changes, err := s.Sync.GetChangesSince(ctx, collection.Info.ID(), since)
+	if errors.Is(err, davsync.ErrInvalidToken) {
+		return "", errInvalidSyncToken
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to GetChangesSince: %w", err)
+	}
+
+	for _, change := range changes {
+		href := s.objectHref(collection.Info.Name(), change.Name())
+
+		var res *Resource
+		if !change.IsDeleted() {
+			res, _, err = s.getResource(ctx, m, &target{collection: collection.Info.Name(), object: change.Name()})
+			if err != nil && !errors.Is(err, errs.ErrNotFound) && !errors.Is(err, errNotAnObject) {
+				return "", err
+			}
+		}
+
+		if res == nil {
+			err = mw.Write(&Response{Href: EscapeHref(href), Status: StatusLine(http.StatusNotFound)})
+		} else {
+			var pstats []Propstat
+			pstats, err = s.Propstats(ctx, m, res, report.Prop)
+			if err == nil {
+				err = mw.Write(&Response{Href: EscapeHref(href), Propstats: pstats})
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return syncToken, nil
+}
+
+// resolveObjectHref returns the object of the collection pointed by href.
+func (s *Server) resolveObjectHref(ctx context.Context, m *Mount, collection *Resource, href string) (*Resource, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil, errInvalidPath
+	}
+
+	t, err := s.parsePath(u.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.collection != collection.Info.Name() || t.object == "" {
+		return nil, errInvalidPath
+	}
+
+	res, _, err := s.getResource(ctx, m, t)
+	if errors.Is(err, errNotAnObject) {
+		return nil, errs.ErrNotFound
+	}
+
+	return res, err
+}
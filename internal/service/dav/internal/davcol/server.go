@@ -0,0 +1,786 @@
+// Package davcol provides the parts shared by the CardDAV and CalDAV
+// servers: the authentication, the home folder of the sessions, the
+// properties and the multistatus responses.
+//
+// The collections (the address books or the calendars) are the folders
+// inside the home folder of the session space, each resource being a file
+// inside them. The sync tokens track the changes of the files whatever the
+// client used to make them: CardDAV, CalDAV, WebDAV or the browser. The
+// changes older than davsync.ChangesRetention are pruned and the clients
+// using an older token must resync the whole collection.
+package davcol
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
+	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/davsync"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+)
+
+// MaxResourceSize is the maximum size of a resource.
+const MaxResourceSize = 1 << 20
+
+// http://www.webdav.org/specs/rfc4918.html#status.code.extensions.to.http11
+const (
+	StatusMulti               = 207
+	StatusInsufficientStorage = 507
+)
+
+func StatusText(code int) string {
+	switch code {
+	case StatusMulti:
+		return "Multi-Status"
+	case StatusInsufficientStorage:
+		return "Insufficient Storage"
+	}
+	return http.StatusText(code)
+}
+
+var (
+	errAllSpacesSession   = errors.New("davcol: the sessions with all the spaces are not supported")
+	errCollectionExists   = errors.New("davcol: collection already exists")
+	errReadOnly           = errors.New("davcol: read only collections")
+	errInvalidPath        = errors.New("davcol: invalid path")
+	errNotAnObject        = errors.New("davcol: not an object resource")
+	errMissingCollection  = errors.New("davcol: unknown collection")
+	errPrefixMismatch     = errors.New("davcol: prefix mismatch")
+	errPreconditionFailed = errors.New("davcol: precondition failed")
+	errUnsupportedReport  = errors.New("davcol: unsupported report")
+	errInvalidSyncToken   = errors.New("davcol: invalid sync token")
+	errInvalidDepth       = errors.New("davcol: invalid depth")
+	errResourceTooLarge   = errors.New("davcol: resource too large")
+	errUIDConflict        = errors.New("davcol: uid already used in the collection")
+)
+
+// Config describes the collections served and their resources. The
+// element names are inside Namespace.
+type Config struct {
+	// Realm is the basic authentication realm.
+	Realm string
+	// HomeFolder is the folder containing the collections, at the root of
+	// the session space.
+	HomeFolder string
+	// DefaultCollection is created inside an empty home folder.
+	DefaultCollection string
+	// Namespace is the namespace of the collections elements, bound to the
+	// "C" prefix.
+	Namespace string
+	// Class is the compliance class advertised by OPTIONS, after the WebDAV
+	// classes.
+	Class string
+	// MkcolMethod is an extra method creating the collections, like
+	// MKCALENDAR. It fails with resource-must-be-null instead of a 405
+	// status when the collection exists.
+	MkcolMethod string
+	// ContentType is the content type of the resources.
+	ContentType string
+	// MediaTypes are the media types accepted by PUT.
+	MediaTypes []string
+	// ResourceType is the resourcetype of the collections, after the
+	// collection element.
+	ResourceType string
+	// HomeSet is the property pointing to the home.
+	HomeSet string
+	// DataProp is the property containing the whole resource.
+	DataProp string
+	// SupportedData is the precondition failed by the unsupported media
+	// types.
+	SupportedData string
+	// ValidData is the precondition failed by the invalid resources.
+	ValidData string
+	// Reports are the reports supported by the collections, in addition to
+	// sync-collection.
+	Reports []string
+	// Props are the constant properties of the collections. Their inner XML
+	// must use the namespace prefixes declared on the multistatus root.
+	Props []Prop
+}
+
+// Prop is a constant property.
+type Prop struct {
+	Name  xml.Name
+	Inner string
+}
+
+// Collection is the kind of collections served: the address books or the
+// calendars.
+type Collection interface {
+	Config() *Config
+	// Validate checks the content of a resource before its upload. The
+	// *ConditionError are answered with their condition, any other error
+	// with the ValidData precondition.
+	Validate(content []byte) error
+	// UID returns the UID of a valid resource, unique inside its collection.
+	// It returns an empty string for an invalid resource.
+	UID(content []byte) string
+	// Data returns the value of the data property. pn.Data contains the
+	// parts of the resource asked by the client.
+	Data(content []byte, pn *PropNames) []byte
+	// NewReport returns a pointer to the struct decoding the report with
+	// the given root element, or nil for the unsupported reports.
+	NewReport(root xml.Name) any
+	// Report writes the responses of a report decoded by NewReport.
+	Report(ctx context.Context, s *Server, mw *MultistatusWriter, m *Mount, collection *Resource, report any) error
+}
+
+type Server struct {
+	// FileSystem stores the resources.
+	FileSystem dfs.Service
+	// Sessions handle the users sessions used for authentification.
+	Sessions davsessions.Service
+	Spaces   spaces.Service
+	Users    users.Service
+	// Sync returns the changes of the collections for the sync-collection
+	// reports.
+	Sync davsync.Service
+	// Locks are the WebDAV locks. A resource locked by a WebDAV client
+	// can't be modified without one of its lock tokens.
+	Locks davlocks.Service
+	// Logger is an optional error logger. If non-nil, it will be called
+	// for all HTTP requests.
+	Logger func(*http.Request, error)
+	// Prefix is the URL path prefix of the home.
+	Prefix     string
+	Collection Collection
+}
+
+// Mount is the home folder of a session.
+type Mount struct {
+	user     *users.User
+	home     *dfs.PathCmd
+	writable bool
+}
+
+// target is the resource targeted by a request. The collection and the
+// object are empty for the home.
+type target struct {
+	collection string
+	object     string
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cfg := s.Collection.Config()
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		w.Header().Add("WWW-Authenticate", `Basic realm="`+cfg.Realm+`"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	session, err := s.Sessions.Authenticate(r.Context(), username, secret.NewText(password), remoteIP(r))
+	if errors.Is(err, davsessions.ErrInvalidCredentials) || errors.Is(err, davsessions.ErrSessionExpired) {
+		w.Header().Add("WWW-Authenticate", `Basic realm="`+cfg.Realm+`"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	m, status, err := s.getMount(r, session)
+	if err == nil && !m.writable && s.isWriteMethod(r.Method) {
+		status, err = http.StatusForbidden, errReadOnly
+	}
+	if err != nil {
+		s.writeStatus(w, r, status, err)
+		return
+	}
+
+	t, err := s.parsePath(r.URL.Path)
+	if err != nil {
+		s.writeStatus(w, r, http.StatusNotFound, err)
+		return
+	}
+
+	switch {
+	case r.Method == "OPTIONS":
+		status, err = s.handleOptions(w, t)
+	case r.Method == "GET", r.Method == "HEAD":
+		status, err = s.handleGet(w, r, m, t)
+	case r.Method == "PUT":
+		status, err = s.handlePut(w, r, m, t)
+	case r.Method == "DELETE":
+		status, err = s.handleDelete(w, r, m, t)
+	case r.Method == "MKCOL", r.Method == cfg.MkcolMethod && cfg.MkcolMethod != "":
+		status, err = s.handleMkcol(w, r, m, t)
+	case r.Method == "PROPFIND":
+		status, err = s.handlePropfind(w, r, m, t)
+	case r.Method == "REPORT":
+		status, err = s.handleReport(w, r, m, t)
+	default:
+		status = http.StatusMethodNotAllowed
+	}
+
+	s.writeStatus(w, r, status, err)
+}
+
+// writeStatus writes the status of the requests not written by their
+// handler and logs the error.
+func (s *Server) writeStatus(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if status != 0 {
+		w.WriteHeader(status)
+		if status != http.StatusNoContent {
+			w.Write([]byte(StatusText(status)))
+		}
+	}
+	if s.Logger != nil {
+		s.Logger(r, err)
+	}
+}
+
+// getMount returns the home folder of the session. The collections are
+// read only for the read only sessions and roles.
+func (s *Server) getMount(r *http.Request, session *davsessions.DavSession) (*Mount, int, error) {
+	ctx := r.Context()
+
+	user, err := s.Users.GetByID(ctx, session.UserID())
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if session.AllSpaces() {
+		return nil, http.StatusForbidden, errAllSpacesSession
+	}
+
+	var root *dfs.PathCmd
+	var writable bool
+	if session.GrantID() != nil {
+		grant, err := s.FileSystem.GetUserGrant(ctx, session.UserID(), *session.GrantID())
+		if errors.Is(err, errs.ErrNotFound) {
+			// The grant have been revoked.
+			return nil, http.StatusForbidden, err
+		}
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		root, err = s.FileSystem.GetGrantPath(ctx, grant)
+		if errors.Is(err, errs.ErrNotFound) {
+			// The shared folder is inside the trash.
+			return nil, http.StatusNotFound, err
+		}
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		writable = grant.Permission().CanWrite()
+	} else {
+		space, err := s.Spaces.GetUserSpace(ctx, session.UserID(), session.SpaceID())
+		if errors.Is(err, errs.ErrUnauthorized) {
+			return nil, http.StatusForbidden, err
+		}
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		role, err := s.Spaces.GetUserRole(ctx, session.UserID(), space.ID())
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		root = dfs.NewPathCmd(space, "/")
+		writable = role.CanWrite()
+	}
+
+	return &Mount{
+		user:     user,
+		home:     dfs.NewPathCmd(root.Space(), path.Join(root.Path(), session.RootPath(), s.Collection.Config().HomeFolder)),
+		writable: writable && !session.ReadOnly(),
+	}, http.StatusOK, nil
+}
+
+// parsePath splits the url path into the collection and the object names.
+func (s *Server) parsePath(urlPath string) (*target, error) {
+	p, ok := strings.CutPrefix(urlPath, s.Prefix)
+	if !ok {
+		return nil, errPrefixMismatch
+	}
+
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return &target{}, nil
+	}
+
+	parts := strings.Split(p, "/")
+	if len(parts) > 2 {
+		return nil, errInvalidPath
+	}
+
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." {
+			return nil, errInvalidPath
+		}
+	}
+
+	res := target{collection: parts[0]}
+	if len(parts) == 2 {
+		res.object = parts[1]
+	}
+
+	return &res, nil
+}
+
+func (s *Server) collectionPath(m *Mount, collection string) *dfs.PathCmd {
+	return dfs.NewPathCmd(m.home.Space(), path.Join(m.home.Path(), collection))
+}
+
+func (s *Server) objectPath(m *Mount, collection, object string) *dfs.PathCmd {
+	return dfs.NewPathCmd(m.home.Space(), path.Join(m.home.Path(), collection, object))
+}
+
+func (s *Server) homeHref() string {
+	return s.Prefix + "/"
+}
+
+func (s *Server) collectionHref(collection string) string {
+	return path.Join(s.Prefix, collection) + "/"
+}
+
+func (s *Server) objectHref(collection, object string) string {
+	return path.Join(s.Prefix, collection, object)
+}
+
+// getCollection returns the collection folder.
+func (s *Server) getCollection(ctx context.Context, m *Mount, collection string) (*dfs.INode, int, error) {
+	info, err := s.FileSystem.Get(ctx, s.collectionPath(m, collection))
+	if errors.Is(err, errs.ErrNotFound) {
+		return nil, http.StatusNotFound, err
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if !info.IsDir() {
+		return nil, http.StatusNotFound, errMissingCollection
+	}
+
+	return info, http.StatusOK, nil
+}
+
+func (s *Server) handleOptions(w http.ResponseWriter, t *target) (int, error) {
+	cfg := s.Collection.Config()
+
+	allow := "OPTIONS, PROPFIND"
+	switch {
+	case t.object != "":
+		allow = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND"
+	case t.collection != "" && cfg.MkcolMethod != "":
+		allow = "OPTIONS, DELETE, MKCOL, " + cfg.MkcolMethod + ", PROPFIND, REPORT"
+	case t.collection != "":
+		allow = "OPTIONS, DELETE, MKCOL, PROPFIND, REPORT"
+	}
+
+	w.Header().Set("Allow", allow)
+	w.Header().Set("DAV", "1, 3, "+cfg.Class)
+	return 0, nil
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request, m *Mount, t *target) (int, error) {
+	if t.object == "" {
+		return http.StatusMethodNotAllowed, errNotAnObject
+	}
+
+	ctx := r.Context()
+	objectPath := s.objectPath(m, t.collection, t.object)
+	info, err := s.FileSystem.Get(ctx, objectPath)
+	if err != nil {
+		return http.StatusNotFound, err
+	}
+
+	if info.IsDir() {
+		return http.StatusMethodNotAllowed, errNotAnObject
+	}
+
+	f, err := s.FileSystem.Download(ctx, objectPath)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	defer f.Close()
+
+	w.Header().Set("ETag", info.ETag())
+	w.Header().Set("Content-Type", s.Collection.Config().ContentType)
+	http.ServeContent(w, r, t.object, info.LastModifiedAt(), f)
+	return 0, nil
+}
+
+func (s *Server) handlePut(w http.ResponseWriter, r *http.Request, m *Mount, t *target) (int, error) {
+	if t.object == "" {
+		return http.StatusMethodNotAllowed, errNotAnObject
+	}
+
+	ctx := r.Context()
+	cfg := s.Collection.Config()
+
+	collection, status, err := s.getCollection(ctx, m, t.collection)
+	if err != nil {
+		if status == http.StatusNotFound {
+			return http.StatusConflict, err
+		}
+		return status, err
+	}
+
+	objectPath := s.objectPath(m, t.collection, t.object)
+	existing, status, err := s.checkPreconditions(ctx, r, objectPath)
+	if err != nil {
+		return status, err
+	}
+
+	status, err = s.confirmLocks(r, m, objectPath)
+	if err != nil {
+		return status, err
+	}
+
+	if existing != nil && existing.IsDir() {
+		return http.StatusMethodNotAllowed, errNotAnObject
+	}
+
+	if hdr := r.Header.Get("Content-Type"); hdr != "" {
+		mediaType, _, err := mime.ParseMediaType(hdr)
+		if err != nil || !slices.Contains(cfg.MediaTypes, mediaType) {
+			s.writeError(w, http.StatusForbidden, xml.Name{Space: cfg.Namespace, Local: cfg.SupportedData})
+			return 0, fmt.Errorf("unsupported content type %q", hdr)
+		}
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r.Body, MaxResourceSize+1))
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if len(content) > MaxResourceSize {
+		s.writeError(w, http.StatusForbidden, xml.Name{Space: cfg.Namespace, Local: "max-resource-size"})
+		return 0, errResourceTooLarge
+	}
+
+	err = s.Collection.Validate(content)
+	if err != nil {
+		var condErr *ConditionError
+		if !errors.As(err, &condErr) {
+			condErr = &ConditionError{Name: xml.Name{Space: cfg.Namespace, Local: cfg.ValidData}, Err: err}
+		}
+
+		s.writeError(w, http.StatusForbidden, condErr.Name)
+		return 0, err
+	}
+
+	conflict, err := s.findUIDConflict(ctx, m, collection, t.object, s.Collection.UID(content))
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if conflict != nil {
+		s.writeHrefError(w, http.StatusForbidden, xml.Name{Space: cfg.Namespace, Local: "no-uid-conflict"}, conflict.Href)
+		return 0, errUIDConflict
+	}
+
+	err = s.FileSystem.Upload(ctx, &dfs.UploadCmd{
+		Path:       objectPath,
+		Content:    bytes.NewReader(content),
+		UploadedBy: m.user,
+	})
+	if errors.Is(err, dfs.ErrQuotaExceeded) {
+		return StatusInsufficientStorage, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	info, err := s.FileSystem.Get(ctx, objectPath)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	w.Header().Set("ETag", info.ETag())
+	if existing != nil {
+		return http.StatusNoContent, nil
+	}
+
+	return http.StatusCreated, nil
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request, m *Mount, t *target) (int, error) {
+	if t.collection == "" {
+		return http.StatusMethodNotAllowed, errInvalidPath
+	}
+
+	ctx := r.Context()
+
+	_, status, err := s.getCollection(ctx, m, t.collection)
+	if err != nil {
+		return status, err
+	}
+
+	if t.object == "" {
+		status, err = s.confirmLocks(r, m, s.collectionPath(m, t.collection))
+		if err != nil {
+			return status, err
+		}
+
+		// The whole collection is moved to the trash.
+		err = s.FileSystem.Remove(ctx, m.user, s.collectionPath(m, t.collection))
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+
+		return http.StatusNoContent, nil
+	}
+
+	objectPath := s.objectPath(m, t.collection, t.object)
+	info, status, err := s.checkPreconditions(ctx, r, objectPath)
+	if err != nil {
+		return status, err
+	}
+
+	if info == nil {
+		return http.StatusNotFound, errs.ErrNotFound
+	}
+
+	status, err = s.confirmLocks(r, m, objectPath)
+	if err != nil {
+		return status, err
+	}
+
+	err = s.FileSystem.Remove(ctx, m.user, objectPath)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusNoContent, nil
+}
+
+// handleMkcol creates a new collection with MKCOL or with the MkcolMethod
+// of the config. The properties of the extended MKCOL body (RFC 5689) are
+// ignored and the collection is named after its url.
+func (s *Server) handleMkcol(w http.ResponseWriter, r *http.Request, m *Mount, t *target) (int, error) {
+	if t.collection == "" || t.object != "" {
+		return http.StatusMethodNotAllowed, errInvalidPath
+	}
+
+	ctx := r.Context()
+	collectionPath := s.collectionPath(m, t.collection)
+
+	_, err := s.FileSystem.Get(ctx, collectionPath)
+	if err == nil {
+		if r.Method == "MKCOL" {
+			return http.StatusMethodNotAllowed, errCollectionExists
+		}
+
+		s.writeError(w, http.StatusForbidden, xml.Name{Space: NamespaceDAV, Local: "resource-must-be-null"})
+		return 0, errCollectionExists
+	}
+	if !errors.Is(err, errs.ErrNotFound) {
+		return http.StatusInternalServerError, err
+	}
+
+	_, err = s.FileSystem.CreateDir(ctx, &dfs.CreateDirCmd{
+		Path:      collectionPath,
+		CreatedBy: m.user,
+	})
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusCreated, nil
+}
+
+// ensureHome creates the home folder with a default collection if it
+// doesn't exist yet. It returns nil if the home is missing and can't be
+// created.
+func (s *Server) ensureHome(ctx context.Context, m *Mount) (*dfs.INode, error) {
+	home, err := s.FileSystem.Get(ctx, m.home)
+	if err == nil {
+		return home, nil
+	}
+
+	if !errors.Is(err, errs.ErrNotFound) {
+		return nil, err
+	}
+
+	if !m.writable {
+		return nil, nil
+	}
+
+	_, err = s.FileSystem.CreateDir(ctx, &dfs.CreateDirCmd{
+		Path:      s.collectionPath(m, s.Collection.Config().DefaultCollection),
+		CreatedBy: m.user,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the default collection: %w", err)
+	}
+
+	return s.FileSystem.Get(ctx, m.home)
+}
+
+// checkPreconditions evaluates the If-Match and If-None-Match headers
+// against the resource at pathCmd. It returns the resource or nil if it
+// doesn't exist yet.
+func (s *Server) checkPreconditions(ctx context.Context, r *http.Request, pathCmd *dfs.PathCmd) (*dfs.INode, int, error) {
+	info, err := s.FileSystem.Get(ctx, pathCmd)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if hdr := r.Header.Get("If-Match"); hdr != "" {
+		if info == nil || !matchETags(hdr, info.ETag()) {
+			return nil, http.StatusPreconditionFailed, errPreconditionFailed
+		}
+	}
+
+	if hdr := r.Header.Get("If-None-Match"); hdr != "" && info != nil {
+		if matchETags(hdr, info.ETag()) {
+			return nil, http.StatusPreconditionFailed, errPreconditionFailed
+		}
+	}
+
+	return info, 0, nil
+}
+
+// confirmLocks checks that the resource at pathCmd, or one of its
+// descendants, is not locked by an other client. The lock tokens submitted
+// with the If header are accepted but its conditions are not evaluated.
+func (s *Server) confirmLocks(r *http.Request, m *Mount, pathCmd *dfs.PathCmd) (int, error) {
+	err := s.Locks.Confirm(r.Context(), &davlocks.ConfirmCmd{
+		User:   m.user,
+		Path:   pathCmd,
+		Tokens: submittedTokens(r.Header.Get("If")),
+	})
+	if errors.Is(err, davlocks.ErrLocked) {
+		return http.StatusLocked, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return 0, nil
+}
+
+// submittedTokens returns the Coded-URLs found inside the lists of the If
+// header (RFC 4918 section 10.4). The resource tags, outside of the lists,
+// are skipped.
+func submittedTokens(hdr string) []string {
+	var res []string
+
+	inList := false
+	for hdr != "" {
+		switch hdr[0] {
+		case '(':
+			inList = true
+		case ')':
+			inList = false
+		case '[':
+			// The entity tags can't contain a Coded-URL.
+			end := strings.IndexByte(hdr, ']')
+			if end < 0 {
+				return res
+			}
+			hdr = hdr[end:]
+		case '<':
+			end := strings.IndexByte(hdr, '>')
+			if end < 0 {
+				return res
+			}
+			if inList {
+				res = append(res, hdr[1:end])
+			}
+			hdr = hdr[end:]
+		}
+
+		hdr = hdr[1:]
+	}
+
+	return res
+}
+
+// findUIDConflict returns the object of the collection, other than the
+// given one, having the given UID. It returns nil if there is none.
+func (s *Server) findUIDConflict(ctx context.Context, m *Mount, collection *dfs.INode, object string, uid string) (*Resource, error) {
+	if uid == "" {
+		return nil, nil
+	}
+
+	parent := &Resource{
+		Kind: KindCollection,
+		Href: s.collectionHref(collection.Name()),
+		path: s.collectionPath(m, collection.Name()),
+		Info: collection,
+	}
+
+	var res *Resource
+	err := s.WalkChildren(ctx, m, parent, func(child *Resource) error {
+		if res != nil || child.Info.Name() == object {
+			return nil
+		}
+
+		content, err := s.LoadContent(ctx, child)
+		if err != nil {
+			return err
+		}
+
+		if s.Collection.UID(content) == uid {
+			res = child
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// matchETags returns true if the etag is part of the comma separated list
+// of entity tags given by hdr. The "*" value matches any existing resource.
+func matchETags(hdr string, etag string) bool {
+	if strings.TrimSpace(hdr) == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(hdr, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remoteIP returns the address of the client without its port.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// isWriteMethod returns true for the methods modifying the collections.
+func (s *Server) isWriteMethod(method string) bool {
+	switch method {
+	case "PUT", "DELETE", "MKCOL", "PROPPATCH":
+		return true
+	}
+
+	mkcolMethod := s.Collection.Config().MkcolMethod
+	return mkcolMethod != "" && method == mkcolMethod
+}
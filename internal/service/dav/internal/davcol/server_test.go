@@ -0,0 +1,51 @@
+package davcol
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchETags(t *testing.T) {
+	assert.True(t, matchETags("*", `"abc"`))
+	assert.True(t, matchETags(`"foo", W/"abc"`, `"abc"`))
+	assert.False(t, matchETags(`"foo"`, `"abc"`))
+}
+
+func TestSubmittedTokens(t *testing.T) {
+	assert.Nil(t, submittedTokens(""))
+	assert.Equal(t, []string{"urn:uuid:a"}, submittedTokens("(<urn:uuid:a>)"))
+	assert.Equal(t, []string{"urn:uuid:a", "urn:uuid:b"},
+		submittedTokens(`</carddav/Personal/bob.vcf> (<urn:uuid:a> ["etag"]) (Not <urn:uuid:b>)`))
+}
+
+func TestParseSyncToken(t *testing.T) {
+	res, err := parseSyncToken("")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res)
+
+	res, err = parseSyncToken("urn:duckcloud:sync:42")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), res)
+
+	_, err = parseSyncToken("urn:duckcloud:sync:-1")
+	require.ErrorIs(t, err, errInvalidSyncToken)
+
+	_, err = parseSyncToken("invalid")
+	require.ErrorIs(t, err, errInvalidSyncToken)
+}
+
+func TestPropNames(t *testing.T) {
+	var pn PropNames
+	err := xml.Unmarshal([]byte(`<D:prop xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:carddav">`+
+		`<D:getetag/><C:address-data><C:prop name="EMAIL"/><C:prop name="FN"/></C:address-data></D:prop>`), &pn)
+	require.NoError(t, err)
+
+	assert.Equal(t, []xml.Name{
+		{Space: "DAV:", Local: "getetag"},
+		{Space: "urn:ietf:params:xml:ns:carddav", Local: "address-data"},
+	}, pn.Names)
+	assert.Equal(t, []string{"EMAIL", "FN"}, pn.Data)
+}
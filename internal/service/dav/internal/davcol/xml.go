@@ -0,0 +1,279 @@
+package davcol
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// NamespaceDAV is the namespace of the WebDAV elements, bound to the "D"
+	// prefix.
+	NamespaceDAV = "DAV:"
+	// NamespaceCalendarServer is the namespace of the getctag property,
+	// bound to the "CS" prefix.
+	NamespaceCalendarServer = "http://calendarserver.org/ns/"
+)
+
+// ErrInvalidRequest is returned for the request bodies which can't be
+// decoded.
+var ErrInvalidRequest = errors.New("davcol: invalid request body")
+
+// ConditionError is an error answered with a 403 status and the given
+// precondition or postcondition element.
+type ConditionError struct {
+	Name xml.Name
+	Err  error
+}
+
+func (e *ConditionError) Error() string { return e.Err.Error() }
+func (e *ConditionError) Unwrap() error { return e.Err }
+
+// PropNames is the list of the properties asked inside a prop element.
+type PropNames struct {
+	Names []xml.Name
+	// Data contains the names of the prop elements asked inside a property,
+	// like the vCard properties asked by the address-data element. Empty
+	// means the whole resource.
+	Data []string
+}
+
+func (p *PropNames) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch elem := t.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			p.Names = append(p.Names, elem.Name)
+
+			var inner struct {
+				Elems []struct {
+					XMLName xml.Name
+					Name    string `xml:"name,attr"`
+				} `xml:",any"`
+			}
+			err = d.DecodeElement(&inner, &elem)
+			if err != nil {
+				return err
+			}
+
+			for _, child := range inner.Elems {
+				if child.XMLName == (xml.Name{Space: elem.Name.Space, Local: "prop"}) {
+					p.Data = append(p.Data, child.Name)
+				}
+			}
+		}
+	}
+}
+
+// http://www.webdav.org/specs/rfc4918.html#ELEMENT_propfind
+type propfind struct {
+	XMLName  xml.Name   `xml:"DAV: propfind"`
+	Prop     *PropNames `xml:"DAV: prop"`
+	Allprop  *struct{}  `xml:"DAV: allprop"`
+	Propname *struct{}  `xml:"DAV: propname"`
+}
+
+// https://www.rfc-editor.org/rfc/rfc6578#section-6.1
+type syncCollection struct {
+	XMLName   xml.Name   `xml:"DAV: sync-collection"`
+	SyncToken string     `xml:"DAV: sync-token"`
+	SyncLevel string     `xml:"DAV: sync-level"`
+	Prop      *PropNames `xml:"DAV: prop"`
+}
+
+// readReport decodes the report body into the struct matching its root
+// element. The prop elements of the report must be *PropNames.
+func (s *Server) readReport(r io.Reader) (any, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var root struct {
+		XMLName xml.Name
+	}
+	err = xml.Unmarshal(body, &root)
+	if err != nil {
+		return nil, ErrInvalidRequest
+	}
+
+	var res any
+	if root.XMLName == (xml.Name{Space: NamespaceDAV, Local: "sync-collection"}) {
+		res = new(syncCollection)
+	} else {
+		res = s.Collection.NewReport(root.XMLName)
+	}
+
+	if res == nil {
+		return nil, errUnsupportedReport
+	}
+
+	err = xml.Unmarshal(body, res)
+	if err != nil {
+		return nil, ErrInvalidRequest
+	}
+
+	return res, nil
+}
+
+// property is a property value. Its name and its inner XML must use the
+// namespace prefixes declared on the multistatus root.
+type property struct {
+	name  xml.Name
+	inner string
+}
+
+func (p property) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Inner string `xml:",innerxml"`
+	}{p.inner}, xml.StartElement{Name: p.name})
+}
+
+// Propstat groups the properties of a resource with the same status.
+type Propstat struct {
+	Props  []property `xml:"D:prop>_"`
+	Status string     `xml:"D:status"`
+}
+
+// Response is a response of a multistatus.
+//
+// http://www.webdav.org/specs/rfc4918.html#ELEMENT_response
+type Response struct {
+	XMLName             xml.Name   `xml:"D:response"`
+	Href                string     `xml:"D:href"`
+	Propstats           []Propstat `xml:"D:propstat"`
+	Status              string     `xml:"D:status,omitempty"`
+	Error               *XMLError  `xml:"D:error,omitempty"`
+	ResponseDescription string     `xml:"D:responsedescription,omitempty"`
+}
+
+// XMLError is the content of an error element. It must use the namespace
+// prefixes declared on the multistatus root.
+type XMLError struct {
+	Inner string `xml:",innerxml"`
+}
+
+// StatusLine returns the status element value for the status code.
+func StatusLine(status int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", status, StatusText(status))
+}
+
+// EscapeHref escapes the path to use it inside an href element.
+func EscapeHref(p string) string {
+	return (&url.URL{Path: p}).EscapedPath()
+}
+
+// MultistatusWriter streams the responses of a multistatus. The status
+// and the root element are written with the first response.
+type MultistatusWriter struct {
+	w         http.ResponseWriter
+	namespace string
+	enc       *xml.Encoder
+}
+
+// Write writes a response.
+func (w *MultistatusWriter) Write(r *Response) error {
+	err := w.writeHeader()
+	if err != nil {
+		return err
+	}
+
+	return w.enc.Encode(r)
+}
+
+// writeHeader writes the status and the multistatus start element. After
+// the first call, writeHeader becomes a no-op.
+func (w *MultistatusWriter) writeHeader() error {
+	if w.enc != nil {
+		return nil
+	}
+
+	w.w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.w.WriteHeader(StatusMulti)
+	_, err := io.WriteString(w.w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	w.enc = xml.NewEncoder(w.w)
+	return w.enc.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "D:multistatus"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "xmlns:D"}, Value: NamespaceDAV},
+			{Name: xml.Name{Local: "xmlns:C"}, Value: w.namespace},
+			{Name: xml.Name{Local: "xmlns:CS"}, Value: NamespaceCalendarServer},
+		},
+	})
+}
+
+// close ends the multistatus. The sync token is only set for the
+// sync-collection reports.
+func (w *MultistatusWriter) close(syncToken string) error {
+	err := w.writeHeader()
+	if err != nil {
+		return err
+	}
+
+	if syncToken != "" {
+		err = w.enc.EncodeElement(syncToken, xml.StartElement{Name: xml.Name{Local: "D:sync-token"}})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = w.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "D:multistatus"}})
+	if err != nil {
+		return err
+	}
+
+	return w.enc.Flush()
+}
+
+// prefixedName uses the prefixes declared on the multistatus root for the
+// known namespaces. The properties from an another namespace declare their
+// own namespace.
+func (s *Server) prefixedName(name xml.Name) xml.Name {
+	switch name.Space {
+	case NamespaceDAV:
+		return xml.Name{Local: "D:" + name.Local}
+	case s.Collection.Config().Namespace:
+		return xml.Name{Local: "C:" + name.Local}
+	case NamespaceCalendarServer:
+		return xml.Name{Local: "CS:" + name.Local}
+	}
+
+	return name
+}
+
+// writeError writes an error response with the given precondition or
+// postcondition element.
+func (s *Server) writeError(w http.ResponseWriter, status int, condition xml.Name) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `%s<D:error xmlns:D="%s" xmlns:C="%s"><%s/></D:error>`,
+		xml.Header, NamespaceDAV, s.Collection.Config().Namespace, s.prefixedName(condition).Local)
+}
+
+// writeHrefError writes an error response with the given precondition
+// element pointing to the resource at href, like no-uid-conflict.
+func (s *Server) writeHrefError(w http.ResponseWriter, status int, condition xml.Name, href string) {
+	name := s.prefixedName(condition).Local
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `%s<D:error xmlns:D="%s" xmlns:C="%s"><%s><D:href>%s</D:href></%s></D:error>`,
+		xml.Header, NamespaceDAV, s.Collection.Config().Namespace, name, Escape(EscapeHref(href)), name)
+}
+
+func (s *Server) newMultistatusWriter(w http.ResponseWriter) *MultistatusWriter {
+	return &MultistatusWriter{w: w, namespace: s.Collection.Config().Namespace}
+}
@@ -3,9 +3,12 @@ package webdav
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
+	"slices"
 
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
@@ -14,16 +17,11 @@ import (
 
 // copyFiles copies files and/or directories from src to dst.
 //
+// The copy is made by dfs.Service.Copy which reuses the content of the source
+// files instead of downloading and uploading them again.
+//
 // See section 9.8.5 for when various HTTP status codes apply.
-func copyFiles(ctx context.Context, user *users.User, fs dfs.Service, src, dst *dfs.PathCmd, overwrite bool, depth, recursion int) (status int, err error) {
-	if recursion == 1000 {
-		return http.StatusInternalServerError, errRecursionTooDeep
-	}
-	recursion++
-
-	// TODO: section 9.8.3 says that "Note that an infinite-depth COPY of /A/
-	// into /A/B/ could lead to infinite recursion if not handled correctly."
-
+func copyFiles(ctx context.Context, user *users.User, fs dfs.Service, src, dst *dfs.PathCmd, overwrite bool, depth int) (status int, err error) {
 	srcStat, err := fs.Get(ctx, src)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
@@ -44,53 +42,35 @@ func copyFiles(ctx context.Context, user *users.User, fs dfs.Service, src, dst *
 		if err != nil && errors.Is(err, errs.ErrNotFound) && !overwrite {
 			return http.StatusConflict, nil
 		}
-	} else {
-		if !overwrite {
-			return http.StatusPreconditionFailed, os.ErrExist
-		}
-		if err := fs.Remove(ctx, dst); err != nil && !errors.Is(err, errs.ErrNotFound) {
-			return http.StatusForbidden, err
-		}
+	} else if !overwrite {
+		return http.StatusPreconditionFailed, os.ErrExist
 	}
 
-	if srcStat.IsDir() {
-		if _, err := fs.CreateDir(ctx, &dfs.CreateDirCmd{Path: dst, CreatedBy: user}); err != nil {
-			return http.StatusForbidden, err
-		}
-		if depth == infiniteDepth {
-			children, err := fs.ListDir(ctx, src, nil)
-			if err != nil {
+	if srcStat.IsDir() && depth != infiniteDepth {
+		// Section 9.8.3 says that a COPY with "Depth: 0" only copies the collection
+		// and its properties, not the resources identified by its internal
+		// member URLs.
+		if !created {
+			if err := fs.Remove(ctx, user, dst); err != nil && !errors.Is(err, errs.ErrNotFound) {
 				return http.StatusForbidden, err
 			}
-			for _, c := range children {
-				name := c.Name()
-				s := dfs.NewPathCmd(src.Space(), path.Join(src.Path(), name))
-				d := dfs.NewPathCmd(dst.Space(), path.Join(dst.Path(), name))
-				cStatus, cErr := copyFiles(ctx, user, fs, s, d, overwrite, depth, recursion)
-				if cErr != nil {
-					// TODO: MultiStatus.
-					return cStatus, cErr
-				}
-			}
+		}
+
+		if _, err := fs.CreateDir(ctx, &dfs.CreateDirCmd{Path: dst, CreatedBy: user}); err != nil {
+			return http.StatusForbidden, err
 		}
 	} else {
-		reader, err := fs.Download(ctx, src)
-		if err != nil {
-			if errors.Is(err, errs.ErrNotFound) {
-				return http.StatusConflict, err
-			}
+		err = fs.Copy(ctx, &dfs.CopyCmd{Src: src, Dst: dst, CopiedBy: user})
+		if errors.Is(err, dfs.ErrQuotaExceeded) {
+			return http.StatusInsufficientStorage, err
+		}
 
-			return http.StatusInternalServerError, err
+		if errors.Is(err, errs.ErrBadRequest) {
+			return http.StatusForbidden, err
 		}
-		defer reader.Close()
 
-		err = fs.Upload(ctx, &dfs.UploadCmd{
-			Path:       dst,
-			Content:    reader,
-			UploadedBy: user,
-		})
 		if err != nil {
-			return http.StatusForbidden, err
+			return http.StatusInternalServerError, err
 		}
 	}
 
@@ -99,3 +79,91 @@ func copyFiles(ctx context.Context, user *users.User, fs dfs.Service, src, dst *
 	}
 	return http.StatusNoContent, nil
 }
+
+// memberError is the failure of a member of the resource identified by the
+// request URI. Those failures are reported inside a 207 (Multi-Status)
+// response, as described by the sections 9.6.1, 9.8.5 and 9.9.4.
+type memberError struct {
+	path   *dfs.PathCmd
+	status int
+}
+
+// lockedErrors returns a 423 (Locked) error for each locked member.
+func lockedErrors(locked []*dfs.PathCmd) []memberError {
+	res := make([]memberError, 0, len(locked))
+	for _, p := range locked {
+		res = append(res, memberError{path: p, status: StatusLocked})
+	}
+
+	return res
+}
+
+// deleteMembers removes all the members of dir except the locked ones and their
+// ancestors.
+//
+// Section 9.6.1 says that "If any resource identified by a member URL cannot
+// be deleted, then all of the member's ancestors must not be deleted, so as to
+// maintain URL namespace consistency". A failure doesn't stop the other
+// members deletion, it's returned with the locked members left to the caller.
+func (h *Handler) deleteMembers(ctx context.Context, user *users.User, dir *dfs.PathCmd, locked []*dfs.PathCmd) ([]memberError, error) {
+	children, err := h.FileSystem.ListDir(ctx, dir, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []memberError{}
+	for _, child := range children {
+		member := dfs.NewPathCmd(dir.Space(), path.Join(dir.Path(), child.Name()))
+
+		keep := slices.ContainsFunc(locked, func(l *dfs.PathCmd) bool { return member.Contains(*l) })
+		if !keep {
+			err = h.FileSystem.Remove(ctx, user, member)
+			if err == nil {
+				err = h.Locks.RemoveAll(ctx, member)
+			}
+			if err != nil {
+				res = append(res, memberError{path: member, status: http.StatusInternalServerError})
+			}
+			continue
+		}
+
+		if !child.IsDir() || slices.ContainsFunc(locked, func(l *dfs.PathCmd) bool { return member.Equal(*l) }) {
+			continue
+		}
+
+		failures, err := h.deleteMembers(ctx, user, member, locked)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, failures...)
+	}
+
+	return res, nil
+}
+
+// writeMemberErrors writes a 207 (Multi-Status) response with the status of
+// each failed member.
+func (h *Handler) writeMemberErrors(w http.ResponseWriter, m *mount, failures []memberError) (int, error) {
+	mw := multistatusWriter{w: w}
+
+	var writeErr error
+	for _, failure := range failures {
+		writeErr = mw.write(&response{
+			Href:   []string{(&url.URL{Path: h.hrefPath(m, failure.path)}).EscapedPath()},
+			Status: fmt.Sprintf("HTTP/1.1 %d %s", failure.status, StatusText(failure.status)),
+		})
+		if writeErr != nil {
+			break
+		}
+	}
+
+	closeErr := mw.close()
+	if writeErr != nil {
+		return http.StatusInternalServerError, writeErr
+	}
+	if closeErr != nil {
+		return http.StatusInternalServerError, closeErr
+	}
+	return 0, nil
+}
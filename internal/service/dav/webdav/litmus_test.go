@@ -39,6 +39,7 @@ func TestWebdavLitmus(t *testing.T) {
 		Spaces:     serv.SpacesSvc,
 		Users:      serv.UsersSvc,
 		Files:      serv.Files,
+		Locks:      serv.DavLocksSvc,
 		Logger: func(r *http.Request, err error) {
 			litmus := r.Header.Get("X-Litmus")
 			if len(litmus) > 19 {
@@ -0,0 +1,368 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+)
+
+func (h *Handler) handleLock(w http.ResponseWriter, r *http.Request, user *users.User, m *mount, pathCmd *dfs.PathCmd) (status int, err error) {
+	ctx := r.Context()
+
+	timeout, err := parseTimeout(r.Header.Get("Timeout"))
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	li, status, err := readLockInfo(r.Body)
+	if err != nil {
+		return status, err
+	}
+
+	var lock *davlocks.Lock
+	created := false
+	if li == (lockInfo{}) {
+		// An empty lockInfo means to refresh the lock given by the If header.
+		ih, ok := parseIfHeader(r.Header.Get("If"))
+		if !ok {
+			return http.StatusBadRequest, errInvalidIfHeader
+		}
+		if len(ih.lists) != 1 || len(ih.lists[0].conditions) != 1 || ih.lists[0].conditions[0].Token == "" {
+			return http.StatusBadRequest, errInvalidLockToken
+		}
+
+		lock, err = h.Locks.Refresh(ctx, &davlocks.RefreshCmd{
+			User:    user,
+			Path:    pathCmd,
+			Token:   ih.lists[0].conditions[0].Token,
+			Timeout: timeout,
+		})
+		if errors.Is(err, errs.ErrNotFound) {
+			return http.StatusPreconditionFailed, err
+		}
+		if errors.Is(err, errs.ErrUnauthorized) {
+			return http.StatusForbidden, err
+		}
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+	} else {
+		// Section 9.10.3 says that "If no Depth header is submitted on a LOCK
+		// request, then the request MUST act as if a "Depth:infinity" had
+		// been submitted."
+		depth := davlocks.DepthInfinity
+		if hdr := r.Header.Get("Depth"); hdr != "" {
+			switch parseDepth(hdr) {
+			case 0:
+				depth = davlocks.DepthZero
+			case infiniteDepth:
+			default:
+				return http.StatusBadRequest, errInvalidDepth
+			}
+		}
+
+		lock, err = h.Locks.Create(ctx, &davlocks.CreateCmd{
+			User:    user,
+			Path:    pathCmd,
+			Scope:   li.scope(),
+			Depth:   depth,
+			Owner:   li.Owner.InnerXML,
+			Timeout: timeout,
+		})
+		if errors.Is(err, davlocks.ErrLocked) {
+			return StatusLocked, err
+		}
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+
+		// Section 7.3 says that "A successful lock request to an unmapped URL
+		// MUST result in the creation of a locked (non-collection) resource
+		// with empty content."
+		created, status, err = h.createLockedResource(ctx, user, pathCmd)
+		if err != nil {
+			_ = h.Locks.Unlock(ctx, &davlocks.UnlockCmd{User: user, Path: pathCmd, Token: lock.Token()})
+			return status, err
+		}
+
+		// http://www.webdav.org/specs/rfc4918.html#HEADER_Lock-Token says that the
+		// Lock-Token value is a Coded-URL. We add angle brackets.
+		w.Header().Set("Lock-Token", "<"+lock.Token()+">")
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	if created {
+		// This is "w.WriteHeader(http.StatusCreated)" and not "return
+		// http.StatusCreated, nil" because we write our own (XML) response to w
+		// and Handler.ServeHTTP would otherwise write "Created".
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	_, err = writeLockInfo(w, lock, h.hrefPath(m, dfs.NewPathCmd(pathCmd.Space(), lock.Path())))
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return 0, nil
+}
+
+// createLockedResource creates an empty file at pathCmd if nothing exists yet.
+func (h *Handler) createLockedResource(ctx context.Context, user *users.User, pathCmd *dfs.PathCmd) (bool, int, error) {
+	_, err := h.FileSystem.Get(ctx, pathCmd)
+	if err == nil {
+		return false, 0, nil
+	}
+
+	if !errors.Is(err, errs.ErrNotFound) {
+		return false, http.StatusInternalServerError, err
+	}
+
+	// All the parents must exists
+	_, err = h.FileSystem.Get(ctx, dfs.NewPathCmd(pathCmd.Space(), path.Dir(pathCmd.Path())))
+	if errors.Is(err, errs.ErrNotFound) {
+		return false, http.StatusConflict, err
+	}
+	if err != nil {
+		return false, http.StatusInternalServerError, err
+	}
+
+	err = h.FileSystem.Upload(ctx, &dfs.UploadCmd{
+		Path:       pathCmd,
+		Content:    http.NoBody,
+		UploadedBy: user,
+	})
+	if errors.Is(err, dfs.ErrQuotaExceeded) {
+		return false, http.StatusInsufficientStorage, err
+	}
+	if err != nil {
+		return false, http.StatusInternalServerError, err
+	}
+
+	return true, 0, nil
+}
+
+func (h *Handler) handleUnlock(w http.ResponseWriter, r *http.Request, user *users.User, pathCmd *dfs.PathCmd) (status int, err error) {
+	// http://www.webdav.org/specs/rfc4918.html#HEADER_Lock-Token says that the
+	// Lock-Token value is a Coded-URL. We strip its angle brackets.
+	t := r.Header.Get("Lock-Token")
+	if len(t) < 2 || t[0] != '<' || t[len(t)-1] != '>' {
+		return http.StatusBadRequest, errInvalidLockToken
+	}
+	t = t[1 : len(t)-1]
+
+	err = h.Locks.Unlock(r.Context(), &davlocks.UnlockCmd{
+		User:  user,
+		Path:  pathCmd,
+		Token: t,
+	})
+	if errors.Is(err, errs.ErrNotFound) {
+		return http.StatusConflict, err
+	}
+	if errors.Is(err, errs.ErrUnauthorized) {
+		return http.StatusForbidden, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusNoContent, nil
+}
+
+// confirmLocks checks that the user can modify the resources at paths.
+//
+// The If header is evaluated first as described by the section 10.4. The lists
+// without a resource tag apply to src, the resource identified by the request
+// URI. All the lock tokens submitted are then used to confirm that the
+// resources are not locked by an another client.
+func (h *Handler) confirmLocks(r *http.Request, user *users.User, m *mount, src *dfs.PathCmd, paths ...*dfs.PathCmd) (status int, err error) {
+	ctx := r.Context()
+
+	tokens, status, err := h.submittedTokens(r, m, src)
+	if err != nil {
+		return status, err
+	}
+
+	for _, p := range paths {
+		err = h.Locks.Confirm(ctx, &davlocks.ConfirmCmd{
+			User:   user,
+			Path:   p,
+			Tokens: tokens,
+		})
+		if errors.Is(err, davlocks.ErrLocked) {
+			return StatusLocked, err
+		}
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+
+	return 0, nil
+}
+
+// submittedTokens evaluates the If header as described by the section 10.4 and
+// returns all the lock tokens submitted with it. The lists without a resource
+// tag apply to src, the resource identified by the request URI.
+func (h *Handler) submittedTokens(r *http.Request, m *mount, src *dfs.PathCmd) ([]string, int, error) {
+	hdr := r.Header.Get("If")
+	if hdr == "" {
+		return nil, 0, nil
+	}
+
+	ih, ok := parseIfHeader(hdr)
+	if !ok {
+		return nil, http.StatusBadRequest, errInvalidIfHeader
+	}
+
+	var tokens []string
+
+	// ih is a disjunction (OR) of ifLists, so any ifList will do.
+	matched := false
+	for _, l := range ih.lists {
+		target := src
+		if l.resourceTag != "" {
+			u, err := url.Parse(l.resourceTag)
+			if err != nil {
+				continue
+			}
+			if u.Host != "" && u.Host != r.Host {
+				continue
+			}
+			var status int
+			target, status, err = h.resolvePath(m, u.Path)
+			if err != nil {
+				return nil, status, err
+			}
+		}
+
+		// The virtual root listing the spaces can't match any condition.
+		if target != nil {
+			ok, err := h.evalIfList(r.Context(), target, l)
+			if err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+			matched = matched || ok
+		}
+
+		for _, c := range l.conditions {
+			if !c.Not && c.Token != "" {
+				tokens = append(tokens, c.Token)
+			}
+		}
+	}
+
+	// Section 10.4.1 says that "If this header is evaluated and all state lists
+	// fail, then the request must fail with a 412 (Precondition Failed) status."
+	if !matched {
+		return nil, http.StatusPreconditionFailed, errPreconditionFailed
+	}
+
+	return tokens, 0, nil
+}
+
+// lockedMembers returns the members of p locked by an another client. Contrary
+// to confirmLocks, the other members can still be modified. A 423 (Locked)
+// status is returned if p itself is locked.
+func (h *Handler) lockedMembers(ctx context.Context, user *users.User, tokens []string, p *dfs.PathCmd) ([]*dfs.PathCmd, int, error) {
+	conflicts, err := h.Locks.GetConflicts(ctx, &davlocks.ConfirmCmd{
+		User:   user,
+		Path:   p,
+		Tokens: tokens,
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	res := []*dfs.PathCmd{}
+	for _, l := range conflicts {
+		if l.Covers(p) {
+			return nil, StatusLocked, davlocks.ErrLocked
+		}
+
+		member := dfs.NewPathCmd(p.Space(), l.Path())
+		if !slices.ContainsFunc(res, func(other *dfs.PathCmd) bool { return other.Equal(*member) }) {
+			res = append(res, member)
+		}
+	}
+
+	return res, 0, nil
+}
+
+// evalIfList returns true if all the conditions of l match the target.
+func (h *Handler) evalIfList(ctx context.Context, target *dfs.PathCmd, l ifList) (bool, error) {
+	for _, c := range l.conditions {
+		ok, err := h.evalCondition(ctx, target, c)
+		if err != nil {
+			return false, err
+		}
+
+		if ok == c.Not {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (h *Handler) evalCondition(ctx context.Context, target *dfs.PathCmd, c Condition) (bool, error) {
+	if c.Token != "" {
+		lock, err := h.Locks.GetByToken(ctx, c.Token)
+		if errors.Is(err, errs.ErrNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to GetByToken: %w", err)
+		}
+
+		return lock.Covers(target), nil
+	}
+
+	info, err := h.FileSystem.Get(ctx, target)
+	if errors.Is(err, errs.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to Get the resource: %w", err)
+	}
+
+	return info.ETag() != "" && c.ETag == info.ETag(), nil
+}
+
+// parseTimeout parses the Timeout HTTP header, as per section 10.7. If s is
+// empty, a zero duration is returned and the default timeout will be used.
+func parseTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if i := strings.IndexByte(s, ','); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if s == "Infinite" {
+		return davlocks.MaxTimeout, nil
+	}
+	const pre = "Second-"
+	if !strings.HasPrefix(s, pre) {
+		return 0, errInvalidTimeout
+	}
+	s = s[len(pre):]
+	if s == "" || s[0] < '0' || '9' < s[0] {
+		return 0, errInvalidTimeout
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || 1<<32-1 < n {
+		return 0, errInvalidTimeout
+	}
+	return time.Duration(n) * time.Second, nil
+}
@@ -0,0 +1,98 @@
+package webdav
+
+import (
+	"path"
+	"strings"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+// mount maps the url paths of a WebDAV session to the dfs paths.
+//
+// A session gives an access either to a single folder, the root of a space or
+// a folder shared with the user, or to all the spaces of the user. In this
+// last case "/" is a virtual collection listing the spaces and the first
+// segment of each path selects the space.
+type mount struct {
+	// root is the folder mounted at "/". It's nil for a virtual root.
+	root *dfs.PathCmd
+	// spaces are the spaces listed by the virtual root.
+	spaces []spaces.Space
+	// segments contains the url segment of each space listed by the virtual
+	// root.
+	segments map[uuid.UUID]string
+}
+
+// newFolderMount returns a mount exposing the root folder at "/".
+func newFolderMount(root *dfs.PathCmd) *mount {
+	return &mount{root: root, spaces: nil, segments: nil}
+}
+
+// newVirtualMount returns a mount exposing all the given spaces under a
+// virtual root.
+//
+// Each space is exposed under its name. The space id is used instead if the
+// name can't be used as a path segment or if it's already taken by an other
+// space.
+func newVirtualMount(userSpaces []spaces.Space) *mount {
+	segments := make(map[uuid.UUID]string, len(userSpaces))
+	taken := make(map[string]bool, len(userSpaces))
+
+	for _, space := range userSpaces {
+		segment := space.Name()
+		if strings.Contains(segment, "/") || segment == "." || segment == ".." || taken[segment] {
+			segment = string(space.ID())
+		}
+
+		segments[space.ID()] = segment
+		taken[segment] = true
+	}
+
+	return &mount{root: nil, spaces: userSpaces, segments: segments}
+}
+
+// isVirtual returns true if "/" is the virtual collection listing the spaces.
+func (m *mount) isVirtual() bool {
+	return m.root == nil
+}
+
+// resolve converts a path inside the mount into a dfs path. It returns nil
+// for the virtual root.
+func (m *mount) resolve(p string) (*dfs.PathCmd, error) {
+	// The cleaning removes all the ".." so the result can't be outside the root.
+	p = dfs.CleanPath(p)
+
+	if m.root != nil {
+		return dfs.NewPathCmd(m.root.Space(), path.Join(m.root.Path(), p)), nil
+	}
+
+	if p == "/" {
+		return nil, nil
+	}
+
+	segment, rest, _ := strings.Cut(p[1:], "/")
+	for i, space := range m.spaces {
+		if m.segments[space.ID()] == segment {
+			return dfs.NewPathCmd(&m.spaces[i], dfs.CleanPath(rest)), nil
+		}
+	}
+
+	return nil, errUnknownSpace
+}
+
+// href is the opposite of resolve, it returns the path of cmd inside the
+// mount.
+func (m *mount) href(cmd *dfs.PathCmd) string {
+	if m.root == nil {
+		return path.Join("/", m.segments[cmd.Space().ID()], cmd.Path())
+	}
+
+	p := cmd.Path()
+	if m.root.Path() != "/" {
+		p = dfs.CleanPath(strings.TrimPrefix(p, m.root.Path()))
+	}
+
+	return p
+}
@@ -0,0 +1,66 @@
+package webdav
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+)
+
+func TestMount(t *testing.T) {
+	t.Run("folder mount", func(t *testing.T) {
+		space := spaces.NewFakeSpace(t).Build()
+		m := newFolderMount(dfs.NewPathCmd(space, "/shared"))
+
+		assert.False(t, m.isVirtual())
+
+		res, err := m.resolve("/foo/../../bar.txt")
+		require.NoError(t, err)
+		assert.Equal(t, dfs.NewPathCmd(space, "/shared/bar.txt"), res)
+
+		assert.Equal(t, "/bar.txt", m.href(res))
+	})
+
+	t.Run("virtual mount", func(t *testing.T) {
+		projects := spaces.NewFakeSpace(t).WithName("Projects").Build()
+		m := newVirtualMount([]spaces.Space{*projects})
+
+		assert.True(t, m.isVirtual())
+
+		res, err := m.resolve("/")
+		require.NoError(t, err)
+		assert.Nil(t, res)
+
+		res, err = m.resolve("/Projects/foo/bar.txt")
+		require.NoError(t, err)
+		assert.Equal(t, projects.ID(), res.Space().ID())
+		assert.Equal(t, "/foo/bar.txt", res.Path())
+		assert.Equal(t, "/Projects/foo/bar.txt", m.href(res))
+
+		res, err = m.resolve("/Projects")
+		require.NoError(t, err)
+		assert.Equal(t, "/", res.Path())
+		assert.Equal(t, "/Projects", m.href(res))
+
+		res, err = m.resolve("/unknown/bar.txt")
+		require.ErrorIs(t, err, errUnknownSpace)
+		assert.Nil(t, res)
+	})
+
+	t.Run("virtual mount with names unusable as a segment", func(t *testing.T) {
+		first := spaces.NewFakeSpace(t).WithName("Docs").Build()
+		duplicate := spaces.NewFakeSpace(t).WithName("Docs").Build()
+		withSlash := spaces.NewFakeSpace(t).WithName("a/b").Build()
+		m := newVirtualMount([]spaces.Space{*first, *duplicate, *withSlash})
+
+		assert.Equal(t, "/Docs", m.href(dfs.NewPathCmd(first, "/")))
+		assert.Equal(t, "/"+string(duplicate.ID()), m.href(dfs.NewPathCmd(duplicate, "/")))
+		assert.Equal(t, "/"+string(withSlash.ID()), m.href(dfs.NewPathCmd(withSlash, "/")))
+
+		res, err := m.resolve("/" + string(duplicate.ID()) + "/foo")
+		require.NoError(t, err)
+		assert.Equal(t, duplicate.ID(), res.Space().ID())
+	})
+}
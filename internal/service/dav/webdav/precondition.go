@@ -0,0 +1,78 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+)
+
+// checkPreconditions evaluates the If-Match, If-Unmodified-Since and
+// If-None-Match headers of a write request against the resource at pathCmd,
+// as described by the RFC 7232 section 6. It returns the resource or nil if
+// it doesn't exist yet.
+//
+// The GET and HEAD requests are handled by http.ServeContent.
+func (h *Handler) checkPreconditions(ctx context.Context, r *http.Request, pathCmd *dfs.PathCmd) (*dfs.INode, int, error) {
+	info, err := h.FileSystem.Get(ctx, pathCmd)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if hdr := r.Header.Get("If-Match"); hdr != "" {
+		if info == nil || !matchETags(hdr, info.ETag(), false) {
+			return nil, http.StatusPreconditionFailed, errPreconditionFailed
+		}
+	} else if hdr := r.Header.Get("If-Unmodified-Since"); hdr != "" && info != nil {
+		t, err := http.ParseTime(hdr)
+		// The dates with a wrong format must be ignored.
+		if err == nil && info.LastModifiedAt().Truncate(time.Second).After(t) {
+			return nil, http.StatusPreconditionFailed, errPreconditionFailed
+		}
+	}
+
+	if hdr := r.Header.Get("If-None-Match"); hdr != "" && info != nil {
+		if matchETags(hdr, info.ETag(), true) {
+			return nil, http.StatusPreconditionFailed, errPreconditionFailed
+		}
+	}
+
+	return info, 0, nil
+}
+
+// matchETags returns true if the etag is part of the comma separated list
+// of entity tags given by hdr. The "*" value matches any existing resource.
+//
+// The weak comparison ignores the "W/" prefix of the weak tags. With the
+// strong comparison the weak tags never match.
+func matchETags(hdr string, etag string, weak bool) bool {
+	if strings.TrimSpace(hdr) == "*" {
+		return true
+	}
+
+	if etag == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(hdr, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+
+			tag = tag[2:]
+		}
+
+		if tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,32 @@
+package webdav
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchETags(t *testing.T) {
+	testCases := []struct {
+		desc string
+		hdr  string
+		etag string
+		weak bool
+		want bool
+	}{
+		{"any", "*", `"a"`, false, true},
+		{"any without etag", "*", "", false, true},
+		{"same", `"a"`, `"a"`, false, true},
+		{"different", `"b"`, `"a"`, false, false},
+		{"list", `"b", "a"`, `"a"`, false, true},
+		{"no etag", `"a"`, "", false, false},
+		{"weak with a strong comparison", `W/"a"`, `"a"`, false, false},
+		{"weak with a weak comparison", `W/"a"`, `"a"`, true, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.want, matchETags(tc.hdr, tc.etag, tc.weak))
+		})
+	}
+}
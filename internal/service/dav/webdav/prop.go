@@ -9,11 +9,19 @@ import (
 	"context"
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
 // Proppatch describes a property update instruction as defined in RFC 4918.
@@ -69,43 +77,22 @@ func makePropstats(x, y Propstat) []Propstat {
 	return pstats
 }
 
-// DeadPropsHolder holds the dead properties of a resource.
-//
-// Dead properties are those properties that are explicitly defined. In
-// comparison, live properties, such as DAV:getcontentlength, are implicitly
-// defined by the underlying resource, and cannot be explicitly overridden or
-// removed. See the Terminology section of
-// http://www.webdav.org/specs/rfc4918.html#rfc.section.3
-//
-// There is a whitelist of the names of live properties. This package handles
-// all live properties, and will only pass non-whitelisted names to the Patch
-// method of DeadPropsHolder implementations.
-type DeadPropsHolder interface {
-	// DeadProps returns a copy of the dead properties held.
-	DeadProps() (map[xml.Name]Property, error)
-
-	// Patch patches the dead properties held.
-	//
-	// Patching is atomic; either all or no patches succeed. It returns (nil,
-	// non-nil) if an internal server error occurred, otherwise the Propstats
-	// collectively contain one Property for each proposed patch Property. If
-	// all patches succeed, Patch returns a slice of length one and a Propstat
-	// element with a 200 OK HTTP status code. If none succeed, for reasons
-	// other than an internal server error, no Propstat has status 200 OK.
-	//
-	// For more details on when various HTTP status codes apply, see
-	// http://www.webdav.org/specs/rfc4918.html#PROPPATCH-status
-	Patch([]Proppatch) ([]Propstat, error)
-}
-
-// liveProps contains all supported, protected DAV: properties.
-var liveProps = map[xml.Name]struct {
+// liveProp describes how a live property is found.
+type liveProp struct {
 	// findFn implements the propfind function of this property. If nil,
 	// it indicates a hidden property.
 	findFn func(context.Context, *dfs.PathCmd, *dfs.INode, *files.FileMeta) (string, error)
 	// dir is true if the property applies to directories.
 	dir bool
-}{
+	// dirOnly is true if the property doesn't apply to the files.
+	dirOnly bool
+	// onDemand is true if the property is not returned by allprop and must
+	// be explicitly requested.
+	onDemand bool
+}
+
+// liveProps contains all supported, protected DAV: properties.
+var liveProps = map[xml.Name]liveProp{
 	{Space: "DAV:", Local: "resourcetype"}: {
 		findFn: findResourceType,
 		dir:    true,
@@ -141,14 +128,56 @@ var liveProps = map[xml.Name]struct {
 		findFn: findContentType,
 		dir:    false,
 	},
+	{Space: "DAV:", Local: "supportedlock"}: {
+		findFn: findSupportedLock,
+		dir:    true,
+	},
 	{Space: "DAV:", Local: "getetag"}: {
 		findFn: findETag,
-		// findETag implements ETag as the concatenated hex values of a file's
-		// modification time and size. This is not a reliable synchronization
-		// mechanism for directories, so we do not advertise getetag for DAV
-		// collections.
+		// findETag implements ETag with the inode and the file content ids.
+		// The directories don't have any content, so we do not advertise
+		// getetag for DAV collections.
 		dir: false,
 	},
+	// The quota properties are defined by RFC 4331 for the collections and
+	// they must not be returned by allprop.
+	{Space: "DAV:", Local: "quota-available-bytes"}: {
+		findFn:   findQuotaAvailableBytes,
+		dir:      true,
+		dirOnly:  true,
+		onDemand: true,
+	},
+	{Space: "DAV:", Local: "quota-used-bytes"}: {
+		findFn:   findQuotaUsedBytes,
+		dir:      true,
+		dirOnly:  true,
+		onDemand: true,
+	},
+}
+
+// errPropNotFound is returned by a findFn if the property has no value for
+// the resource.
+var errPropNotFound = errors.New("webdav: property not found")
+
+// applies returns true if the property exists for the resource.
+func (p liveProp) applies(isDir bool) bool {
+	if p.findFn == nil {
+		return false
+	}
+
+	if isDir {
+		return p.dir
+	}
+
+	return !p.dirOnly
+}
+
+// modTimeProps contains the properties used by the clients in order to keep
+// the original modification time of a resource. A PROPPATCH setting one of
+// them changes the modification time returned by DAV:getlastmodified.
+var modTimeProps = map[xml.Name]bool{
+	{Space: "DAV:", Local: "getlastmodified"}:                             true,
+	{Space: "urn:schemas-microsoft-com:", Local: "Win32LastModifiedTime"}: true,
 }
 
 // TODO(nigeltao) merge props and allprop?
@@ -157,17 +186,13 @@ var liveProps = map[xml.Name]struct {
 //
 // Each Propstat has a unique status and each property name will only be part
 // of one Propstat element.
-func props(ctx context.Context, fi *dfs.INode, fm *files.FileMeta, cmd *dfs.PathCmd, pnames []xml.Name) ([]Propstat, error) {
+func props(ctx context.Context, fs dfs.Service, fi *dfs.INode, fm *files.FileMeta, cmd *dfs.PathCmd, pnames []xml.Name) ([]Propstat, error) {
 	isDir := fi.IsDir()
 
-	var deadProps map[xml.Name]Property
-	// TODO: Implement dead props
-	// if dph, ok := f.(DeadPropsHolder); ok {
-	// 	deadProps, err = dph.DeadProps()
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// }
+	deadProps, err := findDeadProps(ctx, fs, fi)
+	if err != nil {
+		return nil, err
+	}
 
 	pstatOK := Propstat{Status: http.StatusOK}
 	pstatNotFound := Propstat{Status: http.StatusNotFound}
@@ -178,8 +203,14 @@ func props(ctx context.Context, fi *dfs.INode, fm *files.FileMeta, cmd *dfs.Path
 			continue
 		}
 		// Otherwise, it must either be a live property or we don't know it.
-		if prop := liveProps[pn]; prop.findFn != nil && (prop.dir || !isDir) {
+		if prop := liveProps[pn]; prop.applies(isDir) {
 			innerXML, err := prop.findFn(ctx, cmd, fi, fm)
+			if errors.Is(err, errPropNotFound) {
+				pstatNotFound.Props = append(pstatNotFound.Props, Property{
+					XMLName: pn,
+				})
+				continue
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -196,23 +227,62 @@ func props(ctx context.Context, fi *dfs.INode, fm *files.FileMeta, cmd *dfs.Path
 	return makePropstats(pstatOK, pstatNotFound), nil
 }
 
+// virtualRootProps returns the properties requested by pf for the virtual root
+// listing the spaces. It only has the properties of an empty collection.
+func virtualRootProps(pf propfind) []Propstat {
+	known := []Property{
+		{XMLName: xml.Name{Space: "DAV:", Local: "resourcetype"}, InnerXML: []byte(`<D:collection xmlns:D="DAV:"/>`)},
+		{XMLName: xml.Name{Space: "DAV:", Local: "displayname"}},
+	}
+
+	if pf.Propname != nil {
+		pstat := Propstat{Status: http.StatusOK}
+		for _, p := range known {
+			pstat.Props = append(pstat.Props, Property{XMLName: p.XMLName})
+		}
+		return []Propstat{pstat}
+	}
+
+	pnames := []xml.Name(pf.Prop)
+	if pf.Allprop != nil {
+		pnames = append([]xml.Name{known[0].XMLName, known[1].XMLName}, pnames...)
+	}
+
+	pstatOK := Propstat{Status: http.StatusOK}
+	pstatNotFound := Propstat{Status: http.StatusNotFound}
+	seen := map[xml.Name]bool{}
+	for _, pn := range pnames {
+		if seen[pn] {
+			continue
+		}
+		seen[pn] = true
+
+		found := false
+		for _, p := range known {
+			if p.XMLName == pn {
+				pstatOK.Props = append(pstatOK.Props, p)
+				found = true
+			}
+		}
+		if !found {
+			pstatNotFound.Props = append(pstatNotFound.Props, Property{XMLName: pn})
+		}
+	}
+	return makePropstats(pstatOK, pstatNotFound)
+}
+
 // propnames returns the property names defined for resource name.
-func propnames(_ context.Context, fi *dfs.INode, _ *dfs.PathCmd) ([]xml.Name, error) {
+func propnames(ctx context.Context, fs dfs.Service, fi *dfs.INode, _ *dfs.PathCmd) ([]xml.Name, error) {
 	isDir := fi.IsDir()
 
-	var deadProps map[xml.Name]Property
-
-	// TODO: Implement deadProps
-	// if dph, ok := fi.(DeadPropsHolder); ok {
-	// 	deadProps, err = dph.DeadProps()
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// }
+	deadProps, err := findDeadProps(ctx, fs, fi)
+	if err != nil {
+		return nil, err
+	}
 
 	pnames := make([]xml.Name, 0, len(liveProps)+len(deadProps))
 	for pn, prop := range liveProps {
-		if prop.findFn != nil && (prop.dir || !isDir) {
+		if prop.applies(isDir) {
 			pnames = append(pnames, pn)
 		}
 	}
@@ -230,11 +300,20 @@ func propnames(_ context.Context, fi *dfs.INode, _ *dfs.PathCmd) ([]xml.Name, er
 // returned if they are named in 'include'.
 //
 // See http://www.webdav.org/specs/rfc4918.html#METHOD_PROPFIND
-func allprop(ctx context.Context, fi *dfs.INode, fm *files.FileMeta, cmd *dfs.PathCmd, include []xml.Name) ([]Propstat, error) {
-	pnames, err := propnames(ctx, fi, cmd)
+func allprop(ctx context.Context, fs dfs.Service, fi *dfs.INode, fm *files.FileMeta, cmd *dfs.PathCmd, include []xml.Name) ([]Propstat, error) {
+	pnames, err := propnames(ctx, fs, fi, cmd)
 	if err != nil {
 		return nil, err
 	}
+	// Remove the properties which must be explicitly requested.
+	n := 0
+	for _, pn := range pnames {
+		if !liveProps[pn].onDemand {
+			pnames[n] = pn
+			n++
+		}
+	}
+	pnames = pnames[:n]
 	// Add names from include if they are not already covered in pnames.
 	nameset := make(map[xml.Name]bool)
 	for _, pn := range pnames {
@@ -245,75 +324,164 @@ func allprop(ctx context.Context, fi *dfs.INode, fm *files.FileMeta, cmd *dfs.Pa
 			pnames = append(pnames, pn)
 		}
 	}
-	return props(ctx, fi, fm, cmd, pnames)
+	return props(ctx, fs, fi, fm, cmd, pnames)
 }
 
-// patch patches the properties of resource name. The return values are
-// constrained in the same manner as DeadPropsHolder.Patch.
-func patch(_ context.Context, _ dfs.Service, _ *dfs.PathCmd, patches []Proppatch) ([]Propstat, error) {
-	conflict := false
-loop:
-	for _, patch := range patches {
-		for _, p := range patch.Props {
-			if _, ok := liveProps[p.XMLName]; ok {
-				conflict = true
-				break loop
-			}
+// findDeadProps returns the dead properties of the resource.
+//
+// Dead properties are those properties that are explicitly defined. In
+// comparison, live properties, such as DAV:getcontentlength, are implicitly
+// defined by the underlying resource, and cannot be explicitly overridden or
+// removed. See the Terminology section of
+// http://www.webdav.org/specs/rfc4918.html#rfc.section.3
+func findDeadProps(ctx context.Context, fs dfs.Service, fi *dfs.INode) (map[xml.Name]Property, error) {
+	res, ok := popPrefetchedProps(ctx, fi)
+	if !ok {
+		var err error
+		res, err = fs.GetProps(ctx, fi)
+		if err != nil {
+			return nil, err
 		}
 	}
-	if conflict {
-		pstatForbidden := Propstat{
+
+	deadProps := make(map[xml.Name]Property, len(res))
+	for _, p := range res {
+		pn := xml.Name{Space: p.Namespace(), Local: p.Name()}
+		deadProps[pn] = Property{
+			XMLName:  pn,
+			Lang:     p.Lang(),
+			InnerXML: []byte(p.Value()),
+		}
+	}
+	return deadProps, nil
+}
+
+// patch patches the properties of resource name.
+//
+// Patching is atomic; either all or no patches succeed. It returns (nil,
+// non-nil) if an internal server error occurred, otherwise the Propstats
+// collectively contain one Property for each proposed patch Property. If
+// all patches succeed, patch returns a slice of length one and a Propstat
+// element with a 200 OK HTTP status code. If none succeed, for reasons
+// other than an internal server error, no Propstat has status 200 OK.
+//
+// There is a whitelist of the names of live properties. Only the
+// non-whitelisted names are stored as dead properties.
+//
+// For more details on when various HTTP status codes apply, see
+// http://www.webdav.org/specs/rfc4918.html#PROPPATCH-status
+func patch(ctx context.Context, fs dfs.Service, user *users.User, cmd *dfs.PathCmd, patches []Proppatch) ([]Propstat, error) {
+	isProtected := func(p Property, remove bool) bool {
+		_, ok := liveProps[p.XMLName]
+		return ok && (remove || !modTimeProps[p.XMLName])
+	}
+	if hasPatch(patches, isProtected) {
+		return failPatch(patches, Propstat{
 			Status:   http.StatusForbidden,
 			XMLError: `<D:cannot-modify-protected-property xmlns:D="DAV:"/>`,
+		}, isProtected), nil
+	}
+
+	var modifiedAt time.Time
+	isInvalidModTime := func(p Property, remove bool) bool {
+		if remove || !modTimeProps[p.XMLName] {
+			return false
 		}
-		pstatFailedDep := Propstat{
-			Status: StatusFailedDependency,
-		}
-		for _, patch := range patches {
-			for _, p := range patch.Props {
-				if _, ok := liveProps[p.XMLName]; ok {
-					pstatForbidden.Props = append(pstatForbidden.Props, Property{XMLName: p.XMLName})
-				} else {
-					pstatFailedDep.Props = append(pstatFailedDep.Props, Property{XMLName: p.XMLName})
-				}
-			}
+		t, err := http.ParseTime(strings.TrimSpace(string(p.InnerXML)))
+		if err != nil {
+			return true
 		}
-		return makePropstats(pstatForbidden, pstatFailedDep), nil
-	}
-
-	// TODO: Implement dead properties
-	// f, err := fs.OpenFile(ctx, name, os.O_RDWR, 0)
-	// if err != nil {
-	// 	return nil, err
-	// }
-	// defer f.Close()
-	// if dph, ok := f.(DeadPropsHolder); ok {
-	// ret, err := dph.Patch(patches)
-	// 	if err != nil {
-	// 		return nil, err
-	// 	}
-	// 	// http://www.webdav.org/specs/rfc4918.html#ELEMENT_propstat says that
-	// 	// "The contents of the prop XML element must only list the names of
-	// 	// properties to which the result in the status element applies."
-	// 	for _, pstat := range ret {
-	// 		for i, p := range pstat.Props {
-	// 			pstat.Props[i] = Property{XMLName: p.XMLName}
-	// 		}
-	// 	}
-	// 	return ret, nil
-	// }
-
-	// The file doesn't implement the optional DeadPropsHolder interface, so
-	// all patches are forbidden.
-	pstat := Propstat{Status: http.StatusForbidden}
+		modifiedAt = t
+		return false
+	}
+	if hasPatch(patches, isInvalidModTime) {
+		return failPatch(patches, Propstat{Status: http.StatusConflict}, isInvalidModTime), nil
+	}
+
+	// http://www.webdav.org/specs/rfc4918.html#ELEMENT_propstat says that
+	// "The contents of the prop XML element must only list the names of
+	// properties to which the result in the status element applies."
+	pstat := Propstat{Status: http.StatusOK}
+	propPatches := []dfs.PropPatch{}
 	for _, patch := range patches {
 		for _, p := range patch.Props {
 			pstat.Props = append(pstat.Props, Property{XMLName: p.XMLName})
+			if !patch.Remove && modTimeProps[p.XMLName] {
+				continue
+			}
+			propPatches = append(propPatches, dfs.PropPatch{
+				Namespace: p.XMLName.Space,
+				Name:      p.XMLName.Local,
+				Lang:      p.Lang,
+				Value:     string(p.InnerXML),
+				Remove:    patch.Remove,
+			})
 		}
 	}
+
+	var err error
+	if len(propPatches) > 0 {
+		err = fs.PatchProps(ctx, &dfs.PatchPropsCmd{
+			Path:      cmd,
+			Patches:   propPatches,
+			PatchedBy: user,
+		})
+	}
+	if err == nil && !modifiedAt.IsZero() {
+		err = setModifiedAt(ctx, fs, user, cmd, modifiedAt)
+	}
+	if errors.Is(err, errs.ErrValidation) || errors.Is(err, errs.ErrUnauthorized) {
+		pstat.Status = http.StatusForbidden
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	return []Propstat{pstat}, nil
 }
 
+// hasPatch returns true if fn returns true for at least one of the patched
+// properties.
+func hasPatch(patches []Proppatch, fn func(p Property, remove bool) bool) bool {
+	for _, patch := range patches {
+		for _, p := range patch.Props {
+			if fn(p, patch.Remove) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// failPatch returns the failed pstat for the properties matched by fn. All
+// the others fail with a 424 (Failed Dependency) status.
+func failPatch(patches []Proppatch, failed Propstat, fn func(p Property, remove bool) bool) []Propstat {
+	pstatFailedDep := Propstat{
+		Status: StatusFailedDependency,
+	}
+	for _, patch := range patches {
+		for _, p := range patch.Props {
+			if fn(p, patch.Remove) {
+				failed.Props = append(failed.Props, Property{XMLName: p.XMLName})
+			} else {
+				pstatFailedDep.Props = append(pstatFailedDep.Props, Property{XMLName: p.XMLName})
+			}
+		}
+	}
+	return makePropstats(failed, pstatFailedDep)
+}
+
+func setModifiedAt(ctx context.Context, fs dfs.Service, user *users.User, cmd *dfs.PathCmd, modifiedAt time.Time) error {
+	info, err := fs.Get(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	_, err = fs.SetModifiedAt(ctx, user, info, modifiedAt)
+	return err
+}
+
 func escapeXML(s string) string {
 	for i := 0; i < len(s); i++ {
 		// As an optimization, if s contains only ASCII letters, digits or a
@@ -356,6 +524,131 @@ func findLastModified(ctx context.Context, cmd *dfs.PathCmd, fi *dfs.INode, meta
 	return fi.LastModifiedAt().UTC().Format(http.TimeFormat), nil
 }
 
+// findQuotaAvailableBytes implements DAV:quota-available-bytes from RFC 4331.
+// The property isn't returned if nothing limits the space.
+func findQuotaAvailableBytes(ctx context.Context, cmd *dfs.PathCmd, _ *dfs.INode, _ *files.FileMeta) (string, error) {
+	quotas, ok := ctx.Value(quotaFinderKey{}).(*quotaFinder)
+	if !ok {
+		return "", errPropNotFound
+	}
+
+	available, err := quotas.availableBytes(ctx, cmd.Space())
+	if err != nil {
+		return "", err
+	}
+
+	if available == math.MaxUint64 {
+		return "", errPropNotFound
+	}
+
+	return strconv.FormatUint(available, 10), nil
+}
+
+// findQuotaUsedBytes implements DAV:quota-used-bytes from RFC 4331. The
+// collection size is computed by the fs-refresh-size task.
+func findQuotaUsedBytes(_ context.Context, _ *dfs.PathCmd, fi *dfs.INode, _ *files.FileMeta) (string, error) {
+	return strconv.FormatUint(fi.Size(), 10), nil
+}
+
+type quotaFinderKey struct{}
+
+// quotaFinder finds the available space for the user doing a PROPFIND. The
+// value is the same for all the collections of a space so it's computed only
+// once per space.
+type quotaFinder struct {
+	fs        dfs.Service
+	user      *users.User
+	available map[uuid.UUID]uint64
+}
+
+// withQuotaFinder returns a context allowing findQuotaAvailableBytes to find
+// the space available for the user.
+func withQuotaFinder(ctx context.Context, fs dfs.Service, user *users.User) context.Context {
+	return context.WithValue(ctx, quotaFinderKey{}, &quotaFinder{
+		fs:        fs,
+		user:      user,
+		available: map[uuid.UUID]uint64{},
+	})
+}
+
+func (q *quotaFinder) availableBytes(ctx context.Context, space *spaces.Space) (uint64, error) {
+	if res, ok := q.available[space.ID()]; ok {
+		return res, nil
+	}
+
+	res, err := q.fs.GetAvailableSpace(ctx, q.user, space)
+	if err != nil {
+		return 0, fmt.Errorf("failed to GetAvailableSpace: %w", err)
+	}
+
+	q.available[space.ID()] = res
+
+	return res, nil
+}
+
+type propsPrefetcherKey struct{}
+
+// propsPrefetcher keeps the dead properties of a page of children fetched
+// at once by a PROPFIND. Each entry is removed once used.
+type propsPrefetcher struct {
+	fs    dfs.Service
+	props map[uuid.UUID][]dfs.Property
+}
+
+// withPropsPrefetcher returns a context allowing findDeadProps to use the
+// properties fetched by prefetchProps.
+func withPropsPrefetcher(ctx context.Context, fs dfs.Service) context.Context {
+	return context.WithValue(ctx, propsPrefetcherKey{}, &propsPrefetcher{
+		fs:    fs,
+		props: map[uuid.UUID][]dfs.Property{},
+	})
+}
+
+// prefetchProps fetches the dead properties of all the inodes with a single
+// query. It does nothing if the context doesn't come from
+// withPropsPrefetcher.
+func prefetchProps(ctx context.Context, infos []dfs.INode) error {
+	p, ok := ctx.Value(propsPrefetcherKey{}).(*propsPrefetcher)
+	if !ok {
+		return nil
+	}
+
+	res, err := p.fs.GetAllProps(ctx, infos)
+	if err != nil {
+		return fmt.Errorf("failed to GetAllProps: %w", err)
+	}
+
+	for _, info := range infos {
+		p.props[info.ID()] = res[info.ID()]
+	}
+
+	return nil
+}
+
+func popPrefetchedProps(ctx context.Context, fi *dfs.INode) ([]dfs.Property, bool) {
+	p, ok := ctx.Value(propsPrefetcherKey{}).(*propsPrefetcher)
+	if !ok {
+		return nil, false
+	}
+
+	res, ok := p.props[fi.ID()]
+	delete(p.props, fi.ID())
+
+	return res, ok
+}
+
+func findSupportedLock(_ context.Context, _ *dfs.PathCmd, _ *dfs.INode, _ *files.FileMeta) (string, error) {
+	return `` +
+		`<D:lockentry xmlns:D="DAV:">` +
+		`<D:lockscope><D:exclusive/></D:lockscope>` +
+		`<D:locktype><D:write/></D:locktype>` +
+		`</D:lockentry>` +
+		`<D:lockentry xmlns:D="DAV:">` +
+		`<D:lockscope><D:shared/></D:lockscope>` +
+		`<D:locktype><D:write/></D:locktype>` +
+		`</D:lockentry>`, nil
+}
+
 // ErrNotImplemented should be returned by optional interfaces if they
 // want the original implementation to be used.
 var ErrNotImplemented = errors.New("not implemented")
@@ -403,10 +696,6 @@ type ETager interface {
 	ETag(ctx context.Context) (string, error)
 }
 
-func findETag(_ context.Context, _ *dfs.PathCmd, _ *dfs.INode, meta *files.FileMeta) (string, error) {
-	if meta == nil {
-		return "???", nil
-	}
-
-	return meta.Checksum(), nil
+func findETag(_ context.Context, _ *dfs.PathCmd, fi *dfs.INode, _ *files.FileMeta) (string, error) {
+	return escapeXML(fi.ETag()), nil
 }
@@ -18,19 +18,21 @@ import (
 )
 
 func TestMemPS(t *testing.T) {
-	t.Skip("The DeadPros are not handled yet")
 	ctx := context.Background()
 	// calcProps calculates the getlastmodified and getetag DAV: property
 	// values in pstats for resource name in file-system fs.
-	calcProps := func(cmd *dfs.PathCmd, fs dfs.Service, files files.Service, pstats []Propstat) error {
+	calcProps := func(cmd *dfs.PathCmd, fs dfs.Service, filesSvc files.Service, pstats []Propstat) error {
 		fi, err := fs.Get(ctx, cmd)
 		if err != nil {
 			return err
 		}
 
-		meta, err := files.GetMetadata(ctx, *fi.FileID())
-		if err != nil {
-			return err
+		var meta *files.FileMeta
+		if fi.FileID() != nil {
+			meta, err = filesSvc.GetMetadata(ctx, *fi.FileID())
+			if err != nil {
+				return err
+			}
 		}
 
 		for _, pst := range pstats {
@@ -60,6 +62,10 @@ func TestMemPS(t *testing.T) {
 			`<D:lockentry xmlns:D="DAV:">` +
 			`<D:lockscope><D:exclusive/></D:lockscope>` +
 			`<D:locktype><D:write/></D:locktype>` +
+			`</D:lockentry>` +
+			`<D:lockentry xmlns:D="DAV:">` +
+			`<D:lockscope><D:shared/></D:lockscope>` +
+			`<D:locktype><D:write/></D:locktype>` +
 			`</D:lockentry>`
 		statForbiddenError = `<D:cannot-modify-protected-property xmlns:D="DAV:"/>`
 	)
@@ -74,8 +80,7 @@ func TestMemPS(t *testing.T) {
 	}
 
 	testCases := []struct {
-		desc string
-		// noDeadProps bool
+		desc    string
 		buildfs []string
 		propOp  []propOp
 	}{{
@@ -89,6 +94,8 @@ func TestMemPS(t *testing.T) {
 				{Space: "DAV:", Local: "displayname"},
 				{Space: "DAV:", Local: "supportedlock"},
 				{Space: "DAV:", Local: "getlastmodified"},
+				{Space: "DAV:", Local: "quota-available-bytes"},
+				{Space: "DAV:", Local: "quota-used-bytes"},
 			},
 		}, {
 			op:   "propname",
@@ -268,70 +275,75 @@ func TestMemPS(t *testing.T) {
 				}},
 			}},
 		}},
-		// }, {
-		// 	desc:        "proppatch property on no-dead-properties file system",
-		// 	buildfs:     []string{"mkdir /dir"},
-		// 	noDeadProps: true,
-		// 	propOp: []propOp{{
-		// 		op:   "proppatch",
-		// 		name: "/dir",
-		// 		patches: []Proppatch{{
-		// 			Props: []Property{{
-		// 				XMLName: xml.Name{Space: "foo", Local: "bar"},
-		// 			}},
-		// 		}},
-		// 		wantPropstats: []Propstat{{
-		// 			Status: http.StatusForbidden,
-		// 			Props: []Property{{
-		// 				XMLName: xml.Name{Space: "foo", Local: "bar"},
-		// 			}},
-		// 		}},
-		// 	}, {
-		// 		op:   "proppatch",
-		// 		name: "/dir",
-		// 		patches: []Proppatch{{
-		// 			Props: []Property{{
-		// 				XMLName: xml.Name{Space: "DAV:", Local: "getetag"},
-		// 			}},
-		// 		}},
-		// 		wantPropstats: []Propstat{{
-		// 			Status:   http.StatusForbidden,
-		// 			XMLError: statForbiddenError,
-		// 			Props: []Property{{
-		// 				XMLName: xml.Name{Space: "DAV:", Local: "getetag"},
-		// 			}},
-		// 		}},
-		// 	}},
-		// }, {
-		// 	desc:    "proppatch dead property",
-		// 	buildfs: []string{"mkdir /dir"},
-		// 	propOp: []propOp{{
-		// 		op:   "proppatch",
-		// 		name: "/dir",
-		// 		patches: []Proppatch{{
-		// 			Props: []Property{{
-		// 				XMLName:  xml.Name{Space: "foo", Local: "bar"},
-		// 				InnerXML: []byte("baz"),
-		// 			}},
-		// 		}},
-		// 		wantPropstats: []Propstat{{
-		// 			Status: http.StatusOK,
-		// 			Props: []Property{{
-		// 				XMLName: xml.Name{Space: "foo", Local: "bar"},
-		// 			}},
-		// 		}},
-		// 	}, {
-		// 		op:     "propfind",
-		// 		name:   "/dir",
-		// 		pnames: []xml.Name{{Space: "foo", Local: "bar"}},
-		// 		wantPropstats: []Propstat{{
-		// 			Status: http.StatusOK,
-		// 			Props: []Property{{
-		// 				XMLName:  xml.Name{Space: "foo", Local: "bar"},
-		// 				InnerXML: []byte("baz"),
-		// 			}},
-		// 		}},
-		// 	}},
+	}, {
+		desc:    "propfind quota properties for directories only",
+		buildfs: []string{"mkdir /dir", "write /dir/file foobarbaz"},
+		propOp: []propOp{{
+			op:   "propfind",
+			name: "/dir",
+			pnames: []xml.Name{
+				{Space: "DAV:", Local: "quota-used-bytes"},
+				{Space: "DAV:", Local: "quota-available-bytes"},
+			},
+			wantPropstats: []Propstat{{
+				Status: http.StatusOK,
+				Props: []Property{{
+					XMLName:  xml.Name{Space: "DAV:", Local: "quota-used-bytes"},
+					InnerXML: []byte("9"),
+				}},
+			}, {
+				// Nothing limits the space available.
+				Status: http.StatusNotFound,
+				Props: []Property{{
+					XMLName: xml.Name{Space: "DAV:", Local: "quota-available-bytes"},
+				}},
+			}},
+		}, {
+			op:   "propfind",
+			name: "/dir/file",
+			pnames: []xml.Name{
+				{Space: "DAV:", Local: "quota-used-bytes"},
+				{Space: "DAV:", Local: "quota-available-bytes"},
+			},
+			wantPropstats: []Propstat{{
+				Status: http.StatusNotFound,
+				Props: []Property{{
+					XMLName: xml.Name{Space: "DAV:", Local: "quota-used-bytes"},
+				}, {
+					XMLName: xml.Name{Space: "DAV:", Local: "quota-available-bytes"},
+				}},
+			}},
+		}},
+	}, {
+		desc:    "proppatch dead property",
+		buildfs: []string{"mkdir /dir"},
+		propOp: []propOp{{
+			op:   "proppatch",
+			name: "/dir",
+			patches: []Proppatch{{
+				Props: []Property{{
+					XMLName:  xml.Name{Space: "foo", Local: "bar"},
+					InnerXML: []byte("baz"),
+				}},
+			}},
+			wantPropstats: []Propstat{{
+				Status: http.StatusOK,
+				Props: []Property{{
+					XMLName: xml.Name{Space: "foo", Local: "bar"},
+				}},
+			}},
+		}, {
+			op:     "propfind",
+			name:   "/dir",
+			pnames: []xml.Name{{Space: "foo", Local: "bar"}},
+			wantPropstats: []Propstat{{
+				Status: http.StatusOK,
+				Props: []Property{{
+					XMLName:  xml.Name{Space: "foo", Local: "bar"},
+					InnerXML: []byte("baz"),
+				}},
+			}},
+		}},
 	}, {
 		desc:    "proppatch dead property with failed dependency",
 		buildfs: []string{"mkdir /dir"},
@@ -517,12 +529,12 @@ func TestMemPS(t *testing.T) {
 	for _, tc := range testCases {
 		testContext := buildTestFS(t, tc.buildfs)
 		fs := testContext.FS
-		files := testContext.Files
+		filesSvc := testContext.Files
 
 		var err error
 		for _, op := range tc.propOp {
 			desc := fmt.Sprintf("%s: %s %s", tc.desc, op.op, op.name)
-			if err = calcProps(dfs.NewPathCmd(testContext.Space, op.name), fs, files, op.wantPropstats); err != nil {
+			if err = calcProps(dfs.NewPathCmd(testContext.Space, op.name), fs, filesSvc, op.wantPropstats); err != nil {
 				t.Fatalf("%s: calcProps: %v", desc, err)
 			}
 
@@ -533,16 +545,19 @@ func TestMemPS(t *testing.T) {
 				t.Fatalf("failed to get %q\n", op.name)
 			}
 
-			meta, err := files.GetMetadata(ctx, *info.FileID())
-			if err != nil {
-				t.Fatalf("failed to get files metas for  %q\n", *info.FileID())
+			var meta *files.FileMeta
+			if info.FileID() != nil {
+				meta, err = filesSvc.GetMetadata(ctx, *info.FileID())
+				if err != nil {
+					t.Fatalf("failed to get files metas for  %q\n", *info.FileID())
+				}
 			}
 
 			// Call property system.
 			var propstats []Propstat
 			switch op.op {
 			case "propname":
-				pnames, err := propnames(ctx, info, path)
+				pnames, err := propnames(ctx, fs, info, path)
 				if err != nil {
 					t.Errorf("%s: got error %v, want nil", desc, err)
 					continue
@@ -554,11 +569,11 @@ func TestMemPS(t *testing.T) {
 				}
 				continue
 			case "allprop":
-				propstats, err = allprop(ctx, info, meta, path, op.pnames)
+				propstats, err = allprop(ctx, fs, info, meta, path, op.pnames)
 			case "propfind":
-				propstats, err = props(ctx, info, meta, path, op.pnames)
+				propstats, err = props(ctx, fs, info, meta, path, op.pnames)
 			case "proppatch":
-				propstats, err = patch(ctx, fs, path, op.patches)
+				propstats, err = patch(ctx, fs, testContext.User, path, op.patches)
 			default:
 				t.Fatalf("%s: %s not implemented", desc, op.op)
 			}
@@ -606,28 +621,3 @@ type byStatus []Propstat
 func (b byStatus) Len() int           { return len(b) }
 func (b byStatus) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
 func (b byStatus) Less(i, j int) bool { return b[i].Status < b[j].Status }
-
-// type noDeadPropsFS struct {
-// 	dfs.Service
-// }
-
-// func (fs noDeadPropsFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (File, error) {
-// 	f, err := fs.FS.Get(ctx, name)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	return noDeadPropsFile{f}, nil
-// }
-
-// // noDeadPropsFile wraps a File but strips any optional DeadPropsHolder methods
-// // provided by the underlying File implementation.
-// type noDeadPropsFile struct {
-// 	f *dfs.INode
-// }
-
-// func (f noDeadPropsFile) Close() error                              { return nil }
-// func (f noDeadPropsFile) Read(p []byte) (int, error)                { return f.f.Read(p) }
-// func (f noDeadPropsFile) Readdir(count int) ([]os.FileInfo, error)  { return f.f.Readdir(count) }
-// func (f noDeadPropsFile) Seek(off int64, whence int) (int64, error) { return f.f.Seek(off, whence) }
-// func (f noDeadPropsFile) Stat() (os.FileInfo, error)                { return f.f.Stat() }
-// func (f noDeadPropsFile) Write(p []byte) (int, error)               { return f.f.Write(p) }
@@ -0,0 +1,383 @@
+package webdav
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+
+	ixml "github.com/theduckcompany/duckcloud/internal/service/dav/webdav/internal/xml"
+)
+
+var (
+	errInvalidSearch     = errors.New("webdav: invalid search")
+	errUnsupportedSearch = errors.New("webdav: unsupported search")
+	errInvalidScope      = errors.New("webdav: invalid search scope")
+	errSearchLimit       = errors.New("webdav: search limit reached")
+)
+
+// https://www.rfc-editor.org/rfc/rfc5323#section-2.2.2
+type searchRequest struct {
+	XMLName     ixml.Name    `xml:"DAV: searchrequest"`
+	Basicsearch *basicsearch `xml:"DAV: basicsearch"`
+}
+
+// https://www.rfc-editor.org/rfc/rfc5323#section-5.2
+type basicsearch struct {
+	Select struct {
+		Allprop *struct{}     `xml:"DAV: allprop"`
+		Prop    propfindProps `xml:"DAV: prop"`
+	} `xml:"DAV: select"`
+	Scopes []struct {
+		Href  string `xml:"DAV: href"`
+		Depth string `xml:"DAV: depth"`
+	} `xml:"DAV: from>scope"`
+	Where *searchExpr `xml:"DAV: where"`
+	Limit *struct {
+		NResults int `xml:"DAV: nresults"`
+	} `xml:"DAV: limit"`
+}
+
+// searchExpr is an operator of the where clause with its operands.
+type searchExpr struct {
+	name     xml.Name
+	props    propfindProps
+	literal  *string
+	operands []searchExpr
+}
+
+func (e *searchExpr) UnmarshalXML(d *ixml.Decoder, start ixml.StartElement) error {
+	e.name = xml.Name(start.Name)
+	for {
+		t, err := next(d)
+		if err != nil {
+			return err
+		}
+
+		elem, ok := t.(ixml.StartElement)
+		if !ok {
+			if _, ok := t.(ixml.EndElement); ok {
+				return nil
+			}
+			continue
+		}
+
+		switch elem.Name {
+		case ixml.Name{Space: "DAV:", Local: "prop"}:
+			err = d.DecodeElement(&e.props, &elem)
+		case ixml.Name{Space: "DAV:", Local: "literal"}, ixml.Name{Space: "DAV:", Local: "typed-literal"}:
+			e.literal = new(string)
+			err = d.DecodeElement(e.literal, &elem)
+		default:
+			var operand searchExpr
+			err = d.DecodeElement(&operand, &elem)
+			e.operands = append(e.operands, operand)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// searchFields are the properties usable inside the comparisons with their
+// dfs field.
+var searchFields = map[xml.Name]dfs.SearchField{
+	{Space: "DAV:", Local: "displayname"}:      dfs.SearchName,
+	{Space: "DAV:", Local: "getcontenttype"}:   dfs.SearchMimeType,
+	{Space: "DAV:", Local: "getcontentlength"}: dfs.SearchSize,
+	{Space: "DAV:", Local: "getlastmodified"}:  dfs.SearchLastModifiedAt,
+}
+
+// searchFilter converts a where clause operator into a dfs filter. It
+// returns errUnsupportedSearch for the operators and the properties not
+// handled.
+func searchFilter(e *searchExpr) (*dfs.SearchFilter, error) {
+	if e.name.Space != "DAV:" {
+		return nil, fmt.Errorf("%w: unknown operator %q", errUnsupportedSearch, e.name.Local)
+	}
+
+	operator := dfs.SearchOperator(e.name.Local)
+	switch operator {
+	case dfs.SearchAnd, dfs.SearchOr, dfs.SearchNot:
+		if len(e.operands) == 0 || (operator == dfs.SearchNot && len(e.operands) != 1) {
+			return nil, fmt.Errorf("%w: invalid %q operands", errInvalidSearch, e.name.Local)
+		}
+
+		res := dfs.SearchFilter{Operator: operator, Filters: make([]dfs.SearchFilter, 0, len(e.operands))}
+		for i := range e.operands {
+			filter, err := searchFilter(&e.operands[i])
+			if err != nil {
+				return nil, err
+			}
+
+			res.Filters = append(res.Filters, *filter)
+		}
+
+		return &res, nil
+	case dfs.SearchLike, dfs.SearchEq, dfs.SearchGt, dfs.SearchLt:
+	default:
+		return nil, fmt.Errorf("%w: unknown operator %q", errUnsupportedSearch, e.name.Local)
+	}
+
+	if len(e.props) != 1 || e.literal == nil || len(e.operands) > 0 {
+		return nil, fmt.Errorf("%w: invalid %q operands", errInvalidSearch, e.name.Local)
+	}
+
+	field, ok := searchFields[e.props[0]]
+	if !ok || (operator == dfs.SearchLike && field != dfs.SearchName && field != dfs.SearchMimeType) {
+		return nil, fmt.Errorf("%w: %q can't be used with %q", errUnsupportedSearch, e.props[0].Local, e.name.Local)
+	}
+
+	var value any
+	var err error
+	switch field {
+	case dfs.SearchSize:
+		value, err = strconv.ParseUint(*e.literal, 10, 64)
+	case dfs.SearchLastModifiedAt:
+		value, err = parseSearchTime(*e.literal)
+	default:
+		value = *e.literal
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %q value: %w", errInvalidSearch, e.props[0].Local, err)
+	}
+
+	return &dfs.SearchFilter{Operator: operator, Field: field, Value: value}, nil
+}
+
+// parseSearchTime parses the getlastmodified format or, as sent with the
+// typed literals, the dateTime.tz format.
+func parseSearchTime(s string) (time.Time, error) {
+	res, err := http.ParseTime(s)
+	if err != nil {
+		return time.Parse(time.RFC3339, s)
+	}
+
+	return res, nil
+}
+
+// readSearch decodes a basicsearch request and returns the status of the
+// invalid ones.
+func readSearch(r io.Reader) (*basicsearch, *dfs.SearchFilter, int, error) {
+	var req searchRequest
+	err := ixml.NewDecoder(r).Decode(&req)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, fmt.Errorf("%w: %w", errInvalidSearch, err)
+	}
+
+	if req.Basicsearch == nil {
+		return nil, nil, http.StatusBadRequest, errUnsupportedSearch
+	}
+
+	search := req.Basicsearch
+	if search.Select.Allprop == nil && len(search.Select.Prop) == 0 {
+		return nil, nil, http.StatusBadRequest, fmt.Errorf("%w: missing select", errInvalidSearch)
+	}
+
+	if search.Where == nil {
+		return search, nil, 0, nil
+	}
+
+	if len(search.Where.operands) != 1 {
+		return nil, nil, http.StatusBadRequest, fmt.Errorf("%w: the where clause must have a single operator", errInvalidSearch)
+	}
+
+	filter, err := searchFilter(&search.Where.operands[0])
+	if errors.Is(err, errUnsupportedSearch) {
+		return nil, nil, http.StatusUnprocessableEntity, err
+	}
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, err
+	}
+
+	return search, filter, 0, nil
+}
+
+// handleSearch runs a basicsearch query (RFC 5323) scoped to a single
+// collection of the session.
+//
+// Only the members of the scope are searched, not the scope collection
+// itself. The results are ordered by path, the orderby clause is ignored.
+func (h *Handler) handleSearch(w http.ResponseWriter, r *http.Request, user *users.User, m *mount) (status int, err error) {
+	ctx := withQuotaFinder(r.Context(), h.FileSystem, user)
+
+	search, filter, status, err := readSearch(r.Body)
+	if errors.Is(err, errUnsupportedSearch) && status == http.StatusBadRequest {
+		return writePreconditionError(w, status, "<D:search-grammar-supported/>"), err
+	}
+	if err != nil {
+		return status, err
+	}
+
+	if len(search.Scopes) == 0 {
+		return http.StatusBadRequest, fmt.Errorf("%w: missing scope", errInvalidSearch)
+	}
+
+	if len(search.Scopes) > 1 {
+		return writePreconditionError(w, http.StatusBadRequest, "<D:search-multiple-scope-supported/>"), errInvalidScope
+	}
+
+	scope, status, err := h.resolveScope(r, m, search.Scopes[0].Href)
+	if err != nil {
+		if status == http.StatusBadRequest {
+			return writePreconditionError(w, status, "<D:search-scope-valid/>"), err
+		}
+		return status, err
+	}
+
+	var recursive bool
+	switch search.Scopes[0].Depth {
+	case "", "infinity":
+		recursive = true
+	case "1":
+	default:
+		return writePreconditionError(w, http.StatusBadRequest, "<D:search-scope-valid/>"), errInvalidScope
+	}
+
+	limit := 0
+	if search.Limit != nil {
+		limit = search.Limit.NResults
+	}
+
+	mw := multistatusWriter{w: w}
+	nbResults := 0
+	paginateCmd := sqlstorage.PaginateCmd{
+		StartAfter: map[string]string{"c.path": ""},
+		Limit:      h.Propfind.batchSize(),
+	}
+
+	for {
+		var results []dfs.SearchResult
+		results, err = h.FileSystem.SearchTree(ctx, &dfs.SearchTreeCmd{
+			Root:      scope,
+			Filter:    filter,
+			Recursive: recursive,
+		}, &paginateCmd)
+		if (errors.Is(err, errs.ErrNotFound) || errors.Is(err, dfs.ErrIsNotDir)) && mw.enc == nil {
+			return writePreconditionError(w, http.StatusBadRequest, "<D:search-scope-valid/>"), err
+		}
+		if err == nil {
+			err = h.writeSearchResults(ctx, &mw, m, scope, search, results, limit, &nbResults)
+		}
+
+		if errors.Is(err, errSearchLimit) {
+			err = mw.write(&response{
+				Href:                []string{(&url.URL{Path: r.URL.Path}).EscapedPath()},
+				Status:              fmt.Sprintf("HTTP/1.1 %d %s", StatusInsufficientStorage, StatusText(StatusInsufficientStorage)),
+				Error:               &xmlError{InnerXML: []byte("<D:number-of-matches-within-limits/>")},
+				ResponseDescription: fmt.Sprintf("Only the first %d results are returned", limit),
+			})
+			break
+		}
+		if err != nil || len(results) < paginateCmd.Limit {
+			break
+		}
+
+		paginateCmd.StartAfter["c.path"] = results[len(results)-1].Path()
+	}
+
+	closeErr := mw.close()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if closeErr != nil {
+		return http.StatusInternalServerError, closeErr
+	}
+
+	return 0, nil
+}
+
+// resolveScope returns the collection pointed by the scope href. The
+// relative references are resolved against the request url.
+func (h *Handler) resolveScope(r *http.Request, m *mount, href string) (*dfs.PathCmd, int, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil, http.StatusBadRequest, errInvalidScope
+	}
+
+	u = r.URL.ResolveReference(u)
+	if u.Host != "" && u.Host != r.Host {
+		return nil, http.StatusBadRequest, errInvalidScope
+	}
+
+	scope, _, err := h.resolvePath(m, u.Path)
+	if err != nil || scope == nil {
+		// The virtual root listing the spaces can't be searched.
+		return nil, http.StatusBadRequest, errInvalidScope
+	}
+
+	return scope, http.StatusOK, nil
+}
+
+// writeSearchResults writes a page of results. It returns errSearchLimit
+// once the limit of results is reached.
+func (h *Handler) writeSearchResults(ctx context.Context, mw *multistatusWriter, m *mount, scope *dfs.PathCmd, search *basicsearch, results []dfs.SearchResult, limit int, nbResults *int) error {
+	// Send the responses of the previous page before reading the next one.
+	err := mw.flush()
+	if err != nil {
+		return err
+	}
+
+	fileIDs := []uuid.UUID{}
+	for _, res := range results {
+		if fileID := res.INode().FileID(); fileID != nil {
+			fileIDs = append(fileIDs, *fileID)
+		}
+	}
+
+	fileMetas, err := h.Files.GetAllMetadata(ctx, fileIDs)
+	if err != nil {
+		return fmt.Errorf("failed to GetAllMetadata: %w", err)
+	}
+
+	for _, res := range results {
+		if limit > 0 && *nbResults >= limit {
+			return errSearchLimit
+		}
+		*nbResults++
+
+		info := res.INode()
+		cmd := dfs.NewPathCmd(scope.Space(), res.Path())
+
+		var fileMeta *files.FileMeta
+		if fileID := info.FileID(); fileID != nil {
+			if meta, ok := fileMetas[*fileID]; ok {
+				fileMeta = &meta
+			}
+		}
+
+		var pstats []Propstat
+		if search.Select.Allprop != nil {
+			pstats, err = allprop(ctx, h.FileSystem, &info, fileMeta, cmd, nil)
+		} else {
+			pstats, err = props(ctx, h.FileSystem, &info, fileMeta, cmd, search.Select.Prop)
+		}
+		if err != nil {
+			return err
+		}
+
+		href := h.hrefPath(m, cmd)
+		if href != "/" && info.IsDir() {
+			href += "/"
+		}
+
+		err = mw.write(makePropstatResponse(href, pstats))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
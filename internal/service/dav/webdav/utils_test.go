@@ -3,12 +3,15 @@ package webdav
 import (
 	"bytes"
 	"context"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"path"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/files"
@@ -23,6 +26,7 @@ type TestContext struct {
 	SpacesSvc      spaces.Service
 	UsersSvc       users.Service
 	DavSessionsSvc davsessions.Service
+	DavLocksSvc    davlocks.Service
 
 	FSService dfs.Service
 	Scheduler scheduler.Service
@@ -85,6 +89,7 @@ func buildTestFS(t *testing.T, buildfs []string) *TestContext {
 		SpacesSvc:      serv.SpacesSvc,
 		UsersSvc:       serv.UsersSvc,
 		DavSessionsSvc: serv.DavSessionsSvc,
+		DavLocksSvc:    serv.DavLocksSvc,
 
 		FSService: serv.DFSSvc,
 		Scheduler: serv.SchedulerSvc,
@@ -97,6 +102,68 @@ func buildTestFS(t *testing.T, buildfs []string) *TestContext {
 	}
 }
 
+// newTestHandler returns a Handler built on the TestContext services and
+// served by a test server closed at the end of the test.
+func newTestHandler(t *testing.T, tc *TestContext) (*Handler, *httptest.Server) {
+	t.Helper()
+
+	h := &Handler{
+		FileSystem: tc.FSService,
+		Sessions:   tc.DavSessionsSvc,
+		Spaces:     tc.SpacesSvc,
+		Users:      tc.UsersSvc,
+		Files:      tc.Files,
+		Locks:      tc.DavLocksSvc,
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	return h, srv
+}
+
+// doFunc sends a request to the test server and returns the response with
+// its body. The headers are given as key/value pairs.
+type doFunc func(method, name, content string, headers ...string) (*http.Response, string)
+
+// newTestClient creates a session with cmd and returns a doFunc sending the
+// requests with this session. A nil cmd creates a session for the TestContext
+// user on its space.
+func newTestClient(t *testing.T, tc *TestContext, srv *httptest.Server, cmd *davsessions.CreateCmd) doFunc {
+	t.Helper()
+
+	if cmd == nil {
+		cmd = &davsessions.CreateCmd{
+			Name:     "test session",
+			Username: tc.User.Username(),
+			UserID:   tc.User.ID(),
+			SpaceID:  tc.Space.ID(),
+		}
+	}
+
+	_, token, err := tc.DavSessionsSvc.Create(context.Background(), cmd)
+	require.NoError(t, err)
+
+	return func(method, name, content string, headers ...string) (*http.Response, string) {
+		req, err := http.NewRequest(method, srv.URL+name, strings.NewReader(content))
+		require.NoError(t, err)
+		req.SetBasicAuth(cmd.Username, token)
+
+		for len(headers) >= 2 {
+			req.Header.Add(headers[0], headers[1])
+			headers = headers[2:]
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		return res, string(body)
+	}
+}
+
 // find appends to ss the names of the named file and its children. It is
 // analogous to the Unix find command.
 //
@@ -9,13 +9,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/files"
@@ -23,6 +28,8 @@ import (
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
 	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
 type webdavKeyCtx string
@@ -37,11 +44,39 @@ type Handler struct {
 	Spaces   spaces.Service
 	Users    users.Service
 	Files    files.Service
+	// Locks handles the WebDAV locks shared with the other clients.
+	Locks davlocks.Service
 	// Logger is an optional error logger. If non-nil, it will be called
 	// for all HTTP requests.
 	Logger func(*http.Request, error)
 	// Prefix is the URL path prefix to strip from WebDAV resource paths.
 	Prefix string
+	// Propfind configures the PROPFIND requests.
+	Propfind PropfindConfig
+}
+
+// DefaultPropfindBatchSize is the number of children read at once by a
+// PROPFIND if PropfindConfig.BatchSize is not set.
+const DefaultPropfindBatchSize = 100
+
+// PropfindConfig configures how the PROPFIND requests are walked.
+type PropfindConfig struct {
+	// RefuseInfiniteDepth refuses the "Depth: infinity" requests with the
+	// propfind-finite-depth precondition.
+	RefuseInfiniteDepth bool
+	// InfiniteDepthLimit is the maximum number of resources returned to a
+	// "Depth: infinity" request. Zero means no limit.
+	InfiniteDepthLimit int
+	// BatchSize is the number of children read and sent at once.
+	BatchSize int
+}
+
+func (c PropfindConfig) batchSize() int {
+	if c.BatchSize <= 0 {
+		return DefaultPropfindBatchSize
+	}
+
+	return c.BatchSize
 }
 
 func (h *Handler) stripPrefix(p string) (string, int, error) {
@@ -70,8 +105,8 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, err := h.Sessions.Authenticate(r.Context(), username, secret.NewText(password))
-	if errors.Is(err, davsessions.ErrInvalidCredentials) {
+	session, err := h.Sessions.Authenticate(r.Context(), username, secret.NewText(password), remoteIP(r))
+	if errors.Is(err, davsessions.ErrInvalidCredentials) || errors.Is(err, davsessions.ErrSessionExpired) {
 		w.Header().Add("WWW-Authenticate", `Basic realm="fs"`)
 		w.WriteHeader(http.StatusUnauthorized)
 		return
@@ -87,24 +122,55 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	space, err := h.Spaces.GetUserSpace(r.Context(), session.UserID(), session.SpaceID())
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+	if session.ReadOnly() && isWriteMethod(r.Method) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(StatusText(http.StatusForbidden)))
 		return
 	}
 
-	reqPath, status, err := h.stripPrefix(r.URL.Path)
+	var m *mount
+	var status int
+	switch {
+	case session.AllSpaces():
+		m, status = h.getVirtualMount(r, session)
+	case session.GrantID() != nil:
+		m, status = h.getGrantMount(r, session)
+	default:
+		m, status = h.getSpaceMount(r, session)
+	}
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		w.Write([]byte(StatusText(status)))
+		return
+	}
+
+	pathCmd, status, err := h.resolvePath(m, r.URL.Path)
+	if errors.Is(err, errUnknownSpace) && isWriteMethod(r.Method) {
+		// The spaces can't be created or modified from the virtual root.
+		status = http.StatusForbidden
+	}
 	if err != nil {
 		w.WriteHeader(status)
 		w.Write([]byte(StatusText(status)))
 		return
 	}
 
-	pathCmd := dfs.NewPathCmd(space, reqPath)
+	if m.isVirtual() && pathCmd != nil && isWriteMethod(r.Method) && r.Method != "COPY" {
+		// The source of a copy is not modified, only the destination is
+		// checked.
+		status = h.checkSpaceWrite(r, user.ID(), pathCmd.Space())
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			w.Write([]byte(StatusText(status)))
+			return
+		}
+	}
 
-	switch h.FileSystem {
-	case nil:
+	switch {
+	case h.FileSystem == nil:
 		status, err = http.StatusInternalServerError, errNoFileSystem
+	case pathCmd == nil:
+		status, err = h.handleVirtualRoot(w, r, user, m)
 	default:
 		switch r.Method {
 		case "OPTIONS":
@@ -112,17 +178,23 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		case "GET", "HEAD", "POST":
 			status, err = h.handleGetHeadPost(w, r, pathCmd)
 		case "DELETE":
-			status, err = h.handleDelete(w, r, pathCmd)
+			status, err = h.handleDelete(w, r, user, m, pathCmd)
 		case "PUT":
-			status, err = h.handlePut(w, r, user, pathCmd)
+			status, err = h.handlePut(w, r, user, m, pathCmd)
 		case "MKCOL":
-			status, err = h.handleMkcol(w, r, user, pathCmd)
+			status, err = h.handleMkcol(w, r, user, m, pathCmd)
 		case "COPY", "MOVE":
-			status, err = h.handleCopyMove(w, r, user, space)
+			status, err = h.handleCopyMove(w, r, user, m, pathCmd)
 		case "PROPFIND":
-			status, err = h.handlePropfind(w, r, pathCmd)
+			status, err = h.handlePropfind(w, r, user, m, pathCmd)
 		case "PROPPATCH":
-			status, err = h.handleProppatch(w, r, pathCmd)
+			status, err = h.handleProppatch(w, r, user, m, pathCmd)
+		case "LOCK":
+			status, err = h.handleLock(w, r, user, m, pathCmd)
+		case "UNLOCK":
+			status, err = h.handleUnlock(w, r, user, pathCmd)
+		case "SEARCH":
+			status, err = h.handleSearch(w, r, user, m)
 		}
 	}
 
@@ -137,19 +209,140 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// getSpaceMount mounts the root of the space given by the session. The write
+// methods are refused to the members with a read only role.
+func (h *Handler) getSpaceMount(r *http.Request, session *davsessions.DavSession) (*mount, int) {
+	space, err := h.Spaces.GetUserSpace(r.Context(), session.UserID(), session.SpaceID())
+	if errors.Is(err, errs.ErrUnauthorized) {
+		return nil, http.StatusForbidden
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError
+	}
+
+	if isWriteMethod(r.Method) {
+		status := h.checkSpaceWrite(r, session.UserID(), space)
+		if status != http.StatusOK {
+			return nil, status
+		}
+	}
+
+	return newFolderMount(dfs.NewPathCmd(space, session.RootPath())), http.StatusOK
+}
+
+// getGrantMount mounts the folder shared with the session user. The user is
+// not a member of the space so every path is resolved inside this folder.
+func (h *Handler) getGrantMount(r *http.Request, session *davsessions.DavSession) (*mount, int) {
+	grant, err := h.FileSystem.GetUserGrant(r.Context(), session.UserID(), *session.GrantID())
+	if errors.Is(err, errs.ErrNotFound) {
+		// The grant have been revoked.
+		return nil, http.StatusForbidden
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError
+	}
+
+	if isWriteMethod(r.Method) && !grant.Permission().CanWrite() {
+		return nil, http.StatusForbidden
+	}
+
+	root, err := h.FileSystem.GetGrantPath(r.Context(), grant)
+	if errors.Is(err, errs.ErrNotFound) {
+		// The shared folder is inside the trash.
+		return nil, http.StatusNotFound
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError
+	}
+
+	return newFolderMount(dfs.NewPathCmd(root.Space(), path.Join(root.Path(), session.RootPath()))), http.StatusOK
+}
+
+// getVirtualMount mounts all the spaces of the session user under a virtual
+// root. The spaces are listed for each request so a new space is available
+// without creating a new session. The write accesses are checked once the
+// space of the request is known.
+func (h *Handler) getVirtualMount(r *http.Request, session *davsessions.DavSession) (*mount, int) {
+	userSpaces, err := h.Spaces.GetAllUserSpaces(r.Context(), session.UserID(), nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError
+	}
+
+	return newVirtualMount(userSpaces), http.StatusOK
+}
+
+// checkSpaceWrite refuses the write methods to the members of the space with a
+// read only role.
+func (h *Handler) checkSpaceWrite(r *http.Request, userID uuid.UUID, space *spaces.Space) int {
+	role, err := h.Spaces.GetUserRole(r.Context(), userID, space.ID())
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	if !role.CanWrite() {
+		return http.StatusForbidden
+	}
+
+	return http.StatusOK
+}
+
+// resolvePath converts the url path into a path inside the session mount. The
+// result is nil for the virtual root listing the spaces.
+func (h *Handler) resolvePath(m *mount, urlPath string) (*dfs.PathCmd, int, error) {
+	p, status, err := h.stripPrefix(urlPath)
+	if err != nil {
+		return nil, status, err
+	}
+
+	res, err := m.resolve(p)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+
+	return res, http.StatusOK, nil
+}
+
+// hrefPath is the opposite of resolvePath, it returns the url path of cmd.
+func (h *Handler) hrefPath(m *mount, cmd *dfs.PathCmd) string {
+	return path.Join(h.Prefix, m.href(cmd))
+}
+
+// remoteIP returns the address of the client without its port.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// isWriteMethod returns true for the methods modifying the space content. Those
+// methods are refused to the members with a read only role.
+func isWriteMethod(method string) bool {
+	switch method {
+	case "DELETE", "PUT", "MKCOL", "COPY", "MOVE", "PROPPATCH", "LOCK", "UNLOCK":
+		return true
+	default:
+		return false
+	}
+}
+
 func (h *Handler) handleOptions(w http.ResponseWriter, r *http.Request, pathCmd *dfs.PathCmd) (status int, err error) {
 	ctx := r.Context()
-	allow := "OPTIONS, PUT, MKCOL"
+	allow := "OPTIONS, LOCK, PUT, MKCOL"
 	if fi, err := h.FileSystem.Get(ctx, pathCmd); err == nil {
 		if fi.IsDir() {
-			allow = "OPTIONS, DELETE, PROPPATCH, COPY, MOVE, PROPFIND"
+			allow = "OPTIONS, LOCK, DELETE, PROPPATCH, COPY, MOVE, UNLOCK, PROPFIND, SEARCH"
+			// https://www.rfc-editor.org/rfc/rfc5323#section-3.2
+			w.Header().Set("DASL", "<DAV:basicsearch>")
 		} else {
-			allow = "OPTIONS, GET, HEAD, POST, DELETE, PROPPATCH, COPY, MOVE, PROPFIND, PUT"
+			allow = "OPTIONS, LOCK, GET, HEAD, POST, DELETE, PROPPATCH, COPY, MOVE, UNLOCK, PROPFIND, PUT"
 		}
 	}
 	w.Header().Set("Allow", allow)
 	// http://www.webdav.org/specs/rfc4918.html#dav.compliance.classes
-	w.Header().Set("DAV", "1")
+	w.Header().Set("DAV", "1, 2")
 	// http://msdn.microsoft.com/en-au/library/cc250217.aspx
 	w.Header().Set("MS-Author-Via", "DAV")
 	return 0, nil
@@ -178,54 +371,131 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request, path
 		return http.StatusInternalServerError, fmt.Errorf("failed to get the file metadatas: %w", err)
 	}
 
-	w.Header().Set("ETag", fmt.Sprintf("W/%q", fileMetas.Checksum()))
+	w.Header().Set("ETag", info.ETag())
 	w.Header().Set("Content-Type", fileMetas.MimeType())
 	http.ServeContent(w, r, pathCmd.Path(), info.LastModifiedAt(), f)
 	return 0, nil
 }
 
-func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request, pathCmd *dfs.PathCmd) (status int, err error) {
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request, user *users.User, m *mount, pathCmd *dfs.PathCmd) (status int, err error) {
 	ctx := r.Context()
 
-	// TODO: return MultiStatus where appropriate.
+	tokens, status, err := h.submittedTokens(r, m, pathCmd)
+	if err != nil {
+		return status, err
+	}
+
+	locked, status, err := h.lockedMembers(ctx, user, tokens, pathCmd)
+	if err != nil {
+		return status, err
+	}
 
 	// "godoc os RemoveAll" says that "If the path does not exist, RemoveAll
 	// returns nil (no error)." WebDAV semantics are that it should return a
 	// "404 Not Found". We therefore have to Stat before we RemoveAll.
-	if _, err := h.FileSystem.Get(ctx, pathCmd); err != nil {
-		if errors.Is(err, errs.ErrNotFound) {
-			return http.StatusNotFound, err
+	info, status, err := h.checkPreconditions(ctx, r, pathCmd)
+	if err != nil {
+		return status, err
+	}
+	if info == nil {
+		return http.StatusNotFound, nil
+	}
+
+	if len(locked) > 0 {
+		// Only the members which are not locked can be removed, the collection
+		// itself is kept.
+		failures, err := h.deleteMembers(ctx, user, pathCmd, locked)
+		if err != nil {
+			return http.StatusInternalServerError, err
 		}
-		return http.StatusMethodNotAllowed, err
+
+		return h.writeMemberErrors(w, m, append(lockedErrors(locked), failures...))
 	}
-	if err := h.FileSystem.Remove(ctx, pathCmd); err != nil {
+
+	if err := h.FileSystem.Remove(ctx, user, pathCmd); err != nil {
 		return http.StatusMethodNotAllowed, err
 	}
+	if err := h.Locks.RemoveAll(ctx, pathCmd); err != nil {
+		return http.StatusInternalServerError, err
+	}
 	return http.StatusNoContent, nil
 }
 
-func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request, user *users.User, pathCmd *dfs.PathCmd) (status int, err error) {
-	// TODO(rost): Support the If-Match, If-None-Match headers? See bradfitz'
-	// comments in http.checkEtag.
+func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request, user *users.User, m *mount, pathCmd *dfs.PathCmd) (status int, err error) {
 	ctx := r.Context()
 
+	status, err = h.confirmLocks(r, user, m, pathCmd, pathCmd)
+	if err != nil {
+		return status, err
+	}
+
+	_, status, err = h.checkPreconditions(ctx, r, pathCmd)
+	if err != nil {
+		return status, err
+	}
+
+	// The ownCloud and Nextcloud clients send the modification time of the
+	// local file inside the X-OC-Mtime header.
+	var modifiedAt time.Time
+	if hdr := r.Header.Get("X-OC-Mtime"); hdr != "" {
+		modifiedAt, err = parseMtime(hdr)
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
+	}
+
 	err = h.FileSystem.Upload(ctx, &dfs.UploadCmd{
 		Path:       pathCmd,
 		Content:    r.Body,
 		UploadedBy: user,
+		ModifiedAt: modifiedAt,
 	})
+	if errors.Is(err, dfs.ErrIsADir) {
+		return http.StatusMethodNotAllowed, err
+	}
+
+	if errors.Is(err, dfs.ErrQuotaExceeded) {
+		return http.StatusInsufficientStorage, err
+	}
+
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	info, err := h.FileSystem.Get(ctx, pathCmd)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
 
-	// TODO(peltoche): Put back Etag again?
+	w.Header().Set("ETag", info.ETag())
+	if !modifiedAt.IsZero() {
+		w.Header().Set("X-OC-Mtime", "accepted")
+	}
 
 	return http.StatusCreated, nil
 }
 
-func (h *Handler) handleMkcol(w http.ResponseWriter, r *http.Request, user *users.User, pathCmd *dfs.PathCmd) (status int, err error) {
+// parseMtime parses the X-OC-Mtime header containing a unix timestamp in
+// seconds, with an optional fractional part.
+func parseMtime(s string) (time.Time, error) {
+	secs, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || secs <= 0 || math.IsInf(secs, 0) {
+		return time.Time{}, errInvalidMtime
+	}
+
+	sec, frac := math.Modf(secs)
+
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC(), nil
+}
+
+func (h *Handler) handleMkcol(w http.ResponseWriter, r *http.Request, user *users.User, m *mount, pathCmd *dfs.PathCmd) (status int, err error) {
 	ctx := r.Context()
 
+	status, err = h.confirmLocks(r, user, m, pathCmd, pathCmd)
+	if err != nil {
+		return status, err
+	}
+
 	if r.ContentLength > 0 {
 		return http.StatusUnsupportedMediaType, nil
 	}
@@ -261,7 +531,7 @@ func (h *Handler) handleMkcol(w http.ResponseWriter, r *http.Request, user *user
 	return http.StatusCreated, nil
 }
 
-func (h *Handler) handleCopyMove(w http.ResponseWriter, r *http.Request, user *users.User, space *spaces.Space) (status int, err error) {
+func (h *Handler) handleCopyMove(w http.ResponseWriter, r *http.Request, user *users.User, m *mount, srcPath *dfs.PathCmd) (status int, err error) {
 	hdr := r.Header.Get("Destination")
 	if hdr == "" {
 		return http.StatusBadRequest, errInvalidDestination
@@ -274,39 +544,24 @@ func (h *Handler) handleCopyMove(w http.ResponseWriter, r *http.Request, user *u
 		return http.StatusBadGateway, errInvalidDestination
 	}
 
-	src, status, err := h.stripPrefix(r.URL.Path)
+	dstPath, status, err := h.resolvePath(m, u.Path)
 	if err != nil {
 		return status, err
 	}
-	srcPath := dfs.NewPathCmd(space, src)
 
-	dst, status, err := h.stripPrefix(u.Path)
-	if err != nil {
-		return status, err
+	if dstPath == nil {
+		// The virtual root listing the spaces can't be modified.
+		return http.StatusForbidden, errInvalidDestination
 	}
-	dstPath := dfs.NewPathCmd(space, dst)
 
-	if dst == "" {
-		return http.StatusBadGateway, errInvalidDestination
-	}
-	if dst == src {
+	if dstPath.Equal(*srcPath) {
 		return http.StatusForbidden, errDestinationEqualsSource
 	}
 
-	ctx := r.Context()
-
-	_, err = h.FileSystem.Get(ctx, srcPath)
-	if err != nil {
-		if errors.Is(err, errs.ErrNotFound) {
-			return http.StatusConflict, err
-		}
-		return http.StatusInternalServerError, err
-	}
-
+	// Section 9.8.3 says that "The COPY method on a collection without a Depth
+	// header must act as if a Depth header with value "infinity" was included".
+	depth := infiniteDepth
 	if r.Method == "COPY" {
-		// Section 9.8.3 says that "The COPY method on a collection without a Depth
-		// header must act as if a Depth header with value "infinity" was included".
-		depth := infiniteDepth
 		if hdr := r.Header.Get("Depth"); hdr != "" {
 			depth = parseDepth(hdr)
 			if depth != 0 && depth != infiniteDepth {
@@ -315,7 +570,67 @@ func (h *Handler) handleCopyMove(w http.ResponseWriter, r *http.Request, user *u
 				return http.StatusBadRequest, errInvalidDepth
 			}
 		}
-		return copyFiles(ctx, user, h.FileSystem, srcPath, dstPath, r.Header.Get("Overwrite") != "F", depth, 0)
+	}
+
+	overwrite := r.Header.Get("Overwrite") != "F"
+
+	// Copying or moving /A/ into /A/B/ would never end, as noted by the section
+	// 9.8.3, and overwriting /A/ with /A/B/ would remove the source first.
+	if depth == infiniteDepth && srcPath.Contains(*dstPath) {
+		return http.StatusForbidden, errDestinationInsideSource
+	}
+	if overwrite && dstPath.Contains(*srcPath) {
+		return http.StatusForbidden, errSourceInsideDestination
+	}
+
+	if m.isVirtual() {
+		// The destination can be inside an other space than the source.
+		status = h.checkSpaceWrite(r, user.ID(), dstPath.Space())
+		if status != http.StatusOK {
+			return status, nil
+		}
+	}
+
+	ctx := r.Context()
+
+	tokens, status, err := h.submittedTokens(r, m, srcPath)
+	if err != nil {
+		return status, err
+	}
+
+	// The source is not modified by a copy, only the destination must be
+	// confirmed.
+	targets := []*dfs.PathCmd{dstPath}
+	if r.Method == "MOVE" {
+		targets = []*dfs.PathCmd{srcPath, dstPath}
+	}
+
+	var locked []*dfs.PathCmd
+	for _, target := range targets {
+		members, status, err := h.lockedMembers(ctx, user, tokens, target)
+		if err != nil {
+			return status, err
+		}
+		locked = append(locked, members...)
+	}
+
+	srcInfo, status, err := h.checkPreconditions(ctx, r, srcPath)
+	if err != nil {
+		return status, err
+	}
+	if srcInfo == nil {
+		return http.StatusConflict, nil
+	}
+
+	if len(locked) > 0 {
+		// The sections 9.8.5 and 9.9.4 describe a 207 (Multi-Status) when
+		// "errors on some of them prevented the operation from taking place".
+		// A copy or a move can't be done partially so nothing is modified.
+		return h.writeMemberErrors(w, m, lockedErrors(locked))
+	}
+
+	if r.Method == "COPY" {
+		return copyFiles(ctx, user, h.FileSystem, srcPath, dstPath, overwrite, depth)
 	}
 
 	// Section 9.9.2 says that "The MOVE method on a collection must act as if
@@ -327,20 +642,30 @@ func (h *Handler) handleCopyMove(w http.ResponseWriter, r *http.Request, user *u
 		}
 	}
 
-	dstInfo, err := h.FileSystem.Get(ctx, dfs.NewPathCmd(space, dst))
+	dstInfo, err := h.FileSystem.Get(ctx, dstPath)
 	if err != nil && !errors.Is(err, errs.ErrNotFound) {
 		return http.StatusInternalServerError, err
 	}
 
-	if r.Header.Get("Overwrite") == "F" && dstInfo != nil {
+	if !overwrite && dstInfo != nil {
 		return http.StatusPreconditionFailed, nil
 	}
 
 	err = h.FileSystem.Move(ctx, &dfs.MoveCmd{
-		Src:     dfs.NewPathCmd(space, src),
-		Dst:     dfs.NewPathCmd(space, dst),
+		Src:     srcPath,
+		Dst:     dstPath,
 		MovedBy: user,
 	})
+	if errors.Is(err, dfs.ErrQuotaExceeded) {
+		return http.StatusInsufficientStorage, err
+	}
+
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	// Section 7.7 says that the locks are not moved with the resource.
+	err = h.Locks.RemoveAll(ctx, srcPath)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
@@ -352,14 +677,17 @@ func (h *Handler) handleCopyMove(w http.ResponseWriter, r *http.Request, user *u
 	return http.StatusCreated, nil
 }
 
-func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request, cmd *dfs.PathCmd) (status int, err error) {
-	ctx := r.Context()
-	fi, err := h.FileSystem.Get(ctx, cmd)
-	if err != nil {
-		if errors.Is(err, errs.ErrNotFound) {
-			return http.StatusNotFound, err
+func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request, user *users.User, m *mount, cmd *dfs.PathCmd) (status int, err error) {
+	ctx := withPropsPrefetcher(withQuotaFinder(r.Context(), h.FileSystem, user), h.FileSystem)
+	var fi *dfs.INode
+	if cmd != nil {
+		fi, err = h.FileSystem.Get(ctx, cmd)
+		if err != nil {
+			if errors.Is(err, errs.ErrNotFound) {
+				return http.StatusNotFound, err
+			}
+			return http.StatusMethodNotAllowed, err
 		}
-		return http.StatusMethodNotAllowed, err
 	}
 	depth := infiniteDepth
 	if hdr := r.Header.Get("Depth"); hdr != "" {
@@ -368,6 +696,9 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request, cmd *df
 			return http.StatusBadRequest, errInvalidDepth
 		}
 	}
+	if depth == infiniteDepth && h.Propfind.RefuseInfiniteDepth {
+		return writePreconditionError(w, http.StatusForbidden, "<D:propfind-finite-depth/>"), errInfiniteDepthRefused
+	}
 	pf, status, err := readPropfind(r.Body)
 	if err != nil {
 		return status, err
@@ -375,21 +706,65 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request, cmd *df
 
 	mw := multistatusWriter{w: w}
 
+	// If the listing can be truncated the response of the requested resource
+	// is written last, once known if it must carry the
+	// number-of-matches-within-limits error.
+	mayTruncate := depth == infiniteDepth && h.Propfind.InfiniteDepthLimit > 0
+	var reqResp *response
+	write := func(resp *response) error {
+		if mayTruncate && reqResp == nil {
+			reqResp = resp
+			return nil
+		}
+		return mw.write(resp)
+	}
+
+	// The metadata of the files are fetched once per page of children
+	// and removed once their response is written.
+	fileMetas := map[uuid.UUID]files.FileMeta{}
+	nbResponses := 0
+
 	walkFn := func(cmd *dfs.PathCmd, info *dfs.INode, err error) error {
 		if err != nil {
 			return handlePropfindError(err, info)
 		}
 
+		if depth == infiniteDepth && h.Propfind.InfiniteDepthLimit > 0 && nbResponses >= h.Propfind.InfiniteDepthLimit {
+			return errInfiniteDepthLimit
+		}
+		nbResponses++
+
+		href := h.hrefPath(m, cmd)
+		if href != "/" && info.IsDir() {
+			href += "/"
+		}
+
 		var fileMeta *files.FileMeta
-		if info.FileID() != nil {
-			// TODO: Log the error?
-			fileMeta, _ = h.Files.GetMetadata(ctx, *info.FileID())
+		if fileID := info.FileID(); fileID != nil {
+			if meta, ok := fileMetas[*fileID]; ok {
+				fileMeta = &meta
+				delete(fileMetas, *fileID)
+			} else {
+				var metaErr error
+				fileMeta, metaErr = h.Files.GetMetadata(ctx, *fileID)
+				if metaErr != nil && !errors.Is(metaErr, files.ErrNotExist) {
+					// The other resources are still listed, only this one is
+					// reported as failed.
+					if h.Logger != nil {
+						h.Logger(r, fmt.Errorf("failed to GetMetadata for %q: %w", cmd.Path(), metaErr))
+					}
+					return write(&response{
+						Href:   []string{(&url.URL{Path: href}).EscapedPath()},
+						Status: fmt.Sprintf("HTTP/1.1 %d %s", http.StatusInternalServerError, StatusText(http.StatusInternalServerError)),
+					})
+				}
+			}
 		}
 
 		var pstats []Propstat
 		switch {
 		case pf.Propname != nil:
-			pnames, err := propnames(ctx, info, cmd)
+			pnames, err := propnames(ctx, h.FileSystem, info, cmd)
 			if err != nil {
 				return handlePropfindError(err, info)
 			}
@@ -399,21 +774,67 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request, cmd *df
 			}
 			pstats = append(pstats, pstat)
 		case pf.Allprop != nil:
-			pstats, err = allprop(ctx, info, fileMeta, cmd, pf.Prop)
+			pstats, err = allprop(ctx, h.FileSystem, info, fileMeta, cmd, pf.Prop)
 		default:
-			pstats, err = props(ctx, info, fileMeta, cmd, pf.Prop)
+			pstats, err = props(ctx, h.FileSystem, info, fileMeta, cmd, pf.Prop)
 		}
 		if err != nil {
 			return handlePropfindError(err, info)
 		}
-		href := path.Join(h.Prefix, cmd.Path())
-		if href != "/" && info.IsDir() {
-			href += "/"
-		}
-		return mw.write(makePropstatResponse(href, pstats))
+		return write(makePropstatResponse(href, pstats))
+	}
+
+	walker := fsWalker{
+		fs:        h.FileSystem,
+		batchSize: h.Propfind.batchSize(),
+		walkFn:    walkFn,
+		pageFn: func(infos []dfs.INode) error {
+			// Send the responses of the previous page before reading the next one.
+			err := mw.flush()
+			if err != nil {
+				return err
+			}
+
+			fileIDs := []uuid.UUID{}
+			for _, info := range infos {
+				if info.FileID() != nil {
+					fileIDs = append(fileIDs, *info.FileID())
+				}
+			}
+
+			metas, err := h.Files.GetAllMetadata(ctx, fileIDs)
+			if err != nil {
+				return fmt.Errorf("failed to GetAllMetadata: %w", err)
+			}
+
+			for id, meta := range metas {
+				fileMetas[id] = meta
+			}
+
+			return prefetchProps(ctx, infos)
+		},
 	}
 
-	walkErr := walkFS(ctx, h.FileSystem, depth, cmd, fi, walkFn)
+	var walkErr error
+	if cmd == nil {
+		walkErr = h.walkVirtualRoot(ctx, write, pf, m, depth, &walker)
+	} else {
+		walkErr = walker.walk(ctx, depth, cmd, fi)
+	}
+	if reqResp != nil {
+		if errors.Is(walkErr, errInfiniteDepthLimit) {
+			// The status is already sent so the truncation is reported
+			// inside the response of the requested resource, as for the
+			// searches exceeding a limit.
+			reqResp.Error = &xmlError{InnerXML: []byte("<D:number-of-matches-within-limits/>")}
+			reqResp.ResponseDescription = fmt.Sprintf("Only the first %d resources are listed", h.Propfind.InfiniteDepthLimit)
+			walkErr = nil
+		}
+		writeErr := mw.write(reqResp)
+		if walkErr == nil {
+			walkErr = writeErr
+		}
+	}
 	closeErr := mw.close()
 	if walkErr != nil {
 		return http.StatusInternalServerError, walkErr
@@ -424,9 +845,81 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request, cmd *df
 	return 0, nil
 }
 
-func (h *Handler) handleProppatch(w http.ResponseWriter, r *http.Request, pathCmd *dfs.PathCmd) (status int, err error) {
+// writePreconditionError writes an error response with the given
+// precondition or postcondition element as body and returns a zero
+// status, the response being already sent.
+func writePreconditionError(w http.ResponseWriter, status int, condition string) int {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><D:error xmlns:D="DAV:">%s</D:error>`, condition)
+
+	return 0
+}
+
+// walkVirtualRoot writes the response of the virtual root listing the spaces
+// and then walks the root of each space as its members.
+func (h *Handler) walkVirtualRoot(ctx context.Context, write func(resp *response) error, pf propfind, m *mount, depth int, walker *fsWalker) error {
+	href := path.Join(h.Prefix, "/")
+	if href != "/" {
+		href += "/"
+	}
+
+	err := write(makePropstatResponse(href, virtualRootProps(pf)))
+	if err != nil {
+		return err
+	}
+
+	if depth == 0 {
+		return nil
+	}
+	if depth == 1 {
+		depth = 0
+	}
+
+	for i := range m.spaces {
+		root := dfs.NewPathCmd(&m.spaces[i], "/")
+		info, err := h.FileSystem.Get(ctx, root)
+		if err != nil {
+			return fmt.Errorf("failed to get the root of the space %q: %w", m.spaces[i].ID(), err)
+		}
+
+		err = walker.walk(ctx, depth, root, info)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handleVirtualRoot handles the requests on the virtual root listing the
+// spaces. It can only be listed, its content is managed from the web
+// interface.
+func (h *Handler) handleVirtualRoot(w http.ResponseWriter, r *http.Request, user *users.User, m *mount) (status int, err error) {
+	switch r.Method {
+	case "OPTIONS":
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, SEARCH")
+		w.Header().Set("DAV", "1, 2")
+		w.Header().Set("DASL", "<DAV:basicsearch>")
+		w.Header().Set("MS-Author-Via", "DAV")
+		return 0, nil
+	case "PROPFIND":
+		return h.handlePropfind(w, r, user, m, nil)
+	case "SEARCH":
+		return h.handleSearch(w, r, user, m)
+	default:
+		return http.StatusMethodNotAllowed, errVirtualRoot
+	}
+}
+
+func (h *Handler) handleProppatch(w http.ResponseWriter, r *http.Request, user *users.User, m *mount, pathCmd *dfs.PathCmd) (status int, err error) {
 	ctx := r.Context()
 
+	status, err = h.confirmLocks(r, user, m, pathCmd, pathCmd)
+	if err != nil {
+		return status, err
+	}
+
 	if _, err := h.FileSystem.Get(ctx, pathCmd); err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			return http.StatusNotFound, err
@@ -437,7 +930,7 @@ func (h *Handler) handleProppatch(w http.ResponseWriter, r *http.Request, pathCm
 	if err != nil {
 		return status, err
 	}
-	pstats, err := patch(ctx, h.FileSystem, pathCmd, patches)
+	pstats, err := patch(ctx, h.FileSystem, user, pathCmd, patches)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
@@ -555,14 +1048,25 @@ func StatusText(code int) string {
 
 var (
 	errDestinationEqualsSource = errors.New("webdav: destination equals source")
+	errDestinationInsideSource = errors.New("webdav: destination inside the source")
+	errInfiniteDepthLimit      = errors.New("webdav: infinite depth limit reached")
+	errInfiniteDepthRefused    = errors.New("webdav: infinite depth refused")
 	errInvalidDepth            = errors.New("webdav: invalid depth")
 	errInvalidDestination      = errors.New("webdav: invalid destination")
+	errInvalidIfHeader         = errors.New("webdav: invalid If header")
+	errInvalidLockInfo         = errors.New("webdav: invalid lock info")
+	errInvalidLockToken        = errors.New("webdav: invalid lock token")
+	errInvalidMtime            = errors.New("webdav: invalid X-OC-Mtime")
 	errInvalidPropfind         = errors.New("webdav: invalid propfind")
 	errInvalidProppatch        = errors.New("webdav: invalid proppatch")
 	errInvalidResponse         = errors.New("webdav: invalid response")
+	errInvalidTimeout          = errors.New("webdav: invalid timeout")
 	errNoFileSystem            = errors.New("webdav: no file system")
+	errPreconditionFailed      = errors.New("webdav: precondition failed")
 	errPrefixMismatch          = errors.New("webdav: prefix mismatch")
-	errRecursionTooDeep        = errors.New("webdav: recursion too deep")
+	errSourceInsideDestination = errors.New("webdav: source inside the destination")
+	errUnknownSpace            = errors.New("webdav: unknown space")
+	errVirtualRoot             = errors.New("webdav: the virtual root can't be modified")
 )
 
 type WalkFunc func(cmd *dfs.PathCmd, info *dfs.INode, err error) error
@@ -573,8 +1077,25 @@ type WalkFunc func(cmd *dfs.PathCmd, info *dfs.INode, err error) error
 // walkFS calls walkFn. If a visited file system node is a directory and
 // walkFn returns filepath.SkipDir, walkFS will skip traversal of this node.
 func walkFS(ctx context.Context, fs dfs.Service, depth int, cmd *dfs.PathCmd, info *dfs.INode, walkFn WalkFunc) error {
+	w := fsWalker{fs: fs, batchSize: DefaultPropfindBatchSize, walkFn: walkFn}
+
+	return w.walk(ctx, depth, cmd, info)
+}
+
+// fsWalker is the walkFS implementation. It reads the directories by pages
+// of batchSize children so that a large tree is never loaded at once.
+type fsWalker struct {
+	fs        dfs.Service
+	batchSize int
+	walkFn    WalkFunc
+	// pageFn is an optional function called with each page of children
+	// before they are walked.
+	pageFn func(infos []dfs.INode) error
+}
+
+func (w *fsWalker) walk(ctx context.Context, depth int, cmd *dfs.PathCmd, info *dfs.INode) error {
 	// This implementation is based on Walk's code in the standard path/filepath package.
-	err := walkFn(cmd, info, nil)
+	err := w.walkFn(cmd, info, nil)
 	if err != nil {
 		if info.IsDir() && errors.Is(err, filepath.SkipDir) {
 			return nil
@@ -588,20 +1109,38 @@ func walkFS(ctx context.Context, fs dfs.Service, depth int, cmd *dfs.PathCmd, in
 		depth = 0
 	}
 
-	// Read directory names.
-	fileInfos, err := fs.ListDir(ctx, cmd, nil)
-	if err != nil {
-		return walkFn(cmd, info, err)
+	paginateCmd := sqlstorage.PaginateCmd{
+		StartAfter: map[string]string{"name": ""},
+		Limit:      w.batchSize,
 	}
-
-	for _, fileInfo := range fileInfos {
-		newPath := dfs.NewPathCmd(cmd.Space(), path.Join(cmd.Path(), fileInfo.Name()))
-		err = walkFS(ctx, fs, depth, newPath, &fileInfo, walkFn)
+	for {
+		// Read the children by inode, the directory path is already resolved.
+		fileInfos, err := w.fs.ListChildren(ctx, info, &paginateCmd)
 		if err != nil {
-			if !fileInfo.IsDir() || errors.Is(err, filepath.SkipDir) {
+			return w.walkFn(cmd, info, err)
+		}
+
+		if w.pageFn != nil && len(fileInfos) > 0 {
+			err = w.pageFn(fileInfos)
+			if err != nil {
 				return err
 			}
 		}
+
+		for _, fileInfo := range fileInfos {
+			newPath := dfs.NewPathCmd(cmd.Space(), path.Join(cmd.Path(), fileInfo.Name()))
+			err = w.walk(ctx, depth, newPath, &fileInfo)
+			if err != nil {
+				if !fileInfo.IsDir() || !errors.Is(err, filepath.SkipDir) {
+					return err
+				}
+			}
+		}
+
+		if len(fileInfos) < w.batchSize {
+			return nil
+		}
+
+		paginateCmd.StartAfter["name"] = fileInfos[len(fileInfos)-1].Name()
 	}
-	return nil
 }
@@ -18,10 +18,19 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
 // TODO: add tests to check XML responses with the expected prefix path
@@ -71,6 +80,7 @@ func TestPrefix(t *testing.T) {
 			Spaces:     tc.SpacesSvc,
 			Users:      tc.UsersSvc,
 			Files:      tc.Files,
+			Locks:      tc.DavLocksSvc,
 			Logger: func(_ *http.Request, err error) {
 				if err != nil {
 					t.Fatalf("error from the webdav: %q", err)
@@ -338,6 +348,7 @@ func TestFilenameEscape(t *testing.T) {
 		Files:      tc.Files,
 		Users:      tc.UsersSvc,
 		Spaces:     tc.SpacesSvc,
+		Locks:      tc.DavLocksSvc,
 		Logger: func(_ *http.Request, err error) {
 			if err != nil {
 				t.Fatalf("error from the webdav: %q", err)
@@ -564,5 +575,1010 @@ func TestWalkFS(t *testing.T) {
 			t.Errorf("%s:\ngot  %q\nwant %q", tc.desc, got, tc.want)
 			continue
 		}
+
+		// The same walk reading the directories one child at a time.
+		got = nil
+		walker := fsWalker{fs: fs, batchSize: 1, walkFn: traceFn}
+		err = walker.walk(ctx, tc.depth, dfs.NewPathCmd(testContext.Space, tc.startAt), fi)
+		if err != nil {
+			t.Errorf("%s (paginated):\ngot error %v, want nil", tc.desc, err)
+			continue
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s (paginated):\ngot  %q\nwant %q", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestPutWithQuota(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{})
+
+	_, srv := newTestHandler(t, tc)
+
+	do := newTestClient(t, tc, srv, nil)
+
+	_, err := tc.SpacesSvc.SetQuota(ctx, &spaces.SetQuotaCmd{
+		User:    tc.User,
+		SpaceID: tc.Space.ID(),
+		Quota:   10,
+	})
+	require.NoError(t, err)
+
+	res, _ := do(http.MethodPut, "/small.txt", "small")
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	res, _ = do(http.MethodPut, "/big.txt", "some too big content")
+	require.Equal(t, http.StatusInsufficientStorage, res.StatusCode)
+}
+
+func TestWriteWithViewerRole(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{"write /foo.txt some-content"})
+
+	_, srv := newTestHandler(t, tc)
+
+	do := newTestClient(t, tc, srv, nil)
+
+	_, err := tc.SpacesSvc.SetMemberRole(ctx, &spaces.SetMemberRoleCmd{
+		User:     tc.User,
+		MemberID: tc.User.ID(),
+		SpaceID:  tc.Space.ID(),
+		Role:     spaces.RoleViewer,
+	})
+	require.NoError(t, err)
+
+	res, _ := do(http.MethodGet, "/foo.txt", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, _ = do(http.MethodPut, "/bar.txt", "some-content")
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	res, _ = do("MKCOL", "/dir", "")
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	res, _ = do(http.MethodDelete, "/foo.txt", "")
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	_, err = tc.FSService.Get(ctx, dfs.NewPathCmd(tc.Space, "/foo.txt"))
+	require.NoError(t, err)
+}
+
+func TestSharedFolder(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{"mkdir /shared", "write /shared/foo.txt some-content", "write /secret.txt some-secret"})
+
+	_, srv := newTestHandler(t, tc)
+
+	bob, err := tc.UsersSvc.Create(ctx, &users.CreateCmd{
+		CreatedBy: tc.User,
+		Username:  "bob",
+		Password:  secret.NewText("bob-password"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, tc.Runner.Run(ctx))
+
+	newClient := func(permission dfs.Permission) doFunc {
+		grant, err := tc.FSService.CreateGrant(ctx, &dfs.CreateGrantCmd{
+			Path:       dfs.NewPathCmd(tc.Space, "/shared"),
+			User:       bob,
+			Permission: permission,
+			CreatedBy:  tc.User,
+		})
+		require.NoError(t, err)
+
+		return newTestClient(t, tc, srv, &davsessions.CreateCmd{
+			Name:     "test session",
+			Username: bob.Username(),
+			UserID:   bob.ID(),
+			GrantID:  ptr.To(grant.ID()),
+		})
+	}
+
+	t.Run("with a read grant", func(t *testing.T) {
+		do := newClient(dfs.PermissionRead)
+
+		res, body := do(http.MethodGet, "/foo.txt", "")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "some-content", body)
+
+		res, _ = do(http.MethodGet, "/../secret.txt", "")
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+
+		res, body = do("PROPFIND", "/", "", "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "<D:href>/foo.txt</D:href>")
+		require.NotContains(t, body, "/shared")
+
+		res, _ = do(http.MethodPut, "/bar.txt", "some-content")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		require.NoError(t, tc.FSService.DeleteAllUserGrants(ctx, bob.ID()))
+	})
+
+	t.Run("with a write grant", func(t *testing.T) {
+		do := newClient(dfs.PermissionWrite)
+
+		res, _ := do(http.MethodPut, "/bar.txt", "some-content")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		_, err := tc.FSService.Get(ctx, dfs.NewPathCmd(tc.Space, "/shared/bar.txt"))
+		require.NoError(t, err)
+
+		res, _ = do("MOVE", "/bar.txt", "", "Destination", srv.URL+"/../moved.txt")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		require.NoError(t, tc.Runner.Run(ctx))
+
+		_, err = tc.FSService.Get(ctx, dfs.NewPathCmd(tc.Space, "/shared/moved.txt"))
+		require.NoError(t, err)
+	})
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{"write /foo.txt some-content", "mkdir /dir"})
+
+	_, srv := newTestHandler(t, tc)
+
+	do := newTestClient(t, tc, srv, nil)
+
+	lockInfo := func(scope string) string {
+		return `<?xml version="1.0" encoding="utf-8" ?>` +
+			`<D:lockinfo xmlns:D="DAV:">` +
+			`<D:lockscope><D:` + scope + `/></D:lockscope>` +
+			`<D:locktype><D:write/></D:locktype>` +
+			`<D:owner><D:href>http://example.org/~gopher</D:href></D:owner>` +
+			`</D:lockinfo>`
+	}
+
+	t.Run("with an exclusive lock on a file", func(t *testing.T) {
+		res, body := do("LOCK", "/foo.txt", lockInfo("exclusive"), "Depth", "0")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Contains(t, body, "<D:lockscope><D:exclusive/></D:lockscope>")
+		require.Contains(t, body, "<D:lockroot><D:href>/foo.txt</D:href></D:lockroot>")
+
+		lockToken := res.Header.Get("Lock-Token")
+		require.Regexp(t, `^<urn:uuid:.*>$`, lockToken)
+
+		res, _ = do("LOCK", "/foo.txt", lockInfo("shared"), "Depth", "0")
+		require.Equal(t, StatusLocked, res.StatusCode)
+
+		res, _ = do(http.MethodPut, "/foo.txt", "new-content")
+		require.Equal(t, StatusLocked, res.StatusCode)
+
+		res, _ = do(http.MethodPut, "/foo.txt", "new-content", "If", "(<urn:uuid:unknown>)")
+		require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+		res, _ = do(http.MethodPut, "/foo.txt", "new-content", "If", "("+lockToken+")")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		res, body = do("LOCK", "/foo.txt", "", "If", "("+lockToken+")", "Timeout", "Second-100")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Contains(t, body, "<D:timeout>Second-100</D:timeout>")
+
+		res, _ = do("UNLOCK", "/foo.txt", "", "Lock-Token", "<urn:uuid:unknown>")
+		require.Equal(t, http.StatusConflict, res.StatusCode)
+
+		res, _ = do("UNLOCK", "/foo.txt", "", "Lock-Token", lockToken)
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		res, _ = do(http.MethodPut, "/foo.txt", "some-content")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+	})
+
+	t.Run("with a lock on a directory", func(t *testing.T) {
+		res, _ := do("LOCK", "/dir", lockInfo("exclusive"))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		lockToken := res.Header.Get("Lock-Token")
+
+		res, _ = do(http.MethodPut, "/dir/bar.txt", "some-content")
+		require.Equal(t, StatusLocked, res.StatusCode)
+
+		res, _ = do("MOVE", "/foo.txt", "", "Destination", srv.URL+"/dir/foo.txt")
+		require.Equal(t, StatusLocked, res.StatusCode)
+
+		res, _ = do(http.MethodPut, "/dir/bar.txt", "some-content", "If", "</dir> ("+lockToken+")")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		require.NoError(t, tc.Runner.Run(ctx))
+
+		res, _ = do(http.MethodDelete, "/dir", "", "If", "("+lockToken+")")
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		_, err := tc.DavLocksSvc.GetByToken(ctx, strings.Trim(lockToken, "<>"))
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("with a lock on an unmapped url", func(t *testing.T) {
+		res, _ := do("LOCK", "/new.txt", lockInfo("exclusive"))
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		_, err := tc.FSService.Get(ctx, dfs.NewPathCmd(tc.Space, "/new.txt"))
+		require.NoError(t, err)
+
+		res, _ = do("LOCK", "/unknown/new.txt", lockInfo("exclusive"))
+		require.Equal(t, http.StatusConflict, res.StatusCode)
+	})
+
+	t.Run("OPTIONS advertises the class 2", func(t *testing.T) {
+		res, _ := do("OPTIONS", "/foo.txt", "")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "1, 2", res.Header.Get("DAV"))
+		require.Contains(t, res.Header.Get("Allow"), "LOCK")
+	})
+}
+
+func TestConditionalRequests(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{"write /foo.txt some-content"})
+
+	_, srv := newTestHandler(t, tc)
+
+	do := newTestClient(t, tc, srv, nil)
+
+	info, err := tc.FSService.Get(ctx, dfs.NewPathCmd(tc.Space, "/foo.txt"))
+	require.NoError(t, err)
+	etag := info.ETag()
+
+	t.Run("GET returns the ETag", func(t *testing.T) {
+		res, body := do(http.MethodGet, "/foo.txt", "")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, etag, res.Header.Get("ETag"))
+		require.Equal(t, "some-content", body)
+
+		res, _ = do(http.MethodGet, "/foo.txt", "", "If-None-Match", etag)
+		require.Equal(t, http.StatusNotModified, res.StatusCode)
+
+		res, _ = do(http.MethodGet, "/foo.txt", "", "If-Modified-Since", info.LastModifiedAt().Add(time.Minute).UTC().Format(http.TimeFormat))
+		require.Equal(t, http.StatusNotModified, res.StatusCode)
+
+		res, _ = do(http.MethodGet, "/foo.txt", "", "If-Modified-Since", info.LastModifiedAt().Add(-time.Minute).UTC().Format(http.TimeFormat))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("PROPFIND returns the ETag", func(t *testing.T) {
+		res, body := do("PROPFIND", "/foo.txt", "", "Depth", "0")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "<D:getetag>"+escapeXML(etag)+"</D:getetag>")
+	})
+
+	t.Run("PUT with preconditions", func(t *testing.T) {
+		res, _ := do(http.MethodPut, "/foo.txt", "new-content", "If-None-Match", "*")
+		require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+		res, _ = do(http.MethodPut, "/foo.txt", "new-content", "If-Match", `"invalid"`)
+		require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+		res, _ = do(http.MethodPut, "/foo.txt", "new-content", "If-Unmodified-Since", info.LastModifiedAt().Add(-time.Minute).UTC().Format(http.TimeFormat))
+		require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+		res, _ = do(http.MethodPut, "/foo.txt", "new-content", "If-Match", etag)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		newETag := res.Header.Get("ETag")
+		require.NotEmpty(t, newETag)
+		require.NotEqual(t, etag, newETag)
+
+		res, _ = do(http.MethodPut, "/foo.txt", "other-content", "If-Match", etag)
+		require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+		res, _ = do(http.MethodPut, "/new.txt", "new-content", "If-None-Match", "*")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		res, _ = do(http.MethodPut, "/unknown.txt", "new-content", "If-Match", "*")
+		require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	})
+
+	t.Run("DELETE, COPY and MOVE with preconditions", func(t *testing.T) {
+		res, _ := do(http.MethodDelete, "/new.txt", "", "If-Match", `"invalid"`)
+		require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+		res, _ = do("COPY", "/new.txt", "", "Destination", srv.URL+"/copy.txt", "If-Match", `"invalid"`)
+		require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+		res, _ = do("MOVE", "/new.txt", "", "Destination", srv.URL+"/moved.txt", "If-None-Match", "*")
+		require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+		info, err := tc.FSService.Get(ctx, dfs.NewPathCmd(tc.Space, "/new.txt"))
+		require.NoError(t, err)
+
+		res, _ = do(http.MethodDelete, "/new.txt", "", "If-Match", info.ETag())
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+	})
+}
+
+func TestDeadProps(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{"mkdir /dir", "write /dir/foo.txt some-content"})
+
+	_, srv := newTestHandler(t, tc)
+
+	do := newTestClient(t, tc, srv, nil)
+
+	t.Run("PROPPATCH set", func(t *testing.T) {
+		res, body := do("PROPPATCH", "/dir/foo.txt", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propertyupdate xmlns:D="DAV:" xmlns:Z="urn:test">
+				<D:set><D:prop><Z:color>red</Z:color><Z:size>42</Z:size></D:prop></D:set>
+			</D:propertyupdate>`)
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "HTTP/1.1 200 OK")
+	})
+
+	t.Run("PROPPATCH remove", func(t *testing.T) {
+		res, body := do("PROPPATCH", "/dir/foo.txt", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propertyupdate xmlns:D="DAV:" xmlns:Z="urn:test">
+				<D:remove><D:prop><Z:size/></D:prop></D:remove>
+			</D:propertyupdate>`)
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "HTTP/1.1 200 OK")
+	})
+
+	t.Run("PROPPATCH a live property is forbidden", func(t *testing.T) {
+		res, body := do("PROPPATCH", "/dir/foo.txt", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propertyupdate xmlns:D="DAV:" xmlns:Z="urn:test">
+				<D:set><D:prop><D:getetag>foo</D:getetag><Z:shape>circle</Z:shape></D:prop></D:set>
+			</D:propertyupdate>`)
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "HTTP/1.1 403 Forbidden")
+		require.Contains(t, body, "HTTP/1.1 424 Failed Dependency")
+	})
+
+	t.Run("PROPFIND allprop returns the dead properties", func(t *testing.T) {
+		res, body := do("PROPFIND", "/dir/foo.txt", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propfind xmlns:D="DAV:"><D:allprop/></D:propfind>`, "Depth", "0")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, `<color xmlns="urn:test">red</color>`)
+		require.NotContains(t, body, "size")
+		require.NotContains(t, body, "shape")
+	})
+
+	t.Run("PROPFIND propname returns the dead properties", func(t *testing.T) {
+		res, body := do("PROPFIND", "/dir/foo.txt", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propfind xmlns:D="DAV:"><D:propname/></D:propfind>`, "Depth", "0")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, `<color xmlns="urn:test"></color>`)
+	})
+
+	t.Run("COPY carries the dead properties", func(t *testing.T) {
+		res, _ := do("COPY", "/dir", "", "Destination", srv.URL+"/copy")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		require.NoError(t, tc.Runner.Run(ctx))
+
+		res, body := do("PROPFIND", "/copy/foo.txt", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propfind xmlns:D="DAV:" xmlns:Z="urn:test"><D:prop><Z:color/></D:prop></D:propfind>`, "Depth", "0")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, `<color xmlns="urn:test">red</color>`)
+	})
+}
+
+func TestModificationTime(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{"mkdir /dir", "write /dir/foo.txt some-content"})
+
+	_, srv := newTestHandler(t, tc)
+
+	do := newTestClient(t, tc, srv, nil)
+
+	modifiedAt := func(name string) time.Time {
+		info, err := tc.FSService.Get(ctx, dfs.NewPathCmd(tc.Space, name))
+		require.NoError(t, err)
+
+		return info.LastModifiedAt().UTC()
 	}
+
+	t.Run("PUT with a X-OC-Mtime header", func(t *testing.T) {
+		res, _ := do("PUT", "/dir/bar.txt", "some-content", "X-OC-Mtime", "1426154400")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		require.Equal(t, "accepted", res.Header.Get("X-OC-Mtime"))
+
+		require.Equal(t, time.Unix(1426154400, 0).UTC(), modifiedAt("/dir/bar.txt"))
+	})
+
+	t.Run("PUT with an invalid X-OC-Mtime header", func(t *testing.T) {
+		res, _ := do("PUT", "/dir/baz.txt", "some-content", "X-OC-Mtime", "not-a-time")
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("PROPPATCH getlastmodified", func(t *testing.T) {
+		res, body := do("PROPPATCH", "/dir/foo.txt", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propertyupdate xmlns:D="DAV:">
+				<D:set><D:prop><D:getlastmodified>Thu, 12 Mar 2015 10:00:00 GMT</D:getlastmodified></D:prop></D:set>
+			</D:propertyupdate>`)
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "HTTP/1.1 200 OK")
+		require.Equal(t, time.Date(2015, time.March, 12, 10, 0, 0, 0, time.UTC), modifiedAt("/dir/foo.txt"))
+
+		res, body = do("PROPFIND", "/dir/foo.txt", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propfind xmlns:D="DAV:"><D:prop><D:getlastmodified/></D:prop></D:propfind>`, "Depth", "0")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "Thu, 12 Mar 2015 10:00:00 GMT")
+	})
+
+	t.Run("PROPPATCH Win32LastModifiedTime", func(t *testing.T) {
+		res, body := do("PROPPATCH", "/dir/foo.txt", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propertyupdate xmlns:D="DAV:" xmlns:Z="urn:schemas-microsoft-com:">
+				<D:set><D:prop><Z:Win32LastModifiedTime>Fri, 13 Mar 2015 11:00:00 GMT</Z:Win32LastModifiedTime></D:prop></D:set>
+			</D:propertyupdate>`)
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "HTTP/1.1 200 OK")
+		require.Equal(t, time.Date(2015, time.March, 13, 11, 0, 0, 0, time.UTC), modifiedAt("/dir/foo.txt"))
+	})
+
+	t.Run("PROPPATCH getlastmodified with an invalid date", func(t *testing.T) {
+		res, body := do("PROPPATCH", "/dir/foo.txt", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propertyupdate xmlns:D="DAV:" xmlns:Z="urn:test">
+				<D:set><D:prop><D:getlastmodified>yesterday</D:getlastmodified><Z:color>red</Z:color></D:prop></D:set>
+			</D:propertyupdate>`)
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "HTTP/1.1 409 Conflict")
+		require.Contains(t, body, "HTTP/1.1 424 Failed Dependency")
+		require.Equal(t, time.Date(2015, time.March, 13, 11, 0, 0, 0, time.UTC), modifiedAt("/dir/foo.txt"))
+	})
+
+	t.Run("PROPPATCH remove getlastmodified is forbidden", func(t *testing.T) {
+		res, body := do("PROPPATCH", "/dir/foo.txt", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propertyupdate xmlns:D="DAV:">
+				<D:remove><D:prop><D:getlastmodified/></D:prop></D:remove>
+			</D:propertyupdate>`)
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "HTTP/1.1 403 Forbidden")
+	})
+}
+
+func TestQuotaProps(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{"mkdir /dir", "write /dir/foo.txt some-content"})
+
+	_, srv := newTestHandler(t, tc)
+
+	do := newTestClient(t, tc, srv, nil)
+
+	_, err := tc.SpacesSvc.SetQuota(ctx, &spaces.SetQuotaCmd{
+		User:    tc.User,
+		SpaceID: tc.Space.ID(),
+		Quota:   100,
+	})
+	require.NoError(t, err)
+
+	t.Run("PROPFIND the quota of a collection", func(t *testing.T) {
+		res, body := do("PROPFIND", "/dir", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propfind xmlns:D="DAV:"><D:prop><D:quota-available-bytes/><D:quota-used-bytes/></D:prop></D:propfind>`, "Depth", "0")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		// "some-content" is 12 bytes long.
+		require.Contains(t, body, "<D:quota-available-bytes>88</D:quota-available-bytes>")
+		require.Contains(t, body, "<D:quota-used-bytes>12</D:quota-used-bytes>")
+	})
+
+	t.Run("PROPFIND allprop doesn't return the quota", func(t *testing.T) {
+		res, body := do("PROPFIND", "/dir", `<?xml version="1.0" encoding="utf-8" ?>
+			<D:propfind xmlns:D="DAV:"><D:allprop/></D:propfind>`, "Depth", "0")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.NotContains(t, body, "quota")
+	})
+}
+
+func TestAllSpaces(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{"mkdir /dir", "write /dir/foo.txt some-content"})
+
+	_, srv := newTestHandler(t, tc)
+
+	newSpace := func(name string, role spaces.Role, dirs ...string) *spaces.Space {
+		space, err := tc.SpacesSvc.Create(ctx, &spaces.CreateCmd{
+			User:     tc.User,
+			Name:     name,
+			Managers: []uuid.UUID{tc.User.ID()},
+		})
+		require.NoError(t, err)
+
+		_, err = tc.FSService.CreateFS(ctx, tc.User, space)
+		require.NoError(t, err)
+
+		for _, dir := range dirs {
+			_, err = tc.FSService.CreateDir(ctx, &dfs.CreateDirCmd{
+				Path:      dfs.NewPathCmd(space, dir),
+				CreatedBy: tc.User,
+			})
+			require.NoError(t, err)
+		}
+
+		_, err = tc.SpacesSvc.SetMemberRole(ctx, &spaces.SetMemberRoleCmd{
+			User:     tc.User,
+			MemberID: tc.User.ID(),
+			SpaceID:  space.ID(),
+			Role:     role,
+		})
+		require.NoError(t, err)
+
+		return space
+	}
+
+	projects := newSpace("Projects", spaces.RoleEditor)
+	newSpace("Archives", spaces.RoleViewer, "/old")
+
+	do := newTestClient(t, tc, srv, &davsessions.CreateCmd{
+		Name:      "test session",
+		Username:  tc.User.Username(),
+		UserID:    tc.User.ID(),
+		AllSpaces: true,
+	})
+
+	home := "/" + url.PathEscape(tc.Space.Name())
+
+	t.Run("PROPFIND the virtual root", func(t *testing.T) {
+		res, body := do("PROPFIND", "/", "", "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "<D:href>/</D:href>")
+		require.Contains(t, body, "<D:href>"+home+"/</D:href>")
+		require.Contains(t, body, "<D:href>/Projects/</D:href>")
+		require.Contains(t, body, "<D:href>/Archives/</D:href>")
+		require.NotContains(t, body, "foo.txt")
+	})
+
+	t.Run("PROPFIND inside a space", func(t *testing.T) {
+		res, body := do("PROPFIND", home+"/dir", "", "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "<D:href>"+home+"/dir/foo.txt</D:href>")
+	})
+
+	t.Run("GET and PUT inside a space", func(t *testing.T) {
+		res, body := do(http.MethodGet, home+"/dir/foo.txt", "")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "some-content", body)
+
+		res, _ = do(http.MethodPut, "/Projects/bar.txt", "some-other-content")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		_, err := tc.FSService.Get(ctx, dfs.NewPathCmd(projects, "/bar.txt"))
+		require.NoError(t, err)
+	})
+
+	t.Run("an unknown space", func(t *testing.T) {
+		res, _ := do(http.MethodGet, "/unknown/foo.txt", "")
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+
+		res, _ = do("MKCOL", "/unknown", "")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("the virtual root can't be modified", func(t *testing.T) {
+		res, _ := do(http.MethodPut, "/", "some-content")
+		require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+
+		res, _ = do("MOVE", "/Projects/bar.txt", "", "Destination", srv.URL+"/")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("COPY to an other space", func(t *testing.T) {
+		res, _ := do("COPY", home+"/dir", "", "Destination", srv.URL+"/Projects/dir-copy")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		require.NoError(t, tc.Runner.Run(ctx))
+
+		res, body := do(http.MethodGet, "/Projects/dir-copy/foo.txt", "")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "some-content", body)
+	})
+
+	t.Run("MOVE to an other space", func(t *testing.T) {
+		res, _ := do("MOVE", "/Projects/bar.txt", "", "Destination", srv.URL+home+"/bar.txt")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		require.NoError(t, tc.Runner.Run(ctx))
+
+		_, err := tc.FSService.Get(ctx, dfs.NewPathCmd(tc.Space, "/bar.txt"))
+		require.NoError(t, err)
+		_, err = tc.FSService.Get(ctx, dfs.NewPathCmd(projects, "/bar.txt"))
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("a read only space", func(t *testing.T) {
+		res, _ := do(http.MethodPut, "/Archives/foo.txt", "some-content")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		res, _ = do("COPY", home+"/dir/foo.txt", "", "Destination", srv.URL+"/Archives/foo.txt")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		// The content of a read only space can be copied elsewhere.
+		res, _ = do("COPY", "/Archives/old", "", "Destination", srv.URL+home+"/old")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+	})
+}
+
+func TestScopedSession(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{"mkdir /Movies", "write /Movies/film.mkv some-content", "write /secret.txt some-secret"})
+
+	_, srv := newTestHandler(t, tc)
+
+	do := newTestClient(t, tc, srv, &davsessions.CreateCmd{
+		Name:     "media player",
+		Username: tc.User.Username(),
+		UserID:   tc.User.ID(),
+		SpaceID:  tc.Space.ID(),
+		ReadOnly: true,
+		RootPath: "/Movies",
+	})
+
+	t.Run("only the root path is exposed", func(t *testing.T) {
+		res, body := do(http.MethodGet, "/film.mkv", "")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "some-content", body)
+
+		res, body = do("PROPFIND", "/", "", "Depth", "1")
+		require.Equal(t, http.StatusMultiStatus, res.StatusCode)
+		require.Contains(t, body, "<D:href>/film.mkv</D:href>")
+
+		res, _ = do(http.MethodGet, "/secret.txt", "")
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+
+		res, _ = do(http.MethodGet, "/../secret.txt", "")
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("the write methods are refused", func(t *testing.T) {
+		res, _ := do(http.MethodPut, "/bar.txt", "some-content")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		res, _ = do(http.MethodDelete, "/film.mkv", "")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		res, _ = do("MKCOL", "/dir", "")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		res, _ = do("MOVE", "/film.mkv", "", "Destination", srv.URL+"/film2.mkv")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		res, _ = do("PROPPATCH", "/film.mkv", "")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		_, err := tc.FSService.Get(ctx, dfs.NewPathCmd(tc.Space, "/Movies/film.mkv"))
+		require.NoError(t, err)
+	})
+
+	t.Run("the last usage is tracked", func(t *testing.T) {
+		sessions, err := tc.DavSessionsSvc.GetAllForUser(ctx, tc.User.ID(), nil)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+
+		require.NotNil(t, sessions[0].LastUsedAt())
+		require.Equal(t, "127.0.0.1", *sessions[0].LastUsedIP())
+	})
+}
+
+func TestMultiStatus(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{
+		"mkdir /a",
+		"write /a/free.txt some-content",
+		"mkdir /a/sub",
+		"write /a/sub/locked.txt some-content",
+		"mkdir /a/other",
+		"write /a/other/free.txt some-content",
+		"mkdir /b",
+		"mkdir /b/c",
+	})
+
+	_, srv := newTestHandler(t, tc)
+
+	do := newTestClient(t, tc, srv, nil)
+
+	exists := func(p string) bool {
+		_, err := tc.FSService.Get(ctx, dfs.NewPathCmd(tc.Space, p))
+		if errors.Is(err, errs.ErrNotFound) {
+			return false
+		}
+		require.NoError(t, err)
+
+		return true
+	}
+
+	res, _ := do("LOCK", "/a/sub/locked.txt", `<?xml version="1.0" encoding="utf-8" ?>`+
+		`<D:lockinfo xmlns:D="DAV:">`+
+		`<D:lockscope><D:exclusive/></D:lockscope>`+
+		`<D:locktype><D:write/></D:locktype>`+
+		`</D:lockinfo>`, "Depth", "0")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	lockToken := res.Header.Get("Lock-Token")
+
+	t.Run("COPY and MOVE refuse the overlapping paths", func(t *testing.T) {
+		res, _ := do("COPY", "/b", "", "Destination", srv.URL+"/b/c/d")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		res, _ = do("MOVE", "/b", "", "Destination", srv.URL+"/b/c/d")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		res, _ = do("MOVE", "/b/c", "", "Destination", srv.URL+"/b")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		// Only the collection is copied with a depth 0.
+		res, _ = do("COPY", "/b", "", "Destination", srv.URL+"/b/c/d", "Depth", "0")
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		require.True(t, exists("/b/c/d"))
+	})
+
+	t.Run("MOVE with a locked member", func(t *testing.T) {
+		res, body := do("MOVE", "/a", "", "Destination", srv.URL+"/moved")
+		require.Equal(t, StatusMulti, res.StatusCode)
+		require.Contains(t, body, "<D:href>/a/sub/locked.txt</D:href>")
+		require.Contains(t, body, "<D:status>HTTP/1.1 423 Locked</D:status>")
+		require.NoError(t, tc.Runner.Run(ctx))
+
+		require.True(t, exists("/a/sub/locked.txt"))
+		require.False(t, exists("/moved"))
+	})
+
+	t.Run("COPY over a locked member", func(t *testing.T) {
+		res, body := do("COPY", "/b", "", "Destination", srv.URL+"/a")
+		require.Equal(t, StatusMulti, res.StatusCode)
+		require.Contains(t, body, "<D:href>/a/sub/locked.txt</D:href>")
+		require.NoError(t, tc.Runner.Run(ctx))
+
+		require.True(t, exists("/a/free.txt"))
+	})
+
+	t.Run("DELETE continues past a locked member", func(t *testing.T) {
+		res, body := do(http.MethodDelete, "/a", "")
+		require.Equal(t, StatusMulti, res.StatusCode)
+		require.Contains(t, body, "<D:href>/a/sub/locked.txt</D:href>")
+		require.Contains(t, body, "<D:status>HTTP/1.1 423 Locked</D:status>")
+		require.NotContains(t, body, "<D:href>/a</D:href>")
+		require.NoError(t, tc.Runner.Run(ctx))
+
+		// The locked member and its ancestors are kept.
+		require.True(t, exists("/a/sub/locked.txt"))
+		require.False(t, exists("/a/free.txt"))
+		require.False(t, exists("/a/other"))
+	})
+
+	t.Run("DELETE with the lock token submitted", func(t *testing.T) {
+		res, _ := do(http.MethodDelete, "/a", "", "If", "</a/sub/locked.txt> ("+lockToken+")")
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		require.False(t, exists("/a"))
+	})
+}
+
+func TestPropfindInfiniteDepth(t *testing.T) {
+	tc := buildTestFS(t, []string{
+		"mkdir /a",
+		"write /a/1.txt some-content",
+		"write /a/2.txt some-content",
+		"mkdir /a/3",
+		"write /a/3/4.txt some-content",
+		"write /a/3/5.txt some-content",
+		"write /a/6.txt some-content",
+	})
+
+	h, srv := newTestHandler(t, tc)
+	do := newTestClient(t, tc, srv, nil)
+
+	propfind := func(cfg PropfindConfig, depth string) (*http.Response, string) {
+		h.Propfind = cfg
+
+		return do("PROPFIND", "/a", `<?xml version="1.0" encoding="utf-8" ?>`+
+			`<D:propfind xmlns:D="DAV:"><D:prop><D:getcontenttype/></D:prop></D:propfind>`, "Depth", depth)
+	}
+
+	hrefs := func(body string) []string {
+		res := []string{}
+		for _, match := range regexp.MustCompile(`<D:href>([^<]*)</D:href>`).FindAllStringSubmatch(body, -1) {
+			res = append(res, match[1])
+		}
+		return res
+	}
+
+	t.Run("all the pages are walked", func(t *testing.T) {
+		res, body := propfind(PropfindConfig{BatchSize: 2}, "infinity")
+		require.Equal(t, StatusMulti, res.StatusCode)
+
+		require.Equal(t, []string{"/a/", "/a/1.txt", "/a/2.txt", "/a/3/", "/a/3/4.txt", "/a/3/5.txt", "/a/6.txt"}, hrefs(body))
+		require.Equal(t, 5, strings.Count(body, "<D:getcontenttype>text/plain; charset=utf-8</D:getcontenttype>"))
+	})
+
+	t.Run("the limit truncates the response", func(t *testing.T) {
+		res, body := propfind(PropfindConfig{BatchSize: 2, InfiniteDepthLimit: 3}, "infinity")
+		require.Equal(t, StatusMulti, res.StatusCode)
+
+		// The requested resource is listed once, last, with the error.
+		require.Equal(t, []string{"/a/1.txt", "/a/2.txt", "/a/"}, hrefs(body))
+		require.Equal(t, 1, strings.Count(body, "<D:number-of-matches-within-limits/>"))
+		require.Regexp(t, `<D:href>/a/</D:href>(?s:.*)<D:number-of-matches-within-limits/>`, body)
+	})
+
+	t.Run("the limit doesn't apply to a finite depth", func(t *testing.T) {
+		res, body := propfind(PropfindConfig{InfiniteDepthLimit: 1}, "1")
+		require.Equal(t, StatusMulti, res.StatusCode)
+
+		require.Equal(t, []string{"/a/", "/a/1.txt", "/a/2.txt", "/a/3/", "/a/6.txt"}, hrefs(body))
+	})
+
+	t.Run("the infinite depth can be refused", func(t *testing.T) {
+		res, body := propfind(PropfindConfig{RefuseInfiniteDepth: true}, "infinity")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.Contains(t, body, "<D:propfind-finite-depth/>")
+
+		res, _ = propfind(PropfindConfig{RefuseInfiniteDepth: true}, "1")
+		require.Equal(t, StatusMulti, res.StatusCode)
+	})
+}
+
+func TestPropfindDeadProps(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{
+		"mkdir /a",
+		"write /a/1.txt some-content",
+		"write /a/2.txt some-content",
+		"write /a/3.txt some-content",
+	})
+
+	for _, name := range []string{"/a", "/a/1.txt", "/a/3.txt"} {
+		err := tc.FSService.PatchProps(ctx, &dfs.PatchPropsCmd{
+			Path:      dfs.NewPathCmd(tc.Space, name),
+			Patches:   []dfs.PropPatch{{Namespace: "http://example.com/ns", Name: "color", Value: "red" + name[strings.LastIndex(name, "/")+1:]}},
+			PatchedBy: tc.User,
+		})
+		require.NoError(t, err)
+	}
+
+	h, srv := newTestHandler(t, tc)
+	h.Propfind = PropfindConfig{BatchSize: 2}
+	do := newTestClient(t, tc, srv, nil)
+
+	res, body := do("PROPFIND", "/a", `<?xml version="1.0" encoding="utf-8" ?>`+
+		`<D:propfind xmlns:D="DAV:"><D:prop><E:color xmlns:E="http://example.com/ns"/></D:prop></D:propfind>`, "Depth", "1")
+	require.Equal(t, StatusMulti, res.StatusCode)
+
+	// The props of the children are fetched once per page.
+	require.Contains(t, body, ">reda<")
+	require.Contains(t, body, ">red1.txt<")
+	require.Contains(t, body, ">red3.txt<")
+	require.Equal(t, 1, strings.Count(body, "<D:status>HTTP/1.1 404 Not Found</D:status>"))
+}
+
+func TestPropfindMetadataError(t *testing.T) {
+	ctx := context.Background()
+
+	tc := buildTestFS(t, []string{
+		"write /foo.txt some-content",
+	})
+
+	inode, err := tc.FSService.Get(ctx, dfs.NewPathCmd(tc.Space, "/foo.txt"))
+	require.NoError(t, err)
+
+	h, srv := newTestHandler(t, tc)
+	do := newTestClient(t, tc, srv, nil)
+
+	filesMock := files.NewMockService(t)
+	h.Files = filesMock
+
+	filesMock.On("GetMetadata", mock.Anything, *inode.FileID()).Return(nil, fmt.Errorf("some-error")).Once()
+
+	res, body := do("PROPFIND", "/foo.txt", `<?xml version="1.0" encoding="utf-8" ?>`+
+		`<D:propfind xmlns:D="DAV:"><D:prop><D:getcontenttype/></D:prop></D:propfind>`, "Depth", "0")
+	require.Equal(t, StatusMulti, res.StatusCode)
+	require.Contains(t, body, "<D:href>/foo.txt</D:href>")
+	require.Contains(t, body, "<D:status>HTTP/1.1 500 Internal Server Error</D:status>")
+	require.NotContains(t, body, "<D:propstat>")
+}
+
+func TestSearch(t *testing.T) {
+	tc := buildTestFS(t, []string{
+		"mkdir /a",
+		"write /a/1.txt some-content",
+		"write /a/2.md some-content",
+		"mkdir /a/3",
+		"write /a/3/4.txt some-longer-content",
+		"write /b.txt some-content",
+	})
+
+	h, srv := newTestHandler(t, tc)
+	h.Propfind = PropfindConfig{BatchSize: 2}
+	do := newTestClient(t, tc, srv, nil)
+
+	search := func(scope, where, limit string) (*http.Response, string) {
+		return do("SEARCH", "/", `<?xml version="1.0" encoding="utf-8" ?>`+
+			`<D:searchrequest xmlns:D="DAV:"><D:basicsearch>`+
+			`<D:select><D:prop><D:getcontentlength/></D:prop></D:select>`+
+			`<D:from>`+scope+`</D:from>`+
+			where+limit+
+			`</D:basicsearch></D:searchrequest>`)
+	}
+
+	hrefs := func(body string) []string {
+		res := []string{}
+		for _, match := range regexp.MustCompile(`<D:href>([^<]*)</D:href>`).FindAllStringSubmatch(body, -1) {
+			res = append(res, match[1])
+		}
+		return res
+	}
+
+	t.Run("OPTIONS advertises the basicsearch grammar", func(t *testing.T) {
+		res, _ := do("OPTIONS", "/a", "")
+		require.Equal(t, "<DAV:basicsearch>", res.Header.Get("DASL"))
+		require.Contains(t, res.Header.Get("Allow"), "SEARCH")
+	})
+
+	t.Run("without where clause all the pages are returned", func(t *testing.T) {
+		res, body := search(`<D:scope><D:href>/a</D:href><D:depth>infinity</D:depth></D:scope>`, "", "")
+		require.Equal(t, StatusMulti, res.StatusCode)
+
+		require.Equal(t, []string{"/a/1.txt", "/a/2.md", "/a/3/", "/a/3/4.txt"}, hrefs(body))
+		require.Contains(t, body, "<D:getcontentlength>19</D:getcontentlength>")
+	})
+
+	t.Run("with a depth 1", func(t *testing.T) {
+		res, body := search(`<D:scope><D:href>/a/</D:href><D:depth>1</D:depth></D:scope>`, "", "")
+		require.Equal(t, StatusMulti, res.StatusCode)
+
+		require.Equal(t, []string{"/a/1.txt", "/a/2.md", "/a/3/"}, hrefs(body))
+	})
+
+	t.Run("with a like on the name", func(t *testing.T) {
+		res, body := search(`<D:scope><D:href>/</D:href></D:scope>`,
+			`<D:where><D:like><D:prop><D:displayname/></D:prop><D:literal>%.txt</D:literal></D:like></D:where>`, "")
+		require.Equal(t, StatusMulti, res.StatusCode)
+
+		require.Equal(t, []string{"/a/1.txt", "/a/3/4.txt", "/b.txt"}, hrefs(body))
+	})
+
+	t.Run("with combined operators", func(t *testing.T) {
+		res, body := search(`<D:scope><D:href>/a</D:href></D:scope>`,
+			`<D:where><D:and>`+
+				`<D:gt><D:prop><D:getcontentlength/></D:prop><D:literal>12</D:literal></D:gt>`+
+				`<D:not><D:eq><D:prop><D:getcontenttype/></D:prop><D:literal>text/markdown</D:literal></D:eq></D:not>`+
+				`</D:and></D:where>`, "")
+		require.Equal(t, StatusMulti, res.StatusCode)
+
+		require.Equal(t, []string{"/a/3/4.txt"}, hrefs(body))
+	})
+
+	t.Run("the limit truncates the response", func(t *testing.T) {
+		res, body := search(`<D:scope><D:href>/a</D:href></D:scope>`, "", `<D:limit><D:nresults>3</D:nresults></D:limit>`)
+		require.Equal(t, StatusMulti, res.StatusCode)
+
+		require.Equal(t, []string{"/a/1.txt", "/a/2.md", "/a/3/", "/"}, hrefs(body))
+		require.Contains(t, body, "<D:status>HTTP/1.1 507 Insufficient Storage</D:status>")
+		require.Contains(t, body, "<D:number-of-matches-within-limits/>")
+	})
+
+	t.Run("with an unsupported property", func(t *testing.T) {
+		res, _ := search(`<D:scope><D:href>/a</D:href></D:scope>`,
+			`<D:where><D:eq><D:prop><D:getetag/></D:prop><D:literal>foo</D:literal></D:eq></D:where>`, "")
+		require.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	})
+
+	t.Run("with an invalid size", func(t *testing.T) {
+		res, _ := search(`<D:scope><D:href>/a</D:href></D:scope>`,
+			`<D:where><D:lt><D:prop><D:getcontentlength/></D:prop><D:literal>foo</D:literal></D:lt></D:where>`, "")
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("with a file as scope", func(t *testing.T) {
+		res, body := search(`<D:scope><D:href>/b.txt</D:href></D:scope>`, "", "")
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.Contains(t, body, "<D:search-scope-valid/>")
+	})
+
+	t.Run("with an unknown scope", func(t *testing.T) {
+		res, body := search(`<D:scope><D:href>/unknown</D:href></D:scope>`, "", "")
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.Contains(t, body, "<D:search-scope-valid/>")
+	})
+
+	t.Run("with several scopes", func(t *testing.T) {
+		res, body := search(`<D:scope><D:href>/a</D:href></D:scope><D:scope><D:href>/</D:href></D:scope>`, "", "")
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.Contains(t, body, "<D:search-multiple-scope-supported/>")
+	})
 }
@@ -14,6 +14,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 
 	// As of https://go-review.googlesource.com/#/c/12772/ which was submitted
 	// in July 2015, this package uses an internal fork of the standard
@@ -35,6 +38,48 @@ import (
 	ixml "github.com/theduckcompany/duckcloud/internal/service/dav/webdav/internal/xml"
 )
 
+// http://www.webdav.org/specs/rfc4918.html#ELEMENT_lockinfo
+type lockInfo struct {
+	XMLName   ixml.Name `xml:"lockinfo"`
+	Exclusive *struct{} `xml:"lockscope>exclusive"`
+	Shared    *struct{} `xml:"lockscope>shared"`
+	Write     *struct{} `xml:"locktype>write"`
+	Owner     owner     `xml:"owner"`
+}
+
+// http://www.webdav.org/specs/rfc4918.html#ELEMENT_owner
+type owner struct {
+	InnerXML string `xml:",innerxml"`
+}
+
+func readLockInfo(r io.Reader) (li lockInfo, status int, err error) {
+	c := &countingReader{r: r}
+	if err = ixml.NewDecoder(c).Decode(&li); err != nil {
+		if errors.Is(err, io.EOF) {
+			if c.n == 0 {
+				// An empty body means to refresh the lock.
+				// http://www.webdav.org/specs/rfc4918.html#refreshing-locks
+				return lockInfo{}, 0, nil
+			}
+			err = errInvalidLockInfo
+		}
+		return lockInfo{}, http.StatusBadRequest, err
+	}
+	// Write locks are the only lock type defined by RFC 4918 and a lock is
+	// either exclusive or shared.
+	if li.Write == nil || (li.Exclusive == nil) == (li.Shared == nil) {
+		return lockInfo{}, http.StatusBadRequest, errInvalidLockInfo
+	}
+	return li, 0, nil
+}
+
+func (li lockInfo) scope() davlocks.Scope {
+	if li.Shared != nil {
+		return davlocks.ScopeShared
+	}
+	return davlocks.ScopeExclusive
+}
+
 type countingReader struct {
 	n int
 	r io.Reader
@@ -46,6 +91,22 @@ func (c *countingReader) Read(p []byte) (int, error) {
 	return n, err
 }
 
+func writeLockInfo(w io.Writer, lock *davlocks.Lock, root string) (int, error) {
+	return fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"utf-8\"?>\n"+
+		"<D:prop xmlns:D=\"DAV:\"><D:lockdiscovery><D:activelock>\n"+
+		"	<D:locktype><D:write/></D:locktype>\n"+
+		"	<D:lockscope><D:%s/></D:lockscope>\n"+
+		"	<D:depth>%s</D:depth>\n"+
+		"	<D:owner>%s</D:owner>\n"+
+		"	<D:timeout>Second-%d</D:timeout>\n"+
+		"	<D:locktoken><D:href>%s</D:href></D:locktoken>\n"+
+		"	<D:lockroot><D:href>%s</D:href></D:lockroot>\n"+
+		"</D:activelock></D:lockdiscovery></D:prop>",
+		lock.Scope(), lock.Depth(), lock.Owner(), int64(lock.Timeout().Seconds()),
+		escapeXML(lock.Token()), escapeXML((&url.URL{Path: root}).EscapedPath()),
+	)
+}
+
 // next returns the next token, if any, in the XML stream of d.
 // RFC 4918 requires to ignore comments, processing instructions
 // and directives.
@@ -278,6 +339,22 @@ func (w *multistatusWriter) write(r *response) error {
 	return w.enc.Encode(r)
 }
 
+// flush sends the responses already written to the client if the
+// underlying http.ResponseWriter supports it.
+func (w *multistatusWriter) flush() error {
+	if w.enc == nil {
+		return nil
+	}
+	err := w.enc.Flush()
+	if err != nil {
+		return err
+	}
+	if flusher, ok := w.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
 // writeHeader writes a XML multistatus start element on w's underlying
 // http.ResponseWriter and returns the result of the write operation.
 // After the first write attempt, writeHeader becomes a no-op.
@@ -0,0 +1,28 @@
+package davlocks
+
+import (
+	"context"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+//go:generate mockery --name Service
+type Service interface {
+	Create(ctx context.Context, cmd *CreateCmd) (*Lock, error)
+	Refresh(ctx context.Context, cmd *RefreshCmd) (*Lock, error)
+	Unlock(ctx context.Context, cmd *UnlockCmd) error
+	GetByToken(ctx context.Context, token string) (*Lock, error)
+	Confirm(ctx context.Context, cmd *ConfirmCmd) error
+	GetConflicts(ctx context.Context, cmd *ConfirmCmd) ([]Lock, error)
+	RemoveAll(ctx context.Context, cmd *dfs.PathCmd) error
+	DeleteAll(ctx context.Context, userID uuid.UUID) error
+}
+
+func Init(tools tools.Tools, db sqlstorage.Querier) Service {
+	storage := newSqlStorage(db)
+
+	return newService(tools, storage)
+}
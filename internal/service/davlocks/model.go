@@ -0,0 +1,162 @@
+package davlocks
+
+import (
+	"strings"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const (
+	// DefaultTimeout is used when the client doesn't ask for a specific timeout.
+	DefaultTimeout = time.Hour
+	// MaxTimeout is the longest timeout given to a lock. It is also used for
+	// the clients asking for an "Infinite" timeout.
+	MaxTimeout = 24 * time.Hour
+)
+
+type Scope string
+
+const (
+	ScopeExclusive Scope = "exclusive"
+	ScopeShared    Scope = "shared"
+)
+
+type Depth string
+
+const (
+	DepthZero     Depth = "0"
+	DepthInfinity Depth = "infinity"
+)
+
+// Lock is a WebDAV write lock as described by RFC 4918 section 6.
+type Lock struct {
+	createdAt time.Time
+	expiresAt time.Time
+	token     string
+	spaceID   uuid.UUID
+	path      string
+	scope     Scope
+	depth     Depth
+	// owner is the raw XML given by the client inside the lockinfo.
+	owner     string
+	timeout   time.Duration
+	createdBy uuid.UUID
+}
+
+func (l Lock) Token() string                { return l.token }
+func (l Lock) SpaceID() uuid.UUID           { return l.spaceID }
+func (l Lock) Path() string                 { return l.path }
+func (l Lock) Scope() Scope                 { return l.scope }
+func (l Lock) Depth() Depth                 { return l.depth }
+func (l Lock) Owner() string                { return l.owner }
+func (l Lock) Timeout() time.Duration       { return l.timeout }
+func (l Lock) ExpiresAt() time.Time         { return l.expiresAt }
+func (l Lock) CreatedAt() time.Time         { return l.createdAt }
+func (l Lock) CreatedBy() uuid.UUID         { return l.createdBy }
+func (l Lock) IsExclusive() bool            { return l.scope == ScopeExclusive }
+func (l Lock) IsExpired(now time.Time) bool { return !now.Before(l.expiresAt) }
+
+// Covers returns true if the given path is protected by the lock: it is the
+// lock root or one of its descendants for a depth infinity lock.
+func (l Lock) Covers(cmd *dfs.PathCmd) bool {
+	if cmd.Space().ID() != l.spaceID {
+		return false
+	}
+
+	if cmd.Path() == l.path {
+		return true
+	}
+
+	return l.depth == DepthInfinity && isDescendant(cmd.Path(), l.path)
+}
+
+// overlaps returns true if the lock and a new lock on cmd with the given depth
+// protect some common resources.
+func (l Lock) overlaps(cmd *dfs.PathCmd, depth Depth) bool {
+	if l.Covers(cmd) {
+		return true
+	}
+
+	return cmd.Space().ID() == l.spaceID && depth == DepthInfinity && isDescendant(l.path, cmd.Path())
+}
+
+// isDescendant returns true if p is strictly inside the dir directory.
+func isDescendant(p, dir string) bool {
+	if dir == "/" {
+		return p != "/"
+	}
+
+	return strings.HasPrefix(p, dir+"/")
+}
+
+type CreateCmd struct {
+	User    *users.User
+	Path    *dfs.PathCmd
+	Scope   Scope
+	Depth   Depth
+	Owner   string
+	Timeout time.Duration
+}
+
+// Validate the fields.
+func (t CreateCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.Path, v.Required),
+		v.Field(&t.Scope, v.Required, v.In(ScopeExclusive, ScopeShared)),
+		v.Field(&t.Depth, v.Required, v.In(DepthZero, DepthInfinity)),
+		v.Field(&t.Timeout, v.Min(time.Duration(0))),
+	)
+}
+
+type RefreshCmd struct {
+	User    *users.User
+	Path    *dfs.PathCmd
+	Token   string
+	Timeout time.Duration
+}
+
+// Validate the fields.
+func (t RefreshCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.Path, v.Required),
+		v.Field(&t.Token, v.Required),
+		v.Field(&t.Timeout, v.Min(time.Duration(0))),
+	)
+}
+
+type UnlockCmd struct {
+	User  *users.User
+	Path  *dfs.PathCmd
+	Token string
+}
+
+// Validate the fields.
+func (t UnlockCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.Path, v.Required),
+		v.Field(&t.Token, v.Required),
+	)
+}
+
+// ConfirmCmd asks if the user can modify the resource at Path. Tokens are the
+// lock tokens submitted by the client.
+type ConfirmCmd struct {
+	User   *users.User
+	Path   *dfs.PathCmd
+	Tokens []string
+}
+
+// Validate the fields.
+func (t ConfirmCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.Path, v.Required),
+	)
+}
@@ -0,0 +1,78 @@
+package davlocks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+type FakeLockBuilder struct {
+	t    *testing.T
+	lock *Lock
+}
+
+// NewFakeLock builds an exclusive lock with a depth infinity on the given
+// path, expiring in an hour.
+func NewFakeLock(t *testing.T, cmd *dfs.PathCmd, user *users.User) *FakeLockBuilder {
+	t.Helper()
+
+	uuidProvider := uuid.NewProvider()
+
+	createdAt := gofakeit.DateRange(time.Now().Add(-time.Hour*1000), time.Now()).UTC()
+
+	return &FakeLockBuilder{
+		t: t,
+		lock: &Lock{
+			token:     "urn:uuid:" + string(uuidProvider.New()),
+			spaceID:   cmd.Space().ID(),
+			path:      cmd.Path(),
+			scope:     ScopeExclusive,
+			depth:     DepthInfinity,
+			owner:     "<D:href>" + gofakeit.URL() + "</D:href>",
+			timeout:   DefaultTimeout,
+			expiresAt: time.Now().Add(DefaultTimeout).UTC(),
+			createdAt: createdAt,
+			createdBy: user.ID(),
+		},
+	}
+}
+
+func (f *FakeLockBuilder) WithScope(scope Scope) *FakeLockBuilder {
+	f.lock.scope = scope
+
+	return f
+}
+
+func (f *FakeLockBuilder) WithDepth(depth Depth) *FakeLockBuilder {
+	f.lock.depth = depth
+
+	return f
+}
+
+func (f *FakeLockBuilder) ExpiresAt(expiresAt time.Time) *FakeLockBuilder {
+	f.lock.expiresAt = expiresAt
+
+	return f
+}
+
+func (f *FakeLockBuilder) Build() *Lock {
+	return f.lock
+}
+
+func (f *FakeLockBuilder) BuildAndStore(ctx context.Context, db sqlstorage.Querier) *Lock {
+	f.t.Helper()
+
+	storage := newSqlStorage(db)
+
+	err := storage.Save(ctx, f.lock)
+	require.NoError(f.t, err)
+
+	return f.lock
+}
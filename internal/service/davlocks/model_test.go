@@ -0,0 +1,134 @@
+package davlocks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+)
+
+func Test_Lock_Getters(t *testing.T) {
+	space := spaces.NewFakeSpace(t).Build()
+	user := users.NewFakeUser(t).Build()
+	lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).Build()
+
+	assert.Equal(t, lock.token, lock.Token())
+	assert.Equal(t, lock.spaceID, lock.SpaceID())
+	assert.Equal(t, lock.path, lock.Path())
+	assert.Equal(t, lock.scope, lock.Scope())
+	assert.Equal(t, lock.depth, lock.Depth())
+	assert.Equal(t, lock.owner, lock.Owner())
+	assert.Equal(t, lock.timeout, lock.Timeout())
+	assert.Equal(t, lock.expiresAt, lock.ExpiresAt())
+	assert.Equal(t, lock.createdAt, lock.CreatedAt())
+	assert.Equal(t, lock.createdBy, lock.CreatedBy())
+	assert.True(t, lock.IsExclusive())
+}
+
+func Test_Lock_IsExpired(t *testing.T) {
+	space := spaces.NewFakeSpace(t).Build()
+	user := users.NewFakeUser(t).Build()
+	now := time.Now()
+	lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).ExpiresAt(now).Build()
+
+	assert.False(t, lock.IsExpired(now.Add(-time.Second)))
+	assert.True(t, lock.IsExpired(now))
+}
+
+func Test_Lock_Covers(t *testing.T) {
+	space := spaces.NewFakeSpace(t).Build()
+	otherSpace := spaces.NewFakeSpace(t).Build()
+	user := users.NewFakeUser(t).Build()
+
+	infiniteLock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).Build()
+	zeroLock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).WithDepth(DepthZero).Build()
+	rootLock := NewFakeLock(t, dfs.NewPathCmd(space, "/"), user).Build()
+
+	assert.True(t, infiniteLock.Covers(dfs.NewPathCmd(space, "/foo")))
+	assert.True(t, infiniteLock.Covers(dfs.NewPathCmd(space, "/foo/bar")))
+	assert.False(t, infiniteLock.Covers(dfs.NewPathCmd(space, "/foobar")))
+	assert.False(t, infiniteLock.Covers(dfs.NewPathCmd(space, "/")))
+	assert.False(t, infiniteLock.Covers(dfs.NewPathCmd(otherSpace, "/foo")))
+
+	assert.True(t, zeroLock.Covers(dfs.NewPathCmd(space, "/foo")))
+	assert.False(t, zeroLock.Covers(dfs.NewPathCmd(space, "/foo/bar")))
+
+	assert.True(t, rootLock.Covers(dfs.NewPathCmd(space, "/")))
+	assert.True(t, rootLock.Covers(dfs.NewPathCmd(space, "/foo/bar")))
+}
+
+func Test_Lock_overlaps(t *testing.T) {
+	space := spaces.NewFakeSpace(t).Build()
+	user := users.NewFakeUser(t).Build()
+
+	lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo/bar"), user).WithDepth(DepthZero).Build()
+
+	assert.True(t, lock.overlaps(dfs.NewPathCmd(space, "/foo/bar"), DepthZero))
+	assert.True(t, lock.overlaps(dfs.NewPathCmd(space, "/foo"), DepthInfinity))
+	assert.True(t, lock.overlaps(dfs.NewPathCmd(space, "/"), DepthInfinity))
+	assert.False(t, lock.overlaps(dfs.NewPathCmd(space, "/foo"), DepthZero))
+	assert.False(t, lock.overlaps(dfs.NewPathCmd(space, "/foo/bar/baz"), DepthInfinity))
+}
+
+func Test_CreateCmd_Validate(t *testing.T) {
+	space := spaces.NewFakeSpace(t).Build()
+
+	require.NoError(t, CreateCmd{
+		User:  &users.ExampleAlice,
+		Path:  dfs.NewPathCmd(space, "/foo"),
+		Scope: ScopeExclusive,
+		Depth: DepthInfinity,
+	}.Validate())
+
+	require.EqualError(t, CreateCmd{
+		User:  &users.ExampleAlice,
+		Path:  dfs.NewPathCmd(space, "/foo"),
+		Scope: Scope("invalid"),
+		Depth: DepthInfinity,
+	}.Validate(), "Scope: must be a valid value.")
+
+	require.EqualError(t, CreateCmd{
+		User:  &users.ExampleAlice,
+		Path:  dfs.NewPathCmd(space, "/foo"),
+		Scope: ScopeShared,
+		Depth: Depth("1"),
+	}.Validate(), "Depth: must be a valid value.")
+}
+
+func Test_RefreshCmd_Validate(t *testing.T) {
+	space := spaces.NewFakeSpace(t).Build()
+
+	require.NoError(t, RefreshCmd{
+		User:  &users.ExampleAlice,
+		Path:  dfs.NewPathCmd(space, "/foo"),
+		Token: "urn:uuid:some-token",
+	}.Validate())
+
+	require.EqualError(t, RefreshCmd{
+		User: &users.ExampleAlice,
+		Path: dfs.NewPathCmd(space, "/foo"),
+	}.Validate(), "Token: cannot be blank.")
+}
+
+func Test_UnlockCmd_Validate(t *testing.T) {
+	space := spaces.NewFakeSpace(t).Build()
+
+	require.NoError(t, UnlockCmd{
+		User:  &users.ExampleAlice,
+		Path:  dfs.NewPathCmd(space, "/foo"),
+		Token: "urn:uuid:some-token",
+	}.Validate())
+}
+
+func Test_ConfirmCmd_Validate(t *testing.T) {
+	space := spaces.NewFakeSpace(t).Build()
+
+	require.NoError(t, ConfirmCmd{
+		User: &users.ExampleAlice,
+		Path: dfs.NewPathCmd(space, "/foo"),
+	}.Validate())
+}
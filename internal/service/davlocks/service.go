@@ -0,0 +1,291 @@
+package davlocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/clock"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+var (
+	ErrLocked       = errors.New("resource locked")
+	ErrLockNotFound = errors.New("lock not found")
+	ErrNotLockOwner = errors.New("not the lock owner")
+)
+
+//go:generate mockery --name storage
+type storage interface {
+	Save(ctx context.Context, lock *Lock) error
+	GetByToken(ctx context.Context, token string) (*Lock, error)
+	GetAllActiveInSpace(ctx context.Context, spaceID uuid.UUID, now time.Time) ([]Lock, error)
+	Patch(ctx context.Context, token string, fields map[string]any) error
+	Delete(ctx context.Context, token string) error
+	DeleteAllForUser(ctx context.Context, userID uuid.UUID) error
+	DeleteAllExpired(ctx context.Context, now time.Time) error
+}
+
+type service struct {
+	storage storage
+	clock   clock.Clock
+	uuid    uuid.Service
+
+	// createLock ensures that the conflict check and the save of a new lock
+	// are not interleaved with an another creation.
+	//
+	// XXX:MULTI-WRITE
+	createLock *sync.Mutex
+}
+
+func newService(tools tools.Tools, storage storage) *service {
+	return &service{storage, tools.Clock(), tools.UUID(), new(sync.Mutex)}
+}
+
+// Create a new lock. An [ErrLocked] error is returned if an another lock
+// conflicts with it.
+func (s *service) Create(ctx context.Context, cmd *CreateCmd) (*Lock, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	s.createLock.Lock()
+	defer s.createLock.Unlock()
+
+	now := s.clock.Now()
+
+	err = s.storage.DeleteAllExpired(ctx, now)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to DeleteAllExpired: %w", err))
+	}
+
+	locks, err := s.storage.GetAllActiveInSpace(ctx, cmd.Path.Space().ID(), now)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllActiveInSpace: %w", err))
+	}
+
+	for _, l := range locks {
+		// Only the shared locks are compatible between them.
+		if l.overlaps(cmd.Path, cmd.Depth) && (l.IsExclusive() || cmd.Scope == ScopeExclusive) {
+			return nil, errs.BadRequest(ErrLocked)
+		}
+	}
+
+	timeout := clampTimeout(cmd.Timeout)
+	lock := Lock{
+		token:     "urn:uuid:" + string(s.uuid.New()),
+		spaceID:   cmd.Path.Space().ID(),
+		path:      cmd.Path.Path(),
+		scope:     cmd.Scope,
+		depth:     cmd.Depth,
+		owner:     cmd.Owner,
+		timeout:   timeout,
+		expiresAt: now.Add(timeout),
+		createdAt: now,
+		createdBy: cmd.User.ID(),
+	}
+
+	err = s.storage.Save(ctx, &lock)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to Save: %w", err))
+	}
+
+	return &lock, nil
+}
+
+// Refresh resets the timeout of an existing lock.
+func (s *service) Refresh(ctx context.Context, cmd *RefreshCmd) (*Lock, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	lock, err := s.getUserLock(ctx, cmd.User, cmd.Path, cmd.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	lock.timeout = clampTimeout(cmd.Timeout)
+	lock.expiresAt = now.Add(lock.timeout)
+
+	err = s.storage.Patch(ctx, lock.token, map[string]any{
+		"timeout":    int64(lock.timeout.Seconds()),
+		"expires_at": sqlstorage.SQLTime(lock.expiresAt),
+	})
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to Patch: %w", err))
+	}
+
+	return lock, nil
+}
+
+// Unlock removes the lock identified by the token. The path must be covered
+// by the lock.
+func (s *service) Unlock(ctx context.Context, cmd *UnlockCmd) error {
+	err := cmd.Validate()
+	if err != nil {
+		return errs.Validation(err)
+	}
+
+	lock, err := s.getUserLock(ctx, cmd.User, cmd.Path, cmd.Token)
+	if err != nil {
+		return err
+	}
+
+	err = s.storage.Delete(ctx, lock.token)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to Delete: %w", err))
+	}
+
+	return nil
+}
+
+// GetByToken returns the active lock identified by the given token.
+func (s *service) GetByToken(ctx context.Context, token string) (*Lock, error) {
+	lock, err := s.storage.GetByToken(ctx, token)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(ErrLockNotFound)
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetByToken: %w", err))
+	}
+
+	if lock.IsExpired(s.clock.Now()) {
+		return nil, errs.NotFound(ErrLockNotFound)
+	}
+
+	return lock, nil
+}
+
+// Confirm checks that the user can modify the resource at the given path and
+// all its descendants. Every exclusive lock on those resources must have its
+// token submitted and at least one of the shared locks must be submitted. An
+// [ErrLocked] error is returned otherwise.
+func (s *service) Confirm(ctx context.Context, cmd *ConfirmCmd) error {
+	conflicts, err := s.GetConflicts(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	if len(conflicts) > 0 {
+		return errs.BadRequest(ErrLocked)
+	}
+
+	return nil
+}
+
+// GetConflicts returns the locks preventing the user to modify the resource at
+// the given path or one of its descendants, following the rules of [Confirm].
+// The result is empty if the modification is allowed.
+//
+// It lets the caller continue with the resources which are not locked and
+// report the others.
+func (s *service) GetConflicts(ctx context.Context, cmd *ConfirmCmd) ([]Lock, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	locks, err := s.storage.GetAllActiveInSpace(ctx, cmd.Path.Space().ID(), s.clock.Now())
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllActiveInSpace: %w", err))
+	}
+
+	res := []Lock{}
+	shared := []Lock{}
+	sharedSubmitted := false
+	for _, l := range locks {
+		if !l.overlaps(cmd.Path, DepthInfinity) {
+			continue
+		}
+
+		// A lock token is only usable by the user who created the lock.
+		submitted := slices.Contains(cmd.Tokens, l.token) && l.createdBy == cmd.User.ID()
+
+		if l.IsExclusive() && !submitted {
+			res = append(res, l)
+		}
+
+		if !l.IsExclusive() {
+			shared = append(shared, l)
+			sharedSubmitted = sharedSubmitted || submitted
+		}
+	}
+
+	if !sharedSubmitted {
+		res = append(res, shared...)
+	}
+
+	return res, nil
+}
+
+// RemoveAll removes all the locks on the resource at the given path and its
+// descendants. It is used once the resources have been removed.
+func (s *service) RemoveAll(ctx context.Context, cmd *dfs.PathCmd) error {
+	locks, err := s.storage.GetAllActiveInSpace(ctx, cmd.Space().ID(), s.clock.Now())
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to GetAllActiveInSpace: %w", err))
+	}
+
+	for _, l := range locks {
+		if l.path != cmd.Path() && !isDescendant(l.path, cmd.Path()) {
+			continue
+		}
+
+		err = s.storage.Delete(ctx, l.token)
+		if err != nil {
+			return errs.Internal(fmt.Errorf("failed to Delete the lock %q: %w", l.token, err))
+		}
+	}
+
+	return nil
+}
+
+// DeleteAll removes all the locks created by the given user.
+func (s *service) DeleteAll(ctx context.Context, userID uuid.UUID) error {
+	err := s.storage.DeleteAllForUser(ctx, userID)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to DeleteAllForUser: %w", err))
+	}
+
+	return nil
+}
+
+func (s *service) getUserLock(ctx context.Context, user *users.User, cmd *dfs.PathCmd, token string) (*Lock, error) {
+	lock, err := s.GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !lock.Covers(cmd) {
+		return nil, errs.NotFound(ErrLockNotFound)
+	}
+
+	if lock.createdBy != user.ID() {
+		return nil, errs.Unauthorized(ErrNotLockOwner)
+	}
+
+	return lock, nil
+}
+
+func clampTimeout(timeout time.Duration) time.Duration {
+	switch {
+	case timeout == 0:
+		return DefaultTimeout
+	case timeout > MaxTimeout:
+		return MaxTimeout
+	default:
+		return timeout
+	}
+}
@@ -0,0 +1,669 @@
+package davlocks
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func Test_DavLocksService(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("Create success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		otherLock := NewFakeLock(t, dfs.NewPathCmd(space, "/bar"), user).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("DeleteAllExpired", mock.Anything, now).Return(nil).Once()
+		storageMock.On("GetAllActiveInSpace", mock.Anything, space.ID(), now).Return([]Lock{*otherLock}, nil).Once()
+		tools.UUIDMock.On("New").Return(uuid.UUID("some-token-id")).Once()
+		storageMock.On("Save", mock.Anything, &Lock{
+			token:     "urn:uuid:some-token-id",
+			spaceID:   space.ID(),
+			path:      "/foo",
+			scope:     ScopeExclusive,
+			depth:     DepthInfinity,
+			owner:     "<D:href>some-owner</D:href>",
+			timeout:   DefaultTimeout,
+			expiresAt: now.Add(DefaultTimeout),
+			createdAt: now,
+			createdBy: user.ID(),
+		}).Return(nil).Once()
+
+		// Run
+		res, err := svc.Create(ctx, &CreateCmd{
+			User:  user,
+			Path:  dfs.NewPathCmd(space, "/foo"),
+			Scope: ScopeExclusive,
+			Depth: DepthInfinity,
+			Owner: "<D:href>some-owner</D:href>",
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, "urn:uuid:some-token-id", res.Token())
+		assert.Equal(t, DefaultTimeout, res.Timeout())
+	})
+
+	t.Run("Create with a timeout above the max", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("DeleteAllExpired", mock.Anything, now).Return(nil).Once()
+		storageMock.On("GetAllActiveInSpace", mock.Anything, space.ID(), now).Return([]Lock{}, nil).Once()
+		tools.UUIDMock.On("New").Return(uuid.UUID("some-token-id")).Once()
+		storageMock.On("Save", mock.Anything, mock.Anything).Return(nil).Once()
+
+		// Run
+		res, err := svc.Create(ctx, &CreateCmd{
+			User:    user,
+			Path:    dfs.NewPathCmd(space, "/foo"),
+			Scope:   ScopeShared,
+			Depth:   DepthZero,
+			Timeout: 1000 * time.Hour,
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, MaxTimeout, res.Timeout())
+		assert.Equal(t, now.Add(MaxTimeout), res.ExpiresAt())
+	})
+
+	t.Run("Create with a validation error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+
+		// Run
+		res, err := svc.Create(ctx, &CreateCmd{
+			User:  user,
+			Path:  dfs.NewPathCmd(space, "/foo"),
+			Scope: Scope("invalid"),
+			Depth: DepthZero,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
+	t.Run("Create with an exclusive lock on a parent", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		parentLock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("DeleteAllExpired", mock.Anything, now).Return(nil).Once()
+		storageMock.On("GetAllActiveInSpace", mock.Anything, space.ID(), now).Return([]Lock{*parentLock}, nil).Once()
+
+		// Run
+		res, err := svc.Create(ctx, &CreateCmd{
+			User:  user,
+			Path:  dfs.NewPathCmd(space, "/foo/bar.txt"),
+			Scope: ScopeShared,
+			Depth: DepthZero,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, ErrLocked)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+	})
+
+	t.Run("Create a shared lock over an another shared lock", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		sharedLock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).WithScope(ScopeShared).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("DeleteAllExpired", mock.Anything, now).Return(nil).Once()
+		storageMock.On("GetAllActiveInSpace", mock.Anything, space.ID(), now).Return([]Lock{*sharedLock}, nil).Once()
+		tools.UUIDMock.On("New").Return(uuid.UUID("some-token-id")).Once()
+		storageMock.On("Save", mock.Anything, mock.Anything).Return(nil).Once()
+
+		// Run
+		res, err := svc.Create(ctx, &CreateCmd{
+			User:  user,
+			Path:  dfs.NewPathCmd(space, "/foo"),
+			Scope: ScopeShared,
+			Depth: DepthInfinity,
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, ScopeShared, res.Scope())
+	})
+
+	t.Run("Create with a DeleteAllExpired error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("DeleteAllExpired", mock.Anything, now).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := svc.Create(ctx, &CreateCmd{
+			User:  user,
+			Path:  dfs.NewPathCmd(space, "/foo"),
+			Scope: ScopeExclusive,
+			Depth: DepthZero,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("Refresh success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).Build()
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, lock.Token()).Return(lock, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Twice()
+		storageMock.On("Patch", mock.Anything, lock.Token(), mock.Anything).Return(nil).Once()
+
+		// Run
+		res, err := svc.Refresh(ctx, &RefreshCmd{
+			User:    user,
+			Path:    dfs.NewPathCmd(space, "/foo/bar"),
+			Token:   lock.Token(),
+			Timeout: 2 * time.Hour,
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Hour, res.Timeout())
+		assert.Equal(t, now.Add(2*time.Hour), res.ExpiresAt())
+	})
+
+	t.Run("Refresh with an expired lock", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).ExpiresAt(now.Add(-time.Minute)).Build()
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, lock.Token()).Return(lock, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		// Run
+		res, err := svc.Refresh(ctx, &RefreshCmd{
+			User:  user,
+			Path:  dfs.NewPathCmd(space, "/foo"),
+			Token: lock.Token(),
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, ErrLockNotFound)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("Unlock success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).Build()
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, lock.Token()).Return(lock, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Delete", mock.Anything, lock.Token()).Return(nil).Once()
+
+		// Run
+		err := svc.Unlock(ctx, &UnlockCmd{
+			User:  user,
+			Path:  dfs.NewPathCmd(space, "/foo"),
+			Token: lock.Token(),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("Unlock with an unknown token", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, "urn:uuid:unknown").Return(nil, errNotFound).Once()
+
+		// Run
+		err := svc.Unlock(ctx, &UnlockCmd{
+			User:  user,
+			Path:  dfs.NewPathCmd(space, "/foo"),
+			Token: "urn:uuid:unknown",
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, ErrLockNotFound)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("Unlock with a path not covered by the lock", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).Build()
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, lock.Token()).Return(lock, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		// Run
+		err := svc.Unlock(ctx, &UnlockCmd{
+			User:  user,
+			Path:  dfs.NewPathCmd(space, "/bar"),
+			Token: lock.Token(),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, ErrLockNotFound)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("Unlock by an another user", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		otherUser := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).Build()
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, lock.Token()).Return(lock, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		// Run
+		err := svc.Unlock(ctx, &UnlockCmd{
+			User:  otherUser,
+			Path:  dfs.NewPathCmd(space, "/foo"),
+			Token: lock.Token(),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, ErrNotLockOwner)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("Confirm success without any lock", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		otherLock := NewFakeLock(t, dfs.NewPathCmd(space, "/bar"), user).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("GetAllActiveInSpace", mock.Anything, space.ID(), now).Return([]Lock{*otherLock}, nil).Once()
+
+		// Run
+		err := svc.Confirm(ctx, &ConfirmCmd{
+			User: user,
+			Path: dfs.NewPathCmd(space, "/foo"),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("Confirm success with the submitted token", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("GetAllActiveInSpace", mock.Anything, space.ID(), now).Return([]Lock{*lock}, nil).Once()
+
+		// Run
+		err := svc.Confirm(ctx, &ConfirmCmd{
+			User:   user,
+			Path:   dfs.NewPathCmd(space, "/foo/bar.txt"),
+			Tokens: []string{lock.Token()},
+		})
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("Confirm with a lock on a descendant", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo/bar.txt"), user).WithDepth(DepthZero).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("GetAllActiveInSpace", mock.Anything, space.ID(), now).Return([]Lock{*lock}, nil).Once()
+
+		// Run
+		err := svc.Confirm(ctx, &ConfirmCmd{
+			User: user,
+			Path: dfs.NewPathCmd(space, "/foo"),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, ErrLocked)
+	})
+
+	t.Run("Confirm with a token submitted by an another user", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		otherUser := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("GetAllActiveInSpace", mock.Anything, space.ID(), now).Return([]Lock{*lock}, nil).Once()
+
+		// Run
+		err := svc.Confirm(ctx, &ConfirmCmd{
+			User:   otherUser,
+			Path:   dfs.NewPathCmd(space, "/foo"),
+			Tokens: []string{lock.Token()},
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, ErrLocked)
+	})
+
+	t.Run("Confirm with only one of the shared locks submitted", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		otherUser := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).WithScope(ScopeShared).Build()
+		otherLock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), otherUser).WithScope(ScopeShared).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("GetAllActiveInSpace", mock.Anything, space.ID(), now).Return([]Lock{*lock, *otherLock}, nil).Once()
+
+		// Run
+		err := svc.Confirm(ctx, &ConfirmCmd{
+			User:   user,
+			Path:   dfs.NewPathCmd(space, "/foo"),
+			Tokens: []string{lock.Token()},
+		})
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("Confirm without any shared lock submitted", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).WithScope(ScopeShared).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("GetAllActiveInSpace", mock.Anything, space.ID(), now).Return([]Lock{*lock}, nil).Once()
+
+		// Run
+		err := svc.Confirm(ctx, &ConfirmCmd{
+			User: user,
+			Path: dfs.NewPathCmd(space, "/foo"),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, ErrLocked)
+	})
+
+	t.Run("GetConflicts returns only the locks not submitted", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		otherUser := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		lockedByOther := NewFakeLock(t, dfs.NewPathCmd(space, "/foo/bar.txt"), otherUser).WithDepth(DepthZero).Build()
+		submitted := NewFakeLock(t, dfs.NewPathCmd(space, "/foo/baz.txt"), user).WithDepth(DepthZero).Build()
+		outside := NewFakeLock(t, dfs.NewPathCmd(space, "/other"), otherUser).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("GetAllActiveInSpace", mock.Anything, space.ID(), now).
+			Return([]Lock{*lockedByOther, *submitted, *outside}, nil).Once()
+
+		// Run
+		res, err := svc.GetConflicts(ctx, &ConfirmCmd{
+			User:   user,
+			Path:   dfs.NewPathCmd(space, "/foo"),
+			Tokens: []string{submitted.Token()},
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []Lock{*lockedByOther}, res)
+	})
+
+	t.Run("RemoveAll success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).Build()
+		childLock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo/bar"), user).Build()
+		otherLock := NewFakeLock(t, dfs.NewPathCmd(space, "/foobar"), user).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("GetAllActiveInSpace", mock.Anything, space.ID(), now).
+			Return([]Lock{*lock, *childLock, *otherLock}, nil).Once()
+		storageMock.On("Delete", mock.Anything, lock.Token()).Return(nil).Once()
+		storageMock.On("Delete", mock.Anything, childLock.Token()).Return(nil).Once()
+
+		// Run
+		err := svc.RemoveAll(ctx, dfs.NewPathCmd(space, "/foo"))
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("DeleteAll success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+
+		// Mocks
+		storageMock.On("DeleteAllForUser", mock.Anything, user.ID()).Return(nil).Once()
+
+		// Run
+		err := svc.DeleteAll(ctx, user.ID())
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("DeleteAll with an error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		svc := newService(tools, storageMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+
+		// Mocks
+		storageMock.On("DeleteAllForUser", mock.Anything, user.ID()).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		err := svc.DeleteAll(ctx, user.ID())
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+}
@@ -0,0 +1,158 @@
+package davlocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const tableName = "dav_locks"
+
+var errNotFound = errors.New("not found")
+
+var allFields = []string{"token", "space_id", "path", "scope", "depth", "owner", "timeout", "expires_at", "created_at", "created_by"}
+
+type sqlStorage struct {
+	db sqlstorage.Querier
+}
+
+func newSqlStorage(db sqlstorage.Querier) *sqlStorage {
+	return &sqlStorage{db}
+}
+
+func (s *sqlStorage) Save(ctx context.Context, lock *Lock) error {
+	_, err := sq.
+		Insert(tableName).
+		Columns(allFields...).
+		Values(lock.token,
+			lock.spaceID,
+			lock.path,
+			lock.scope,
+			lock.depth,
+			lock.owner,
+			int64(lock.timeout.Seconds()),
+			ptr.To(sqlstorage.SQLTime(lock.expiresAt)),
+			ptr.To(sqlstorage.SQLTime(lock.createdAt)),
+			lock.createdBy).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) GetByToken(ctx context.Context, token string) (*Lock, error) {
+	res, err := s.getAllbyKeys(ctx, sq.Eq{"token": token})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, errNotFound
+	}
+
+	return &res[0], nil
+}
+
+// GetAllActiveInSpace returns all the locks not yet expired at the given date.
+func (s *sqlStorage) GetAllActiveInSpace(ctx context.Context, spaceID uuid.UUID, now time.Time) ([]Lock, error) {
+	return s.getAllbyKeys(ctx, sq.Eq{"space_id": spaceID}, sq.Gt{"expires_at": ptr.To(sqlstorage.SQLTime(now))})
+}
+
+func (s *sqlStorage) Patch(ctx context.Context, token string, fields map[string]any) error {
+	_, err := sq.
+		Update(tableName).
+		SetMap(fields).
+		Where(sq.Eq{"token": token}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) Delete(ctx context.Context, token string) error {
+	return s.deleteByKeys(ctx, sq.Eq{"token": token})
+}
+
+func (s *sqlStorage) DeleteAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return s.deleteByKeys(ctx, sq.Eq{"created_by": userID})
+}
+
+// DeleteAllExpired removes all the locks expired at the given date.
+func (s *sqlStorage) DeleteAllExpired(ctx context.Context, now time.Time) error {
+	return s.deleteByKeys(ctx, sq.LtOrEq{"expires_at": ptr.To(sqlstorage.SQLTime(now))})
+}
+
+func (s *sqlStorage) deleteByKeys(ctx context.Context, wheres ...any) error {
+	query := sq.Delete(tableName)
+
+	for _, where := range wheres {
+		query = query.Where(where)
+	}
+
+	_, err := query.
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) getAllbyKeys(ctx context.Context, wheres ...any) ([]Lock, error) {
+	query := sq.
+		Select(allFields...).
+		From(tableName)
+
+	for _, where := range wheres {
+		query = query.Where(where)
+	}
+
+	rows, err := query.
+		OrderBy("created_at", "token").
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	locks := []Lock{}
+
+	for rows.Next() {
+		var res Lock
+		var timeout int64
+		var sqlExpiresAt sqlstorage.SQLTime
+		var sqlCreatedAt sqlstorage.SQLTime
+
+		err := rows.Scan(&res.token, &res.spaceID, &res.path, &res.scope, &res.depth, &res.owner, &timeout, &sqlExpiresAt, &sqlCreatedAt, &res.createdBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		res.timeout = time.Duration(timeout) * time.Second
+		res.expiresAt = sqlExpiresAt.Time()
+		res.createdAt = sqlCreatedAt.Time()
+
+		locks = append(locks, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return locks, nil
+}
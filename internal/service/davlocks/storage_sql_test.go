@@ -0,0 +1,127 @@
+package davlocks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+)
+
+func TestLockSqlstore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db := sqlstorage.NewTestStorage(t)
+	store := newSqlStorage(db)
+
+	now := time.Now().UTC()
+	user := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	space := spaces.NewFakeSpace(t).Build()
+	lock := NewFakeLock(t, dfs.NewPathCmd(space, "/foo"), user).Build()
+	expiredLock := NewFakeLock(t, dfs.NewPathCmd(space, "/bar"), user).ExpiresAt(now.Add(-time.Minute)).Build()
+
+	t.Run("Save success", func(t *testing.T) {
+		// Run
+		err := store.Save(ctx, lock)
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("Save an expired lock success", func(t *testing.T) {
+		// Run
+		err := store.Save(ctx, expiredLock)
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("GetByToken success", func(t *testing.T) {
+		// Run
+		res, err := store.GetByToken(ctx, lock.Token())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, lock, res)
+	})
+
+	t.Run("GetByToken not found", func(t *testing.T) {
+		// Run
+		res, err := store.GetByToken(ctx, "urn:uuid:some-invalid-token")
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("GetAllActiveInSpace success", func(t *testing.T) {
+		// Run
+		res, err := store.GetAllActiveInSpace(ctx, space.ID(), now)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []Lock{*lock}, res)
+	})
+
+	t.Run("Patch success", func(t *testing.T) {
+		// Data
+		newExpiresAt := now.Add(2 * time.Hour)
+
+		// Run
+		err := store.Patch(ctx, lock.Token(), map[string]any{
+			"timeout":    int64((2 * time.Hour).Seconds()),
+			"expires_at": sqlstorage.SQLTime(newExpiresAt),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		res, err := store.GetByToken(ctx, lock.Token())
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Hour, res.Timeout())
+		assert.Equal(t, newExpiresAt, res.ExpiresAt())
+	})
+
+	t.Run("DeleteAllExpired success", func(t *testing.T) {
+		// Run
+		err := store.DeleteAllExpired(ctx, now)
+
+		// Asserts
+		require.NoError(t, err)
+		_, err = store.GetByToken(ctx, expiredLock.Token())
+		require.ErrorIs(t, err, errNotFound)
+		_, err = store.GetByToken(ctx, lock.Token())
+		require.NoError(t, err)
+	})
+
+	t.Run("Delete success", func(t *testing.T) {
+		// Run
+		err := store.Delete(ctx, lock.Token())
+
+		// Asserts
+		require.NoError(t, err)
+		_, err = store.GetByToken(ctx, lock.Token())
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("DeleteAllForUser success", func(t *testing.T) {
+		// Setup
+		err := store.Save(ctx, lock)
+		require.NoError(t, err)
+
+		// Run
+		err = store.DeleteAllForUser(ctx, user.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		res, err := store.GetAllActiveInSpace(ctx, space.ID(), now)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+}
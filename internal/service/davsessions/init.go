@@ -3,6 +3,7 @@ package davsessions
 import (
 	"context"
 
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/secret"
@@ -14,13 +15,13 @@ import (
 type Service interface {
 	GetAllForUser(ctx context.Context, userID uuid.UUID, paginateCmd *sqlstorage.PaginateCmd) ([]DavSession, error)
 	Create(ctx context.Context, cmd *CreateCmd) (*DavSession, string, error)
-	Authenticate(ctx context.Context, username string, password secret.Text) (*DavSession, error)
+	Authenticate(ctx context.Context, username string, password secret.Text, remoteAddr string) (*DavSession, error)
 	Delete(ctx context.Context, cmd *DeleteCmd) error
 	DeleteAll(ctx context.Context, userID uuid.UUID) error
 }
 
-func Init(db sqlstorage.Querier, spaces spaces.Service, tools tools.Tools) Service {
+func Init(db sqlstorage.Querier, spaces spaces.Service, fs dfs.Service, tools tools.Tools) Service {
 	storage := newSqlStorage(db)
 
-	return newService(storage, spaces, tools)
+	return newService(storage, spaces, fs, tools)
 }
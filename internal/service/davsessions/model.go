@@ -1,6 +1,7 @@
 package davsessions
 
 import (
+	"errors"
 	"regexp"
 	"time"
 
@@ -20,31 +21,99 @@ type DavSession struct {
 	username  string
 	password  secret.Text
 	spaceID   uuid.UUID
+	// grantID is set when the session only gives an access to a folder shared
+	// with the user instead of the whole space.
+	grantID *uuid.UUID
+	// allSpaces is set when the session gives an access to all the spaces of
+	// the user. The spaceID is empty in this case.
+	allSpaces bool
+	// readOnly refuses all the methods modifying the files.
+	readOnly bool
+	// rootPath is the folder mounted at the WebDAV root. It's relative to the
+	// space root or to the shared folder and it's always "/" with all the
+	// spaces.
+	rootPath string
+	// expiresAt is nil for the sessions without expiration date.
+	expiresAt *time.Time
+	// lastUsedAt and lastUsedIP are nil until the first authentication.
+	lastUsedAt *time.Time
+	lastUsedIP *string
 }
 
-func (u *DavSession) ID() uuid.UUID        { return u.id }
-func (u *DavSession) UserID() uuid.UUID    { return u.userID }
-func (u DavSession) Name() string          { return u.name }
-func (u *DavSession) Username() string     { return u.username }
-func (u *DavSession) SpaceID() uuid.UUID   { return u.spaceID }
-func (u *DavSession) CreatedAt() time.Time { return u.createdAt }
+func (u *DavSession) ID() uuid.UUID          { return u.id }
+func (u *DavSession) UserID() uuid.UUID      { return u.userID }
+func (u DavSession) Name() string            { return u.name }
+func (u *DavSession) Username() string       { return u.username }
+func (u *DavSession) SpaceID() uuid.UUID     { return u.spaceID }
+func (u *DavSession) GrantID() *uuid.UUID    { return u.grantID }
+func (u *DavSession) AllSpaces() bool        { return u.allSpaces }
+func (u *DavSession) ReadOnly() bool         { return u.readOnly }
+func (u *DavSession) RootPath() string       { return u.rootPath }
+func (u *DavSession) ExpiresAt() *time.Time  { return u.expiresAt }
+func (u *DavSession) LastUsedAt() *time.Time { return u.lastUsedAt }
+func (u *DavSession) LastUsedIP() *string    { return u.lastUsedIP }
+func (u *DavSession) CreatedAt() time.Time   { return u.createdAt }
+
+// IsExpired returns true if the session have an expiration date and this date
+// is reached.
+func (u *DavSession) IsExpired(now time.Time) bool {
+	return u.expiresAt != nil && !now.Before(*u.expiresAt)
+}
 
 type CreateCmd struct {
 	Name     string
 	Username string
 	UserID   uuid.UUID
 	SpaceID  uuid.UUID
+	// GrantID is optional. If set, the session is restricted to the shared folder
+	// and SpaceID is ignored.
+	GrantID *uuid.UUID
+	// AllSpaces is optional. If set, the session gives an access to all the
+	// spaces of the user, listed as the collections of the WebDAV root.
+	// SpaceID is ignored and GrantID must be empty.
+	AllSpaces bool
+	// ReadOnly is optional. If set, all the methods modifying the files are
+	// refused.
+	ReadOnly bool
+	// RootPath is optional. If set, only this folder is exposed. The path is
+	// relative to the space root or to the shared folder and it must be empty
+	// with all the spaces.
+	RootPath string
+	// ExpiresAt is optional. If set, the session is refused from this date.
+	ExpiresAt *time.Time
 }
 
 func (t CreateCmd) Validate() error {
+	spaceRules := []v.Rule{is.UUIDv4}
+	if t.GrantID == nil && !t.AllSpaces {
+		spaceRules = append(spaceRules, v.Required)
+	}
+
+	grantRules := []v.Rule{is.UUIDv4}
+	rootPathRules := []v.Rule{v.Length(0, 4096)}
+	if t.AllSpaces {
+		grantRules = append(grantRules, v.By(emptyWithAllSpaces))
+		rootPathRules = append(rootPathRules, v.By(emptyWithAllSpaces))
+	}
+
 	return v.ValidateStruct(&t,
 		v.Field(&t.Name, v.Required, v.Match(DavSessionRegexp)),
 		v.Field(&t.Username, v.Required, v.Length(1, 30)),
 		v.Field(&t.UserID, v.Required, is.UUIDv4),
-		v.Field(&t.SpaceID, v.Required, is.UUIDv4),
+		v.Field(&t.SpaceID, spaceRules...),
+		v.Field(&t.GrantID, grantRules...),
+		v.Field(&t.RootPath, rootPathRules...),
 	)
 }
 
+func emptyWithAllSpaces(value any) error {
+	if !v.IsEmpty(value) {
+		return errors.New("must be empty with all the spaces")
+	}
+
+	return nil
+}
+
 type DeleteCmd struct {
 	UserID    uuid.UUID
 	SessionID uuid.UUID
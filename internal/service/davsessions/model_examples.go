@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
 	"github.com/theduckcompany/duckcloud/internal/tools/secret"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
@@ -17,15 +18,21 @@ var ExampleAliceSession = DavSession{
 	username:  "Alice",
 	password:  secret.NewText("736f6d652d70617373776f7264"), // hex-encoding of "some-password"
 	spaceID:   spaces.ExampleAlicePersonalSpace.ID(),
+	rootPath:  "/",
 	createdAt: now,
 }
 
 var ExampleAliceSession2 = DavSession{
-	id:        uuid.UUID("0c2f3980-3ee4-42dc-8c9e-17249a99203d"),
-	name:      "My Computer",
-	userID:    uuid.UUID("86bffce3-3f53-4631-baf8-8530773884f3"),
-	username:  "Alice",
-	password:  secret.NewText("736f6d652d70617373776f7264"), // hex-encoding of "some-password"
-	spaceID:   spaces.ExampleAlicePersonalSpace.ID(),
-	createdAt: now,
+	id:         uuid.UUID("0c2f3980-3ee4-42dc-8c9e-17249a99203d"),
+	name:       "My Computer",
+	userID:     uuid.UUID("86bffce3-3f53-4631-baf8-8530773884f3"),
+	username:   "Alice",
+	password:   secret.NewText("736f6d652d70617373776f7264"), // hex-encoding of "some-password"
+	spaceID:    spaces.ExampleAlicePersonalSpace.ID(),
+	rootPath:   "/Movies",
+	readOnly:   true,
+	expiresAt:  ptr.To(now.Add(24 * time.Hour)),
+	lastUsedAt: ptr.To(now),
+	lastUsedIP: ptr.To("192.168.1.1"),
+	createdAt:  now,
 }
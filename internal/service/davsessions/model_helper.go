@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
 	"github.com/theduckcompany/duckcloud/internal/tools/secret"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
@@ -33,6 +35,7 @@ func NewFakeSession(t *testing.T) *FakeSessionBuilder {
 			username:  gofakeit.Username(),
 			password:  secret.NewText(hex.EncodeToString([]byte(rawPassword))),
 			spaceID:   uuidProvider.New(),
+			rootPath:  "/",
 		},
 	}
 }
@@ -61,6 +64,46 @@ func (f *FakeSessionBuilder) WithSpace(space *spaces.Space) *FakeSessionBuilder
 	return f
 }
 
+func (f *FakeSessionBuilder) WithGrant(grant *dfs.Grant) *FakeSessionBuilder {
+	f.session.spaceID = grant.SpaceID()
+	f.session.grantID = ptr.To(grant.ID())
+
+	return f
+}
+
+func (f *FakeSessionBuilder) WithAllSpaces() *FakeSessionBuilder {
+	f.session.spaceID = ""
+	f.session.grantID = nil
+	f.session.allSpaces = true
+
+	return f
+}
+
+func (f *FakeSessionBuilder) WithReadOnly() *FakeSessionBuilder {
+	f.session.readOnly = true
+
+	return f
+}
+
+func (f *FakeSessionBuilder) WithRootPath(rootPath string) *FakeSessionBuilder {
+	f.session.rootPath = rootPath
+
+	return f
+}
+
+func (f *FakeSessionBuilder) WithExpiration(expiresAt time.Time) *FakeSessionBuilder {
+	f.session.expiresAt = &expiresAt
+
+	return f
+}
+
+func (f *FakeSessionBuilder) WithLastUsage(at time.Time, ip string) *FakeSessionBuilder {
+	f.session.lastUsedAt = &at
+	f.session.lastUsedIP = &ip
+
+	return f
+}
+
 func (f *FakeSessionBuilder) CreatedAt(at time.Time) *FakeSessionBuilder {
 	f.session.createdAt = at
 
@@ -2,11 +2,13 @@ package davsessions
 
 import (
 	"testing"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
@@ -16,9 +18,24 @@ func TestDavSession_Getters(t *testing.T) {
 	assert.Equal(t, ExampleAliceSession.name, ExampleAliceSession.Name())
 	assert.Equal(t, ExampleAliceSession.spaceID, ExampleAliceSession.SpaceID())
 	assert.Equal(t, ExampleAliceSession.username, ExampleAliceSession.Username())
+	assert.Equal(t, ExampleAliceSession.grantID, ExampleAliceSession.GrantID())
+	assert.Equal(t, ExampleAliceSession.allSpaces, ExampleAliceSession.AllSpaces())
+	assert.Equal(t, ExampleAliceSession.readOnly, ExampleAliceSession.ReadOnly())
+	assert.Equal(t, ExampleAliceSession.rootPath, ExampleAliceSession.RootPath())
+	assert.Equal(t, ExampleAliceSession.expiresAt, ExampleAliceSession.ExpiresAt())
+	assert.Equal(t, ExampleAliceSession.lastUsedAt, ExampleAliceSession.LastUsedAt())
+	assert.Equal(t, ExampleAliceSession.lastUsedIP, ExampleAliceSession.LastUsedIP())
 	assert.Equal(t, ExampleAliceSession.createdAt, ExampleAliceSession.CreatedAt())
 }
 
+func TestDavSession_IsExpired(t *testing.T) {
+	now := time.Now()
+
+	assert.False(t, NewFakeSession(t).Build().IsExpired(now))
+	assert.False(t, NewFakeSession(t).WithExpiration(now.Add(time.Second)).Build().IsExpired(now))
+	assert.True(t, NewFakeSession(t).WithExpiration(now).Build().IsExpired(now))
+}
+
 func Test_CreateUserRequest_is_validatable(t *testing.T) {
 	assert.Implements(t, (*validation.Validatable)(nil), new(CreateCmd))
 }
@@ -34,6 +51,52 @@ func Test_CreateRequest_Validate_success(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_CreateRequest_Validate_with_a_grant(t *testing.T) {
+	err := CreateCmd{
+		Name:     ExampleAliceSession.Name(),
+		UserID:   uuid.UUID("2c6b2615-6204-4817-a126-b6c13074afdf"),
+		Username: "Jane Doe",
+		GrantID:  ptr.To(uuid.UUID("6f7e2c2c-d4a7-4f53-8e5c-7e6fcda3a0d1")),
+	}.Validate()
+
+	require.NoError(t, err)
+}
+
+func Test_CreateRequest_Validate_with_all_spaces(t *testing.T) {
+	err := CreateCmd{
+		Name:      ExampleAliceSession.Name(),
+		UserID:    uuid.UUID("2c6b2615-6204-4817-a126-b6c13074afdf"),
+		Username:  "Jane Doe",
+		AllSpaces: true,
+	}.Validate()
+
+	require.NoError(t, err)
+}
+
+func Test_CreateRequest_Validate_with_all_spaces_and_a_grant(t *testing.T) {
+	err := CreateCmd{
+		Name:      ExampleAliceSession.Name(),
+		UserID:    uuid.UUID("2c6b2615-6204-4817-a126-b6c13074afdf"),
+		Username:  "Jane Doe",
+		GrantID:   ptr.To(uuid.UUID("6f7e2c2c-d4a7-4f53-8e5c-7e6fcda3a0d1")),
+		AllSpaces: true,
+	}.Validate()
+
+	require.EqualError(t, err, "GrantID: must be empty with all the spaces.")
+}
+
+func Test_CreateRequest_Validate_with_all_spaces_and_a_root_path(t *testing.T) {
+	err := CreateCmd{
+		Name:      ExampleAliceSession.Name(),
+		UserID:    uuid.UUID("2c6b2615-6204-4817-a126-b6c13074afdf"),
+		Username:  "Jane Doe",
+		RootPath:  "/Movies",
+		AllSpaces: true,
+	}.Validate()
+
+	require.EqualError(t, err, "RootPath: must be empty with all the spaces.")
+}
+
 func Test_DeleteRequest_is_validatable(t *testing.T) {
 	assert.Implements(t, (*validation.Validatable)(nil), new(DeleteCmd))
 }
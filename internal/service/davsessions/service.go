@@ -5,12 +5,15 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"slices"
+	"path"
+	"time"
 
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/clock"
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
 	"github.com/theduckcompany/duckcloud/internal/tools/secret"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
@@ -20,8 +23,17 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserIDNotMatching  = errors.New("user ids are not matching")
 	ErrInvalidSpaceID     = errors.New("invalid spaceID")
+	ErrInvalidGrantID     = errors.New("invalid grantID")
+	ErrInvalidRootPath    = errors.New("invalid root path")
+	ErrInvalidExpiration  = errors.New("invalid expiration date")
+	ErrSessionExpired     = errors.New("session expired")
 )
 
+// usageRefreshDelay is the minimal delay between two updates of the last
+// usage of a session. A WebDAV client authenticates each request so saving all
+// of them would cost a write for every request.
+const usageRefreshDelay = time.Minute
+
 //go:generate mockery --name storage
 type storage interface {
 	Save(ctx context.Context, session *DavSession) error
@@ -29,20 +41,23 @@ type storage interface {
 	GetAllForUser(ctx context.Context, userID uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]DavSession, error)
 	GetByID(ctx context.Context, sessionID uuid.UUID) (*DavSession, error)
 	RemoveByID(ctx context.Context, sessionID uuid.UUID) error
+	Patch(ctx context.Context, sessionID uuid.UUID, fields map[string]any) error
 }
 
 type service struct {
 	storage storage
 	spaces  spaces.Service
+	fs      dfs.Service
 	uuid    uuid.Service
 	clock   clock.Clock
 }
 
 func newService(storage storage,
 	spaces spaces.Service,
+	fs dfs.Service,
 	tools tools.Tools,
 ) *service {
-	return &service{storage, spaces, tools.UUID(), tools.Clock()}
+	return &service{storage, spaces, fs, tools.UUID(), tools.Clock()}
 }
 
 func (s *service) Create(ctx context.Context, cmd *CreateCmd) (*DavSession, string, error) {
@@ -51,25 +66,83 @@ func (s *service) Create(ctx context.Context, cmd *CreateCmd) (*DavSession, stri
 		return nil, "", errs.Validation(err)
 	}
 
-	space, err := s.spaces.GetUserSpace(ctx, cmd.UserID, cmd.SpaceID)
-	if err != nil && !errors.Is(err, errs.ErrNotFound) {
-		return nil, "", errs.Internal(fmt.Errorf("failed to get the space %q by id: %w", cmd.SpaceID, err))
+	if cmd.ExpiresAt != nil && !s.clock.Now().Before(*cmd.ExpiresAt) {
+		return nil, "", errs.BadRequest(ErrInvalidExpiration, "the expiration date must be in the future")
 	}
 
-	if space == nil || !slices.Contains(space.Owners(), cmd.UserID) {
-		return nil, "", errs.BadRequest(ErrInvalidSpaceID, "invalid spaces")
+	rootPath := dfs.CleanPath(cmd.RootPath)
+
+	spaceID := cmd.SpaceID
+	switch {
+	case cmd.AllSpaces:
+		// The spaces are resolved for each request so the spaces created
+		// later are also available.
+		spaceID = ""
+	case cmd.GrantID != nil:
+		// The user isn't a member of the space, the access is given by the grant.
+		grant, err := s.fs.GetUserGrant(ctx, cmd.UserID, *cmd.GrantID)
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, "", errs.BadRequest(ErrInvalidGrantID, "invalid shared folder")
+		}
+
+		if err != nil {
+			return nil, "", errs.Internal(fmt.Errorf("failed to get the grant %q: %w", *cmd.GrantID, err))
+		}
+
+		spaceID = grant.SpaceID()
+
+		if rootPath != "/" {
+			root, err := s.fs.GetGrantPath(ctx, grant)
+			if errors.Is(err, errs.ErrNotFound) {
+				return nil, "", errs.BadRequest(ErrInvalidGrantID, "invalid shared folder")
+			}
+
+			if err != nil {
+				return nil, "", errs.Internal(fmt.Errorf("failed to get the grant %q path: %w", grant.ID(), err))
+			}
+
+			err = s.checkRootPath(ctx, dfs.NewPathCmd(root.Space(), path.Join(root.Path(), rootPath)))
+			if err != nil {
+				return nil, "", err
+			}
+		}
+	default:
+		space, err := s.spaces.GetUserSpace(ctx, cmd.UserID, cmd.SpaceID)
+		if errors.Is(err, errs.ErrNotFound) || errors.Is(err, errs.ErrUnauthorized) {
+			return nil, "", errs.BadRequest(ErrInvalidSpaceID, "invalid spaces")
+		}
+
+		if err != nil {
+			return nil, "", errs.Internal(fmt.Errorf("failed to get the space %q by id: %w", cmd.SpaceID, err))
+		}
+
+		spaceID = space.ID()
+
+		if rootPath != "/" {
+			err = s.checkRootPath(ctx, dfs.NewPathCmd(space, rootPath))
+			if err != nil {
+				return nil, "", err
+			}
+		}
 	}
 
 	password := string(s.uuid.New())
 
 	session := DavSession{
-		id:        s.uuid.New(),
-		userID:    cmd.UserID,
-		name:      cmd.Name,
-		username:  cmd.Username,
-		password:  secret.NewText(hex.EncodeToString([]byte(password))),
-		spaceID:   space.ID(),
-		createdAt: s.clock.Now(),
+		id:         s.uuid.New(),
+		userID:     cmd.UserID,
+		name:       cmd.Name,
+		username:   cmd.Username,
+		password:   secret.NewText(hex.EncodeToString([]byte(password))),
+		spaceID:    spaceID,
+		grantID:    cmd.GrantID,
+		allSpaces:  cmd.AllSpaces,
+		readOnly:   cmd.ReadOnly,
+		rootPath:   rootPath,
+		expiresAt:  cmd.ExpiresAt,
+		lastUsedAt: nil,
+		lastUsedIP: nil,
+		createdAt:  s.clock.Now(),
 	}
 
 	err = s.storage.Save(ctx, &session)
@@ -80,7 +153,28 @@ func (s *service) Create(ctx context.Context, cmd *CreateCmd) (*DavSession, stri
 	return &session, password, nil
 }
 
-func (s *service) Authenticate(ctx context.Context, username string, password secret.Text) (*DavSession, error) {
+// checkRootPath returns an ErrBadRequest if the root path of a new session is
+// not an existing folder.
+func (s *service) checkRootPath(ctx context.Context, cmd *dfs.PathCmd) error {
+	inode, err := s.fs.Get(ctx, cmd)
+	if errors.Is(err, errs.ErrNotFound) {
+		return errs.BadRequest(ErrInvalidRootPath, "folder not found")
+	}
+
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to Get the root path: %w", err))
+	}
+
+	if !inode.IsDir() {
+		return errs.BadRequest(ErrInvalidRootPath, "not a folder")
+	}
+
+	return nil
+}
+
+// Authenticate returns the session matching the given credentials. The last
+// usage of the session is saved with the given remote address.
+func (s *service) Authenticate(ctx context.Context, username string, password secret.Text, remoteAddr string) (*DavSession, error) {
 	res, err := s.storage.GetByUsernameAndPassword(ctx, username, secret.NewText(hex.EncodeToString([]byte(password.Raw()))))
 	if errors.Is(err, errNotFound) {
 		return nil, errs.BadRequest(ErrInvalidCredentials, "invalid credentials")
@@ -90,6 +184,27 @@ func (s *service) Authenticate(ctx context.Context, username string, password se
 		return nil, errs.Internal(fmt.Errorf("failed to GetByUsernameandPassword: %w", err))
 	}
 
+	now := s.clock.Now()
+	if res.IsExpired(now) {
+		return nil, errs.Unauthorized(ErrSessionExpired, "session expired")
+	}
+
+	if res.lastUsedAt != nil && now.Sub(*res.lastUsedAt) < usageRefreshDelay &&
+		res.lastUsedIP != nil && *res.lastUsedIP == remoteAddr {
+		return res, nil
+	}
+
+	res.lastUsedAt = &now
+	res.lastUsedIP = &remoteAddr
+
+	err = s.storage.Patch(ctx, res.ID(), map[string]any{
+		"last_used_at": ptr.To(sqlstorage.SQLTime(now)),
+		"last_used_ip": remoteAddr,
+	})
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to patch the session's last usage: %w", err))
+	}
+
 	return res, nil
 }
 
@@ -18,25 +18,25 @@ type MockService struct {
 	mock.Mock
 }
 
-// Authenticate provides a mock function with given fields: ctx, username, password
-func (_m *MockService) Authenticate(ctx context.Context, username string, password secret.Text) (*DavSession, error) {
-	ret := _m.Called(ctx, username, password)
+// Authenticate provides a mock function with given fields: ctx, username, password, remoteAddr
+func (_m *MockService) Authenticate(ctx context.Context, username string, password secret.Text, remoteAddr string) (*DavSession, error) {
+	ret := _m.Called(ctx, username, password, remoteAddr)
 
 	var r0 *DavSession
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, secret.Text) (*DavSession, error)); ok {
-		return rf(ctx, username, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, secret.Text, string) (*DavSession, error)); ok {
+		return rf(ctx, username, password, remoteAddr)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, secret.Text) *DavSession); ok {
-		r0 = rf(ctx, username, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, secret.Text, string) *DavSession); ok {
+		r0 = rf(ctx, username, password, remoteAddr)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*DavSession)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, secret.Text) error); ok {
-		r1 = rf(ctx, username, password)
+	if rf, ok := ret.Get(1).(func(context.Context, string, secret.Text, string) error); ok {
+		r1 = rf(ctx, username, password, remoteAddr)
 	} else {
 		r1 = ret.Error(1)
 	}
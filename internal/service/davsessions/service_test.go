@@ -3,6 +3,7 @@ package davsessions
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -10,10 +11,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
 	"github.com/theduckcompany/duckcloud/internal/tools/secret"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
@@ -29,12 +32,13 @@ func TestDavSessionsService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
 		now := time.Now().UTC()
 		user := users.NewFakeUser(t).Build()
-		space := spaces.NewFakeSpace(t).WithOwners(*user).Build()
+		space := spaces.NewFakeSpace(t).Build()
 		sessionPassword := "some-password"
 		session := NewFakeSession(t).
 			WithName("My Session").
@@ -72,7 +76,8 @@ func TestDavSessionsService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
 		space := spaces.NewFakeSpace(t).Build()
@@ -101,11 +106,12 @@ func TestDavSessionsService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).Build()
-		space := spaces.NewFakeSpace(t).WithOwners(*user).Build()
+		space := spaces.NewFakeSpace(t).Build()
 		session := NewFakeSession(t).Build()
 
 		// Mocks
@@ -126,21 +132,144 @@ func TestDavSessionsService(t *testing.T) {
 		require.ErrorIs(t, err, errNotFound)
 	})
 
-	t.Run("Create with a space not owned by the given user", func(t *testing.T) {
+	t.Run("Create with a grant success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
+
+		// Data
+		now := time.Now().UTC()
+		user := users.NewFakeUser(t).Build()
+		grant := dfs.NewFakeGrant(t, &dfs.ExampleAliceDir, user).Build()
+		sessionPassword := "some-password"
+		session := NewFakeSession(t).
+			WithName("My Session").
+			WithUsername("some-username").
+			WithGrant(grant).
+			WithPassword(sessionPassword).
+			CreatedAt(now).
+			CreatedBy(user).
+			Build()
+
+		// Mocks
+		fsMock.On("GetUserGrant", mock.Anything, user.ID(), grant.ID()).Return(grant, nil).Once()
+		tools.UUIDMock.On("New").Return(uuid.UUID(sessionPassword)).Once()
+		tools.UUIDMock.On("New").Return(session.ID()).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Save", mock.Anything, session).Return(nil).Once()
+
+		// Run
+		res, secret, err := service.Create(ctx, &CreateCmd{
+			Name:     "My Session",
+			UserID:   user.ID(),
+			Username: "some-username",
+			GrantID:  ptr.To(grant.ID()),
+		})
+
+		// Asserts
+		assert.NotEmpty(t, secret)
+		require.NoError(t, err)
+		assert.Equal(t, session, res)
+	})
+
+	t.Run("Create with all the spaces success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
+
+		// Data
+		now := time.Now().UTC()
+		user := users.NewFakeUser(t).Build()
+		sessionPassword := "some-password"
+		session := NewFakeSession(t).
+			WithName("My Session").
+			WithUsername("some-username").
+			WithAllSpaces().
+			WithPassword(sessionPassword).
+			CreatedAt(now).
+			CreatedBy(user).
+			Build()
+
+		// Mocks
+		tools.UUIDMock.On("New").Return(uuid.UUID(sessionPassword)).Once()
+		tools.UUIDMock.On("New").Return(session.ID()).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Save", mock.Anything, session).Return(nil).Once()
+
+		// Run
+		res, secret, err := service.Create(ctx, &CreateCmd{
+			Name:      "My Session",
+			UserID:    user.ID(),
+			Username:  "some-username",
+			AllSpaces: true,
+		})
+
+		// Asserts
+		assert.NotEmpty(t, secret)
+		require.NoError(t, err)
+		assert.Equal(t, session, res)
+		assert.True(t, res.AllSpaces())
+		assert.Empty(t, res.SpaceID())
+	})
+
+	t.Run("Create with a grant not found", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).Build()
-		space := spaces.NewFakeSpace(t).Build() // The space doesn't have the user as owner
+		grant := dfs.NewFakeGrant(t, &dfs.ExampleAliceDir, users.NewFakeUser(t).Build()).Build() // The grant is given to an other user
 		session := NewFakeSession(t).Build()
 
 		// Mocks
-		spacesMock.On("GetUserSpace", mock.Anything, user.ID(), space.ID()).Return(space, nil).Once()
+		fsMock.On("GetUserGrant", mock.Anything, user.ID(), grant.ID()).
+			Return(nil, errs.NotFound(errors.New("some-error"))).Once()
+
+		// Run
+		res, secret, err := service.Create(ctx, &CreateCmd{
+			Name:     session.name,
+			UserID:   user.ID(),
+			Username: session.username,
+			GrantID:  ptr.To(grant.ID()),
+		})
+
+		// Assets
+		assert.Nil(t, res)
+		assert.Empty(t, secret)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrInvalidGrantID)
+	})
+
+	t.Run("Create with a space where the user is not a member", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build() // The space doesn't have the user as member
+		session := NewFakeSession(t).Build()
+
+		// Mocks
+		spacesMock.On("GetUserSpace", mock.Anything, user.ID(), space.ID()).
+			Return(nil, errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
 
 		// Run
 		res, secret, err := service.Create(ctx, &CreateCmd{
@@ -156,13 +285,218 @@ func TestDavSessionsService(t *testing.T) {
 		require.EqualError(t, err, "bad request: invalid spaceID")
 	})
 
+	t.Run("Create with a read only scope success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
+
+		// Data
+		now := time.Now().UTC()
+		expiresAt := now.Add(24 * time.Hour)
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		dir := dfs.NewFakeINode(t).WithSpace(space).WithName("Movies").IsDirectory().Build()
+		sessionPassword := "some-password"
+		session := NewFakeSession(t).
+			WithName("My Session").
+			WithUsername("some-username").
+			WithSpace(space).
+			WithPassword(sessionPassword).
+			WithReadOnly().
+			WithRootPath("/Movies").
+			WithExpiration(expiresAt).
+			CreatedAt(now).
+			CreatedBy(user).
+			Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, user.ID(), space.ID()).Return(space, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(space, "/Movies")).Return(dir, nil).Once()
+		tools.UUIDMock.On("New").Return(uuid.UUID(sessionPassword)).Once()
+		tools.UUIDMock.On("New").Return(session.ID()).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Save", mock.Anything, session).Return(nil).Once()
+
+		// Run
+		res, secret, err := service.Create(ctx, &CreateCmd{
+			Name:      "My Session",
+			UserID:    user.ID(),
+			Username:  "some-username",
+			SpaceID:   space.ID(),
+			ReadOnly:  true,
+			RootPath:  "Movies/",
+			ExpiresAt: &expiresAt,
+		})
+
+		// Asserts
+		assert.NotEmpty(t, secret)
+		require.NoError(t, err)
+		assert.Equal(t, session, res)
+	})
+
+	t.Run("Create with a grant and a root path success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
+
+		// Data
+		now := time.Now().UTC()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		grant := dfs.NewFakeGrant(t, &dfs.ExampleAliceDir, user).Build()
+		dir := dfs.NewFakeINode(t).WithSpace(space).IsDirectory().Build()
+		sessionPassword := "some-password"
+		session := NewFakeSession(t).
+			WithName("My Session").
+			WithUsername("some-username").
+			WithGrant(grant).
+			WithPassword(sessionPassword).
+			WithRootPath("/Movies").
+			CreatedAt(now).
+			CreatedBy(user).
+			Build()
+
+		// Mocks
+		fsMock.On("GetUserGrant", mock.Anything, user.ID(), grant.ID()).Return(grant, nil).Once()
+		fsMock.On("GetGrantPath", mock.Anything, grant).Return(dfs.NewPathCmd(space, "/shared"), nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(space, "/shared/Movies")).Return(dir, nil).Once()
+		tools.UUIDMock.On("New").Return(uuid.UUID(sessionPassword)).Once()
+		tools.UUIDMock.On("New").Return(session.ID()).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Save", mock.Anything, session).Return(nil).Once()
+
+		// Run
+		res, secret, err := service.Create(ctx, &CreateCmd{
+			Name:     "My Session",
+			UserID:   user.ID(),
+			Username: "some-username",
+			GrantID:  ptr.To(grant.ID()),
+			RootPath: "/Movies",
+		})
+
+		// Asserts
+		assert.NotEmpty(t, secret)
+		require.NoError(t, err)
+		assert.Equal(t, session, res)
+	})
+
+	t.Run("Create with a root path not found", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+
+		// Mocks
+		spacesMock.On("GetUserSpace", mock.Anything, user.ID(), space.ID()).Return(space, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(space, "/Movies")).
+			Return(nil, errs.NotFound(errors.New("some-error"))).Once()
+
+		// Run
+		res, secret, err := service.Create(ctx, &CreateCmd{
+			Name:     "My Session",
+			UserID:   user.ID(),
+			Username: "some-username",
+			SpaceID:  space.ID(),
+			RootPath: "/Movies",
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		assert.Empty(t, secret)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrInvalidRootPath)
+	})
+
+	t.Run("Create with a root path on a file", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		file := dfs.NewFakeINode(t).WithSpace(space).Build()
+
+		// Mocks
+		spacesMock.On("GetUserSpace", mock.Anything, user.ID(), space.ID()).Return(space, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(space, "/movie.mkv")).Return(file, nil).Once()
+
+		// Run
+		res, secret, err := service.Create(ctx, &CreateCmd{
+			Name:     "My Session",
+			UserID:   user.ID(),
+			Username: "some-username",
+			SpaceID:  space.ID(),
+			RootPath: "/movie.mkv",
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		assert.Empty(t, secret)
+		require.ErrorIs(t, err, ErrInvalidRootPath)
+	})
+
+	t.Run("Create with an expiration date in the past", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
+
+		// Data
+		now := time.Now().UTC()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		// Run
+		res, secret, err := service.Create(ctx, &CreateCmd{
+			Name:      "My Session",
+			UserID:    user.ID(),
+			Username:  "some-username",
+			SpaceID:   space.ID(),
+			ExpiresAt: ptr.To(now.Add(-time.Hour)),
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		assert.Empty(t, secret)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrInvalidExpiration)
+	})
+
 	t.Run("GetAllForUser success", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
 		session := NewFakeSession(t).Build()
@@ -184,9 +518,11 @@ func TestDavSessionsService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
+		now := time.Now().UTC()
 		sessionPassword := "some-password"
 		session := NewFakeSession(t).
 			WithPassword(sessionPassword).
@@ -195,13 +531,82 @@ func TestDavSessionsService(t *testing.T) {
 		// Mocks
 		storageMock.On("GetByUsernameAndPassword", mock.Anything, session.username, secret.NewText(hex.EncodeToString([]byte("some-password")))).
 			Return(session, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Patch", mock.Anything, session.ID(), map[string]any{
+			"last_used_at": ptr.To(sqlstorage.SQLTime(now)),
+			"last_used_ip": "192.168.1.1",
+		}).Return(nil).Once()
 
 		// Run
-		res, err := service.Authenticate(ctx, session.username, secret.NewText(sessionPassword))
+		res, err := service.Authenticate(ctx, session.username, secret.NewText(sessionPassword), "192.168.1.1")
 
 		// Asserts
 		require.NoError(t, err)
 		assert.Equal(t, session, res)
+		assert.Equal(t, &now, res.LastUsedAt())
+		assert.Equal(t, ptr.To("192.168.1.1"), res.LastUsedIP())
+	})
+
+	t.Run("Authenticate with a recent usage from the same ip", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
+
+		// Data
+		now := time.Now().UTC()
+		sessionPassword := "some-password"
+		session := NewFakeSession(t).
+			WithPassword(sessionPassword).
+			WithLastUsage(now.Add(-10*time.Second), "192.168.1.1").
+			Build()
+
+		// Mocks
+		storageMock.On("GetByUsernameAndPassword", mock.Anything, session.username, secret.NewText(hex.EncodeToString([]byte("some-password")))).
+			Return(session, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		// No Patch call, the last usage is recent enough.
+
+		// Run
+		res, err := service.Authenticate(ctx, session.username, secret.NewText(sessionPassword), "192.168.1.1")
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, session, res)
+	})
+
+	t.Run("Authenticate with an expired session", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
+
+		// Data
+		now := time.Now().UTC()
+		sessionPassword := "some-password"
+		session := NewFakeSession(t).
+			WithPassword(sessionPassword).
+			WithExpiration(now.Add(-time.Hour)).
+			Build()
+
+		// Mocks
+		storageMock.On("GetByUsernameAndPassword", mock.Anything, session.username, secret.NewText(hex.EncodeToString([]byte("some-password")))).
+			Return(session, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		// Run
+		res, err := service.Authenticate(ctx, session.username, secret.NewText(sessionPassword), "192.168.1.1")
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrSessionExpired)
 	})
 
 	t.Run("Delete success", func(t *testing.T) {
@@ -210,7 +615,8 @@ func TestDavSessionsService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -236,7 +642,8 @@ func TestDavSessionsService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -260,7 +667,8 @@ func TestDavSessionsService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -285,7 +693,8 @@ func TestDavSessionsService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -311,7 +720,8 @@ func TestDavSessionsService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -335,7 +745,8 @@ func TestDavSessionsService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -357,7 +768,8 @@ func TestDavSessionsService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		spacesMock := spaces.NewMockService(t)
-		service := newService(storageMock, spacesMock, tools)
+		fsMock := dfs.NewMockService(t)
+		service := newService(storageMock, spacesMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
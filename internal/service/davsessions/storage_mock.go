@@ -96,6 +96,20 @@ func (_m *mockStorage) GetByUsernameAndPassword(ctx context.Context, username st
 	return r0, r1
 }
 
+// Patch provides a mock function with given fields: ctx, sessionID, fields
+func (_m *mockStorage) Patch(ctx context.Context, sessionID uuid.UUID, fields map[string]interface{}) error {
+	ret := _m.Called(ctx, sessionID, fields)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, map[string]interface{}) error); ok {
+		r0 = rf(ctx, sessionID, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RemoveByID provides a mock function with given fields: ctx, sessionID
 func (_m *mockStorage) RemoveByID(ctx context.Context, sessionID uuid.UUID) error {
 	ret := _m.Called(ctx, sessionID)
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
@@ -17,7 +18,7 @@ const tableName = "dav_sessions"
 
 var errNotFound = errors.New("not found")
 
-var allFields = []string{"id", "username", "name", "password", "user_id", "space_id", "created_at"}
+var allFields = []string{"id", "username", "name", "password", "user_id", "space_id", "grant_id", "all_spaces", "read_only", "root_path", "expires_at", "last_used_at", "last_used_ip", "created_at"}
 
 type sqlStorage struct {
 	db sqlstorage.Querier
@@ -31,7 +32,20 @@ func (t *sqlStorage) Save(ctx context.Context, session *DavSession) error {
 	_, err := sq.
 		Insert(tableName).
 		Columns(allFields...).
-		Values(session.id, session.username, session.name, session.password, session.userID, session.spaceID, ptr.To(sqlstorage.SQLTime(session.createdAt))).
+		Values(session.id,
+			session.username,
+			session.name,
+			session.password,
+			session.userID,
+			nullableSpaceID(session),
+			session.grantID,
+			session.allSpaces,
+			session.readOnly,
+			session.rootPath,
+			nullableTime(session.expiresAt),
+			nullableTime(session.lastUsedAt),
+			session.lastUsedIP,
+			ptr.To(sqlstorage.SQLTime(session.createdAt))).
 		RunWith(t.db).
 		ExecContext(ctx)
 	if err != nil {
@@ -43,6 +57,8 @@ func (t *sqlStorage) Save(ctx context.Context, session *DavSession) error {
 
 func (t *sqlStorage) GetByID(ctx context.Context, sessionID uuid.UUID) (*DavSession, error) {
 	var res DavSession
+	var spaceID *uuid.UUID
+	var sqlExpiresAt, sqlLastUsedAt *sqlstorage.SQLTime
 	var sqlCreatedAt sqlstorage.SQLTime
 
 	err := sq.
@@ -50,7 +66,7 @@ func (t *sqlStorage) GetByID(ctx context.Context, sessionID uuid.UUID) (*DavSess
 		From(tableName).
 		Where(sq.Eq{"id": sessionID}).
 		RunWith(t.db).
-		ScanContext(ctx, &res.id, &res.username, &res.name, &res.password, &res.userID, &res.spaceID, &sqlCreatedAt)
+		ScanContext(ctx, &res.id, &res.username, &res.name, &res.password, &res.userID, &spaceID, &res.grantID, &res.allSpaces, &res.readOnly, &res.rootPath, &sqlExpiresAt, &sqlLastUsedAt, &res.lastUsedIP, &sqlCreatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, errNotFound
 	}
@@ -59,6 +75,11 @@ func (t *sqlStorage) GetByID(ctx context.Context, sessionID uuid.UUID) (*DavSess
 		return nil, fmt.Errorf("sql error: %w", err)
 	}
 
+	if spaceID != nil {
+		res.spaceID = *spaceID
+	}
+	res.expiresAt = sqlTimePtr(sqlExpiresAt)
+	res.lastUsedAt = sqlTimePtr(sqlLastUsedAt)
 	res.createdAt = sqlCreatedAt.Time()
 
 	return &res, nil
@@ -77,8 +98,24 @@ func (t *sqlStorage) RemoveByID(ctx context.Context, sessionID uuid.UUID) error
 	return nil
 }
 
+func (t *sqlStorage) Patch(ctx context.Context, sessionID uuid.UUID, fields map[string]any) error {
+	_, err := sq.
+		Update(tableName).
+		SetMap(fields).
+		Where(sq.Eq{"id": sessionID}).
+		RunWith(t.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
 func (t *sqlStorage) GetByUsernameAndPassword(ctx context.Context, username string, password secret.Text) (*DavSession, error) {
 	var res DavSession
+	var spaceID *uuid.UUID
+	var sqlExpiresAt, sqlLastUsedAt *sqlstorage.SQLTime
 	var sqlCreatedAt sqlstorage.SQLTime
 
 	err := sq.
@@ -86,7 +123,7 @@ func (t *sqlStorage) GetByUsernameAndPassword(ctx context.Context, username stri
 		From(tableName).
 		Where(sq.Eq{"username": username, "password": password.Raw()}).
 		RunWith(t.db).
-		ScanContext(ctx, &res.id, &res.username, &res.name, &res.password, &res.userID, &res.spaceID, &sqlCreatedAt)
+		ScanContext(ctx, &res.id, &res.username, &res.name, &res.password, &res.userID, &spaceID, &res.grantID, &res.allSpaces, &res.readOnly, &res.rootPath, &sqlExpiresAt, &sqlLastUsedAt, &res.lastUsedIP, &sqlCreatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, errNotFound
 	}
@@ -95,6 +132,11 @@ func (t *sqlStorage) GetByUsernameAndPassword(ctx context.Context, username stri
 		return nil, fmt.Errorf("sql error: %w", err)
 	}
 
+	if spaceID != nil {
+		res.spaceID = *spaceID
+	}
+	res.expiresAt = sqlTimePtr(sqlExpiresAt)
+	res.lastUsedAt = sqlTimePtr(sqlLastUsedAt)
 	res.createdAt = sqlCreatedAt.Time()
 
 	return &res, nil
@@ -121,13 +163,20 @@ func (s *sqlStorage) scanRows(rows *sql.Rows) ([]DavSession, error) {
 
 	for rows.Next() {
 		var res DavSession
+		var spaceID *uuid.UUID
+		var sqlExpiresAt, sqlLastUsedAt *sqlstorage.SQLTime
 		var sqlCreatedAt sqlstorage.SQLTime
 
-		err := rows.Scan(&res.id, &res.username, &res.name, &res.password, &res.userID, &res.spaceID, &sqlCreatedAt)
+		err := rows.Scan(&res.id, &res.username, &res.name, &res.password, &res.userID, &spaceID, &res.grantID, &res.allSpaces, &res.readOnly, &res.rootPath, &sqlExpiresAt, &sqlLastUsedAt, &res.lastUsedIP, &sqlCreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan a row: %w", err)
 		}
 
+		if spaceID != nil {
+			res.spaceID = *spaceID
+		}
+		res.expiresAt = sqlTimePtr(sqlExpiresAt)
+		res.lastUsedAt = sqlTimePtr(sqlLastUsedAt)
 		res.createdAt = sqlCreatedAt.Time()
 		inodes = append(inodes, res)
 	}
@@ -138,3 +187,29 @@ func (s *sqlStorage) scanRows(rows *sql.Rows) ([]DavSession, error) {
 
 	return inodes, nil
 }
+
+// nullableSpaceID returns nil for the sessions without any space. An empty
+// string would break the foreign key.
+func nullableSpaceID(session *DavSession) *uuid.UUID {
+	if session.spaceID == "" {
+		return nil
+	}
+
+	return &session.spaceID
+}
+
+func nullableTime(t *time.Time) *sqlstorage.SQLTime {
+	if t == nil {
+		return nil
+	}
+
+	return ptr.To(sqlstorage.SQLTime(*t))
+}
+
+func sqlTimePtr(t *sqlstorage.SQLTime) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	return ptr.To(t.Time())
+}
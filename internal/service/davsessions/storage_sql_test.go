@@ -3,11 +3,13 @@ package davsessions
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
 	"github.com/theduckcompany/duckcloud/internal/tools/secret"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
@@ -22,7 +24,7 @@ func TestDavSessionSqlStorage(t *testing.T) {
 
 	// Data
 	user := users.NewFakeUser(t).WithAdminRole().BuildAndStore(ctx, db)
-	space := spaces.NewFakeSpace(t).WithOwners(*user).BuildAndStore(ctx, db)
+	space := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
 
 	sessionPassword := "some-password"
 	session := NewFakeSession(t).
@@ -93,6 +95,54 @@ func TestDavSessionSqlStorage(t *testing.T) {
 		assert.Equal(t, []DavSession{}, res)
 	})
 
+	t.Run("Save and GetByID with all the spaces", func(t *testing.T) {
+		allSpacesSession := NewFakeSession(t).
+			CreatedBy(user).
+			WithAllSpaces().
+			Build()
+
+		err := store.Save(ctx, allSpacesSession)
+		require.NoError(t, err)
+
+		res, err := store.GetByID(ctx, allSpacesSession.ID())
+		require.NoError(t, err)
+		assert.Equal(t, allSpacesSession, res)
+	})
+
+	t.Run("Save and GetByID with a scope", func(t *testing.T) {
+		scopedSession := NewFakeSession(t).
+			CreatedBy(user).
+			WithSpace(space).
+			WithReadOnly().
+			WithRootPath("/Movies").
+			WithExpiration(time.Date(2030, time.January, 2, 0, 0, 0, 0, time.UTC)).
+			Build()
+
+		err := store.Save(ctx, scopedSession)
+		require.NoError(t, err)
+
+		res, err := store.GetByID(ctx, scopedSession.ID())
+		require.NoError(t, err)
+		assert.Equal(t, scopedSession, res)
+	})
+
+	t.Run("Patch success", func(t *testing.T) {
+		lastUsedAt := time.Date(2024, time.March, 4, 10, 11, 12, 0, time.UTC)
+
+		// Run
+		err := store.Patch(ctx, session.ID(), map[string]any{
+			"last_used_at": ptr.To(sqlstorage.SQLTime(lastUsedAt)),
+			"last_used_ip": "192.168.1.1",
+		})
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetByID(ctx, session.ID())
+		require.NoError(t, err)
+		assert.Equal(t, &lastUsedAt, res.LastUsedAt())
+		assert.Equal(t, ptr.To("192.168.1.1"), res.LastUsedIP())
+	})
+
 	t.Run("RemoveByID success", func(t *testing.T) {
 		// Run
 		err := store.RemoveByID(context.Background(), session.ID())
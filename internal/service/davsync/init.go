@@ -0,0 +1,32 @@
+package davsync
+
+import (
+	"context"
+
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/runner"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+	"go.uber.org/fx"
+)
+
+//go:generate mockery --name Service
+type Service interface {
+	GetToken(ctx context.Context, collectionID uuid.UUID) (int64, error)
+	GetChangesSince(ctx context.Context, collectionID uuid.UUID, token int64) ([]Change, error)
+}
+
+type Result struct {
+	fx.Out
+	Service          Service
+	DavSyncPruneTask runner.TaskRunner `group:"tasks"`
+}
+
+func Init(tools tools.Tools, db sqlstorage.Querier) Result {
+	storage := newSqlStorage(db)
+
+	return Result{
+		Service:          newService(storage),
+		DavSyncPruneTask: NewDavSyncPruneTaskRunner(storage, tools),
+	}
+}
@@ -0,0 +1,30 @@
+package davsync
+
+import (
+	"time"
+
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+// ChangesRetention is how long the changes are kept. A client syncing less
+// often than that gets a valid-sync-token error and must resync the whole
+// collection.
+const ChangesRetention = 30 * 24 * time.Hour
+
+// Change is the creation, the modification or the deletion of a member of a
+// synchronized collection. The tokens are increasing so all the changes
+// made after a given token can be returned to a sync-collection report
+// (RFC 6578).
+type Change struct {
+	changedAt    time.Time
+	collectionID uuid.UUID
+	name         string
+	token        int64
+	deleted      bool
+}
+
+func (c Change) Token() int64            { return c.token }
+func (c Change) CollectionID() uuid.UUID { return c.collectionID }
+func (c Change) Name() string            { return c.name }
+func (c Change) IsDeleted() bool         { return c.deleted }
+func (c Change) ChangedAt() time.Time    { return c.changedAt }
@@ -0,0 +1,54 @@
+package davsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+type FakeChangeBuilder struct {
+	t      *testing.T
+	change *Change
+}
+
+// NewFakeChange builds the modification of a random member of the given
+// collection.
+func NewFakeChange(t *testing.T, collectionID uuid.UUID) *FakeChangeBuilder {
+	t.Helper()
+
+	changedAt := gofakeit.DateRange(time.Now().Add(-time.Hour*1000), time.Now()).UTC()
+
+	return &FakeChangeBuilder{
+		t: t,
+		change: &Change{
+			collectionID: collectionID,
+			name:         gofakeit.UUID() + ".vcf",
+			deleted:      false,
+			changedAt:    changedAt,
+		},
+	}
+}
+
+func (f *FakeChangeBuilder) WithName(name string) *FakeChangeBuilder {
+	f.change.name = name
+
+	return f
+}
+
+func (f *FakeChangeBuilder) WithToken(token int64) *FakeChangeBuilder {
+	f.change.token = token
+
+	return f
+}
+
+func (f *FakeChangeBuilder) Deleted() *FakeChangeBuilder {
+	f.change.deleted = true
+
+	return f
+}
+
+func (f *FakeChangeBuilder) Build() *Change {
+	return f.change
+}
@@ -0,0 +1,92 @@
+package davsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+var ErrInvalidToken = errors.New("invalid sync token")
+
+//go:generate mockery --name storage
+type storage interface {
+	GetLastToken(ctx context.Context, collectionID uuid.UUID) (int64, error)
+	GetFirstToken(ctx context.Context) (int64, error)
+	GetAllAfter(ctx context.Context, collectionID uuid.UUID, token int64) ([]Change, error)
+	DeleteAllBefore(ctx context.Context, date time.Time) error
+}
+
+type service struct {
+	storage storage
+}
+
+func newService(storage storage) *service {
+	return &service{storage}
+}
+
+// GetToken returns the token of the latest change of the collection. It
+// returns 0 for a collection never modified.
+//
+// The pruned changes are considered as known by the clients so the token of a
+// collection never goes back below the pruned ones.
+func (s *service) GetToken(ctx context.Context, collectionID uuid.UUID) (int64, error) {
+	token, err := s.storage.GetLastToken(ctx, collectionID)
+	if err != nil {
+		return 0, errs.Internal(fmt.Errorf("failed to GetLastToken: %w", err))
+	}
+
+	firstToken, err := s.storage.GetFirstToken(ctx)
+	if err != nil {
+		return 0, errs.Internal(fmt.Errorf("failed to GetFirstToken: %w", err))
+	}
+
+	return max(token, firstToken-1), nil
+}
+
+// GetChangesSince returns the changes made to the collection after the given
+// token. Only the latest change of each member is returned, ordered by token.
+//
+// It returns ErrInvalidToken if the token is in the future or if the changes
+// made after it have been pruned.
+func (s *service) GetChangesSince(ctx context.Context, collectionID uuid.UUID, token int64) ([]Change, error) {
+	lastToken, err := s.storage.GetLastToken(ctx, collectionID)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetLastToken: %w", err))
+	}
+
+	firstToken, err := s.storage.GetFirstToken(ctx)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetFirstToken: %w", err))
+	}
+
+	if token > max(lastToken, firstToken-1) {
+		return nil, errs.BadRequest(ErrInvalidToken, "token from the future")
+	}
+
+	if token < firstToken-1 {
+		return nil, errs.BadRequest(ErrInvalidToken, "the changes since this token are pruned")
+	}
+
+	changes, err := s.storage.GetAllAfter(ctx, collectionID, token)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllAfter: %w", err))
+	}
+
+	latest := make(map[string]int, len(changes))
+	for i, change := range changes {
+		latest[change.name] = i
+	}
+
+	res := make([]Change, 0, len(latest))
+	for i, change := range changes {
+		if latest[change.name] == i {
+			res = append(res, change)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,250 @@
+package davsync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func Test_DavSyncService(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	collectionID := uuid.UUID("4f5c9ae9-ae13-4a1e-a4e7-2a2bb5b7f0b4")
+
+	t.Run("GetToken success", func(t *testing.T) {
+		t.Parallel()
+
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock)
+
+		// Mocks
+		storageMock.On("GetLastToken", mock.Anything, collectionID).Return(int64(42), nil).Once()
+		storageMock.On("GetFirstToken", mock.Anything).Return(int64(1), nil).Once()
+
+		// Run
+		res, err := svc.GetToken(ctx, collectionID)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), res)
+	})
+
+	t.Run("GetToken without any change", func(t *testing.T) {
+		t.Parallel()
+
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock)
+
+		// Mocks
+		storageMock.On("GetLastToken", mock.Anything, collectionID).Return(int64(0), nil).Once()
+		storageMock.On("GetFirstToken", mock.Anything).Return(int64(0), nil).Once()
+
+		// Run
+		res, err := svc.GetToken(ctx, collectionID)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), res)
+	})
+
+	t.Run("GetToken with all the collection changes pruned", func(t *testing.T) {
+		t.Parallel()
+
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock)
+
+		// Mocks
+		storageMock.On("GetLastToken", mock.Anything, collectionID).Return(int64(0), nil).Once()
+		storageMock.On("GetFirstToken", mock.Anything).Return(int64(12), nil).Once()
+
+		// Run
+		res, err := svc.GetToken(ctx, collectionID)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, int64(11), res)
+	})
+
+	t.Run("GetToken with a GetLastToken error", func(t *testing.T) {
+		t.Parallel()
+
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock)
+
+		// Mocks
+		storageMock.On("GetLastToken", mock.Anything, collectionID).Return(int64(0), fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := svc.GetToken(ctx, collectionID)
+
+		// Asserts
+		assert.Equal(t, int64(0), res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("GetToken with a GetFirstToken error", func(t *testing.T) {
+		t.Parallel()
+
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock)
+
+		// Mocks
+		storageMock.On("GetLastToken", mock.Anything, collectionID).Return(int64(42), nil).Once()
+		storageMock.On("GetFirstToken", mock.Anything).Return(int64(0), fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := svc.GetToken(ctx, collectionID)
+
+		// Asserts
+		assert.Equal(t, int64(0), res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("GetChangesSince keeps only the latest change of each member", func(t *testing.T) {
+		t.Parallel()
+
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock)
+
+		// Data
+		creation := NewFakeChange(t, collectionID).WithName("foo.vcf").WithToken(2).Build()
+		other := NewFakeChange(t, collectionID).WithName("bar.vcf").WithToken(3).Build()
+		deletion := NewFakeChange(t, collectionID).WithName("foo.vcf").WithToken(4).Deleted().Build()
+
+		// Mocks
+		storageMock.On("GetLastToken", mock.Anything, collectionID).Return(int64(4), nil).Once()
+		storageMock.On("GetFirstToken", mock.Anything).Return(int64(1), nil).Once()
+		storageMock.On("GetAllAfter", mock.Anything, collectionID, int64(1)).
+			Return([]Change{*creation, *other, *deletion}, nil).Once()
+
+		// Run
+		res, err := svc.GetChangesSince(ctx, collectionID, 1)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []Change{*other, *deletion}, res)
+	})
+
+	t.Run("GetChangesSince with the token just before the pruned changes", func(t *testing.T) {
+		t.Parallel()
+
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock)
+
+		// Mocks
+		storageMock.On("GetLastToken", mock.Anything, collectionID).Return(int64(0), nil).Once()
+		storageMock.On("GetFirstToken", mock.Anything).Return(int64(12), nil).Once()
+		storageMock.On("GetAllAfter", mock.Anything, collectionID, int64(11)).Return([]Change{}, nil).Once()
+
+		// Run
+		res, err := svc.GetChangesSince(ctx, collectionID, 11)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("GetChangesSince with a token from the future", func(t *testing.T) {
+		t.Parallel()
+
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock)
+
+		// Mocks
+		storageMock.On("GetLastToken", mock.Anything, collectionID).Return(int64(4), nil).Once()
+		storageMock.On("GetFirstToken", mock.Anything).Return(int64(1), nil).Once()
+
+		// Run
+		res, err := svc.GetChangesSince(ctx, collectionID, 5)
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("GetChangesSince with a pruned token", func(t *testing.T) {
+		t.Parallel()
+
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock)
+
+		// Mocks
+		storageMock.On("GetLastToken", mock.Anything, collectionID).Return(int64(42), nil).Once()
+		storageMock.On("GetFirstToken", mock.Anything).Return(int64(12), nil).Once()
+
+		// Run
+		res, err := svc.GetChangesSince(ctx, collectionID, 10)
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("GetChangesSince with a GetLastToken error", func(t *testing.T) {
+		t.Parallel()
+
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock)
+
+		// Mocks
+		storageMock.On("GetLastToken", mock.Anything, collectionID).Return(int64(0), fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := svc.GetChangesSince(ctx, collectionID, 1)
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("GetChangesSince with a GetFirstToken error", func(t *testing.T) {
+		t.Parallel()
+
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock)
+
+		// Mocks
+		storageMock.On("GetLastToken", mock.Anything, collectionID).Return(int64(4), nil).Once()
+		storageMock.On("GetFirstToken", mock.Anything).Return(int64(0), fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := svc.GetChangesSince(ctx, collectionID, 1)
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("GetChangesSince with a GetAllAfter error", func(t *testing.T) {
+		t.Parallel()
+
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock)
+
+		// Mocks
+		storageMock.On("GetLastToken", mock.Anything, collectionID).Return(int64(4), nil).Once()
+		storageMock.On("GetFirstToken", mock.Anything).Return(int64(1), nil).Once()
+		storageMock.On("GetAllAfter", mock.Anything, collectionID, int64(1)).Return(nil, fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := svc.GetChangesSince(ctx, collectionID, 1)
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+}
@@ -0,0 +1,130 @@
+package davsync
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const tableName = "dav_sync_changes"
+
+var allFields = []string{"token", "collection_id", "name", "deleted", "changed_at"}
+
+type sqlStorage struct {
+	db sqlstorage.Querier
+}
+
+func newSqlStorage(db sqlstorage.Querier) *sqlStorage {
+	return &sqlStorage{db}
+}
+
+// Save inserts the change and returns its token. The changes are usually
+// recorded by the fs_inodes triggers.
+func (s *sqlStorage) Save(ctx context.Context, change *Change) (int64, error) {
+	res, err := sq.
+		Insert(tableName).
+		Columns("collection_id", "name", "deleted", "changed_at").
+		Values(change.collectionID,
+			change.name,
+			change.deleted,
+			ptr.To(sqlstorage.SQLTime(change.changedAt))).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("sql error: %w", err)
+	}
+
+	token, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the token: %w", err)
+	}
+
+	return token, nil
+}
+
+func (s *sqlStorage) GetLastToken(ctx context.Context, collectionID uuid.UUID) (int64, error) {
+	var token sql.NullInt64
+
+	err := sq.
+		Select("MAX(token)").
+		From(tableName).
+		Where(sq.Eq{"collection_id": collectionID}).
+		RunWith(s.db).
+		ScanContext(ctx, &token)
+	if err != nil {
+		return 0, fmt.Errorf("sql error: %w", err)
+	}
+
+	return token.Int64, nil
+}
+
+// GetFirstToken returns the oldest token not pruned yet, whatever the
+// collection. It returns 0 if there is no change.
+func (s *sqlStorage) GetFirstToken(ctx context.Context) (int64, error) {
+	var token sql.NullInt64
+
+	err := sq.
+		Select("MIN(token)").
+		From(tableName).
+		RunWith(s.db).
+		ScanContext(ctx, &token)
+	if err != nil {
+		return 0, fmt.Errorf("sql error: %w", err)
+	}
+
+	return token.Int64, nil
+}
+
+// DeleteAllBefore removes all the changes made before the given date. The
+// latest change is always kept so its token is never reused.
+func (s *sqlStorage) DeleteAllBefore(ctx context.Context, date time.Time) error {
+	_, err := sq.
+		Delete(tableName).
+		Where(sq.Lt{"changed_at": ptr.To(sqlstorage.SQLTime(date))}).
+		Where(sq.Expr("token < (SELECT MAX(token) FROM " + tableName + ")")).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) GetAllAfter(ctx context.Context, collectionID uuid.UUID, token int64) ([]Change, error) {
+	rows, err := sq.
+		Select(allFields...).
+		From(tableName).
+		Where(sq.Eq{"collection_id": collectionID}).
+		Where(sq.Gt{"token": token}).
+		OrderBy("token").
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	res := []Change{}
+	for rows.Next() {
+		var change Change
+		var sqlChangedAt sqlstorage.SQLTime
+
+		err = rows.Scan(&change.token, &change.collectionID, &change.name, &change.deleted, &sqlChangedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		change.changedAt = sqlChangedAt.Time()
+		res = append(res, change)
+	}
+
+	return res, rows.Err()
+}
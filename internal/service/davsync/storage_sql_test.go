@@ -0,0 +1,203 @@
+package davsync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func TestChangeSqlstore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db := sqlstorage.NewTestStorage(t)
+	store := newSqlStorage(db)
+
+	collectionID := uuid.NewProvider().New()
+	otherCollectionID := uuid.NewProvider().New()
+	change := NewFakeChange(t, collectionID).Build()
+	otherChange := NewFakeChange(t, otherCollectionID).Build()
+	deletion := NewFakeChange(t, collectionID).WithName(change.Name()).Deleted().Build()
+
+	t.Run("GetLastToken without any change", func(t *testing.T) {
+		// Run
+		res, err := store.GetLastToken(ctx, collectionID)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), res)
+	})
+
+	t.Run("Save success", func(t *testing.T) {
+		// Run
+		token, err := store.Save(ctx, change)
+		require.NoError(t, err)
+		change.token = token
+
+		otherToken, err := store.Save(ctx, otherChange)
+		require.NoError(t, err)
+		otherChange.token = otherToken
+
+		deletionToken, err := store.Save(ctx, deletion)
+		require.NoError(t, err)
+		deletion.token = deletionToken
+
+		// Asserts
+		assert.Greater(t, otherToken, token)
+		assert.Greater(t, deletionToken, otherToken)
+	})
+
+	t.Run("GetLastToken success", func(t *testing.T) {
+		// Run
+		res, err := store.GetLastToken(ctx, collectionID)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, deletion.Token(), res)
+	})
+
+	t.Run("GetAllAfter success", func(t *testing.T) {
+		// Run
+		res, err := store.GetAllAfter(ctx, collectionID, 0)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []Change{*change, *deletion}, res)
+	})
+
+	t.Run("GetAllAfter with a token", func(t *testing.T) {
+		// Run
+		res, err := store.GetAllAfter(ctx, collectionID, change.Token())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []Change{*deletion}, res)
+	})
+
+	t.Run("GetFirstToken success", func(t *testing.T) {
+		// Run
+		res, err := store.GetFirstToken(ctx)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, change.Token(), res)
+	})
+
+	t.Run("DeleteAllBefore success", func(t *testing.T) {
+		// Run
+		err := store.DeleteAllBefore(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetAllAfter(ctx, collectionID, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []Change{*deletion}, res)
+
+		res, err = store.GetAllAfter(ctx, otherCollectionID, 0)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("GetFirstToken after a DeleteAllBefore keeps the latest token", func(t *testing.T) {
+		// Run
+		res, err := store.GetFirstToken(ctx)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, deletion.Token(), res)
+	})
+}
+
+func TestChangeSqlstoreTriggers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db := sqlstorage.NewTestStorage(t)
+	store := newSqlStorage(db)
+
+	user := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	space := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
+	file := files.NewFakeFile(t).BuildAndStore(ctx, db)
+	rootDir := dfs.NewFakeINode(t).WithSpace(space).IsRootDirectory().CreatedBy(user).BuildAndStore(ctx, db)
+	otherDir := dfs.NewFakeINode(t).WithSpace(space).WithParent(rootDir).IsDirectory().CreatedBy(user).BuildAndStore(ctx, db)
+
+	collectionID := rootDir.ID()
+
+	t.Run("a directory creation is not recorded", func(t *testing.T) {
+		res, err := store.GetAllAfter(ctx, collectionID, 0)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	inode := dfs.NewFakeINode(t).WithSpace(space).WithParent(rootDir).WithName("foo.vcf").WithFile(file).CreatedBy(user).BuildAndStore(ctx, db)
+
+	t.Run("a file creation is recorded", func(t *testing.T) {
+		res, err := store.GetAllAfter(ctx, collectionID, 0)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		assert.Equal(t, "foo.vcf", res[0].Name())
+		assert.False(t, res[0].IsDeleted())
+	})
+
+	t.Run("a rename is recorded as a deletion and a creation", func(t *testing.T) {
+		lastToken, err := store.GetLastToken(ctx, collectionID)
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(ctx, "UPDATE fs_inodes SET name = 'bar.vcf' WHERE id = ?", inode.ID())
+		require.NoError(t, err)
+
+		res, err := store.GetAllAfter(ctx, collectionID, lastToken)
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		assert.Equal(t, "foo.vcf", res[0].Name())
+		assert.True(t, res[0].IsDeleted())
+		assert.Equal(t, "bar.vcf", res[1].Name())
+		assert.False(t, res[1].IsDeleted())
+	})
+
+	t.Run("a move is recorded in both collections", func(t *testing.T) {
+		lastToken, err := store.GetLastToken(ctx, collectionID)
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(ctx, "UPDATE fs_inodes SET parent = ? WHERE id = ?", otherDir.ID(), inode.ID())
+		require.NoError(t, err)
+
+		res, err := store.GetAllAfter(ctx, collectionID, lastToken)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		assert.Equal(t, "bar.vcf", res[0].Name())
+		assert.True(t, res[0].IsDeleted())
+
+		res, err = store.GetAllAfter(ctx, otherDir.ID(), lastToken)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		assert.Equal(t, "bar.vcf", res[0].Name())
+		assert.False(t, res[0].IsDeleted())
+	})
+
+	t.Run("a trashed file is recorded as a deletion", func(t *testing.T) {
+		lastToken, err := store.GetLastToken(ctx, otherDir.ID())
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(ctx, "UPDATE fs_inodes SET deleted_at = ? WHERE id = ?", ptr.To(sqlstorage.SQLTime(time.Now())), inode.ID())
+		require.NoError(t, err)
+
+		res, err := store.GetAllAfter(ctx, otherDir.ID(), lastToken)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		assert.Equal(t, "bar.vcf", res[0].Name())
+		assert.True(t, res[0].IsDeleted())
+	})
+}
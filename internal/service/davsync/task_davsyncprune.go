@@ -0,0 +1,39 @@
+package davsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/clock"
+)
+
+// DavSyncPruneTaskRunner removes the changes older than ChangesRetention.
+type DavSyncPruneTaskRunner struct {
+	storage storage
+	clock   clock.Clock
+}
+
+func NewDavSyncPruneTaskRunner(storage storage, tools tools.Tools) *DavSyncPruneTaskRunner {
+	return &DavSyncPruneTaskRunner{
+		storage: storage,
+		clock:   tools.Clock(),
+	}
+}
+
+func (r *DavSyncPruneTaskRunner) Name() string { return "dav-sync-prune" }
+
+func (r *DavSyncPruneTaskRunner) Run(ctx context.Context, rawArgs json.RawMessage) error {
+	return r.RunArgs(ctx, &scheduler.DavSyncPruneArgs{})
+}
+
+func (r *DavSyncPruneTaskRunner) RunArgs(ctx context.Context, args *scheduler.DavSyncPruneArgs) error {
+	err := r.storage.DeleteAllBefore(ctx, r.clock.Now().Add(-ChangesRetention))
+	if err != nil {
+		return fmt.Errorf("failed to DeleteAllBefore: %w", err)
+	}
+
+	return nil
+}
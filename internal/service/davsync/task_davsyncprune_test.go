@@ -0,0 +1,48 @@
+package davsync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+)
+
+func TestDavSyncPruneTask(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	t.Run("Name", func(t *testing.T) {
+		runner := NewDavSyncPruneTaskRunner(nil, tools.NewMock(t))
+		assert.Equal(t, "dav-sync-prune", runner.Name())
+	})
+
+	t.Run("RunArgs success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		runner := NewDavSyncPruneTaskRunner(storageMock, tools)
+
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("DeleteAllBefore", mock.Anything, now.Add(-ChangesRetention)).Return(nil).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.DavSyncPruneArgs{})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with a DeleteAllBefore error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		runner := NewDavSyncPruneTaskRunner(storageMock, tools)
+
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("DeleteAllBefore", mock.Anything, now.Add(-ChangesRetention)).Return(fmt.Errorf("some-error")).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.DavSyncPruneArgs{})
+		require.ErrorContains(t, err, "some-error")
+	})
+}
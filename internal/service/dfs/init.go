@@ -3,6 +3,7 @@ package dfs
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/theduckcompany/duckcloud/internal/service/files"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
@@ -12,6 +13,7 @@ import (
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 	"go.uber.org/fx"
 )
 
@@ -21,22 +23,61 @@ type Service interface {
 	CreateFS(ctx context.Context, user *users.User, space *spaces.Space) (*INode, error)
 	CreateDir(ctx context.Context, cmd *CreateDirCmd) (*INode, error)
 	ListDir(ctx context.Context, cmd *PathCmd, paginateCmd *sqlstorage.PaginateCmd) ([]INode, error)
-	Remove(ctx context.Context, cmd *PathCmd) error
-	Rename(ctx context.Context, inode *INode, newName string) (*INode, error)
+	ListChildren(ctx context.Context, dir *INode, paginateCmd *sqlstorage.PaginateCmd) ([]INode, error)
+	Remove(ctx context.Context, user *users.User, cmd *PathCmd) error
+	ListDeleted(ctx context.Context, space *spaces.Space, cmd *sqlstorage.PaginateCmd) ([]INode, error)
+	GetOriginalPath(ctx context.Context, inode *INode) (string, error)
+	GetPathByID(ctx context.Context, space *spaces.Space, inodeID uuid.UUID) (*PathCmd, error)
+	Restore(ctx context.Context, cmd *RestoreCmd) (*INode, error)
+	EmptyTrash(ctx context.Context, user *users.User, space *spaces.Space) error
+	Rename(ctx context.Context, user *users.User, inode *INode, newName string) (*INode, error)
+	SetModifiedAt(ctx context.Context, user *users.User, inode *INode, modifiedAt time.Time) (*INode, error)
 	Move(ctx context.Context, cmd *MoveCmd) error
+	Copy(ctx context.Context, cmd *CopyCmd) error
 	Get(ctx context.Context, cmd *PathCmd) (*INode, error)
 	Upload(ctx context.Context, cmd *UploadCmd) error
 	Download(ctx context.Context, cmd *PathCmd) (io.ReadSeekCloser, error)
+	ListVersions(ctx context.Context, inode *INode) ([]FileVersion, error)
+	GetVersion(ctx context.Context, inode *INode, versionID uuid.UUID) (*FileVersion, error)
+	DownloadVersion(ctx context.Context, version *FileVersion) (io.ReadSeekCloser, error)
+	RestoreVersion(ctx context.Context, cmd *RestoreVersionCmd) (*INode, error)
+	GetUserUsage(ctx context.Context, user *users.User) (uint64, error)
+	GetAvailableSpace(ctx context.Context, user *users.User, space *spaces.Space) (uint64, error)
+	Search(ctx context.Context, cmd *SearchCmd, paginateCmd *sqlstorage.PaginateCmd) ([]SearchResult, error)
+	SearchTree(ctx context.Context, cmd *SearchTreeCmd, paginateCmd *sqlstorage.PaginateCmd) ([]SearchResult, error)
+	CreateGrant(ctx context.Context, cmd *CreateGrantCmd) (*Grant, error)
+	GetUserGrants(ctx context.Context, user *users.User) ([]Grant, error)
+	GetUserGrant(ctx context.Context, userID, grantID uuid.UUID) (*Grant, error)
+	GetINodeGrants(ctx context.Context, user *users.User, cmd *PathCmd) ([]Grant, error)
+	GetGrantPath(ctx context.Context, grant *Grant) (*PathCmd, error)
+	DeleteGrant(ctx context.Context, user *users.User, grantID uuid.UUID) error
+	DeleteAllUserGrants(ctx context.Context, userID uuid.UUID) error
+	GetProps(ctx context.Context, inode *INode) ([]Property, error)
+	GetAllProps(ctx context.Context, inodes []INode) (map[uuid.UUID][]Property, error)
+	PatchProps(ctx context.Context, cmd *PatchPropsCmd) error
 	removeINode(ctx context.Context, inode *INode) error
 }
 
+// SpaceMover is implemented by the services keeping some data attached to an
+// inode and its space. The fs-move task calls it for each inode moved into an
+// other space.
+//
+//go:generate mockery --name SpaceMover
+type SpaceMover interface {
+	MoveToSpace(ctx context.Context, inodeID, spaceID uuid.UUID) error
+}
+
 type Result struct {
 	fx.Out
 	Service                      Service
 	FSGCTask                     runner.TaskRunner `group:"tasks"`
+	FSEmptyTrashTask             runner.TaskRunner `group:"tasks"`
 	FSMoveTask                   runner.TaskRunner `group:"tasks"`
+	FSCopyTask                   runner.TaskRunner `group:"tasks"`
 	FSRefreshSizeTask            runner.TaskRunner `group:"tasks"`
 	FSRemoveDuplicateFilesRunner runner.TaskRunner `group:"tasks"`
+	FSPruneVersionsTask          runner.TaskRunner `group:"tasks"`
+	FSIndexContentTask           runner.TaskRunner `group:"tasks"`
 }
 
 func Init(db sqlstorage.Querier,
@@ -46,17 +87,23 @@ func Init(db sqlstorage.Querier,
 	users users.Service,
 	tools tools.Tools,
 	stats stats.Service,
+	spaceMover SpaceMover,
 ) (Result,
 	error,
 ) {
 	storage := newSqlStorage(db)
 	svc := newService(storage, files, spaces, scheduler, tools)
+	gcTask := NewFSGGCTaskRunner(storage, files, spaces, scheduler, tools)
 
 	return Result{
 		Service:                      svc,
-		FSGCTask:                     NewFSGGCTaskRunner(storage, files, spaces, scheduler, tools),
-		FSMoveTask:                   NewFSMoveTaskRunner(svc, storage, spaces, users, scheduler),
+		FSGCTask:                     gcTask,
+		FSEmptyTrashTask:             NewFSEmptyTrashTaskRunner(storage, gcTask),
+		FSMoveTask:                   NewFSMoveTaskRunner(svc, storage, spaces, users, scheduler, spaceMover),
+		FSCopyTask:                   NewFSCopyTaskRunner(svc, storage, spaces, users, scheduler, tools),
 		FSRefreshSizeTask:            NewFSRefreshSizeTaskRunner(storage, files, stats),
 		FSRemoveDuplicateFilesRunner: NewFSRemoveDuplicateFileRunner(storage, files, scheduler),
+		FSPruneVersionsTask:          NewFSPruneVersionsTaskRunner(storage, spaces, gcTask, tools),
+		FSIndexContentTask:           NewFSIndexContentTaskRunner(storage, files),
 	}, nil
 }
@@ -11,10 +11,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
 	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
 	"github.com/theduckcompany/duckcloud/internal/tools/startutils"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
 func Test_DFS_Integration(t *testing.T) {
@@ -24,11 +28,11 @@ func Test_DFS_Integration(t *testing.T) {
 
 	var rootFS *dfs.INode
 
-	spaces, err := serv.SpacesSvc.GetAllUserSpaces(ctx, serv.User.ID(), nil)
+	userSpaces, err := serv.SpacesSvc.GetAllUserSpaces(ctx, serv.User.ID(), nil)
 	require.NoError(t, err)
-	require.Len(t, spaces, 1)
+	require.Len(t, userSpaces, 1)
 
-	space := spaces[0]
+	space := userSpaces[0]
 
 	t.Run("Get the rootFS success", func(t *testing.T) {
 		var err error
@@ -321,7 +325,7 @@ func Test_DFS_Integration(t *testing.T) {
 		})
 
 		t.Run("The first replicate is deleted, the second still have the file", func(t *testing.T) {
-			err := serv.DFSSvc.Remove(ctx, dfs.NewPathCmd(&space, "/Duplicate/todo.txt"))
+			err := serv.DFSSvc.Remove(ctx, serv.User, dfs.NewPathCmd(&space, "/Duplicate/todo.txt"))
 			require.NoError(t, err)
 
 			err = serv.RunnerSvc.Run(ctx)
@@ -337,7 +341,7 @@ func Test_DFS_Integration(t *testing.T) {
 		})
 
 		t.Run("Delete the directory", func(t *testing.T) {
-			err := serv.DFSSvc.Remove(ctx, dfs.NewPathCmd(&space, "/Duplicate"))
+			err := serv.DFSSvc.Remove(ctx, serv.User, dfs.NewPathCmd(&space, "/Duplicate"))
 			require.NoError(t, err)
 
 			err = serv.RunnerSvc.Run(ctx)
@@ -368,7 +372,7 @@ func Test_DFS_Integration(t *testing.T) {
 			file, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/rename-name-taken/foo.txt"))
 			require.NoError(t, err)
 
-			res, err := serv.DFSSvc.Rename(ctx, file, "foo2.txt")
+			res, err := serv.DFSSvc.Rename(ctx, serv.User, file, "foo2.txt")
 			require.NoError(t, err)
 
 			require.Equal(t, "foo2.txt", res.Name())
@@ -388,14 +392,14 @@ func Test_DFS_Integration(t *testing.T) {
 			file, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/rename-name-taken/foo2.txt"))
 			require.NoError(t, err)
 
-			res, err := serv.DFSSvc.Rename(ctx, file, "bar.txt")
+			res, err := serv.DFSSvc.Rename(ctx, serv.User, file, "bar.txt")
 			require.NoError(t, err)
 
 			require.Equal(t, "bar (1).txt", res.Name())
 		})
 
 		t.Run("Destroy", func(t *testing.T) {
-			err := serv.DFSSvc.Remove(ctx, dfs.NewPathCmd(&space, "/rename-name-taken"))
+			err := serv.DFSSvc.Remove(ctx, serv.User, dfs.NewPathCmd(&space, "/rename-name-taken"))
 			require.NoError(t, err)
 
 			err = serv.RunnerSvc.Run(ctx)
@@ -433,4 +437,684 @@ func Test_DFS_Integration(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, expected, res)
 	})
+
+	t.Run("Trash", func(t *testing.T) {
+		var trashedFile *dfs.INode
+
+		t.Run("Setup", func(t *testing.T) {
+			err := serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/trash-dir/foo.txt"),
+				Content:    http.NoBody,
+				UploadedBy: serv.User,
+			})
+			require.ErrorIs(t, err, errs.ErrNotFound)
+
+			_, err = serv.DFSSvc.CreateDir(ctx, &dfs.CreateDirCmd{
+				Path:      dfs.NewPathCmd(&space, "/trash-dir"),
+				CreatedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/trash-dir/foo.txt"),
+				Content:    http.NoBody,
+				UploadedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+		})
+
+		t.Run("Remove the file and its parent", func(t *testing.T) {
+			err := serv.DFSSvc.Remove(ctx, serv.User, dfs.NewPathCmd(&space, "/trash-dir/foo.txt"))
+			require.NoError(t, err)
+
+			err = serv.DFSSvc.Remove(ctx, serv.User, dfs.NewPathCmd(&space, "/trash-dir"))
+			require.NoError(t, err)
+
+			// The gc must not purge the trash before the end of the retention.
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+		})
+
+		t.Run("ListDeleted contains the removed file", func(t *testing.T) {
+			res, err := serv.DFSSvc.ListDeleted(ctx, &space, nil)
+			require.NoError(t, err)
+
+			for _, inode := range res {
+				if inode.Name() == "foo.txt" {
+					trashedFile = &inode
+				}
+			}
+
+			require.NotNil(t, trashedFile)
+
+			path, err := serv.DFSSvc.GetOriginalPath(ctx, trashedFile)
+			require.NoError(t, err)
+			assert.Equal(t, "/trash-dir", path)
+		})
+
+		t.Run("Restore recreates the missing parents", func(t *testing.T) {
+			_, err := serv.DFSSvc.Restore(ctx, &dfs.RestoreCmd{
+				Space:      &space,
+				INodeID:    trashedFile.ID(),
+				RestoredBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			res, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/trash-dir/foo.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, trashedFile.ID(), res.ID())
+		})
+
+		t.Run("EmptyTrash purges all the deleted inodes", func(t *testing.T) {
+			err := serv.DFSSvc.EmptyTrash(ctx, serv.User, &space)
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+
+			res, err := serv.DFSSvc.ListDeleted(ctx, &space, nil)
+			require.NoError(t, err)
+			assert.Empty(t, res)
+		})
+	})
+
+	t.Run("Versions", func(t *testing.T) {
+		var file *dfs.INode
+		var versions []dfs.FileVersion
+
+		t.Run("Upload the same file twice", func(t *testing.T) {
+			for _, content := range []string{"first content", "second content"} {
+				err := serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+					Path:       dfs.NewPathCmd(&space, "/versions.txt"),
+					Content:    bytes.NewBufferString(content),
+					UploadedBy: serv.User,
+				})
+				require.NoError(t, err)
+
+				err = serv.RunnerSvc.Run(ctx)
+				require.NoError(t, err)
+			}
+		})
+
+		t.Run("ListVersions returns the previous content", func(t *testing.T) {
+			var err error
+
+			file, err = serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/versions.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, uint64(len("second content")), file.Size())
+
+			versions, err = serv.DFSSvc.ListVersions(ctx, file)
+			require.NoError(t, err)
+			require.Len(t, versions, 1)
+			assert.Equal(t, uint64(len("first content")), versions[0].Size())
+
+			reader, err := serv.DFSSvc.DownloadVersion(ctx, &versions[0])
+			require.NoError(t, err)
+
+			res, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("first content"), res)
+		})
+
+		t.Run("RestoreVersion swaps the contents", func(t *testing.T) {
+			res, err := serv.DFSSvc.RestoreVersion(ctx, &dfs.RestoreVersionCmd{
+				INode:      file,
+				VersionID:  versions[0].ID(),
+				RestoredBy: serv.User,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, file.ID(), res.ID())
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+
+			reader, err := serv.DFSSvc.Download(ctx, dfs.NewPathCmd(&space, "/versions.txt"))
+			require.NoError(t, err)
+
+			content, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("first content"), content)
+
+			// The overwritten content is kept as a new version.
+			versions, err := serv.DFSSvc.ListVersions(ctx, res)
+			require.NoError(t, err)
+			require.Len(t, versions, 1)
+			assert.Equal(t, uint64(len("second content")), versions[0].Size())
+		})
+
+		t.Run("Removing the file purges its versions", func(t *testing.T) {
+			err := serv.DFSSvc.Remove(ctx, serv.User, dfs.NewPathCmd(&space, "/versions.txt"))
+			require.NoError(t, err)
+
+			err = serv.DFSSvc.EmptyTrash(ctx, serv.User, &space)
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("Copy", func(t *testing.T) {
+		t.Run("Setup", func(t *testing.T) {
+			err := serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/copy-src/foo.txt"),
+				Content:    bytes.NewBufferString("some content"),
+				UploadedBy: serv.User,
+			})
+			require.ErrorIs(t, err, errs.ErrNotFound)
+
+			_, err = serv.DFSSvc.CreateDir(ctx, &dfs.CreateDirCmd{
+				Path:      dfs.NewPathCmd(&space, "/copy-src/sub"),
+				CreatedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/copy-src/sub/foo.txt"),
+				Content:    bytes.NewBufferString("some content"),
+				UploadedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+		})
+
+		t.Run("Copy a directory inside itself fails", func(t *testing.T) {
+			err := serv.DFSSvc.Copy(ctx, &dfs.CopyCmd{
+				Src:      dfs.NewPathCmd(&space, "/copy-src"),
+				Dst:      dfs.NewPathCmd(&space, "/copy-src/sub/copy"),
+				CopiedBy: serv.User,
+			})
+			require.ErrorIs(t, err, errs.ErrBadRequest)
+		})
+
+		t.Run("Copy a directory reuses the files", func(t *testing.T) {
+			err := serv.DFSSvc.Copy(ctx, &dfs.CopyCmd{
+				Src:      dfs.NewPathCmd(&space, "/copy-src"),
+				Dst:      dfs.NewPathCmd(&space, "/copy-dst/copy"),
+				CopiedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+
+			src, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/copy-src/sub/foo.txt"))
+			require.NoError(t, err)
+
+			dst, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/copy-dst/copy/sub/foo.txt"))
+			require.NoError(t, err)
+
+			assert.NotEqual(t, src.ID(), dst.ID())
+			assert.Equal(t, src.FileID(), dst.FileID())
+
+			dir, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/copy-dst"))
+			require.NoError(t, err)
+			assert.Equal(t, uint64(len("some content")), dir.Size())
+		})
+
+		t.Run("Removing the source keeps the copy content", func(t *testing.T) {
+			err := serv.DFSSvc.Remove(ctx, serv.User, dfs.NewPathCmd(&space, "/copy-src"))
+			require.NoError(t, err)
+
+			err = serv.DFSSvc.EmptyTrash(ctx, serv.User, &space)
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+
+			reader, err := serv.DFSSvc.Download(ctx, dfs.NewPathCmd(&space, "/copy-dst/copy/sub/foo.txt"))
+			require.NoError(t, err)
+
+			res, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("some content"), res)
+		})
+	})
+
+	t.Run("Cross space move and copy", func(t *testing.T) {
+		var otherSpace *spaces.Space
+
+		t.Run("Setup", func(t *testing.T) {
+			otherSpace, err = serv.SpacesSvc.Create(ctx, &spaces.CreateCmd{
+				User:     serv.User,
+				Name:     "Other space",
+				Managers: []uuid.UUID{serv.User.ID()},
+			})
+			require.NoError(t, err)
+
+			_, err = serv.DFSSvc.CreateFS(ctx, serv.User, otherSpace)
+			require.NoError(t, err)
+
+			err = serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/cross-src/sub/foo.txt"),
+				Content:    bytes.NewBufferString("some content"),
+				UploadedBy: serv.User,
+			})
+			require.ErrorIs(t, err, errs.ErrNotFound)
+
+			_, err = serv.DFSSvc.CreateDir(ctx, &dfs.CreateDirCmd{
+				Path:      dfs.NewPathCmd(&space, "/cross-src/sub"),
+				CreatedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/cross-src/sub/foo.txt"),
+				Content:    bytes.NewBufferString("some content"),
+				UploadedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+		})
+
+		t.Run("Copy a directory into an other space", func(t *testing.T) {
+			err := serv.DFSSvc.Copy(ctx, &dfs.CopyCmd{
+				Src:      dfs.NewPathCmd(&space, "/cross-src"),
+				Dst:      dfs.NewPathCmd(otherSpace, "/copied"),
+				CopiedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+
+			dst, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(otherSpace, "/copied/sub/foo.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, otherSpace.ID(), dst.SpaceID())
+
+			_, err = serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/cross-src/sub/foo.txt"))
+			require.NoError(t, err)
+		})
+
+		t.Run("Move a directory into an other space", func(t *testing.T) {
+			subDir, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/cross-src/sub"))
+			require.NoError(t, err)
+
+			grant, err := serv.DFSSvc.CreateGrant(ctx, &dfs.CreateGrantCmd{
+				Path:       dfs.NewPathCmd(&space, "/cross-src/sub"),
+				User:       serv.User,
+				Permission: dfs.PermissionRead,
+				CreatedBy:  serv.User,
+			})
+			require.NoError(t, err)
+
+			share, err := serv.SharesSvc.Create(ctx, &shares.CreateCmd{
+				Space:     &space,
+				INode:     subDir,
+				CreatedBy: serv.User,
+				Kind:      shares.DownloadKind,
+			})
+			require.NoError(t, err)
+
+			err = serv.DFSSvc.Move(ctx, &dfs.MoveCmd{
+				Src:     dfs.NewPathCmd(&space, "/cross-src"),
+				Dst:     dfs.NewPathCmd(otherSpace, "/moved"),
+				MovedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+
+			_, err = serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/cross-src"))
+			require.ErrorIs(t, err, errs.ErrNotFound)
+
+			dst, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(otherSpace, "/moved/sub/foo.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, otherSpace.ID(), dst.SpaceID())
+
+			dir, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(otherSpace, "/moved/sub"))
+			require.NoError(t, err)
+			assert.Equal(t, otherSpace.ID(), dir.SpaceID())
+
+			// The grant and the share follow the moved folder.
+			grant, err = serv.DFSSvc.GetUserGrant(ctx, serv.User.ID(), grant.ID())
+			require.NoError(t, err)
+			assert.Equal(t, otherSpace.ID(), grant.SpaceID())
+
+			grantPath, err := serv.DFSSvc.GetGrantPath(ctx, grant)
+			require.NoError(t, err)
+			assert.Equal(t, dfs.NewPathCmd(otherSpace, "/moved/sub"), grantPath)
+
+			share, err = serv.SharesSvc.Open(ctx, &shares.OpenCmd{Token: share.Token()})
+			require.NoError(t, err)
+			assert.Equal(t, otherSpace.ID(), share.SpaceID())
+
+			sharePath, err := serv.DFSSvc.GetPathByID(ctx, otherSpace, share.INodeID())
+			require.NoError(t, err)
+			assert.Equal(t, dfs.NewPathCmd(otherSpace, "/moved/sub"), sharePath)
+		})
+
+		t.Run("Both space roots have their size refreshed", func(t *testing.T) {
+			otherRoot, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(otherSpace, "/"))
+			require.NoError(t, err)
+			assert.Equal(t, uint64(2*len("some content")), otherRoot.Size())
+
+			_, err = serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/"))
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("Quota", func(t *testing.T) {
+		var quotaSpace *spaces.Space
+
+		t.Run("Setup", func(t *testing.T) {
+			quotaSpace, err = serv.SpacesSvc.Create(ctx, &spaces.CreateCmd{
+				User:     serv.User,
+				Name:     "Quota space",
+				Managers: []uuid.UUID{serv.User.ID()},
+			})
+			require.NoError(t, err)
+
+			_, err = serv.DFSSvc.CreateFS(ctx, serv.User, quotaSpace)
+			require.NoError(t, err)
+
+			quotaSpace, err = serv.SpacesSvc.SetQuota(ctx, &spaces.SetQuotaCmd{
+				User:    serv.User,
+				SpaceID: quotaSpace.ID(),
+				Quota:   10,
+			})
+			require.NoError(t, err)
+		})
+
+		t.Run("Upload a file exceeding the quota fails", func(t *testing.T) {
+			err := serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(quotaSpace, "/too-big.txt"),
+				Content:    bytes.NewBufferString("some too big content"),
+				UploadedBy: serv.User,
+			})
+			require.ErrorIs(t, err, errs.ErrBadRequest)
+			require.ErrorIs(t, err, dfs.ErrQuotaExceeded)
+
+			_, err = serv.DFSSvc.Get(ctx, dfs.NewPathCmd(quotaSpace, "/too-big.txt"))
+			require.ErrorIs(t, err, errs.ErrNotFound)
+		})
+
+		t.Run("Upload a file within the quota succeed", func(t *testing.T) {
+			err := serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(quotaSpace, "/small.txt"),
+				Content:    bytes.NewBufferString("small"),
+				UploadedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+		})
+
+		t.Run("Overwrite a file doesn't free its previous size", func(t *testing.T) {
+			// The previous content is kept as a version.
+			err := serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(quotaSpace, "/small.txt"),
+				Content:    bytes.NewBufferString("123456"),
+				UploadedBy: serv.User,
+			})
+			require.ErrorIs(t, err, dfs.ErrQuotaExceeded)
+
+			err = serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(quotaSpace, "/small.txt"),
+				Content:    bytes.NewBufferString("12345"),
+				UploadedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+		})
+
+		t.Run("Upload in a full space fails", func(t *testing.T) {
+			err := serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(quotaSpace, "/other.txt"),
+				Content:    bytes.NewBufferString("a"),
+				UploadedBy: serv.User,
+			})
+			require.ErrorIs(t, err, dfs.ErrQuotaExceeded)
+		})
+	})
+
+	t.Run("User quota", func(t *testing.T) {
+		var usage uint64
+		var user *users.User
+
+		t.Run("Setup", func(t *testing.T) {
+			var err error
+
+			usage, err = serv.DFSSvc.GetUserUsage(ctx, serv.User)
+			require.NoError(t, err)
+
+			user, err = serv.UsersSvc.SetQuota(ctx, &users.SetQuotaCmd{
+				User:   serv.User,
+				UserID: serv.User.ID(),
+				Quota:  usage + 5,
+			})
+			require.NoError(t, err)
+		})
+
+		t.Run("Upload a file exceeding the user quota fails", func(t *testing.T) {
+			err := serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/user-too-big.txt"),
+				Content:    bytes.NewBufferString("1234567890"),
+				UploadedBy: user,
+			})
+			require.ErrorIs(t, err, dfs.ErrQuotaExceeded)
+		})
+
+		t.Run("Upload a file within the user quota succeed", func(t *testing.T) {
+			err := serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/user-small.txt"),
+				Content:    bytes.NewBufferString("123"),
+				UploadedBy: user,
+			})
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+
+			res, err := serv.DFSSvc.GetUserUsage(ctx, user)
+			require.NoError(t, err)
+			assert.Equal(t, usage+3, res)
+		})
+
+		t.Run("Upload in a full user quota fails", func(t *testing.T) {
+			err := serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/user-other.txt"),
+				Content:    bytes.NewBufferString("123"),
+				UploadedBy: user,
+			})
+			require.ErrorIs(t, err, dfs.ErrQuotaExceeded)
+		})
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		t.Run("Setup", func(t *testing.T) {
+			_, err := serv.DFSSvc.CreateDir(ctx, &dfs.CreateDirCmd{
+				Path:      dfs.NewPathCmd(&space, "/search/Invoices"),
+				CreatedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/search/Invoices/invoice-2024.txt"),
+				Content:    bytes.NewBufferString("Hello"),
+				UploadedBy: serv.User,
+			})
+			require.NoError(t, err)
+		})
+
+		t.Run("Search an uploaded file", func(t *testing.T) {
+			res, err := serv.DFSSvc.Search(ctx, &dfs.SearchCmd{User: serv.User, Query: "invoice 2024"}, nil)
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+			assert.Equal(t, "/search/Invoices/invoice-2024.txt", res[0].Path())
+			assert.Equal(t, space.ID(), res[0].INode().SpaceID())
+		})
+
+		t.Run("Search after a directory rename", func(t *testing.T) {
+			dir, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/search/Invoices"))
+			require.NoError(t, err)
+
+			_, err = serv.DFSSvc.Rename(ctx, serv.User, dir, "Bills")
+			require.NoError(t, err)
+
+			res, err := serv.DFSSvc.Search(ctx, &dfs.SearchCmd{User: serv.User, Query: "invoice 2024"}, nil)
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+			assert.Equal(t, "/search/Bills/invoice-2024.txt", res[0].Path())
+		})
+
+		t.Run("Search after a removal", func(t *testing.T) {
+			err := serv.DFSSvc.Remove(ctx, serv.User, dfs.NewPathCmd(&space, "/search"))
+			require.NoError(t, err)
+
+			res, err := serv.DFSSvc.Search(ctx, &dfs.SearchCmd{User: serv.User, Query: "invoice"}, nil)
+			require.NoError(t, err)
+			assert.Empty(t, res)
+		})
+	})
+
+	t.Run("Search in content", func(t *testing.T) {
+		t.Run("Setup", func(t *testing.T) {
+			err := serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/todo.md"),
+				Content:    bytes.NewBufferString("# Todo\n\n- Buy some milk\n"),
+				UploadedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.SchedulerSvc.Run(ctx)
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+		})
+
+		t.Run("Search a word from the content", func(t *testing.T) {
+			res, err := serv.DFSSvc.Search(ctx, &dfs.SearchCmd{User: serv.User, Query: "milk", InContent: true}, nil)
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+			assert.Equal(t, "/todo.md", res[0].Path())
+		})
+
+		t.Run("Search after a content change", func(t *testing.T) {
+			err := serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/todo.md"),
+				Content:    bytes.NewBufferString("# Todo\n\n- Call the plumber\n"),
+				UploadedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			// The previous content is not matched anymore.
+			res, err := serv.DFSSvc.Search(ctx, &dfs.SearchCmd{User: serv.User, Query: "milk", InContent: true}, nil)
+			require.NoError(t, err)
+			assert.Empty(t, res)
+		})
+	})
+
+	t.Run("Dead properties", func(t *testing.T) {
+		t.Run("Setup", func(t *testing.T) {
+			_, err := serv.DFSSvc.CreateDir(ctx, &dfs.CreateDirCmd{
+				Path:      dfs.NewPathCmd(&space, "/props"),
+				CreatedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.DFSSvc.Upload(ctx, &dfs.UploadCmd{
+				Path:       dfs.NewPathCmd(&space, "/props/foo.txt"),
+				Content:    bytes.NewBufferString("some content"),
+				UploadedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+		})
+
+		t.Run("PatchProps success", func(t *testing.T) {
+			err := serv.DFSSvc.PatchProps(ctx, &dfs.PatchPropsCmd{
+				Path: dfs.NewPathCmd(&space, "/props/foo.txt"),
+				Patches: []dfs.PropPatch{
+					{Namespace: "urn:test", Name: "color", Value: "red"},
+					{Namespace: "urn:test", Name: "size", Value: "42"},
+					{Namespace: "urn:test", Name: "size", Remove: true},
+				},
+				PatchedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			file, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/props/foo.txt"))
+			require.NoError(t, err)
+
+			res, err := serv.DFSSvc.GetProps(ctx, file)
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+			assert.Equal(t, "color", res[0].Name())
+			assert.Equal(t, "red", res[0].Value())
+		})
+
+		t.Run("Copy carries the properties", func(t *testing.T) {
+			err := serv.DFSSvc.Copy(ctx, &dfs.CopyCmd{
+				Src:      dfs.NewPathCmd(&space, "/props"),
+				Dst:      dfs.NewPathCmd(&space, "/props-copy"),
+				CopiedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+
+			file, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/props-copy/foo.txt"))
+			require.NoError(t, err)
+
+			res, err := serv.DFSSvc.GetProps(ctx, file)
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+			assert.Equal(t, file.ID(), res[0].INodeID())
+			assert.Equal(t, "red", res[0].Value())
+		})
+
+		t.Run("Move carries the properties", func(t *testing.T) {
+			err := serv.DFSSvc.Move(ctx, &dfs.MoveCmd{
+				Src:     dfs.NewPathCmd(&space, "/props-copy/foo.txt"),
+				Dst:     dfs.NewPathCmd(&space, "/props-copy/bar.txt"),
+				MovedBy: serv.User,
+			})
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+
+			file, err := serv.DFSSvc.Get(ctx, dfs.NewPathCmd(&space, "/props-copy/bar.txt"))
+			require.NoError(t, err)
+
+			res, err := serv.DFSSvc.GetProps(ctx, file)
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+			assert.Equal(t, "red", res[0].Value())
+		})
+
+		t.Run("EmptyTrash removes the properties", func(t *testing.T) {
+			err := serv.DFSSvc.Remove(ctx, serv.User, dfs.NewPathCmd(&space, "/props"))
+			require.NoError(t, err)
+
+			err = serv.DFSSvc.Remove(ctx, serv.User, dfs.NewPathCmd(&space, "/props-copy"))
+			require.NoError(t, err)
+
+			err = serv.DFSSvc.EmptyTrash(ctx, serv.User, &space)
+			require.NoError(t, err)
+
+			err = serv.RunnerSvc.Run(ctx)
+			require.NoError(t, err)
+
+			res, err := serv.DFSSvc.ListDeleted(ctx, &space, nil)
+			require.NoError(t, err)
+			assert.Empty(t, res)
+		})
+	})
 }
@@ -1,11 +1,13 @@
 package dfs
 
 import (
+	"errors"
 	io "io"
 	"strings"
 	"time"
 
 	v "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
@@ -38,7 +40,7 @@ func (t PathCmd) Space() *spaces.Space {
 }
 
 func (t PathCmd) Equal(p PathCmd) bool {
-	return t.space == p.space && t.path == p.path
+	return t.space.ID() == p.space.ID() && t.path == p.path
 }
 
 func (t PathCmd) String() string {
@@ -52,11 +54,14 @@ func (t PathCmd) String() string {
 // "/foo/bar".Contains("/foo") -> false
 // "/foo".Contains("/foo/bar") -> true
 func (t PathCmd) Contains(p PathCmd) bool {
-	if t.space != p.space {
+	if t.space.ID() != p.space.ID() {
 		return false
 	}
 
-	return strings.Contains(CleanPath(p.path), CleanPath(t.path))
+	parent := CleanPath(t.path)
+	child := CleanPath(p.path)
+
+	return child == parent || strings.HasPrefix(child, strings.TrimSuffix(parent, "/")+"/")
 }
 
 // Validate the fields.
@@ -71,6 +76,12 @@ type UploadCmd struct {
 	Content    io.Reader
 	Path       *PathCmd
 	UploadedBy *users.User
+	// ModifiedAt is the modification time given by the client. The upload
+	// time is used if it's not set.
+	ModifiedAt time.Time
+	// CreateOnly makes the upload fail with ErrAlreadyExists if a file exists
+	// at Path instead of keeping its content as a version.
+	CreateOnly bool
 }
 
 func (t UploadCmd) Validate() error {
@@ -107,6 +118,198 @@ func (t MoveCmd) Validate() error {
 	)
 }
 
+type CopyCmd struct {
+	Src      *PathCmd
+	Dst      *PathCmd
+	CopiedBy *users.User
+}
+
+func (t CopyCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.Src, v.Required, v.NotNil),
+		v.Field(&t.Dst, v.Required, v.NotNil),
+		v.Field(&t.CopiedBy, v.Required, v.NotNil),
+	)
+}
+
+type RestoreCmd struct {
+	Space      *spaces.Space
+	INodeID    uuid.UUID
+	RestoredBy *users.User
+}
+
+func (t RestoreCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.Space, v.Required, v.NotNil),
+		v.Field(&t.INodeID, v.Required, is.UUIDv4),
+		v.Field(&t.RestoredBy, v.Required, v.NotNil),
+	)
+}
+
+type RestoreVersionCmd struct {
+	INode      *INode
+	VersionID  uuid.UUID
+	RestoredBy *users.User
+}
+
+func (t RestoreVersionCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.INode, v.Required, v.NotNil),
+		v.Field(&t.VersionID, v.Required, is.UUIDv4),
+		v.Field(&t.RestoredBy, v.Required, v.NotNil),
+	)
+}
+
+// SearchCmd looks for the inodes with a name or a path matching Query inside
+// the spaces of User. All the other fields are optional filters.
+type SearchCmd struct {
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+	User           *users.User
+	Query          string
+	SpaceID        uuid.UUID
+	// MimeType is either a full mimetype ("image/png") or a prefix ending with
+	// a "*" ("image/*").
+	MimeType string
+	MinSize  uint64
+	MaxSize  uint64
+	// InContent also matches the files with a text content containing the
+	// query. The contents are indexed by the "fs-index-content" task.
+	InContent bool
+}
+
+func (t SearchCmd) Validate() error {
+	maxSizeRules := []v.Rule{}
+	if t.MaxSize > 0 {
+		maxSizeRules = append(maxSizeRules, v.Min(t.MinSize))
+	}
+
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required, v.NotNil),
+		v.Field(&t.Query, v.Required, v.Length(1, 200)),
+		v.Field(&t.SpaceID, is.UUIDv4),
+		v.Field(&t.MaxSize, maxSizeRules...),
+	)
+}
+
+// SearchResult is an inode found by a search along with its path.
+type SearchResult struct {
+	path  string
+	inode INode
+}
+
+func (r SearchResult) INode() INode { return r.inode }
+func (r SearchResult) Path() string { return r.path }
+
+// SearchOperator is the operator of a SearchFilter.
+type SearchOperator string
+
+const (
+	// SearchAnd matches if all the sub-filters match.
+	SearchAnd SearchOperator = "and"
+	// SearchOr matches if one of the sub-filters matches.
+	SearchOr SearchOperator = "or"
+	// SearchNot matches if its single sub-filter doesn't match.
+	SearchNot SearchOperator = "not"
+	// SearchLike matches a text field with a SQL pattern where "%" matches
+	// any string and "_" any character. The ASCII letters are case
+	// insensitive.
+	SearchLike SearchOperator = "like"
+	SearchEq   SearchOperator = "eq"
+	SearchGt   SearchOperator = "gt"
+	SearchLt   SearchOperator = "lt"
+)
+
+// SearchField is the inode field compared by a SearchFilter.
+type SearchField string
+
+const (
+	// SearchName is the inode name, a string.
+	SearchName SearchField = "name"
+	// SearchMimeType is the mimetype of a file, a string. The folders don't
+	// have any.
+	SearchMimeType SearchField = "mimetype"
+	// SearchSize is the size of a file in bytes, an uint64. The folders
+	// don't have any.
+	SearchSize SearchField = "size"
+	// SearchLastModifiedAt is the last modification date, a time.Time.
+	SearchLastModifiedAt SearchField = "last_modified_at"
+)
+
+// SearchFilter is a condition of a SearchTreeCmd. The "and", "or" and "not"
+// operators combine the sub-filters, the other ones compare Field with
+// Value.
+type SearchFilter struct {
+	Value    any
+	Operator SearchOperator
+	Field    SearchField
+	Filters  []SearchFilter
+}
+
+func (t SearchFilter) Validate() error {
+	switch t.Operator {
+	case SearchAnd, SearchOr:
+		return v.ValidateStruct(&t,
+			v.Field(&t.Filters, v.Required),
+		)
+	case SearchNot:
+		return v.ValidateStruct(&t,
+			v.Field(&t.Filters, v.Required, v.Length(1, 1)),
+		)
+	case SearchLike:
+		return v.ValidateStruct(&t,
+			v.Field(&t.Field, v.Required, v.In(SearchName, SearchMimeType)),
+			v.Field(&t.Value, v.By(t.validateValue)),
+		)
+	case SearchEq, SearchGt, SearchLt:
+		return v.ValidateStruct(&t,
+			v.Field(&t.Field, v.Required, v.In(SearchName, SearchMimeType, SearchSize, SearchLastModifiedAt)),
+			v.Field(&t.Value, v.By(t.validateValue)),
+		)
+	default:
+		return v.ValidateStruct(&t,
+			v.Field(&t.Operator, v.In(SearchAnd, SearchOr, SearchNot, SearchLike, SearchEq, SearchGt, SearchLt)),
+		)
+	}
+}
+
+// validateValue checks that the value type matches the field.
+func (t SearchFilter) validateValue(value any) error {
+	var ok bool
+	switch t.Field {
+	case SearchName, SearchMimeType:
+		_, ok = value.(string)
+	case SearchSize:
+		_, ok = value.(uint64)
+	case SearchLastModifiedAt:
+		_, ok = value.(time.Time)
+	}
+
+	if !ok {
+		return errors.New("invalid type for the field")
+	}
+
+	return nil
+}
+
+// SearchTreeCmd looks for the inodes inside the Root folder matching
+// Filter. Unlike SearchCmd it doesn't use the full text index and every
+// inode matches without Filter.
+type SearchTreeCmd struct {
+	Root   *PathCmd
+	Filter *SearchFilter
+	// Recursive looks inside all the sub-folders. Only the direct children
+	// of Root are searched otherwise.
+	Recursive bool
+}
+
+func (t SearchTreeCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.Root, v.Required, v.NotNil),
+		v.Field(&t.Filter),
+	)
+}
+
 type CreateRootDirCmd struct {
 	CreatedBy *users.User
 	Space     *spaces.Space
@@ -122,6 +325,7 @@ func (t CreateRootDirCmd) Validate() error {
 type INode struct {
 	createdAt      time.Time
 	lastModifiedAt time.Time
+	deletedAt      time.Time
 	parent         *uuid.UUID
 	fileID         *uuid.UUID
 	id             uuid.UUID
@@ -139,5 +343,148 @@ func (n INode) Size() uint64              { return n.size }
 func (n INode) CreatedAt() time.Time      { return n.createdAt }
 func (n INode) CreatedBy() uuid.UUID      { return n.createdBy }
 func (n INode) LastModifiedAt() time.Time { return n.lastModifiedAt }
+func (n INode) DeletedAt() time.Time      { return n.deletedAt }
 func (n INode) FileID() *uuid.UUID        { return n.fileID }
 func (n INode) IsDir() bool               { return n.fileID == nil }
+
+// ETag returns a strong entity tag for the file content. The tag changes each
+// time the file is overwritten with a new content. The directories don't have
+// any ETag.
+func (n INode) ETag() string {
+	if n.fileID == nil {
+		return ""
+	}
+
+	return `"` + string(n.id) + "-" + string(*n.fileID) + `"`
+}
+
+// FileVersion is a past content of a file. It is created each time a file
+// is overwritten.
+type FileVersion struct {
+	createdAt  time.Time
+	modifiedAt time.Time
+	id         uuid.UUID
+	inodeID    uuid.UUID
+	fileID     uuid.UUID
+	createdBy  uuid.UUID
+	size       uint64
+}
+
+func (v FileVersion) ID() uuid.UUID         { return v.id }
+func (v FileVersion) INodeID() uuid.UUID    { return v.inodeID }
+func (v FileVersion) FileID() uuid.UUID     { return v.fileID }
+func (v FileVersion) Size() uint64          { return v.size }
+func (v FileVersion) ModifiedAt() time.Time { return v.modifiedAt }
+func (v FileVersion) CreatedAt() time.Time  { return v.createdAt }
+func (v FileVersion) CreatedBy() uuid.UUID  { return v.createdBy }
+
+// Permission defines what a user can do inside a folder shared with a
+// [Grant].
+type Permission string
+
+const (
+	// PermissionRead allows to list and download the folder content.
+	PermissionRead Permission = "read"
+	// PermissionWrite allows to modify the folder content too.
+	PermissionWrite Permission = "write"
+)
+
+var Permissions = []Permission{PermissionRead, PermissionWrite}
+
+func (p Permission) String() string { return string(p) }
+
+// CanWrite returns true if the permission allows to upload, rename, move and
+// delete files inside the folder.
+func (p Permission) CanWrite() bool { return p == PermissionWrite }
+
+func (p Permission) Validate() error {
+	return v.Validate(string(p), v.In(PermissionRead.String(), PermissionWrite.String()))
+}
+
+// Grant gives a user an access to a folder, and all its content, without
+// being a member of the whole space.
+type Grant struct {
+	createdAt  time.Time
+	id         uuid.UUID
+	spaceID    uuid.UUID
+	inodeID    uuid.UUID
+	userID     uuid.UUID
+	permission Permission
+	createdBy  uuid.UUID
+}
+
+func (g Grant) ID() uuid.UUID          { return g.id }
+func (g Grant) SpaceID() uuid.UUID     { return g.spaceID }
+func (g Grant) INodeID() uuid.UUID     { return g.inodeID }
+func (g Grant) UserID() uuid.UUID      { return g.userID }
+func (g Grant) Permission() Permission { return g.permission }
+func (g Grant) CreatedAt() time.Time   { return g.createdAt }
+func (g Grant) CreatedBy() uuid.UUID   { return g.createdBy }
+
+type CreateGrantCmd struct {
+	Path       *PathCmd
+	User       *users.User
+	Permission Permission
+	CreatedBy  *users.User
+}
+
+func (t CreateGrantCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.Path, v.Required, v.NotNil),
+		v.Field(&t.User, v.Required, v.NotNil),
+		v.Field(&t.Permission, v.Required),
+		v.Field(&t.CreatedBy, v.Required, v.NotNil),
+	)
+}
+
+// MaxPropertySize is the maximum size of a [Property] value.
+const MaxPropertySize = 64 * 1024
+
+// Property is an arbitrary metadata attached to an inode by a client, also
+// called a dead property in the WebDAV specification. The value is kept as
+// the raw XML content of the property.
+type Property struct {
+	inodeID   uuid.UUID
+	namespace string
+	name      string
+	lang      string
+	value     string
+}
+
+func (p Property) INodeID() uuid.UUID { return p.inodeID }
+func (p Property) Namespace() string  { return p.namespace }
+func (p Property) Name() string       { return p.name }
+func (p Property) Lang() string       { return p.lang }
+func (p Property) Value() string      { return p.value }
+
+// PropPatch sets or removes the property identified by its namespace and
+// name.
+type PropPatch struct {
+	Namespace string
+	Name      string
+	Lang      string
+	Value     string
+	Remove    bool
+}
+
+func (t PropPatch) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.Name, v.Required, v.Length(1, 255)),
+		v.Field(&t.Namespace, v.Length(0, 1024)),
+		v.Field(&t.Value, v.Length(0, MaxPropertySize)),
+	)
+}
+
+type PatchPropsCmd struct {
+	Path      *PathCmd
+	Patches   []PropPatch
+	PatchedBy *users.User
+}
+
+func (t PatchPropsCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.Path, v.Required, v.NotNil),
+		v.Field(&t.Patches, v.Required),
+		v.Field(&t.PatchedBy, v.Required, v.NotNil),
+	)
+}
@@ -110,3 +110,43 @@ var ExampleBobRoot INode = INode{
 	lastModifiedAt: now2,
 	fileID:         nil,
 }
+
+var ExampleAliceFileVersion = FileVersion{
+	id:         uuid.UUID("5b5a7d2e-3c3a-4bde-9e2d-52a1f9a2c8a1"),
+	inodeID:    ExampleAliceFile.ID(),
+	fileID:     files.ExampleFile2.ID(),
+	size:       22,
+	modifiedAt: now,
+	createdAt:  now2,
+	createdBy:  users.ExampleAlice.ID(),
+}
+
+var ExampleAliceFileSearchResult = SearchResult{
+	path:  "/foo.pdf",
+	inode: ExampleAliceFile,
+}
+
+var ExampleAliceDirSearchResult = SearchResult{
+	path:  "/dir-a",
+	inode: ExampleAliceDir,
+}
+
+var ExampleBobReadGrant = Grant{
+	id:         uuid.UUID("4f6e25a4-1a2b-4a39-a3d1-2b8f0f0c3e51"),
+	spaceID:    spaces.ExampleAlicePersonalSpace.ID(),
+	inodeID:    ExampleAliceDir.ID(),
+	userID:     users.ExampleBob.ID(),
+	permission: PermissionRead,
+	createdAt:  now,
+	createdBy:  users.ExampleAlice.ID(),
+}
+
+var ExampleBobWriteGrant = Grant{
+	id:         uuid.UUID("a2d7b7a1-8f0e-4a43-9b35-5c1e6f4d2b07"),
+	spaceID:    spaces.ExampleAlicePersonalSpace.ID(),
+	inodeID:    ExampleAliceDir.ID(),
+	userID:     users.ExampleBob.ID(),
+	permission: PermissionWrite,
+	createdAt:  now,
+	createdBy:  users.ExampleAlice.ID(),
+}
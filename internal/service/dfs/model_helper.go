@@ -49,11 +49,11 @@ func (f *FakeINodeBuilder) IsRootDirectory() *FakeINodeBuilder {
 	return f
 }
 
-// func (f *FakeINodeBuilder) IsDirectory() *FakeINodeBuilder {
-// 	f.inode.fileID = nil
-//
-// 	return f
-// }
+func (f *FakeINodeBuilder) IsDirectory() *FakeINodeBuilder {
+	f.inode.fileID = nil
+
+	return f
+}
 
 func (f *FakeINodeBuilder) WithSpace(space *spaces.Space) *FakeINodeBuilder {
 	f.inode.spaceID = space.ID()
@@ -86,6 +86,12 @@ func (f *FakeINodeBuilder) WithName(name string) *FakeINodeBuilder {
 	return f
 }
 
+func (f *FakeINodeBuilder) WithSize(size uint64) *FakeINodeBuilder {
+	f.inode.size = size
+
+	return f
+}
+
 func (f *FakeINodeBuilder) CreatedBy(user *users.User) *FakeINodeBuilder {
 	f.inode.createdBy = user.ID()
 
@@ -99,6 +105,12 @@ func (f *FakeINodeBuilder) CreatedAt(t time.Time) *FakeINodeBuilder {
 	return f
 }
 
+func (f *FakeINodeBuilder) DeletedAt(t time.Time) *FakeINodeBuilder {
+	f.inode.deletedAt = t
+
+	return f
+}
+
 func (f *FakeINodeBuilder) Build() *INode {
 	return f.inode
 }
@@ -113,3 +125,49 @@ func (f *FakeINodeBuilder) BuildAndStore(ctx context.Context, db sqlstorage.Quer
 
 	return f.inode
 }
+
+type FakeGrantBuilder struct {
+	t     *testing.T
+	grant *Grant
+}
+
+// NewFakeGrant returns a read only grant on the given directory.
+func NewFakeGrant(t *testing.T, dir *INode, user *users.User) *FakeGrantBuilder {
+	t.Helper()
+
+	uuidProvider := uuid.NewProvider()
+
+	return &FakeGrantBuilder{
+		t: t,
+		grant: &Grant{
+			id:         uuidProvider.New(),
+			spaceID:    dir.SpaceID(),
+			inodeID:    dir.ID(),
+			userID:     user.ID(),
+			permission: PermissionRead,
+			createdAt:  gofakeit.DateRange(time.Now().Add(-time.Hour*1000), time.Now()).UTC(),
+			createdBy:  dir.CreatedBy(),
+		},
+	}
+}
+
+func (f *FakeGrantBuilder) WithPermission(permission Permission) *FakeGrantBuilder {
+	f.grant.permission = permission
+
+	return f
+}
+
+func (f *FakeGrantBuilder) Build() *Grant {
+	return f.grant
+}
+
+func (f *FakeGrantBuilder) BuildAndStore(ctx context.Context, db sqlstorage.Querier) *Grant {
+	f.t.Helper()
+
+	storage := newSqlStorage(db)
+
+	err := storage.SaveGrant(ctx, f.grant)
+	require.NoError(f.t, err)
+
+	return f.grant
+}
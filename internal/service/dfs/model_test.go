@@ -29,6 +29,21 @@ func TestInodeGetter(t *testing.T) {
 	assert.Equal(t, ExampleAliceFile.SpaceID(), ExampleAliceFile.spaceID)
 }
 
+func TestInodeETag(t *testing.T) {
+	assert.Equal(t, "", ExampleAliceRoot.ETag())
+	assert.Equal(t, `"`+string(ExampleAliceFile.ID())+`-abf05a02-8af9-4184-a46d-847f7d951c6b"`, ExampleAliceFile.ETag())
+}
+
+func TestFileVersionGetter(t *testing.T) {
+	assert.Equal(t, ExampleAliceFileVersion.id, ExampleAliceFileVersion.ID())
+	assert.Equal(t, ExampleAliceFile.ID(), ExampleAliceFileVersion.INodeID())
+	assert.Equal(t, ExampleAliceFileVersion.fileID, ExampleAliceFileVersion.FileID())
+	assert.Equal(t, uint64(22), ExampleAliceFileVersion.Size())
+	assert.Equal(t, now, ExampleAliceFileVersion.ModifiedAt())
+	assert.Equal(t, now2, ExampleAliceFileVersion.CreatedAt())
+	assert.Equal(t, users.ExampleAlice.ID(), ExampleAliceFileVersion.CreatedBy())
+}
+
 func Test_Inodes_Commands(t *testing.T) {
 	t.Run("CreateRootDirCmd", func(t *testing.T) {
 		cmd := CreateRootDirCmd{
@@ -39,6 +54,25 @@ func Test_Inodes_Commands(t *testing.T) {
 		err := cmd.Validate()
 		require.EqualError(t, err, "CreatedBy: cannot be blank.")
 	})
+
+	t.Run("SearchCmd with a max size lower than the min size", func(t *testing.T) {
+		cmd := SearchCmd{
+			User:    &users.ExampleAlice,
+			Query:   "foo",
+			MinSize: 42,
+			MaxSize: 10,
+		}
+
+		err := cmd.Validate()
+		require.EqualError(t, err, "MaxSize: must be no less than 42.")
+	})
+}
+
+func TestSearchResultGetter(t *testing.T) {
+	res := SearchResult{path: "/foo/bar.txt", inode: ExampleAliceFile}
+
+	assert.Equal(t, "/foo/bar.txt", res.Path())
+	assert.Equal(t, ExampleAliceFile, res.INode())
 }
 
 func Test_PathCmd_Equal(t *testing.T) {
@@ -117,6 +151,26 @@ func Test_PathCmd_Contains(t *testing.T) {
 			B:        NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
 			Expected: false,
 		},
+		{
+			A:        NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+			B:        NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/foo"),
+			Expected: false,
+		},
+		{
+			A:        NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+			B:        NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foobar"),
+			Expected: false,
+		},
+		{
+			A:        NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+			B:        NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+			Expected: true,
+		},
+		{
+			A:        NewPathCmd(ptr.To(spaces.ExampleAlicePersonalSpace), "/foo"),
+			B:        NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+			Expected: true,
+		},
 	}
 
 	for _, test := range tests {
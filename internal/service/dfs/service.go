@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/theduckcompany/duckcloud/internal/service/files"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
@@ -32,6 +34,10 @@ var (
 	ErrIsADir          = errors.New("is a directory")
 	ErrNotFound        = errors.New("inode not found")
 	ErrAlreadyExists   = errors.New("already exists")
+	ErrQuotaExceeded   = errors.New("quota exceeded")
+	ErrReadOnly        = errors.New("read only access")
+	ErrAlreadyGranted  = errors.New("the user already have an access to this folder")
+	ErrGrantRoot       = errors.New("the space root can't be shared, add a space member instead")
 )
 
 //go:generate mockery --name storage
@@ -40,14 +46,47 @@ type storage interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*INode, error)
 	GetByNameAndParent(ctx context.Context, name string, parent uuid.UUID) (*INode, error)
 	GetAllChildrens(ctx context.Context, parent uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]INode, error)
+	GetAllChildrensWithDeleted(ctx context.Context, parent uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]INode, error)
 	HardDelete(ctx context.Context, id uuid.UUID) error
-	GetAllDeleted(ctx context.Context, limit int) ([]INode, error)
+	GetAllDeleted(ctx context.Context, cmd *sqlstorage.PaginateCmd) ([]INode, error)
+	GetAllSpaceDeleted(ctx context.Context, spaceID uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]INode, error)
 	GetDeleted(ctx context.Context, id uuid.UUID) (*INode, error)
 	Patch(ctx context.Context, inode uuid.UUID, fields map[string]any) error
 	GetSumChildsSize(ctx context.Context, parent uuid.UUID) (uint64, error)
 	GetAllInodesWithFileID(ctx context.Context, fileID uuid.UUID) ([]INode, error)
 	GetSpaceRoot(ctx context.Context, spaceID uuid.UUID) (*INode, error)
 	GetSumRootsSize(ctx context.Context) (uint64, error)
+	GetSumUserFilesSize(ctx context.Context, userID uuid.UUID) (uint64, error)
+	GetSumSpaceVersionsSize(ctx context.Context, spaceID uuid.UUID) (uint64, error)
+	Search(ctx context.Context, spaceIDs []uuid.UUID, cmd *SearchCmd, paginateCmd *sqlstorage.PaginateCmd) ([]SearchResult, error)
+	SearchTree(ctx context.Context, root *INode, rootPath string, cmd *SearchTreeCmd, paginateCmd *sqlstorage.PaginateCmd) ([]SearchResult, error)
+	GetAllFileIDsToIndex(ctx context.Context, limit int) ([]uuid.UUID, error)
+	SaveFileContent(ctx context.Context, fileID uuid.UUID, content string) error
+	DeleteFileContent(ctx context.Context, fileID uuid.UUID) error
+
+	SaveVersion(ctx context.Context, version *FileVersion) error
+	GetVersionByID(ctx context.Context, id uuid.UUID) (*FileVersion, error)
+	GetAllINodeVersions(ctx context.Context, inodeID uuid.UUID) ([]FileVersion, error)
+	GetAllVersionsWithFileID(ctx context.Context, fileID uuid.UUID) ([]FileVersion, error)
+	GetAllVersionedINodeIDs(ctx context.Context, cmd *sqlstorage.PaginateCmd) ([]uuid.UUID, error)
+	DeleteVersion(ctx context.Context, id uuid.UUID) error
+
+	SaveGrant(ctx context.Context, grant *Grant) error
+	GetGrantByID(ctx context.Context, id uuid.UUID) (*Grant, error)
+	GetGrantByINodeAndUser(ctx context.Context, inodeID, userID uuid.UUID) (*Grant, error)
+	GetAllUserGrants(ctx context.Context, userID uuid.UUID) ([]Grant, error)
+	GetAllINodeGrants(ctx context.Context, inodeID uuid.UUID) ([]Grant, error)
+	DeleteGrant(ctx context.Context, id uuid.UUID) error
+	DeleteAllINodeGrants(ctx context.Context, inodeID uuid.UUID) error
+	MoveAllINodeGrants(ctx context.Context, inodeID, spaceID uuid.UUID) error
+	DeleteAllUserGrants(ctx context.Context, userID uuid.UUID) error
+
+	SaveProp(ctx context.Context, prop *Property) error
+	GetAllINodeProps(ctx context.Context, inodeID uuid.UUID) ([]Property, error)
+	GetAllINodesProps(ctx context.Context, inodeIDs []uuid.UUID) ([]Property, error)
+	CopyAllINodeProps(ctx context.Context, src, dst uuid.UUID) error
+	DeleteProp(ctx context.Context, inodeID uuid.UUID, namespace, name string) error
+	DeleteAllINodeProps(ctx context.Context, inodeID uuid.UUID) error
 }
 
 type service struct {
@@ -127,12 +166,32 @@ func (s *service) ListDir(ctx context.Context, cmd *PathCmd, paginateCmd *sqlsto
 	return res, nil
 }
 
-func (s *service) Rename(ctx context.Context, inode *INode, newName string) (*INode, error) {
+// ListChildren returns the children of an already fetched directory. Unlike
+// ListDir the path of the directory is not resolved for each page.
+func (s *service) ListChildren(ctx context.Context, dir *INode, paginateCmd *sqlstorage.PaginateCmd) ([]INode, error) {
+	if !dir.IsDir() {
+		return nil, errs.BadRequest(ErrIsNotDir)
+	}
+
+	res, err := s.storage.GetAllChildrens(ctx, dir.ID(), paginateCmd)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllChildrens: %w", err))
+	}
+
+	return res, nil
+}
+
+func (s *service) Rename(ctx context.Context, user *users.User, inode *INode, newName string) (*INode, error) {
 	if newName == "" {
 		return nil, errs.Validation(errors.New("can't be empty"))
 	}
 
-	newName, err := s.findUniqueName(ctx, inode, newName)
+	err := s.checkINodeWriteAccess(ctx, user, inode)
+	if err != nil {
+		return nil, err
+	}
+
+	newName, err = s.findUniqueName(ctx, inode, newName)
 	if err != nil {
 		return nil, err
 	}
@@ -154,6 +213,31 @@ func (s *service) Rename(ctx context.Context, inode *INode, newName string) (*IN
 	return &newINode, err
 }
 
+// SetModifiedAt changes the modification time of the inode. It allows the
+// clients to keep the original modification time of a synchronized file.
+func (s *service) SetModifiedAt(ctx context.Context, user *users.User, inode *INode, modifiedAt time.Time) (*INode, error) {
+	if modifiedAt.IsZero() {
+		return nil, errs.Validation(errors.New("can't be empty"))
+	}
+
+	err := s.checkINodeWriteAccess(ctx, user, inode)
+	if err != nil {
+		return nil, err
+	}
+
+	newINode := *inode
+	newINode.lastModifiedAt = modifiedAt
+
+	err = s.storage.Patch(ctx, inode.ID(), map[string]any{
+		"last_modified_at": sqlstorage.SQLTime(modifiedAt),
+	})
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to Patch: %w", err))
+	}
+
+	return &newINode, nil
+}
+
 func (s *service) findUniqueName(ctx context.Context, inode *INode, newName string) (string, error) {
 	if inode.Parent() == nil {
 		return "", errs.Validation(errors.New("can't rename the root"))
@@ -187,6 +271,13 @@ func (s *service) CreateDir(ctx context.Context, cmd *CreateDirCmd) (*INode, err
 		return nil, errs.Validation(err)
 	}
 
+	// The shared folder itself is accepted as it already exists. The move and
+	// copy tasks create the target parent directory with this method.
+	err = s.checkGrantedWriteAccess(ctx, cmd.CreatedBy, cmd.Path, true)
+	if err != nil {
+		return nil, err
+	}
+
 	var inode *INode
 	currentPath := "/"
 	err = s.walk(ctx, cmd.Path, "mkdir", func(dir *INode, frag string, _ bool) error {
@@ -229,11 +320,16 @@ func (s *service) CreateDir(ctx context.Context, cmd *CreateDirCmd) (*INode, err
 	return inode, nil
 }
 
-func (s *service) Remove(ctx context.Context, cmd *PathCmd) error {
+func (s *service) Remove(ctx context.Context, user *users.User, cmd *PathCmd) error {
 	if cmd.Path() == "/" {
 		return fmt.Errorf("%w: can't remove /", errs.ErrUnauthorized)
 	}
 
+	err := s.checkWriteAccess(ctx, user, cmd)
+	if err != nil {
+		return err
+	}
+
 	inode, err := s.Get(ctx, cmd)
 	if errors.Is(err, errs.ErrNotFound) {
 		return nil
@@ -248,16 +344,199 @@ func (s *service) Remove(ctx context.Context, cmd *PathCmd) error {
 func (s *service) removeINode(ctx context.Context, inode *INode) error {
 	now := s.clock.Now()
 	err := s.storage.Patch(ctx, inode.ID(), map[string]any{
-		"deleted_at":       sqlstorage.SQLTime(now),
-		"last_modified_at": sqlstorage.SQLTime(now),
+		"deleted_at": sqlstorage.SQLTime(now),
 	})
 	if err != nil {
 		return errs.Internal(fmt.Errorf("failed to Patch: %w", err))
 	}
 
+	if inode.Parent() == nil {
+		return nil
+	}
+
+	// XXX:MULTI-WRITE
+	//
+	// The inode is already in the trash, only the parents size is wrong if the
+	// task registration fails.
+	err = s.scheduler.RegisterFSRefreshSizeTask(ctx, &scheduler.FSRefreshSizeArg{
+		INode:      *inode.Parent(),
+		ModifiedAt: now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register the fs-refresh-size task: %w", err)
+	}
+
+	return nil
+}
+
+func (s *service) ListDeleted(ctx context.Context, space *spaces.Space, cmd *sqlstorage.PaginateCmd) ([]INode, error) {
+	res, err := s.storage.GetAllSpaceDeleted(ctx, space.ID(), cmd)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllSpaceDeleted: %w", err))
+	}
+
+	return res, nil
+}
+
+// GetOriginalPath returns the path of the directory containing the inode at
+// the time it was removed.
+//
+// If one of the parents have already been purged from the trash, the space
+// root is returned.
+func (s *service) GetOriginalPath(ctx context.Context, inode *INode) (string, error) {
+	names := []string{}
+
+	parentID := inode.Parent()
+	for parentID != nil {
+		parent, err := s.storage.GetByID(ctx, *parentID)
+		if errors.Is(err, errNotFound) {
+			return "/", nil
+		}
+
+		if err != nil {
+			return "", errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
+		}
+
+		names = append([]string{parent.Name()}, names...)
+		parentID = parent.Parent()
+	}
+
+	return CleanPath(path.Join(names...)), nil
+}
+
+// GetPathByID returns the path of the inode with the given id inside the space.
+//
+// An ErrNotFound is returned if the inode, or one of its parents, is inside the
+// trash.
+func (s *service) GetPathByID(ctx context.Context, space *spaces.Space, inodeID uuid.UUID) (*PathCmd, error) {
+	inode, err := s.storage.GetByID(ctx, inodeID)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(err)
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
+	}
+
+	if inode.SpaceID() != space.ID() {
+		return nil, errs.NotFound(ErrNotFound)
+	}
+
+	dirPath, err := s.GetOriginalPath(ctx, inode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetOriginalPath: %w", err)
+	}
+
+	cmd := NewPathCmd(space, path.Join(dirPath, inode.Name()))
+
+	// The path is computed from the parents ids but deleted inodes are still
+	// linked to their parents. Resolving the path ensures that the inode is
+	// still reachable.
+	res, err := s.Get(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get %q: %w", cmd.Path(), err)
+	}
+
+	if res.ID() != inode.ID() {
+		return nil, errs.NotFound(ErrNotFound)
+	}
+
+	return cmd, nil
+}
+
+func (s *service) Restore(ctx context.Context, cmd *RestoreCmd) (*INode, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	inode, err := s.storage.GetDeleted(ctx, cmd.INodeID)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(err)
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetDeleted: %w", err))
+	}
+
+	if inode.SpaceID() != cmd.Space.ID() || inode.Parent() == nil {
+		return nil, errs.NotFound(ErrNotFound)
+	}
+
+	err = s.checkSpaceWriteAccess(ctx, cmd.RestoredBy, cmd.Space.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	dirPath, err := s.GetOriginalPath(ctx, inode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetOriginalPath: %w", err)
+	}
+
+	// CreateDir is idempotent and recreates all the missing parents.
+	dir, err := s.CreateDir(ctx, &CreateDirCmd{
+		Path:      NewPathCmd(cmd.Space, dirPath),
+		CreatedBy: cmd.RestoredBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to CreateDir %q: %w", dirPath, err)
+	}
+
+	restored := *inode
+	restored.parent = ptr.To(dir.ID())
+
+	restored.name, err = s.findUniqueName(ctx, &restored, inode.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	// The content is unchanged, the modification date is kept.
+	restored.deletedAt = time.Time{}
+
+	err = s.storage.Patch(ctx, inode.ID(), map[string]any{
+		"parent":     dir.ID(),
+		"name":       restored.name,
+		"deleted_at": nil,
+	})
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to Patch: %w", err))
+	}
+
+	// XXX:MULTI-WRITE
+	//
+	// The inode is restored, only the parents size is wrong if the task
+	// registration fails.
+	err = s.scheduler.RegisterFSRefreshSizeTask(ctx, &scheduler.FSRefreshSizeArg{
+		INode:      dir.ID(),
+		ModifiedAt: s.clock.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register the fs-refresh-size task: %w", err)
+	}
+
+	return &restored, nil
+}
+
+func (s *service) EmptyTrash(ctx context.Context, user *users.User, space *spaces.Space) error {
+	err := s.checkSpaceWriteAccess(ctx, user, space.ID())
+	if err != nil {
+		return err
+	}
+
+	err = s.scheduler.RegisterFSEmptyTrashTask(ctx, &scheduler.FSEmptyTrashArgs{
+		SpaceID:   space.ID(),
+		EmptiedAt: s.clock.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register the fs-empty-trash task: %w", err)
+	}
+
 	return nil
 }
 
+// Move schedules the move of the inode at `cmd.Src` to `cmd.Dst`.
+//
+// The destination can be in an other space than the source.
 func (s *service) Move(ctx context.Context, cmd *MoveCmd) error {
 	err := cmd.Validate()
 	if err != nil {
@@ -268,17 +547,87 @@ func (s *service) Move(ctx context.Context, cmd *MoveCmd) error {
 		return nil
 	}
 
+	err = s.checkWriteAccess(ctx, cmd.MovedBy, cmd.Src)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkWriteAccess(ctx, cmd.MovedBy, cmd.Dst)
+	if err != nil {
+		return err
+	}
+
 	sourceINode, err := s.Get(ctx, cmd.Src)
 	if err != nil {
 		return fmt.Errorf("invalid source: %w", err)
 	}
 
+	// The moved inodes keep their creator, only the space usage changes.
+	if cmd.Src.Space().ID() != cmd.Dst.Space().ID() {
+		err = s.checkQuotas(ctx, cmd.Dst.Space(), nil, sourceINode.Size())
+		if err != nil {
+			return err
+		}
+	}
+
 	err = s.scheduler.RegisterFSMoveTask(ctx, &scheduler.FSMoveArgs{
-		SpaceID:     cmd.Src.Space().ID(),
-		SourceInode: sourceINode.ID(),
-		TargetPath:  cmd.Dst.Path(),
-		MovedAt:     s.clock.Now(),
-		MovedBy:     cmd.MovedBy.ID(),
+		SpaceID:       cmd.Src.Space().ID(),
+		SourceInode:   sourceINode.ID(),
+		TargetSpaceID: cmd.Dst.Space().ID(),
+		TargetPath:    cmd.Dst.Path(),
+		MovedAt:       s.clock.Now(),
+		MovedBy:       cmd.MovedBy.ID(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save the task: %w", err)
+	}
+
+	return nil
+}
+
+// Copy schedules the copy of the inode at `cmd.Src` to `cmd.Dst`.
+//
+// The copy is done by a "fs-copy" task which clones the inodes and reuses the
+// files of the source. A copy of a directory inside itself is refused.
+func (s *service) Copy(ctx context.Context, cmd *CopyCmd) error {
+	err := cmd.Validate()
+	if err != nil {
+		return errs.Validation(err)
+	}
+
+	if cmd.Src.Contains(*cmd.Dst) {
+		return errs.BadRequest(ErrInvalidPath, "can't copy %q inside itself", cmd.Src.Path())
+	}
+
+	err = s.checkReadAccess(ctx, cmd.CopiedBy, cmd.Src)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkWriteAccess(ctx, cmd.CopiedBy, cmd.Dst)
+	if err != nil {
+		return err
+	}
+
+	sourceINode, err := s.Get(ctx, cmd.Src)
+	if err != nil {
+		return fmt.Errorf("invalid source: %w", err)
+	}
+
+	// The copied inodes are created by the copier, they are accounted to its
+	// quota and to the space quota even if they share the files of the source.
+	err = s.checkQuotas(ctx, cmd.Dst.Space(), cmd.CopiedBy, sourceINode.Size())
+	if err != nil {
+		return err
+	}
+
+	err = s.scheduler.RegisterFSCopyTask(ctx, &scheduler.FSCopyArgs{
+		SpaceID:       cmd.Src.Space().ID(),
+		SourceInode:   sourceINode.ID(),
+		TargetSpaceID: cmd.Dst.Space().ID(),
+		TargetPath:    cmd.Dst.Path(),
+		CopiedAt:      s.clock.Now(),
+		CopiedBy:      cmd.CopiedBy.ID(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to save the task: %w", err)
@@ -355,6 +704,11 @@ func (s *service) Upload(ctx context.Context, cmd *UploadCmd) error {
 		return errs.Validation(err)
 	}
 
+	err = s.checkWriteAccess(ctx, cmd.UploadedBy, cmd.Path)
+	if err != nil {
+		return err
+	}
+
 	dirPath, fileName := path.Split(cmd.Path.Path())
 
 	dir, err := s.Get(ctx, NewPathCmd(cmd.Path.Space(), dirPath))
@@ -362,7 +716,29 @@ func (s *service) Upload(ctx context.Context, cmd *UploadCmd) error {
 		return fmt.Errorf("failed to get the directory: %w", err)
 	}
 
-	fileMeta, err := s.files.Upload(ctx, cmd.Content)
+	existingFile, err := s.storage.GetByNameAndParent(ctx, fileName, dir.ID())
+	if err != nil && !errors.Is(err, errNotFound) {
+		return errs.Internal(fmt.Errorf("failed to GetByNameAndParent: %w", err))
+	}
+
+	if existingFile != nil && existingFile.IsDir() {
+		return errs.BadRequest(ErrIsADir)
+	}
+
+	if existingFile != nil && cmd.CreateOnly {
+		return errs.BadRequest(ErrAlreadyExists, "%q already exists", cmd.Path.Path())
+	}
+
+	content, err := s.limitToQuotas(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	fileMeta, err := s.files.Upload(ctx, content)
+	if errors.Is(err, ErrQuotaExceeded) {
+		return errs.BadRequest(ErrQuotaExceeded, "the quota is exceeded")
+	}
+
 	if err != nil {
 		return fmt.Errorf("failed to Create file: %w", err)
 	}
@@ -370,6 +746,28 @@ func (s *service) Upload(ctx context.Context, cmd *UploadCmd) error {
 	ctx = context.WithoutCancel(ctx)
 	now := s.clock.Now()
 
+	modifiedAt := now
+	if !cmd.ModifiedAt.IsZero() {
+		modifiedAt = cmd.ModifiedAt
+	}
+
+	if existingFile != nil {
+		return s.overwrite(ctx, existingFile, fileMeta, cmd.UploadedBy, now, modifiedAt)
+	}
+
+	if cmd.CreateOnly {
+		// An other upload may have created the file during the transfer. The
+		// uploaded content is kept as it can be shared with other files.
+		existingFile, err = s.storage.GetByNameAndParent(ctx, fileName, dir.ID())
+		if err != nil && !errors.Is(err, errNotFound) {
+			return errs.Internal(fmt.Errorf("failed to GetByNameAndParent: %w", err))
+		}
+
+		if existingFile != nil {
+			return errs.BadRequest(ErrAlreadyExists, "%q already exists", cmd.Path.Path())
+		}
+	}
+
 	inode := INode{
 		id:             s.uuid.New(),
 		parent:         ptr.To(dir.ID()),
@@ -378,7 +776,7 @@ func (s *service) Upload(ctx context.Context, cmd *UploadCmd) error {
 		name:           fileName,
 		createdAt:      now,
 		createdBy:      cmd.UploadedBy.ID(),
-		lastModifiedAt: now,
+		lastModifiedAt: modifiedAt,
 		fileID:         ptr.To(fileMeta.ID()),
 	}
 
@@ -400,6 +798,741 @@ func (s *service) Upload(ctx context.Context, cmd *UploadCmd) error {
 	return nil
 }
 
+// limitToQuotas wraps the uploaded content in order to fail with
+// ErrQuotaExceeded as soon as the space quota or the uploader quota is
+// reached.
+//
+// The space usage is the size of the space root and the user usage is the
+// size of all the files created by the user. As those sizes are refreshed
+// asynchronously by the "fs-refresh-size" task, some concurrent uploads can
+// slightly exceed the quotas.
+//
+// An overwritten content is kept as a FileVersion so it is never considered
+// as freed: the versions are counted inside both usages.
+func (s *service) limitToQuotas(ctx context.Context, cmd *UploadCmd) (io.Reader, error) {
+	remaining, err := s.remainingQuota(ctx, cmd.Path.Space(), cmd.UploadedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if remaining == math.MaxUint64 {
+		return cmd.Content, nil
+	}
+
+	return &quotaReader{r: cmd.Content, remaining: remaining}, nil
+}
+
+// checkQuotas returns ErrQuotaExceeded if `size` bytes don't fit inside the
+// space quota and, if a user is given, inside the user quota.
+func (s *service) checkQuotas(ctx context.Context, space *spaces.Space, user *users.User, size uint64) error {
+	remaining, err := s.remainingQuota(ctx, space, user)
+	if err != nil {
+		return err
+	}
+
+	if size > remaining {
+		return errs.BadRequest(ErrQuotaExceeded, "the quota is exceeded")
+	}
+
+	return nil
+}
+
+// remainingQuota returns the number of bytes the user can still write inside
+// the space before reaching one of the quotas. The user quota is skipped for a
+// nil user. It returns math.MaxUint64 if there is no quota.
+func (s *service) remainingQuota(ctx context.Context, space *spaces.Space, user *users.User) (uint64, error) {
+	remaining := uint64(math.MaxUint64)
+
+	if quota := space.Quota(); quota > 0 {
+		root, err := s.storage.GetSpaceRoot(ctx, space.ID())
+		if err != nil {
+			return 0, errs.Internal(fmt.Errorf("failed to GetSpaceRoot: %w", err))
+		}
+
+		versionsSize, err := s.storage.GetSumSpaceVersionsSize(ctx, space.ID())
+		if err != nil {
+			return 0, errs.Internal(fmt.Errorf("failed to GetSumSpaceVersionsSize: %w", err))
+		}
+
+		used := root.Size() + versionsSize
+		if used > quota {
+			return 0, errs.BadRequest(ErrQuotaExceeded, "the space quota is exceeded")
+		}
+
+		remaining = quota - used
+	}
+
+	if user == nil {
+		return remaining, nil
+	}
+
+	if quota := user.Quota(); quota > 0 {
+		used, err := s.storage.GetSumUserFilesSize(ctx, user.ID())
+		if err != nil {
+			return 0, errs.Internal(fmt.Errorf("failed to GetSumUserFilesSize: %w", err))
+		}
+
+		if used > quota {
+			return 0, errs.BadRequest(ErrQuotaExceeded, "the user quota is exceeded")
+		}
+
+		remaining = min(remaining, quota-used)
+	}
+
+	return remaining, nil
+}
+
+// GetAvailableSpace returns the number of bytes the user can still write
+// inside the space. It's limited by the space and user quotas and by the free
+// space of the disk. It returns math.MaxUint64 if nothing limits it.
+func (s *service) GetAvailableSpace(ctx context.Context, user *users.User, space *spaces.Space) (uint64, error) {
+	remaining, err := s.remainingQuota(ctx, space, user)
+	if errors.Is(err, ErrQuotaExceeded) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	free, err := s.files.GetFreeSpace(ctx)
+	if errors.Is(err, files.ErrUnknownSpace) {
+		return remaining, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to GetFreeSpace: %w", err)
+	}
+
+	return min(remaining, free), nil
+}
+
+// Search looks for the inodes matching the query in all the spaces of the
+// user, or only inside `cmd.SpaceID` if set.
+func (s *service) Search(ctx context.Context, cmd *SearchCmd, paginateCmd *sqlstorage.PaginateCmd) ([]SearchResult, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	var spaceIDs []uuid.UUID
+	if cmd.SpaceID != "" {
+		space, err := s.spaces.GetUserSpace(ctx, cmd.User.ID(), cmd.SpaceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to GetUserSpace: %w", err)
+		}
+
+		spaceIDs = []uuid.UUID{space.ID()}
+	} else {
+		userSpaces, err := s.spaces.GetAllUserSpaces(ctx, cmd.User.ID(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to GetAllUserSpaces: %w", err)
+		}
+
+		for _, space := range userSpaces {
+			spaceIDs = append(spaceIDs, space.ID())
+		}
+	}
+
+	if len(spaceIDs) == 0 || matchQuery(cmd.Query) == "" {
+		return []SearchResult{}, nil
+	}
+
+	res, err := s.storage.Search(ctx, spaceIDs, cmd, paginateCmd)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to Search: %w", err))
+	}
+
+	return res, nil
+}
+
+// SearchTree looks for the inodes inside the `cmd.Root` folder matching
+// `cmd.Filter`.
+func (s *service) SearchTree(ctx context.Context, cmd *SearchTreeCmd, paginateCmd *sqlstorage.PaginateCmd) ([]SearchResult, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	root, err := s.Get(ctx, cmd.Root)
+	if errors.Is(err, errs.ErrNotFound) {
+		return nil, errs.NotFound(err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get inode: %w", err)
+	}
+
+	if !root.IsDir() {
+		return nil, errs.BadRequest(ErrIsNotDir)
+	}
+
+	res, err := s.storage.SearchTree(ctx, root, cmd.Root.Path(), cmd, paginateCmd)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to SearchTree: %w", err))
+	}
+
+	return res, nil
+}
+
+// GetUserUsage returns the number of bytes used by the files created by the
+// given user and by their versions across all the spaces.
+func (s *service) GetUserUsage(ctx context.Context, user *users.User) (uint64, error) {
+	size, err := s.storage.GetSumUserFilesSize(ctx, user.ID())
+	if err != nil {
+		return 0, errs.Internal(fmt.Errorf("failed to GetSumUserFilesSize: %w", err))
+	}
+
+	return size, nil
+}
+
+// overwrite replaces the content of an existing file. The previous content
+// is kept as a new FileVersion.
+//
+// The file takes the modifiedAt modification time while its parents are
+// modified at now.
+func (s *service) overwrite(ctx context.Context, inode *INode, newFile *files.FileMeta, user *users.User, now, modifiedAt time.Time) error {
+	if *inode.FileID() != newFile.ID() {
+		oldFileMeta, err := s.files.GetMetadata(ctx, *inode.FileID())
+		if err != nil {
+			return fmt.Errorf("failed to GetMetadata for the previous content: %w", err)
+		}
+
+		err = s.storage.SaveVersion(ctx, &FileVersion{
+			id:         s.uuid.New(),
+			inodeID:    inode.ID(),
+			fileID:     oldFileMeta.ID(),
+			size:       oldFileMeta.Size(),
+			modifiedAt: inode.LastModifiedAt(),
+			createdAt:  now,
+			createdBy:  user.ID(),
+		})
+		if err != nil {
+			return errs.Internal(fmt.Errorf("failed to SaveVersion: %w", err))
+		}
+	}
+
+	// XXX:MULTI-WRITE
+	//
+	// If the patch fails the previous content is saved twice: inside the
+	// version and inside the inode. This is not an issue as a retry will
+	// create a new valid version.
+	err := s.storage.Patch(ctx, inode.ID(), map[string]any{
+		"file_id":          newFile.ID(),
+		"last_modified_at": modifiedAt,
+	})
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to Patch: %w", err))
+	}
+
+	err = s.scheduler.RegisterFSRefreshSizeTask(ctx, &scheduler.FSRefreshSizeArg{
+		INode:      inode.ID(),
+		ModifiedAt: now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register the fs-refresh-size task: %w", err)
+	}
+
+	return nil
+}
+
+// ListVersions returns the past versions of a file, the most recent first.
+func (s *service) ListVersions(ctx context.Context, inode *INode) ([]FileVersion, error) {
+	if inode.IsDir() {
+		return nil, errs.BadRequest(ErrIsADir)
+	}
+
+	res, err := s.storage.GetAllINodeVersions(ctx, inode.ID())
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllINodeVersions: %w", err))
+	}
+
+	return res, nil
+}
+
+// GetVersion returns the version with the given id if it belongs to the inode.
+func (s *service) GetVersion(ctx context.Context, inode *INode, versionID uuid.UUID) (*FileVersion, error) {
+	res, err := s.storage.GetVersionByID(ctx, versionID)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(err)
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetVersionByID: %w", err))
+	}
+
+	if res.INodeID() != inode.ID() {
+		return nil, errs.NotFound(fmt.Errorf("version %q is not a version of %q", versionID, inode.ID()))
+	}
+
+	return res, nil
+}
+
+func (s *service) DownloadVersion(ctx context.Context, version *FileVersion) (io.ReadSeekCloser, error) {
+	fileMeta, err := s.files.GetMetadata(ctx, version.FileID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetMetadata: %w", err)
+	}
+
+	fileReader, err := s.files.Download(ctx, fileMeta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Open file %q: %w", version.FileID(), err)
+	}
+
+	return fileReader, nil
+}
+
+// RestoreVersion sets back the content of a version as the current content
+// of the file. The current content is saved as a new version.
+func (s *service) RestoreVersion(ctx context.Context, cmd *RestoreVersionCmd) (*INode, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	err = s.checkINodeWriteAccess(ctx, cmd.RestoredBy, cmd.INode)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.GetVersion(ctx, cmd.INode, cmd.VersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	fileMeta, err := s.files.GetMetadata(ctx, version.FileID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetMetadata: %w", err)
+	}
+
+	now := s.clock.Now()
+
+	err = s.overwrite(ctx, cmd.INode, fileMeta, cmd.RestoredBy, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// XXX:MULTI-WRITE
+	//
+	// The restored content is now the current content, its version is useless.
+	err = s.storage.DeleteVersion(ctx, version.ID())
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to DeleteVersion: %w", err))
+	}
+
+	res, err := s.storage.GetByID(ctx, cmd.INode.ID())
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
+	}
+
+	return res, nil
+}
+
+// CreateGrant gives to a user an access to a folder and all its content without
+// giving an access to the whole space.
+func (s *service) CreateGrant(ctx context.Context, cmd *CreateGrantCmd) (*Grant, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	err = s.checkManageAccess(ctx, cmd.CreatedBy, cmd.Path.Space().ID())
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Path.Path() == "/" {
+		return nil, errs.BadRequest(ErrGrantRoot)
+	}
+
+	inode, err := s.Get(ctx, cmd.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get: %w", err)
+	}
+
+	if !inode.IsDir() {
+		return nil, errs.BadRequest(ErrIsNotDir)
+	}
+
+	existingGrant, err := s.storage.GetGrantByINodeAndUser(ctx, inode.ID(), cmd.User.ID())
+	if err != nil && !errors.Is(err, errNotFound) {
+		return nil, errs.Internal(fmt.Errorf("failed to GetGrantByINodeAndUser: %w", err))
+	}
+
+	if existingGrant != nil {
+		return nil, errs.BadRequest(ErrAlreadyGranted)
+	}
+
+	grant := Grant{
+		id:         s.uuid.New(),
+		spaceID:    inode.SpaceID(),
+		inodeID:    inode.ID(),
+		userID:     cmd.User.ID(),
+		permission: cmd.Permission,
+		createdAt:  s.clock.Now(),
+		createdBy:  cmd.CreatedBy.ID(),
+	}
+
+	err = s.storage.SaveGrant(ctx, &grant)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to SaveGrant: %w", err))
+	}
+
+	return &grant, nil
+}
+
+// GetUserGrants returns all the folders shared with the user.
+func (s *service) GetUserGrants(ctx context.Context, user *users.User) ([]Grant, error) {
+	res, err := s.storage.GetAllUserGrants(ctx, user.ID())
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllUserGrants: %w", err))
+	}
+
+	return res, nil
+}
+
+// GetUserGrant returns the grant with the given id only if it have been given to
+// the user.
+func (s *service) GetUserGrant(ctx context.Context, userID, grantID uuid.UUID) (*Grant, error) {
+	res, err := s.storage.GetGrantByID(ctx, grantID)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(err)
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetGrantByID: %w", err))
+	}
+
+	if res.UserID() != userID {
+		return nil, errs.NotFound(ErrNotFound)
+	}
+
+	return res, nil
+}
+
+// GetINodeGrants returns all the users having an access to the given folder.
+//
+// Only the space managers are allowed to list them.
+func (s *service) GetINodeGrants(ctx context.Context, user *users.User, cmd *PathCmd) ([]Grant, error) {
+	err := s.checkManageAccess(ctx, user, cmd.Space().ID())
+	if err != nil {
+		return nil, err
+	}
+
+	inode, err := s.Get(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get: %w", err)
+	}
+
+	res, err := s.storage.GetAllINodeGrants(ctx, inode.ID())
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllINodeGrants: %w", err))
+	}
+
+	return res, nil
+}
+
+// DeleteGrant removes an access to a folder.
+//
+// A grant can be removed by the user having it or by a space manager.
+func (s *service) DeleteGrant(ctx context.Context, user *users.User, grantID uuid.UUID) error {
+	grant, err := s.storage.GetGrantByID(ctx, grantID)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to GetGrantByID: %w", err))
+	}
+
+	if grant.UserID() != user.ID() {
+		err = s.checkManageAccess(ctx, user, grant.SpaceID())
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.storage.DeleteGrant(ctx, grant.ID())
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to DeleteGrant: %w", err))
+	}
+
+	return nil
+}
+
+// DeleteAllUserGrants removes all the accesses given to a user. It must be
+// called before the user deletion.
+func (s *service) DeleteAllUserGrants(ctx context.Context, userID uuid.UUID) error {
+	err := s.storage.DeleteAllUserGrants(ctx, userID)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to DeleteAllUserGrants: %w", err))
+	}
+
+	return nil
+}
+
+// GetGrantPath returns the current path of the shared folder.
+//
+// The grantee can access any path contained by the returned path. The
+// [PathCmd] cleaning ensures that a path joined to this one can't escape it.
+func (s *service) GetGrantPath(ctx context.Context, grant *Grant) (*PathCmd, error) {
+	space, err := s.spaces.GetByID(ctx, grant.SpaceID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the space: %w", err)
+	}
+
+	res, err := s.GetPathByID(ctx, space, grant.INodeID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetPathByID: %w", err)
+	}
+
+	return res, nil
+}
+
+// GetProps returns all the properties attached to the inode.
+func (s *service) GetProps(ctx context.Context, inode *INode) ([]Property, error) {
+	res, err := s.storage.GetAllINodeProps(ctx, inode.ID())
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllINodeProps: %w", err))
+	}
+
+	return res, nil
+}
+
+// GetAllProps returns the properties of all the given inodes in a single
+// query. The inodes without any property are absent from the result.
+func (s *service) GetAllProps(ctx context.Context, inodes []INode) (map[uuid.UUID][]Property, error) {
+	ids := make([]uuid.UUID, len(inodes))
+	for i, inode := range inodes {
+		ids[i] = inode.ID()
+	}
+
+	props, err := s.storage.GetAllINodesProps(ctx, ids)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllINodesProps: %w", err))
+	}
+
+	res := make(map[uuid.UUID][]Property, len(inodes))
+	for _, prop := range props {
+		res[prop.inodeID] = append(res[prop.inodeID], prop)
+	}
+
+	return res, nil
+}
+
+// PatchProps sets and removes the properties of the inode at the given path.
+// The patches are applied in order.
+func (s *service) PatchProps(ctx context.Context, cmd *PatchPropsCmd) error {
+	err := cmd.Validate()
+	if err != nil {
+		return errs.Validation(err)
+	}
+
+	err = s.checkWriteAccess(ctx, cmd.PatchedBy, cmd.Path)
+	if err != nil {
+		return err
+	}
+
+	inode, err := s.Get(ctx, cmd.Path)
+	if err != nil {
+		return fmt.Errorf("failed to Get: %w", err)
+	}
+
+	// XXX:MULTI-WRITE
+	//
+	// The patches are independent from each other and they are all idempotent
+	// so the client can safely retry the whole request in case of error.
+	for _, patch := range cmd.Patches {
+		if patch.Remove {
+			err = s.storage.DeleteProp(ctx, inode.ID(), patch.Namespace, patch.Name)
+			if err != nil {
+				return errs.Internal(fmt.Errorf("failed to DeleteProp: %w", err))
+			}
+
+			continue
+		}
+
+		err = s.storage.SaveProp(ctx, &Property{
+			inodeID:   inode.ID(),
+			namespace: patch.Namespace,
+			name:      patch.Name,
+			lang:      patch.Lang,
+			value:     patch.Value,
+		})
+		if err != nil {
+			return errs.Internal(fmt.Errorf("failed to SaveProp: %w", err))
+		}
+	}
+
+	return nil
+}
+
+// checkSpaceWriteAccess returns an [errs.ErrUnauthorized] error if the user is
+// not allowed to modify the content of the whole space.
+func (s *service) checkSpaceWriteAccess(ctx context.Context, user *users.User, spaceID uuid.UUID) error {
+	role, err := s.spaces.GetUserRole(ctx, user.ID(), spaceID)
+	if err != nil {
+		return fmt.Errorf("failed to GetUserRole: %w", err)
+	}
+
+	if !role.CanWrite() {
+		return errs.Unauthorized(ErrReadOnly, "%q can't modify the space content", user.Username())
+	}
+
+	return nil
+}
+
+// checkWriteAccess returns an [errs.ErrUnauthorized] error if the user is not
+// allowed to modify the given path.
+//
+// The space members are checked first then the write grants of the user. A grant
+// allows to modify the content of the shared folder but not the folder itself.
+func (s *service) checkWriteAccess(ctx context.Context, user *users.User, cmd *PathCmd) error {
+	return s.checkGrantedWriteAccess(ctx, user, cmd, false)
+}
+
+// checkGrantedWriteAccess is [service.checkWriteAccess] with the possibility to
+// accept the shared folder itself.
+func (s *service) checkGrantedWriteAccess(ctx context.Context, user *users.User, cmd *PathCmd, withRoot bool) error {
+	spaceErr := s.checkSpaceWriteAccess(ctx, user, cmd.Space().ID())
+	if !errors.Is(spaceErr, errs.ErrUnauthorized) {
+		return spaceErr
+	}
+
+	grants, err := s.getUserWriteGrants(ctx, user, cmd.Space().ID())
+	if err != nil {
+		return err
+	}
+
+	for _, grant := range grants {
+		root, err := s.GetPathByID(ctx, cmd.Space(), grant.INodeID())
+		if errors.Is(err, errs.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to GetPathByID: %w", err)
+		}
+
+		if root.Contains(*cmd) && (withRoot || !root.Equal(*cmd)) {
+			return nil
+		}
+	}
+
+	return spaceErr
+}
+
+// checkReadAccess returns an [errs.ErrUnauthorized] error if the user is
+// neither a member of the space nor granted a folder containing the given path.
+func (s *service) checkReadAccess(ctx context.Context, user *users.User, cmd *PathCmd) error {
+	_, spaceErr := s.spaces.GetUserRole(ctx, user.ID(), cmd.Space().ID())
+	if !errors.Is(spaceErr, errs.ErrUnauthorized) {
+		if spaceErr != nil {
+			return fmt.Errorf("failed to GetUserRole: %w", spaceErr)
+		}
+
+		return nil
+	}
+
+	grants, err := s.storage.GetAllUserGrants(ctx, user.ID())
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to GetAllUserGrants: %w", err))
+	}
+
+	for _, grant := range grants {
+		if grant.SpaceID() != cmd.Space().ID() {
+			continue
+		}
+
+		root, err := s.GetPathByID(ctx, cmd.Space(), grant.INodeID())
+		if errors.Is(err, errs.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to GetPathByID: %w", err)
+		}
+
+		if root.Contains(*cmd) {
+			return nil
+		}
+	}
+
+	return spaceErr
+}
+
+// checkINodeWriteAccess does the same checks than [service.checkWriteAccess]
+// for an already resolved inode.
+func (s *service) checkINodeWriteAccess(ctx context.Context, user *users.User, inode *INode) error {
+	spaceErr := s.checkSpaceWriteAccess(ctx, user, inode.SpaceID())
+	if !errors.Is(spaceErr, errs.ErrUnauthorized) {
+		return spaceErr
+	}
+
+	grants, err := s.getUserWriteGrants(ctx, user, inode.SpaceID())
+	if err != nil {
+		return err
+	}
+
+	if len(grants) == 0 {
+		return spaceErr
+	}
+
+	grantedINodes := make(map[uuid.UUID]struct{}, len(grants))
+	for _, grant := range grants {
+		grantedINodes[grant.INodeID()] = struct{}{}
+	}
+
+	for parentID := inode.Parent(); parentID != nil; {
+		if _, ok := grantedINodes[*parentID]; ok {
+			return nil
+		}
+
+		parent, err := s.storage.GetByID(ctx, *parentID)
+		if err != nil {
+			return errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
+		}
+
+		parentID = parent.Parent()
+	}
+
+	return spaceErr
+}
+
+func (s *service) getUserWriteGrants(ctx context.Context, user *users.User, spaceID uuid.UUID) ([]Grant, error) {
+	grants, err := s.storage.GetAllUserGrants(ctx, user.ID())
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllUserGrants: %w", err))
+	}
+
+	res := []Grant{}
+	for _, grant := range grants {
+		if grant.SpaceID() == spaceID && grant.Permission().CanWrite() {
+			res = append(res, grant)
+		}
+	}
+
+	return res, nil
+}
+
+// checkManageAccess returns an [errs.ErrUnauthorized] error if the user is not
+// allowed to manage the accesses of the space.
+func (s *service) checkManageAccess(ctx context.Context, user *users.User, spaceID uuid.UUID) error {
+	if user.IsAdmin() {
+		return nil
+	}
+
+	role, err := s.spaces.GetUserRole(ctx, user.ID(), spaceID)
+	if err != nil {
+		return fmt.Errorf("failed to GetUserRole: %w", err)
+	}
+
+	if !role.CanManage() {
+		return errs.Unauthorized(spaces.ErrNotManager)
+	}
+
+	return nil
+}
+
 func (s *service) createDir(ctx context.Context, createdBy *users.User, parent *INode, name string) (*INode, error) {
 	if !parent.IsDir() {
 		return nil, errs.BadRequest(ErrIsNotDir)
@@ -12,7 +12,11 @@ import (
 
 	sqlstorage "github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
 
+	time "time"
+
 	users "github.com/theduckcompany/duckcloud/internal/service/users"
+
+	uuid "github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
 // MockService is an autogenerated mock type for the Service type
@@ -20,6 +24,20 @@ type MockService struct {
 	mock.Mock
 }
 
+// Copy provides a mock function with given fields: ctx, cmd
+func (_m *MockService) Copy(ctx context.Context, cmd *CopyCmd) error {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *CopyCmd) error); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateDir provides a mock function with given fields: ctx, cmd
 func (_m *MockService) CreateDir(ctx context.Context, cmd *CreateDirCmd) (*INode, error) {
 	ret := _m.Called(ctx, cmd)
@@ -72,6 +90,60 @@ func (_m *MockService) CreateFS(ctx context.Context, user *users.User, space *sp
 	return r0, r1
 }
 
+// CreateGrant provides a mock function with given fields: ctx, cmd
+func (_m *MockService) CreateGrant(ctx context.Context, cmd *CreateGrantCmd) (*Grant, error) {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 *Grant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *CreateGrantCmd) (*Grant, error)); ok {
+		return rf(ctx, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *CreateGrantCmd) *Grant); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Grant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *CreateGrantCmd) error); ok {
+		r1 = rf(ctx, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteAllUserGrants provides a mock function with given fields: ctx, userID
+func (_m *MockService) DeleteAllUserGrants(ctx context.Context, userID uuid.UUID) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteGrant provides a mock function with given fields: ctx, user, grantID
+func (_m *MockService) DeleteGrant(ctx context.Context, user *users.User, grantID uuid.UUID) error {
+	ret := _m.Called(ctx, user, grantID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, uuid.UUID) error); ok {
+		r0 = rf(ctx, user, grantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Destroy provides a mock function with given fields: ctx, user, space
 func (_m *MockService) Destroy(ctx context.Context, user *users.User, space *spaces.Space) error {
 	ret := _m.Called(ctx, user, space)
@@ -112,6 +184,46 @@ func (_m *MockService) Download(ctx context.Context, cmd *PathCmd) (io.ReadSeekC
 	return r0, r1
 }
 
+// DownloadVersion provides a mock function with given fields: ctx, version
+func (_m *MockService) DownloadVersion(ctx context.Context, version *FileVersion) (io.ReadSeekCloser, error) {
+	ret := _m.Called(ctx, version)
+
+	var r0 io.ReadSeekCloser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *FileVersion) (io.ReadSeekCloser, error)); ok {
+		return rf(ctx, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *FileVersion) io.ReadSeekCloser); ok {
+		r0 = rf(ctx, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadSeekCloser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *FileVersion) error); ok {
+		r1 = rf(ctx, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EmptyTrash provides a mock function with given fields: ctx, user, space
+func (_m *MockService) EmptyTrash(ctx context.Context, user *users.User, space *spaces.Space) error {
+	ret := _m.Called(ctx, user, space)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, *spaces.Space) error); ok {
+		r0 = rf(ctx, user, space)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: ctx, cmd
 func (_m *MockService) Get(ctx context.Context, cmd *PathCmd) (*INode, error) {
 	ret := _m.Called(ctx, cmd)
@@ -138,6 +250,338 @@ func (_m *MockService) Get(ctx context.Context, cmd *PathCmd) (*INode, error) {
 	return r0, r1
 }
 
+// GetAllProps provides a mock function with given fields: ctx, inodes
+func (_m *MockService) GetAllProps(ctx context.Context, inodes []INode) (map[uuid.UUID][]Property, error) {
+	ret := _m.Called(ctx, inodes)
+
+	var r0 map[uuid.UUID][]Property
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []INode) (map[uuid.UUID][]Property, error)); ok {
+		return rf(ctx, inodes)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []INode) map[uuid.UUID][]Property); ok {
+		r0 = rf(ctx, inodes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID][]Property)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []INode) error); ok {
+		r1 = rf(ctx, inodes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAvailableSpace provides a mock function with given fields: ctx, user, space
+func (_m *MockService) GetAvailableSpace(ctx context.Context, user *users.User, space *spaces.Space) (uint64, error) {
+	ret := _m.Called(ctx, user, space)
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, *spaces.Space) (uint64, error)); ok {
+		return rf(ctx, user, space)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, *spaces.Space) uint64); ok {
+		r0 = rf(ctx, user, space)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *users.User, *spaces.Space) error); ok {
+		r1 = rf(ctx, user, space)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGrantPath provides a mock function with given fields: ctx, grant
+func (_m *MockService) GetGrantPath(ctx context.Context, grant *Grant) (*PathCmd, error) {
+	ret := _m.Called(ctx, grant)
+
+	var r0 *PathCmd
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *Grant) (*PathCmd, error)); ok {
+		return rf(ctx, grant)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *Grant) *PathCmd); ok {
+		r0 = rf(ctx, grant)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*PathCmd)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *Grant) error); ok {
+		r1 = rf(ctx, grant)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetINodeGrants provides a mock function with given fields: ctx, user, cmd
+func (_m *MockService) GetINodeGrants(ctx context.Context, user *users.User, cmd *PathCmd) ([]Grant, error) {
+	ret := _m.Called(ctx, user, cmd)
+
+	var r0 []Grant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, *PathCmd) ([]Grant, error)); ok {
+		return rf(ctx, user, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, *PathCmd) []Grant); ok {
+		r0 = rf(ctx, user, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Grant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *users.User, *PathCmd) error); ok {
+		r1 = rf(ctx, user, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOriginalPath provides a mock function with given fields: ctx, inode
+func (_m *MockService) GetOriginalPath(ctx context.Context, inode *INode) (string, error) {
+	ret := _m.Called(ctx, inode)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *INode) (string, error)); ok {
+		return rf(ctx, inode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *INode) string); ok {
+		r0 = rf(ctx, inode)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *INode) error); ok {
+		r1 = rf(ctx, inode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPathByID provides a mock function with given fields: ctx, space, inodeID
+func (_m *MockService) GetPathByID(ctx context.Context, space *spaces.Space, inodeID uuid.UUID) (*PathCmd, error) {
+	ret := _m.Called(ctx, space, inodeID)
+
+	var r0 *PathCmd
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *spaces.Space, uuid.UUID) (*PathCmd, error)); ok {
+		return rf(ctx, space, inodeID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *spaces.Space, uuid.UUID) *PathCmd); ok {
+		r0 = rf(ctx, space, inodeID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*PathCmd)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *spaces.Space, uuid.UUID) error); ok {
+		r1 = rf(ctx, space, inodeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProps provides a mock function with given fields: ctx, inode
+func (_m *MockService) GetProps(ctx context.Context, inode *INode) ([]Property, error) {
+	ret := _m.Called(ctx, inode)
+
+	var r0 []Property
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *INode) ([]Property, error)); ok {
+		return rf(ctx, inode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *INode) []Property); ok {
+		r0 = rf(ctx, inode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Property)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *INode) error); ok {
+		r1 = rf(ctx, inode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserGrant provides a mock function with given fields: ctx, userID, grantID
+func (_m *MockService) GetUserGrant(ctx context.Context, userID uuid.UUID, grantID uuid.UUID) (*Grant, error) {
+	ret := _m.Called(ctx, userID, grantID)
+
+	var r0 *Grant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (*Grant, error)); ok {
+		return rf(ctx, userID, grantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) *Grant); ok {
+		r0 = rf(ctx, userID, grantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Grant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, userID, grantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserGrants provides a mock function with given fields: ctx, user
+func (_m *MockService) GetUserGrants(ctx context.Context, user *users.User) ([]Grant, error) {
+	ret := _m.Called(ctx, user)
+
+	var r0 []Grant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User) ([]Grant, error)); ok {
+		return rf(ctx, user)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User) []Grant); ok {
+		r0 = rf(ctx, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Grant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *users.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserUsage provides a mock function with given fields: ctx, user
+func (_m *MockService) GetUserUsage(ctx context.Context, user *users.User) (uint64, error) {
+	ret := _m.Called(ctx, user)
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User) (uint64, error)); ok {
+		return rf(ctx, user)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User) uint64); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *users.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetVersion provides a mock function with given fields: ctx, inode, versionID
+func (_m *MockService) GetVersion(ctx context.Context, inode *INode, versionID uuid.UUID) (*FileVersion, error) {
+	ret := _m.Called(ctx, inode, versionID)
+
+	var r0 *FileVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *INode, uuid.UUID) (*FileVersion, error)); ok {
+		return rf(ctx, inode, versionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *INode, uuid.UUID) *FileVersion); ok {
+		r0 = rf(ctx, inode, versionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*FileVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *INode, uuid.UUID) error); ok {
+		r1 = rf(ctx, inode, versionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListChildren provides a mock function with given fields: ctx, dir, paginateCmd
+func (_m *MockService) ListChildren(ctx context.Context, dir *INode, paginateCmd *sqlstorage.PaginateCmd) ([]INode, error) {
+	ret := _m.Called(ctx, dir, paginateCmd)
+
+	var r0 []INode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *INode, *sqlstorage.PaginateCmd) ([]INode, error)); ok {
+		return rf(ctx, dir, paginateCmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *INode, *sqlstorage.PaginateCmd) []INode); ok {
+		r0 = rf(ctx, dir, paginateCmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]INode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *INode, *sqlstorage.PaginateCmd) error); ok {
+		r1 = rf(ctx, dir, paginateCmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDeleted provides a mock function with given fields: ctx, space, cmd
+func (_m *MockService) ListDeleted(ctx context.Context, space *spaces.Space, cmd *sqlstorage.PaginateCmd) ([]INode, error) {
+	ret := _m.Called(ctx, space, cmd)
+
+	var r0 []INode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *spaces.Space, *sqlstorage.PaginateCmd) ([]INode, error)); ok {
+		return rf(ctx, space, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *spaces.Space, *sqlstorage.PaginateCmd) []INode); ok {
+		r0 = rf(ctx, space, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]INode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *spaces.Space, *sqlstorage.PaginateCmd) error); ok {
+		r1 = rf(ctx, space, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListDir provides a mock function with given fields: ctx, cmd, paginateCmd
 func (_m *MockService) ListDir(ctx context.Context, cmd *PathCmd, paginateCmd *sqlstorage.PaginateCmd) ([]INode, error) {
 	ret := _m.Called(ctx, cmd, paginateCmd)
@@ -164,6 +608,32 @@ func (_m *MockService) ListDir(ctx context.Context, cmd *PathCmd, paginateCmd *s
 	return r0, r1
 }
 
+// ListVersions provides a mock function with given fields: ctx, inode
+func (_m *MockService) ListVersions(ctx context.Context, inode *INode) ([]FileVersion, error) {
+	ret := _m.Called(ctx, inode)
+
+	var r0 []FileVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *INode) ([]FileVersion, error)); ok {
+		return rf(ctx, inode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *INode) []FileVersion); ok {
+		r0 = rf(ctx, inode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]FileVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *INode) error); ok {
+		r1 = rf(ctx, inode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Move provides a mock function with given fields: ctx, cmd
 func (_m *MockService) Move(ctx context.Context, cmd *MoveCmd) error {
 	ret := _m.Called(ctx, cmd)
@@ -178,12 +648,12 @@ func (_m *MockService) Move(ctx context.Context, cmd *MoveCmd) error {
 	return r0
 }
 
-// Remove provides a mock function with given fields: ctx, cmd
-func (_m *MockService) Remove(ctx context.Context, cmd *PathCmd) error {
+// PatchProps provides a mock function with given fields: ctx, cmd
+func (_m *MockService) PatchProps(ctx context.Context, cmd *PatchPropsCmd) error {
 	ret := _m.Called(ctx, cmd)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *PathCmd) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *PatchPropsCmd) error); ok {
 		r0 = rf(ctx, cmd)
 	} else {
 		r0 = ret.Error(0)
@@ -192,25 +662,169 @@ func (_m *MockService) Remove(ctx context.Context, cmd *PathCmd) error {
 	return r0
 }
 
-// Rename provides a mock function with given fields: ctx, inode, newName
-func (_m *MockService) Rename(ctx context.Context, inode *INode, newName string) (*INode, error) {
-	ret := _m.Called(ctx, inode, newName)
+// Remove provides a mock function with given fields: ctx, user, cmd
+func (_m *MockService) Remove(ctx context.Context, user *users.User, cmd *PathCmd) error {
+	ret := _m.Called(ctx, user, cmd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, *PathCmd) error); ok {
+		r0 = rf(ctx, user, cmd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Rename provides a mock function with given fields: ctx, user, inode, newName
+func (_m *MockService) Rename(ctx context.Context, user *users.User, inode *INode, newName string) (*INode, error) {
+	ret := _m.Called(ctx, user, inode, newName)
+
+	var r0 *INode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, *INode, string) (*INode, error)); ok {
+		return rf(ctx, user, inode, newName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, *INode, string) *INode); ok {
+		r0 = rf(ctx, user, inode, newName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*INode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *users.User, *INode, string) error); ok {
+		r1 = rf(ctx, user, inode, newName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, cmd
+func (_m *MockService) Restore(ctx context.Context, cmd *RestoreCmd) (*INode, error) {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 *INode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *RestoreCmd) (*INode, error)); ok {
+		return rf(ctx, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *RestoreCmd) *INode); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*INode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *RestoreCmd) error); ok {
+		r1 = rf(ctx, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreVersion provides a mock function with given fields: ctx, cmd
+func (_m *MockService) RestoreVersion(ctx context.Context, cmd *RestoreVersionCmd) (*INode, error) {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 *INode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *RestoreVersionCmd) (*INode, error)); ok {
+		return rf(ctx, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *RestoreVersionCmd) *INode); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*INode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *RestoreVersionCmd) error); ok {
+		r1 = rf(ctx, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, cmd, paginateCmd
+func (_m *MockService) Search(ctx context.Context, cmd *SearchCmd, paginateCmd *sqlstorage.PaginateCmd) ([]SearchResult, error) {
+	ret := _m.Called(ctx, cmd, paginateCmd)
+
+	var r0 []SearchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *SearchCmd, *sqlstorage.PaginateCmd) ([]SearchResult, error)); ok {
+		return rf(ctx, cmd, paginateCmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *SearchCmd, *sqlstorage.PaginateCmd) []SearchResult); ok {
+		r0 = rf(ctx, cmd, paginateCmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]SearchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *SearchCmd, *sqlstorage.PaginateCmd) error); ok {
+		r1 = rf(ctx, cmd, paginateCmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchTree provides a mock function with given fields: ctx, cmd, paginateCmd
+func (_m *MockService) SearchTree(ctx context.Context, cmd *SearchTreeCmd, paginateCmd *sqlstorage.PaginateCmd) ([]SearchResult, error) {
+	ret := _m.Called(ctx, cmd, paginateCmd)
+
+	var r0 []SearchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *SearchTreeCmd, *sqlstorage.PaginateCmd) ([]SearchResult, error)); ok {
+		return rf(ctx, cmd, paginateCmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *SearchTreeCmd, *sqlstorage.PaginateCmd) []SearchResult); ok {
+		r0 = rf(ctx, cmd, paginateCmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]SearchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *SearchTreeCmd, *sqlstorage.PaginateCmd) error); ok {
+		r1 = rf(ctx, cmd, paginateCmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetModifiedAt provides a mock function with given fields: ctx, user, inode, modifiedAt
+func (_m *MockService) SetModifiedAt(ctx context.Context, user *users.User, inode *INode, modifiedAt time.Time) (*INode, error) {
+	ret := _m.Called(ctx, user, inode, modifiedAt)
 
 	var r0 *INode
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *INode, string) (*INode, error)); ok {
-		return rf(ctx, inode, newName)
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, *INode, time.Time) (*INode, error)); ok {
+		return rf(ctx, user, inode, modifiedAt)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *INode, string) *INode); ok {
-		r0 = rf(ctx, inode, newName)
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, *INode, time.Time) *INode); ok {
+		r0 = rf(ctx, user, inode, modifiedAt)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*INode)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *INode, string) error); ok {
-		r1 = rf(ctx, inode, newName)
+	if rf, ok := ret.Get(1).(func(context.Context, *users.User, *INode, time.Time) error); ok {
+		r1 = rf(ctx, user, inode, modifiedAt)
 	} else {
 		r1 = ret.Error(1)
 	}
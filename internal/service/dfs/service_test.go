@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"math"
 	"testing"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
 func Test_DFS_Service(t *testing.T) {
@@ -82,6 +85,8 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
 		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
 		// Call twice: The first one for the walk function in order to check if this is a directory, the second one by createDir
 		// in order to check if the directory already exists.
@@ -124,6 +129,8 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
 		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
 		storageMock.On("GetByNameAndParent", mock.Anything, "some-dir-name", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
 
@@ -144,6 +151,8 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
 		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
 		storageMock.On("GetByNameAndParent", mock.Anything, "some-dir-name", ExampleAliceRoot.ID()).
 			Return(nil, errs.Internal(fmt.Errorf("some-error"))).Once()
@@ -165,6 +174,8 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
 		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
 
 		res, err := spaceFS.CreateDir(ctx, &CreateDirCmd{
@@ -183,18 +194,48 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
 		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
 		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
 		toolsMock.ClockMock.On("Now").Return(now).Once()
 		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
-			"deleted_at":       sqlstorage.SQLTime(now),
-			"last_modified_at": sqlstorage.SQLTime(now),
+			"deleted_at": sqlstorage.SQLTime(now),
+		}).Return(nil).Once()
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      *ExampleAliceFile.Parent(),
+			ModifiedAt: now,
 		}).Return(nil).Once()
 
-		err := spaceFS.Remove(ctx, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"))
+		err := spaceFS.Remove(ctx, &users.ExampleAlice, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"))
 		require.NoError(t, err)
 	})
 
+	t.Run("Remove with a RegisterFSRefreshSizeTask error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"deleted_at": sqlstorage.SQLTime(now),
+		}).Return(nil).Once()
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      *ExampleAliceFile.Parent(),
+			ModifiedAt: now,
+		}).Return(fmt.Errorf("some-error")).Once()
+
+		err := spaceFS.Remove(ctx, &users.ExampleAlice, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"))
+		require.ErrorContains(t, err, "some-error")
+	})
+
 	t.Run("Remove the root is forbidden", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
@@ -203,7 +244,7 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		err := spaceFS.Remove(ctx, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"))
+		err := spaceFS.Remove(ctx, &users.ExampleAlice, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"))
 		require.ErrorIs(t, err, errs.ErrUnauthorized)
 		require.ErrorContains(t, err, "can't remove /")
 	})
@@ -216,7 +257,7 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		err := spaceFS.Remove(ctx, NewPathCmd(&spaces.ExampleAlicePersonalSpace, ""))
+		err := spaceFS.Remove(ctx, &users.ExampleAlice, NewPathCmd(&spaces.ExampleAlicePersonalSpace, ""))
 		require.ErrorIs(t, err, errs.ErrUnauthorized)
 		require.ErrorContains(t, err, "can't remove /")
 	})
@@ -229,10 +270,12 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
 		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
 		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(nil, errs.ErrNotFound).Once()
 
-		err := spaceFS.Remove(ctx, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"))
+		err := spaceFS.Remove(ctx, &users.ExampleAlice, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"))
 		require.NoError(t, err)
 	})
 
@@ -244,10 +287,12 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
 		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
 		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(nil, errs.Internal(fmt.Errorf("some-error"))).Once()
 
-		err := spaceFS.Remove(ctx, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"))
+		err := spaceFS.Remove(ctx, &users.ExampleAlice, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"))
 		require.ErrorIs(t, err, errs.ErrInternal)
 		require.ErrorContains(t, err, "some-error")
 	})
@@ -260,19 +305,52 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
 		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
 		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
 		toolsMock.ClockMock.On("Now").Return(now).Once()
 		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
-			"deleted_at":       sqlstorage.SQLTime(now),
-			"last_modified_at": sqlstorage.SQLTime(now),
+			"deleted_at": sqlstorage.SQLTime(now),
 		}).Return(fmt.Errorf("some-error")).Once()
 
-		err := spaceFS.Remove(ctx, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"))
+		err := spaceFS.Remove(ctx, &users.ExampleAlice, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"))
 		require.ErrorIs(t, err, errs.ErrInternal)
 		require.ErrorContains(t, err, "some-error")
 	})
 
+	t.Run("Remove with a viewer role", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleViewer, nil).Once()
+		storageMock.On("GetAllUserGrants", mock.Anything, users.ExampleAlice.ID()).Return([]Grant{}, nil).Once()
+
+		err := spaceFS.Remove(ctx, &users.ExampleAlice, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"))
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrReadOnly)
+	})
+
+	t.Run("Remove with a GetUserRole error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.Role(""), errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
+		storageMock.On("GetAllUserGrants", mock.Anything, users.ExampleAlice.ID()).Return([]Grant{}, nil).Once()
+
+		err := spaceFS.Remove(ctx, &users.ExampleAlice, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"))
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, spaces.ErrInvalidSpaceAccess)
+	})
+
 	t.Run("ListDir success", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
@@ -293,6 +371,53 @@ func Test_DFS_Service(t *testing.T) {
 		assert.Equal(t, []INode{ExampleAliceFile}, res)
 	})
 
+	t.Run("ListChildren success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetAllChildrens", mock.Anything, ExampleAliceDir.ID(), &sqlstorage.PaginateCmd{Limit: 2}).
+			Return([]INode{ExampleAliceFile}, nil).Once()
+
+		res, err := spaceFS.ListChildren(ctx, &ExampleAliceDir, &sqlstorage.PaginateCmd{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []INode{ExampleAliceFile}, res)
+	})
+
+	t.Run("ListChildren with a file", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		res, err := spaceFS.ListChildren(ctx, &ExampleAliceFile, &sqlstorage.PaginateCmd{Limit: 2})
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrIsNotDir)
+	})
+
+	t.Run("ListChildren with a GetAllChildrens error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetAllChildrens", mock.Anything, ExampleAliceDir.ID(), &sqlstorage.PaginateCmd{Limit: 2}).
+			Return(nil, fmt.Errorf("some-error")).Once()
+
+		res, err := spaceFS.ListChildren(ctx, &ExampleAliceDir, &sqlstorage.PaginateCmd{Limit: 2})
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
 	t.Run("ListDir with an invalid path", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
@@ -374,6 +499,26 @@ func Test_DFS_Service(t *testing.T) {
 		assert.Equal(t, file, res)
 	})
 
+	t.Run("Upload with a viewer role", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleViewer, nil).Once()
+		storageMock.On("GetAllUserGrants", mock.Anything, users.ExampleAlice.ID()).Return([]Grant{}, nil).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/new.pdf"),
+			Content:    bytes.NewBufferString("Hello, World!"),
+			UploadedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrReadOnly)
+	})
+
 	t.Run("Upload success", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
@@ -382,11 +527,14 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
 		content := "Hello, World!"
 
 		// Get /foo
 		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
 		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(nil, errNotFound).Once()
 
 		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(&files.ExampleFile1, nil).Once()
 		toolsMock.ClockMock.On("Now").Return(ExampleAliceNewFile.createdAt).Once()
@@ -407,7 +555,7 @@ func Test_DFS_Service(t *testing.T) {
 		require.NoError(t, err)
 	})
 
-	t.Run("Upload with a validation error", func(t *testing.T) {
+	t.Run("Upload with a modification time given by the client", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -415,38 +563,41 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		err := spaceFS.Upload(ctx, &UploadCmd{
-			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar.txt"),
-			Content:    nil,
-			UploadedBy: &users.ExampleAlice,
-		})
-		require.ErrorIs(t, err, errs.ErrValidation)
-		require.EqualError(t, err, "validation: Content: cannot be blank.")
-	})
+		modifiedAt := time.Date(2015, time.March, 12, 10, 0, 0, 0, time.UTC)
 
-	t.Run("Upload with a non existing directory", func(t *testing.T) {
-		filesMock := files.NewMockService(t)
-		spacesMock := spaces.NewMockService(t)
-		schedulerMock := scheduler.NewMockService(t)
-		toolsMock := tools.NewMock(t)
-		storageMock := newMockStorage(t)
-		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
 
 		content := "Hello, World!"
 
 		// Get /foo
 		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
-		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(nil, errs.ErrNotFound).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(nil, errNotFound).Once()
+
+		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(&files.ExampleFile1, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(ExampleAliceNewFile.createdAt).Once()
+		toolsMock.UUIDMock.On("New").Return(ExampleAliceNewFile.ID()).Once()
+
+		newFile := ExampleAliceNewFile
+		newFile.lastModifiedAt = modifiedAt
+		storageMock.On("Save", mock.Anything, &newFile).Return(nil).Once()
+
+		// The parents are modified at the upload time.
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceNewFile.ID(),
+			ModifiedAt: ExampleAliceNewFile.createdAt,
+		}).Return(nil).Once()
 
 		err := spaceFS.Upload(ctx, &UploadCmd{
 			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new.pdf"),
 			Content:    bytes.NewBufferString(content),
 			UploadedBy: &users.ExampleAlice,
+			ModifiedAt: modifiedAt,
 		})
-		require.ErrorIs(t, err, errs.ErrNotFound)
+		require.NoError(t, err)
 	})
 
-	t.Run("Upload with a file upload error", func(t *testing.T) {
+	t.Run("Upload with a space already over its quota", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -454,24 +605,29 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		content := "Hello, World!"
+		space := spaces.NewFakeSpace(t).WithQuota(10).Build()
+		root := NewFakeINode(t).WithSpace(space).IsRootDirectory().WithSize(42).Build()
 
-		// Get /foo
-		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
-		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), space.ID()).Return(spaces.RoleEditor, nil).Once()
 
-		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(nil, errs.Internal(fmt.Errorf("some-error"))).Once()
+		// Get /
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", root.ID()).Return(nil, errNotFound).Once()
+
+		// Check the quota
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetSumSpaceVersionsSize", mock.Anything, space.ID()).Return(uint64(0), nil).Once()
 
 		err := spaceFS.Upload(ctx, &UploadCmd{
-			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new.pdf"),
-			Content:    bytes.NewBufferString(content),
+			Path:       NewPathCmd(space, "/new.pdf"),
+			Content:    bytes.NewBufferString("Hello, World!"),
 			UploadedBy: &users.ExampleAlice,
 		})
-		require.ErrorIs(t, err, errs.ErrInternal)
-		require.ErrorContains(t, err, "some-error")
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrQuotaExceeded)
 	})
 
-	t.Run("Upload with a Save error", func(t *testing.T) {
+	t.Run("Upload with a content exceeding the quota", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -479,28 +635,36 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		content := "Hello, World!"
+		space := spaces.NewFakeSpace(t).WithQuota(50).Build()
+		root := NewFakeINode(t).WithSpace(space).IsRootDirectory().WithSize(42).Build()
 
-		// Get /foo
-		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
-		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), space.ID()).Return(spaces.RoleEditor, nil).Once()
 
-		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(&files.ExampleFile1, nil).Once()
-		toolsMock.ClockMock.On("Now").Return(ExampleAliceNewFile.createdAt).Once()
-		toolsMock.UUIDMock.On("New").Return(ExampleAliceNewFile.ID()).Once()
+		// Get /
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", root.ID()).Return(nil, errNotFound).Once()
 
-		storageMock.On("Save", mock.Anything, &ExampleAliceNewFile).Return(fmt.Errorf("some-error")).Once()
+		// Check the quota
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetSumSpaceVersionsSize", mock.Anything, space.ID()).Return(uint64(0), nil).Once()
+
+		filesMock.On("Upload", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				_, err := io.ReadAll(args.Get(1).(io.Reader))
+				require.ErrorIs(t, err, ErrQuotaExceeded)
+			}).
+			Return(nil, errs.Internal(fmt.Errorf("upload error: %w", ErrQuotaExceeded))).Once()
 
 		err := spaceFS.Upload(ctx, &UploadCmd{
-			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo/new.pdf"),
-			Content:    bytes.NewBufferString(content),
+			Path:       NewPathCmd(space, "/new.pdf"),
+			Content:    bytes.NewBufferString("Hello, World!"), // 13 bytes
 			UploadedBy: &users.ExampleAlice,
 		})
-		require.ErrorIs(t, err, errs.ErrInternal)
-		require.ErrorContains(t, err, "some-error")
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrQuotaExceeded)
 	})
 
-	t.Run("Upload with a RegisterFSRefreshSizeTask", func(t *testing.T) {
+	t.Run("Upload with a user already over its quota", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -508,33 +672,29 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		content := "Hello, World!"
-
-		// Get /foo
-		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
-		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		user := users.NewFakeUser(t).WithQuota(10).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		root := NewFakeINode(t).WithSpace(space).IsRootDirectory().Build()
 
-		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(&files.ExampleFile1, nil).Once()
-		toolsMock.ClockMock.On("Now").Return(ExampleAliceNewFile.createdAt).Once()
-		toolsMock.UUIDMock.On("New").Return(ExampleAliceNewFile.ID()).Once()
+		spacesMock.On("GetUserRole", mock.Anything, user.ID(), space.ID()).Return(spaces.RoleEditor, nil).Once()
 
-		storageMock.On("Save", mock.Anything, &ExampleAliceNewFile).Return(nil).Once()
+		// Get /
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", root.ID()).Return(nil, errNotFound).Once()
 
-		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
-			INode:      ExampleAliceNewFile.ID(),
-			ModifiedAt: ExampleAliceNewFile.createdAt,
-		}).Return(errs.Internal(fmt.Errorf("some-error"))).Once()
+		// Check the quota
+		storageMock.On("GetSumUserFilesSize", mock.Anything, user.ID()).Return(uint64(42), nil).Once()
 
 		err := spaceFS.Upload(ctx, &UploadCmd{
-			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new.pdf"),
-			Content:    bytes.NewBufferString(content),
-			UploadedBy: &users.ExampleAlice,
+			Path:       NewPathCmd(space, "/new.pdf"),
+			Content:    bytes.NewBufferString("Hello, World!"),
+			UploadedBy: user,
 		})
-		require.ErrorIs(t, err, errs.ErrInternal)
-		require.ErrorContains(t, err, "some-error")
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrQuotaExceeded)
 	})
 
-	t.Run("Move success", func(t *testing.T) {
+	t.Run("Upload with a content exceeding the user quota", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -542,28 +702,38 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		// Get /foo.txt
-		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
-		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+		user := users.NewFakeUser(t).WithQuota(50).Build()
+		space := spaces.NewFakeSpace(t).WithQuota(1000).Build()
+		root := NewFakeINode(t).WithSpace(space).IsRootDirectory().WithSize(42).Build()
 
-		toolsMock.ClockMock.On("Now").Return(now).Once()
-		schedulerMock.On("RegisterFSMoveTask", mock.Anything, &scheduler.FSMoveArgs{
-			SpaceID:     spaces.ExampleAlicePersonalSpace.ID(),
-			SourceInode: ExampleAliceFile.ID(),
-			TargetPath:  "/bar.txt",
-			MovedAt:     now,
-			MovedBy:     users.ExampleAlice.ID(),
-		}).Return(nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, user.ID(), space.ID()).Return(spaces.RoleEditor, nil).Once()
 
-		err := spaceFS.Move(ctx, &MoveCmd{
-			Src:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
-			Dst:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
-			MovedBy: &users.ExampleAlice,
+		// Get /
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", root.ID()).Return(nil, errNotFound).Once()
+
+		// Check the quotas
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetSumSpaceVersionsSize", mock.Anything, space.ID()).Return(uint64(0), nil).Once()
+		storageMock.On("GetSumUserFilesSize", mock.Anything, user.ID()).Return(uint64(42), nil).Once()
+
+		filesMock.On("Upload", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				_, err := io.ReadAll(args.Get(1).(io.Reader))
+				require.ErrorIs(t, err, ErrQuotaExceeded)
+			}).
+			Return(nil, errs.Internal(fmt.Errorf("upload error: %w", ErrQuotaExceeded))).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(space, "/new.pdf"),
+			Content:    bytes.NewBufferString("Hello, World!"), // 13 bytes
+			UploadedBy: user,
 		})
-		require.NoError(t, err)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrQuotaExceeded)
 	})
 
-	t.Run("Move with a validation error", func(t *testing.T) {
+	t.Run("Upload with a GetSumUserFilesSize error", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -571,16 +741,29 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		err := spaceFS.Move(ctx, &MoveCmd{
-			Src:     nil,
-			Dst:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
-			MovedBy: &users.ExampleAlice,
+		user := users.NewFakeUser(t).WithQuota(50).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		root := NewFakeINode(t).WithSpace(space).IsRootDirectory().Build()
+
+		spacesMock.On("GetUserRole", mock.Anything, user.ID(), space.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		// Get /
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", root.ID()).Return(nil, errNotFound).Once()
+
+		// Check the quota
+		storageMock.On("GetSumUserFilesSize", mock.Anything, user.ID()).Return(uint64(0), fmt.Errorf("some-error")).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(space, "/new.pdf"),
+			Content:    bytes.NewBufferString("Hello, World!"),
+			UploadedBy: user,
 		})
-		require.ErrorIs(t, err, errs.ErrValidation)
-		require.EqualError(t, err, "validation: Src: cannot be blank.")
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
 	})
 
-	t.Run("Move to the same place", func(t *testing.T) {
+	t.Run("GetUserUsage success", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -588,15 +771,14 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		err := spaceFS.Move(ctx, &MoveCmd{
-			Src:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
-			Dst:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
-			MovedBy: &users.ExampleAlice,
-		})
+		storageMock.On("GetSumUserFilesSize", mock.Anything, users.ExampleAlice.ID()).Return(uint64(42), nil).Once()
+
+		res, err := spaceFS.GetUserUsage(ctx, &users.ExampleAlice)
 		require.NoError(t, err)
+		assert.Equal(t, uint64(42), res)
 	})
 
-	t.Run("Move with a source not found", func(t *testing.T) {
+	t.Run("GetUserUsage with a storage error", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -604,19 +786,15 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		// Get /foo.txt
-		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
-		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(nil, errs.ErrNotFound).Once()
+		storageMock.On("GetSumUserFilesSize", mock.Anything, users.ExampleAlice.ID()).Return(uint64(0), fmt.Errorf("some-error")).Once()
 
-		err := spaceFS.Move(ctx, &MoveCmd{
-			Src:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
-			Dst:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
-			MovedBy: &users.ExampleAlice,
-		})
-		require.ErrorIs(t, err, errs.ErrNotFound)
+		res, err := spaceFS.GetUserUsage(ctx, &users.ExampleAlice)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+		assert.Zero(t, res)
 	})
 
-	t.Run("Move with a move error", func(t *testing.T) {
+	t.Run("GetAvailableSpace success", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -624,29 +802,21 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		// Get /foo.txt
-		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
-		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+		user := users.NewFakeUser(t).WithQuota(100).Build()
+		space := spaces.NewFakeSpace(t).WithQuota(50).Build()
+		root := NewFakeINode(t).WithSpace(space).IsRootDirectory().WithSize(42).Build()
 
-		toolsMock.ClockMock.On("Now").Return(now).Once()
-		schedulerMock.On("RegisterFSMoveTask", mock.Anything, &scheduler.FSMoveArgs{
-			SpaceID:     spaces.ExampleAlicePersonalSpace.ID(),
-			SourceInode: ExampleAliceFile.ID(),
-			TargetPath:  "/bar.txt",
-			MovedAt:     now,
-			MovedBy:     users.ExampleAlice.ID(),
-		}).Return(errs.Internal(fmt.Errorf("some-error"))).Once()
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetSumSpaceVersionsSize", mock.Anything, space.ID()).Return(uint64(3), nil).Once()
+		storageMock.On("GetSumUserFilesSize", mock.Anything, user.ID()).Return(uint64(90), nil).Once()
+		filesMock.On("GetFreeSpace", mock.Anything).Return(uint64(1000), nil).Once()
 
-		err := spaceFS.Move(ctx, &MoveCmd{
-			Src:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
-			Dst:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
-			MovedBy: &users.ExampleAlice,
-		})
-		require.ErrorIs(t, err, errs.ErrInternal)
-		require.ErrorContains(t, err, "some-error")
+		res, err := spaceFS.GetAvailableSpace(ctx, user, space)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(5), res) // The space quota minus the root and the versions sizes
 	})
 
-	t.Run("Rename success", func(t *testing.T) {
+	t.Run("GetAvailableSpace limited by the disk", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -654,22 +824,2036 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		storageMock.On("GetByNameAndParent", mock.Anything, "foobar.jpg", *ExampleAliceFile.Parent()).Return(nil, errNotFound).Once()
-		toolsMock.ClockMock.On("Now").Return(now).Once()
-		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
-			"last_modified_at": sqlstorage.SQLTime(now),
+		filesMock.On("GetFreeSpace", mock.Anything).Return(uint64(5), nil).Once()
+
+		res, err := spaceFS.GetAvailableSpace(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(5), res)
+	})
+
+	t.Run("GetAvailableSpace without any limit", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		filesMock.On("GetFreeSpace", mock.Anything).Return(uint64(0), files.ErrUnknownSpace).Once()
+
+		res, err := spaceFS.GetAvailableSpace(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(math.MaxUint64), res)
+	})
+
+	t.Run("GetAvailableSpace with a space already over its quota", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		space := spaces.NewFakeSpace(t).WithQuota(10).Build()
+		root := NewFakeINode(t).WithSpace(space).IsRootDirectory().WithSize(42).Build()
+
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetSumSpaceVersionsSize", mock.Anything, space.ID()).Return(uint64(0), nil).Once()
+
+		res, err := spaceFS.GetAvailableSpace(ctx, &users.ExampleAlice, space)
+		require.NoError(t, err)
+		assert.Zero(t, res)
+	})
+
+	t.Run("GetAvailableSpace with a GetSumSpaceVersionsSize error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		space := spaces.NewFakeSpace(t).WithQuota(50).Build()
+		root := NewFakeINode(t).WithSpace(space).IsRootDirectory().WithSize(42).Build()
+
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetSumSpaceVersionsSize", mock.Anything, space.ID()).Return(uint64(0), fmt.Errorf("some-error")).Once()
+
+		res, err := spaceFS.GetAvailableSpace(ctx, &users.ExampleAlice, space)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+		assert.Zero(t, res)
+	})
+
+	t.Run("GetAvailableSpace with a GetFreeSpace error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		filesMock.On("GetFreeSpace", mock.Anything).Return(uint64(0), errs.Internal(fmt.Errorf("some-error"))).Once()
+
+		res, err := spaceFS.GetAvailableSpace(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+		assert.Zero(t, res)
+	})
+
+	t.Run("Search success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		cmd := &SearchCmd{User: &users.ExampleAlice, Query: "foo"}
+		results := []SearchResult{{path: "/foo", inode: ExampleAliceFile}}
+
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
+		storageMock.On("Search", mock.Anything, []uuid.UUID{spaces.ExampleAlicePersonalSpace.ID()}, cmd, &sqlstorage.PaginateCmd{Limit: 10}).
+			Return(results, nil).Once()
+
+		res, err := spaceFS.Search(ctx, cmd, &sqlstorage.PaginateCmd{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, results, res)
+	})
+
+	t.Run("Search with a space", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		cmd := &SearchCmd{User: &users.ExampleAlice, Query: "foo", SpaceID: spaces.ExampleAlicePersonalSpace.ID()}
+		results := []SearchResult{{path: "/foo", inode: ExampleAliceFile}}
+
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		storageMock.On("Search", mock.Anything, []uuid.UUID{spaces.ExampleAlicePersonalSpace.ID()}, cmd, (*sqlstorage.PaginateCmd)(nil)).
+			Return(results, nil).Once()
+
+		res, err := spaceFS.Search(ctx, cmd, nil)
+		require.NoError(t, err)
+		assert.Equal(t, results, res)
+	})
+
+	t.Run("Search with a space not accessible by the user", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		cmd := &SearchCmd{User: &users.ExampleAlice, Query: "foo", SpaceID: spaces.ExampleBobPersonalSpace.ID()}
+
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleBobPersonalSpace.ID()).
+			Return(nil, errs.ErrNotFound).Once()
+
+		res, err := spaceFS.Search(ctx, cmd, nil)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		assert.Nil(t, res)
+	})
+
+	t.Run("Search with a validation error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		res, err := spaceFS.Search(ctx, &SearchCmd{User: &users.ExampleAlice, Query: ""}, nil)
+		require.ErrorIs(t, err, errs.ErrValidation)
+		require.EqualError(t, err, "validation: Query: cannot be blank.")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Search with a query without any term", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
+
+		res, err := spaceFS.Search(ctx, &SearchCmd{User: &users.ExampleAlice, Query: `"*"`}, nil)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("Search with a storage error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		cmd := &SearchCmd{User: &users.ExampleAlice, Query: "foo"}
+
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
+		storageMock.On("Search", mock.Anything, []uuid.UUID{spaces.ExampleAlicePersonalSpace.ID()}, cmd, (*sqlstorage.PaginateCmd)(nil)).
+			Return(nil, fmt.Errorf("some-error")).Once()
+
+		res, err := spaceFS.Search(ctx, cmd, nil)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+		assert.Nil(t, res)
+	})
+
+	t.Run("SearchTree success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		cmd := &SearchTreeCmd{
+			Root:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"),
+			Filter:    &SearchFilter{Operator: SearchLike, Field: SearchName, Value: "%.txt"},
+			Recursive: true,
+		}
+		results := []SearchResult{{path: "/foo/bar.txt", inode: ExampleAliceFile}}
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+
+		storageMock.On("SearchTree", mock.Anything, &ExampleAliceDir, "/foo", cmd, &sqlstorage.PaginateCmd{Limit: 10}).
+			Return(results, nil).Once()
+
+		res, err := spaceFS.SearchTree(ctx, cmd, &sqlstorage.PaginateCmd{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, results, res)
+	})
+
+	t.Run("SearchTree with an invalid filter", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		res, err := spaceFS.SearchTree(ctx, &SearchTreeCmd{
+			Root:   NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"),
+			Filter: &SearchFilter{Operator: SearchGt, Field: SearchSize, Value: "12"},
+		}, nil)
+		require.ErrorIs(t, err, errs.ErrValidation)
+		require.EqualError(t, err, "validation: Filter: (Value: invalid type for the field.).")
+		assert.Nil(t, res)
+	})
+
+	t.Run("SearchTree with a like on the size", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		res, err := spaceFS.SearchTree(ctx, &SearchTreeCmd{
+			Root: NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo"),
+			Filter: &SearchFilter{Operator: SearchNot, Filters: []SearchFilter{
+				{Operator: SearchLike, Field: SearchSize, Value: uint64(12)},
+			}},
+		}, nil)
+		require.ErrorIs(t, err, errs.ErrValidation)
+		assert.Nil(t, res)
+	})
+
+	t.Run("SearchTree with a file as root", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		res, err := spaceFS.SearchTree(ctx, &SearchTreeCmd{Root: NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo")}, nil)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrIsNotDir)
+		assert.Nil(t, res)
+	})
+
+	t.Run("SearchTree with an unknown root", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(nil, errNotFound).Once()
+
+		res, err := spaceFS.SearchTree(ctx, &SearchTreeCmd{Root: NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo")}, nil)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		assert.Nil(t, res)
+	})
+
+	t.Run("SearchTree with a storage error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		cmd := &SearchTreeCmd{Root: NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo")}
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+
+		storageMock.On("SearchTree", mock.Anything, &ExampleAliceDir, "/foo", cmd, (*sqlstorage.PaginateCmd)(nil)).
+			Return(nil, fmt.Errorf("some-error")).Once()
+
+		res, err := spaceFS.SearchTree(ctx, cmd, nil)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Upload with a validation error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar.txt"),
+			Content:    nil,
+			UploadedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrValidation)
+		require.EqualError(t, err, "validation: Content: cannot be blank.")
+	})
+
+	t.Run("Upload with a non existing directory", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		content := "Hello, World!"
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(nil, errs.ErrNotFound).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new.pdf"),
+			Content:    bytes.NewBufferString(content),
+			UploadedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("Upload with a file upload error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		content := "Hello, World!"
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(nil, errNotFound).Once()
+
+		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(nil, errs.Internal(fmt.Errorf("some-error"))).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new.pdf"),
+			Content:    bytes.NewBufferString(content),
+			UploadedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("Upload with a Save error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		content := "Hello, World!"
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(nil, errNotFound).Once()
+
+		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(&files.ExampleFile1, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(ExampleAliceNewFile.createdAt).Once()
+		toolsMock.UUIDMock.On("New").Return(ExampleAliceNewFile.ID()).Once()
+
+		storageMock.On("Save", mock.Anything, &ExampleAliceNewFile).Return(fmt.Errorf("some-error")).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo/new.pdf"),
+			Content:    bytes.NewBufferString(content),
+			UploadedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("Upload with a RegisterFSRefreshSizeTask", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		content := "Hello, World!"
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(nil, errNotFound).Once()
+
+		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(&files.ExampleFile1, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(ExampleAliceNewFile.createdAt).Once()
+		toolsMock.UUIDMock.On("New").Return(ExampleAliceNewFile.ID()).Once()
+
+		storageMock.On("Save", mock.Anything, &ExampleAliceNewFile).Return(nil).Once()
+
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceNewFile.ID(),
+			ModifiedAt: ExampleAliceNewFile.createdAt,
+		}).Return(errs.Internal(fmt.Errorf("some-error"))).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new.pdf"),
+			Content:    bytes.NewBufferString(content),
+			UploadedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("Upload on an existing file creates a new version", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		content := "Hello, World!"
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(&ExampleAliceNewFile, nil).Once()
+
+		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(&files.ExampleFile2, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+
+		// Save the previous content as a version
+		filesMock.On("GetMetadata", mock.Anything, files.ExampleFile1.ID()).Return(&files.ExampleFile1, nil).Once()
+		toolsMock.UUIDMock.On("New").Return(ExampleAliceFileVersion.ID()).Once()
+		storageMock.On("SaveVersion", mock.Anything, &FileVersion{
+			id:         ExampleAliceFileVersion.ID(),
+			inodeID:    ExampleAliceNewFile.ID(),
+			fileID:     files.ExampleFile1.ID(),
+			size:       files.ExampleFile1.Size(),
+			modifiedAt: ExampleAliceNewFile.LastModifiedAt(),
+			createdAt:  now,
+			createdBy:  users.ExampleAlice.ID(),
+		}).Return(nil).Once()
+
+		storageMock.On("Patch", mock.Anything, ExampleAliceNewFile.ID(), map[string]any{
+			"file_id":          files.ExampleFile2.ID(),
+			"last_modified_at": now,
+		}).Return(nil).Once()
+
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceNewFile.ID(),
+			ModifiedAt: now,
+		}).Return(nil).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new.pdf"),
+			Content:    bytes.NewBufferString(content),
+			UploadedBy: &users.ExampleAlice,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Upload on an existing file with a modification time given by the client", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		modifiedAt := time.Date(2015, time.March, 12, 10, 0, 0, 0, time.UTC)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		content := "Hello, World!"
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(&ExampleAliceNewFile, nil).Once()
+
+		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(&files.ExampleFile2, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+
+		// Save the previous content as a version
+		filesMock.On("GetMetadata", mock.Anything, files.ExampleFile1.ID()).Return(&files.ExampleFile1, nil).Once()
+		toolsMock.UUIDMock.On("New").Return(ExampleAliceFileVersion.ID()).Once()
+		storageMock.On("SaveVersion", mock.Anything, mock.Anything).Return(nil).Once()
+
+		storageMock.On("Patch", mock.Anything, ExampleAliceNewFile.ID(), map[string]any{
+			"file_id":          files.ExampleFile2.ID(),
+			"last_modified_at": modifiedAt,
+		}).Return(nil).Once()
+
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceNewFile.ID(),
+			ModifiedAt: now,
+		}).Return(nil).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new.pdf"),
+			Content:    bytes.NewBufferString(content),
+			UploadedBy: &users.ExampleAlice,
+			ModifiedAt: modifiedAt,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Upload on an existing file with the same content", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		content := "Hello, World!"
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(&ExampleAliceNewFile, nil).Once()
+
+		// The files service deduplicate the content so the same file is returned.
+		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(&files.ExampleFile1, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+
+		// No version is created
+		storageMock.On("Patch", mock.Anything, ExampleAliceNewFile.ID(), map[string]any{
+			"file_id":          files.ExampleFile1.ID(),
+			"last_modified_at": now,
+		}).Return(nil).Once()
+
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceNewFile.ID(),
+			ModifiedAt: now,
+		}).Return(nil).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new.pdf"),
+			Content:    bytes.NewBufferString(content),
+			UploadedBy: &users.ExampleAlice,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Upload on an existing directory", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		// Get /
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+			Content:    bytes.NewBufferString("Hello, World!"),
+			UploadedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrIsADir)
+	})
+
+	t.Run("Upload in create only mode success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		content := "Hello, World!"
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+
+		// Checked before and after the transfer
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(nil, errNotFound).Twice()
+
+		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(&files.ExampleFile1, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(ExampleAliceNewFile.createdAt).Once()
+		toolsMock.UUIDMock.On("New").Return(ExampleAliceNewFile.ID()).Once()
+
+		storageMock.On("Save", mock.Anything, &ExampleAliceNewFile).Return(nil).Once()
+
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceNewFile.ID(),
+			ModifiedAt: ExampleAliceNewFile.createdAt,
+		}).Return(nil).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new.pdf"),
+			Content:    bytes.NewBufferString(content),
+			UploadedBy: &users.ExampleAlice,
+			CreateOnly: true,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Upload in create only mode on an existing file", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		// Get /
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
+			Content:    bytes.NewBufferString("Hello, World!"),
+			UploadedBy: &users.ExampleAlice,
+			CreateOnly: true,
+		})
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrAlreadyExists)
+	})
+
+	t.Run("Upload in create only mode with a file created during the transfer", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		content := "Hello, World!"
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(nil, errNotFound).Once()
+
+		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(&files.ExampleFile1, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(ExampleAliceNewFile.createdAt).Once()
+
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(&ExampleAliceNewFile, nil).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new.pdf"),
+			Content:    bytes.NewBufferString(content),
+			UploadedBy: &users.ExampleAlice,
+			CreateOnly: true,
+		})
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrAlreadyExists)
+	})
+
+	t.Run("Upload with a SaveVersion error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		content := "Hello, World!"
+
+		// Get /foo
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(&ExampleAliceNewFile, nil).Once()
+
+		filesMock.On("Upload", mock.Anything, bytes.NewBufferString(content)).Return(&files.ExampleFile2, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+
+		filesMock.On("GetMetadata", mock.Anything, files.ExampleFile1.ID()).Return(&files.ExampleFile1, nil).Once()
+		toolsMock.UUIDMock.On("New").Return(ExampleAliceFileVersion.ID()).Once()
+		storageMock.On("SaveVersion", mock.Anything, mock.Anything).Return(fmt.Errorf("some-error")).Once()
+
+		err := spaceFS.Upload(ctx, &UploadCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new.pdf"),
+			Content:    bytes.NewBufferString(content),
+			UploadedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("ListVersions success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetAllINodeVersions", mock.Anything, ExampleAliceFile.ID()).
+			Return([]FileVersion{ExampleAliceFileVersion}, nil).Once()
+
+		res, err := spaceFS.ListVersions(ctx, &ExampleAliceFile)
+		require.NoError(t, err)
+		assert.Equal(t, []FileVersion{ExampleAliceFileVersion}, res)
+	})
+
+	t.Run("ListVersions with a directory", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		res, err := spaceFS.ListVersions(ctx, &ExampleAliceDir)
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrIsADir)
+	})
+
+	t.Run("ListVersions with a storage error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetAllINodeVersions", mock.Anything, ExampleAliceFile.ID()).
+			Return(nil, fmt.Errorf("some-error")).Once()
+
+		res, err := spaceFS.ListVersions(ctx, &ExampleAliceFile)
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("GetVersion success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetVersionByID", mock.Anything, ExampleAliceFileVersion.ID()).
+			Return(&ExampleAliceFileVersion, nil).Once()
+
+		res, err := spaceFS.GetVersion(ctx, &ExampleAliceFile, ExampleAliceFileVersion.ID())
+		require.NoError(t, err)
+		assert.Equal(t, &ExampleAliceFileVersion, res)
+	})
+
+	t.Run("GetVersion not found", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetVersionByID", mock.Anything, ExampleAliceFileVersion.ID()).
+			Return(nil, errNotFound).Once()
+
+		res, err := spaceFS.GetVersion(ctx, &ExampleAliceFile, ExampleAliceFileVersion.ID())
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("GetVersion with a version from an other inode", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetVersionByID", mock.Anything, ExampleAliceFileVersion.ID()).
+			Return(&ExampleAliceFileVersion, nil).Once()
+
+		res, err := spaceFS.GetVersion(ctx, &ExampleAliceNewFile, ExampleAliceFileVersion.ID())
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("DownloadVersion success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		afs := afero.NewMemMapFs()
+		file, err := afs.Create("foo")
+		require.NoError(t, err)
+
+		filesMock.On("GetMetadata", mock.Anything, files.ExampleFile2.ID()).Return(&files.ExampleFile2, nil).Once()
+		filesMock.On("Download", mock.Anything, &files.ExampleFile2).Return(file, nil).Once()
+
+		res, err := spaceFS.DownloadVersion(ctx, &ExampleAliceFileVersion)
+		require.NoError(t, err)
+		assert.Equal(t, file, res)
+	})
+
+	t.Run("DownloadVersion with a GetMetadata error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		filesMock.On("GetMetadata", mock.Anything, files.ExampleFile2.ID()).Return(nil, fmt.Errorf("some-error")).Once()
+
+		res, err := spaceFS.DownloadVersion(ctx, &ExampleAliceFileVersion)
+		assert.Nil(t, res)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("RestoreVersion success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		storageMock.On("GetVersionByID", mock.Anything, ExampleAliceFileVersion.ID()).
+			Return(&ExampleAliceFileVersion, nil).Once()
+		filesMock.On("GetMetadata", mock.Anything, files.ExampleFile2.ID()).Return(&files.ExampleFile2, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+
+		// The current content is saved as a new version
+		filesMock.On("GetMetadata", mock.Anything, files.ExampleFile1.ID()).Return(&files.ExampleFile1, nil).Once()
+		toolsMock.UUIDMock.On("New").Return(uuid.UUID("c0b4fbb2-1b4b-4f3c-8a8f-0e9b5d2f6a11")).Once()
+		storageMock.On("SaveVersion", mock.Anything, &FileVersion{
+			id:         uuid.UUID("c0b4fbb2-1b4b-4f3c-8a8f-0e9b5d2f6a11"),
+			inodeID:    ExampleAliceFile.ID(),
+			fileID:     files.ExampleFile1.ID(),
+			size:       files.ExampleFile1.Size(),
+			modifiedAt: ExampleAliceFile.LastModifiedAt(),
+			createdAt:  now,
+			createdBy:  users.ExampleAlice.ID(),
+		}).Return(nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"file_id":          files.ExampleFile2.ID(),
+			"last_modified_at": now,
+		}).Return(nil).Once()
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceFile.ID(),
+			ModifiedAt: now,
+		}).Return(nil).Once()
+
+		// The restored version is removed
+		storageMock.On("DeleteVersion", mock.Anything, ExampleAliceFileVersion.ID()).Return(nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		res, err := spaceFS.RestoreVersion(ctx, &RestoreVersionCmd{
+			INode:      &ExampleAliceFile,
+			VersionID:  ExampleAliceFileVersion.ID(),
+			RestoredBy: &users.ExampleAlice,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &ExampleAliceFile, res)
+	})
+
+	t.Run("RestoreVersion with a validation error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		res, err := spaceFS.RestoreVersion(ctx, &RestoreVersionCmd{
+			INode:      &ExampleAliceFile,
+			VersionID:  uuid.UUID("some-invalid-id"),
+			RestoredBy: &users.ExampleAlice,
+		})
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
+	t.Run("RestoreVersion with a version not found", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		storageMock.On("GetVersionByID", mock.Anything, ExampleAliceFileVersion.ID()).
+			Return(nil, errNotFound).Once()
+
+		res, err := spaceFS.RestoreVersion(ctx, &RestoreVersionCmd{
+			INode:      &ExampleAliceFile,
+			VersionID:  ExampleAliceFileVersion.ID(),
+			RestoredBy: &users.ExampleAlice,
+		})
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("Move success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Checked for the source then for the destination.
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Twice()
+
+		// Get /foo.txt
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		schedulerMock.On("RegisterFSMoveTask", mock.Anything, &scheduler.FSMoveArgs{
+			SpaceID:       spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode:   ExampleAliceFile.ID(),
+			TargetSpaceID: spaces.ExampleAlicePersonalSpace.ID(),
+			TargetPath:    "/bar.txt",
+			MovedAt:       now,
+			MovedBy:       users.ExampleAlice.ID(),
+		}).Return(nil).Once()
+
+		err := spaceFS.Move(ctx, &MoveCmd{
+			Src:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
+			Dst:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
+			MovedBy: &users.ExampleAlice,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Move to an other space success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAliceBobSharedSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		// Get /foo.txt
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		schedulerMock.On("RegisterFSMoveTask", mock.Anything, &scheduler.FSMoveArgs{
+			SpaceID:       spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode:   ExampleAliceFile.ID(),
+			TargetSpaceID: spaces.ExampleAliceBobSharedSpace.ID(),
+			TargetPath:    "/foo.txt",
+			MovedAt:       now,
+			MovedBy:       users.ExampleAlice.ID(),
+		}).Return(nil).Once()
+
+		err := spaceFS.Move(ctx, &MoveCmd{
+			Src:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
+			Dst:     NewPathCmd(&spaces.ExampleAliceBobSharedSpace, "/foo.txt"),
+			MovedBy: &users.ExampleAlice,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Move to an other space exceeding its quota", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		space := spaces.NewFakeSpace(t).WithQuota(50).Build()
+		root := NewFakeINode(t).WithSpace(space).IsRootDirectory().WithSize(42).Build()
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), space.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		// Get /foo.txt
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		// Check the quota
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetSumSpaceVersionsSize", mock.Anything, space.ID()).Return(uint64(0), nil).Once()
+
+		err := spaceFS.Move(ctx, &MoveCmd{
+			Src:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
+			Dst:     NewPathCmd(space, "/foo.txt"),
+			MovedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+
+	t.Run("Move with a validation error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		err := spaceFS.Move(ctx, &MoveCmd{
+			Src:     nil,
+			Dst:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
+			MovedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrValidation)
+		require.EqualError(t, err, "validation: Src: cannot be blank.")
+	})
+
+	t.Run("Move to the same place", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		err := spaceFS.Move(ctx, &MoveCmd{
+			Src:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
+			Dst:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
+			MovedBy: &users.ExampleAlice,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Move with a source not found", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Checked for the source then for the destination.
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Twice()
+
+		// Get /foo.txt
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(nil, errs.ErrNotFound).Once()
+
+		err := spaceFS.Move(ctx, &MoveCmd{
+			Src:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
+			Dst:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
+			MovedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("Move with a move error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Checked for the source then for the destination.
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Twice()
+
+		// Get /foo.txt
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		schedulerMock.On("RegisterFSMoveTask", mock.Anything, &scheduler.FSMoveArgs{
+			SpaceID:       spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode:   ExampleAliceFile.ID(),
+			TargetSpaceID: spaces.ExampleAlicePersonalSpace.ID(),
+			TargetPath:    "/bar.txt",
+			MovedAt:       now,
+			MovedBy:       users.ExampleAlice.ID(),
+		}).Return(errs.Internal(fmt.Errorf("some-error"))).Once()
+
+		err := spaceFS.Move(ctx, &MoveCmd{
+			Src:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
+			Dst:     NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
+			MovedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("Copy success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Check the source and the destination accesses
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		// Get /foo.txt
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		schedulerMock.On("RegisterFSCopyTask", mock.Anything, &scheduler.FSCopyArgs{
+			SpaceID:       spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode:   ExampleAliceFile.ID(),
+			TargetSpaceID: spaces.ExampleAlicePersonalSpace.ID(),
+			TargetPath:    "/bar.txt",
+			CopiedAt:      now,
+			CopiedBy:      users.ExampleAlice.ID(),
+		}).Return(nil).Once()
+
+		err := spaceFS.Copy(ctx, &CopyCmd{
+			Src:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
+			Dst:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
+			CopiedBy: &users.ExampleAlice,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Copy exceeding the space quota", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		space := spaces.NewFakeSpace(t).WithQuota(50).Build()
+		root := NewFakeINode(t).WithSpace(space).IsRootDirectory().WithSize(42).Build()
+		file := NewFakeINode(t).WithSpace(space).WithParent(root).WithName("foo.txt").WithSize(42).Build()
+
+		// Check the source and the destination accesses
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), space.ID()).Return(spaces.RoleEditor, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), space.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		// Get /foo.txt
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", root.ID()).Return(file, nil).Once()
+
+		// Check the quota
+		storageMock.On("GetSpaceRoot", mock.Anything, space.ID()).Return(root, nil).Once()
+		storageMock.On("GetSumSpaceVersionsSize", mock.Anything, space.ID()).Return(uint64(0), nil).Once()
+
+		err := spaceFS.Copy(ctx, &CopyCmd{
+			Src:      NewPathCmd(space, "/foo.txt"),
+			Dst:      NewPathCmd(space, "/bar.txt"),
+			CopiedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+
+	t.Run("Copy exceeding the user quota", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		user := users.NewFakeUser(t).WithQuota(50).Build()
+
+		// Check the source and the destination accesses
+		spacesMock.On("GetUserRole", mock.Anything, user.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, user.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		// Get /foo.txt
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		// Check the quota
+		storageMock.On("GetSumUserFilesSize", mock.Anything, user.ID()).Return(uint64(10), nil).Once()
+
+		err := spaceFS.Copy(ctx, &CopyCmd{
+			Src:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
+			Dst:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
+			CopiedBy: user,
+		})
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+
+	t.Run("Copy with a validation error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		err := spaceFS.Copy(ctx, &CopyCmd{
+			Src:      nil,
+			Dst:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
+			CopiedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
+	t.Run("Copy inside itself", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		err := spaceFS.Copy(ctx, &CopyCmd{
+			Src:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+			Dst:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+			CopiedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrInvalidPath)
+	})
+
+	t.Run("Copy with a source not found", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Check the source and the destination accesses
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		// Get /foo.txt
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(nil, errs.ErrNotFound).Once()
+
+		err := spaceFS.Copy(ctx, &CopyCmd{
+			Src:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
+			Dst:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
+			CopiedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("Copy from a space the user isn't a member of", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleBob.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.Role(""), errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
+		storageMock.On("GetAllUserGrants", mock.Anything, users.ExampleBob.ID()).Return([]Grant{}, nil).Once()
+
+		err := spaceFS.Copy(ctx, &CopyCmd{
+			Src:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
+			Dst:      NewPathCmd(&spaces.ExampleBobPersonalSpace, "/bar.txt"),
+			CopiedBy: &users.ExampleBob,
+		})
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, spaces.ErrInvalidSpaceAccess)
+	})
+
+	t.Run("Copy from a granted folder", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		dir := NewFakeINode(t).WithSpace(&spaces.ExampleAlicePersonalSpace).WithParent(&ExampleAliceRoot).WithName("foo").IsDirectory().Build()
+		file := NewFakeINode(t).WithSpace(&spaces.ExampleAlicePersonalSpace).WithParent(dir).WithName("bar.txt").Build()
+		grant := NewFakeGrant(t, dir, &users.ExampleBob).Build()
+
+		// Check the source access
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleBob.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.Role(""), errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
+		storageMock.On("GetAllUserGrants", mock.Anything, users.ExampleBob.ID()).Return([]Grant{*grant}, nil).Once()
+		storageMock.On("GetByID", mock.Anything, dir.ID()).Return(dir, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceRoot.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(dir, nil).Once()
+
+		// Check the destination access
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleBob.ID(), spaces.ExampleBobPersonalSpace.ID()).Return(spaces.RoleManager, nil).Once()
+
+		// Get /foo/bar.txt
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo", ExampleAliceRoot.ID()).Return(dir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "bar.txt", dir.ID()).Return(file, nil).Once()
+
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		schedulerMock.On("RegisterFSCopyTask", mock.Anything, &scheduler.FSCopyArgs{
+			SpaceID:       spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode:   file.ID(),
+			TargetSpaceID: spaces.ExampleBobPersonalSpace.ID(),
+			TargetPath:    "/bar.txt",
+			CopiedAt:      now,
+			CopiedBy:      users.ExampleBob.ID(),
+		}).Return(nil).Once()
+
+		err := spaceFS.Copy(ctx, &CopyCmd{
+			Src:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar.txt"),
+			Dst:      NewPathCmd(&spaces.ExampleBobPersonalSpace, "/bar.txt"),
+			CopiedBy: &users.ExampleBob,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Copy with a RegisterFSCopyTask error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Check the source and the destination accesses
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		// Get /foo.txt
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.txt", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		schedulerMock.On("RegisterFSCopyTask", mock.Anything, &scheduler.FSCopyArgs{
+			SpaceID:       spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode:   ExampleAliceFile.ID(),
+			TargetSpaceID: spaces.ExampleAlicePersonalSpace.ID(),
+			TargetPath:    "/bar.txt",
+			CopiedAt:      now,
+			CopiedBy:      users.ExampleAlice.ID(),
+		}).Return(errs.Internal(fmt.Errorf("some-error"))).Once()
+
+		err := spaceFS.Copy(ctx, &CopyCmd{
+			Src:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.txt"),
+			Dst:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt"),
+			CopiedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("Rename with a viewer role", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleViewer, nil).Once()
+		storageMock.On("GetAllUserGrants", mock.Anything, users.ExampleAlice.ID()).Return([]Grant{}, nil).Once()
+
+		res, err := spaceFS.Rename(ctx, &users.ExampleAlice, &ExampleAliceFile, "foobar.jpg")
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrReadOnly)
+	})
+
+	t.Run("Rename success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		storageMock.On("GetByNameAndParent", mock.Anything, "foobar.jpg", *ExampleAliceFile.Parent()).Return(nil, errNotFound).Once()
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"last_modified_at": sqlstorage.SQLTime(now),
 			"name":             "foobar.jpg",
 		}).Return(nil).Once()
 
-		res, err := spaceFS.Rename(ctx, &ExampleAliceFile, "foobar.jpg")
+		res, err := spaceFS.Rename(ctx, &users.ExampleAlice, &ExampleAliceFile, "foobar.jpg")
+
+		require.NoError(t, err)
+		assert.NotEqual(t, &ExampleAliceRenamedFile, res)
+		assert.Equal(t, "foobar.jpg", res.Name())
+		assert.Equal(t, res.LastModifiedAt(), now)
+	})
+
+	t.Run("SetModifiedAt success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		modifiedAt := time.Date(2015, time.March, 12, 10, 0, 0, 0, time.UTC)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"last_modified_at": sqlstorage.SQLTime(modifiedAt),
+		}).Return(nil).Once()
+
+		res, err := spaceFS.SetModifiedAt(ctx, &users.ExampleAlice, &ExampleAliceFile, modifiedAt)
+		require.NoError(t, err)
+		assert.Equal(t, modifiedAt, res.LastModifiedAt())
+		assert.Equal(t, ExampleAliceFile.ID(), res.ID())
+	})
+
+	t.Run("SetModifiedAt with a viewer role", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleViewer, nil).Once()
+		storageMock.On("GetAllUserGrants", mock.Anything, users.ExampleAlice.ID()).Return([]Grant{}, nil).Once()
+
+		res, err := spaceFS.SetModifiedAt(ctx, &users.ExampleAlice, &ExampleAliceFile, now)
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrReadOnly)
+	})
+
+	t.Run("SetModifiedAt with an empty time", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		res, err := spaceFS.SetModifiedAt(ctx, &users.ExampleAlice, &ExampleAliceFile, time.Time{})
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
+	t.Run("SetModifiedAt with a Patch error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), mock.Anything).Return(fmt.Errorf("some-error")).Once()
+
+		res, err := spaceFS.SetModifiedAt(ctx, &users.ExampleAlice, &ExampleAliceFile, now)
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("Rename with an empty name", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		res, err := spaceFS.Rename(ctx, &users.ExampleAlice, &ExampleAliceFile, "")
+
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrValidation)
+		require.ErrorContains(t, err, "can't be empty")
+	})
+
+	t.Run("Rename with a root inode", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		res, err := spaceFS.Rename(ctx, &users.ExampleAlice, &ExampleAliceRoot, "foo")
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrValidation)
+		require.ErrorContains(t, err, "can't rename the root")
+	})
+
+	t.Run("Rename with a file with the same name", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		storageMock.On("GetByNameAndParent", mock.Anything, "foobar.pdf", *ExampleAliceFile.Parent()).Return(&ExampleAliceFile, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foobar (1).pdf", *ExampleAliceFile.Parent()).Return(nil, errNotFound).Once()
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"last_modified_at": sqlstorage.SQLTime(now),
+			"name":             "foobar (1).pdf",
+		}).Return(nil).Once()
+
+		res, err := spaceFS.Rename(ctx, &users.ExampleAlice, &ExampleAliceFile, "foobar.pdf")
+		require.NoError(t, err)
+		assert.NotEqual(t, &ExampleAliceRenamedFile, res)
+		assert.Equal(t, "foobar (1).pdf", res.Name())
+		assert.Equal(t, res.LastModifiedAt(), now)
+	})
+
+	t.Run("Destroy success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Delete the file system
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&ExampleAliceRoot, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceRoot.ID(), map[string]any{
+			"deleted_at": sqlstorage.SQLTime(now),
+		}).Return(nil).Once()
+
+		err := spaceFS.Destroy(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		require.NoError(t, err)
+	})
+
+	t.Run("Destroy with an non admin user", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		err := spaceFS.Destroy(ctx, &users.ExampleBob, &spaces.ExampleAlicePersonalSpace)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("Destroy with a root already removed", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Delete the file system
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(nil, errs.ErrNotFound).Once()
+
+		err := spaceFS.Destroy(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		require.NoError(t, err)
+	})
+
+	t.Run("Destroy with a GetSpaceRoot error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Delete the file system
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(nil, fmt.Errorf("some-error")).Once()
+
+		err := spaceFS.Destroy(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("Destroy with a Patch error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Delete the file system
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&ExampleAliceRoot, nil).Once()
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceRoot.ID(), map[string]any{
+			"deleted_at": sqlstorage.SQLTime(now),
+		}).Return(fmt.Errorf("some-error")).Once()
+
+		err := spaceFS.Destroy(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("CreateFS success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		rootFS := INode{
+			id:             ExampleAliceRoot.id,
+			parent:         nil,
+			name:           "",
+			spaceID:        spaces.ExampleAlicePersonalSpace.ID(),
+			createdAt:      now,
+			createdBy:      users.ExampleAlice.ID(),
+			lastModifiedAt: now,
+			fileID:         nil,
+		}
+
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		toolsMock.UUIDMock.On("New").Return(ExampleAliceRoot.ID())
+		storageMock.On("Save", mock.Anything, &rootFS).Return(nil)
+
+		res, err := spaceFS.CreateFS(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		require.NoError(t, err)
+		assert.Equal(t, &rootFS, res)
+	})
+
+	t.Run("CreateFS with a create storage error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		toolsMock.UUIDMock.On("New").Return(ExampleAliceRoot.ID())
+		storageMock.On("Save", mock.Anything, &INode{
+			id:             ExampleAliceRoot.id,
+			parent:         nil,
+			name:           "",
+			spaceID:        spaces.ExampleAlicePersonalSpace.ID(),
+			createdAt:      now,
+			createdBy:      users.ExampleAlice.ID(),
+			lastModifiedAt: now,
+			fileID:         nil,
+		}).Return(fmt.Errorf("some-error"))
+
+		res, err := spaceFS.CreateFS(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("ListDeleted success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetAllSpaceDeleted", mock.Anything, spaces.ExampleAlicePersonalSpace.ID(), &sqlstorage.PaginateCmd{Limit: 10}).
+			Return([]INode{ExampleAliceFile}, nil).Once()
+
+		res, err := spaceFS.ListDeleted(ctx, &spaces.ExampleAlicePersonalSpace, &sqlstorage.PaginateCmd{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []INode{ExampleAliceFile}, res)
+	})
+
+	t.Run("ListDeleted with a storage error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetAllSpaceDeleted", mock.Anything, spaces.ExampleAlicePersonalSpace.ID(), &sqlstorage.PaginateCmd{Limit: 10}).
+			Return(nil, fmt.Errorf("some-error")).Once()
+
+		res, err := spaceFS.ListDeleted(ctx, &spaces.ExampleAlicePersonalSpace, &sqlstorage.PaginateCmd{Limit: 10})
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("GetOriginalPath success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetByID", mock.Anything, ExampleAliceDir.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceRoot.ID()).Return(&ExampleAliceRoot, nil).Once()
+
+		res, err := spaceFS.GetOriginalPath(ctx, &ExampleAliceFile2)
+		require.NoError(t, err)
+		assert.Equal(t, "/dir-a", res)
+	})
+
+	t.Run("GetOriginalPath with a purged parent", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetByID", mock.Anything, ExampleAliceDir.ID()).Return(nil, errNotFound).Once()
+
+		res, err := spaceFS.GetOriginalPath(ctx, &ExampleAliceFile2)
+		require.NoError(t, err)
+		assert.Equal(t, "/", res)
+	})
+
+	t.Run("GetPathByID success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile2.ID()).Return(&ExampleAliceFile2, nil).Once()
+
+		// Get the original path
+		storageMock.On("GetByID", mock.Anything, ExampleAliceDir.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceRoot.ID()).Return(&ExampleAliceRoot, nil).Once()
+
+		// Check that the path is reachable
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "dir-a", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "file.txt", ExampleAliceDir.ID()).Return(&ExampleAliceFile2, nil).Once()
+
+		res, err := spaceFS.GetPathByID(ctx, &spaces.ExampleAlicePersonalSpace, ExampleAliceFile2.ID())
+		require.NoError(t, err)
+		assert.Equal(t, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a/file.txt"), res)
+	})
+
+	t.Run("GetPathByID with an unknown inode", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile2.ID()).Return(nil, errNotFound).Once()
+
+		res, err := spaceFS.GetPathByID(ctx, &spaces.ExampleAlicePersonalSpace, ExampleAliceFile2.ID())
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("GetPathByID with an inode from an other space", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile2.ID()).Return(&ExampleAliceFile2, nil).Once()
+
+		res, err := spaceFS.GetPathByID(ctx, &spaces.ExampleBobPersonalSpace, ExampleAliceFile2.ID())
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("GetPathByID with a deleted inode", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile2.ID()).Return(&ExampleAliceFile2, nil).Once()
+
+		// Get the original path
+		storageMock.On("GetByID", mock.Anything, ExampleAliceDir.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceRoot.ID()).Return(&ExampleAliceRoot, nil).Once()
+
+		// The deleted inode is not reachable anymore
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "dir-a", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "file.txt", ExampleAliceDir.ID()).Return(nil, errNotFound).Once()
+
+		res, err := spaceFS.GetPathByID(ctx, &spaces.ExampleAlicePersonalSpace, ExampleAliceFile2.ID())
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("Restore success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Checked by Restore then by CreateDir.
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Twice()
+
+		storageMock.On("GetDeleted", mock.Anything, ExampleAliceNewFile.ID()).Return(&ExampleAliceNewFile, nil).Once()
+
+		// Get the original path
+		storageMock.On("GetByID", mock.Anything, ExampleAliceDir.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceRoot.ID()).Return(&ExampleAliceRoot, nil).Once()
+
+		// Create the parent dir if needed
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "dir-a", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+
+		// Check the name is available
+		storageMock.On("GetByNameAndParent", mock.Anything, "new.pdf", ExampleAliceDir.ID()).Return(nil, errNotFound).Once()
+
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceNewFile.ID(), map[string]any{
+			"parent":     ExampleAliceDir.ID(),
+			"name":       "new.pdf",
+			"deleted_at": nil,
+		}).Return(nil).Once()
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceDir.ID(),
+			ModifiedAt: now,
+		}).Return(nil).Once()
+
+		res, err := spaceFS.Restore(ctx, &RestoreCmd{
+			Space:      &spaces.ExampleAlicePersonalSpace,
+			INodeID:    ExampleAliceNewFile.ID(),
+			RestoredBy: &users.ExampleAlice,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "new.pdf", res.Name())
+		assert.Equal(t, ExampleAliceNewFile.LastModifiedAt(), res.LastModifiedAt())
+		assert.True(t, res.DeletedAt().IsZero())
+	})
+
+	t.Run("Restore with a name already taken", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Checked by Restore then by CreateDir.
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Twice()
+
+		storageMock.On("GetDeleted", mock.Anything, ExampleAliceFile.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		// Get the original path
+		storageMock.On("GetByID", mock.Anything, ExampleAliceRoot.ID()).Return(&ExampleAliceRoot, nil).Once()
+
+		// Create the parent dir if needed
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+
+		// Check the name is available
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.pdf", ExampleAliceRoot.ID()).Return(&ExampleAliceNewFile, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo (1).pdf", ExampleAliceRoot.ID()).Return(nil, errNotFound).Once()
+
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"parent":     ExampleAliceRoot.ID(),
+			"name":       "foo (1).pdf",
+			"deleted_at": nil,
+		}).Return(nil).Once()
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceRoot.ID(),
+			ModifiedAt: now,
+		}).Return(nil).Once()
+
+		res, err := spaceFS.Restore(ctx, &RestoreCmd{
+			Space:      &spaces.ExampleAlicePersonalSpace,
+			INodeID:    ExampleAliceFile.ID(),
+			RestoredBy: &users.ExampleAlice,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "foo (1).pdf", res.Name())
+	})
+
+	t.Run("Restore with an inode not in the trash", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetDeleted", mock.Anything, ExampleAliceFile.ID()).Return(nil, errNotFound).Once()
+
+		res, err := spaceFS.Restore(ctx, &RestoreCmd{
+			Space:      &spaces.ExampleAlicePersonalSpace,
+			INodeID:    ExampleAliceFile.ID(),
+			RestoredBy: &users.ExampleAlice,
+		})
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("Restore with an inode from an other space", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetDeleted", mock.Anything, ExampleAliceFile.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		res, err := spaceFS.Restore(ctx, &RestoreCmd{
+			Space:      &spaces.ExampleBobPersonalSpace,
+			INodeID:    ExampleAliceFile.ID(),
+			RestoredBy: &users.ExampleBob,
+		})
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("Restore with a validation error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		res, err := spaceFS.Restore(ctx, &RestoreCmd{
+			Space:      &spaces.ExampleAlicePersonalSpace,
+			INodeID:    "some-invalid-id",
+			RestoredBy: &users.ExampleAlice,
+		})
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
+	t.Run("EmptyTrash success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		schedulerMock.On("RegisterFSEmptyTrashTask", mock.Anything, &scheduler.FSEmptyTrashArgs{
+			SpaceID:   spaces.ExampleAlicePersonalSpace.ID(),
+			EmptiedAt: now,
+		}).Return(nil).Once()
+
+		err := spaceFS.EmptyTrash(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		require.NoError(t, err)
+	})
+
+	t.Run("EmptyTrash with a scheduler error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
 
-		require.NoError(t, err)
-		assert.NotEqual(t, &ExampleAliceRenamedFile, res)
-		assert.Equal(t, "foobar.jpg", res.Name())
-		assert.Equal(t, res.LastModifiedAt(), now)
+		toolsMock.ClockMock.On("Now").Return(now).Once()
+		schedulerMock.On("RegisterFSEmptyTrashTask", mock.Anything, &scheduler.FSEmptyTrashArgs{
+			SpaceID:   spaces.ExampleAlicePersonalSpace.ID(),
+			EmptiedAt: now,
+		}).Return(fmt.Errorf("some-error")).Once()
+
+		err := spaceFS.EmptyTrash(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		require.ErrorContains(t, err, "some-error")
 	})
 
-	t.Run("Rename with an empty name", func(t *testing.T) {
+	t.Run("CreateGrant success", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -677,14 +2861,44 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		res, err := spaceFS.Rename(ctx, &ExampleAliceFile, "")
+		// Get /dir-a
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "dir-a", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+
+		storageMock.On("GetGrantByINodeAndUser", mock.Anything, ExampleAliceDir.ID(), users.ExampleBob.ID()).Return(nil, errNotFound).Once()
+		toolsMock.UUIDMock.On("New").Return(ExampleBobReadGrant.ID()).Once()
+		toolsMock.ClockMock.On("Now").Return(ExampleBobReadGrant.CreatedAt()).Once()
+		storageMock.On("SaveGrant", mock.Anything, &ExampleBobReadGrant).Return(nil).Once()
+
+		res, err := spaceFS.CreateGrant(ctx, &CreateGrantCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a"),
+			User:       &users.ExampleBob,
+			Permission: PermissionRead,
+			CreatedBy:  &users.ExampleAlice,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &ExampleBobReadGrant, res)
+	})
+
+	t.Run("CreateGrant with a validation error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
+		res, err := spaceFS.CreateGrant(ctx, &CreateGrantCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a"),
+			User:       &users.ExampleBob,
+			Permission: Permission("invalid"),
+			CreatedBy:  &users.ExampleAlice,
+		})
 		assert.Nil(t, res)
 		require.ErrorIs(t, err, errs.ErrValidation)
-		require.ErrorContains(t, err, "can't be empty")
 	})
 
-	t.Run("Rename with a root inode", func(t *testing.T) {
+	t.Run("CreateGrant with a user not manager", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -692,13 +2906,22 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		res, err := spaceFS.Rename(ctx, &ExampleAliceRoot, "foo")
+		user := users.NewFakeUser(t).Build()
+
+		spacesMock.On("GetUserRole", mock.Anything, user.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		res, err := spaceFS.CreateGrant(ctx, &CreateGrantCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a"),
+			User:       &users.ExampleBob,
+			Permission: PermissionRead,
+			CreatedBy:  user,
+		})
 		assert.Nil(t, res)
-		require.ErrorIs(t, err, errs.ErrValidation)
-		require.ErrorContains(t, err, "can't rename the root")
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, spaces.ErrNotManager)
 	})
 
-	t.Run("Rename with a file with the same name", func(t *testing.T) {
+	t.Run("CreateGrant on the space root", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -706,22 +2929,81 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		storageMock.On("GetByNameAndParent", mock.Anything, "foobar.pdf", *ExampleAliceFile.Parent()).Return(&ExampleAliceFile, nil).Once()
-		storageMock.On("GetByNameAndParent", mock.Anything, "foobar (1).pdf", *ExampleAliceFile.Parent()).Return(nil, errNotFound).Once()
-		toolsMock.ClockMock.On("Now").Return(now).Once()
-		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
-			"last_modified_at": sqlstorage.SQLTime(now),
-			"name":             "foobar (1).pdf",
-		}).Return(nil).Once()
+		res, err := spaceFS.CreateGrant(ctx, &CreateGrantCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+			User:       &users.ExampleBob,
+			Permission: PermissionRead,
+			CreatedBy:  &users.ExampleAlice,
+		})
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrGrantRoot)
+	})
+
+	t.Run("CreateGrant on a file", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Get /foo.pdf
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "foo.pdf", ExampleAliceRoot.ID()).Return(&ExampleAliceFile, nil).Once()
+
+		res, err := spaceFS.CreateGrant(ctx, &CreateGrantCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo.pdf"),
+			User:       &users.ExampleBob,
+			Permission: PermissionRead,
+			CreatedBy:  &users.ExampleAlice,
+		})
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrIsNotDir)
+	})
+
+	t.Run("CreateGrant with an already granted user", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		// Get /dir-a
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "dir-a", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+
+		storageMock.On("GetGrantByINodeAndUser", mock.Anything, ExampleAliceDir.ID(), users.ExampleBob.ID()).Return(&ExampleBobReadGrant, nil).Once()
+
+		res, err := spaceFS.CreateGrant(ctx, &CreateGrantCmd{
+			Path:       NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a"),
+			User:       &users.ExampleBob,
+			Permission: PermissionWrite,
+			CreatedBy:  &users.ExampleAlice,
+		})
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrAlreadyGranted)
+	})
+
+	t.Run("GetUserGrant success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetGrantByID", mock.Anything, ExampleBobReadGrant.ID()).Return(&ExampleBobReadGrant, nil).Once()
 
-		res, err := spaceFS.Rename(ctx, &ExampleAliceFile, "foobar.pdf")
+		res, err := spaceFS.GetUserGrant(ctx, users.ExampleBob.ID(), ExampleBobReadGrant.ID())
 		require.NoError(t, err)
-		assert.NotEqual(t, &ExampleAliceRenamedFile, res)
-		assert.Equal(t, "foobar (1).pdf", res.Name())
-		assert.Equal(t, res.LastModifiedAt(), now)
+		assert.Equal(t, &ExampleBobReadGrant, res)
 	})
 
-	t.Run("Destroy success", func(t *testing.T) {
+	t.Run("GetUserGrant with a grant given to another user", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -729,20 +3011,71 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		// Delete the file system
-		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
-			Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetGrantByID", mock.Anything, ExampleBobReadGrant.ID()).Return(&ExampleBobReadGrant, nil).Once()
+
+		res, err := spaceFS.GetUserGrant(ctx, users.ExampleAlice.ID(), ExampleBobReadGrant.ID())
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("DeleteGrant by the grantee", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		storageMock.On("GetGrantByID", mock.Anything, ExampleBobReadGrant.ID()).Return(&ExampleBobReadGrant, nil).Once()
+		storageMock.On("DeleteGrant", mock.Anything, ExampleBobReadGrant.ID()).Return(nil).Once()
+
+		err := spaceFS.DeleteGrant(ctx, &users.ExampleBob, ExampleBobReadGrant.ID())
+		require.NoError(t, err)
+	})
+
+	t.Run("DeleteGrant with a user not manager", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		user := users.NewFakeUser(t).Build()
+
+		storageMock.On("GetGrantByID", mock.Anything, ExampleBobReadGrant.ID()).Return(&ExampleBobReadGrant, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, user.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleViewer, nil).Once()
+
+		err := spaceFS.DeleteGrant(ctx, user, ExampleBobReadGrant.ID())
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, spaces.ErrNotManager)
+	})
+
+	t.Run("Rename with a write grant", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleBob.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.Role(""), errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
+		storageMock.On("GetAllUserGrants", mock.Anything, users.ExampleBob.ID()).Return([]Grant{ExampleBobWriteGrant}, nil).Once()
+
+		storageMock.On("GetByNameAndParent", mock.Anything, "foobar.txt", *ExampleAliceFile2.Parent()).Return(nil, errNotFound).Once()
 		toolsMock.ClockMock.On("Now").Return(now).Once()
-		storageMock.On("Patch", mock.Anything, ExampleAliceRoot.ID(), map[string]any{
-			"deleted_at":       sqlstorage.SQLTime(now),
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile2.ID(), map[string]any{
 			"last_modified_at": sqlstorage.SQLTime(now),
+			"name":             "foobar.txt",
 		}).Return(nil).Once()
 
-		err := spaceFS.Destroy(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		res, err := spaceFS.Rename(ctx, &users.ExampleBob, &ExampleAliceFile2, "foobar.txt")
 		require.NoError(t, err)
+		assert.Equal(t, "foobar.txt", res.Name())
 	})
 
-	t.Run("Destroy with an non admin user", func(t *testing.T) {
+	t.Run("Rename with a read grant", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -750,11 +3083,17 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		err := spaceFS.Destroy(ctx, &users.ExampleBob, &spaces.ExampleAlicePersonalSpace)
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleBob.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.Role(""), errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
+		storageMock.On("GetAllUserGrants", mock.Anything, users.ExampleBob.ID()).Return([]Grant{ExampleBobReadGrant}, nil).Once()
+
+		res, err := spaceFS.Rename(ctx, &users.ExampleBob, &ExampleAliceFile2, "foobar.txt")
+		assert.Nil(t, res)
 		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, spaces.ErrInvalidSpaceAccess)
 	})
 
-	t.Run("Destroy with a root already removed", func(t *testing.T) {
+	t.Run("Remove the shared folder with a write grant", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -762,15 +3101,51 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		// Delete the file system
-		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
-			Return(nil, errs.ErrNotFound).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleBob.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.Role(""), errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
+		storageMock.On("GetAllUserGrants", mock.Anything, users.ExampleBob.ID()).Return([]Grant{ExampleBobWriteGrant}, nil).Once()
 
-		err := spaceFS.Destroy(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		// GetPathByID for the shared folder
+		storageMock.On("GetByID", mock.Anything, ExampleAliceDir.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceRoot.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "dir-a", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+
+		// Only the content of the shared folder can be modified.
+		err := spaceFS.Remove(ctx, &users.ExampleBob, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a"))
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("CreateDir on the shared folder with a write grant", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleBob.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.Role(""), errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
+		storageMock.On("GetAllUserGrants", mock.Anything, users.ExampleBob.ID()).Return([]Grant{ExampleBobWriteGrant}, nil).Once()
+
+		// GetPathByID for the shared folder
+		storageMock.On("GetByID", mock.Anything, ExampleAliceDir.ID()).Return(&ExampleAliceDir, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceRoot.ID()).Return(&ExampleAliceRoot, nil).Once()
+
+		// Checked for the grant then walked by CreateDir.
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Twice()
+		storageMock.On("GetByNameAndParent", mock.Anything, "dir-a", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Twice()
+
+		// The shared folder already exists so it can be used as a target by the move and copy tasks.
+		res, err := spaceFS.CreateDir(ctx, &CreateDirCmd{
+			Path:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a"),
+			CreatedBy: &users.ExampleBob,
+		})
 		require.NoError(t, err)
+		assert.Equal(t, &ExampleAliceDir, res)
 	})
 
-	t.Run("Destroy with a GetSpaceRoot error", func(t *testing.T) {
+	t.Run("GetProps success", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -778,16 +3153,16 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		// Delete the file system
-		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
-			Return(nil, fmt.Errorf("some-error")).Once()
+		props := []Property{{inodeID: ExampleAliceDir.ID(), namespace: "foo", name: "bar", value: "baz"}}
 
-		err := spaceFS.Destroy(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
-		require.ErrorIs(t, err, errs.ErrInternal)
-		require.ErrorContains(t, err, "some-error")
+		storageMock.On("GetAllINodeProps", mock.Anything, ExampleAliceDir.ID()).Return(props, nil).Once()
+
+		res, err := spaceFS.GetProps(ctx, &ExampleAliceDir)
+		require.NoError(t, err)
+		assert.Equal(t, props, res)
 	})
 
-	t.Run("Destroy with a Patch error", func(t *testing.T) {
+	t.Run("GetProps with a GetAllINodeProps error", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -795,21 +3170,15 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		// Delete the file system
-		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
-			Return(&ExampleAliceRoot, nil).Once()
-		toolsMock.ClockMock.On("Now").Return(now).Once()
-		storageMock.On("Patch", mock.Anything, ExampleAliceRoot.ID(), map[string]any{
-			"deleted_at":       sqlstorage.SQLTime(now),
-			"last_modified_at": sqlstorage.SQLTime(now),
-		}).Return(fmt.Errorf("some-error")).Once()
+		storageMock.On("GetAllINodeProps", mock.Anything, ExampleAliceDir.ID()).Return(nil, fmt.Errorf("some-error")).Once()
 
-		err := spaceFS.Destroy(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		res, err := spaceFS.GetProps(ctx, &ExampleAliceDir)
+		assert.Nil(t, res)
 		require.ErrorIs(t, err, errs.ErrInternal)
 		require.ErrorContains(t, err, "some-error")
 	})
 
-	t.Run("CreateFS success", func(t *testing.T) {
+	t.Run("GetAllProps success", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -817,27 +3186,18 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		rootFS := INode{
-			id:             ExampleAliceRoot.id,
-			parent:         nil,
-			name:           "",
-			spaceID:        spaces.ExampleAlicePersonalSpace.ID(),
-			createdAt:      now,
-			createdBy:      users.ExampleAlice.ID(),
-			lastModifiedAt: now,
-			fileID:         nil,
-		}
+		dirProp := Property{inodeID: ExampleAliceDir.ID(), namespace: "foo", name: "bar", value: "baz"}
+		dirProp2 := Property{inodeID: ExampleAliceDir.ID(), namespace: "foo", name: "qux", value: "quux"}
 
-		toolsMock.ClockMock.On("Now").Return(now).Once()
-		toolsMock.UUIDMock.On("New").Return(ExampleAliceRoot.ID())
-		storageMock.On("Save", mock.Anything, &rootFS).Return(nil)
+		storageMock.On("GetAllINodesProps", mock.Anything, []uuid.UUID{ExampleAliceDir.ID(), ExampleAliceFile.ID()}).
+			Return([]Property{dirProp, dirProp2}, nil).Once()
 
-		res, err := spaceFS.CreateFS(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		res, err := spaceFS.GetAllProps(ctx, []INode{ExampleAliceDir, ExampleAliceFile})
 		require.NoError(t, err)
-		assert.Equal(t, &rootFS, res)
+		assert.Equal(t, map[uuid.UUID][]Property{ExampleAliceDir.ID(): {dirProp, dirProp2}}, res)
 	})
 
-	t.Run("CreateFS with a create storage error", func(t *testing.T) {
+	t.Run("GetAllProps with a GetAllINodesProps error", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
@@ -845,22 +3205,106 @@ func Test_DFS_Service(t *testing.T) {
 		storageMock := newMockStorage(t)
 		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
 
-		toolsMock.ClockMock.On("Now").Return(now).Once()
-		toolsMock.UUIDMock.On("New").Return(ExampleAliceRoot.ID())
-		storageMock.On("Save", mock.Anything, &INode{
-			id:             ExampleAliceRoot.id,
-			parent:         nil,
-			name:           "",
-			spaceID:        spaces.ExampleAlicePersonalSpace.ID(),
-			createdAt:      now,
-			createdBy:      users.ExampleAlice.ID(),
-			lastModifiedAt: now,
-			fileID:         nil,
-		}).Return(fmt.Errorf("some-error"))
+		storageMock.On("GetAllINodesProps", mock.Anything, []uuid.UUID{ExampleAliceDir.ID()}).
+			Return(nil, fmt.Errorf("some-error")).Once()
 
-		res, err := spaceFS.CreateFS(ctx, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace)
+		res, err := spaceFS.GetAllProps(ctx, []INode{ExampleAliceDir})
 		assert.Nil(t, res)
 		require.ErrorIs(t, err, errs.ErrInternal)
 		require.ErrorContains(t, err, "some-error")
 	})
+
+	t.Run("PatchProps success", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		// Get /dir-a
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "dir-a", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+
+		storageMock.On("SaveProp", mock.Anything, &Property{
+			inodeID:   ExampleAliceDir.ID(),
+			namespace: "foo",
+			name:      "bar",
+			lang:      "en",
+			value:     "baz",
+		}).Return(nil).Once()
+		storageMock.On("DeleteProp", mock.Anything, ExampleAliceDir.ID(), "spam", "ham").Return(nil).Once()
+
+		err := spaceFS.PatchProps(ctx, &PatchPropsCmd{
+			Path: NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a"),
+			Patches: []PropPatch{
+				{Namespace: "foo", Name: "bar", Lang: "en", Value: "baz"},
+				{Namespace: "spam", Name: "ham", Remove: true},
+			},
+			PatchedBy: &users.ExampleAlice,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("PatchProps with a validation error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		err := spaceFS.PatchProps(ctx, &PatchPropsCmd{
+			Path:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a"),
+			Patches:   []PropPatch{{Namespace: "foo", Name: ""}},
+			PatchedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
+	t.Run("PatchProps with a read only user", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleBob.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleViewer, nil).Once()
+		storageMock.On("GetAllUserGrants", mock.Anything, users.ExampleBob.ID()).Return([]Grant{}, nil).Once()
+
+		err := spaceFS.PatchProps(ctx, &PatchPropsCmd{
+			Path:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a"),
+			Patches:   []PropPatch{{Namespace: "foo", Name: "bar", Value: "baz"}},
+			PatchedBy: &users.ExampleBob,
+		})
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("PatchProps with a SaveProp error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		toolsMock := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spaceFS := newService(storageMock, filesMock, spacesMock, schedulerMock, toolsMock)
+
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).Return(spaces.RoleEditor, nil).Once()
+
+		// Get /dir-a
+		storageMock.On("GetSpaceRoot", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetByNameAndParent", mock.Anything, "dir-a", ExampleAliceRoot.ID()).Return(&ExampleAliceDir, nil).Once()
+
+		storageMock.On("SaveProp", mock.Anything, mock.Anything).Return(fmt.Errorf("some-error")).Once()
+
+		err := spaceFS.PatchProps(ctx, &PatchPropsCmd{
+			Path:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a"),
+			Patches:   []PropPatch{{Namespace: "foo", Name: "bar", Value: "baz"}},
+			PatchedBy: &users.ExampleAlice,
+		})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
 }
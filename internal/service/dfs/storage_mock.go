@@ -16,6 +16,118 @@ type mockStorage struct {
 	mock.Mock
 }
 
+// CopyAllINodeProps provides a mock function with given fields: ctx, src, dst
+func (_m *mockStorage) CopyAllINodeProps(ctx context.Context, src uuid.UUID, dst uuid.UUID) error {
+	ret := _m.Called(ctx, src, dst)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, src, dst)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteAllINodeGrants provides a mock function with given fields: ctx, inodeID
+func (_m *mockStorage) DeleteAllINodeGrants(ctx context.Context, inodeID uuid.UUID) error {
+	ret := _m.Called(ctx, inodeID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, inodeID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteAllINodeProps provides a mock function with given fields: ctx, inodeID
+func (_m *mockStorage) DeleteAllINodeProps(ctx context.Context, inodeID uuid.UUID) error {
+	ret := _m.Called(ctx, inodeID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, inodeID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteAllUserGrants provides a mock function with given fields: ctx, userID
+func (_m *mockStorage) DeleteAllUserGrants(ctx context.Context, userID uuid.UUID) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteFileContent provides a mock function with given fields: ctx, fileID
+func (_m *mockStorage) DeleteFileContent(ctx context.Context, fileID uuid.UUID) error {
+	ret := _m.Called(ctx, fileID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, fileID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteGrant provides a mock function with given fields: ctx, id
+func (_m *mockStorage) DeleteGrant(ctx context.Context, id uuid.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteProp provides a mock function with given fields: ctx, inodeID, namespace, name
+func (_m *mockStorage) DeleteProp(ctx context.Context, inodeID uuid.UUID, namespace string, name string) error {
+	ret := _m.Called(ctx, inodeID, namespace, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string) error); ok {
+		r0 = rf(ctx, inodeID, namespace, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteVersion provides a mock function with given fields: ctx, id
+func (_m *mockStorage) DeleteVersion(ctx context.Context, id uuid.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAllChildrens provides a mock function with given fields: ctx, parent, cmd
 func (_m *mockStorage) GetAllChildrens(ctx context.Context, parent uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]INode, error) {
 	ret := _m.Called(ctx, parent, cmd)
@@ -42,20 +154,72 @@ func (_m *mockStorage) GetAllChildrens(ctx context.Context, parent uuid.UUID, cm
 	return r0, r1
 }
 
-// GetAllDeleted provides a mock function with given fields: ctx, limit
-func (_m *mockStorage) GetAllDeleted(ctx context.Context, limit int) ([]INode, error) {
-	ret := _m.Called(ctx, limit)
+// GetAllChildrensWithDeleted provides a mock function with given fields: ctx, parent, cmd
+func (_m *mockStorage) GetAllChildrensWithDeleted(ctx context.Context, parent uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]INode, error) {
+	ret := _m.Called(ctx, parent, cmd)
+
+	var r0 []INode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, *sqlstorage.PaginateCmd) ([]INode, error)); ok {
+		return rf(ctx, parent, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, *sqlstorage.PaginateCmd) []INode); ok {
+		r0 = rf(ctx, parent, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]INode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, *sqlstorage.PaginateCmd) error); ok {
+		r1 = rf(ctx, parent, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllDeleted provides a mock function with given fields: ctx, cmd
+func (_m *mockStorage) GetAllDeleted(ctx context.Context, cmd *sqlstorage.PaginateCmd) ([]INode, error) {
+	ret := _m.Called(ctx, cmd)
 
 	var r0 []INode
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) ([]INode, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *sqlstorage.PaginateCmd) ([]INode, error)); ok {
+		return rf(ctx, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sqlstorage.PaginateCmd) []INode); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]INode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sqlstorage.PaginateCmd) error); ok {
+		r1 = rf(ctx, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllFileIDsToIndex provides a mock function with given fields: ctx, limit
+func (_m *mockStorage) GetAllFileIDsToIndex(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	ret := _m.Called(ctx, limit)
+
+	var r0 []uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]uuid.UUID, error)); ok {
 		return rf(ctx, limit)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) []INode); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int) []uuid.UUID); ok {
 		r0 = rf(ctx, limit)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]INode)
+			r0 = ret.Get(0).([]uuid.UUID)
 		}
 	}
 
@@ -68,6 +232,110 @@ func (_m *mockStorage) GetAllDeleted(ctx context.Context, limit int) ([]INode, e
 	return r0, r1
 }
 
+// GetAllINodeGrants provides a mock function with given fields: ctx, inodeID
+func (_m *mockStorage) GetAllINodeGrants(ctx context.Context, inodeID uuid.UUID) ([]Grant, error) {
+	ret := _m.Called(ctx, inodeID)
+
+	var r0 []Grant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]Grant, error)); ok {
+		return rf(ctx, inodeID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []Grant); ok {
+		r0 = rf(ctx, inodeID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Grant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, inodeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllINodeProps provides a mock function with given fields: ctx, inodeID
+func (_m *mockStorage) GetAllINodeProps(ctx context.Context, inodeID uuid.UUID) ([]Property, error) {
+	ret := _m.Called(ctx, inodeID)
+
+	var r0 []Property
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]Property, error)); ok {
+		return rf(ctx, inodeID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []Property); ok {
+		r0 = rf(ctx, inodeID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Property)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, inodeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllINodeVersions provides a mock function with given fields: ctx, inodeID
+func (_m *mockStorage) GetAllINodeVersions(ctx context.Context, inodeID uuid.UUID) ([]FileVersion, error) {
+	ret := _m.Called(ctx, inodeID)
+
+	var r0 []FileVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]FileVersion, error)); ok {
+		return rf(ctx, inodeID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []FileVersion); ok {
+		r0 = rf(ctx, inodeID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]FileVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, inodeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllINodesProps provides a mock function with given fields: ctx, inodeIDs
+func (_m *mockStorage) GetAllINodesProps(ctx context.Context, inodeIDs []uuid.UUID) ([]Property, error) {
+	ret := _m.Called(ctx, inodeIDs)
+
+	var r0 []Property
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) ([]Property, error)); ok {
+		return rf(ctx, inodeIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) []Property); ok {
+		r0 = rf(ctx, inodeIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Property)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = rf(ctx, inodeIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllInodesWithFileID provides a mock function with given fields: ctx, fileID
 func (_m *mockStorage) GetAllInodesWithFileID(ctx context.Context, fileID uuid.UUID) ([]INode, error) {
 	ret := _m.Called(ctx, fileID)
@@ -94,6 +362,110 @@ func (_m *mockStorage) GetAllInodesWithFileID(ctx context.Context, fileID uuid.U
 	return r0, r1
 }
 
+// GetAllSpaceDeleted provides a mock function with given fields: ctx, spaceID, cmd
+func (_m *mockStorage) GetAllSpaceDeleted(ctx context.Context, spaceID uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]INode, error) {
+	ret := _m.Called(ctx, spaceID, cmd)
+
+	var r0 []INode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, *sqlstorage.PaginateCmd) ([]INode, error)); ok {
+		return rf(ctx, spaceID, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, *sqlstorage.PaginateCmd) []INode); ok {
+		r0 = rf(ctx, spaceID, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]INode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, *sqlstorage.PaginateCmd) error); ok {
+		r1 = rf(ctx, spaceID, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllUserGrants provides a mock function with given fields: ctx, userID
+func (_m *mockStorage) GetAllUserGrants(ctx context.Context, userID uuid.UUID) ([]Grant, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []Grant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]Grant, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []Grant); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Grant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllVersionedINodeIDs provides a mock function with given fields: ctx, cmd
+func (_m *mockStorage) GetAllVersionedINodeIDs(ctx context.Context, cmd *sqlstorage.PaginateCmd) ([]uuid.UUID, error) {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 []uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sqlstorage.PaginateCmd) ([]uuid.UUID, error)); ok {
+		return rf(ctx, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sqlstorage.PaginateCmd) []uuid.UUID); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sqlstorage.PaginateCmd) error); ok {
+		r1 = rf(ctx, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllVersionsWithFileID provides a mock function with given fields: ctx, fileID
+func (_m *mockStorage) GetAllVersionsWithFileID(ctx context.Context, fileID uuid.UUID) ([]FileVersion, error) {
+	ret := _m.Called(ctx, fileID)
+
+	var r0 []FileVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]FileVersion, error)); ok {
+		return rf(ctx, fileID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []FileVersion); ok {
+		r0 = rf(ctx, fileID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]FileVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, fileID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *mockStorage) GetByID(ctx context.Context, id uuid.UUID) (*INode, error) {
 	ret := _m.Called(ctx, id)
@@ -172,6 +544,58 @@ func (_m *mockStorage) GetDeleted(ctx context.Context, id uuid.UUID) (*INode, er
 	return r0, r1
 }
 
+// GetGrantByID provides a mock function with given fields: ctx, id
+func (_m *mockStorage) GetGrantByID(ctx context.Context, id uuid.UUID) (*Grant, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *Grant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*Grant, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *Grant); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Grant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGrantByINodeAndUser provides a mock function with given fields: ctx, inodeID, userID
+func (_m *mockStorage) GetGrantByINodeAndUser(ctx context.Context, inodeID uuid.UUID, userID uuid.UUID) (*Grant, error) {
+	ret := _m.Called(ctx, inodeID, userID)
+
+	var r0 *Grant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (*Grant, error)); ok {
+		return rf(ctx, inodeID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) *Grant); ok {
+		r0 = rf(ctx, inodeID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Grant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, inodeID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSpaceRoot provides a mock function with given fields: ctx, spaceID
 func (_m *mockStorage) GetSpaceRoot(ctx context.Context, spaceID uuid.UUID) (*INode, error) {
 	ret := _m.Called(ctx, spaceID)
@@ -246,6 +670,80 @@ func (_m *mockStorage) GetSumRootsSize(ctx context.Context) (uint64, error) {
 	return r0, r1
 }
 
+// GetSumSpaceVersionsSize provides a mock function with given fields: ctx, spaceID
+func (_m *mockStorage) GetSumSpaceVersionsSize(ctx context.Context, spaceID uuid.UUID) (uint64, error) {
+	ret := _m.Called(ctx, spaceID)
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (uint64, error)); ok {
+		return rf(ctx, spaceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) uint64); ok {
+		r0 = rf(ctx, spaceID)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, spaceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSumUserFilesSize provides a mock function with given fields: ctx, userID
+func (_m *mockStorage) GetSumUserFilesSize(ctx context.Context, userID uuid.UUID) (uint64, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (uint64, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) uint64); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetVersionByID provides a mock function with given fields: ctx, id
+func (_m *mockStorage) GetVersionByID(ctx context.Context, id uuid.UUID) (*FileVersion, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *FileVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*FileVersion, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *FileVersion); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*FileVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // HardDelete provides a mock function with given fields: ctx, id
 func (_m *mockStorage) HardDelete(ctx context.Context, id uuid.UUID) error {
 	ret := _m.Called(ctx, id)
@@ -260,6 +758,20 @@ func (_m *mockStorage) HardDelete(ctx context.Context, id uuid.UUID) error {
 	return r0
 }
 
+// MoveAllINodeGrants provides a mock function with given fields: ctx, inodeID, spaceID
+func (_m *mockStorage) MoveAllINodeGrants(ctx context.Context, inodeID uuid.UUID, spaceID uuid.UUID) error {
+	ret := _m.Called(ctx, inodeID, spaceID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, inodeID, spaceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Patch provides a mock function with given fields: ctx, inode, fields
 func (_m *mockStorage) Patch(ctx context.Context, inode uuid.UUID, fields map[string]interface{}) error {
 	ret := _m.Called(ctx, inode, fields)
@@ -288,6 +800,114 @@ func (_m *mockStorage) Save(ctx context.Context, dir *INode) error {
 	return r0
 }
 
+// SaveFileContent provides a mock function with given fields: ctx, fileID, content
+func (_m *mockStorage) SaveFileContent(ctx context.Context, fileID uuid.UUID, content string) error {
+	ret := _m.Called(ctx, fileID, content)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, fileID, content)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveGrant provides a mock function with given fields: ctx, grant
+func (_m *mockStorage) SaveGrant(ctx context.Context, grant *Grant) error {
+	ret := _m.Called(ctx, grant)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *Grant) error); ok {
+		r0 = rf(ctx, grant)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveProp provides a mock function with given fields: ctx, prop
+func (_m *mockStorage) SaveProp(ctx context.Context, prop *Property) error {
+	ret := _m.Called(ctx, prop)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *Property) error); ok {
+		r0 = rf(ctx, prop)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveVersion provides a mock function with given fields: ctx, version
+func (_m *mockStorage) SaveVersion(ctx context.Context, version *FileVersion) error {
+	ret := _m.Called(ctx, version)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *FileVersion) error); ok {
+		r0 = rf(ctx, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Search provides a mock function with given fields: ctx, spaceIDs, cmd, paginateCmd
+func (_m *mockStorage) Search(ctx context.Context, spaceIDs []uuid.UUID, cmd *SearchCmd, paginateCmd *sqlstorage.PaginateCmd) ([]SearchResult, error) {
+	ret := _m.Called(ctx, spaceIDs, cmd, paginateCmd)
+
+	var r0 []SearchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID, *SearchCmd, *sqlstorage.PaginateCmd) ([]SearchResult, error)); ok {
+		return rf(ctx, spaceIDs, cmd, paginateCmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID, *SearchCmd, *sqlstorage.PaginateCmd) []SearchResult); ok {
+		r0 = rf(ctx, spaceIDs, cmd, paginateCmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]SearchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uuid.UUID, *SearchCmd, *sqlstorage.PaginateCmd) error); ok {
+		r1 = rf(ctx, spaceIDs, cmd, paginateCmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchTree provides a mock function with given fields: ctx, root, rootPath, cmd, paginateCmd
+func (_m *mockStorage) SearchTree(ctx context.Context, root *INode, rootPath string, cmd *SearchTreeCmd, paginateCmd *sqlstorage.PaginateCmd) ([]SearchResult, error) {
+	ret := _m.Called(ctx, root, rootPath, cmd, paginateCmd)
+
+	var r0 []SearchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *INode, string, *SearchTreeCmd, *sqlstorage.PaginateCmd) ([]SearchResult, error)); ok {
+		return rf(ctx, root, rootPath, cmd, paginateCmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *INode, string, *SearchTreeCmd, *sqlstorage.PaginateCmd) []SearchResult); ok {
+		r0 = rf(ctx, root, rootPath, cmd, paginateCmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]SearchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *INode, string, *SearchTreeCmd, *sqlstorage.PaginateCmd) error); ok {
+		r1 = rf(ctx, root, rootPath, cmd, paginateCmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // newMockStorage creates a new instance of mockStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func newMockStorage(t interface {
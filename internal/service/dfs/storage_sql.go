@@ -17,7 +17,7 @@ const tableName = "fs_inodes"
 
 var errNotFound = errors.New("not found")
 
-var allFiels = []string{"id", "name", "parent", "space_id", "size", "last_modified_at", "created_at", "created_by", "file_id"}
+var allFiels = []string{"id", "name", "parent", "space_id", "size", "last_modified_at", "created_at", "created_by", "file_id", "deleted_at"}
 
 type sqlStorage struct {
 	db sqlstorage.Querier
@@ -28,25 +28,33 @@ func newSqlStorage(db sqlstorage.Querier) *sqlStorage {
 }
 
 func (s *sqlStorage) Save(ctx context.Context, i *INode) error {
-	_, err := sq.
-		Insert(tableName).
-		Columns(allFiels...).
-		Values(i.id,
-			i.name,
-			i.parent,
-			i.spaceID,
-			i.size,
-			ptr.To(sqlstorage.SQLTime(i.lastModifiedAt)),
-			ptr.To(sqlstorage.SQLTime(i.createdAt)),
-			i.createdBy,
-			i.fileID).
-		RunWith(s.db).
-		ExecContext(ctx)
-	if err != nil {
-		return fmt.Errorf("sql error: %w", err)
-	}
+	return sqlstorage.RunInTx(ctx, s.db, func(tx sqlstorage.Querier) error {
+		_, err := sq.
+			Insert(tableName).
+			Columns(allFiels...).
+			Values(i.id,
+				i.name,
+				i.parent,
+				i.spaceID,
+				i.size,
+				ptr.To(sqlstorage.SQLTime(i.lastModifiedAt)),
+				ptr.To(sqlstorage.SQLTime(i.createdAt)),
+				i.createdBy,
+				i.fileID,
+				nullableTime(i.deletedAt)).
+			RunWith(tx).
+			ExecContext(ctx)
+		if err != nil {
+			return fmt.Errorf("sql error: %w", err)
+		}
 
-	return nil
+		err = indexTree(ctx, tx, i.id)
+		if err != nil {
+			return fmt.Errorf("failed to index the inode: %w", err)
+		}
+
+		return nil
+	})
 }
 
 func (s *sqlStorage) GetSpaceRoot(ctx context.Context, spaceID uuid.UUID) (*INode, error) {
@@ -63,16 +71,45 @@ func (s *sqlStorage) Patch(ctx context.Context, inode uuid.UUID, fields map[stri
 		}
 	}
 
-	_, err := sq.Update(tableName).
-		SetMap(fields).
-		Where(sq.Eq{"id": inode}).
-		RunWith(s.db).
-		ExecContext(ctx)
-	if err != nil {
-		return fmt.Errorf("sql error: %w", err)
-	}
+	return sqlstorage.RunInTx(ctx, s.db, func(tx sqlstorage.Querier) error {
+		_, err := sq.Update(tableName).
+			SetMap(fields).
+			Where(sq.Eq{"id": inode}).
+			RunWith(tx).
+			ExecContext(ctx)
+		if err != nil {
+			return fmt.Errorf("sql error: %w", err)
+		}
+
+		// The search index is only updated when the path of the inode changes.
+		_, nameChanged := fields["name"]
+		_, parentChanged := fields["parent"]
+		_, deletedChanged := fields["deleted_at"]
+		if nameChanged || parentChanged || deletedChanged {
+			err = unindexTree(ctx, tx, inode)
+			if err != nil {
+				return fmt.Errorf("failed to unindex the inode: %w", err)
+			}
+
+			err = indexTree(ctx, tx, inode)
+			if err != nil {
+				return fmt.Errorf("failed to index the inode: %w", err)
+			}
+		}
 
-	return nil
+		if spaceID, ok := fields["space_id"]; ok {
+			_, err = sq.Update(searchContentTableName).
+				Set("space_id", spaceID).
+				Where(sq.Eq{"inode_id": inode}).
+				RunWith(tx).
+				ExecContext(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to update the indexed space: %w", err)
+			}
+		}
+
+		return nil
+	})
 }
 
 func (s *sqlStorage) GetByID(ctx context.Context, id uuid.UUID) (*INode, error) {
@@ -80,22 +117,49 @@ func (s *sqlStorage) GetByID(ctx context.Context, id uuid.UUID) (*INode, error)
 }
 
 func (s *sqlStorage) HardDelete(ctx context.Context, id uuid.UUID) error {
-	_, err := sq.
-		Delete(tableName).
-		Where(sq.Eq{"id": string(id)}).
+	return sqlstorage.RunInTx(ctx, s.db, func(tx sqlstorage.Querier) error {
+		_, err := sq.
+			Delete(tableName).
+			Where(sq.Eq{"id": string(id)}).
+			RunWith(tx).
+			ExecContext(ctx)
+		if err != nil {
+			return fmt.Errorf("sql error: %w", err)
+		}
+
+		_, err = sq.
+			Delete(searchContentTableName).
+			Where(sq.Eq{"inode_id": string(id)}).
+			RunWith(tx).
+			ExecContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to unindex the inode: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *sqlStorage) GetAllChildrens(ctx context.Context, parent uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]INode, error) {
+	rows, err := sqlstorage.PaginateSelection(sq.
+		Select(allFiels...).
+		Where(sq.Eq{"parent": string(parent), "deleted_at": nil}).
+		From(tableName), cmd).
 		RunWith(s.db).
-		ExecContext(ctx)
+		QueryContext(ctx)
 	if err != nil {
-		return fmt.Errorf("sql error: %w", err)
+		return nil, fmt.Errorf("sql error: %w", err)
 	}
 
-	return nil
+	defer rows.Close()
+
+	return s.scanRows(rows)
 }
 
-func (s *sqlStorage) GetAllChildrens(ctx context.Context, parent uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]INode, error) {
+func (s *sqlStorage) GetAllChildrensWithDeleted(ctx context.Context, parent uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]INode, error) {
 	rows, err := sqlstorage.PaginateSelection(sq.
 		Select(allFiels...).
-		Where(sq.Eq{"parent": string(parent), "deleted_at": nil}).
+		Where(sq.Eq{"parent": string(parent)}).
 		From(tableName), cmd).
 		RunWith(s.db).
 		QueryContext(ctx)
@@ -112,12 +176,28 @@ func (s *sqlStorage) GetDeleted(ctx context.Context, id uuid.UUID) (*INode, erro
 	return s.getByKeys(ctx, sq.Eq{"id": id}, sq.NotEq{"deleted_at": nil})
 }
 
-func (s *sqlStorage) GetAllDeleted(ctx context.Context, limit int) ([]INode, error) {
-	rows, err := sq.
+func (s *sqlStorage) GetAllDeleted(ctx context.Context, cmd *sqlstorage.PaginateCmd) ([]INode, error) {
+	rows, err := sqlstorage.PaginateSelection(sq.
 		Select(allFiels...).
 		From(tableName).
-		Where(sq.NotEq{"deleted_at": nil}).
-		Limit(uint64(limit)).
+		Where(sq.NotEq{"deleted_at": nil}), cmd).
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
+func (s *sqlStorage) GetAllSpaceDeleted(ctx context.Context, spaceID uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]INode, error) {
+	rows, err := sqlstorage.PaginateSelection(sq.
+		Select(allFiels...).
+		From(tableName).
+		Where(sq.Eq{"space_id": spaceID}).
+		Where(sq.NotEq{"deleted_at": nil, "parent": nil}), cmd).
 		RunWith(s.db).
 		QueryContext(ctx)
 	if err != nil {
@@ -136,6 +216,7 @@ func (s *sqlStorage) scanRows(rows *sql.Rows) ([]INode, error) {
 		var res INode
 		var sqlLastModifiedAt sqlstorage.SQLTime
 		var sqlCreatedAt sqlstorage.SQLTime
+		var sqlDeletedAt *sqlstorage.SQLTime
 
 		err := rows.Scan(&res.id,
 			&res.name,
@@ -145,13 +226,15 @@ func (s *sqlStorage) scanRows(rows *sql.Rows) ([]INode, error) {
 			&sqlLastModifiedAt,
 			&sqlCreatedAt,
 			&res.createdBy,
-			&res.fileID)
+			&res.fileID,
+			&sqlDeletedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan a row: %w", err)
 		}
 
 		res.lastModifiedAt = sqlLastModifiedAt.Time()
 		res.createdAt = sqlCreatedAt.Time()
+		res.deletedAt = nullTime(sqlDeletedAt)
 		inodes = append(inodes, res)
 	}
 
@@ -202,6 +285,37 @@ func (s *sqlStorage) GetSumRootsSize(ctx context.Context) (uint64, error) {
 	return *size, nil
 }
 
+// GetSumUserFilesSize returns the size of all the files created by the user,
+// their versions included. The files inside a trashed directory are not
+// counted, even before their removal by the garbage collector, as they are
+// already removed from the space size.
+//
+// The parents are walked up from the user files, so only the trees containing
+// a file of the user are read: a file is counted when the walk reaches the
+// root without crossing a trashed directory.
+func (s *sqlStorage) GetSumUserFilesSize(ctx context.Context, userID uuid.UUID) (uint64, error) {
+	var size *uint64
+
+	err := s.db.QueryRowContext(ctx, `WITH RECURSIVE ancestors(id, size, parent) AS (
+  SELECT i.id, i.size + COALESCE((SELECT SUM(v.size) FROM `+versionsTableName+` v WHERE v.inode_id = i.id), 0), i.parent
+    FROM `+tableName+` i
+    WHERE i.created_by = ? AND i.deleted_at IS NULL AND i.file_id IS NOT NULL
+  UNION ALL
+  SELECT a.id, a.size, p.parent FROM ancestors a JOIN `+tableName+` p ON p.id = a.parent
+    WHERE p.deleted_at IS NULL
+)
+SELECT SUM(size) FROM ancestors WHERE parent IS NULL`, string(userID)).Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("sql error: %w", err)
+	}
+
+	if size == nil {
+		return 0, nil
+	}
+
+	return *size, nil
+}
+
 func (s *sqlStorage) GetAllInodesWithFileID(ctx context.Context, fileID uuid.UUID) ([]INode, error) {
 	rows, err := sq.
 		Select(allFiels...).
@@ -234,6 +348,7 @@ func (s *sqlStorage) getByKeys(ctx context.Context, wheres ...any) (*INode, erro
 	var res INode
 	var sqlLastModifiedAt sqlstorage.SQLTime
 	var sqlCreatedAt sqlstorage.SQLTime
+	var sqlDeletedAt *sqlstorage.SQLTime
 
 	err := query.
 		RunWith(s.db).
@@ -246,7 +361,8 @@ func (s *sqlStorage) getByKeys(ctx context.Context, wheres ...any) (*INode, erro
 			&sqlLastModifiedAt,
 			&sqlCreatedAt,
 			&res.createdBy,
-			&res.fileID)
+			&res.fileID,
+			&sqlDeletedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, errNotFound
 	}
@@ -257,6 +373,25 @@ func (s *sqlStorage) getByKeys(ctx context.Context, wheres ...any) (*INode, erro
 
 	res.lastModifiedAt = sqlLastModifiedAt.Time()
 	res.createdAt = sqlCreatedAt.Time()
+	res.deletedAt = nullTime(sqlDeletedAt)
 
 	return &res, nil
 }
+
+// nullableTime stores the zero time as NULL.
+func nullableTime(t time.Time) *sqlstorage.SQLTime {
+	if t.IsZero() {
+		return nil
+	}
+
+	return ptr.To(sqlstorage.SQLTime(t))
+}
+
+// nullTime returns the zero time for a NULL value.
+func nullTime(t *sqlstorage.SQLTime) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return t.Time()
+}
@@ -0,0 +1,182 @@
+package dfs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const grantsTableName = "fs_grants"
+
+var allGrantFields = []string{"id", "space_id", "inode_id", "user_id", "permission", "created_at", "created_by"}
+
+func (s *sqlStorage) SaveGrant(ctx context.Context, grant *Grant) error {
+	_, err := sq.
+		Insert(grantsTableName).
+		Columns(allGrantFields...).
+		Values(grant.id,
+			grant.spaceID,
+			grant.inodeID,
+			grant.userID,
+			grant.permission,
+			ptr.To(sqlstorage.SQLTime(grant.createdAt)),
+			grant.createdBy).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) GetGrantByID(ctx context.Context, id uuid.UUID) (*Grant, error) {
+	return s.getGrantByKeys(ctx, sq.Eq{"id": id})
+}
+
+func (s *sqlStorage) GetGrantByINodeAndUser(ctx context.Context, inodeID, userID uuid.UUID) (*Grant, error) {
+	return s.getGrantByKeys(ctx, sq.Eq{"inode_id": inodeID, "user_id": userID})
+}
+
+// GetAllUserGrants returns all the grants given to the user, the oldest first.
+func (s *sqlStorage) GetAllUserGrants(ctx context.Context, userID uuid.UUID) ([]Grant, error) {
+	return s.getAllGrantsByKeys(ctx, sq.Eq{"user_id": userID})
+}
+
+// GetAllINodeGrants returns all the grants given on the inode, the oldest first.
+func (s *sqlStorage) GetAllINodeGrants(ctx context.Context, inodeID uuid.UUID) ([]Grant, error) {
+	return s.getAllGrantsByKeys(ctx, sq.Eq{"inode_id": inodeID})
+}
+
+func (s *sqlStorage) DeleteGrant(ctx context.Context, id uuid.UUID) error {
+	return s.deleteGrantsByKeys(ctx, sq.Eq{"id": id})
+}
+
+func (s *sqlStorage) DeleteAllINodeGrants(ctx context.Context, inodeID uuid.UUID) error {
+	return s.deleteGrantsByKeys(ctx, sq.Eq{"inode_id": inodeID})
+}
+
+// MoveAllINodeGrants attaches all the grants given on the inode to the given
+// space.
+func (s *sqlStorage) MoveAllINodeGrants(ctx context.Context, inodeID, spaceID uuid.UUID) error {
+	_, err := sq.
+		Update(grantsTableName).
+		Set("space_id", spaceID).
+		Where(sq.Eq{"inode_id": inodeID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) DeleteAllUserGrants(ctx context.Context, userID uuid.UUID) error {
+	return s.deleteGrantsByKeys(ctx, sq.Eq{"user_id": userID})
+}
+
+func (s *sqlStorage) getGrantByKeys(ctx context.Context, wheres ...any) (*Grant, error) {
+	var res Grant
+	var sqlCreatedAt sqlstorage.SQLTime
+
+	query := sq.
+		Select(allGrantFields...).
+		From(grantsTableName)
+
+	for _, where := range wheres {
+		query = query.Where(where)
+	}
+
+	err := query.
+		RunWith(s.db).
+		ScanContext(ctx,
+			&res.id,
+			&res.spaceID,
+			&res.inodeID,
+			&res.userID,
+			&res.permission,
+			&sqlCreatedAt,
+			&res.createdBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	res.createdAt = sqlCreatedAt.Time()
+
+	return &res, nil
+}
+
+func (s *sqlStorage) getAllGrantsByKeys(ctx context.Context, wheres ...any) ([]Grant, error) {
+	query := sq.
+		Select(allGrantFields...).
+		From(grantsTableName).
+		OrderBy("created_at", "id")
+
+	for _, where := range wheres {
+		query = query.Where(where)
+	}
+
+	rows, err := query.
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	grants := []Grant{}
+
+	for rows.Next() {
+		var res Grant
+		var sqlCreatedAt sqlstorage.SQLTime
+
+		err := rows.Scan(&res.id,
+			&res.spaceID,
+			&res.inodeID,
+			&res.userID,
+			&res.permission,
+			&sqlCreatedAt,
+			&res.createdBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		res.createdAt = sqlCreatedAt.Time()
+		grants = append(grants, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return grants, nil
+}
+
+func (s *sqlStorage) deleteGrantsByKeys(ctx context.Context, wheres ...any) error {
+	query := sq.Delete(grantsTableName)
+
+	for _, where := range wheres {
+		query = query.Where(where)
+	}
+
+	_, err := query.
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package dfs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func TestGrantSqlstore(t *testing.T) {
+	ctx := context.Background()
+
+	db := sqlstorage.NewTestStorage(t)
+	store := newSqlStorage(db)
+
+	// Data
+	user := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	user2 := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	space := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
+	rootInode := NewFakeINode(t).WithSpace(space).IsRootDirectory().CreatedBy(user).BuildAndStore(ctx, db)
+	dir := NewFakeINode(t).WithSpace(space).WithParent(rootInode).IsDirectory().CreatedBy(user).BuildAndStore(ctx, db)
+	grant := NewFakeGrant(t, dir, user2).WithPermission(PermissionWrite).Build()
+
+	t.Run("SaveGrant success", func(t *testing.T) {
+		err := store.SaveGrant(ctx, grant)
+		require.NoError(t, err)
+	})
+
+	t.Run("SaveGrant a second time for the same user and inode", func(t *testing.T) {
+		other := NewFakeGrant(t, dir, user2).Build()
+
+		err := store.SaveGrant(ctx, other)
+		require.ErrorContains(t, err, "UNIQUE constraint failed")
+	})
+
+	t.Run("GetGrantByID success", func(t *testing.T) {
+		res, err := store.GetGrantByID(ctx, grant.ID())
+		require.NoError(t, err)
+		require.Equal(t, grant, res)
+	})
+
+	t.Run("GetGrantByID not found", func(t *testing.T) {
+		res, err := store.GetGrantByID(ctx, uuid.UUID("some-invalid-id"))
+		require.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("GetGrantByINodeAndUser success", func(t *testing.T) {
+		res, err := store.GetGrantByINodeAndUser(ctx, dir.ID(), user2.ID())
+		require.NoError(t, err)
+		require.Equal(t, grant, res)
+	})
+
+	t.Run("GetGrantByINodeAndUser not found", func(t *testing.T) {
+		res, err := store.GetGrantByINodeAndUser(ctx, dir.ID(), user.ID())
+		require.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("GetAllUserGrants success", func(t *testing.T) {
+		res, err := store.GetAllUserGrants(ctx, user2.ID())
+		require.NoError(t, err)
+		require.Equal(t, []Grant{*grant}, res)
+	})
+
+	t.Run("GetAllINodeGrants success", func(t *testing.T) {
+		res, err := store.GetAllINodeGrants(ctx, dir.ID())
+		require.NoError(t, err)
+		require.Equal(t, []Grant{*grant}, res)
+	})
+
+	t.Run("MoveAllINodeGrants success", func(t *testing.T) {
+		otherSpace := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
+
+		err := store.MoveAllINodeGrants(ctx, dir.ID(), otherSpace.ID())
+		require.NoError(t, err)
+
+		res, err := store.GetGrantByID(ctx, grant.ID())
+		require.NoError(t, err)
+		require.Equal(t, otherSpace.ID(), res.SpaceID())
+	})
+
+	t.Run("DeleteGrant success", func(t *testing.T) {
+		err := store.DeleteGrant(ctx, grant.ID())
+		require.NoError(t, err)
+
+		res, err := store.GetGrantByID(ctx, grant.ID())
+		require.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("DeleteAllINodeGrants success", func(t *testing.T) {
+		grant := NewFakeGrant(t, dir, user2).BuildAndStore(ctx, db)
+
+		err := store.DeleteAllINodeGrants(ctx, dir.ID())
+		require.NoError(t, err)
+
+		res, err := store.GetGrantByID(ctx, grant.ID())
+		require.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("DeleteAllUserGrants success", func(t *testing.T) {
+		grant := NewFakeGrant(t, dir, user2).BuildAndStore(ctx, db)
+
+		err := store.DeleteAllUserGrants(ctx, user2.ID())
+		require.NoError(t, err)
+
+		res, err := store.GetGrantByID(ctx, grant.ID())
+		require.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+}
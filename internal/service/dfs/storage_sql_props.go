@@ -0,0 +1,137 @@
+package dfs
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const propsTableName = "fs_props"
+
+var allPropFields = []string{"inode_id", "namespace", "name", "lang", "value"}
+
+// SaveProp creates the property or replaces the value of an existing one.
+func (s *sqlStorage) SaveProp(ctx context.Context, prop *Property) error {
+	_, err := sq.
+		Insert(propsTableName).
+		Columns(allPropFields...).
+		Values(prop.inodeID,
+			prop.namespace,
+			prop.name,
+			prop.lang,
+			prop.value).
+		Suffix("ON CONFLICT DO UPDATE SET lang = ?, value = ?", prop.lang, prop.value).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+// GetAllINodeProps returns all the properties of the inode sorted by namespace
+// and name.
+func (s *sqlStorage) GetAllINodeProps(ctx context.Context, inodeID uuid.UUID) ([]Property, error) {
+	return s.getAllPropsByKeys(ctx, sq.Eq{"inode_id": inodeID})
+}
+
+// GetAllINodesProps returns all the properties of the given inodes sorted by
+// inode, namespace and name.
+func (s *sqlStorage) GetAllINodesProps(ctx context.Context, inodeIDs []uuid.UUID) ([]Property, error) {
+	if len(inodeIDs) == 0 {
+		return []Property{}, nil
+	}
+
+	return s.getAllPropsByKeys(ctx, sq.Eq{"inode_id": inodeIDs})
+}
+
+func (s *sqlStorage) getAllPropsByKeys(ctx context.Context, wheres ...any) ([]Property, error) {
+	query := sq.
+		Select(allPropFields...).
+		From(propsTableName).
+		OrderBy("inode_id", "namespace", "name")
+
+	for _, where := range wheres {
+		query = query.Where(where)
+	}
+
+	rows, err := query.
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	props := []Property{}
+
+	for rows.Next() {
+		var res Property
+
+		err := rows.Scan(&res.inodeID,
+			&res.namespace,
+			&res.name,
+			&res.lang,
+			&res.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		props = append(props, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return props, nil
+}
+
+// CopyAllINodeProps duplicates all the properties of src to dst.
+func (s *sqlStorage) CopyAllINodeProps(ctx context.Context, src, dst uuid.UUID) error {
+	_, err := sq.
+		Insert(propsTableName).
+		Columns("namespace", "name", "lang", "value", "inode_id").
+		Select(sq.
+			Select("namespace", "name", "lang", "value").
+			Column("? AS inode_id", dst).
+			From(propsTableName).
+			Where(sq.Eq{"inode_id": src})).
+		Suffix("ON CONFLICT DO NOTHING").
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) DeleteProp(ctx context.Context, inodeID uuid.UUID, namespace, name string) error {
+	return s.deletePropsByKeys(ctx, sq.Eq{"inode_id": inodeID, "namespace": namespace, "name": name})
+}
+
+func (s *sqlStorage) DeleteAllINodeProps(ctx context.Context, inodeID uuid.UUID) error {
+	return s.deletePropsByKeys(ctx, sq.Eq{"inode_id": inodeID})
+}
+
+func (s *sqlStorage) deletePropsByKeys(ctx context.Context, wheres ...any) error {
+	query := sq.Delete(propsTableName)
+
+	for _, where := range wheres {
+		query = query.Where(where)
+	}
+
+	_, err := query.
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package dfs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func TestPropSqlstore(t *testing.T) {
+	ctx := context.Background()
+
+	db := sqlstorage.NewTestStorage(t)
+	store := newSqlStorage(db)
+
+	// Data
+	user := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	space := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
+	rootInode := NewFakeINode(t).WithSpace(space).IsRootDirectory().CreatedBy(user).BuildAndStore(ctx, db)
+	dir := NewFakeINode(t).WithSpace(space).WithParent(rootInode).IsDirectory().CreatedBy(user).BuildAndStore(ctx, db)
+	dir2 := NewFakeINode(t).WithSpace(space).WithParent(rootInode).IsDirectory().CreatedBy(user).BuildAndStore(ctx, db)
+
+	prop := Property{inodeID: dir.ID(), namespace: "http://example.com/ns", name: "color", lang: "en", value: "red"}
+	prop2 := Property{inodeID: dir.ID(), namespace: "", name: "author", lang: "", value: "<name>Jane</name>"}
+
+	t.Run("SaveProp success", func(t *testing.T) {
+		err := store.SaveProp(ctx, &prop)
+		require.NoError(t, err)
+
+		err = store.SaveProp(ctx, &prop2)
+		require.NoError(t, err)
+	})
+
+	t.Run("GetAllINodeProps success", func(t *testing.T) {
+		res, err := store.GetAllINodeProps(ctx, dir.ID())
+		require.NoError(t, err)
+		require.Equal(t, []Property{prop2, prop}, res)
+	})
+
+	t.Run("GetAllINodeProps with no props", func(t *testing.T) {
+		res, err := store.GetAllINodeProps(ctx, rootInode.ID())
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("GetAllINodesProps success", func(t *testing.T) {
+		res, err := store.GetAllINodesProps(ctx, []uuid.UUID{dir.ID(), rootInode.ID()})
+		require.NoError(t, err)
+		require.Equal(t, []Property{prop2, prop}, res)
+	})
+
+	t.Run("GetAllINodesProps with no inodes", func(t *testing.T) {
+		res, err := store.GetAllINodesProps(ctx, []uuid.UUID{})
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("SaveProp a second time replaces the value", func(t *testing.T) {
+		prop.value = "blue"
+		prop.lang = "fr"
+
+		err := store.SaveProp(ctx, &prop)
+		require.NoError(t, err)
+
+		res, err := store.GetAllINodeProps(ctx, dir.ID())
+		require.NoError(t, err)
+		require.Equal(t, []Property{prop2, prop}, res)
+	})
+
+	t.Run("CopyAllINodeProps success", func(t *testing.T) {
+		err := store.CopyAllINodeProps(ctx, dir.ID(), dir2.ID())
+		require.NoError(t, err)
+
+		res, err := store.GetAllINodeProps(ctx, dir2.ID())
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, dir2.ID(), res[0].INodeID())
+		require.Equal(t, prop2.Name(), res[0].Name())
+		require.Equal(t, prop2.Value(), res[0].Value())
+		require.Equal(t, dir2.ID(), res[1].INodeID())
+		require.Equal(t, prop.Namespace(), res[1].Namespace())
+		require.Equal(t, prop.Lang(), res[1].Lang())
+		require.Equal(t, prop.Value(), res[1].Value())
+	})
+
+	t.Run("DeleteProp success", func(t *testing.T) {
+		err := store.DeleteProp(ctx, dir.ID(), prop.Namespace(), prop.Name())
+		require.NoError(t, err)
+
+		res, err := store.GetAllINodeProps(ctx, dir.ID())
+		require.NoError(t, err)
+		require.Equal(t, []Property{prop2}, res)
+	})
+
+	t.Run("DeleteProp with an unknown prop", func(t *testing.T) {
+		err := store.DeleteProp(ctx, dir.ID(), "unknown", "prop")
+		require.NoError(t, err)
+	})
+
+	t.Run("DeleteAllINodeProps success", func(t *testing.T) {
+		err := store.DeleteAllINodeProps(ctx, dir2.ID())
+		require.NoError(t, err)
+
+		res, err := store.GetAllINodeProps(ctx, dir2.ID())
+		require.NoError(t, err)
+		require.Empty(t, res)
+
+		// The other inodes are not impacted.
+		res, err = store.GetAllINodeProps(ctx, dir.ID())
+		require.NoError(t, err)
+		require.Equal(t, []Property{prop2}, res)
+	})
+}
@@ -0,0 +1,447 @@
+package dfs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const (
+	searchTableName            = "fs_search"
+	searchContentTableName     = "fs_search_content"
+	searchTextTableName        = "fs_search_text"
+	searchTextContentTableName = "fs_search_text_content"
+)
+
+// indexedMimeTypes are the prefixes of the mimetypes having their content
+// indexed by the "fs-index-content" task.
+var indexedMimeTypes = []string{
+	"text/",
+	"application/json",
+	"application/x-ndjson",
+	"application/xml",
+	"application/javascript",
+	"application/x-sh",
+	"application/sql",
+}
+
+var searchTermRegexp = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// Search returns the inodes matching the query inside the given spaces.
+//
+// The results are ordered by path.
+func (s *sqlStorage) Search(ctx context.Context, spaceIDs []uuid.UUID, cmd *SearchCmd, paginateCmd *sqlstorage.PaginateCmd) ([]SearchResult, error) {
+	fields := make([]string, 0, len(allFiels)+1)
+	for _, field := range allFiels {
+		fields = append(fields, "i."+field)
+	}
+	fields = append(fields, "c.path")
+
+	query := sq.
+		Select(fields...).
+		From(searchContentTableName + " c").
+		Join(tableName + " i ON i.id = c.inode_id").
+		LeftJoin("files f ON f.id = i.file_id").
+		Where(sq.Eq{"c.space_id": spaceIDs, "i.deleted_at": nil})
+
+	nameMatch := sq.Expr("c.docid IN (SELECT rowid FROM "+searchTableName+" WHERE "+searchTableName+" MATCH ?)", matchQuery(cmd.Query))
+	if cmd.InContent {
+		query = query.Where(sq.Or{
+			nameMatch,
+			sq.Expr("i.file_id IN (SELECT t.file_id FROM "+searchTextContentTableName+" t JOIN "+searchTextTableName+
+				" ON "+searchTextTableName+".rowid = t.docid WHERE "+searchTextTableName+" MATCH ?)", matchQuery(cmd.Query)),
+		})
+	} else {
+		query = query.Where(nameMatch)
+	}
+
+	if mimeType, isPrefix := strings.CutSuffix(cmd.MimeType, "*"); isPrefix {
+		query = query.Where("substr(f.mimetype, 1, ?) = ?", len(mimeType), mimeType)
+	} else if cmd.MimeType != "" {
+		query = query.Where(sq.Eq{"f.mimetype": cmd.MimeType})
+	}
+
+	if cmd.MinSize > 0 {
+		query = query.Where(sq.GtOrEq{"i.size": cmd.MinSize})
+	}
+
+	if cmd.MaxSize > 0 {
+		query = query.Where(sq.LtOrEq{"i.size": cmd.MaxSize})
+	}
+
+	if !cmd.ModifiedAfter.IsZero() {
+		query = query.Where(sq.GtOrEq{"i.last_modified_at": sqlstorage.SQLTime(cmd.ModifiedAfter)})
+	}
+
+	if !cmd.ModifiedBefore.IsZero() {
+		query = query.Where(sq.LtOrEq{"i.last_modified_at": sqlstorage.SQLTime(cmd.ModifiedBefore)})
+	}
+
+	if paginateCmd == nil || len(paginateCmd.StartAfter) == 0 {
+		query = query.OrderBy("c.path")
+	}
+
+	rows, err := sqlstorage.PaginateSelection(query, paginateCmd).
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var res SearchResult
+		var sqlLastModifiedAt sqlstorage.SQLTime
+		var sqlCreatedAt sqlstorage.SQLTime
+		var sqlDeletedAt *sqlstorage.SQLTime
+
+		err := rows.Scan(&res.inode.id,
+			&res.inode.name,
+			&res.inode.parent,
+			&res.inode.spaceID,
+			&res.inode.size,
+			&sqlLastModifiedAt,
+			&sqlCreatedAt,
+			&res.inode.createdBy,
+			&res.inode.fileID,
+			&sqlDeletedAt,
+			&res.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		res.inode.lastModifiedAt = sqlLastModifiedAt.Time()
+		res.inode.createdAt = sqlCreatedAt.Time()
+		res.inode.deletedAt = nullTime(sqlDeletedAt)
+		results = append(results, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return results, nil
+}
+
+// SearchTree returns the inodes of the root folder matching the filter. The
+// root path is the path of the root inside its space.
+//
+// The results are ordered by path.
+func (s *sqlStorage) SearchTree(ctx context.Context, root *INode, rootPath string, cmd *SearchTreeCmd, paginateCmd *sqlstorage.PaginateCmd) ([]SearchResult, error) {
+	fields := make([]string, 0, len(allFiels)+1)
+	for _, field := range allFiels {
+		fields = append(fields, "i."+field)
+	}
+	fields = append(fields, "c.path")
+
+	query := sq.
+		Select(fields...).
+		From(searchContentTableName + " c").
+		Join(tableName + " i ON i.id = c.inode_id").
+		LeftJoin("files f ON f.id = i.file_id").
+		Where(sq.Eq{"c.space_id": root.SpaceID(), "i.deleted_at": nil})
+
+	switch {
+	case !cmd.Recursive:
+		query = query.Where(sq.Eq{"i.parent": root.ID()})
+	case rootPath != "/":
+		// All the childrens paths are between "{path}/" and "{path}0" as '0'
+		// is the character right after '/'.
+		query = query.Where(sq.Gt{"c.path": rootPath + "/"}).Where(sq.Lt{"c.path": rootPath + "0"})
+	}
+
+	if cmd.Filter != nil {
+		cond, err := searchFilterCond(cmd.Filter)
+		if err != nil {
+			return nil, err
+		}
+
+		query = query.Where(cond)
+	}
+
+	if paginateCmd == nil || len(paginateCmd.StartAfter) == 0 {
+		query = query.OrderBy("c.path")
+	}
+
+	rows, err := sqlstorage.PaginateSelection(query, paginateCmd).
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var res SearchResult
+		var sqlLastModifiedAt sqlstorage.SQLTime
+		var sqlCreatedAt sqlstorage.SQLTime
+		var sqlDeletedAt *sqlstorage.SQLTime
+
+		err := rows.Scan(&res.inode.id,
+			&res.inode.name,
+			&res.inode.parent,
+			&res.inode.spaceID,
+			&res.inode.size,
+			&sqlLastModifiedAt,
+			&sqlCreatedAt,
+			&res.inode.createdBy,
+			&res.inode.fileID,
+			&sqlDeletedAt,
+			&res.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		res.inode.lastModifiedAt = sqlLastModifiedAt.Time()
+		res.inode.createdAt = sqlCreatedAt.Time()
+		res.inode.deletedAt = nullTime(sqlDeletedAt)
+		results = append(results, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return results, nil
+}
+
+// searchFilterCond converts a validated filter into a SQL condition on the
+// "i" inodes and "f" files tables.
+func searchFilterCond(filter *SearchFilter) (sq.Sqlizer, error) {
+	switch filter.Operator {
+	case SearchAnd, SearchOr, SearchNot:
+		conds := make([]sq.Sqlizer, 0, len(filter.Filters))
+		for i := range filter.Filters {
+			cond, err := searchFilterCond(&filter.Filters[i])
+			if err != nil {
+				return nil, err
+			}
+
+			conds = append(conds, cond)
+		}
+
+		switch filter.Operator {
+		case SearchAnd:
+			return sq.And(conds), nil
+		case SearchOr:
+			return sq.Or(conds), nil
+		}
+
+		query, args, err := conds[0].ToSql()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build the negated filter: %w", err)
+		}
+
+		// As in SQL, a comparison with a NULL value, the mimetype of a
+		// folder for example, stays unknown once negated.
+		return sq.Expr("NOT ("+query+")", args...), nil
+	}
+
+	var column string
+	switch filter.Field {
+	case SearchName:
+		column = "i.name"
+	case SearchMimeType:
+		column = "f.mimetype"
+	case SearchSize:
+		column = "f.size"
+	case SearchLastModifiedAt:
+		column = "i.last_modified_at"
+	default:
+		return nil, fmt.Errorf("unknown search field %q", filter.Field)
+	}
+
+	value := filter.Value
+	if t, ok := value.(time.Time); ok {
+		// The dates are stored as text with a variable number of decimals, they
+		// are compared at the second precision of the HTTP dates instead.
+		column = "CAST(strftime('%s', " + column + ") AS INTEGER)"
+		value = t.Unix()
+	}
+
+	switch filter.Operator {
+	case SearchLike:
+		return sq.Expr(column+` LIKE ? ESCAPE '\'`, value), nil
+	case SearchEq:
+		return sq.Eq{column: value}, nil
+	case SearchGt:
+		return sq.Gt{column: value}, nil
+	case SearchLt:
+		return sq.Lt{column: value}, nil
+	default:
+		return nil, fmt.Errorf("unknown search operator %q", filter.Operator)
+	}
+}
+
+// indexTree adds the inode and all its non-deleted childrens to the search
+// index.
+//
+// Nothing is indexed for a root, a deleted inode or an inode with a parent
+// missing from the index (a parent in the trash for example).
+func indexTree(ctx context.Context, db sqlstorage.Querier, inodeID uuid.UUID) error {
+	var parentPath *string
+
+	err := sq.
+		Select("CASE WHEN p.parent IS NULL THEN '' ELSE c.path END").
+		From(tableName+" i").
+		Join(tableName+" p ON p.id = i.parent").
+		LeftJoin(searchContentTableName+" c ON c.inode_id = p.id").
+		Where(sq.Eq{"i.id": inodeID, "i.deleted_at": nil}).
+		RunWith(db).
+		ScanContext(ctx, &parentPath)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && parentPath == nil) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get the parent path: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO `+searchContentTableName+`(inode_id, space_id, name, path)
+WITH RECURSIVE tree(id, space_id, name, path) AS (
+  SELECT id, space_id, name, ? || '/' || name FROM `+tableName+` WHERE id = ?
+  UNION ALL
+  SELECT c.id, c.space_id, c.name, t.path || '/' || c.name
+    FROM `+tableName+` c JOIN tree t ON c.parent = t.id
+    WHERE c.deleted_at IS NULL
+)
+SELECT id, space_id, name, path FROM tree`, *parentPath, string(inodeID))
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+// unindexTree removes the inode and all its childrens from the search index.
+func unindexTree(ctx context.Context, db sqlstorage.Querier, inodeID uuid.UUID) error {
+	var spaceID, path string
+
+	err := sq.
+		Select("space_id", "path").
+		From(searchContentTableName).
+		Where(sq.Eq{"inode_id": inodeID}).
+		RunWith(db).
+		ScanContext(ctx, &spaceID, &path)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get the indexed path: %w", err)
+	}
+
+	// All the childrens paths are between "{path}/" and "{path}0" as '0' is
+	// the character right after '/'.
+	_, err = sq.
+		Delete(searchContentTableName).
+		Where(sq.Or{
+			sq.Eq{"inode_id": inodeID},
+			sq.And{
+				sq.Eq{"space_id": spaceID},
+				sq.Gt{"path": path + "/"},
+				sq.Lt{"path": path + "0"},
+			},
+		}).
+		RunWith(db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+// GetAllFileIDsToIndex returns the ids of the files used by an inode with an
+// indexable mimetype and without any indexed content yet.
+func (s *sqlStorage) GetAllFileIDsToIndex(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	mimeTypes := sq.Or{}
+	for _, mimeType := range indexedMimeTypes {
+		mimeTypes = append(mimeTypes, sq.Like{"f.mimetype": mimeType + "%"})
+	}
+
+	rows, err := sq.
+		Select("f.id").
+		From("files f").
+		Where(mimeTypes).
+		Where("EXISTS (SELECT 1 FROM " + tableName + " i WHERE i.file_id = f.id AND i.deleted_at IS NULL)").
+		Where("NOT EXISTS (SELECT 1 FROM " + searchTextContentTableName + " t WHERE t.file_id = f.id)").
+		OrderBy("f.id").
+		Limit(uint64(limit)).
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+	defer rows.Close()
+
+	res := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		res = append(res, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return res, nil
+}
+
+// SaveFileContent indexes the text content of a file. Any previous content is
+// replaced.
+func (s *sqlStorage) SaveFileContent(ctx context.Context, fileID uuid.UUID, content string) error {
+	_, err := sq.
+		Insert(searchTextContentTableName).
+		Columns("file_id", "content").
+		Values(fileID, content).
+		Suffix("ON CONFLICT(file_id) DO UPDATE SET content = excluded.content").
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFileContent removes the indexed text content of a file.
+func (s *sqlStorage) DeleteFileContent(ctx context.Context, fileID uuid.UUID) error {
+	_, err := sq.
+		Delete(searchTextContentTableName).
+		Where(sq.Eq{"file_id": fileID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+// matchQuery converts a user query into an FTS query where all the terms are
+// required and can be the prefix of a word.
+func matchQuery(query string) string {
+	terms := searchTermRegexp.FindAllString(strings.ToLower(query), -1)
+	for i := range terms {
+		terms[i] += "*"
+	}
+
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,551 @@
+package dfs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func TestINodeSqlstoreSearch(t *testing.T) {
+	ctx := context.Background()
+
+	db := sqlstorage.NewTestStorage(t)
+	store := newSqlStorage(db)
+
+	// Data
+	user := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	file := files.NewFakeFile(t).BuildAndStore(ctx, db)
+	space := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
+	otherSpace := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
+	now := time.Now().UTC()
+
+	rootInode := NewFakeINode(t).
+		WithSpace(space).
+		IsRootDirectory().
+		CreatedBy(user).
+		CreatedAt(now).
+		Build()
+	dirInode := NewFakeINode(t).
+		WithSpace(space).
+		WithParent(rootInode).
+		IsDirectory().
+		WithName("Photos").
+		CreatedBy(user).
+		CreatedAt(now).
+		Build()
+	fileInode := NewFakeINode(t).
+		WithSpace(space).
+		WithParent(dirInode).
+		WithFile(file).
+		WithName("Holiday in Rome.jpg").
+		CreatedBy(user).
+		CreatedAt(now).
+		Build()
+
+	t.Run("Save indexes the inodes", func(t *testing.T) {
+		for _, inode := range []*INode{rootInode, dirInode, fileInode} {
+			err := store.Save(ctx, inode)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("Search success", func(t *testing.T) {
+		// Run
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "holi"}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Equal(t, []SearchResult{{path: "/Photos/Holiday in Rome.jpg", inode: *fileInode}}, res)
+	})
+
+	t.Run("Search matches the parent directories names", func(t *testing.T) {
+		// Run
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "photos"}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, "/Photos", res[0].Path())
+		require.Equal(t, "/Photos/Holiday in Rome.jpg", res[1].Path())
+	})
+
+	t.Run("Search requires all the terms", func(t *testing.T) {
+		// Run
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "rome paris"}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("Search with an other space", func(t *testing.T) {
+		// Run
+		res, err := store.Search(ctx, []uuid.UUID{otherSpace.ID()}, &SearchCmd{Query: "holi"}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("Search with a mimetype prefix", func(t *testing.T) {
+		// Run
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "holi", MimeType: "text/*"}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+	})
+
+	t.Run("Search with a not matching mimetype", func(t *testing.T) {
+		// Run
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "holi", MimeType: "image/jpeg"}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("Search with a size range", func(t *testing.T) {
+		// Run
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{
+			Query:   "holi",
+			MinSize: file.Size(),
+			MaxSize: file.Size(),
+		}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+
+		// Run
+		res, err = store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "holi", MinSize: file.Size() + 1}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("Search with a modification date range", func(t *testing.T) {
+		// Run
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{
+			Query:          "holi",
+			ModifiedAfter:  now.Add(-time.Minute),
+			ModifiedBefore: now.Add(time.Minute),
+		}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+
+		// Run
+		res, err = store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "holi", ModifiedAfter: now.Add(time.Minute)}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("Search with a pagination", func(t *testing.T) {
+		// Run
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "photos"}, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"c.path": "/Photos"},
+			Limit:      10,
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "/Photos/Holiday in Rome.jpg", res[0].Path())
+	})
+
+	t.Run("Patch a directory name updates the childrens paths", func(t *testing.T) {
+		// Run
+		err := store.Patch(ctx, dirInode.ID(), map[string]any{"name": "Pictures"})
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "holi"}, nil)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "/Pictures/Holiday in Rome.jpg", res[0].Path())
+
+		res, err = store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "photos"}, nil)
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("Patch the deletion date removes the tree from the index", func(t *testing.T) {
+		// Run
+		err := store.Patch(ctx, dirInode.ID(), map[string]any{"deleted_at": now})
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "holi"}, nil)
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("Patch a restored inode adds the tree back into the index", func(t *testing.T) {
+		// Run
+		err := store.Patch(ctx, dirInode.ID(), map[string]any{"deleted_at": nil})
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "holi"}, nil)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "/Pictures/Holiday in Rome.jpg", res[0].Path())
+	})
+
+	t.Run("Patch the space updates the index", func(t *testing.T) {
+		// Run
+		err := store.Patch(ctx, fileInode.ID(), map[string]any{"space_id": otherSpace.ID()})
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.Search(ctx, []uuid.UUID{otherSpace.ID()}, &SearchCmd{Query: "holi"}, nil)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+	})
+
+	t.Run("HardDelete removes the inode from the index", func(t *testing.T) {
+		// Run
+		err := store.HardDelete(ctx, fileInode.ID())
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.Search(ctx, []uuid.UUID{space.ID(), otherSpace.ID()}, &SearchCmd{Query: "holi"}, nil)
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+}
+
+func TestINodeSqlstoreSearchContent(t *testing.T) {
+	ctx := context.Background()
+
+	db := sqlstorage.NewTestStorage(t)
+	store := newSqlStorage(db)
+
+	// Data
+	user := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	file := files.NewFakeFile(t).BuildAndStore(ctx, db)
+	unusedFile := files.NewFakeFile(t).BuildAndStore(ctx, db)
+	space := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
+	rootInode := NewFakeINode(t).
+		WithSpace(space).
+		IsRootDirectory().
+		CreatedBy(user).
+		BuildAndStore(ctx, db)
+	fileInode := NewFakeINode(t).
+		WithSpace(space).
+		WithParent(rootInode).
+		WithFile(file).
+		WithName("notes.md").
+		CreatedBy(user).
+		BuildAndStore(ctx, db)
+
+	t.Run("GetAllFileIDsToIndex success", func(t *testing.T) {
+		// Run
+		res, err := store.GetAllFileIDsToIndex(ctx, 10)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{file.ID()}, res)
+		require.NotContains(t, res, unusedFile.ID())
+	})
+
+	t.Run("SaveFileContent success", func(t *testing.T) {
+		// Run
+		err := store.SaveFileContent(ctx, file.ID(), "Buy some milk and some bread")
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("GetAllFileIDsToIndex skips the indexed files", func(t *testing.T) {
+		// Run
+		res, err := store.GetAllFileIDsToIndex(ctx, 10)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("Search in content success", func(t *testing.T) {
+		// Run
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "milk", InContent: true}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Equal(t, []SearchResult{{path: "/notes.md", inode: *fileInode}}, res)
+	})
+
+	t.Run("Search in content still matches the names", func(t *testing.T) {
+		// Run
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "notes", InContent: true}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+	})
+
+	t.Run("Search without InContent ignores the content", func(t *testing.T) {
+		// Run
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "milk"}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("SaveFileContent replaces the previous content", func(t *testing.T) {
+		// Run
+		err := store.SaveFileContent(ctx, file.ID(), "Call the plumber")
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "milk", InContent: true}, nil)
+		require.NoError(t, err)
+		require.Empty(t, res)
+
+		res, err = store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "plumber", InContent: true}, nil)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+	})
+
+	t.Run("DeleteFileContent success", func(t *testing.T) {
+		// Run
+		err := store.DeleteFileContent(ctx, file.ID())
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.Search(ctx, []uuid.UUID{space.ID()}, &SearchCmd{Query: "plumber", InContent: true}, nil)
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+}
+
+func Test_matchQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "single term", query: "foo", expected: "foo*"},
+		{name: "multiple terms", query: "Foo  Bar", expected: "foo* bar*"},
+		{name: "with punctuation", query: `foo.txt "bar"`, expected: "foo* txt* bar*"},
+		{name: "with fts operators", query: "foo OR -bar NEAR(baz)", expected: "foo* or* bar* near* baz*"},
+		{name: "with unicode", query: "Été 2024", expected: "été* 2024*"},
+		{name: "without terms", query: `"*" -`, expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, matchQuery(test.query))
+		})
+	}
+}
+
+func TestINodeSqlstoreSearchTree(t *testing.T) {
+	ctx := context.Background()
+
+	db := sqlstorage.NewTestStorage(t)
+	store := newSqlStorage(db)
+
+	// Data
+	user := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	file := files.NewFakeFile(t).BuildAndStore(ctx, db)
+	space := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
+	// The stored dates have a sub-second precision.
+	now := time.Date(2024, time.January, 15, 10, 0, 0, 500_000_000, time.UTC)
+
+	rootInode := NewFakeINode(t).
+		WithSpace(space).
+		IsRootDirectory().
+		CreatedBy(user).
+		CreatedAt(now).
+		Build()
+	dirInode := NewFakeINode(t).
+		WithSpace(space).
+		WithParent(rootInode).
+		IsDirectory().
+		WithName("Photos").
+		CreatedBy(user).
+		CreatedAt(now).
+		Build()
+	fileInode := NewFakeINode(t).
+		WithSpace(space).
+		WithParent(dirInode).
+		WithFile(file).
+		WithName("Holiday in Rome.jpg").
+		CreatedBy(user).
+		CreatedAt(now).
+		Build()
+	otherDirInode := NewFakeINode(t).
+		WithSpace(space).
+		WithParent(rootInode).
+		IsDirectory().
+		WithName("Photos of Rome").
+		CreatedBy(user).
+		CreatedAt(now).
+		Build()
+
+	for _, inode := range []*INode{rootInode, dirInode, fileInode, otherDirInode} {
+		err := store.Save(ctx, inode)
+		require.NoError(t, err)
+	}
+
+	t.Run("SearchTree without filter", func(t *testing.T) {
+		// Run
+		res, err := store.SearchTree(ctx, rootInode, "/", &SearchTreeCmd{Recursive: true}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Len(t, res, 3)
+		require.Equal(t, "/Photos", res[0].Path())
+		require.Equal(t, "/Photos of Rome", res[1].Path())
+		require.Equal(t, SearchResult{path: "/Photos/Holiday in Rome.jpg", inode: *fileInode}, res[2])
+	})
+
+	t.Run("SearchTree inside a folder", func(t *testing.T) {
+		// Run
+		res, err := store.SearchTree(ctx, dirInode, "/Photos", &SearchTreeCmd{Recursive: true}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Equal(t, []SearchResult{{path: "/Photos/Holiday in Rome.jpg", inode: *fileInode}}, res)
+	})
+
+	t.Run("SearchTree not recursive", func(t *testing.T) {
+		// Run
+		res, err := store.SearchTree(ctx, rootInode, "/", &SearchTreeCmd{Recursive: false}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, "/Photos", res[0].Path())
+		require.Equal(t, "/Photos of Rome", res[1].Path())
+	})
+
+	t.Run("SearchTree with a like filter", func(t *testing.T) {
+		// Run
+		res, err := store.SearchTree(ctx, rootInode, "/", &SearchTreeCmd{
+			Recursive: true,
+			Filter:    &SearchFilter{Operator: SearchLike, Field: SearchName, Value: "%rome%"},
+		}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, "/Photos of Rome", res[0].Path())
+		require.Equal(t, "/Photos/Holiday in Rome.jpg", res[1].Path())
+	})
+
+	t.Run("SearchTree with an escaped like filter", func(t *testing.T) {
+		// Run
+		res, err := store.SearchTree(ctx, rootInode, "/", &SearchTreeCmd{
+			Recursive: true,
+			Filter:    &SearchFilter{Operator: SearchLike, Field: SearchName, Value: `Photos\_%`},
+		}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("SearchTree with combined filters", func(t *testing.T) {
+		// Run
+		res, err := store.SearchTree(ctx, rootInode, "/", &SearchTreeCmd{
+			Recursive: true,
+			Filter: &SearchFilter{Operator: SearchAnd, Filters: []SearchFilter{
+				{Operator: SearchEq, Field: SearchMimeType, Value: file.MimeType()},
+				{Operator: SearchGt, Field: SearchSize, Value: file.Size() - 1},
+				{Operator: SearchLt, Field: SearchLastModifiedAt, Value: now.Add(time.Minute)},
+			}},
+		}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Equal(t, []SearchResult{{path: "/Photos/Holiday in Rome.jpg", inode: *fileInode}}, res)
+	})
+
+	t.Run("SearchTree with the dates compared at the second", func(t *testing.T) {
+		second := now.Truncate(time.Second)
+
+		for _, test := range []struct {
+			operator SearchOperator
+			value    time.Time
+			expected int
+		}{
+			{SearchEq, second, 3},
+			{SearchEq, second.Add(time.Second), 0},
+			{SearchGt, second, 0},
+			{SearchGt, second.Add(-time.Second), 3},
+			{SearchLt, second, 0},
+			{SearchLt, second.Add(time.Second), 3},
+		} {
+			// Run
+			res, err := store.SearchTree(ctx, rootInode, "/", &SearchTreeCmd{
+				Recursive: true,
+				Filter:    &SearchFilter{Operator: test.operator, Field: SearchLastModifiedAt, Value: test.value},
+			}, nil)
+
+			// Asserts
+			require.NoError(t, err)
+			require.Len(t, res, test.expected, "%s %s", test.operator, test.value)
+		}
+	})
+
+	t.Run("SearchTree with a not filter", func(t *testing.T) {
+		// Run
+		res, err := store.SearchTree(ctx, rootInode, "/", &SearchTreeCmd{
+			Recursive: true,
+			Filter: &SearchFilter{Operator: SearchNot, Filters: []SearchFilter{
+				{Operator: SearchEq, Field: SearchName, Value: "Photos"},
+			}},
+		}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, "/Photos of Rome", res[0].Path())
+		require.Equal(t, "/Photos/Holiday in Rome.jpg", res[1].Path())
+	})
+
+	t.Run("SearchTree with a not filter on a folder without mimetype", func(t *testing.T) {
+		// Run
+		res, err := store.SearchTree(ctx, rootInode, "/", &SearchTreeCmd{
+			Recursive: true,
+			Filter: &SearchFilter{Operator: SearchNot, Filters: []SearchFilter{
+				{Operator: SearchEq, Field: SearchMimeType, Value: "image/png"},
+			}},
+		}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Equal(t, []SearchResult{{path: "/Photos/Holiday in Rome.jpg", inode: *fileInode}}, res)
+	})
+
+	t.Run("SearchTree with an or filter and a pagination", func(t *testing.T) {
+		// Run
+		res, err := store.SearchTree(ctx, rootInode, "/", &SearchTreeCmd{
+			Recursive: true,
+			Filter: &SearchFilter{Operator: SearchOr, Filters: []SearchFilter{
+				{Operator: SearchEq, Field: SearchName, Value: "Photos"},
+				{Operator: SearchEq, Field: SearchName, Value: "Photos of Rome"},
+			}},
+		}, &sqlstorage.PaginateCmd{StartAfter: map[string]string{"c.path": "/Photos"}, Limit: 10})
+
+		// Asserts
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "/Photos of Rome", res[0].Path())
+	})
+}
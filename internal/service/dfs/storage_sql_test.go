@@ -25,7 +25,7 @@ func TestINodeSqlstore(t *testing.T) {
 	// Data
 	user := users.NewFakeUser(t).BuildAndStore(ctx, db)
 	file := files.NewFakeFile(t).BuildAndStore(ctx, db)
-	space := spaces.NewFakeSpace(t).WithOwners(*user).BuildAndStore(ctx, db)
+	space := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
 	now := time.Now().UTC()
 
 	rootInode := NewFakeINode(t).
@@ -115,6 +115,24 @@ func TestINodeSqlstore(t *testing.T) {
 		require.Equal(t, file.Size()*10, totalSize)
 	})
 
+	t.Run("GetSumUserFilesSize success", func(t *testing.T) {
+		// Run
+		totalSize, err := store.GetSumUserFilesSize(ctx, user.ID()) // The root directory is not counted
+
+		// Asserts
+		require.NoError(t, err)
+		require.Equal(t, file.Size()*10, totalSize)
+	})
+
+	t.Run("GetSumUserFilesSize with an unknown user", func(t *testing.T) {
+		// Run
+		totalSize, err := store.GetSumUserFilesSize(ctx, uuid.UUID("some-invalid-id"))
+
+		// Asserts
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), totalSize)
+	})
+
 	t.Run("GetSumRootsSize success", func(t *testing.T) {
 		// Data
 		anAnotherRoot := NewFakeINode(t).
@@ -204,10 +222,12 @@ func TestINodeSqlstore(t *testing.T) {
 
 		t.Run("Delete via a Patch", func(t *testing.T) {
 			// Run
-			err := store.Patch(ctx, deletedInode.id, map[string]any{"deleted_at": time.Now().UTC()})
+			deletedAt := time.Now().UTC()
+			err := store.Patch(ctx, deletedInode.id, map[string]any{"deleted_at": deletedAt})
 
 			// Asserts
 			require.NoError(t, err)
+			deletedInode.deletedAt = deletedAt
 		})
 
 		t.Run("GetByID a soft deleted inode success", func(t *testing.T) {
@@ -221,20 +241,56 @@ func TestINodeSqlstore(t *testing.T) {
 
 		t.Run("GetAllDeleted", func(t *testing.T) {
 			// Run
-			res, err := store.GetAllDeleted(ctx, 10)
+			res, err := store.GetAllDeleted(ctx, &sqlstorage.PaginateCmd{Limit: 10})
 
 			// Asserts
 			require.NoError(t, err)
 			require.Equal(t, []INode{*deletedInode}, res)
 		})
 
+		t.Run("GetAllChildrens doesn't return the deleted inodes", func(t *testing.T) {
+			// Run
+			res, err := store.GetAllChildrens(ctx, rootInode.ID(), nil)
+
+			// Asserts
+			require.NoError(t, err)
+			require.NotContains(t, res, *deletedInode)
+		})
+
+		t.Run("GetAllChildrensWithDeleted", func(t *testing.T) {
+			// Run
+			res, err := store.GetAllChildrensWithDeleted(ctx, rootInode.ID(), nil)
+
+			// Asserts
+			require.NoError(t, err)
+			require.Contains(t, res, *deletedInode)
+		})
+
+		t.Run("GetAllSpaceDeleted", func(t *testing.T) {
+			// Run
+			res, err := store.GetAllSpaceDeleted(ctx, space.ID(), &sqlstorage.PaginateCmd{Limit: 10})
+
+			// Asserts
+			require.NoError(t, err)
+			require.Equal(t, []INode{*deletedInode}, res)
+		})
+
+		t.Run("GetAllSpaceDeleted with an other space", func(t *testing.T) {
+			// Run
+			res, err := store.GetAllSpaceDeleted(ctx, uuid.UUID("some-other-space-id"), &sqlstorage.PaginateCmd{Limit: 10})
+
+			// Asserts
+			require.NoError(t, err)
+			require.Empty(t, res)
+		})
+
 		t.Run("HardDelete success", func(t *testing.T) {
 			// Run
 			err := store.HardDelete(ctx, deletedInode.id)
 			require.NoError(t, err)
 
 			// Check that the node is no more available even as a soft deleted one
-			res, err := store.GetAllDeleted(ctx, 10)
+			res, err := store.GetAllDeleted(ctx, &sqlstorage.PaginateCmd{Limit: 10})
 			require.NoError(t, err)
 			require.Empty(t, res)
 		})
@@ -247,3 +303,64 @@ func TestINodeSqlstore(t *testing.T) {
 		require.Equal(t, childs, res)
 	})
 }
+
+func TestINodeSqlstoreGetSumUserFilesSize(t *testing.T) {
+	ctx := context.Background()
+
+	db := sqlstorage.NewTestStorage(t)
+	store := newSqlStorage(db)
+
+	// Data
+	user := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	file := files.NewFakeFile(t).BuildAndStore(ctx, db)
+	space := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
+	now := time.Now().UTC()
+
+	rootInode := NewFakeINode(t).WithSpace(space).IsRootDirectory().CreatedBy(user).BuildAndStore(ctx, db)
+	NewFakeINode(t).WithSpace(space).WithParent(rootInode).WithFile(file).CreatedBy(user).BuildAndStore(ctx, db)
+
+	t.Run("GetSumUserFilesSize success", func(t *testing.T) {
+		// Run
+		totalSize, err := store.GetSumUserFilesSize(ctx, user.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		require.Equal(t, file.Size(), totalSize)
+	})
+
+	t.Run("GetSumUserFilesSize skips the files inside a trashed directory", func(t *testing.T) {
+		// Data
+		trashedDir := NewFakeINode(t).WithSpace(space).WithParent(rootInode).IsDirectory().CreatedBy(user).DeletedAt(now).BuildAndStore(ctx, db)
+		subDir := NewFakeINode(t).WithSpace(space).WithParent(trashedDir).IsDirectory().CreatedBy(user).BuildAndStore(ctx, db)
+		NewFakeINode(t).WithSpace(space).WithParent(trashedDir).WithFile(file).CreatedBy(user).BuildAndStore(ctx, db)
+		NewFakeINode(t).WithSpace(space).WithParent(subDir).WithFile(file).CreatedBy(user).BuildAndStore(ctx, db)
+
+		// Run
+		totalSize, err := store.GetSumUserFilesSize(ctx, user.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		require.Equal(t, file.Size(), totalSize)
+	})
+
+	t.Run("GetSumUserFilesSize skips the files inside a directory trashed by an another user", func(t *testing.T) {
+		// Data
+		otherUser := users.NewFakeUser(t).BuildAndStore(ctx, db)
+		otherDir := NewFakeINode(t).WithSpace(space).WithParent(rootInode).IsDirectory().CreatedBy(otherUser).BuildAndStore(ctx, db)
+		NewFakeINode(t).WithSpace(space).WithParent(otherDir).WithFile(file).CreatedBy(user).BuildAndStore(ctx, db)
+
+		totalSize, err := store.GetSumUserFilesSize(ctx, user.ID())
+		require.NoError(t, err)
+		require.Equal(t, file.Size()*2, totalSize)
+
+		err = store.Patch(ctx, otherDir.ID(), map[string]any{"deleted_at": now})
+		require.NoError(t, err)
+
+		// Run
+		totalSize, err = store.GetSumUserFilesSize(ctx, user.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		require.Equal(t, file.Size(), totalSize)
+	})
+}
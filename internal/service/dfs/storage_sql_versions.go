@@ -0,0 +1,206 @@
+package dfs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const versionsTableName = "fs_versions"
+
+var allVersionFields = []string{"id", "inode_id", "file_id", "size", "modified_at", "created_at", "created_by"}
+
+func (s *sqlStorage) SaveVersion(ctx context.Context, version *FileVersion) error {
+	_, err := sq.
+		Insert(versionsTableName).
+		Columns(allVersionFields...).
+		Values(version.id,
+			version.inodeID,
+			version.fileID,
+			version.size,
+			ptr.To(sqlstorage.SQLTime(version.modifiedAt)),
+			ptr.To(sqlstorage.SQLTime(version.createdAt)),
+			version.createdBy).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) GetVersionByID(ctx context.Context, id uuid.UUID) (*FileVersion, error) {
+	var res FileVersion
+	var sqlModifiedAt sqlstorage.SQLTime
+	var sqlCreatedAt sqlstorage.SQLTime
+
+	err := sq.
+		Select(allVersionFields...).
+		From(versionsTableName).
+		Where(sq.Eq{"id": id}).
+		RunWith(s.db).
+		ScanContext(ctx,
+			&res.id,
+			&res.inodeID,
+			&res.fileID,
+			&res.size,
+			&sqlModifiedAt,
+			&sqlCreatedAt,
+			&res.createdBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	res.modifiedAt = sqlModifiedAt.Time()
+	res.createdAt = sqlCreatedAt.Time()
+
+	return &res, nil
+}
+
+// GetAllINodeVersions returns all the versions of the given inode, the most
+// recent first.
+func (s *sqlStorage) GetAllINodeVersions(ctx context.Context, inodeID uuid.UUID) ([]FileVersion, error) {
+	rows, err := sq.
+		Select(allVersionFields...).
+		From(versionsTableName).
+		Where(sq.Eq{"inode_id": inodeID}).
+		OrderBy("created_at DESC").
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	return s.scanVersionRows(rows)
+}
+
+func (s *sqlStorage) GetAllVersionsWithFileID(ctx context.Context, fileID uuid.UUID) ([]FileVersion, error) {
+	rows, err := sq.
+		Select(allVersionFields...).
+		From(versionsTableName).
+		Where(sq.Eq{"file_id": fileID}).
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	return s.scanVersionRows(rows)
+}
+
+// GetAllVersionedINodeIDs returns the ids of all the inodes having at least
+// one version.
+func (s *sqlStorage) GetAllVersionedINodeIDs(ctx context.Context, cmd *sqlstorage.PaginateCmd) ([]uuid.UUID, error) {
+	rows, err := sqlstorage.PaginateSelection(sq.
+		Select("DISTINCT inode_id").
+		From(versionsTableName), cmd).
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	res := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		res = append(res, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return res, nil
+}
+
+// GetSumSpaceVersionsSize returns the size of all the versions kept for the
+// files of the space. The versions of the files inside the trash are not
+// counted, like their current content.
+func (s *sqlStorage) GetSumSpaceVersionsSize(ctx context.Context, spaceID uuid.UUID) (uint64, error) {
+	var size *uint64
+
+	err := s.db.QueryRowContext(ctx, `WITH RECURSIVE ancestors(id, size, parent) AS (
+  SELECT v.id, v.size, i.parent FROM `+versionsTableName+` v JOIN `+tableName+` i ON i.id = v.inode_id
+    WHERE i.space_id = ? AND i.deleted_at IS NULL
+  UNION ALL
+  SELECT a.id, a.size, p.parent FROM ancestors a JOIN `+tableName+` p ON p.id = a.parent
+    WHERE p.deleted_at IS NULL
+)
+SELECT SUM(size) FROM ancestors WHERE parent IS NULL`, string(spaceID)).Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("sql error: %w", err)
+	}
+
+	if size == nil {
+		return 0, nil
+	}
+
+	return *size, nil
+}
+
+func (s *sqlStorage) DeleteVersion(ctx context.Context, id uuid.UUID) error {
+	_, err := sq.
+		Delete(versionsTableName).
+		Where(sq.Eq{"id": id}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) scanVersionRows(rows *sql.Rows) ([]FileVersion, error) {
+	versions := []FileVersion{}
+
+	for rows.Next() {
+		var res FileVersion
+		var sqlModifiedAt sqlstorage.SQLTime
+		var sqlCreatedAt sqlstorage.SQLTime
+
+		err := rows.Scan(&res.id,
+			&res.inodeID,
+			&res.fileID,
+			&res.size,
+			&sqlModifiedAt,
+			&sqlCreatedAt,
+			&res.createdBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		res.modifiedAt = sqlModifiedAt.Time()
+		res.createdAt = sqlCreatedAt.Time()
+		versions = append(versions, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return versions, nil
+}
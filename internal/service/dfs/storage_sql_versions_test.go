@@ -0,0 +1,105 @@
+package dfs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func TestFileVersionSqlstore(t *testing.T) {
+	ctx := context.Background()
+
+	db := sqlstorage.NewTestStorage(t)
+	store := newSqlStorage(db)
+
+	// Data
+	user := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	file := files.NewFakeFile(t).BuildAndStore(ctx, db)
+	oldFile := files.NewFakeFile(t).BuildAndStore(ctx, db)
+	space := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
+	rootInode := NewFakeINode(t).WithSpace(space).IsRootDirectory().CreatedBy(user).BuildAndStore(ctx, db)
+	inode := NewFakeINode(t).WithSpace(space).WithParent(rootInode).WithFile(file).CreatedBy(user).BuildAndStore(ctx, db)
+	now := time.Now().UTC()
+
+	version := FileVersion{
+		id:         uuid.UUID("f3b8cbd4-33c7-4ed0-bd9a-8e4c3e8b1c61"),
+		inodeID:    inode.ID(),
+		fileID:     oldFile.ID(),
+		size:       oldFile.Size(),
+		modifiedAt: now.Add(-time.Hour),
+		createdAt:  now,
+		createdBy:  user.ID(),
+	}
+
+	t.Run("SaveVersion success", func(t *testing.T) {
+		err := store.SaveVersion(ctx, &version)
+		require.NoError(t, err)
+	})
+
+	t.Run("GetVersionByID success", func(t *testing.T) {
+		res, err := store.GetVersionByID(ctx, version.ID())
+		require.NoError(t, err)
+		require.Equal(t, &version, res)
+	})
+
+	t.Run("GetVersionByID not found", func(t *testing.T) {
+		res, err := store.GetVersionByID(ctx, uuid.UUID("some-invalid-id"))
+		require.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("GetAllINodeVersions success", func(t *testing.T) {
+		res, err := store.GetAllINodeVersions(ctx, inode.ID())
+		require.NoError(t, err)
+		require.Equal(t, []FileVersion{version}, res)
+	})
+
+	t.Run("GetAllVersionsWithFileID success", func(t *testing.T) {
+		res, err := store.GetAllVersionsWithFileID(ctx, oldFile.ID())
+		require.NoError(t, err)
+		require.Equal(t, []FileVersion{version}, res)
+	})
+
+	t.Run("GetAllVersionedINodeIDs success", func(t *testing.T) {
+		res, err := store.GetAllVersionedINodeIDs(ctx, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"inode_id": ""},
+			Limit:      10,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{inode.ID()}, res)
+	})
+
+	t.Run("GetSumSpaceVersionsSize success", func(t *testing.T) {
+		res, err := store.GetSumSpaceVersionsSize(ctx, space.ID())
+		require.NoError(t, err)
+		require.Equal(t, oldFile.Size(), res)
+	})
+
+	t.Run("GetSumSpaceVersionsSize with an unknown space", func(t *testing.T) {
+		res, err := store.GetSumSpaceVersionsSize(ctx, uuid.UUID("some-invalid-id"))
+		require.NoError(t, err)
+		require.Zero(t, res)
+	})
+
+	t.Run("GetSumUserFilesSize counts the versions", func(t *testing.T) {
+		res, err := store.GetSumUserFilesSize(ctx, user.ID())
+		require.NoError(t, err)
+		require.Equal(t, file.Size()+oldFile.Size(), res)
+	})
+
+	t.Run("DeleteVersion success", func(t *testing.T) {
+		err := store.DeleteVersion(ctx, version.ID())
+		require.NoError(t, err)
+
+		res, err := store.GetAllINodeVersions(ctx, inode.ID())
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+}
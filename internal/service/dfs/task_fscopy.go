@@ -0,0 +1,167 @@
+package dfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const copyBatchSize = 10
+
+type FSCopyTaskRunner struct {
+	fs        Service
+	storage   storage
+	spaces    spaces.Service
+	users     users.Service
+	scheduler scheduler.Service
+	uuid      uuid.Service
+}
+
+func NewFSCopyTaskRunner(fs Service, storage storage, spaces spaces.Service, users users.Service, scheduler scheduler.Service, tools tools.Tools) *FSCopyTaskRunner {
+	return &FSCopyTaskRunner{fs, storage, spaces, users, scheduler, tools.UUID()}
+}
+
+func (r *FSCopyTaskRunner) Name() string { return "fs-copy" }
+
+func (r *FSCopyTaskRunner) Run(ctx context.Context, rawArgs json.RawMessage) error {
+	var args scheduler.FSCopyArgs
+	err := json.Unmarshal(rawArgs, &args)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the args: %w", err)
+	}
+
+	return r.RunArgs(ctx, &args)
+}
+
+func (r *FSCopyTaskRunner) RunArgs(ctx context.Context, args *scheduler.FSCopyArgs) error {
+	targetSpaceID := args.TargetSpaceID
+	if targetSpaceID == "" {
+		targetSpaceID = args.SpaceID
+	}
+
+	space, err := r.spaces.GetByID(ctx, targetSpaceID)
+	if err != nil {
+		return fmt.Errorf("failed to Get the space: %w", err)
+	}
+
+	user, err := r.users.GetByID(ctx, args.CopiedBy)
+	if err != nil {
+		return fmt.Errorf("failed to get the user: %w", err)
+	}
+
+	sourceNode, err := r.storage.GetByID(ctx, args.SourceInode)
+	if err != nil {
+		return fmt.Errorf("failed to GetByID %q: %w", args.SourceInode, err)
+	}
+
+	existingFile, err := r.fs.Get(ctx, NewPathCmd(space, args.TargetPath))
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return fmt.Errorf("failed to check if a file already existed: %w", err)
+	}
+
+	dir, filename := path.Split(args.TargetPath)
+
+	targetDir, err := r.fs.CreateDir(ctx, &CreateDirCmd{
+		Path:      NewPathCmd(space, dir),
+		CreatedBy: user,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the target directory: %w", err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	if existingFile != nil {
+		// XXX:MULTI-WRITE
+		//
+		// The existing inode is removed before the copy. In case of error the task is
+		// retried and the partial copy is removed the same way.
+		err = r.fs.removeINode(ctx, existingFile)
+		if err != nil {
+			return errs.Internal(fmt.Errorf("failed to remove the old file: %w", err))
+		}
+	}
+
+	err = r.copyINode(ctx, sourceNode, targetDir, filename, user, args.CopiedAt)
+	if err != nil {
+		return fmt.Errorf("failed to copy %q: %w", sourceNode.ID(), err)
+	}
+
+	err = r.scheduler.RegisterFSRefreshSizeTask(ctx, &scheduler.FSRefreshSizeArg{
+		INode:      targetDir.ID(),
+		ModifiedAt: args.CopiedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule the fs-refresh-size task: %w", err)
+	}
+
+	return nil
+}
+
+// copyINode clones the given inode and all its childrens inside the parent.
+//
+// The new file inodes target the same files than the sources so no content
+// is duplicated.
+func (r *FSCopyTaskRunner) copyINode(ctx context.Context, src *INode, parent *INode, name string, user *users.User, now time.Time) error {
+	newNode := INode{
+		id:             r.uuid.New(),
+		parent:         ptr.To(parent.ID()),
+		name:           name,
+		spaceID:        parent.SpaceID(),
+		size:           src.Size(),
+		createdAt:      now,
+		createdBy:      user.ID(),
+		lastModifiedAt: now,
+		fileID:         src.FileID(),
+	}
+
+	err := r.storage.Save(ctx, &newNode)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to Save: %w", err))
+	}
+
+	err = r.storage.CopyAllINodeProps(ctx, src.ID(), newNode.ID())
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to CopyAllINodeProps: %w", err))
+	}
+
+	if !src.IsDir() {
+		return nil
+	}
+
+	lastName := ""
+	for {
+		childs, err := r.storage.GetAllChildrens(ctx, src.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"name": lastName},
+			Limit:      copyBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to GetAllChildrens: %w", err)
+		}
+
+		for _, child := range childs {
+			lastName = child.Name()
+
+			err = r.copyINode(ctx, &child, &newNode, child.Name(), user, now)
+			if err != nil {
+				return fmt.Errorf("failed to copy %q: %w", child.ID(), err)
+			}
+		}
+
+		if len(childs) < copyBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package dfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func TestFSCopyTask(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Add(time.Minute)
+
+	t.Run("Name", func(t *testing.T) {
+		runner := NewFSCopyTaskRunner(nil, nil, nil, nil, nil, tools.NewMock(t))
+		assert.Equal(t, "fs-copy", runner.Name())
+	})
+
+	t.Run("Run with some invalid json arg", func(t *testing.T) {
+		runner := NewFSCopyTaskRunner(nil, nil, nil, nil, nil, tools.NewMock(t))
+
+		err := runner.Run(ctx, json.RawMessage(`some-invalid-json`))
+		require.ErrorContains(t, err, "failed to unmarshal the args")
+	})
+
+	t.Run("RunArgs with a file success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		fsMock := NewMockService(t)
+		storageMock := newMockStorage(t)
+		runner := NewFSCopyTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, tools)
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile.ID()).Return(&ExampleAliceFile, nil).Once()
+		fsMock.On("Get", mock.Anything, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt")).Return(nil, errs.ErrNotFound).Once()
+		fsMock.On("CreateDir", mock.Anything, &CreateDirCmd{
+			Path:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+			CreatedBy: &users.ExampleAlice,
+		}).Return(&ExampleAliceRoot, nil).Once()
+
+		// The new inode targets the same file.
+		tools.UUIDMock.On("New").Return(uuid.UUID("2b0f5a9e-6d5c-4a54-9a3f-3b9ad7d3e5a1")).Once()
+		storageMock.On("Save", mock.Anything, &INode{
+			id:             uuid.UUID("2b0f5a9e-6d5c-4a54-9a3f-3b9ad7d3e5a1"),
+			parent:         ptr.To(ExampleAliceRoot.ID()),
+			name:           "bar.txt",
+			spaceID:        ExampleAliceRoot.SpaceID(),
+			size:           ExampleAliceFile.Size(),
+			createdAt:      now,
+			createdBy:      users.ExampleAlice.ID(),
+			lastModifiedAt: now,
+			fileID:         ExampleAliceFile.FileID(),
+		}).Return(nil).Once()
+		storageMock.On("CopyAllINodeProps", mock.Anything, ExampleAliceFile.ID(), uuid.UUID("2b0f5a9e-6d5c-4a54-9a3f-3b9ad7d3e5a1")).Return(nil).Once()
+
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceRoot.ID(),
+			ModifiedAt: now,
+		}).Return(nil).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSCopyArgs{
+			SpaceID:     spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode: ExampleAliceFile.ID(),
+			TargetPath:  "/bar.txt",
+			CopiedAt:    now,
+			CopiedBy:    users.ExampleAlice.ID(),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with a directory success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		fsMock := NewMockService(t)
+		storageMock := newMockStorage(t)
+		runner := NewFSCopyTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, tools)
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceDir.ID()).Return(&ExampleAliceDir, nil).Once()
+		fsMock.On("Get", mock.Anything, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/copy")).Return(nil, errs.ErrNotFound).Once()
+		fsMock.On("CreateDir", mock.Anything, &CreateDirCmd{
+			Path:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+			CreatedBy: &users.ExampleAlice,
+		}).Return(&ExampleAliceRoot, nil).Once()
+
+		// Copy the directory
+		tools.UUIDMock.On("New").Return(uuid.UUID("2b0f5a9e-6d5c-4a54-9a3f-3b9ad7d3e5a1")).Once()
+		storageMock.On("Save", mock.Anything, &INode{
+			id:             uuid.UUID("2b0f5a9e-6d5c-4a54-9a3f-3b9ad7d3e5a1"),
+			parent:         ptr.To(ExampleAliceRoot.ID()),
+			name:           "copy",
+			spaceID:        ExampleAliceRoot.SpaceID(),
+			size:           ExampleAliceDir.Size(),
+			createdAt:      now,
+			createdBy:      users.ExampleAlice.ID(),
+			lastModifiedAt: now,
+			fileID:         nil,
+		}).Return(nil).Once()
+		storageMock.On("CopyAllINodeProps", mock.Anything, ExampleAliceDir.ID(), uuid.UUID("2b0f5a9e-6d5c-4a54-9a3f-3b9ad7d3e5a1")).Return(nil).Once()
+
+		// Copy its content
+		storageMock.On("GetAllChildrens", mock.Anything, ExampleAliceDir.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"name": ""},
+			Limit:      copyBatchSize,
+		}).Return([]INode{ExampleAliceFile}, nil).Once()
+		tools.UUIDMock.On("New").Return(uuid.UUID("5f0a3f41-2f4e-4d7c-8a1b-c0f0f4f3e2d1")).Once()
+		storageMock.On("Save", mock.Anything, &INode{
+			id:             uuid.UUID("5f0a3f41-2f4e-4d7c-8a1b-c0f0f4f3e2d1"),
+			parent:         ptr.To(uuid.UUID("2b0f5a9e-6d5c-4a54-9a3f-3b9ad7d3e5a1")),
+			name:           ExampleAliceFile.Name(),
+			spaceID:        ExampleAliceRoot.SpaceID(),
+			size:           ExampleAliceFile.Size(),
+			createdAt:      now,
+			createdBy:      users.ExampleAlice.ID(),
+			lastModifiedAt: now,
+			fileID:         ExampleAliceFile.FileID(),
+		}).Return(nil).Once()
+		storageMock.On("CopyAllINodeProps", mock.Anything, ExampleAliceFile.ID(), uuid.UUID("5f0a3f41-2f4e-4d7c-8a1b-c0f0f4f3e2d1")).Return(nil).Once()
+
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceRoot.ID(),
+			ModifiedAt: now,
+		}).Return(nil).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSCopyArgs{
+			SpaceID:     spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode: ExampleAliceDir.ID(),
+			TargetPath:  "/copy",
+			CopiedAt:    now,
+			CopiedBy:    users.ExampleAlice.ID(),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with an existing file at destination", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		fsMock := NewMockService(t)
+		storageMock := newMockStorage(t)
+		runner := NewFSCopyTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, tools)
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile.ID()).Return(&ExampleAliceFile, nil).Once()
+		fsMock.On("Get", mock.Anything, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt")).Return(&ExampleAliceFile2, nil).Once()
+		fsMock.On("CreateDir", mock.Anything, &CreateDirCmd{
+			Path:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+			CreatedBy: &users.ExampleAlice,
+		}).Return(&ExampleAliceRoot, nil).Once()
+		fsMock.On("removeINode", mock.Anything, &ExampleAliceFile2).Return(nil).Once()
+
+		tools.UUIDMock.On("New").Return(uuid.UUID("2b0f5a9e-6d5c-4a54-9a3f-3b9ad7d3e5a1")).Once()
+		storageMock.On("Save", mock.Anything, mock.Anything).Return(nil).Once()
+		storageMock.On("CopyAllINodeProps", mock.Anything, ExampleAliceFile.ID(), uuid.UUID("2b0f5a9e-6d5c-4a54-9a3f-3b9ad7d3e5a1")).Return(nil).Once()
+
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceRoot.ID(),
+			ModifiedAt: now,
+		}).Return(nil).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSCopyArgs{
+			SpaceID:     spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode: ExampleAliceFile.ID(),
+			TargetPath:  "/bar.txt",
+			CopiedAt:    now,
+			CopiedBy:    users.ExampleAlice.ID(),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with a Save error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		fsMock := NewMockService(t)
+		storageMock := newMockStorage(t)
+		runner := NewFSCopyTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, tools)
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile.ID()).Return(&ExampleAliceFile, nil).Once()
+		fsMock.On("Get", mock.Anything, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt")).Return(nil, errs.ErrNotFound).Once()
+		fsMock.On("CreateDir", mock.Anything, &CreateDirCmd{
+			Path:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+			CreatedBy: &users.ExampleAlice,
+		}).Return(&ExampleAliceRoot, nil).Once()
+
+		tools.UUIDMock.On("New").Return(uuid.UUID("2b0f5a9e-6d5c-4a54-9a3f-3b9ad7d3e5a1")).Once()
+		storageMock.On("Save", mock.Anything, mock.Anything).Return(errors.New("some-error")).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSCopyArgs{
+			SpaceID:     spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode: ExampleAliceFile.ID(),
+			TargetPath:  "/bar.txt",
+			CopiedAt:    now,
+			CopiedBy:    users.ExampleAlice.ID(),
+		})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("RunArgs with a CopyAllINodeProps error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		fsMock := NewMockService(t)
+		storageMock := newMockStorage(t)
+		runner := NewFSCopyTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, tools)
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile.ID()).Return(&ExampleAliceFile, nil).Once()
+		fsMock.On("Get", mock.Anything, NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar.txt")).Return(nil, errs.ErrNotFound).Once()
+		fsMock.On("CreateDir", mock.Anything, &CreateDirCmd{
+			Path:      NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+			CreatedBy: &users.ExampleAlice,
+		}).Return(&ExampleAliceRoot, nil).Once()
+
+		tools.UUIDMock.On("New").Return(uuid.UUID("2b0f5a9e-6d5c-4a54-9a3f-3b9ad7d3e5a1")).Once()
+		storageMock.On("Save", mock.Anything, mock.Anything).Return(nil).Once()
+		storageMock.On("CopyAllINodeProps", mock.Anything, ExampleAliceFile.ID(), uuid.UUID("2b0f5a9e-6d5c-4a54-9a3f-3b9ad7d3e5a1")).Return(errors.New("some-error")).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSCopyArgs{
+			SpaceID:     spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode: ExampleAliceFile.ID(),
+			TargetPath:  "/bar.txt",
+			CopiedAt:    now,
+			CopiedBy:    users.ExampleAlice.ID(),
+		})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+}
@@ -0,0 +1,63 @@
+package dfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+)
+
+type FSEmptyTrashTaskRunner struct {
+	storage storage
+	gc      *FSGGCTaskRunner
+}
+
+func NewFSEmptyTrashTaskRunner(storage storage, gc *FSGGCTaskRunner) *FSEmptyTrashTaskRunner {
+	return &FSEmptyTrashTaskRunner{storage, gc}
+}
+
+func (r *FSEmptyTrashTaskRunner) Name() string { return "fs-empty-trash" }
+
+func (r *FSEmptyTrashTaskRunner) Run(ctx context.Context, rawArgs json.RawMessage) error {
+	var args scheduler.FSEmptyTrashArgs
+	err := json.Unmarshal(rawArgs, &args)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the args: %w", err)
+	}
+
+	return r.RunArgs(ctx, &args)
+}
+
+func (r *FSEmptyTrashTaskRunner) RunArgs(ctx context.Context, args *scheduler.FSEmptyTrashArgs) error {
+	lastID := ""
+
+	for {
+		toDelete, err := r.storage.GetAllSpaceDeleted(ctx, args.SpaceID, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": lastID},
+			Limit:      gcBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to GetAllSpaceDeleted: %w", err)
+		}
+
+		for _, inode := range toDelete {
+			lastID = string(inode.ID())
+
+			if inode.DeletedAt().After(args.EmptiedAt) {
+				// The inode have been removed after the trash have been emptied.
+				continue
+			}
+
+			err = r.gc.purge(ctx, &inode, args.EmptiedAt, args.EmptiedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(toDelete) < gcBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package dfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+)
+
+func TestFSEmptyTrashTask(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Add(time.Hour)
+
+	t.Run("Name", func(t *testing.T) {
+		runner := NewFSEmptyTrashTaskRunner(nil, nil)
+		assert.Equal(t, "fs-empty-trash", runner.Name())
+	})
+
+	t.Run("Run with some invalid json arg", func(t *testing.T) {
+		runner := NewFSEmptyTrashTaskRunner(nil, nil)
+
+		err := runner.Run(ctx, json.RawMessage(`some-invalid-json`))
+		require.ErrorContains(t, err, "failed to unmarshal the args")
+	})
+
+	t.Run("RunArgs success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		gc := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
+		runner := NewFSEmptyTrashTaskRunner(storageMock, gc)
+
+		storageMock.On("GetAllSpaceDeleted", mock.Anything, spaces.ExampleAlicePersonalSpace.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{ExampleAliceFile}, nil).Once()
+
+		// We remove the file content and inode
+		storageMock.On("GetAllINodeVersions", mock.Anything, ExampleAliceFile.ID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("DeleteAllINodeProps", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
+		storageMock.On("HardDelete", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
+		storageMock.On("GetAllInodesWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]INode{}, nil).Once()
+		storageMock.On("GetAllVersionsWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("DeleteFileContent", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
+		filesMock.On("Delete", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
+
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      *ExampleAliceFile.Parent(),
+			ModifiedAt: now,
+		}).Return(nil).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSEmptyTrashArgs{
+			SpaceID:   spaces.ExampleAlicePersonalSpace.ID(),
+			EmptiedAt: now,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with an inode removed after the call", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		gc := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
+		runner := NewFSEmptyTrashTaskRunner(storageMock, gc)
+
+		deletedFile := ExampleAliceFile
+		deletedFile.deletedAt = now
+
+		storageMock.On("GetAllSpaceDeleted", mock.Anything, spaces.ExampleAlicePersonalSpace.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{deletedFile}, nil).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSEmptyTrashArgs{
+			SpaceID:   spaces.ExampleAlicePersonalSpace.ID(),
+			EmptiedAt: deletedFile.DeletedAt().Add(-time.Minute),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with a child removed after the call", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		gc := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
+		runner := NewFSEmptyTrashTaskRunner(storageMock, gc)
+
+		deletedDir := ExampleAliceDir
+		deletedDir.deletedAt = now.Add(-time.Hour)
+		deletedFile := ExampleAliceFile
+		deletedFile.deletedAt = now.Add(time.Minute)
+
+		storageMock.On("GetAllSpaceDeleted", mock.Anything, spaces.ExampleAlicePersonalSpace.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{deletedDir}, nil).Once()
+
+		// The child is kept so the directory is kept too.
+		storageMock.On("GetAllChildrensWithDeleted", mock.Anything, ExampleAliceDir.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{deletedFile}, nil).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSEmptyTrashArgs{
+			SpaceID:   spaces.ExampleAlicePersonalSpace.ID(),
+			EmptiedAt: now,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with a GetAllSpaceDeleted error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		gc := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
+		runner := NewFSEmptyTrashTaskRunner(storageMock, gc)
+
+		storageMock.On("GetAllSpaceDeleted", mock.Anything, spaces.ExampleAlicePersonalSpace.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return(nil, fmt.Errorf("some-error")).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSEmptyTrashArgs{
+			SpaceID:   spaces.ExampleAlicePersonalSpace.ID(),
+			EmptiedAt: now,
+		})
+		require.EqualError(t, err, "failed to GetAllSpaceDeleted: some-error")
+	})
+}
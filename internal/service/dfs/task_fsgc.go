@@ -3,6 +3,7 @@ package dfs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,7 +12,9 @@ import (
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/clock"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
 const gcBatchSize = 10
@@ -51,30 +54,37 @@ func (r *FSGGCTaskRunner) Run(ctx context.Context, rawArgs json.RawMessage) erro
 }
 
 func (r *FSGGCTaskRunner) RunArgs(ctx context.Context, args *scheduler.FSGCArgs) error {
+	retentions := map[uuid.UUID]time.Duration{}
+	lastID := ""
+
 	for {
-		toDelete, err := r.storage.GetAllDeleted(ctx, gcBatchSize)
+		toDelete, err := r.storage.GetAllDeleted(ctx, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": lastID},
+			Limit:      gcBatchSize,
+		})
 		if err != nil {
 			return fmt.Errorf("failed to GetAllDeleted: %w", err)
 		}
 
 		for _, inode := range toDelete {
+			lastID = string(inode.ID())
 			now := r.clock.Now()
 
-			deletionDate := inode.LastModifiedAt()
+			deletionDate := inode.DeletedAt()
 
-			err = r.deleteINode(ctx, &inode, deletionDate)
+			retention, err := r.getTrashRetention(ctx, &inode, retentions)
 			if err != nil {
-				return fmt.Errorf("failed to delete inode %q: %w", inode.ID(), err)
+				return fmt.Errorf("failed to get the trash retention for inode %q: %w", inode.ID(), err)
 			}
 
-			if inode.parent != nil {
-				err = r.scheduler.RegisterFSRefreshSizeTask(ctx, &scheduler.FSRefreshSizeArg{
-					INode:      *inode.Parent(),
-					ModifiedAt: now,
-				})
-				if err != nil {
-					return fmt.Errorf("failed to schedule the fs-refresh-size task: %w", err)
-				}
+			if retention > 0 && now.Sub(deletionDate) < retention {
+				// The inode is still in the trash.
+				continue
+			}
+
+			err = r.purge(ctx, &inode, now, now.Add(-retention))
+			if err != nil {
+				return err
 			}
 		}
 
@@ -84,18 +94,86 @@ func (r *FSGGCTaskRunner) RunArgs(ctx context.Context, args *scheduler.FSGCArgs)
 	}
 }
 
-func (r *FSGGCTaskRunner) deleteDirINode(ctx context.Context, inode *INode, deletionDate time.Time) error {
+// getTrashRetention returns the retention duration applied to the given deleted inode.
+//
+// The space roots and the inodes of the deleted spaces are purged immediately.
+func (r *FSGGCTaskRunner) getTrashRetention(ctx context.Context, inode *INode, cache map[uuid.UUID]time.Duration) (time.Duration, error) {
+	if inode.Parent() == nil {
+		return 0, nil
+	}
+
+	if retention, ok := cache[inode.SpaceID()]; ok {
+		return retention, nil
+	}
+
+	space, err := r.spaces.GetByID(ctx, inode.SpaceID())
+	if errors.Is(err, errs.ErrNotFound) {
+		cache[inode.SpaceID()] = 0
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to GetByID the space: %w", err)
+	}
+
+	cache[inode.SpaceID()] = space.TrashRetention()
+
+	return space.TrashRetention(), nil
+}
+
+// purge definitively removes a deleted inode and all its content.
+//
+// The children moved to the trash after keepDeletedAfter are still within their
+// own retention. They are kept, with the directories containing them, until a
+// later purge.
+func (r *FSGGCTaskRunner) purge(ctx context.Context, inode *INode, now time.Time, keepDeletedAfter time.Time) error {
+	purged, err := r.deleteINode(ctx, inode, keepDeletedAfter)
+	if err != nil {
+		return fmt.Errorf("failed to delete inode %q: %w", inode.ID(), err)
+	}
+
+	if purged && inode.parent != nil {
+		err = r.scheduler.RegisterFSRefreshSizeTask(ctx, &scheduler.FSRefreshSizeArg{
+			INode:      *inode.Parent(),
+			ModifiedAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to schedule the fs-refresh-size task: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *FSGGCTaskRunner) deleteDirINode(ctx context.Context, inode *INode, keepDeletedAfter time.Time) (bool, error) {
+	lastID := ""
+	keep := false
+
 	for {
-		childs, err := r.storage.GetAllChildrens(ctx, inode.ID(), &sqlstorage.PaginateCmd{Limit: gcBatchSize})
+		// The childs already in the trash are purged with their parent once
+		// their own retention is over.
+		childs, err := r.storage.GetAllChildrensWithDeleted(ctx, inode.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": lastID},
+			Limit:      gcBatchSize,
+		})
 		if err != nil {
-			return fmt.Errorf("failed to Readdir: %w", err)
+			return false, fmt.Errorf("failed to Readdir: %w", err)
 		}
 
 		for _, child := range childs {
-			err = r.deleteINode(ctx, &child, r.clock.Now())
+			lastID = string(child.ID())
+
+			if child.DeletedAt().After(keepDeletedAfter) {
+				keep = true
+				continue
+			}
+
+			purged, err := r.deleteINode(ctx, &child, keepDeletedAfter)
 			if err != nil {
-				return fmt.Errorf("failed to deleteINode %q: %w", child.ID(), err)
+				return false, fmt.Errorf("failed to deleteINode %q: %w", child.ID(), err)
 			}
+
+			keep = keep || !purged
 		}
 
 		if len(childs) < gcBatchSize {
@@ -103,39 +181,108 @@ func (r *FSGGCTaskRunner) deleteDirINode(ctx context.Context, inode *INode, dele
 		}
 	}
 
-	err := r.storage.HardDelete(ctx, inode.id)
+	if keep {
+		// The directory can't be removed while it still has some childs.
+		return false, nil
+	}
+
+	// Only the directories can be shared.
+	err := r.storage.DeleteAllINodeGrants(ctx, inode.ID())
 	if err != nil {
-		return fmt.Errorf("failed to HardDelete: %w", err)
+		return false, fmt.Errorf("failed to DeleteAllINodeGrants: %w", err)
 	}
 
-	return nil
+	err = r.storage.DeleteAllINodeProps(ctx, inode.ID())
+	if err != nil {
+		return false, fmt.Errorf("failed to DeleteAllINodeProps: %w", err)
+	}
+
+	err = r.storage.HardDelete(ctx, inode.id)
+	if err != nil {
+		return false, fmt.Errorf("failed to HardDelete: %w", err)
+	}
+
+	return true, nil
 }
 
-func (j *FSGGCTaskRunner) deleteINode(ctx context.Context, inode *INode, deletionDate time.Time) error {
+// deleteINode removes the inode and its content. It returns false if the inode
+// is a directory kept for some childs still in the trash.
+func (j *FSGGCTaskRunner) deleteINode(ctx context.Context, inode *INode, keepDeletedAfter time.Time) (bool, error) {
 	// XXX:MULTI-WRITE
 	//
 	// This file have severa consecutive writes but they are all idempotent and the
 	// task is retried in case of error.
 	if inode.IsDir() {
-		return j.deleteDirINode(ctx, inode, deletionDate)
+		return j.deleteDirINode(ctx, inode, keepDeletedAfter)
+	}
+
+	versions, err := j.storage.GetAllINodeVersions(ctx, inode.ID())
+	if err != nil {
+		return false, fmt.Errorf("failed to GetAllINodeVersions: %w", err)
+	}
+
+	for _, version := range versions {
+		err = j.deleteVersion(ctx, &version)
+		if err != nil {
+			return false, fmt.Errorf("failed to delete the version %q: %w", version.ID(), err)
+		}
+	}
+
+	err = j.storage.DeleteAllINodeProps(ctx, inode.ID())
+	if err != nil {
+		return false, fmt.Errorf("failed to DeleteAllINodeProps: %w", err)
+	}
+
+	err = j.storage.HardDelete(ctx, inode.id)
+	if err != nil {
+		return false, fmt.Errorf("failed to HardDelete: %w", err)
+	}
+
+	err = j.deleteFileIfUnused(ctx, *inode.FileID())
+	if err != nil {
+		return false, err
 	}
 
-	err := j.storage.HardDelete(ctx, inode.id)
+	return true, nil
+}
+
+func (j *FSGGCTaskRunner) deleteVersion(ctx context.Context, version *FileVersion) error {
+	err := j.storage.DeleteVersion(ctx, version.ID())
 	if err != nil {
-		return fmt.Errorf("failed to HardDelete: %w", err)
+		return fmt.Errorf("failed to DeleteVersion: %w", err)
 	}
 
-	inodes, err := j.storage.GetAllInodesWithFileID(ctx, *inode.FileID())
+	return j.deleteFileIfUnused(ctx, version.FileID())
+}
+
+// deleteFileIfUnused removes the file if no more inodes or versions target it.
+func (j *FSGGCTaskRunner) deleteFileIfUnused(ctx context.Context, fileID uuid.UUID) error {
+	inodes, err := j.storage.GetAllInodesWithFileID(ctx, fileID)
 	if err != nil {
 		return fmt.Errorf("failed to GetAllINodesWithFileID: %w", err)
 	}
 
-	if len(inodes) == 0 {
-		// No more inodes target this file so it can be removed
-		err = j.files.Delete(ctx, *inode.FileID())
-		if err != nil {
-			return fmt.Errorf("failed to remove the file %q: %w", inode.ID(), err)
-		}
+	if len(inodes) > 0 {
+		return nil
+	}
+
+	versions, err := j.storage.GetAllVersionsWithFileID(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to GetAllVersionsWithFileID: %w", err)
+	}
+
+	if len(versions) > 0 {
+		return nil
+	}
+
+	err = j.storage.DeleteFileContent(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to DeleteFileContent: %w", err)
+	}
+
+	err = j.files.Delete(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to remove the file %q: %w", fileID, err)
 	}
 
 	return nil
@@ -14,6 +14,7 @@ import (
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
 	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
 )
 
@@ -36,7 +37,10 @@ func TestFSGC(t *testing.T) {
 		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
 
 		// First loop to fetch the deleted inodes
-		storageMock.On("GetAllDeleted", mock.Anything, 10).Return([]INode{}, nil).Once()
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{}, nil).Once()
 
 		err := job.Run(ctx, json.RawMessage(`{}`))
 		require.NoError(t, err)
@@ -51,7 +55,10 @@ func TestFSGC(t *testing.T) {
 		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
 
 		// First loop to fetch the deleted inodes
-		storageMock.On("GetAllDeleted", mock.Anything, 10).Return([]INode{}, nil).Once()
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{}, nil).Once()
 
 		// It works because we don't need the arg to run the job.
 		err := job.Run(ctx, json.RawMessage(`some-invalid-json`))
@@ -67,18 +74,30 @@ func TestFSGC(t *testing.T) {
 		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
 
 		// First loop to fetch the deleted inodes
-		storageMock.On("GetAllDeleted", mock.Anything, 10).Return([]INode{ExampleAliceRoot}, nil).Once()
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{ExampleAliceRoot}, nil).Once()
 
 		// This is a dir we will delete all its content
-		storageMock.On("GetAllChildrens", mock.Anything, ExampleAliceRoot.ID(), &sqlstorage.PaginateCmd{Limit: 10}).Return([]INode{ExampleAliceFile}, nil).Once()
+		storageMock.On("GetAllChildrensWithDeleted", mock.Anything, ExampleAliceRoot.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{ExampleAliceFile}, nil).Once()
 
 		// We remove the file content and inode
 		tools.ClockMock.On("Now").Return(now)
 		storageMock.On("GetAllInodesWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]INode{}, nil).Once()
+		storageMock.On("GetAllVersionsWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("GetAllINodeVersions", mock.Anything, ExampleAliceFile.ID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("DeleteAllINodeProps", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
 		storageMock.On("HardDelete", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
+		storageMock.On("DeleteFileContent", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
 		filesMock.On("Delete", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
 
 		// We remove the dir itself
+		storageMock.On("DeleteAllINodeGrants", mock.Anything, ExampleAliceRoot.ID()).Return(nil).Once()
+		storageMock.On("DeleteAllINodeProps", mock.Anything, ExampleAliceRoot.ID()).Return(nil).Once()
 		storageMock.On("HardDelete", mock.Anything, ExampleAliceRoot.ID()).Return(nil).Once()
 
 		err := job.RunArgs(ctx, &scheduler.FSGCArgs{})
@@ -94,18 +113,32 @@ func TestFSGC(t *testing.T) {
 		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
 
 		// First loop to fetch the deleted inodes
-		storageMock.On("GetAllDeleted", mock.Anything, 10).Return([]INode{ExampleAliceDir}, nil).Once()
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{ExampleAliceDir}, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.NewFakeSpace(t).WithTrashRetention(0).Build(), nil).Once()
 
 		// This is a dir we will delete all its content
-		storageMock.On("GetAllChildrens", mock.Anything, ExampleAliceDir.ID(), &sqlstorage.PaginateCmd{Limit: 10}).Return([]INode{ExampleAliceFile}, nil).Once()
+		storageMock.On("GetAllChildrensWithDeleted", mock.Anything, ExampleAliceDir.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{ExampleAliceFile}, nil).Once()
 
 		// We remove the file content and inode
 		tools.ClockMock.On("Now").Return(now)
 		storageMock.On("GetAllInodesWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]INode{}, nil).Once()
+		storageMock.On("GetAllVersionsWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("GetAllINodeVersions", mock.Anything, ExampleAliceFile.ID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("DeleteAllINodeProps", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
 		storageMock.On("HardDelete", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
+		storageMock.On("DeleteFileContent", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
 		filesMock.On("Delete", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
 
 		// We remove the dir itself
+		storageMock.On("DeleteAllINodeGrants", mock.Anything, ExampleAliceDir.ID()).Return(nil).Once()
+		storageMock.On("DeleteAllINodeProps", mock.Anything, ExampleAliceDir.ID()).Return(nil).Once()
 		storageMock.On("HardDelete", mock.Anything, ExampleAliceDir.ID()).Return(nil).Once()
 
 		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
@@ -126,14 +159,23 @@ func TestFSGC(t *testing.T) {
 		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
 
 		// First loop to fetch the deleted inodes
-		storageMock.On("GetAllDeleted", mock.Anything, 10).Return([]INode{ExampleAliceFile}, nil).Once()
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{ExampleAliceFile}, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.NewFakeSpace(t).WithTrashRetention(0).Build(), nil).Once()
 
 		tools.ClockMock.On("Now").Return(now).Once()
 
 		// We remove the file content and inode
 		tools.ClockMock.On("Now").Return(now)
 		storageMock.On("GetAllInodesWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]INode{}, nil).Once()
+		storageMock.On("GetAllVersionsWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("GetAllINodeVersions", mock.Anything, ExampleAliceFile.ID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("DeleteAllINodeProps", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
 		storageMock.On("HardDelete", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
+		storageMock.On("DeleteFileContent", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
 		filesMock.On("Delete", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
 
 		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
@@ -154,7 +196,10 @@ func TestFSGC(t *testing.T) {
 		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
 
 		// First loop to fetch the deleted inodes
-		storageMock.On("GetAllDeleted", mock.Anything, 10).Return(nil, fmt.Errorf("some-error")).Once()
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return(nil, fmt.Errorf("some-error")).Once()
 
 		err := job.RunArgs(ctx, &scheduler.FSGCArgs{})
 		require.EqualError(t, err, "failed to GetAllDeleted: some-error")
@@ -169,12 +214,18 @@ func TestFSGC(t *testing.T) {
 		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
 
 		// First loop to fetch the deleted inodes
-		storageMock.On("GetAllDeleted", mock.Anything, 10).Return([]INode{ExampleAliceRoot}, nil).Once()
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{ExampleAliceRoot}, nil).Once()
 
 		tools.ClockMock.On("Now").Return(now).Once()
 
 		// This is a dir we will delete all its content
-		storageMock.On("GetAllChildrens", mock.Anything, ExampleAliceRoot.ID(), &sqlstorage.PaginateCmd{Limit: 10}).Return(nil, fmt.Errorf("some-error")).Once()
+		storageMock.On("GetAllChildrensWithDeleted", mock.Anything, ExampleAliceRoot.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return(nil, fmt.Errorf("some-error")).Once()
 
 		err := job.RunArgs(ctx, &scheduler.FSGCArgs{})
 		require.EqualError(t, err, "failed to delete inode \"f5c0d3d2-e1b9-492b-b5d4-bd64bde0128f\": failed to Readdir: some-error")
@@ -189,14 +240,23 @@ func TestFSGC(t *testing.T) {
 		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
 
 		// First loop to fetch the deleted inodes
-		storageMock.On("GetAllDeleted", mock.Anything, 10).Return([]INode{ExampleAliceFile}, nil).Once()
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{ExampleAliceFile}, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.NewFakeSpace(t).WithTrashRetention(0).Build(), nil).Once()
 
 		tools.ClockMock.On("Now").Return(now).Once()
 
 		// We remove the file content and inode
 		tools.ClockMock.On("Now").Return(now)
 		storageMock.On("GetAllInodesWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]INode{}, nil).Once()
+		storageMock.On("GetAllVersionsWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("GetAllINodeVersions", mock.Anything, ExampleAliceFile.ID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("DeleteAllINodeProps", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
 		storageMock.On("HardDelete", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
+		storageMock.On("DeleteFileContent", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
 		filesMock.On("Delete", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
 
 		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
@@ -207,4 +267,167 @@ func TestFSGC(t *testing.T) {
 		err := job.RunArgs(ctx, &scheduler.FSGCArgs{})
 		require.EqualError(t, err, "failed to schedule the fs-refresh-size task: some-error")
 	})
+
+	t.Run("RunArgs with an inode still in the trash", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
+
+		// The file content is older than the retention but it has been
+		// removed recently.
+		deletedFile := ExampleAliceFile
+		deletedFile.deletedAt = ExampleAliceFile.LastModifiedAt().Add(60 * 24 * time.Hour)
+
+		// First loop to fetch the deleted inodes
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{deletedFile}, nil).Once()
+		tools.ClockMock.On("Now").Return(deletedFile.DeletedAt().Add(time.Hour)).Once()
+
+		// The default retention is 30 days so nothing is purged.
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		err := job.RunArgs(ctx, &scheduler.FSGCArgs{})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with an inode with an expired retention", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
+
+		deletedFile := ExampleAliceFile
+		deletedFile.deletedAt = now
+		purgeDate := deletedFile.DeletedAt().Add(31 * 24 * time.Hour)
+
+		// First loop to fetch the deleted inodes
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{deletedFile}, nil).Once()
+		tools.ClockMock.On("Now").Return(purgeDate).Once()
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		// We remove the file content and inode
+		storageMock.On("GetAllINodeVersions", mock.Anything, ExampleAliceFile.ID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("DeleteAllINodeProps", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
+		storageMock.On("HardDelete", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
+		storageMock.On("GetAllInodesWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]INode{}, nil).Once()
+		storageMock.On("GetAllVersionsWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("DeleteFileContent", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
+		filesMock.On("Delete", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
+
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      *ExampleAliceFile.parent,
+			ModifiedAt: purgeDate,
+		}).Return(nil).Once()
+
+		err := job.RunArgs(ctx, &scheduler.FSGCArgs{})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with a directory containing a child still in the trash", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
+
+		// The directory retention is over but its child have been moved to
+		// the trash on its own more recently.
+		deletedDir := ExampleAliceDir
+		deletedDir.deletedAt = now
+		deletedFile := ExampleAliceFile
+		deletedFile.deletedAt = now.Add(20 * 24 * time.Hour)
+		purgeDate := deletedDir.DeletedAt().Add(31 * 24 * time.Hour)
+
+		// First loop to fetch the deleted inodes
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{deletedDir}, nil).Once()
+		tools.ClockMock.On("Now").Return(purgeDate).Once()
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		// The child is kept so the directory is kept too.
+		storageMock.On("GetAllChildrensWithDeleted", mock.Anything, ExampleAliceDir.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{deletedFile}, nil).Once()
+
+		err := job.RunArgs(ctx, &scheduler.FSGCArgs{})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with an inode from a deleted space", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
+
+		// First loop to fetch the deleted inodes
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{ExampleAliceFile}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(nil, errs.NotFound(fmt.Errorf("some-error"))).Once()
+
+		// We remove the file content and inode
+		storageMock.On("GetAllINodeVersions", mock.Anything, ExampleAliceFile.ID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("DeleteAllINodeProps", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
+		storageMock.On("HardDelete", mock.Anything, ExampleAliceFile.ID()).Return(nil).Once()
+		storageMock.On("GetAllInodesWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]INode{}, nil).Once()
+		storageMock.On("GetAllVersionsWithFileID", mock.Anything, *ExampleAliceFile.FileID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("DeleteFileContent", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
+		filesMock.On("Delete", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
+
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      *ExampleAliceFile.parent,
+			ModifiedAt: now,
+		}).Return(nil).Once()
+
+		err := job.RunArgs(ctx, &scheduler.FSGCArgs{})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with a spaces GetByID error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		job := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
+
+		// First loop to fetch the deleted inodes
+		storageMock.On("GetAllDeleted", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      10,
+		}).Return([]INode{ExampleAliceFile}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(nil, errs.Internal(fmt.Errorf("some-error"))).Once()
+
+		err := job.RunArgs(ctx, &scheduler.FSGCArgs{})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
 }
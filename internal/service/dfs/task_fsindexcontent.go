@@ -0,0 +1,96 @@
+package dfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const (
+	indexBatchSize = 10
+	// maxIndexedContentSize is the maximum number of bytes indexed for a
+	// single file. The rest of the content is ignored.
+	maxIndexedContentSize = 1024 * 1024 // 1MB
+)
+
+type FSIndexContentTaskRunner struct {
+	storage storage
+	files   files.Service
+}
+
+func NewFSIndexContentTaskRunner(storage storage, files files.Service) *FSIndexContentTaskRunner {
+	return &FSIndexContentTaskRunner{storage, files}
+}
+
+func (r *FSIndexContentTaskRunner) Name() string { return "fs-index-content" }
+
+func (r *FSIndexContentTaskRunner) Run(ctx context.Context, rawArgs json.RawMessage) error {
+	return r.RunArgs(ctx, &scheduler.FSIndexContentArgs{})
+}
+
+func (r *FSIndexContentTaskRunner) RunArgs(ctx context.Context, args *scheduler.FSIndexContentArgs) error {
+	for {
+		fileIDs, err := r.storage.GetAllFileIDsToIndex(ctx, indexBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to GetAllFileIDsToIndex: %w", err)
+		}
+
+		for _, fileID := range fileIDs {
+			err = r.indexFile(ctx, fileID)
+			if err != nil {
+				return fmt.Errorf("failed to index the file %q: %w", fileID, err)
+			}
+		}
+
+		if len(fileIDs) < indexBatchSize {
+			return nil
+		}
+	}
+}
+
+// indexFile saves the text content of the file into the search index.
+//
+// A missing file is saved with an empty content in order to not retry it
+// at each run.
+func (r *FSIndexContentTaskRunner) indexFile(ctx context.Context, fileID uuid.UUID) error {
+	content, err := r.readContent(ctx, fileID)
+	if errors.Is(err, files.ErrNotExist) {
+		content = ""
+	} else if err != nil {
+		return err
+	}
+
+	err = r.storage.SaveFileContent(ctx, fileID, content)
+	if err != nil {
+		return fmt.Errorf("failed to SaveFileContent: %w", err)
+	}
+
+	return nil
+}
+
+func (r *FSIndexContentTaskRunner) readContent(ctx context.Context, fileID uuid.UUID) (string, error) {
+	fileMeta, err := r.files.GetMetadata(ctx, fileID)
+	if err != nil {
+		return "", fmt.Errorf("failed to GetMetadata: %w", err)
+	}
+
+	file, err := r.files.Download(ctx, fileMeta)
+	if err != nil {
+		return "", fmt.Errorf("failed to Download: %w", err)
+	}
+	defer file.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(file, maxIndexedContentSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the content: %w", err)
+	}
+
+	return strings.ToValidUTF8(string(raw), " "), nil
+}
@@ -0,0 +1,124 @@
+package dfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func TestFSIndexContentTask(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Name", func(t *testing.T) {
+		runner := NewFSIndexContentTaskRunner(nil, nil)
+		assert.Equal(t, "fs-index-content", runner.Name())
+	})
+
+	t.Run("Run success", func(t *testing.T) {
+		storageMock := newMockStorage(t)
+		filesMock := files.NewMockService(t)
+		runner := NewFSIndexContentTaskRunner(storageMock, filesMock)
+
+		file, err := afero.TempFile(afero.NewMemMapFs(), "foo", "")
+		require.NoError(t, err)
+		_, err = file.WriteString("Hello, World!\xff")
+		require.NoError(t, err)
+		_, err = file.Seek(0, 0)
+		require.NoError(t, err)
+
+		storageMock.On("GetAllFileIDsToIndex", mock.Anything, indexBatchSize).
+			Return([]uuid.UUID{files.ExampleFile1.ID()}, nil).Once()
+		filesMock.On("GetMetadata", mock.Anything, files.ExampleFile1.ID()).Return(&files.ExampleFile1, nil).Once()
+		filesMock.On("Download", mock.Anything, &files.ExampleFile1).Return(file, nil).Once()
+		storageMock.On("SaveFileContent", mock.Anything, files.ExampleFile1.ID(), "Hello, World! ").Return(nil).Once()
+
+		err = runner.Run(ctx, json.RawMessage(`{}`))
+		require.NoError(t, err)
+	})
+
+	t.Run("Run with a full batch", func(t *testing.T) {
+		storageMock := newMockStorage(t)
+		filesMock := files.NewMockService(t)
+		runner := NewFSIndexContentTaskRunner(storageMock, filesMock)
+
+		fileIDs := make([]uuid.UUID, indexBatchSize)
+		for i := range fileIDs {
+			fileIDs[i] = uuid.UUID(fmt.Sprintf("file-%d", i))
+		}
+
+		// All the files are missing so their content is empty.
+		storageMock.On("GetAllFileIDsToIndex", mock.Anything, indexBatchSize).Return(fileIDs, nil).Once()
+		filesMock.On("GetMetadata", mock.Anything, mock.Anything).Return(nil, files.ErrNotExist).Times(indexBatchSize)
+		storageMock.On("SaveFileContent", mock.Anything, mock.Anything, "").Return(nil).Times(indexBatchSize)
+
+		// A second batch is fetched because the first one was full.
+		storageMock.On("GetAllFileIDsToIndex", mock.Anything, indexBatchSize).Return([]uuid.UUID{}, nil).Once()
+
+		err := runner.Run(ctx, json.RawMessage(`{}`))
+		require.NoError(t, err)
+	})
+
+	t.Run("Run with a file missing on disk", func(t *testing.T) {
+		storageMock := newMockStorage(t)
+		filesMock := files.NewMockService(t)
+		runner := NewFSIndexContentTaskRunner(storageMock, filesMock)
+
+		storageMock.On("GetAllFileIDsToIndex", mock.Anything, indexBatchSize).
+			Return([]uuid.UUID{files.ExampleFile1.ID()}, nil).Once()
+		filesMock.On("GetMetadata", mock.Anything, files.ExampleFile1.ID()).Return(&files.ExampleFile1, nil).Once()
+		filesMock.On("Download", mock.Anything, &files.ExampleFile1).
+			Return(nil, errs.BadRequest(fmt.Errorf("some-path: %w", files.ErrNotExist))).Once()
+		storageMock.On("SaveFileContent", mock.Anything, files.ExampleFile1.ID(), "").Return(nil).Once()
+
+		err := runner.Run(ctx, json.RawMessage(`{}`))
+		require.NoError(t, err)
+	})
+
+	t.Run("Run with a GetAllFileIDsToIndex error", func(t *testing.T) {
+		storageMock := newMockStorage(t)
+		filesMock := files.NewMockService(t)
+		runner := NewFSIndexContentTaskRunner(storageMock, filesMock)
+
+		storageMock.On("GetAllFileIDsToIndex", mock.Anything, indexBatchSize).Return(nil, fmt.Errorf("some-error")).Once()
+
+		err := runner.Run(ctx, json.RawMessage(`{}`))
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("Run with a Download error", func(t *testing.T) {
+		storageMock := newMockStorage(t)
+		filesMock := files.NewMockService(t)
+		runner := NewFSIndexContentTaskRunner(storageMock, filesMock)
+
+		storageMock.On("GetAllFileIDsToIndex", mock.Anything, indexBatchSize).
+			Return([]uuid.UUID{files.ExampleFile1.ID()}, nil).Once()
+		filesMock.On("GetMetadata", mock.Anything, files.ExampleFile1.ID()).Return(&files.ExampleFile1, nil).Once()
+		filesMock.On("Download", mock.Anything, &files.ExampleFile1).Return(nil, fmt.Errorf("some-error")).Once()
+
+		err := runner.Run(ctx, json.RawMessage(`{}`))
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("Run with a SaveFileContent error", func(t *testing.T) {
+		storageMock := newMockStorage(t)
+		filesMock := files.NewMockService(t)
+		runner := NewFSIndexContentTaskRunner(storageMock, filesMock)
+
+		storageMock.On("GetAllFileIDsToIndex", mock.Anything, indexBatchSize).
+			Return([]uuid.UUID{files.ExampleFile1.ID()}, nil).Once()
+		filesMock.On("GetMetadata", mock.Anything, files.ExampleFile1.ID()).Return(nil, files.ErrNotExist).Once()
+		storageMock.On("SaveFileContent", mock.Anything, files.ExampleFile1.ID(), "").Return(fmt.Errorf("some-error")).Once()
+
+		err := runner.Run(ctx, json.RawMessage(`{}`))
+		require.ErrorContains(t, err, "some-error")
+	})
+}
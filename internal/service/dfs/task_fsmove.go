@@ -11,18 +11,23 @@ import (
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
+const moveBatchSize = 10
+
 type FSMoveTaskRunner struct {
-	fs        Service
-	storage   storage
-	spaces    spaces.Service
-	users     users.Service
-	scheduler scheduler.Service
+	fs         Service
+	storage    storage
+	spaces     spaces.Service
+	users      users.Service
+	scheduler  scheduler.Service
+	spaceMover SpaceMover
 }
 
-func NewFSMoveTaskRunner(fs Service, storage storage, spaces spaces.Service, users users.Service, scheduler scheduler.Service) *FSMoveTaskRunner {
-	return &FSMoveTaskRunner{fs, storage, spaces, users, scheduler}
+func NewFSMoveTaskRunner(fs Service, storage storage, spaces spaces.Service, users users.Service, scheduler scheduler.Service, spaceMover SpaceMover) *FSMoveTaskRunner {
+	return &FSMoveTaskRunner{fs, storage, spaces, users, scheduler, spaceMover}
 }
 
 func (r *FSMoveTaskRunner) Name() string { return "fs-move" }
@@ -38,7 +43,12 @@ func (r *FSMoveTaskRunner) Run(ctx context.Context, rawArgs json.RawMessage) err
 }
 
 func (r *FSMoveTaskRunner) RunArgs(ctx context.Context, args *scheduler.FSMoveArgs) error {
-	space, err := r.spaces.GetByID(ctx, args.SpaceID)
+	targetSpaceID := args.TargetSpaceID
+	if targetSpaceID == "" {
+		targetSpaceID = args.SpaceID
+	}
+
+	space, err := r.spaces.GetByID(ctx, targetSpaceID)
 	if err != nil {
 		return fmt.Errorf("failed to Get the space: %w", err)
 	}
@@ -87,6 +97,17 @@ func (r *FSMoveTaskRunner) RunArgs(ctx context.Context, args *scheduler.FSMoveAr
 
 	ctx = context.WithoutCancel(ctx)
 
+	if oldNode.SpaceID() != targetDir.SpaceID() {
+		// XXX:MULTI-WRITE
+		//
+		// The inode is already attached to its new parent. In case of error the
+		// task is retried and all the inodes not yet moved are fixed.
+		err = r.setSpace(ctx, oldNode, targetDir.SpaceID())
+		if err != nil {
+			return fmt.Errorf("failed to move %q to the space %q: %w", oldNode.ID(), targetDir.SpaceID(), err)
+		}
+	}
+
 	if existingFile != nil {
 		// XXX:MULTI-WRITE
 		//
@@ -125,3 +146,51 @@ func (r *FSMoveTaskRunner) RunArgs(ctx context.Context, args *scheduler.FSMoveAr
 
 	return nil
 }
+
+// setSpace moves the inode and all its childrens, deleted ones included, into
+// the given space. The grants and the data kept by the SpaceMover follow the
+// inodes so that they still resolve inside the new space.
+func (r *FSMoveTaskRunner) setSpace(ctx context.Context, inode *INode, spaceID uuid.UUID) error {
+	err := r.storage.Patch(ctx, inode.ID(), map[string]any{"space_id": spaceID})
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to Patch: %w", err))
+	}
+
+	err = r.storage.MoveAllINodeGrants(ctx, inode.ID(), spaceID)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to MoveAllINodeGrants: %w", err))
+	}
+
+	err = r.spaceMover.MoveToSpace(ctx, inode.ID(), spaceID)
+	if err != nil {
+		return fmt.Errorf("failed to MoveToSpace: %w", err)
+	}
+
+	if !inode.IsDir() {
+		return nil
+	}
+
+	lastID := ""
+	for {
+		childs, err := r.storage.GetAllChildrensWithDeleted(ctx, inode.ID(), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": lastID},
+			Limit:      moveBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to GetAllChildrensWithDeleted: %w", err)
+		}
+
+		for i := range childs {
+			err = r.setSpace(ctx, &childs[i], spaceID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(childs) < moveBatchSize {
+			return nil
+		}
+
+		lastID = string(childs[len(childs)-1].ID())
+	}
+}
@@ -3,6 +3,7 @@ package dfs
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -21,7 +22,7 @@ func TestFSMoveTask(t *testing.T) {
 	now := time.Now().UTC().Add(time.Minute)
 
 	t.Run("Name", func(t *testing.T) {
-		runner := NewFSMoveTaskRunner(nil, nil, nil, nil, nil)
+		runner := NewFSMoveTaskRunner(nil, nil, nil, nil, nil, nil)
 		assert.Equal(t, "fs-move", runner.Name())
 	})
 
@@ -31,7 +32,8 @@ func TestFSMoveTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		fsMock := NewMockService(t)
 		storageMock := newMockStorage(t)
-		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock)
+		spaceMoverMock := NewMockSpaceMover(t)
+		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, spaceMoverMock)
 
 		require.True(t, ExampleAliceRoot.LastModifiedAt().Before(now))
 
@@ -69,13 +71,190 @@ func TestFSMoveTask(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("RunArg to an other space success", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		fsMock := NewMockService(t)
+		storageMock := newMockStorage(t)
+		spaceMoverMock := NewMockSpaceMover(t)
+		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, spaceMoverMock)
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleBobPersonalSpace.ID()).
+			Return(&spaces.ExampleBobPersonalSpace, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+		fsMock.On("Get", mock.Anything, NewPathCmd(&spaces.ExampleBobPersonalSpace, "/bar.txt")).Return(nil, errs.ErrNotFound).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile.ID()).
+			Return(&ExampleAliceFile, nil).Once()
+		fsMock.On("CreateDir", mock.Anything, &CreateDirCmd{
+			Path:      NewPathCmd(&spaces.ExampleBobPersonalSpace, "/"),
+			CreatedBy: &users.ExampleAlice,
+		}).Return(&ExampleBobRoot, nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"parent":           ptr.To(ExampleBobRoot.ID()),
+			"name":             "bar.txt",
+			"last_modified_at": now,
+		}).Return(nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"space_id": spaces.ExampleBobPersonalSpace.ID(),
+		}).Return(nil).Once()
+		storageMock.On("MoveAllINodeGrants", mock.Anything, ExampleAliceFile.ID(), spaces.ExampleBobPersonalSpace.ID()).
+			Return(nil).Once()
+		spaceMoverMock.On("MoveToSpace", mock.Anything, ExampleAliceFile.ID(), spaces.ExampleBobPersonalSpace.ID()).
+			Return(nil).Once()
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      *ExampleAliceFile.Parent(),
+			ModifiedAt: now,
+		}).Return(nil).Once()
+		schedulerMock.On("RegisterFSRefreshSizeTask", mock.Anything, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleBobRoot.ID(),
+			ModifiedAt: now,
+		}).Return(nil).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSMoveArgs{
+			SpaceID:       spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode:   ExampleAliceFile.ID(),
+			TargetSpaceID: spaces.ExampleBobPersonalSpace.ID(),
+			TargetPath:    "/bar.txt",
+			MovedAt:       now,
+			MovedBy:       users.ExampleAlice.ID(),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArg to an other space with a Patch error", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		fsMock := NewMockService(t)
+		storageMock := newMockStorage(t)
+		spaceMoverMock := NewMockSpaceMover(t)
+		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, spaceMoverMock)
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleBobPersonalSpace.ID()).
+			Return(&spaces.ExampleBobPersonalSpace, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+		fsMock.On("Get", mock.Anything, NewPathCmd(&spaces.ExampleBobPersonalSpace, "/bar.txt")).Return(nil, errs.ErrNotFound).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile.ID()).
+			Return(&ExampleAliceFile, nil).Once()
+		fsMock.On("CreateDir", mock.Anything, &CreateDirCmd{
+			Path:      NewPathCmd(&spaces.ExampleBobPersonalSpace, "/"),
+			CreatedBy: &users.ExampleAlice,
+		}).Return(&ExampleBobRoot, nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"parent":           ptr.To(ExampleBobRoot.ID()),
+			"name":             "bar.txt",
+			"last_modified_at": now,
+		}).Return(nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"space_id": spaces.ExampleBobPersonalSpace.ID(),
+		}).Return(fmt.Errorf("some-error")).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSMoveArgs{
+			SpaceID:       spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode:   ExampleAliceFile.ID(),
+			TargetSpaceID: spaces.ExampleBobPersonalSpace.ID(),
+			TargetPath:    "/bar.txt",
+			MovedAt:       now,
+			MovedBy:       users.ExampleAlice.ID(),
+		})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("RunArg to an other space with a MoveAllINodeGrants error", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		fsMock := NewMockService(t)
+		storageMock := newMockStorage(t)
+		spaceMoverMock := NewMockSpaceMover(t)
+		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, spaceMoverMock)
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleBobPersonalSpace.ID()).
+			Return(&spaces.ExampleBobPersonalSpace, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+		fsMock.On("Get", mock.Anything, NewPathCmd(&spaces.ExampleBobPersonalSpace, "/bar.txt")).Return(nil, errs.ErrNotFound).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile.ID()).
+			Return(&ExampleAliceFile, nil).Once()
+		fsMock.On("CreateDir", mock.Anything, &CreateDirCmd{
+			Path:      NewPathCmd(&spaces.ExampleBobPersonalSpace, "/"),
+			CreatedBy: &users.ExampleAlice,
+		}).Return(&ExampleBobRoot, nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"parent":           ptr.To(ExampleBobRoot.ID()),
+			"name":             "bar.txt",
+			"last_modified_at": now,
+		}).Return(nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"space_id": spaces.ExampleBobPersonalSpace.ID(),
+		}).Return(nil).Once()
+		storageMock.On("MoveAllINodeGrants", mock.Anything, ExampleAliceFile.ID(), spaces.ExampleBobPersonalSpace.ID()).
+			Return(fmt.Errorf("some-error")).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSMoveArgs{
+			SpaceID:       spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode:   ExampleAliceFile.ID(),
+			TargetSpaceID: spaces.ExampleBobPersonalSpace.ID(),
+			TargetPath:    "/bar.txt",
+			MovedAt:       now,
+			MovedBy:       users.ExampleAlice.ID(),
+		})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("RunArg to an other space with a MoveToSpace error", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		fsMock := NewMockService(t)
+		storageMock := newMockStorage(t)
+		spaceMoverMock := NewMockSpaceMover(t)
+		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, spaceMoverMock)
+
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleBobPersonalSpace.ID()).
+			Return(&spaces.ExampleBobPersonalSpace, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+		fsMock.On("Get", mock.Anything, NewPathCmd(&spaces.ExampleBobPersonalSpace, "/bar.txt")).Return(nil, errs.ErrNotFound).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile.ID()).
+			Return(&ExampleAliceFile, nil).Once()
+		fsMock.On("CreateDir", mock.Anything, &CreateDirCmd{
+			Path:      NewPathCmd(&spaces.ExampleBobPersonalSpace, "/"),
+			CreatedBy: &users.ExampleAlice,
+		}).Return(&ExampleBobRoot, nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"parent":           ptr.To(ExampleBobRoot.ID()),
+			"name":             "bar.txt",
+			"last_modified_at": now,
+		}).Return(nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"space_id": spaces.ExampleBobPersonalSpace.ID(),
+		}).Return(nil).Once()
+		storageMock.On("MoveAllINodeGrants", mock.Anything, ExampleAliceFile.ID(), spaces.ExampleBobPersonalSpace.ID()).
+			Return(nil).Once()
+		spaceMoverMock.On("MoveToSpace", mock.Anything, ExampleAliceFile.ID(), spaces.ExampleBobPersonalSpace.ID()).
+			Return(fmt.Errorf("some-error")).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSMoveArgs{
+			SpaceID:       spaces.ExampleAlicePersonalSpace.ID(),
+			SourceInode:   ExampleAliceFile.ID(),
+			TargetSpaceID: spaces.ExampleBobPersonalSpace.ID(),
+			TargetPath:    "/bar.txt",
+			MovedAt:       now,
+			MovedBy:       users.ExampleAlice.ID(),
+		})
+		require.ErrorContains(t, err, "some-error")
+	})
+
 	t.Run("RunArg with an existing file at destination", func(t *testing.T) {
 		spacesMock := spaces.NewMockService(t)
 		schedulerMock := scheduler.NewMockService(t)
 		usersMock := users.NewMockService(t)
 		fsMock := NewMockService(t)
 		storageMock := newMockStorage(t)
-		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock)
+		spaceMoverMock := NewMockSpaceMover(t)
+		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, spaceMoverMock)
 
 		require.True(t, ExampleAliceRoot.LastModifiedAt().Before(now))
 
@@ -120,7 +299,8 @@ func TestFSMoveTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		fsMock := NewMockService(t)
 		storageMock := newMockStorage(t)
-		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock)
+		spaceMoverMock := NewMockSpaceMover(t)
+		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, spaceMoverMock)
 
 		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
 			Return(nil, errs.ErrNotFound).Once()
@@ -141,7 +321,8 @@ func TestFSMoveTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		fsMock := NewMockService(t)
 		storageMock := newMockStorage(t)
-		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock)
+		spaceMoverMock := NewMockSpaceMover(t)
+		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, spaceMoverMock)
 
 		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
 			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
@@ -166,7 +347,8 @@ func TestFSMoveTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		fsMock := NewMockService(t)
 		storageMock := newMockStorage(t)
-		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock)
+		spaceMoverMock := NewMockSpaceMover(t)
+		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, spaceMoverMock)
 
 		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
 			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
@@ -190,7 +372,8 @@ func TestFSMoveTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		fsMock := NewMockService(t)
 		storageMock := newMockStorage(t)
-		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock)
+		spaceMoverMock := NewMockSpaceMover(t)
+		runner := NewFSMoveTaskRunner(fsMock, storageMock, spacesMock, usersMock, schedulerMock, spaceMoverMock)
 
 		require.True(t, ExampleAliceRoot.LastModifiedAt().Before(now))
 
@@ -0,0 +1,134 @@
+package dfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/clock"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+type FSPruneVersionsTaskRunner struct {
+	storage storage
+	spaces  spaces.Service
+	gc      *FSGGCTaskRunner
+	clock   clock.Clock
+}
+
+func NewFSPruneVersionsTaskRunner(storage storage, spaces spaces.Service, gc *FSGGCTaskRunner, tools tools.Tools) *FSPruneVersionsTaskRunner {
+	return &FSPruneVersionsTaskRunner{
+		storage: storage,
+		spaces:  spaces,
+		gc:      gc,
+		clock:   tools.Clock(),
+	}
+}
+
+func (r *FSPruneVersionsTaskRunner) Name() string { return "fs-prune-versions" }
+
+func (r *FSPruneVersionsTaskRunner) Run(ctx context.Context, rawArgs json.RawMessage) error {
+	return r.RunArgs(ctx, &scheduler.FSPruneVersionsArgs{})
+}
+
+func (r *FSPruneVersionsTaskRunner) RunArgs(ctx context.Context, args *scheduler.FSPruneVersionsArgs) error {
+	spacesCache := map[uuid.UUID]*spaces.Space{}
+	lastID := ""
+
+	for {
+		inodeIDs, err := r.storage.GetAllVersionedINodeIDs(ctx, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"inode_id": lastID},
+			Limit:      gcBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to GetAllVersionedINodeIDs: %w", err)
+		}
+
+		for _, inodeID := range inodeIDs {
+			lastID = string(inodeID)
+
+			err = r.pruneINodeVersions(ctx, inodeID, spacesCache)
+			if err != nil {
+				return fmt.Errorf("failed to prune the versions of %q: %w", inodeID, err)
+			}
+		}
+
+		if len(inodeIDs) < gcBatchSize {
+			return nil
+		}
+	}
+}
+
+func (r *FSPruneVersionsTaskRunner) pruneINodeVersions(ctx context.Context, inodeID uuid.UUID, cache map[uuid.UUID]*spaces.Space) error {
+	inode, err := r.storage.GetByID(ctx, inodeID)
+	if errors.Is(err, errNotFound) {
+		// The inode have been purged, its versions are removed by the fs-gc task.
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to GetByID: %w", err)
+	}
+
+	space, err := r.getSpace(ctx, inode.SpaceID(), cache)
+	if err != nil {
+		return err
+	}
+
+	if space == nil {
+		// The space have been deleted, all its content will be purged by the fs-gc task.
+		return nil
+	}
+
+	versions, err := r.storage.GetAllINodeVersions(ctx, inodeID)
+	if err != nil {
+		return fmt.Errorf("failed to GetAllINodeVersions: %w", err)
+	}
+
+	now := r.clock.Now()
+
+	// XXX:MULTI-WRITE
+	//
+	// Each version deletion is idempotent and the task is retried in case of error.
+	for i, version := range versions {
+		tooMany := space.MaxVersions() > 0 && i >= space.MaxVersions()
+		tooOld := space.VersionsRetention() > 0 && now.Sub(version.CreatedAt()) > space.VersionsRetention()
+
+		if !tooMany && !tooOld {
+			continue
+		}
+
+		err = r.gc.deleteVersion(ctx, &version)
+		if err != nil {
+			return fmt.Errorf("failed to delete the version %q: %w", version.ID(), err)
+		}
+	}
+
+	return nil
+}
+
+func (r *FSPruneVersionsTaskRunner) getSpace(ctx context.Context, spaceID uuid.UUID, cache map[uuid.UUID]*spaces.Space) (*spaces.Space, error) {
+	if space, ok := cache[spaceID]; ok {
+		return space, nil
+	}
+
+	space, err := r.spaces.GetByID(ctx, spaceID)
+	if errors.Is(err, errs.ErrNotFound) {
+		cache[spaceID] = nil
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetByID the space: %w", err)
+	}
+
+	cache[spaceID] = space
+
+	return space, nil
+}
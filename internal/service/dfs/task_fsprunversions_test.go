@@ -0,0 +1,182 @@
+package dfs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func TestFSPruneVersionsTask(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	newVersion := func(inode *INode, fileID uuid.UUID, createdAt time.Time) FileVersion {
+		return FileVersion{
+			id:         uuid.NewProvider().New(),
+			inodeID:    inode.ID(),
+			fileID:     fileID,
+			size:       42,
+			modifiedAt: createdAt,
+			createdAt:  createdAt,
+			createdBy:  users.ExampleAlice.ID(),
+		}
+	}
+
+	t.Run("Name", func(t *testing.T) {
+		runner := NewFSPruneVersionsTaskRunner(nil, nil, nil, tools.NewMock(t))
+		assert.Equal(t, "fs-prune-versions", runner.Name())
+	})
+
+	t.Run("RunArgs with too many versions", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		gc := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
+		runner := NewFSPruneVersionsTaskRunner(storageMock, spacesMock, gc, tools)
+
+		space := spaces.NewFakeSpace(t).WithVersionsPolicy(1, 0).Build()
+		inode := NewFakeINode(t).WithSpace(space).Build()
+		lastVersion := newVersion(inode, files.ExampleFile1.ID(), now.Add(-time.Minute))
+		oldVersion := newVersion(inode, files.ExampleFile2.ID(), now.Add(-time.Hour))
+
+		storageMock.On("GetAllVersionedINodeIDs", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"inode_id": ""},
+			Limit:      gcBatchSize,
+		}).Return([]uuid.UUID{inode.ID()}, nil).Once()
+		storageMock.On("GetByID", mock.Anything, inode.ID()).Return(inode, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, space.ID()).Return(space, nil).Once()
+		storageMock.On("GetAllINodeVersions", mock.Anything, inode.ID()).Return([]FileVersion{lastVersion, oldVersion}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		// Only the oldest version is removed with its file.
+		storageMock.On("DeleteVersion", mock.Anything, oldVersion.ID()).Return(nil).Once()
+		storageMock.On("GetAllInodesWithFileID", mock.Anything, oldVersion.FileID()).Return([]INode{}, nil).Once()
+		storageMock.On("GetAllVersionsWithFileID", mock.Anything, oldVersion.FileID()).Return([]FileVersion{}, nil).Once()
+		storageMock.On("DeleteFileContent", mock.Anything, oldVersion.FileID()).Return(nil).Once()
+		filesMock.On("Delete", mock.Anything, oldVersion.FileID()).Return(nil).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSPruneVersionsArgs{})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with an expired version", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		gc := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
+		runner := NewFSPruneVersionsTaskRunner(storageMock, spacesMock, gc, tools)
+
+		space := spaces.NewFakeSpace(t).WithVersionsPolicy(0, 24*time.Hour).Build()
+		inode := NewFakeINode(t).WithSpace(space).Build()
+		recentVersion := newVersion(inode, files.ExampleFile1.ID(), now.Add(-time.Hour))
+		expiredVersion := newVersion(inode, files.ExampleFile2.ID(), now.Add(-48*time.Hour))
+
+		storageMock.On("GetAllVersionedINodeIDs", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"inode_id": ""},
+			Limit:      gcBatchSize,
+		}).Return([]uuid.UUID{inode.ID()}, nil).Once()
+		storageMock.On("GetByID", mock.Anything, inode.ID()).Return(inode, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, space.ID()).Return(space, nil).Once()
+		storageMock.On("GetAllINodeVersions", mock.Anything, inode.ID()).Return([]FileVersion{recentVersion, expiredVersion}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		// The file is still used by another inode so it's kept.
+		storageMock.On("DeleteVersion", mock.Anything, expiredVersion.ID()).Return(nil).Once()
+		storageMock.On("GetAllInodesWithFileID", mock.Anything, expiredVersion.FileID()).Return([]INode{ExampleAliceFile}, nil).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSPruneVersionsArgs{})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with an inode already purged", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		storageMock := newMockStorage(t)
+		runner := NewFSPruneVersionsTaskRunner(storageMock, spacesMock, nil, tools)
+
+		storageMock.On("GetAllVersionedINodeIDs", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"inode_id": ""},
+			Limit:      gcBatchSize,
+		}).Return([]uuid.UUID{ExampleAliceFile.ID()}, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile.ID()).Return(nil, errNotFound).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSPruneVersionsArgs{})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with a space not found", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		storageMock := newMockStorage(t)
+		runner := NewFSPruneVersionsTaskRunner(storageMock, spacesMock, nil, tools)
+
+		storageMock.On("GetAllVersionedINodeIDs", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"inode_id": ""},
+			Limit:      gcBatchSize,
+		}).Return([]uuid.UUID{ExampleAliceFile.ID()}, nil).Once()
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile.ID()).Return(&ExampleAliceFile, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, ExampleAliceFile.SpaceID()).Return(nil, errs.ErrNotFound).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSPruneVersionsArgs{})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with a GetAllVersionedINodeIDs error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		runner := NewFSPruneVersionsTaskRunner(storageMock, nil, nil, tools)
+
+		storageMock.On("GetAllVersionedINodeIDs", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"inode_id": ""},
+			Limit:      gcBatchSize,
+		}).Return(nil, fmt.Errorf("some-error")).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSPruneVersionsArgs{})
+		require.ErrorContains(t, err, "failed to GetAllVersionedINodeIDs: some-error")
+	})
+
+	t.Run("RunArgs with a DeleteVersion error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		spacesMock := spaces.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		gc := NewFSGGCTaskRunner(storageMock, filesMock, spacesMock, schedulerMock, tools)
+		runner := NewFSPruneVersionsTaskRunner(storageMock, spacesMock, gc, tools)
+
+		space := spaces.NewFakeSpace(t).WithVersionsPolicy(1, 0).Build()
+		inode := NewFakeINode(t).WithSpace(space).Build()
+		lastVersion := newVersion(inode, files.ExampleFile1.ID(), now.Add(-time.Minute))
+		oldVersion := newVersion(inode, files.ExampleFile2.ID(), now.Add(-time.Hour))
+
+		storageMock.On("GetAllVersionedINodeIDs", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"inode_id": ""},
+			Limit:      gcBatchSize,
+		}).Return([]uuid.UUID{inode.ID()}, nil).Once()
+		storageMock.On("GetByID", mock.Anything, inode.ID()).Return(inode, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, space.ID()).Return(space, nil).Once()
+		storageMock.On("GetAllINodeVersions", mock.Anything, inode.ID()).Return([]FileVersion{lastVersion, oldVersion}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("DeleteVersion", mock.Anything, oldVersion.ID()).Return(fmt.Errorf("some-error")).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSPruneVersionsArgs{})
+		require.ErrorContains(t, err, "failed to DeleteVersion: some-error")
+	})
+}
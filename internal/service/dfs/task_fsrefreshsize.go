@@ -45,10 +45,15 @@ func (r *FSRefreshSizeTaskRunner) RunArgs(ctx context.Context, args *scheduler.F
 		var newSize uint64
 
 		inode, err := r.storage.GetByID(ctx, *inodeID)
-		if errors.Is(err, errs.ErrNotFound) {
+		if errors.Is(err, errNotFound) || errors.Is(err, errs.ErrNotFound) {
+			// The inode have been purged, there is nothing left to refresh.
 			return nil
 		}
 
+		if err != nil {
+			return fmt.Errorf("failed to GetByID %q: %w", *inodeID, err)
+		}
+
 		switch inode.IsDir() {
 		case true:
 			newSize, err = r.storage.GetSumChildsSize(ctx, inode.ID())
@@ -64,10 +69,15 @@ func (r *FSRefreshSizeTaskRunner) RunArgs(ctx context.Context, args *scheduler.F
 			newSize = fileMeta.Size()
 		}
 
-		err = r.storage.Patch(ctx, inode.ID(), map[string]any{
-			"last_modified_at": args.ModifiedAt,
-			"size":             newSize,
-		})
+		fields := map[string]any{"size": newSize}
+
+		// The modification time of a file is set during the upload and can
+		// be provided by the client, only the directories are updated.
+		if inode.IsDir() {
+			fields["last_modified_at"] = args.ModifiedAt
+		}
+
+		err = r.storage.Patch(ctx, inode.ID(), fields)
 		if err != nil {
 			return errs.Internal(fmt.Errorf("failed to Patch: %w", err))
 		}
@@ -58,6 +58,36 @@ func TestFSRefreshSizeTask(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("RunArg with a file keeps its modification time", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		statsMock := stats.NewMockService(t)
+		runner := NewFSRefreshSizeTaskRunner(storageMock, filesMock, statsMock)
+
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFile.ID()).Return(&ExampleAliceFile, nil).Once()
+		filesMock.On("GetMetadata", mock.Anything, *ExampleAliceFile.FileID()).Return(&files.ExampleFile1, nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceFile.ID(), map[string]any{
+			"size": uint64(42),
+		}).Return(nil).Once()
+
+		// Only the parent directory takes the new modification time
+		storageMock.On("GetByID", mock.Anything, *ExampleAliceFile.Parent()).Return(&ExampleAliceRoot, nil).Once()
+		storageMock.On("GetSumChildsSize", mock.Anything, ExampleAliceRoot.ID()).Return(uint64(42), nil).Once()
+		storageMock.On("Patch", mock.Anything, ExampleAliceRoot.ID(), map[string]any{
+			"last_modified_at": now,
+			"size":             uint64(42),
+		}).Return(nil).Once()
+
+		storageMock.On("GetSumRootsSize", mock.Anything).Return(uint64(42), nil).Once()
+		statsMock.On("SetTotalSize", mock.Anything, uint64(42)).Return(nil).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceFile.ID(),
+			ModifiedAt: now,
+		})
+		require.NoError(t, err)
+	})
+
 	t.Run("RunArg with an inode not found", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		storageMock := newMockStorage(t)
@@ -82,6 +112,36 @@ func TestFSRefreshSizeTask(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("RunArg with an inode purged", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		statsMock := stats.NewMockService(t)
+		runner := NewFSRefreshSizeTaskRunner(storageMock, filesMock, statsMock)
+
+		storageMock.On("GetByID", mock.Anything, ExampleAliceDir.ID()).Return(nil, errNotFound).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceDir.ID(),
+			ModifiedAt: now,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArg with a GetByID error", func(t *testing.T) {
+		filesMock := files.NewMockService(t)
+		storageMock := newMockStorage(t)
+		statsMock := stats.NewMockService(t)
+		runner := NewFSRefreshSizeTaskRunner(storageMock, filesMock, statsMock)
+
+		storageMock.On("GetByID", mock.Anything, ExampleAliceDir.ID()).Return(nil, errors.New("some-error")).Once()
+
+		err := runner.RunArgs(ctx, &scheduler.FSRefreshSizeArg{
+			INode:      ExampleAliceDir.ID(),
+			ModifiedAt: now,
+		})
+		require.ErrorContains(t, err, "some-error")
+	})
+
 	t.Run("RunArg with a GetSumChildsSize error", func(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		storageMock := newMockStorage(t)
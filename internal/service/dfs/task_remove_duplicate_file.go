@@ -61,6 +61,11 @@ func (r *FSRemoveDuplicateFilesRunner) RunArgs(ctx context.Context, args *schedu
 		}
 	}
 
+	err = r.storage.DeleteFileContent(ctx, args.DuplicateFileID)
+	if err != nil {
+		return fmt.Errorf("failed to DeleteFileContent: %w", err)
+	}
+
 	err = r.files.Delete(ctx, args.DuplicateFileID)
 	if err != nil {
 		return fmt.Errorf("failed to Delete the old file id: %w", err)
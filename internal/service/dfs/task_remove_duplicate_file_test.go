@@ -43,6 +43,7 @@ func Test_FSRemoveDuplicateFilesRunner_Task(t *testing.T) {
 			ModifiedAt: ExampleAliceDir.lastModifiedAt,
 		}).Return(nil).Once()
 
+		storageMock.On("DeleteFileContent", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
 		filesMock.On("Delete", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
 
 		err := runner.RunArgs(ctx, &scheduler.FSRemoveDuplicateFileArgs{
@@ -133,6 +134,7 @@ func Test_FSRemoveDuplicateFilesRunner_Task(t *testing.T) {
 			ModifiedAt: ExampleAliceDir.lastModifiedAt,
 		}).Return(nil).Once()
 
+		storageMock.On("DeleteFileContent", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
 		filesMock.On("Delete", mock.Anything, *ExampleAliceFile.FileID()).Return(nil).Once()
 
 		err := runner.Run(ctx, json.RawMessage(`{
@@ -72,3 +72,22 @@ func CleanPath(name string) string {
 	}
 	return path.Clean(name)
 }
+
+// quotaReader returns ErrQuotaExceeded once more than `remaining` bytes have
+// been read.
+type quotaReader struct {
+	r         io.Reader
+	remaining uint64
+}
+
+func (q *quotaReader) Read(p []byte) (int, error) {
+	n, err := q.r.Read(p)
+	if uint64(n) > q.remaining {
+		q.remaining = 0
+		return n, ErrQuotaExceeded
+	}
+
+	q.remaining -= uint64(n)
+
+	return n, err
+}
@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !freebsd
+
+package files
+
+func freeDiskSpace(_ string) (uint64, error) {
+	return 0, ErrUnknownSpace
+}
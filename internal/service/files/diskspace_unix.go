@@ -0,0 +1,20 @@
+//go:build linux || darwin || freebsd
+
+package files
+
+import (
+	"fmt"
+	"syscall"
+)
+
+func freeDiskSpace(dirPath string) (uint64, error) {
+	var stat syscall.Statfs_t
+
+	err := syscall.Statfs(dirPath, &stat)
+	if err != nil {
+		return 0, fmt.Errorf("failed to statfs %q: %w", dirPath, err)
+	}
+
+	// Bavail is the number of blocks available for the unprivileged users.
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -22,6 +22,8 @@ type Service interface {
 	Download(ctx context.Context, file *FileMeta) (io.ReadSeekCloser, error)
 	Delete(ctx context.Context, fileID uuid.UUID) error
 	GetMetadata(ctx context.Context, fileID uuid.UUID) (*FileMeta, error)
+	GetAllMetadata(ctx context.Context, fileIDs []uuid.UUID) (map[uuid.UUID]FileMeta, error)
+	GetFreeSpace(ctx context.Context) (uint64, error)
 }
 
 type Result struct {
@@ -52,6 +54,11 @@ func Init(
 
 	service := newService(storage, rootFS, tools, masterkey)
 
+	// The free space can only be found for the files saved on a real disk.
+	if _, ok := fs.(*afero.OsFs); ok {
+		service.diskPath = root
+	}
+
 	return Result{
 		Service: service,
 	}, nil
@@ -26,12 +26,14 @@ var (
 	ErrInvalidPath   = errors.New("invalid path")
 	ErrInodeNotAFile = errors.New("inode doesn't point to a file")
 	ErrNotExist      = errors.New("file not exists")
+	ErrUnknownSpace  = errors.New("unknown free space")
 )
 
 //go:generate mockery --name storage
 type storage interface {
 	Save(ctx context.Context, meta *FileMeta) error
 	GetByID(ctx context.Context, id uuid.UUID) (*FileMeta, error)
+	GetAllByIDs(ctx context.Context, ids []uuid.UUID) ([]FileMeta, error)
 	Delete(ctx context.Context, fileID uuid.UUID) error
 	GetByChecksum(ctx context.Context, checksum string) (*FileMeta, error)
 }
@@ -42,10 +44,30 @@ type service struct {
 	fs        afero.Fs
 	uuid      uuid.Service
 	clock     clock.Clock
+
+	// diskPath is the directory used to find the free space of the disk.
+	// It's empty if the files are not saved on a real disk.
+	diskPath string
 }
 
 func newService(storage storage, rootFS afero.Fs, tools tools.Tools, masterkey masterkey.Service) *service {
-	return &service{masterkey, storage, rootFS, tools.UUID(), tools.Clock()}
+	return &service{masterkey, storage, rootFS, tools.UUID(), tools.Clock(), ""}
+}
+
+// GetFreeSpace returns the number of bytes still available on the disk
+// storing the files. ErrUnknownSpace is returned if the storage doesn't
+// allow to find it.
+func (s *service) GetFreeSpace(_ context.Context) (uint64, error) {
+	if s.diskPath == "" {
+		return 0, ErrUnknownSpace
+	}
+
+	res, err := freeDiskSpace(s.diskPath)
+	if err != nil {
+		return 0, errs.Internal(err)
+	}
+
+	return res, nil
 }
 
 func (s *service) Upload(ctx context.Context, r io.Reader) (*FileMeta, error) {
@@ -180,6 +202,26 @@ func (s *service) GetMetadata(ctx context.Context, fileID uuid.UUID) (*FileMeta,
 	return res, err
 }
 
+// GetAllMetadata returns the metadata of all the given files indexed by
+// their id. The unknown ids are omitted from the result.
+func (s *service) GetAllMetadata(ctx context.Context, fileIDs []uuid.UUID) (map[uuid.UUID]FileMeta, error) {
+	res := make(map[uuid.UUID]FileMeta, len(fileIDs))
+	if len(fileIDs) == 0 {
+		return res, nil
+	}
+
+	metas, err := s.storage.GetAllByIDs(ctx, fileIDs)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllByIDs: %w", err))
+	}
+
+	for _, meta := range metas {
+		res[meta.id] = meta
+	}
+
+	return res, nil
+}
+
 func (s *service) Download(ctx context.Context, fileMeta *FileMeta) (io.ReadSeekCloser, error) {
 	idStr := string(fileMeta.id)
 	filePath := path.Join(idStr[:2], idStr)
@@ -56,6 +56,56 @@ func (_m *MockService) Download(ctx context.Context, file *FileMeta) (io.ReadSee
 	return r0, r1
 }
 
+// GetAllMetadata provides a mock function with given fields: ctx, fileIDs
+func (_m *MockService) GetAllMetadata(ctx context.Context, fileIDs []uuid.UUID) (map[uuid.UUID]FileMeta, error) {
+	ret := _m.Called(ctx, fileIDs)
+
+	var r0 map[uuid.UUID]FileMeta
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) (map[uuid.UUID]FileMeta, error)); ok {
+		return rf(ctx, fileIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) map[uuid.UUID]FileMeta); ok {
+		r0 = rf(ctx, fileIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID]FileMeta)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = rf(ctx, fileIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFreeSpace provides a mock function with given fields: ctx
+func (_m *MockService) GetFreeSpace(ctx context.Context) (uint64, error) {
+	ret := _m.Called(ctx)
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (uint64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) uint64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMetadata provides a mock function with given fields: ctx, fileID
 func (_m *MockService) GetMetadata(ctx context.Context, fileID uuid.UUID) (*FileMeta, error) {
 	ret := _m.Called(ctx, fileID)
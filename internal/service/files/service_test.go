@@ -18,6 +18,7 @@ import (
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
 	"github.com/theduckcompany/duckcloud/internal/tools/secret"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
 func TestFileService(t *testing.T) {
@@ -159,6 +160,73 @@ func TestFileService(t *testing.T) {
 		assert.Equal(t, fileMeta, res)
 	})
 
+	t.Run("GetAllMetadata success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewToolboxForTest(t)
+		fs := afero.NewMemMapFs()
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock, fs, tools, nil)
+
+		// Data
+		fileMeta := NewFakeFile(t).Build()
+		otherMeta := NewFakeFile(t).Build()
+
+		// Mocks
+		storageMock.On("GetAllByIDs", mock.Anything, []uuid.UUID{fileMeta.ID(), otherMeta.ID()}).
+			Return([]FileMeta{*fileMeta, *otherMeta}, nil).Once()
+
+		// Run
+		res, err := svc.GetAllMetadata(ctx, []uuid.UUID{fileMeta.ID(), otherMeta.ID()})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]FileMeta{
+			fileMeta.ID():  *fileMeta,
+			otherMeta.ID(): *otherMeta,
+		}, res)
+	})
+
+	t.Run("GetAllMetadata without ids", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewToolboxForTest(t)
+		fs := afero.NewMemMapFs()
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock, fs, tools, nil)
+
+		// Run
+		res, err := svc.GetAllMetadata(ctx, []uuid.UUID{})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("GetAllMetadata with a storage error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewToolboxForTest(t)
+		fs := afero.NewMemMapFs()
+		storageMock := newMockStorage(t)
+		svc := newService(storageMock, fs, tools, nil)
+
+		// Data
+		fileMeta := NewFakeFile(t).Build()
+
+		// Mocks
+		storageMock.On("GetAllByIDs", mock.Anything, []uuid.UUID{fileMeta.ID()}).
+			Return(nil, fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := svc.GetAllMetadata(ctx, []uuid.UUID{fileMeta.ID()})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
 	t.Run("GetMetadataByChecksum success", func(t *testing.T) {
 		t.Parallel()
 
@@ -214,6 +282,33 @@ func TestFileService(t *testing.T) {
 		assert.Nil(t, reader)
 		require.EqualError(t, err, "failed to open the file key: internal: failed to open the sealed key")
 	})
+
+	t.Run("GetFreeSpace success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewToolboxForTest(t)
+		storage := newMockStorage(t)
+		masterkeySvc := masterkey.NewMockService(t)
+		svc := newService(storage, afero.NewOsFs(), tools, masterkeySvc)
+		svc.diskPath = t.TempDir()
+
+		res, err := svc.GetFreeSpace(ctx)
+		require.NoError(t, err)
+		assert.Positive(t, res)
+	})
+
+	t.Run("GetFreeSpace without a disk", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewToolboxForTest(t)
+		storage := newMockStorage(t)
+		masterkeySvc := masterkey.NewMockService(t)
+		svc := newService(storage, afero.NewMemMapFs(), tools, masterkeySvc)
+
+		res, err := svc.GetFreeSpace(ctx)
+		require.ErrorIs(t, err, ErrUnknownSpace)
+		assert.Empty(t, res)
+	})
 }
 
 type closer struct {
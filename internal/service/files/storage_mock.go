@@ -28,6 +28,32 @@ func (_m *mockStorage) Delete(ctx context.Context, fileID uuid.UUID) error {
 	return r0
 }
 
+// GetAllByIDs provides a mock function with given fields: ctx, ids
+func (_m *mockStorage) GetAllByIDs(ctx context.Context, ids []uuid.UUID) ([]FileMeta, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 []FileMeta
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) ([]FileMeta, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) []FileMeta); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]FileMeta)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByChecksum provides a mock function with given fields: ctx, checksum
 func (_m *mockStorage) GetByChecksum(ctx context.Context, checksum string) (*FileMeta, error) {
 	ret := _m.Called(ctx, checksum)
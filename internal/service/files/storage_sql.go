@@ -46,6 +46,36 @@ func (s *sqlStorage) GetByID(ctx context.Context, id uuid.UUID) (*FileMeta, erro
 	return s.getByKeys(ctx, sq.Eq{"id": id})
 }
 
+func (s *sqlStorage) GetAllByIDs(ctx context.Context, ids []uuid.UUID) ([]FileMeta, error) {
+	rows, err := sq.
+		Select(allFields...).
+		From(tableName).
+		Where(sq.Eq{"id": ids}).
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	res := []FileMeta{}
+	for rows.Next() {
+		var meta FileMeta
+		var sqlUploadedAt sqlstorage.SQLTime
+
+		err := rows.Scan(&meta.id, &meta.size, &meta.mimetype, &meta.checksum, &meta.key, &sqlUploadedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		meta.uploadedAt = sqlUploadedAt.Time()
+		res = append(res, meta)
+	}
+
+	return res, rows.Err()
+}
+
 func (s *sqlStorage) GetByChecksum(ctx context.Context, checksum string) (*FileMeta, error) {
 	return s.getByKeys(ctx, sq.Eq{"checksum": checksum})
 }
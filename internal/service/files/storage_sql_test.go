@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
 func TestUserSqlStorage(t *testing.T) {
@@ -45,6 +46,15 @@ func TestUserSqlStorage(t *testing.T) {
 		require.ErrorIs(t, err, errNotFound)
 	})
 
+	t.Run("GetAllByIDs success", func(t *testing.T) {
+		// Run
+		res, err := store.GetAllByIDs(ctx, []uuid.UUID{file.ID(), "some-invalid-id"})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []FileMeta{*file}, res)
+	})
+
 	t.Run("Delete success", func(t *testing.T) {
 		// Run
 		err := store.Delete(ctx, file.ID())
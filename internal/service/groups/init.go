@@ -0,0 +1,31 @@
+package groups
+
+import (
+	"context"
+
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+//go:generate mockery --name Service
+type Service interface {
+	Create(ctx context.Context, cmd *CreateCmd) (*Group, error)
+	GetByID(ctx context.Context, groupID uuid.UUID) (*Group, error)
+	GetAll(ctx context.Context, user *users.User, cmd *sqlstorage.PaginateCmd) ([]Group, error)
+	GetAllUserGroups(ctx context.Context, userID uuid.UUID) ([]Group, error)
+	GetAllMembers(ctx context.Context, user *users.User, groupID uuid.UUID) ([]Member, error)
+	AddMember(ctx context.Context, cmd *AddMemberCmd) (*Member, error)
+	RemoveMember(ctx context.Context, cmd *RemoveMemberCmd) error
+	RemoveFromAllGroups(ctx context.Context, userID uuid.UUID) error
+	Delete(ctx context.Context, cmd *DeleteCmd) error
+	HardDelete(ctx context.Context, groupID uuid.UUID) error
+}
+
+func Init(tools tools.Tools, db sqlstorage.Querier, scheduler scheduler.Service) Service {
+	storage := newSqlStorage(db)
+
+	return newService(tools, storage, scheduler)
+}
@@ -0,0 +1,92 @@
+package groups
+
+import (
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+// Group is a set of users. A space can be given to a group instead of adding
+// its users one by one.
+type Group struct {
+	createdAt time.Time
+	id        uuid.UUID
+	name      string
+	createdBy uuid.UUID
+}
+
+func (g Group) ID() uuid.UUID        { return g.id }
+func (g Group) Name() string         { return g.name }
+func (g Group) CreatedAt() time.Time { return g.createdAt }
+func (g Group) CreatedBy() uuid.UUID { return g.createdBy }
+
+type Member struct {
+	createdAt time.Time
+	groupID   uuid.UUID
+	userID    uuid.UUID
+	createdBy uuid.UUID
+}
+
+func (m Member) GroupID() uuid.UUID   { return m.groupID }
+func (m Member) UserID() uuid.UUID    { return m.userID }
+func (m Member) CreatedAt() time.Time { return m.createdAt }
+func (m Member) CreatedBy() uuid.UUID { return m.createdBy }
+
+type CreateCmd struct {
+	User *users.User
+	Name string
+}
+
+// Validate the fields.
+func (t CreateCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.Name, v.Required, v.Length(1, 30)),
+	)
+}
+
+type AddMemberCmd struct {
+	User    *users.User
+	Member  *users.User
+	GroupID uuid.UUID
+}
+
+// Validate the fields.
+func (t AddMemberCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.Member, v.Required),
+		v.Field(&t.GroupID, v.Required, is.UUIDv4),
+	)
+}
+
+type RemoveMemberCmd struct {
+	User     *users.User
+	MemberID uuid.UUID
+	GroupID  uuid.UUID
+}
+
+// Validate the fields.
+func (t RemoveMemberCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.MemberID, v.Required, is.UUIDv4),
+		v.Field(&t.GroupID, v.Required, is.UUIDv4),
+	)
+}
+
+type DeleteCmd struct {
+	User    *users.User
+	GroupID uuid.UUID
+}
+
+// Validate the fields.
+func (t DeleteCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.GroupID, v.Required, is.UUIDv4),
+	)
+}
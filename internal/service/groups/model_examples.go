@@ -0,0 +1,24 @@
+package groups
+
+import (
+	"time"
+
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+var now time.Time = time.Now().UTC()
+
+var ExampleFamily = Group{
+	id:        uuid.UUID("0a5b8d5e-3f7c-4d8b-9a3e-6f1c2b4d7e90"),
+	name:      "Family",
+	createdAt: now,
+	createdBy: users.ExampleAlice.ID(),
+}
+
+var ExampleFamilyBobMember = Member{
+	groupID:   ExampleFamily.ID(),
+	userID:    users.ExampleBob.ID(),
+	createdAt: now,
+	createdBy: users.ExampleAlice.ID(),
+}
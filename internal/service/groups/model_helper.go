@@ -0,0 +1,124 @@
+package groups
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+type FakeGroupBuilder struct {
+	t       *testing.T
+	group   *Group
+	members []Member
+}
+
+func NewFakeGroup(t *testing.T) *FakeGroupBuilder {
+	t.Helper()
+
+	uuidProvider := uuid.NewProvider()
+
+	createdAt := gofakeit.DateRange(time.Now().Add(-time.Hour*1000), time.Now())
+
+	return &FakeGroupBuilder{
+		t: t,
+		group: &Group{
+			id:        uuidProvider.New(),
+			name:      gofakeit.Animal(),
+			createdAt: createdAt,
+			createdBy: uuidProvider.New(),
+		},
+	}
+}
+
+func (f *FakeGroupBuilder) WithName(name string) *FakeGroupBuilder {
+	f.group.name = name
+
+	return f
+}
+
+func (f *FakeGroupBuilder) CreatedBy(user *users.User) *FakeGroupBuilder {
+	f.group.createdBy = user.ID()
+
+	return f
+}
+
+func (f *FakeGroupBuilder) CreatedAt(at time.Time) *FakeGroupBuilder {
+	f.group.createdAt = at
+
+	return f
+}
+
+// WithMembers adds the given users to the group. The members are only saved
+// by [FakeGroupBuilder.BuildAndStore].
+func (f *FakeGroupBuilder) WithMembers(users ...users.User) *FakeGroupBuilder {
+	for _, elem := range users {
+		f.members = append(f.members, Member{
+			groupID:   f.group.id,
+			userID:    elem.ID(),
+			createdAt: f.group.createdAt,
+			createdBy: f.group.createdBy,
+		})
+	}
+
+	return f
+}
+
+func (f *FakeGroupBuilder) Build() *Group {
+	return f.group
+}
+
+func (f *FakeGroupBuilder) BuildAndStore(ctx context.Context, db sqlstorage.Querier) *Group {
+	f.t.Helper()
+
+	storage := newSqlStorage(db)
+
+	err := storage.Save(ctx, f.group)
+	require.NoError(f.t, err)
+
+	for _, member := range f.members {
+		err = storage.SaveMember(ctx, &member)
+		require.NoError(f.t, err)
+	}
+
+	return f.group
+}
+
+type FakeMemberBuilder struct {
+	t      *testing.T
+	member *Member
+}
+
+func NewFakeMember(t *testing.T, group *Group, user *users.User) *FakeMemberBuilder {
+	t.Helper()
+
+	return &FakeMemberBuilder{
+		t: t,
+		member: &Member{
+			groupID:   group.ID(),
+			userID:    user.ID(),
+			createdAt: gofakeit.DateRange(time.Now().Add(-time.Hour*1000), time.Now()),
+			createdBy: group.CreatedBy(),
+		},
+	}
+}
+
+func (f *FakeMemberBuilder) Build() *Member {
+	return f.member
+}
+
+func (f *FakeMemberBuilder) BuildAndStore(ctx context.Context, db sqlstorage.Querier) *Member {
+	f.t.Helper()
+
+	storage := newSqlStorage(db)
+
+	err := storage.SaveMember(ctx, f.member)
+	require.NoError(f.t, err)
+
+	return f.member
+}
@@ -0,0 +1,63 @@
+package groups
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func Test_Group_Getters(t *testing.T) {
+	assert.Equal(t, ExampleFamily.ID(), ExampleFamily.id)
+	assert.Equal(t, ExampleFamily.Name(), ExampleFamily.name)
+	assert.Equal(t, ExampleFamily.CreatedAt(), ExampleFamily.createdAt)
+	assert.Equal(t, ExampleFamily.CreatedBy(), ExampleFamily.createdBy)
+}
+
+func Test_Member_Getters(t *testing.T) {
+	assert.Equal(t, ExampleFamilyBobMember.GroupID(), ExampleFamilyBobMember.groupID)
+	assert.Equal(t, ExampleFamilyBobMember.UserID(), ExampleFamilyBobMember.userID)
+	assert.Equal(t, ExampleFamilyBobMember.CreatedAt(), ExampleFamilyBobMember.createdAt)
+	assert.Equal(t, ExampleFamilyBobMember.CreatedBy(), ExampleFamilyBobMember.createdBy)
+}
+
+func Test_CreateCmd_Validate(t *testing.T) {
+	require.NoError(t, CreateCmd{User: &users.ExampleAlice, Name: "Family"}.Validate())
+	require.EqualError(t, CreateCmd{User: &users.ExampleAlice, Name: ""}.Validate(), "Name: cannot be blank.")
+}
+
+func Test_AddMemberCmd_Validate(t *testing.T) {
+	require.NoError(t, AddMemberCmd{
+		User:    &users.ExampleAlice,
+		Member:  &users.ExampleBob,
+		GroupID: ExampleFamily.ID(),
+	}.Validate())
+
+	require.EqualError(t, AddMemberCmd{
+		User:    &users.ExampleAlice,
+		Member:  &users.ExampleBob,
+		GroupID: uuid.UUID("some-invalid-id"),
+	}.Validate(), "GroupID: must be a valid UUID v4.")
+}
+
+func Test_RemoveMemberCmd_Validate(t *testing.T) {
+	require.NoError(t, RemoveMemberCmd{
+		User:     &users.ExampleAlice,
+		MemberID: users.ExampleBob.ID(),
+		GroupID:  ExampleFamily.ID(),
+	}.Validate())
+}
+
+func Test_DeleteCmd_Validate(t *testing.T) {
+	require.NoError(t, DeleteCmd{
+		User:    &users.ExampleAlice,
+		GroupID: ExampleFamily.ID(),
+	}.Validate())
+
+	require.EqualError(t, DeleteCmd{
+		User:    &users.ExampleAlice,
+		GroupID: uuid.UUID("some-invalid-id"),
+	}.Validate(), "GroupID: must be a valid UUID v4.")
+}
@@ -0,0 +1,232 @@
+package groups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/clock"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+var (
+	ErrNotFound       = errors.New("group not found")
+	ErrAlreadyMember  = errors.New("already a member of the group")
+	ErrMemberNotFound = errors.New("member not found")
+)
+
+//go:generate mockery --name storage
+type storage interface {
+	Save(ctx context.Context, group *Group) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Group, error)
+	GetAll(ctx context.Context, cmd *sqlstorage.PaginateCmd) ([]Group, error)
+	GetAllUserGroups(ctx context.Context, userID uuid.UUID) ([]Group, error)
+
+	SaveMember(ctx context.Context, member *Member) error
+	GetMember(ctx context.Context, groupID, userID uuid.UUID) (*Member, error)
+	GetAllMembers(ctx context.Context, groupID uuid.UUID) ([]Member, error)
+	DeleteMember(ctx context.Context, groupID, userID uuid.UUID) error
+	DeleteAllUserMembers(ctx context.Context, userID uuid.UUID) error
+
+	HardDelete(ctx context.Context, id uuid.UUID) error
+}
+
+type service struct {
+	storage   storage
+	scheduler scheduler.Service
+	clock     clock.Clock
+	uuid      uuid.Service
+}
+
+func newService(tools tools.Tools, storage storage, scheduler scheduler.Service) *service {
+	return &service{storage, scheduler, tools.Clock(), tools.UUID()}
+}
+
+func (s *service) Create(ctx context.Context, cmd *CreateCmd) (*Group, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	if !cmd.User.IsAdmin() {
+		return nil, errs.ErrUnauthorized
+	}
+
+	group := Group{
+		id:        s.uuid.New(),
+		name:      cmd.Name,
+		createdAt: s.clock.Now(),
+		createdBy: cmd.User.ID(),
+	}
+
+	err = s.storage.Save(ctx, &group)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to Save the group: %w", err))
+	}
+
+	return &group, nil
+}
+
+func (s *service) GetByID(ctx context.Context, groupID uuid.UUID) (*Group, error) {
+	res, err := s.storage.GetByID(ctx, groupID)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
+	}
+
+	return res, nil
+}
+
+func (s *service) GetAll(ctx context.Context, user *users.User, cmd *sqlstorage.PaginateCmd) ([]Group, error) {
+	if !user.IsAdmin() {
+		return nil, errs.ErrUnauthorized
+	}
+
+	res, err := s.storage.GetAll(ctx, cmd)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAll: %w", err))
+	}
+
+	return res, nil
+}
+
+// GetAllUserGroups returns all the groups containing the given user.
+func (s *service) GetAllUserGroups(ctx context.Context, userID uuid.UUID) ([]Group, error) {
+	res, err := s.storage.GetAllUserGroups(ctx, userID)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllUserGroups: %w", err))
+	}
+
+	return res, nil
+}
+
+func (s *service) GetAllMembers(ctx context.Context, user *users.User, groupID uuid.UUID) ([]Member, error) {
+	if !user.IsAdmin() {
+		return nil, errs.ErrUnauthorized
+	}
+
+	res, err := s.storage.GetAllMembers(ctx, groupID)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllMembers: %w", err))
+	}
+
+	return res, nil
+}
+
+func (s *service) AddMember(ctx context.Context, cmd *AddMemberCmd) (*Member, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	if !cmd.User.IsAdmin() {
+		return nil, errs.ErrUnauthorized
+	}
+
+	_, err = s.GetByID(ctx, cmd.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.storage.GetMember(ctx, cmd.GroupID, cmd.Member.ID())
+	if err == nil {
+		return nil, errs.BadRequest(ErrAlreadyMember)
+	}
+
+	if !errors.Is(err, errNotFound) {
+		return nil, errs.Internal(fmt.Errorf("failed to GetMember: %w", err))
+	}
+
+	member := Member{
+		groupID:   cmd.GroupID,
+		userID:    cmd.Member.ID(),
+		createdAt: s.clock.Now(),
+		createdBy: cmd.User.ID(),
+	}
+
+	err = s.storage.SaveMember(ctx, &member)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to SaveMember: %w", err))
+	}
+
+	return &member, nil
+}
+
+func (s *service) RemoveMember(ctx context.Context, cmd *RemoveMemberCmd) error {
+	err := cmd.Validate()
+	if err != nil {
+		return errs.Validation(err)
+	}
+
+	if !cmd.User.IsAdmin() {
+		return errs.ErrUnauthorized
+	}
+
+	err = s.storage.DeleteMember(ctx, cmd.GroupID, cmd.MemberID)
+	if errors.Is(err, errNotFound) {
+		return errs.NotFound(ErrMemberNotFound)
+	}
+
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to DeleteMember: %w", err))
+	}
+
+	return nil
+}
+
+// RemoveFromAllGroups removes the user from all its groups. It is used during
+// the user deletion.
+func (s *service) RemoveFromAllGroups(ctx context.Context, userID uuid.UUID) error {
+	err := s.storage.DeleteAllUserMembers(ctx, userID)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to DeleteAllUserMembers: %w", err))
+	}
+
+	return nil
+}
+
+// Delete registers a task removing the group from all its spaces before
+// deleting it with all its members.
+func (s *service) Delete(ctx context.Context, cmd *DeleteCmd) error {
+	err := cmd.Validate()
+	if err != nil {
+		return errs.Validation(err)
+	}
+
+	if !cmd.User.IsAdmin() {
+		return errs.ErrUnauthorized
+	}
+
+	_, err = s.GetByID(ctx, cmd.GroupID)
+	if err != nil {
+		return err
+	}
+
+	err = s.scheduler.RegisterGroupDeleteTask(ctx, &scheduler.GroupDeleteArgs{
+		GroupID: cmd.GroupID,
+	})
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to RegisterGroupDeleteTask: %w", err))
+	}
+
+	return nil
+}
+
+// HardDelete removes the group and all its members. It is used by the
+// "group-delete" task once the group has been removed from all its spaces.
+func (s *service) HardDelete(ctx context.Context, groupID uuid.UUID) error {
+	err := s.storage.HardDelete(ctx, groupID)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to HardDelete: %w", err))
+	}
+
+	return nil
+}
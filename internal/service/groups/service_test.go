@@ -0,0 +1,678 @@
+package groups
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+)
+
+func Test_GroupService(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("Create success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		group := NewFakeGroup(t).
+			CreatedBy(user).
+			CreatedAt(now).
+			WithName("Family").
+			Build()
+
+		// Mocks
+		tools.UUIDMock.On("New").Return(group.ID()).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Save", mock.Anything, group).Return(nil).Once()
+
+		// Run
+		res, err := svc.Create(ctx, &CreateCmd{
+			User: user,
+			Name: "Family",
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.EqualValues(t, group, res)
+	})
+
+	t.Run("Create with a validation error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+
+		// Run
+		res, err := svc.Create(ctx, &CreateCmd{
+			User: user,
+			Name: "",
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
+	t.Run("Create with a non admin user", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+
+		// Run
+		res, err := svc.Create(ctx, &CreateCmd{
+			User: user,
+			Name: "Family",
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("Create with a Save error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		group := NewFakeGroup(t).
+			CreatedBy(user).
+			CreatedAt(now).
+			WithName("Family").
+			Build()
+
+		// Mocks
+		tools.UUIDMock.On("New").Return(group.ID()).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Save", mock.Anything, group).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := svc.Create(ctx, &CreateCmd{
+			User: user,
+			Name: "Family",
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("GetByID success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, group.ID()).Return(group, nil).Once()
+
+		// Run
+		res, err := svc.GetByID(ctx, group.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, group, res)
+	})
+
+	t.Run("GetByID not found", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, group.ID()).Return(nil, errNotFound).Once()
+
+		// Run
+		res, err := svc.GetByID(ctx, group.ID())
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("GetAll success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetAll", mock.Anything, &sqlstorage.PaginateCmd{Limit: 10}).Return([]Group{*group}, nil).Once()
+
+		// Run
+		res, err := svc.GetAll(ctx, user, &sqlstorage.PaginateCmd{Limit: 10})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []Group{*group}, res)
+	})
+
+	t.Run("GetAll with a non admin user", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+
+		// Run
+		res, err := svc.GetAll(ctx, user, nil)
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("GetAllUserGroups success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetAllUserGroups", mock.Anything, user.ID()).Return([]Group{*group}, nil).Once()
+
+		// Run
+		res, err := svc.GetAllUserGroups(ctx, user.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []Group{*group}, res)
+	})
+
+	t.Run("GetAllMembers success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		group := NewFakeGroup(t).Build()
+		member := NewFakeMember(t, group, user).Build()
+
+		// Mocks
+		storageMock.On("GetAllMembers", mock.Anything, group.ID()).Return([]Member{*member}, nil).Once()
+
+		// Run
+		res, err := svc.GetAllMembers(ctx, user, group.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []Member{*member}, res)
+	})
+
+	t.Run("AddMember success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someOtherUser := users.NewFakeUser(t).Build()
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, group.ID()).Return(group, nil).Once()
+		storageMock.On("GetMember", mock.Anything, group.ID(), someOtherUser.ID()).Return(nil, errNotFound).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("SaveMember", mock.Anything, &Member{
+			groupID:   group.ID(),
+			userID:    someOtherUser.ID(),
+			createdAt: now,
+			createdBy: user.ID(),
+		}).Return(nil).Once()
+
+		// Run
+		res, err := svc.AddMember(ctx, &AddMemberCmd{
+			User:    user,
+			Member:  someOtherUser,
+			GroupID: group.ID(),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, someOtherUser.ID(), res.UserID())
+		assert.Equal(t, group.ID(), res.GroupID())
+	})
+
+	t.Run("AddMember with a non admin user", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		group := NewFakeGroup(t).Build()
+
+		// Run
+		res, err := svc.AddMember(ctx, &AddMemberCmd{
+			User:    user,
+			Member:  user,
+			GroupID: group.ID(),
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("AddMember with a group not found", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someOtherUser := users.NewFakeUser(t).Build()
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, group.ID()).Return(nil, errNotFound).Once()
+
+		// Run
+		res, err := svc.AddMember(ctx, &AddMemberCmd{
+			User:    user,
+			Member:  someOtherUser,
+			GroupID: group.ID(),
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("AddMember with an already member", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someOtherUser := users.NewFakeUser(t).Build()
+		group := NewFakeGroup(t).Build()
+		member := NewFakeMember(t, group, someOtherUser).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, group.ID()).Return(group, nil).Once()
+		storageMock.On("GetMember", mock.Anything, group.ID(), someOtherUser.ID()).Return(member, nil).Once()
+
+		// Run
+		res, err := svc.AddMember(ctx, &AddMemberCmd{
+			User:    user,
+			Member:  someOtherUser,
+			GroupID: group.ID(),
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrAlreadyMember)
+	})
+
+	t.Run("RemoveMember success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someOtherUser := users.NewFakeUser(t).Build()
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("DeleteMember", mock.Anything, group.ID(), someOtherUser.ID()).Return(nil).Once()
+
+		// Run
+		err := svc.RemoveMember(ctx, &RemoveMemberCmd{
+			User:     user,
+			MemberID: someOtherUser.ID(),
+			GroupID:  group.ID(),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("RemoveMember with a member not found", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someOtherUser := users.NewFakeUser(t).Build()
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("DeleteMember", mock.Anything, group.ID(), someOtherUser.ID()).Return(errNotFound).Once()
+
+		// Run
+		err := svc.RemoveMember(ctx, &RemoveMemberCmd{
+			User:     user,
+			MemberID: someOtherUser.ID(),
+			GroupID:  group.ID(),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		require.ErrorIs(t, err, ErrMemberNotFound)
+	})
+
+	t.Run("RemoveMember with a non admin user", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		group := NewFakeGroup(t).Build()
+
+		// Run
+		err := svc.RemoveMember(ctx, &RemoveMemberCmd{
+			User:     user,
+			MemberID: user.ID(),
+			GroupID:  group.ID(),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("RemoveFromAllGroups success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+
+		// Mocks
+		storageMock.On("DeleteAllUserMembers", mock.Anything, user.ID()).Return(nil).Once()
+
+		// Run
+		err := svc.RemoveFromAllGroups(ctx, user.ID())
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("RemoveFromAllGroups with a DeleteAllUserMembers error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+
+		// Mocks
+		storageMock.On("DeleteAllUserMembers", mock.Anything, user.ID()).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		err := svc.RemoveFromAllGroups(ctx, user.ID())
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("Delete success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, group.ID()).Return(group, nil).Once()
+		schedulerMock.On("RegisterGroupDeleteTask", mock.Anything, &scheduler.GroupDeleteArgs{
+			GroupID: group.ID(),
+		}).Return(nil).Once()
+
+		// Run
+		err := svc.Delete(ctx, &DeleteCmd{
+			User:    user,
+			GroupID: group.ID(),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("Delete with a validation error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+
+		// Run
+		err := svc.Delete(ctx, &DeleteCmd{
+			User:    user,
+			GroupID: "some-invalid-id",
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
+	t.Run("Delete with a non admin user", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		group := NewFakeGroup(t).Build()
+
+		// Run
+		err := svc.Delete(ctx, &DeleteCmd{
+			User:    user,
+			GroupID: group.ID(),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("Delete with a group not found", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, group.ID()).Return(nil, errNotFound).Once()
+
+		// Run
+		err := svc.Delete(ctx, &DeleteCmd{
+			User:    user,
+			GroupID: group.ID(),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Delete with a RegisterGroupDeleteTask error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, group.ID()).Return(group, nil).Once()
+		schedulerMock.On("RegisterGroupDeleteTask", mock.Anything, &scheduler.GroupDeleteArgs{
+			GroupID: group.ID(),
+		}).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		err := svc.Delete(ctx, &DeleteCmd{
+			User:    user,
+			GroupID: group.ID(),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("HardDelete success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("HardDelete", mock.Anything, group.ID()).Return(nil).Once()
+
+		// Run
+		err := svc.HardDelete(ctx, group.ID())
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("HardDelete with a storage error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock)
+
+		// Data
+		group := NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("HardDelete", mock.Anything, group.ID()).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		err := svc.HardDelete(ctx, group.ID())
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+}
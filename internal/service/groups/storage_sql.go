@@ -0,0 +1,143 @@
+package groups
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const tableName = "groups"
+
+var errNotFound = errors.New("not found")
+
+var allFields = []string{"id", "name", "created_at", "created_by"}
+
+type sqlStorage struct {
+	db sqlstorage.Querier
+}
+
+func newSqlStorage(db sqlstorage.Querier) *sqlStorage {
+	return &sqlStorage{db}
+}
+
+func (s *sqlStorage) Save(ctx context.Context, group *Group) error {
+	_, err := sq.
+		Insert(tableName).
+		Columns(allFields...).
+		Values(group.id,
+			group.name,
+			ptr.To(sqlstorage.SQLTime(group.createdAt)),
+			group.createdBy).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) GetByID(ctx context.Context, id uuid.UUID) (*Group, error) {
+	var res Group
+	var sqlCreatedAt sqlstorage.SQLTime
+
+	err := sq.
+		Select(allFields...).
+		From(tableName).
+		Where(sq.Eq{"id": id}).
+		RunWith(s.db).
+		ScanContext(ctx, &res.id, &res.name, &sqlCreatedAt, &res.createdBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	res.createdAt = sqlCreatedAt.Time()
+
+	return &res, nil
+}
+
+// HardDelete removes the group and all its members.
+func (s *sqlStorage) HardDelete(ctx context.Context, id uuid.UUID) error {
+	return sqlstorage.RunInTx(ctx, s.db, func(tx sqlstorage.Querier) error {
+		_, err := sq.
+			Delete(membersTableName).
+			Where(sq.Eq{"group_id": id}).
+			RunWith(tx).
+			ExecContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to delete the members: %w", err)
+		}
+
+		_, err = sq.
+			Delete(tableName).
+			Where(sq.Eq{"id": id}).
+			RunWith(tx).
+			ExecContext(ctx)
+		if err != nil {
+			return fmt.Errorf("sql error: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *sqlStorage) GetAll(ctx context.Context, cmd *sqlstorage.PaginateCmd) ([]Group, error) {
+	return s.getAllbyKeys(ctx, cmd)
+}
+
+func (s *sqlStorage) GetAllUserGroups(ctx context.Context, userID uuid.UUID) ([]Group, error) {
+	return s.getAllbyKeys(ctx, nil, sq.Expr("id IN (SELECT group_id FROM "+membersTableName+" WHERE user_id = ?)", userID))
+}
+
+func (s *sqlStorage) getAllbyKeys(ctx context.Context, cmd *sqlstorage.PaginateCmd, wheres ...any) ([]Group, error) {
+	query := sq.
+		Select(allFields...).
+		From(tableName)
+
+	for _, where := range wheres {
+		query = query.Where(where)
+	}
+
+	query = sqlstorage.PaginateSelection(query, cmd)
+
+	rows, err := query.
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	groups := []Group{}
+
+	for rows.Next() {
+		var res Group
+		var sqlCreatedAt sqlstorage.SQLTime
+
+		err := rows.Scan(&res.id, &res.name, &sqlCreatedAt, &res.createdBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		res.createdAt = sqlCreatedAt.Time()
+
+		groups = append(groups, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return groups, nil
+}
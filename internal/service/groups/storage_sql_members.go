@@ -0,0 +1,132 @@
+package groups
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const membersTableName = "group_members"
+
+var allMemberFields = []string{"group_id", "user_id", "created_at", "created_by"}
+
+func (s *sqlStorage) SaveMember(ctx context.Context, member *Member) error {
+	_, err := sq.
+		Insert(membersTableName).
+		Columns(allMemberFields...).
+		Values(member.groupID,
+			member.userID,
+			ptr.To(sqlstorage.SQLTime(member.createdAt)),
+			member.createdBy).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) GetMember(ctx context.Context, groupID, userID uuid.UUID) (*Member, error) {
+	var res Member
+	var sqlCreatedAt sqlstorage.SQLTime
+
+	err := sq.
+		Select(allMemberFields...).
+		From(membersTableName).
+		Where(sq.Eq{"group_id": groupID, "user_id": userID}).
+		RunWith(s.db).
+		ScanContext(ctx, &res.groupID, &res.userID, &sqlCreatedAt, &res.createdBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	res.createdAt = sqlCreatedAt.Time()
+
+	return &res, nil
+}
+
+// GetAllMembers returns all the members of the given group, the oldest first.
+func (s *sqlStorage) GetAllMembers(ctx context.Context, groupID uuid.UUID) ([]Member, error) {
+	rows, err := sq.
+		Select(allMemberFields...).
+		From(membersTableName).
+		Where(sq.Eq{"group_id": groupID}).
+		OrderBy("created_at", "user_id").
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	members := []Member{}
+
+	for rows.Next() {
+		var res Member
+		var sqlCreatedAt sqlstorage.SQLTime
+
+		err := rows.Scan(&res.groupID, &res.userID, &sqlCreatedAt, &res.createdBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		res.createdAt = sqlCreatedAt.Time()
+
+		members = append(members, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return members, nil
+}
+
+// DeleteMember removes the user from the group. It returns errNotFound if the
+// user is not a member of the group.
+func (s *sqlStorage) DeleteMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	res, err := sq.
+		Delete(membersTableName).
+		Where(sq.Eq{"group_id": groupID, "user_id": userID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	nb, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get the affected rows: %w", err)
+	}
+
+	if nb == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) DeleteAllUserMembers(ctx context.Context, userID uuid.UUID) error {
+	_, err := sq.
+		Delete(membersTableName).
+		Where(sq.Eq{"user_id": userID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
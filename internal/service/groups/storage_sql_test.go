@@ -0,0 +1,165 @@
+package groups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+)
+
+func TestGroupSqlstore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db := sqlstorage.NewTestStorage(t)
+	store := newSqlStorage(db)
+
+	user := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	group := NewFakeGroup(t).Build()
+	group2 := NewFakeGroup(t).Build()
+	member := NewFakeMember(t, group, user).Build()
+
+	t.Run("Save success", func(t *testing.T) {
+		// Run
+		err := store.Save(ctx, group)
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("GetByID success", func(t *testing.T) {
+		// Run
+		res, err := store.GetByID(ctx, group.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.EqualValues(t, group, res)
+	})
+
+	t.Run("GetByID not found", func(t *testing.T) {
+		// Run
+		res, err := store.GetByID(ctx, "some-invalid-uuid")
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("Save success 2", func(t *testing.T) {
+		// Run
+		err := store.Save(ctx, group2)
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("GetAll success", func(t *testing.T) {
+		// Run
+		res, err := store.GetAll(ctx, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []Group{*group, *group2}, res)
+	})
+
+	t.Run("SaveMember success", func(t *testing.T) {
+		// Run
+		err := store.SaveMember(ctx, member)
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("GetMember success", func(t *testing.T) {
+		// Run
+		res, err := store.GetMember(ctx, group.ID(), user.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, member, res)
+	})
+
+	t.Run("GetMember not found", func(t *testing.T) {
+		// Run
+		res, err := store.GetMember(ctx, group2.ID(), user.ID())
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("GetAllMembers success", func(t *testing.T) {
+		// Run
+		res, err := store.GetAllMembers(ctx, group.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []Member{*member}, res)
+	})
+
+	t.Run("GetAllUserGroups success", func(t *testing.T) {
+		// Run
+		res, err := store.GetAllUserGroups(ctx, user.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []Group{*group}, res)
+	})
+
+	t.Run("DeleteMember success", func(t *testing.T) {
+		// Run
+		err := store.DeleteMember(ctx, group.ID(), user.ID())
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetAllMembers(ctx, group.ID())
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("DeleteMember not found", func(t *testing.T) {
+		// Run
+		err := store.DeleteMember(ctx, group.ID(), user.ID())
+
+		// Asserts
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("DeleteAllUserMembers success", func(t *testing.T) {
+		// Setup
+		err := store.SaveMember(ctx, member)
+		require.NoError(t, err)
+
+		// Run
+		err = store.DeleteAllUserMembers(ctx, user.ID())
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetAllUserGroups(ctx, user.ID())
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("HardDelete success", func(t *testing.T) {
+		// Setup
+		err := store.SaveMember(ctx, member)
+		require.NoError(t, err)
+
+		// Run
+		err = store.HardDelete(ctx, group.ID())
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetByID(ctx, group.ID())
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+
+		members, err := store.GetAllMembers(ctx, group.ID())
+		require.NoError(t, err)
+		assert.Empty(t, members)
+	})
+}
@@ -0,0 +1,32 @@
+package shares
+
+import (
+	"context"
+
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+//go:generate mockery --name Service
+type Service interface {
+	Create(ctx context.Context, cmd *CreateCmd) (*Share, error)
+	Open(ctx context.Context, cmd *OpenCmd) (*Share, error)
+	Unlock(ctx context.Context, cmd *OpenCmd) (secret.Text, error)
+	RegisterDownload(ctx context.Context, share *Share) error
+	NewDownloadKey(share *Share, inodeID uuid.UUID) secret.Text
+	IsValidDownloadKey(share *Share, inodeID uuid.UUID, key secret.Text) bool
+	RegisterUpload(ctx context.Context, share *Share, size uint64) error
+	GetAllForINode(ctx context.Context, inodeID uuid.UUID, paginateCmd *sqlstorage.PaginateCmd) ([]Share, error)
+	Delete(ctx context.Context, cmd *DeleteCmd) error
+	MoveToSpace(ctx context.Context, inodeID, spaceID uuid.UUID) error
+	DeleteAll(ctx context.Context, userID uuid.UUID) error
+}
+
+func Init(db sqlstorage.Querier, spaces spaces.Service, tools tools.Tools) Service {
+	storage := newSqlStorage(db)
+
+	return newService(storage, spaces, tools)
+}
@@ -0,0 +1,166 @@
+package shares
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const (
+	PasswordMinLength = 4
+	PasswordMaxLength = 200
+
+	// DownloadKeyTTL is the time during which a download key allows to resume
+	// the download of a file without counting it again.
+	DownloadKeyTTL = 6 * time.Hour
+)
+
+type Kind string
+
+const (
+	// DownloadKind gives a read only access to a file or a folder.
+	DownloadKind Kind = "download"
+	// UploadKind allows to upload files into a folder without being able
+	// to list or download its content.
+	UploadKind Kind = "upload"
+)
+
+// Share is a public link giving access to a file or a folder without any
+// account.
+type Share struct {
+	createdAt     time.Time
+	expiresAt     *time.Time
+	id            uuid.UUID
+	token         secret.Text
+	kind          Kind
+	spaceID       uuid.UUID
+	inodeID       uuid.UUID
+	password      secret.Text
+	createdBy     uuid.UUID
+	maxDownloads  int
+	downloads     int
+	maxUploads    int
+	uploads       int
+	maxUploadSize uint64
+	uploadedSize  uint64
+}
+
+func (s Share) ID() uuid.UUID         { return s.id }
+func (s Share) Token() secret.Text    { return s.token }
+func (s Share) Kind() Kind            { return s.kind }
+func (s Share) SpaceID() uuid.UUID    { return s.spaceID }
+func (s Share) INodeID() uuid.UUID    { return s.inodeID }
+func (s Share) ExpiresAt() *time.Time { return s.expiresAt }
+func (s Share) MaxDownloads() int     { return s.maxDownloads }
+func (s Share) Downloads() int        { return s.downloads }
+func (s Share) MaxUploads() int       { return s.maxUploads }
+func (s Share) Uploads() int          { return s.uploads }
+func (s Share) MaxUploadSize() uint64 { return s.maxUploadSize }
+func (s Share) UploadedSize() uint64  { return s.uploadedSize }
+func (s Share) CreatedAt() time.Time  { return s.createdAt }
+func (s Share) CreatedBy() uuid.UUID  { return s.createdBy }
+func (s Share) HasPassword() bool     { return s.password.Raw() != "" }
+
+// unlockKey returns an HMAC of the share ID keyed by the hashed password. It
+// proves that the password was given without having to keep it.
+func (s Share) unlockKey() secret.Text {
+	mac := hmac.New(sha256.New, []byte(s.password.Raw()))
+	mac.Write([]byte(s.id))
+
+	return secret.NewText(base64.RawURLEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// downloadKey returns a key proving that the download of the inode was counted
+// at issuedAt. It's made of the issue date and of an HMAC of the inode ID and
+// of this date keyed by the share ID, which is never given to the visitors.
+func (s Share) downloadKey(inodeID uuid.UUID, issuedAt time.Time) secret.Text {
+	rawIssuedAt := strconv.FormatInt(issuedAt.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(s.id))
+	mac.Write([]byte(inodeID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(rawIssuedAt))
+
+	return secret.NewText(rawIssuedAt + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// IsExpired returns true if the share have an expiration date and this date
+// is passed.
+func (s Share) IsExpired(now time.Time) bool {
+	return s.expiresAt != nil && !now.Before(*s.expiresAt)
+}
+
+// IsDownloadLimitReached returns true if the share have a download limit
+// and it has been reached.
+func (s Share) IsDownloadLimitReached() bool {
+	return s.maxDownloads > 0 && s.downloads >= s.maxDownloads
+}
+
+// IsUploadLimitReached returns true if the share have a file count or a size
+// limit and one of them has been reached.
+func (s Share) IsUploadLimitReached() bool {
+	return (s.maxUploads > 0 && s.uploads >= s.maxUploads) ||
+		(s.maxUploadSize > 0 && s.uploadedSize >= s.maxUploadSize)
+}
+
+type CreateCmd struct {
+	Space     *spaces.Space
+	INode     *dfs.INode
+	CreatedBy *users.User
+	ExpiresAt *time.Time
+	Kind      Kind
+	Password  secret.Text
+	// MaxDownloads is only used by the DownloadKind shares.
+	MaxDownloads int
+	// MaxUploads and MaxUploadSize are only used by the UploadKind shares.
+	MaxUploads    int
+	MaxUploadSize uint64
+}
+
+func (t CreateCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.Space, v.Required),
+		v.Field(&t.INode, v.Required),
+		v.Field(&t.CreatedBy, v.Required),
+		v.Field(&t.Kind, v.Required, v.In(DownloadKind, UploadKind)),
+		v.Field(&t.Password, v.Length(PasswordMinLength, PasswordMaxLength)),
+		v.Field(&t.MaxDownloads, v.Min(0)),
+		v.Field(&t.MaxUploads, v.Min(0)),
+	)
+}
+
+type OpenCmd struct {
+	Token    secret.Text
+	Password secret.Text
+	// UnlockKey is the key returned by Unlock. It replaces the password for
+	// the protected shares.
+	UnlockKey secret.Text
+}
+
+func (t OpenCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.Token, v.Required),
+	)
+}
+
+type DeleteCmd struct {
+	User    *users.User
+	ShareID uuid.UUID
+}
+
+func (t DeleteCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.ShareID, v.Required, is.UUIDv4),
+	)
+}
@@ -0,0 +1,58 @@
+package shares
+
+import (
+	"time"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+var now time.Time = time.Now().UTC()
+
+var ExampleAliceFileShare = Share{
+	id:           uuid.UUID("8d6a8e4c-73a6-4bf0-8f5c-53b0e6f1a9d3"),
+	token:        secret.NewText("0e4a7e8b-2b0f-4d6e-9c3a-1f5d2c7b8e90"),
+	kind:         DownloadKind,
+	spaceID:      spaces.ExampleAlicePersonalSpace.ID(),
+	inodeID:      dfs.ExampleAliceFile.ID(),
+	password:     secret.Empty,
+	maxDownloads: 0,
+	downloads:    0,
+	expiresAt:    nil,
+	createdAt:    now,
+	createdBy:    users.ExampleAlice.ID(),
+}
+
+var ExampleAliceProtectedDirShare = Share{
+	id:           uuid.UUID("5c2f7a39-1e84-4b6d-a0c2-9d8e3f4b5a61"),
+	token:        secret.NewText("b7c1d2e3-4f5a-4b6c-8d7e-9f0a1b2c3d4e"),
+	kind:         DownloadKind,
+	spaceID:      spaces.ExampleAlicePersonalSpace.ID(),
+	inodeID:      dfs.ExampleAliceDir.ID(),
+	password:     secret.NewText("some-hashed-password"),
+	maxDownloads: 10,
+	downloads:    3,
+	expiresAt:    ptr.To(now.Add(24 * time.Hour)),
+	createdAt:    now,
+	createdBy:    users.ExampleAlice.ID(),
+}
+
+var ExampleAliceUploadShare = Share{
+	id:            uuid.UUID("2a4e6c8d-0f1b-4d3a-9c5e-7b9d1f3a5c7e"),
+	token:         secret.NewText("6f8a0c2e-4b6d-4f8a-8c0e-2d4f6a8c0e2b"),
+	kind:          UploadKind,
+	spaceID:       spaces.ExampleAlicePersonalSpace.ID(),
+	inodeID:       dfs.ExampleAliceDir.ID(),
+	password:      secret.Empty,
+	maxUploads:    20,
+	uploads:       2,
+	maxUploadSize: 100 * 1024 * 1024, // 100MB
+	uploadedSize:  2048,
+	expiresAt:     ptr.To(now.Add(7 * 24 * time.Hour)),
+	createdAt:     now,
+	createdBy:     users.ExampleAlice.ID(),
+}
@@ -0,0 +1,129 @@
+package shares
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+type FakeShareBuilder struct {
+	t     *testing.T
+	share *Share
+}
+
+func NewFakeShare(t *testing.T) *FakeShareBuilder {
+	t.Helper()
+
+	uuidProvider := uuid.NewProvider()
+
+	createdAt := gofakeit.DateRange(time.Now().Add(-time.Hour*1000), time.Now())
+
+	return &FakeShareBuilder{
+		t: t,
+		share: &Share{
+			id:           uuidProvider.New(),
+			token:        secret.NewText(string(uuidProvider.New())),
+			kind:         DownloadKind,
+			spaceID:      uuidProvider.New(),
+			inodeID:      uuidProvider.New(),
+			password:     secret.Empty,
+			maxDownloads: 0,
+			downloads:    0,
+			expiresAt:    nil,
+			createdAt:    createdAt,
+			createdBy:    uuidProvider.New(),
+		},
+	}
+}
+
+func (f *FakeShareBuilder) WithSpace(space *spaces.Space) *FakeShareBuilder {
+	f.share.spaceID = space.ID()
+
+	return f
+}
+
+func (f *FakeShareBuilder) WithINode(inode *dfs.INode) *FakeShareBuilder {
+	f.share.inodeID = inode.ID()
+	f.share.spaceID = inode.SpaceID()
+
+	return f
+}
+
+// WithHashedPassword sets the password hash. The password is not hashed by
+// the builder.
+func (f *FakeShareBuilder) WithHashedPassword(hash string) *FakeShareBuilder {
+	f.share.password = secret.NewText(hash)
+
+	return f
+}
+
+func (f *FakeShareBuilder) WithMaxDownloads(max int) *FakeShareBuilder {
+	f.share.maxDownloads = max
+
+	return f
+}
+
+func (f *FakeShareBuilder) WithDownloads(nb int) *FakeShareBuilder {
+	f.share.downloads = nb
+
+	return f
+}
+
+// WithUploadLimits turns the share into an UploadKind share with the given
+// limits.
+func (f *FakeShareBuilder) WithUploadLimits(maxUploads int, maxUploadSize uint64) *FakeShareBuilder {
+	f.share.kind = UploadKind
+	f.share.maxUploads = maxUploads
+	f.share.maxUploadSize = maxUploadSize
+
+	return f
+}
+
+func (f *FakeShareBuilder) WithUploads(nb int, size uint64) *FakeShareBuilder {
+	f.share.uploads = nb
+	f.share.uploadedSize = size
+
+	return f
+}
+
+func (f *FakeShareBuilder) ExpiresAt(at time.Time) *FakeShareBuilder {
+	f.share.expiresAt = &at
+
+	return f
+}
+
+func (f *FakeShareBuilder) CreatedAt(at time.Time) *FakeShareBuilder {
+	f.share.createdAt = at
+
+	return f
+}
+
+func (f *FakeShareBuilder) CreatedBy(user *users.User) *FakeShareBuilder {
+	f.share.createdBy = user.ID()
+
+	return f
+}
+
+func (f *FakeShareBuilder) Build() *Share {
+	return f.share
+}
+
+func (f *FakeShareBuilder) BuildAndStore(ctx context.Context, db sqlstorage.Querier) *Share {
+	f.t.Helper()
+
+	storage := newSqlStorage(db)
+
+	err := storage.Save(ctx, f.share)
+	require.NoError(f.t, err)
+
+	return f.share
+}
@@ -0,0 +1,130 @@
+package shares
+
+import (
+	"testing"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+)
+
+func TestShare_Getters(t *testing.T) {
+	share := ExampleAliceProtectedDirShare
+
+	assert.Equal(t, share.id, share.ID())
+	assert.Equal(t, share.token, share.Token())
+	assert.Equal(t, share.kind, share.Kind())
+	assert.Equal(t, share.spaceID, share.SpaceID())
+	assert.Equal(t, share.inodeID, share.INodeID())
+	assert.Equal(t, share.expiresAt, share.ExpiresAt())
+	assert.Equal(t, share.maxDownloads, share.MaxDownloads())
+	assert.Equal(t, share.downloads, share.Downloads())
+	assert.Equal(t, ExampleAliceUploadShare.maxUploads, ExampleAliceUploadShare.MaxUploads())
+	assert.Equal(t, ExampleAliceUploadShare.uploads, ExampleAliceUploadShare.Uploads())
+	assert.Equal(t, ExampleAliceUploadShare.maxUploadSize, ExampleAliceUploadShare.MaxUploadSize())
+	assert.Equal(t, ExampleAliceUploadShare.uploadedSize, ExampleAliceUploadShare.UploadedSize())
+	assert.Equal(t, share.createdAt, share.CreatedAt())
+	assert.Equal(t, share.createdBy, share.CreatedBy())
+	assert.True(t, share.HasPassword())
+	assert.False(t, ExampleAliceFileShare.HasPassword())
+}
+
+func TestShare_IsExpired(t *testing.T) {
+	share := ExampleAliceProtectedDirShare
+
+	assert.False(t, share.IsExpired(share.ExpiresAt().Add(-time.Second)))
+	assert.True(t, share.IsExpired(*share.ExpiresAt()))
+	assert.True(t, share.IsExpired(share.ExpiresAt().Add(time.Second)))
+	assert.False(t, ExampleAliceFileShare.IsExpired(time.Now().Add(1000*time.Hour)))
+}
+
+func TestShare_IsDownloadLimitReached(t *testing.T) {
+	assert.False(t, ExampleAliceFileShare.IsDownloadLimitReached())
+	assert.False(t, NewFakeShare(t).WithMaxDownloads(2).WithDownloads(1).Build().IsDownloadLimitReached())
+	assert.True(t, NewFakeShare(t).WithMaxDownloads(2).WithDownloads(2).Build().IsDownloadLimitReached())
+}
+
+func TestShare_IsUploadLimitReached(t *testing.T) {
+	assert.False(t, ExampleAliceUploadShare.IsUploadLimitReached())
+	assert.False(t, NewFakeShare(t).WithUploadLimits(0, 0).WithUploads(100, 1024).Build().IsUploadLimitReached())
+	assert.True(t, NewFakeShare(t).WithUploadLimits(2, 0).WithUploads(2, 10).Build().IsUploadLimitReached())
+	assert.True(t, NewFakeShare(t).WithUploadLimits(0, 1024).WithUploads(1, 1024).Build().IsUploadLimitReached())
+}
+
+func Test_CreateCmd_is_validatable(t *testing.T) {
+	assert.Implements(t, (*validation.Validatable)(nil), new(CreateCmd))
+}
+
+func Test_CreateCmd_Validate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		err := CreateCmd{
+			Space:        &spaces.ExampleAlicePersonalSpace,
+			INode:        &dfs.ExampleAliceFile,
+			CreatedBy:    &users.ExampleAlice,
+			Kind:         DownloadKind,
+			ExpiresAt:    nil,
+			Password:     secret.NewText("some-password"),
+			MaxDownloads: 3,
+		}.Validate()
+
+		require.NoError(t, err)
+	})
+
+	t.Run("with a password too short", func(t *testing.T) {
+		err := CreateCmd{
+			Space:     &spaces.ExampleAlicePersonalSpace,
+			INode:     &dfs.ExampleAliceFile,
+			CreatedBy: &users.ExampleAlice,
+			Kind:      DownloadKind,
+			Password:  secret.NewText("abc"),
+		}.Validate()
+
+		require.EqualError(t, err, "Password: the length must be between 4 and 200.")
+	})
+
+	t.Run("with a negative download limit", func(t *testing.T) {
+		err := CreateCmd{
+			Space:        &spaces.ExampleAlicePersonalSpace,
+			INode:        &dfs.ExampleAliceFile,
+			CreatedBy:    &users.ExampleAlice,
+			Kind:         DownloadKind,
+			MaxDownloads: -1,
+		}.Validate()
+
+		require.EqualError(t, err, "MaxDownloads: must be no less than 0.")
+	})
+}
+
+func Test_CreateCmd_Validate_with_an_invalid_kind(t *testing.T) {
+	err := CreateCmd{
+		Space:     &spaces.ExampleAlicePersonalSpace,
+		INode:     &dfs.ExampleAliceDir,
+		CreatedBy: &users.ExampleAlice,
+		Kind:      Kind("invalid"),
+	}.Validate()
+
+	require.EqualError(t, err, "Kind: must be a valid value.")
+}
+
+func Test_OpenCmd_Validate_success(t *testing.T) {
+	err := OpenCmd{
+		Token:    ExampleAliceFileShare.Token(),
+		Password: secret.Empty,
+	}.Validate()
+
+	require.NoError(t, err)
+}
+
+func Test_DeleteCmd_Validate_success(t *testing.T) {
+	err := DeleteCmd{
+		User:    &users.ExampleAlice,
+		ShareID: ExampleAliceFileShare.ID(),
+	}.Validate()
+
+	require.NoError(t, err)
+}
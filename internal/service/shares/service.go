@@ -0,0 +1,330 @@
+package shares
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/clock"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/password"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+var (
+	ErrExpired              = errors.New("share expired")
+	ErrDownloadLimitReached = errors.New("download limit reached")
+	ErrUploadLimitReached   = errors.New("upload limit reached")
+	ErrNotADirectory        = errors.New("not a directory")
+	ErrInvalidPassword      = errors.New("invalid password")
+	ErrInvalidSpaceID       = errors.New("invalid spaceID")
+	ErrINodeNotInSpace      = errors.New("the inode is not inside the space")
+	ErrReadOnlyRole         = errors.New("the editor or manager role is required")
+)
+
+//go:generate mockery --name storage
+type storage interface {
+	Save(ctx context.Context, share *Share) error
+	GetByID(ctx context.Context, shareID uuid.UUID) (*Share, error)
+	GetByToken(ctx context.Context, token secret.Text) (*Share, error)
+	GetAllForINode(ctx context.Context, inodeID uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]Share, error)
+	GetAllCreatedBy(ctx context.Context, userID uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]Share, error)
+	IncrementDownloads(ctx context.Context, shareID uuid.UUID) (bool, error)
+	IncrementUploads(ctx context.Context, shareID uuid.UUID, size uint64) (bool, error)
+	MoveAllForINode(ctx context.Context, inodeID, spaceID uuid.UUID) error
+	RemoveByID(ctx context.Context, shareID uuid.UUID) error
+}
+
+type service struct {
+	storage  storage
+	spaces   spaces.Service
+	uuid     uuid.Service
+	clock    clock.Clock
+	password password.Password
+}
+
+func newService(storage storage, spaces spaces.Service, tools tools.Tools) *service {
+	return &service{storage, spaces, tools.UUID(), tools.Clock(), tools.Password()}
+}
+
+func (s *service) Create(ctx context.Context, cmd *CreateCmd) (*Share, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	role, err := s.spaces.GetUserRole(ctx, cmd.CreatedBy.ID(), cmd.Space.ID())
+	if errors.Is(err, errs.ErrNotFound) || errors.Is(err, errs.ErrUnauthorized) {
+		return nil, errs.BadRequest(ErrInvalidSpaceID, "invalid space")
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetUserRole: %w", err))
+	}
+
+	if !role.CanWrite() {
+		return nil, errs.Unauthorized(ErrReadOnlyRole, "only the editors and the managers can share the space content")
+	}
+
+	if cmd.INode.SpaceID() != cmd.Space.ID() {
+		return nil, errs.BadRequest(ErrINodeNotInSpace, "invalid file")
+	}
+
+	if cmd.Kind == UploadKind && !cmd.INode.IsDir() {
+		return nil, errs.BadRequest(ErrNotADirectory, "an upload link requires a folder")
+	}
+
+	hashedPassword := secret.Empty
+	if cmd.Password.Raw() != "" {
+		hashedPassword, err = s.password.Encrypt(ctx, cmd.Password)
+		if err != nil {
+			return nil, errs.Internal(fmt.Errorf("failed to hash the password: %w", err))
+		}
+	}
+
+	share := Share{
+		id:            s.uuid.New(),
+		token:         secret.NewText(string(s.uuid.New())),
+		kind:          cmd.Kind,
+		spaceID:       cmd.Space.ID(),
+		inodeID:       cmd.INode.ID(),
+		password:      hashedPassword,
+		maxDownloads:  0,
+		downloads:     0,
+		maxUploads:    0,
+		uploads:       0,
+		maxUploadSize: 0,
+		uploadedSize:  0,
+		expiresAt:     cmd.ExpiresAt,
+		createdAt:     s.clock.Now(),
+		createdBy:     cmd.CreatedBy.ID(),
+	}
+
+	switch cmd.Kind {
+	case DownloadKind:
+		share.maxDownloads = cmd.MaxDownloads
+	case UploadKind:
+		share.maxUploads = cmd.MaxUploads
+		share.maxUploadSize = cmd.MaxUploadSize
+	}
+
+	err = s.storage.Save(ctx, &share)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to Save: %w", err))
+	}
+
+	return &share, nil
+}
+
+// Open returns the share matching the token if it is still usable and if
+// the password or the unlock key is valid.
+//
+// An ErrNotFound is returned for an unknown, expired or exhausted share and
+// an ErrUnauthorized is returned if the password is missing or invalid. An
+// upload share is exhausted once its file count or size limit is reached.
+func (s *service) Open(ctx context.Context, cmd *OpenCmd) (*Share, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	share, err := s.storage.GetByToken(ctx, cmd.Token)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(err, "share not found")
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetByToken: %w", err))
+	}
+
+	if share.IsExpired(s.clock.Now()) {
+		return nil, errs.NotFound(ErrExpired, "share expired")
+	}
+
+	if share.IsDownloadLimitReached() {
+		return nil, errs.NotFound(ErrDownloadLimitReached, "download limit reached")
+	}
+
+	if share.IsUploadLimitReached() {
+		return nil, errs.NotFound(ErrUploadLimitReached, "upload limit reached")
+	}
+
+	if !share.HasPassword() {
+		return share, nil
+	}
+
+	if cmd.UnlockKey.Raw() != "" && hmac.Equal([]byte(cmd.UnlockKey.Raw()), []byte(share.unlockKey().Raw())) {
+		return share, nil
+	}
+
+	if cmd.Password.Raw() == "" {
+		return nil, errs.Unauthorized(ErrInvalidPassword, "password required")
+	}
+
+	ok, err := s.password.Compare(ctx, share.password, cmd.Password)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed password compare: %w", err))
+	}
+
+	if !ok {
+		return nil, errs.Unauthorized(ErrInvalidPassword, "invalid password")
+	}
+
+	return share, nil
+}
+
+// Unlock opens the share like Open and returns a key replacing the password
+// for the next calls to Open. The key is invalidated by a password change.
+func (s *service) Unlock(ctx context.Context, cmd *OpenCmd) (secret.Text, error) {
+	share, err := s.Open(ctx, cmd)
+	if err != nil {
+		return secret.Empty, err
+	}
+
+	return share.unlockKey(), nil
+}
+
+// RegisterDownload increases the download counter of the share. An
+// ErrNotFound is returned if the download limit is already reached.
+func (s *service) RegisterDownload(ctx context.Context, share *Share) error {
+	ok, err := s.storage.IncrementDownloads(ctx, share.ID())
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to IncrementDownloads: %w", err))
+	}
+
+	if !ok {
+		return errs.NotFound(ErrDownloadLimitReached, "download limit reached")
+	}
+
+	share.downloads++
+
+	return nil
+}
+
+// NewDownloadKey returns the key given to a visitor once the download of the
+// inode is counted. It allows to resume this download during [DownloadKeyTTL].
+func (s *service) NewDownloadKey(share *Share, inodeID uuid.UUID) secret.Text {
+	return share.downloadKey(inodeID, s.clock.Now())
+}
+
+// IsValidDownloadKey returns true if the key was given by
+// [service.NewDownloadKey] for this inode less than [DownloadKeyTTL] ago.
+func (s *service) IsValidDownloadKey(share *Share, inodeID uuid.UUID, key secret.Text) bool {
+	rawIssuedAt, _, ok := strings.Cut(key.Raw(), ".")
+	if !ok {
+		return false
+	}
+
+	unixIssuedAt, err := strconv.ParseInt(rawIssuedAt, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	issuedAt := time.Unix(unixIssuedAt, 0)
+	now := s.clock.Now()
+	if issuedAt.After(now) || now.Sub(issuedAt) >= DownloadKeyTTL {
+		return false
+	}
+
+	return hmac.Equal([]byte(key.Raw()), []byte(share.downloadKey(inodeID, issuedAt).Raw()))
+}
+
+// RegisterUpload increases the upload counters of the share with a new file
+// of the given size. An ErrNotFound is returned if the file count or the size
+// limit is exceeded.
+func (s *service) RegisterUpload(ctx context.Context, share *Share, size uint64) error {
+	ok, err := s.storage.IncrementUploads(ctx, share.ID(), size)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to IncrementUploads: %w", err))
+	}
+
+	if !ok {
+		return errs.NotFound(ErrUploadLimitReached, "upload limit reached")
+	}
+
+	share.uploads++
+	share.uploadedSize += size
+
+	return nil
+}
+
+func (s *service) GetAllForINode(ctx context.Context, inodeID uuid.UUID, paginateCmd *sqlstorage.PaginateCmd) ([]Share, error) {
+	res, err := s.storage.GetAllForINode(ctx, inodeID, paginateCmd)
+	if err != nil {
+		return nil, errs.Internal(err)
+	}
+
+	return res, nil
+}
+
+func (s *service) Delete(ctx context.Context, cmd *DeleteCmd) error {
+	err := cmd.Validate()
+	if err != nil {
+		return errs.Validation(err)
+	}
+
+	share, err := s.storage.GetByID(ctx, cmd.ShareID)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
+	}
+
+	role, err := s.spaces.GetUserRole(ctx, cmd.User.ID(), share.SpaceID())
+	if errors.Is(err, errs.ErrNotFound) || errors.Is(err, errs.ErrUnauthorized) {
+		return errs.NotFound(err, "not found")
+	}
+
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to GetUserRole: %w", err))
+	}
+
+	if !role.CanWrite() {
+		return errs.Unauthorized(ErrReadOnlyRole, "only the editors and the managers can delete a share")
+	}
+
+	err = s.storage.RemoveByID(ctx, share.ID())
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to RemoveByID: %w", err))
+	}
+
+	return nil
+}
+
+// MoveToSpace attaches the shares of the inode to its new space. It's called
+// by the fs-move task when the inode is moved into an other space.
+func (s *service) MoveToSpace(ctx context.Context, inodeID, spaceID uuid.UUID) error {
+	err := s.storage.MoveAllForINode(ctx, inodeID, spaceID)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to MoveAllForINode: %w", err))
+	}
+
+	return nil
+}
+
+// DeleteAll removes all the shares created by the given user.
+func (s *service) DeleteAll(ctx context.Context, userID uuid.UUID) error {
+	shares, err := s.storage.GetAllCreatedBy(ctx, userID, nil)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to GetAllCreatedBy: %w", err))
+	}
+
+	for _, share := range shares {
+		err = s.storage.RemoveByID(ctx, share.ID())
+		if err != nil {
+			return errs.Internal(fmt.Errorf("failed to RemoveByID %q: %w", share.ID(), err))
+		}
+	}
+
+	return nil
+}
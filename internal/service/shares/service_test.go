@@ -0,0 +1,971 @@
+package shares
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func TestSharesService(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("Create success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		now := time.Now().UTC()
+		expiresAt := now.Add(24 * time.Hour)
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		inode := dfs.NewFakeINode(t).WithSpace(space).Build()
+		share := NewFakeShare(t).
+			WithINode(inode).
+			WithHashedPassword("some-hashed-password").
+			WithMaxDownloads(3).
+			ExpiresAt(expiresAt).
+			CreatedAt(now).
+			CreatedBy(user).
+			Build()
+
+		// Mocks
+		spacesMock.On("GetUserRole", mock.Anything, user.ID(), space.ID()).Return(spaces.RoleEditor, nil).Once()
+		tools.PasswordMock.On("Encrypt", mock.Anything, secret.NewText("some-password")).
+			Return(secret.NewText("some-hashed-password"), nil).Once()
+		tools.UUIDMock.On("New").Return(share.ID()).Once()
+		tools.UUIDMock.On("New").Return(uuid.UUID(share.Token().Raw())).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Save", mock.Anything, share).Return(nil).Once()
+
+		// Run
+		res, err := service.Create(ctx, &CreateCmd{
+			Space:        space,
+			INode:        inode,
+			CreatedBy:    user,
+			Kind:         DownloadKind,
+			ExpiresAt:    &expiresAt,
+			Password:     secret.NewText("some-password"),
+			MaxDownloads: 3,
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, share, res)
+	})
+
+	t.Run("Create without password", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		now := time.Now().UTC()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		inode := dfs.NewFakeINode(t).WithSpace(space).Build()
+		share := NewFakeShare(t).
+			WithINode(inode).
+			CreatedAt(now).
+			CreatedBy(user).
+			Build()
+
+		// Mocks
+		spacesMock.On("GetUserRole", mock.Anything, user.ID(), space.ID()).Return(spaces.RoleEditor, nil).Once()
+		tools.UUIDMock.On("New").Return(share.ID()).Once()
+		tools.UUIDMock.On("New").Return(uuid.UUID(share.Token().Raw())).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Save", mock.Anything, share).Return(nil).Once()
+
+		// Run
+		res, err := service.Create(ctx, &CreateCmd{
+			Space:     space,
+			INode:     inode,
+			CreatedBy: user,
+			Kind:      DownloadKind,
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, share, res)
+		assert.False(t, res.HasPassword())
+	})
+
+	t.Run("Create an upload share", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		now := time.Now().UTC()
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		inode := dfs.NewFakeINode(t).WithSpace(space).IsDirectory().Build()
+		share := NewFakeShare(t).
+			WithINode(inode).
+			WithUploadLimits(10, 2048).
+			CreatedAt(now).
+			CreatedBy(user).
+			Build()
+
+		// Mocks
+		spacesMock.On("GetUserRole", mock.Anything, user.ID(), space.ID()).Return(spaces.RoleEditor, nil).Once()
+		tools.UUIDMock.On("New").Return(share.ID()).Once()
+		tools.UUIDMock.On("New").Return(uuid.UUID(share.Token().Raw())).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Save", mock.Anything, share).Return(nil).Once()
+
+		// Run
+		res, err := service.Create(ctx, &CreateCmd{
+			Space:         space,
+			INode:         inode,
+			CreatedBy:     user,
+			Kind:          UploadKind,
+			MaxDownloads:  5, // Ignored for an upload share
+			MaxUploads:    10,
+			MaxUploadSize: 2048,
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, share, res)
+	})
+
+	t.Run("Create an upload share on a file", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.RoleEditor, nil).Once()
+
+		// Run
+		res, err := service.Create(ctx, &CreateCmd{
+			Space:     &spaces.ExampleAlicePersonalSpace,
+			INode:     &dfs.ExampleAliceFile,
+			CreatedBy: &users.ExampleAlice,
+			Kind:      UploadKind,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrNotADirectory)
+	})
+
+	t.Run("Create with a validation error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Run
+		res, err := service.Create(ctx, &CreateCmd{
+			Space:     &spaces.ExampleAlicePersonalSpace,
+			INode:     nil,
+			CreatedBy: &users.ExampleAlice,
+			Kind:      DownloadKind,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrValidation)
+		require.ErrorContains(t, err, "INode: cannot be blank.")
+	})
+
+	t.Run("Create with a space not accessible by the user", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleBob.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.Role(""), errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
+
+		// Run
+		res, err := service.Create(ctx, &CreateCmd{
+			Space:     &spaces.ExampleAlicePersonalSpace,
+			INode:     &dfs.ExampleAliceFile,
+			CreatedBy: &users.ExampleBob,
+			Kind:      DownloadKind,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrInvalidSpaceID)
+	})
+
+	t.Run("Create with a viewer role", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleBob.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.RoleViewer, nil).Once()
+
+		// Run
+		res, err := service.Create(ctx, &CreateCmd{
+			Space:     &spaces.ExampleAlicePersonalSpace,
+			INode:     &dfs.ExampleAliceFile,
+			CreatedBy: &users.ExampleBob,
+			Kind:      DownloadKind,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrReadOnlyRole)
+	})
+
+	t.Run("Create with an inode from an other space", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		inode := dfs.NewFakeINode(t).Build()
+
+		// Mocks
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.RoleEditor, nil).Once()
+
+		// Run
+		res, err := service.Create(ctx, &CreateCmd{
+			Space:     &spaces.ExampleAlicePersonalSpace,
+			INode:     inode,
+			CreatedBy: &users.ExampleAlice,
+			Kind:      DownloadKind,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrINodeNotInSpace)
+	})
+
+	t.Run("Create with a Save error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.RoleEditor, nil).Once()
+		tools.UUIDMock.On("New").Return(ExampleAliceFileShare.ID()).Once()
+		tools.UUIDMock.On("New").Return(uuid.UUID(ExampleAliceFileShare.Token().Raw())).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("Save", mock.Anything, &ExampleAliceFileShare).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := service.Create(ctx, &CreateCmd{
+			Space:     &spaces.ExampleAlicePersonalSpace,
+			INode:     &dfs.ExampleAliceFile,
+			CreatedBy: &users.ExampleAlice,
+			Kind:      DownloadKind,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("Open success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, ExampleAliceFileShare.Token()).Return(&ExampleAliceFileShare, nil).Once()
+		tools.ClockMock.On("Now").Return(time.Now()).Once()
+
+		// Run
+		res, err := service.Open(ctx, &OpenCmd{Token: ExampleAliceFileShare.Token()})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, &ExampleAliceFileShare, res)
+	})
+
+	t.Run("Open with an unknown token", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, secret.NewText("some-token")).Return(nil, errNotFound).Once()
+
+		// Run
+		res, err := service.Open(ctx, &OpenCmd{Token: secret.NewText("some-token")})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("Open with an expired share", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		share := NewFakeShare(t).ExpiresAt(time.Now().Add(-time.Hour)).Build()
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, share.Token()).Return(share, nil).Once()
+		tools.ClockMock.On("Now").Return(time.Now()).Once()
+
+		// Run
+		res, err := service.Open(ctx, &OpenCmd{Token: share.Token()})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		require.ErrorIs(t, err, ErrExpired)
+	})
+
+	t.Run("Open with the download limit reached", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		share := NewFakeShare(t).WithMaxDownloads(1).WithDownloads(1).Build()
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, share.Token()).Return(share, nil).Once()
+		tools.ClockMock.On("Now").Return(time.Now()).Once()
+
+		// Run
+		res, err := service.Open(ctx, &OpenCmd{Token: share.Token()})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		require.ErrorIs(t, err, ErrDownloadLimitReached)
+	})
+
+	t.Run("Open with the upload limit reached", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		share := NewFakeShare(t).WithUploadLimits(0, 1024).WithUploads(3, 1024).Build()
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, share.Token()).Return(share, nil).Once()
+		tools.ClockMock.On("Now").Return(time.Now()).Once()
+
+		// Run
+		res, err := service.Open(ctx, &OpenCmd{Token: share.Token()})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		require.ErrorIs(t, err, ErrUploadLimitReached)
+	})
+
+	t.Run("Open with a valid password", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, ExampleAliceProtectedDirShare.Token()).Return(&ExampleAliceProtectedDirShare, nil).Once()
+		tools.ClockMock.On("Now").Return(time.Now()).Once()
+		tools.PasswordMock.On("Compare", mock.Anything, secret.NewText("some-hashed-password"), secret.NewText("some-password")).
+			Return(true, nil).Once()
+
+		// Run
+		res, err := service.Open(ctx, &OpenCmd{
+			Token:    ExampleAliceProtectedDirShare.Token(),
+			Password: secret.NewText("some-password"),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, &ExampleAliceProtectedDirShare, res)
+	})
+
+	t.Run("Open without the required password", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, ExampleAliceProtectedDirShare.Token()).Return(&ExampleAliceProtectedDirShare, nil).Once()
+		tools.ClockMock.On("Now").Return(time.Now()).Once()
+
+		// Run
+		res, err := service.Open(ctx, &OpenCmd{Token: ExampleAliceProtectedDirShare.Token()})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrInvalidPassword)
+	})
+
+	t.Run("Open with an invalid password", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, ExampleAliceProtectedDirShare.Token()).Return(&ExampleAliceProtectedDirShare, nil).Once()
+		tools.ClockMock.On("Now").Return(time.Now()).Once()
+		tools.PasswordMock.On("Compare", mock.Anything, secret.NewText("some-hashed-password"), secret.NewText("invalid")).
+			Return(false, nil).Once()
+
+		// Run
+		res, err := service.Open(ctx, &OpenCmd{
+			Token:    ExampleAliceProtectedDirShare.Token(),
+			Password: secret.NewText("invalid"),
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrInvalidPassword)
+	})
+
+	t.Run("Unlock success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, ExampleAliceProtectedDirShare.Token()).Return(&ExampleAliceProtectedDirShare, nil).Once()
+		tools.ClockMock.On("Now").Return(time.Now()).Once()
+		tools.PasswordMock.On("Compare", mock.Anything, secret.NewText("some-hashed-password"), secret.NewText("some-password")).
+			Return(true, nil).Once()
+
+		// Run
+		res, err := service.Unlock(ctx, &OpenCmd{
+			Token:    ExampleAliceProtectedDirShare.Token(),
+			Password: secret.NewText("some-password"),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, ExampleAliceProtectedDirShare.unlockKey(), res)
+		assert.NotContains(t, res.Raw(), "some-password")
+	})
+
+	t.Run("Unlock with an invalid password", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, ExampleAliceProtectedDirShare.Token()).Return(&ExampleAliceProtectedDirShare, nil).Once()
+		tools.ClockMock.On("Now").Return(time.Now()).Once()
+		tools.PasswordMock.On("Compare", mock.Anything, secret.NewText("some-hashed-password"), secret.NewText("invalid")).
+			Return(false, nil).Once()
+
+		// Run
+		res, err := service.Unlock(ctx, &OpenCmd{
+			Token:    ExampleAliceProtectedDirShare.Token(),
+			Password: secret.NewText("invalid"),
+		})
+
+		// Asserts
+		assert.Empty(t, res.Raw())
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("Open with an unlock key", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, ExampleAliceProtectedDirShare.Token()).Return(&ExampleAliceProtectedDirShare, nil).Once()
+		tools.ClockMock.On("Now").Return(time.Now()).Once()
+
+		// Run
+		res, err := service.Open(ctx, &OpenCmd{
+			Token:     ExampleAliceProtectedDirShare.Token(),
+			UnlockKey: ExampleAliceProtectedDirShare.unlockKey(),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, &ExampleAliceProtectedDirShare, res)
+	})
+
+	t.Run("Open with an invalid unlock key", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByToken", mock.Anything, ExampleAliceProtectedDirShare.Token()).Return(&ExampleAliceProtectedDirShare, nil).Once()
+		tools.ClockMock.On("Now").Return(time.Now()).Once()
+
+		// Run
+		res, err := service.Open(ctx, &OpenCmd{
+			Token:     ExampleAliceProtectedDirShare.Token(),
+			UnlockKey: secret.NewText("some-password"),
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrInvalidPassword)
+	})
+
+	t.Run("RegisterDownload success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		share := NewFakeShare(t).WithMaxDownloads(2).WithDownloads(1).Build()
+
+		// Mocks
+		storageMock.On("IncrementDownloads", mock.Anything, share.ID()).Return(true, nil).Once()
+
+		// Run
+		err := service.RegisterDownload(ctx, share)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, 2, share.Downloads())
+	})
+
+	t.Run("RegisterDownload with the limit reached", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		share := NewFakeShare(t).WithMaxDownloads(2).WithDownloads(1).Build()
+
+		// Mocks
+		storageMock.On("IncrementDownloads", mock.Anything, share.ID()).Return(false, nil).Once()
+
+		// Run
+		err := service.RegisterDownload(ctx, share)
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		require.ErrorIs(t, err, ErrDownloadLimitReached)
+	})
+
+	t.Run("IsValidDownloadKey with a key from NewDownloadKey", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		now := time.Now()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		tools.ClockMock.On("Now").Return(now.Add(DownloadKeyTTL - time.Minute)).Once()
+
+		// Run
+		key := service.NewDownloadKey(&ExampleAliceFileShare, "some-inode-id")
+		res := service.IsValidDownloadKey(&ExampleAliceFileShare, "some-inode-id", key)
+
+		// Asserts
+		assert.True(t, res)
+	})
+
+	t.Run("IsValidDownloadKey with an expired key", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		now := time.Now()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now).Once()
+		tools.ClockMock.On("Now").Return(now.Add(DownloadKeyTTL)).Once()
+
+		// Run
+		key := service.NewDownloadKey(&ExampleAliceFileShare, "some-inode-id")
+		res := service.IsValidDownloadKey(&ExampleAliceFileShare, "some-inode-id", key)
+
+		// Asserts
+		assert.False(t, res)
+	})
+
+	t.Run("IsValidDownloadKey with a key issued in the future", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		now := time.Now()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now.Add(time.Hour)).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		// Run
+		key := service.NewDownloadKey(&ExampleAliceFileShare, "some-inode-id")
+		res := service.IsValidDownloadKey(&ExampleAliceFileShare, "some-inode-id", key)
+
+		// Asserts
+		assert.False(t, res)
+	})
+
+	t.Run("IsValidDownloadKey with a key for an other inode or share", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		now := time.Now()
+
+		// Mocks
+		tools.ClockMock.On("Now").Return(now)
+
+		// Run
+		key := service.NewDownloadKey(&ExampleAliceFileShare, "some-inode-id")
+
+		// Asserts
+		assert.False(t, service.IsValidDownloadKey(&ExampleAliceFileShare, "some-other-inode-id", key))
+		assert.False(t, service.IsValidDownloadKey(&ExampleAliceProtectedDirShare, "some-inode-id", key))
+		assert.False(t, service.IsValidDownloadKey(&ExampleAliceFileShare, "some-inode-id", secret.NewText("some-invalid-key")))
+	})
+
+	t.Run("RegisterUpload success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		share := NewFakeShare(t).WithUploadLimits(2, 0).WithUploads(1, 100).Build()
+
+		// Mocks
+		storageMock.On("IncrementUploads", mock.Anything, share.ID(), uint64(42)).Return(true, nil).Once()
+
+		// Run
+		err := service.RegisterUpload(ctx, share, 42)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, 2, share.Uploads())
+		assert.Equal(t, uint64(142), share.UploadedSize())
+	})
+
+	t.Run("RegisterUpload with the limit reached", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Data
+		share := NewFakeShare(t).WithUploadLimits(2, 0).WithUploads(1, 100).Build()
+
+		// Mocks
+		storageMock.On("IncrementUploads", mock.Anything, share.ID(), uint64(42)).Return(false, nil).Once()
+
+		// Run
+		err := service.RegisterUpload(ctx, share, 42)
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		require.ErrorIs(t, err, ErrUploadLimitReached)
+		assert.Equal(t, 1, share.Uploads())
+	})
+
+	t.Run("GetAllForINode success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetAllForINode", mock.Anything, dfs.ExampleAliceFile.ID(), &sqlstorage.PaginateCmd{Limit: 10}).
+			Return([]Share{ExampleAliceFileShare}, nil).Once()
+
+		// Run
+		res, err := service.GetAllForINode(ctx, dfs.ExampleAliceFile.ID(), &sqlstorage.PaginateCmd{Limit: 10})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []Share{ExampleAliceFileShare}, res)
+	})
+
+	t.Run("Delete success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFileShare.ID()).Return(&ExampleAliceFileShare, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.RoleEditor, nil).Once()
+		storageMock.On("RemoveByID", mock.Anything, ExampleAliceFileShare.ID()).Return(nil).Once()
+
+		// Run
+		err := service.Delete(ctx, &DeleteCmd{
+			User:    &users.ExampleAlice,
+			ShareID: ExampleAliceFileShare.ID(),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("Delete an unknown share", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFileShare.ID()).Return(nil, errNotFound).Once()
+
+		// Run
+		err := service.Delete(ctx, &DeleteCmd{
+			User:    &users.ExampleAlice,
+			ShareID: ExampleAliceFileShare.ID(),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("Delete with a user outside of the space", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFileShare.ID()).Return(&ExampleAliceFileShare, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleBob.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.Role(""), errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
+
+		// Run
+		err := service.Delete(ctx, &DeleteCmd{
+			User:    &users.ExampleBob,
+			ShareID: ExampleAliceFileShare.ID(),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("Delete with a viewer role", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, ExampleAliceFileShare.ID()).Return(&ExampleAliceFileShare, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleBob.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.RoleViewer, nil).Once()
+
+		// Run
+		err := service.Delete(ctx, &DeleteCmd{
+			User:    &users.ExampleBob,
+			ShareID: ExampleAliceFileShare.ID(),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrReadOnlyRole)
+	})
+
+	t.Run("MoveToSpace success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("MoveAllForINode", mock.Anything, ExampleAliceFileShare.INodeID(), spaces.ExampleBobPersonalSpace.ID()).
+			Return(nil).Once()
+
+		// Run
+		err := service.MoveToSpace(ctx, ExampleAliceFileShare.INodeID(), spaces.ExampleBobPersonalSpace.ID())
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("MoveToSpace with a MoveAllForINode error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("MoveAllForINode", mock.Anything, ExampleAliceFileShare.INodeID(), spaces.ExampleBobPersonalSpace.ID()).
+			Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		err := service.MoveToSpace(ctx, ExampleAliceFileShare.INodeID(), spaces.ExampleBobPersonalSpace.ID())
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("DeleteAll success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetAllCreatedBy", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]Share{ExampleAliceFileShare, ExampleAliceProtectedDirShare}, nil).Once()
+		storageMock.On("RemoveByID", mock.Anything, ExampleAliceFileShare.ID()).Return(nil).Once()
+		storageMock.On("RemoveByID", mock.Anything, ExampleAliceProtectedDirShare.ID()).Return(nil).Once()
+
+		// Run
+		err := service.DeleteAll(ctx, users.ExampleAlice.ID())
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("DeleteAll with a RemoveByID error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		spacesMock := spaces.NewMockService(t)
+		service := newService(storageMock, spacesMock, tools)
+
+		// Mocks
+		storageMock.On("GetAllCreatedBy", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]Share{ExampleAliceFileShare}, nil).Once()
+		storageMock.On("RemoveByID", mock.Anything, ExampleAliceFileShare.ID()).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		err := service.DeleteAll(ctx, users.ExampleAlice.ID())
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+}
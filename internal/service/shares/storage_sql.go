@@ -0,0 +1,264 @@
+package shares
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const tableName = "shares"
+
+var errNotFound = errors.New("not found")
+
+var allFields = []string{"id", "token", "kind", "space_id", "inode_id", "password", "max_downloads", "downloads", "max_uploads", "uploads", "max_upload_size", "uploaded_size", "expires_at", "created_at", "created_by"}
+
+type sqlStorage struct {
+	db sqlstorage.Querier
+}
+
+func newSqlStorage(db sqlstorage.Querier) *sqlStorage {
+	return &sqlStorage{db}
+}
+
+func (s *sqlStorage) Save(ctx context.Context, share *Share) error {
+	var expiresAt *sqlstorage.SQLTime
+	if share.expiresAt != nil {
+		expiresAt = ptr.To(sqlstorage.SQLTime(*share.expiresAt))
+	}
+
+	_, err := sq.
+		Insert(tableName).
+		Columns(allFields...).
+		Values(share.id,
+			share.token,
+			share.kind,
+			share.spaceID,
+			share.inodeID,
+			share.password,
+			share.maxDownloads,
+			share.downloads,
+			share.maxUploads,
+			share.uploads,
+			share.maxUploadSize,
+			share.uploadedSize,
+			expiresAt,
+			ptr.To(sqlstorage.SQLTime(share.createdAt)),
+			share.createdBy).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) GetByID(ctx context.Context, shareID uuid.UUID) (*Share, error) {
+	return s.getByKeys(ctx, sq.Eq{"id": shareID})
+}
+
+func (s *sqlStorage) GetByToken(ctx context.Context, token secret.Text) (*Share, error) {
+	return s.getByKeys(ctx, sq.Eq{"token": token})
+}
+
+func (s *sqlStorage) GetAllForINode(ctx context.Context, inodeID uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]Share, error) {
+	rows, err := sqlstorage.PaginateSelection(sq.
+		Select(allFields...).
+		Where(sq.Eq{"inode_id": inodeID}).
+		From(tableName), cmd).
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
+func (s *sqlStorage) GetAllCreatedBy(ctx context.Context, userID uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]Share, error) {
+	rows, err := sqlstorage.PaginateSelection(sq.
+		Select(allFields...).
+		Where(sq.Eq{"created_by": userID}).
+		From(tableName), cmd).
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
+// IncrementDownloads increases the download counter of the share. It returns
+// false if the download limit is already reached.
+func (s *sqlStorage) IncrementDownloads(ctx context.Context, shareID uuid.UUID) (bool, error) {
+	res, err := sq.
+		Update(tableName).
+		Set("downloads", sq.Expr("downloads + 1")).
+		Where(sq.Eq{"id": shareID}).
+		Where(sq.Or{sq.Eq{"max_downloads": 0}, sq.Expr("downloads < max_downloads")}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return false, fmt.Errorf("sql error: %w", err)
+	}
+
+	nb, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get the affected rows: %w", err)
+	}
+
+	return nb > 0, nil
+}
+
+// IncrementUploads registers a new uploaded file of the given size. It
+// returns false if the file count or the size limit would be exceeded.
+func (s *sqlStorage) IncrementUploads(ctx context.Context, shareID uuid.UUID, size uint64) (bool, error) {
+	res, err := sq.
+		Update(tableName).
+		Set("uploads", sq.Expr("uploads + 1")).
+		Set("uploaded_size", sq.Expr("uploaded_size + ?", size)).
+		Where(sq.Eq{"id": shareID}).
+		Where(sq.Or{sq.Eq{"max_uploads": 0}, sq.Expr("uploads < max_uploads")}).
+		Where(sq.Or{sq.Eq{"max_upload_size": 0}, sq.Expr("uploaded_size + ? <= max_upload_size", size)}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return false, fmt.Errorf("sql error: %w", err)
+	}
+
+	nb, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get the affected rows: %w", err)
+	}
+
+	return nb > 0, nil
+}
+
+// MoveAllForINode attaches all the shares of the inode to the given space.
+func (s *sqlStorage) MoveAllForINode(ctx context.Context, inodeID, spaceID uuid.UUID) error {
+	_, err := sq.
+		Update(tableName).
+		Set("space_id", spaceID).
+		Where(sq.Eq{"inode_id": inodeID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) RemoveByID(ctx context.Context, shareID uuid.UUID) error {
+	_, err := sq.
+		Delete(tableName).
+		Where(sq.Eq{"id": shareID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) getByKeys(ctx context.Context, wheres ...any) (*Share, error) {
+	query := sq.
+		Select(allFields...).
+		From(tableName)
+
+	for _, where := range wheres {
+		query = query.Where(where)
+	}
+
+	var res Share
+	var sqlExpiresAt *sqlstorage.SQLTime
+	var sqlCreatedAt sqlstorage.SQLTime
+
+	err := query.
+		RunWith(s.db).
+		ScanContext(ctx,
+			&res.id,
+			&res.token,
+			&res.kind,
+			&res.spaceID,
+			&res.inodeID,
+			&res.password,
+			&res.maxDownloads,
+			&res.downloads,
+			&res.maxUploads,
+			&res.uploads,
+			&res.maxUploadSize,
+			&res.uploadedSize,
+			&sqlExpiresAt,
+			&sqlCreatedAt,
+			&res.createdBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	if sqlExpiresAt != nil {
+		res.expiresAt = ptr.To(sqlExpiresAt.Time())
+	}
+	res.createdAt = sqlCreatedAt.Time()
+
+	return &res, nil
+}
+
+func (s *sqlStorage) scanRows(rows *sql.Rows) ([]Share, error) {
+	shares := []Share{}
+
+	for rows.Next() {
+		var res Share
+		var sqlExpiresAt *sqlstorage.SQLTime
+		var sqlCreatedAt sqlstorage.SQLTime
+
+		err := rows.Scan(
+			&res.id,
+			&res.token,
+			&res.kind,
+			&res.spaceID,
+			&res.inodeID,
+			&res.password,
+			&res.maxDownloads,
+			&res.downloads,
+			&res.maxUploads,
+			&res.uploads,
+			&res.maxUploadSize,
+			&res.uploadedSize,
+			&sqlExpiresAt,
+			&sqlCreatedAt,
+			&res.createdBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		if sqlExpiresAt != nil {
+			res.expiresAt = ptr.To(sqlExpiresAt.Time())
+		}
+		res.createdAt = sqlCreatedAt.Time()
+		shares = append(shares, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return shares, nil
+}
@@ -0,0 +1,150 @@
+package shares
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+func TestShareSqlStorage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := sqlstorage.NewTestStorage(t)
+	store := newSqlStorage(db)
+
+	// Data
+	user := users.NewFakeUser(t).WithAdminRole().BuildAndStore(ctx, db)
+	space := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
+	inode := dfs.NewFakeINode(t).WithSpace(space).Build()
+	share := NewFakeShare(t).
+		WithINode(inode).
+		WithHashedPassword("some-hashed-password").
+		WithMaxDownloads(2).
+		ExpiresAt(time.Now().Add(time.Hour).UTC()).
+		CreatedBy(user).
+		Build()
+
+	t.Run("Save success", func(t *testing.T) {
+		err := store.Save(ctx, share)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("GetByID success", func(t *testing.T) {
+		res, err := store.GetByID(ctx, share.ID())
+
+		require.NoError(t, err)
+		assert.Equal(t, share, res)
+	})
+
+	t.Run("GetByID not found", func(t *testing.T) {
+		res, err := store.GetByID(ctx, uuid.UUID("some-invalid-id"))
+
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("GetByToken success", func(t *testing.T) {
+		res, err := store.GetByToken(ctx, share.Token())
+
+		require.NoError(t, err)
+		assert.Equal(t, share, res)
+	})
+
+	t.Run("GetByToken not found", func(t *testing.T) {
+		res, err := store.GetByToken(ctx, secret.NewText("some-invalid-token"))
+
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("GetAllForINode success", func(t *testing.T) {
+		res, err := store.GetAllForINode(ctx, inode.ID(), nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, []Share{*share}, res)
+	})
+
+	t.Run("GetAllCreatedBy success", func(t *testing.T) {
+		res, err := store.GetAllCreatedBy(ctx, user.ID(), nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, []Share{*share}, res)
+	})
+
+	t.Run("IncrementDownloads until the limit", func(t *testing.T) {
+		ok, err := store.IncrementDownloads(ctx, share.ID())
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = store.IncrementDownloads(ctx, share.ID())
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = store.IncrementDownloads(ctx, share.ID())
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		res, err := store.GetByID(ctx, share.ID())
+		require.NoError(t, err)
+		assert.Equal(t, 2, res.Downloads())
+	})
+
+	t.Run("IncrementUploads until the limits", func(t *testing.T) {
+		uploadShare := NewFakeShare(t).
+			WithINode(inode).
+			WithUploadLimits(3, 100).
+			CreatedBy(user).
+			BuildAndStore(ctx, db)
+
+		ok, err := store.IncrementUploads(ctx, uploadShare.ID(), 60)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		// The size limit would be exceeded.
+		ok, err = store.IncrementUploads(ctx, uploadShare.ID(), 50)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = store.IncrementUploads(ctx, uploadShare.ID(), 40)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		res, err := store.GetByID(ctx, uploadShare.ID())
+		require.NoError(t, err)
+		assert.Equal(t, UploadKind, res.Kind())
+		assert.Equal(t, 2, res.Uploads())
+		assert.Equal(t, uint64(100), res.UploadedSize())
+		assert.True(t, res.IsUploadLimitReached())
+	})
+
+	t.Run("MoveAllForINode success", func(t *testing.T) {
+		otherSpace := spaces.NewFakeSpace(t).WithMembers(spaces.RoleManager, *user).BuildAndStore(ctx, db)
+
+		err := store.MoveAllForINode(ctx, inode.ID(), otherSpace.ID())
+		require.NoError(t, err)
+
+		res, err := store.GetByID(ctx, share.ID())
+		require.NoError(t, err)
+		assert.Equal(t, otherSpace.ID(), res.SpaceID())
+	})
+
+	t.Run("RemoveByID success", func(t *testing.T) {
+		err := store.RemoveByID(ctx, share.ID())
+		require.NoError(t, err)
+
+		res, err := store.GetByID(ctx, share.ID())
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+}
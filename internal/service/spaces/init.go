@@ -3,6 +3,7 @@ package spaces
 import (
 	"context"
 
+	"github.com/theduckcompany/duckcloud/internal/service/groups"
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools"
@@ -18,13 +19,23 @@ type Service interface {
 	GetAllSpaces(ctx context.Context, user *users.User, cmd *sqlstorage.PaginateCmd) ([]Space, error)
 	GetUserSpace(ctx context.Context, userID, spaceID uuid.UUID) (*Space, error)
 	GetByID(ctx context.Context, spaceID uuid.UUID) (*Space, error)
-	AddOwner(ctx context.Context, cmd *AddOwnerCmd) (*Space, error)
-	RemoveOwner(ctx context.Context, cmd *RemoveOwnerCmd) (*Space, error)
+	GetUserRole(ctx context.Context, userID, spaceID uuid.UUID) (Role, error)
+	GetAllMembers(ctx context.Context, user *users.User, spaceID uuid.UUID) ([]Member, error)
+	AddMember(ctx context.Context, cmd *AddMemberCmd) (*Member, error)
+	SetMemberRole(ctx context.Context, cmd *SetMemberRoleCmd) (*Member, error)
+	RemoveMember(ctx context.Context, cmd *RemoveMemberCmd) error
+	GetAllGroups(ctx context.Context, user *users.User, spaceID uuid.UUID) ([]GroupAccess, error)
+	AddGroup(ctx context.Context, cmd *AddGroupCmd) (*GroupAccess, error)
+	RemoveGroup(ctx context.Context, cmd *RemoveGroupCmd) error
+	RemoveGroupFromAllSpaces(ctx context.Context, groupID uuid.UUID) error
+	SetTrashRetention(ctx context.Context, cmd *SetTrashRetentionCmd) (*Space, error)
+	SetVersionsPolicy(ctx context.Context, cmd *SetVersionsPolicyCmd) (*Space, error)
+	SetQuota(ctx context.Context, cmd *SetQuotaCmd) (*Space, error)
 	Delete(ctx context.Context, user *users.User, spaceID uuid.UUID) error
 }
 
-func Init(tools tools.Tools, db sqlstorage.Querier, scheduler scheduler.Service) Service {
+func Init(tools tools.Tools, db sqlstorage.Querier, scheduler scheduler.Service, groups groups.Service) Service {
 	storage := newSqlStorage(db, tools)
 
-	return newService(tools, storage, scheduler)
+	return newService(tools, storage, scheduler, groups)
 }
@@ -1,9 +1,7 @@
 package spaces
 
 import (
-	"database/sql/driver"
-	"fmt"
-	"strings"
+	"slices"
 	"time"
 
 	v "github.com/go-ozzo/ozzo-validation"
@@ -12,60 +10,111 @@ import (
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
-const BootstrapSpaceName = "Everyone"
+const (
+	BootstrapSpaceName    = "Everyone"
+	DefaultTrashRetention = 30 * 24 * time.Hour
+	DefaultMaxVersions    = 10
+)
 
 type Space struct {
-	createdAt time.Time
-	id        uuid.UUID
-	name      string
-	createdBy uuid.UUID
-	owners    Owners
+	createdAt         time.Time
+	id                uuid.UUID
+	name              string
+	createdBy         uuid.UUID
+	trashRetention    time.Duration
+	maxVersions       int
+	versionsRetention time.Duration
+	quota             uint64
 }
 
-func (f Space) ID() uuid.UUID        { return f.id }
-func (f Space) Name() string         { return f.name }
-func (f Space) Owners() Owners       { return f.owners }
-func (f Space) CreatedAt() time.Time { return f.createdAt }
-func (f Space) CreatedBy() uuid.UUID { return f.createdBy }
+func (f Space) ID() uuid.UUID                 { return f.id }
+func (f Space) Name() string                  { return f.name }
+func (f Space) CreatedAt() time.Time          { return f.createdAt }
+func (f Space) CreatedBy() uuid.UUID          { return f.createdBy }
+func (f Space) TrashRetention() time.Duration { return f.trashRetention }
+
+// MaxVersions is the number of past versions kept for each file. Zero means
+// no limit.
+func (f Space) MaxVersions() int { return f.maxVersions }
+
+// VersionsRetention is the maximum age of a file version before being pruned.
+// Zero means no limit.
+func (f Space) VersionsRetention() time.Duration { return f.versionsRetention }
+
+// Quota is the maximum number of bytes stored inside the space. Zero means
+// no limit.
+func (f Space) Quota() uint64 { return f.quota }
+
+// Role defines what a member is allowed to do inside a space.
+type Role string
+
+const (
+	// RoleViewer can only read the space content.
+	RoleViewer Role = "viewer"
+	// RoleEditor can read and modify the space content.
+	RoleEditor Role = "editor"
+	// RoleManager can modify the space content and manage its members.
+	RoleManager Role = "manager"
+)
 
-type Owners []uuid.UUID
+var Roles = []Role{RoleViewer, RoleEditor, RoleManager}
 
-func (t Owners) String() string {
-	rawIDs := make([]string, len(t))
+func (r Role) String() string { return string(r) }
 
-	for i, id := range t {
-		rawIDs[i] = string(id)
-	}
+// CanWrite returns true if the role allows to upload, rename, move and
+// delete files.
+func (r Role) CanWrite() bool { return r == RoleEditor || r == RoleManager }
 
-	return strings.Join(rawIDs, ",")
-}
+// CanManage returns true if the role allows to manage the space members.
+func (r Role) CanManage() bool { return r == RoleManager }
 
-func (t Owners) Value() (driver.Value, error) { return t.String(), nil }
-func (t *Owners) Scan(src any) error {
-	s, ok := src.(string)
-	if !ok {
-		return fmt.Errorf("not a string")
+// Max returns the role with the most rights between r and other.
+func (r Role) Max(other Role) Role {
+	if slices.Index(Roles, other) > slices.Index(Roles, r) {
+		return other
 	}
 
-	*t = Owners{}
+	return r
+}
 
-	if s == "" {
-		return nil
-	}
+func (r Role) Validate() error {
+	return v.Validate(string(r), v.In(RoleViewer.String(), RoleEditor.String(), RoleManager.String()))
+}
 
-	rawIDs := strings.Split(s, ",")
+type Member struct {
+	createdAt time.Time
+	spaceID   uuid.UUID
+	userID    uuid.UUID
+	role      Role
+	createdBy uuid.UUID
+}
 
-	for _, id := range rawIDs {
-		*t = append(*t, uuid.UUID(id))
-	}
+func (m Member) SpaceID() uuid.UUID   { return m.spaceID }
+func (m Member) UserID() uuid.UUID    { return m.userID }
+func (m Member) Role() Role           { return m.role }
+func (m Member) CreatedAt() time.Time { return m.createdAt }
+func (m Member) CreatedBy() uuid.UUID { return m.createdBy }
 
-	return nil
+// GroupAccess gives a role inside a space to all the members of a group.
+type GroupAccess struct {
+	createdAt time.Time
+	spaceID   uuid.UUID
+	groupID   uuid.UUID
+	role      Role
+	createdBy uuid.UUID
 }
 
+func (g GroupAccess) SpaceID() uuid.UUID   { return g.spaceID }
+func (g GroupAccess) GroupID() uuid.UUID   { return g.groupID }
+func (g GroupAccess) Role() Role           { return g.role }
+func (g GroupAccess) CreatedAt() time.Time { return g.createdAt }
+func (g GroupAccess) CreatedBy() uuid.UUID { return g.createdBy }
+
 type CreateCmd struct {
-	User   *users.User
-	Name   string
-	Owners []uuid.UUID
+	User *users.User
+	Name string
+	// Managers are the users added to the space with the [RoleManager] role.
+	Managers []uuid.UUID
 }
 
 // Validate the fields.
@@ -73,36 +122,133 @@ func (t CreateCmd) Validate() error {
 	return v.ValidateStruct(&t,
 		v.Field(&t.User, v.Required),
 		v.Field(&t.Name, v.Required, v.Length(1, 30)),
-		v.Field(&t.Owners, v.Each(is.UUIDv4)),
+		v.Field(&t.Managers, v.Each(is.UUIDv4)),
+	)
+}
+
+type AddMemberCmd struct {
+	User    *users.User
+	Member  *users.User
+	SpaceID uuid.UUID
+	Role    Role
+}
+
+// Validate the fields.
+func (t AddMemberCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.Member, v.Required),
+		v.Field(&t.SpaceID, v.Required, is.UUIDv4),
+		v.Field(&t.Role, v.Required),
+	)
+}
+
+type SetMemberRoleCmd struct {
+	User     *users.User
+	MemberID uuid.UUID
+	SpaceID  uuid.UUID
+	Role     Role
+}
+
+// Validate the fields.
+func (t SetMemberRoleCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.MemberID, v.Required, is.UUIDv4),
+		v.Field(&t.SpaceID, v.Required, is.UUIDv4),
+		v.Field(&t.Role, v.Required),
+	)
+}
+
+type RemoveMemberCmd struct {
+	User     *users.User
+	MemberID uuid.UUID
+	SpaceID  uuid.UUID
+}
+
+// Validate the fields.
+func (t RemoveMemberCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.MemberID, v.Required, is.UUIDv4),
+		v.Field(&t.SpaceID, v.Required, is.UUIDv4),
+	)
+}
+
+type AddGroupCmd struct {
+	User    *users.User
+	GroupID uuid.UUID
+	SpaceID uuid.UUID
+	Role    Role
+}
+
+// Validate the fields.
+func (t AddGroupCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.GroupID, v.Required, is.UUIDv4),
+		v.Field(&t.SpaceID, v.Required, is.UUIDv4),
+		v.Field(&t.Role, v.Required),
 	)
 }
 
-type AddOwnerCmd struct {
+type RemoveGroupCmd struct {
 	User    *users.User
-	Owner   *users.User
+	GroupID uuid.UUID
 	SpaceID uuid.UUID
 }
 
 // Validate the fields.
-func (t AddOwnerCmd) Validate() error {
+func (t RemoveGroupCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.GroupID, v.Required, is.UUIDv4),
+		v.Field(&t.SpaceID, v.Required, is.UUIDv4),
+	)
+}
+
+type SetTrashRetentionCmd struct {
+	User      *users.User
+	SpaceID   uuid.UUID
+	Retention time.Duration
+}
+
+// Validate the fields.
+func (t SetTrashRetentionCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.SpaceID, v.Required, is.UUIDv4),
+		v.Field(&t.Retention, v.Min(time.Duration(0))),
+	)
+}
+
+type SetVersionsPolicyCmd struct {
+	User        *users.User
+	SpaceID     uuid.UUID
+	MaxVersions int
+	Retention   time.Duration
+}
+
+// Validate the fields.
+func (t SetVersionsPolicyCmd) Validate() error {
 	return v.ValidateStruct(&t,
 		v.Field(&t.User, v.Required),
-		v.Field(&t.Owner, v.Required),
 		v.Field(&t.SpaceID, v.Required, is.UUIDv4),
+		v.Field(&t.MaxVersions, v.Min(0)),
+		v.Field(&t.Retention, v.Min(time.Duration(0))),
 	)
 }
 
-type RemoveOwnerCmd struct {
+type SetQuotaCmd struct {
 	User    *users.User
-	Owner   *users.User
 	SpaceID uuid.UUID
+	Quota   uint64
 }
 
 // Validate the fields.
-func (t RemoveOwnerCmd) Validate() error {
+func (t SetQuotaCmd) Validate() error {
 	return v.ValidateStruct(&t,
 		v.Field(&t.User, v.Required),
-		v.Field(&t.Owner, v.Required),
 		v.Field(&t.SpaceID, v.Required, is.UUIDv4),
 	)
 }
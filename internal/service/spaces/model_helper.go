@@ -7,6 +7,7 @@ import (
 
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/groups"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
@@ -14,8 +15,9 @@ import (
 )
 
 type FakeSpaceBuilder struct {
-	t     *testing.T
-	space *Space
+	t       *testing.T
+	space   *Space
+	members []Member
 }
 
 func NewFakeSpace(t *testing.T) *FakeSpaceBuilder {
@@ -28,11 +30,12 @@ func NewFakeSpace(t *testing.T) *FakeSpaceBuilder {
 	return &FakeSpaceBuilder{
 		t: t,
 		space: &Space{
-			id:        uuidProvider.New(),
-			name:      gofakeit.Animal(),
-			owners:    Owners{},
-			createdAt: createdAt,
-			createdBy: uuidProvider.New(),
+			id:             uuidProvider.New(),
+			name:           gofakeit.Animal(),
+			createdAt:      createdAt,
+			createdBy:      uuidProvider.New(),
+			trashRetention: DefaultTrashRetention,
+			maxVersions:    DefaultMaxVersions,
 		},
 	}
 }
@@ -55,14 +58,37 @@ func (f *FakeSpaceBuilder) CreatedAt(at time.Time) *FakeSpaceBuilder {
 	return f
 }
 
-func (f *FakeSpaceBuilder) WithOwners(users ...users.User) *FakeSpaceBuilder {
-	owners := make(Owners, len(users))
-
-	for i, elem := range users {
-		owners[i] = elem.ID()
+// WithMembers adds the given users to the space with the given role. The
+// members are only saved by [FakeSpaceBuilder.BuildAndStore].
+func (f *FakeSpaceBuilder) WithMembers(role Role, users ...users.User) *FakeSpaceBuilder {
+	for _, elem := range users {
+		f.members = append(f.members, Member{
+			spaceID:   f.space.id,
+			userID:    elem.ID(),
+			role:      role,
+			createdAt: f.space.createdAt,
+			createdBy: f.space.createdBy,
+		})
 	}
 
-	f.space.owners = owners
+	return f
+}
+
+func (f *FakeSpaceBuilder) WithTrashRetention(retention time.Duration) *FakeSpaceBuilder {
+	f.space.trashRetention = retention
+
+	return f
+}
+
+func (f *FakeSpaceBuilder) WithVersionsPolicy(maxVersions int, retention time.Duration) *FakeSpaceBuilder {
+	f.space.maxVersions = maxVersions
+	f.space.versionsRetention = retention
+
+	return f
+}
+
+func (f *FakeSpaceBuilder) WithQuota(quota uint64) *FakeSpaceBuilder {
+	f.space.quota = quota
 
 	return f
 }
@@ -80,5 +106,94 @@ func (f *FakeSpaceBuilder) BuildAndStore(ctx context.Context, db sqlstorage.Quer
 	err := storage.Save(ctx, f.space)
 	require.NoError(f.t, err)
 
+	for _, member := range f.members {
+		err = storage.SaveMember(ctx, &member)
+		require.NoError(f.t, err)
+	}
+
 	return f.space
 }
+
+type FakeMemberBuilder struct {
+	t      *testing.T
+	member *Member
+}
+
+func NewFakeMember(t *testing.T, space *Space, user *users.User) *FakeMemberBuilder {
+	t.Helper()
+
+	return &FakeMemberBuilder{
+		t: t,
+		member: &Member{
+			spaceID:   space.ID(),
+			userID:    user.ID(),
+			role:      RoleViewer,
+			createdAt: gofakeit.DateRange(time.Now().Add(-time.Hour*1000), time.Now()),
+			createdBy: space.CreatedBy(),
+		},
+	}
+}
+
+func (f *FakeMemberBuilder) WithRole(role Role) *FakeMemberBuilder {
+	f.member.role = role
+
+	return f
+}
+
+func (f *FakeMemberBuilder) Build() *Member {
+	return f.member
+}
+
+func (f *FakeMemberBuilder) BuildAndStore(ctx context.Context, db sqlstorage.Querier) *Member {
+	f.t.Helper()
+
+	tools := tools.NewToolboxForTest(f.t)
+	storage := newSqlStorage(db, tools)
+
+	err := storage.SaveMember(ctx, f.member)
+	require.NoError(f.t, err)
+
+	return f.member
+}
+
+type FakeGroupAccessBuilder struct {
+	t      *testing.T
+	access *GroupAccess
+}
+
+func NewFakeGroupAccess(t *testing.T, space *Space, group *groups.Group) *FakeGroupAccessBuilder {
+	t.Helper()
+
+	return &FakeGroupAccessBuilder{
+		t: t,
+		access: &GroupAccess{
+			spaceID:   space.ID(),
+			groupID:   group.ID(),
+			role:      RoleViewer,
+			createdAt: gofakeit.DateRange(time.Now().Add(-time.Hour*1000), time.Now()),
+			createdBy: space.CreatedBy(),
+		},
+	}
+}
+
+func (f *FakeGroupAccessBuilder) WithRole(role Role) *FakeGroupAccessBuilder {
+	f.access.role = role
+
+	return f
+}
+
+func (f *FakeGroupAccessBuilder) Build() *GroupAccess {
+	return f.access
+}
+
+func (f *FakeGroupAccessBuilder) BuildAndStore(ctx context.Context, db sqlstorage.Querier) *GroupAccess {
+	f.t.Helper()
+
+	tools := tools.NewToolboxForTest(f.t)
+	storage := newSqlStorage(db, tools)
+
+	err := storage.SaveGroup(ctx, f.access)
+	require.NoError(f.t, err)
+
+	return f.access
+}
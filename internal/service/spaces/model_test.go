@@ -12,63 +12,63 @@ import (
 func Test_Space_Getters(t *testing.T) {
 	assert.Equal(t, ExampleAlicePersonalSpace.ID(), ExampleAlicePersonalSpace.id)
 	assert.Equal(t, ExampleAlicePersonalSpace.Name(), ExampleAlicePersonalSpace.name)
-	assert.Equal(t, ExampleAlicePersonalSpace.Owners(), ExampleAlicePersonalSpace.owners)
 	assert.Equal(t, ExampleAlicePersonalSpace.CreatedAt(), ExampleAlicePersonalSpace.createdAt)
 	assert.Equal(t, ExampleAlicePersonalSpace.CreatedBy(), ExampleAlicePersonalSpace.createdBy)
+	assert.Equal(t, ExampleAlicePersonalSpace.TrashRetention(), ExampleAlicePersonalSpace.trashRetention)
+	assert.Equal(t, ExampleAlicePersonalSpace.MaxVersions(), ExampleAlicePersonalSpace.maxVersions)
+	assert.Equal(t, ExampleAlicePersonalSpace.VersionsRetention(), ExampleAlicePersonalSpace.versionsRetention)
 }
 
-func Test_Owners_Getters(t *testing.T) {
-	var raw string
-	owners := Owners{uuid.UUID("some-id-1"), "some-id-2"}
-
-	t.Run("String", func(t *testing.T) {
-		raw = owners.String()
-		assert.Equal(t, "some-id-1,some-id-2", raw)
-	})
-
-	t.Run("Scan", func(t *testing.T) {
-		res := Owners{}
-		err := res.Scan(raw)
-
-		require.NoError(t, err)
-		assert.Equal(t, owners, res)
-	})
-
-	t.Run("Scan error", func(t *testing.T) {
-		res := Owners{}
-		err := res.Scan(nil)
+func Test_Member_Getters(t *testing.T) {
+	assert.Equal(t, ExampleAliceManager.SpaceID(), ExampleAliceManager.spaceID)
+	assert.Equal(t, ExampleAliceManager.UserID(), ExampleAliceManager.userID)
+	assert.Equal(t, ExampleAliceManager.Role(), ExampleAliceManager.role)
+	assert.Equal(t, ExampleAliceManager.CreatedAt(), ExampleAliceManager.createdAt)
+	assert.Equal(t, ExampleAliceManager.CreatedBy(), ExampleAliceManager.createdBy)
+}
 
-		require.EqualError(t, err, "not a string")
-		assert.Empty(t, res)
-	})
+func Test_Role(t *testing.T) {
+	assert.False(t, RoleViewer.CanWrite())
+	assert.False(t, RoleViewer.CanManage())
+	assert.True(t, RoleEditor.CanWrite())
+	assert.False(t, RoleEditor.CanManage())
+	assert.True(t, RoleManager.CanWrite())
+	assert.True(t, RoleManager.CanManage())
 
-	t.Run("Value", func(t *testing.T) {
-		val, err := owners.Value()
-		assert.Equal(t, owners.String(), val)
-		require.NoError(t, err)
-	})
+	require.NoError(t, RoleEditor.Validate())
+	require.EqualError(t, Role("owner").Validate(), "must be a valid value")
 }
 
 func Test_CreateCmd_Validate(t *testing.T) {
 	require.EqualError(t, CreateCmd{
-		User:   &users.ExampleAlice,
-		Name:   "My space",
-		Owners: []uuid.UUID{"some-invalid-uuid"},
-	}.Validate(), "Owners: (0: must be a valid UUID v4.).")
+		User:     &users.ExampleAlice,
+		Name:     "My space",
+		Managers: []uuid.UUID{"some-invalid-uuid"},
+	}.Validate(), "Managers: (0: must be a valid UUID v4.).")
 }
 
-func Test_AddOwnerCmd_Validate(t *testing.T) {
-	require.EqualError(t, AddOwnerCmd{
+func Test_AddMemberCmd_Validate(t *testing.T) {
+	require.EqualError(t, AddMemberCmd{
 		User:    &users.ExampleAlice,
-		Owner:   &users.ExampleAlice,
-		SpaceID: "",
+		Member:  &users.ExampleBob,
+		SpaceID: ExampleAlicePersonalSpace.ID(),
+		Role:    Role("owner"),
+	}.Validate(), "Role: must be a valid value.")
+}
+
+func Test_SetMemberRoleCmd_Validate(t *testing.T) {
+	require.EqualError(t, SetMemberRoleCmd{
+		User:     &users.ExampleAlice,
+		MemberID: users.ExampleBob.ID(),
+		SpaceID:  "",
+		Role:     RoleEditor,
 	}.Validate(), "SpaceID: cannot be blank.")
 }
 
-func Test_RemoveOwnerCmd_Validate(t *testing.T) {
-	require.EqualError(t, RemoveOwnerCmd{
-		User:    &users.ExampleAlice,
-		Owner:   &users.ExampleAlice,
-		SpaceID: "",
+func Test_RemoveMemberCmd_Validate(t *testing.T) {
+	require.EqualError(t, RemoveMemberCmd{
+		User:     &users.ExampleAlice,
+		MemberID: users.ExampleBob.ID(),
+		SpaceID:  "",
 	}.Validate(), "SpaceID: cannot be blank.")
 }
@@ -4,8 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"slices"
 
+	"github.com/theduckcompany/duckcloud/internal/service/groups"
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools"
@@ -21,16 +21,34 @@ var (
 	ErrInvalidRootFS      = errors.New("invalid rootFS")
 	ErrNotFound           = errors.New("space not found")
 	ErrInvalidSpaceAccess = errors.New("no access to space")
+	ErrNotManager         = errors.New("not a space manager")
+	ErrAlreadyMember      = errors.New("already a member of the space")
+	ErrMemberNotFound     = errors.New("member not found")
+	ErrAlreadyGroup       = errors.New("the group already have an access to the space")
 )
 
 //go:generate mockery --name storage
 type storage interface {
 	Save(ctx context.Context, space *Space) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Space, error)
-	GetAllUserSpaces(ctx context.Context, userID uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]Space, error)
+	GetAllUserSpaces(ctx context.Context, userID uuid.UUID, groupIDs []uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]Space, error)
 	GetAllSpaces(ctx context.Context, cmd *sqlstorage.PaginateCmd) ([]Space, error)
 	Delete(ctx context.Context, spaceID uuid.UUID) error
 	Patch(ctx context.Context, spaceID uuid.UUID, fields map[string]any) error
+
+	SaveMember(ctx context.Context, member *Member) error
+	GetMember(ctx context.Context, spaceID, userID uuid.UUID) (*Member, error)
+	GetAllMembers(ctx context.Context, spaceID uuid.UUID) ([]Member, error)
+	PatchMember(ctx context.Context, spaceID, userID uuid.UUID, fields map[string]any) error
+	DeleteMember(ctx context.Context, spaceID, userID uuid.UUID) error
+	DeleteAllMembers(ctx context.Context, spaceID uuid.UUID) error
+
+	SaveGroup(ctx context.Context, access *GroupAccess) error
+	GetGroup(ctx context.Context, spaceID, groupID uuid.UUID) (*GroupAccess, error)
+	GetAllGroups(ctx context.Context, spaceID uuid.UUID) ([]GroupAccess, error)
+	DeleteGroup(ctx context.Context, spaceID, groupID uuid.UUID) error
+	DeleteAllGroups(ctx context.Context, spaceID uuid.UUID) error
+	DeleteGroupFromAllSpaces(ctx context.Context, groupID uuid.UUID) error
 }
 
 type service struct {
@@ -38,10 +56,11 @@ type service struct {
 	clock     clock.Clock
 	uuid      uuid.Service
 	scheduler scheduler.Service
+	groups    groups.Service
 }
 
-func newService(tools tools.Tools, storage storage, scheduler scheduler.Service) *service {
-	return &service{storage, tools.Clock(), tools.UUID(), scheduler}
+func newService(tools tools.Tools, storage storage, scheduler scheduler.Service, groups groups.Service) *service {
+	return &service{storage, tools.Clock(), tools.UUID(), scheduler, groups}
 }
 
 func (s *service) GetAllSpaces(ctx context.Context, user *users.User, cmd *sqlstorage.PaginateCmd) ([]Space, error) {
@@ -62,24 +81,14 @@ func (s *service) Create(ctx context.Context, cmd *CreateCmd) (*Space, error) {
 		return nil, errs.ErrUnauthorized
 	}
 
-	// Ensure that the owners are set only once.
-	uniqOwnersMap := make(map[uuid.UUID]struct{})
-	for _, owner := range cmd.Owners {
-		uniqOwnersMap[owner] = struct{}{}
-	}
-
-	uniqOwners := []uuid.UUID{}
-	for owner := range uniqOwnersMap {
-		uniqOwners = append(uniqOwners, owner)
-	}
-
 	now := s.clock.Now()
 	space := Space{
-		id:        s.uuid.New(),
-		name:      cmd.Name,
-		owners:    uniqOwners,
-		createdAt: now,
-		createdBy: cmd.User.ID(),
+		id:             s.uuid.New(),
+		name:           cmd.Name,
+		createdAt:      now,
+		createdBy:      cmd.User.ID(),
+		trashRetention: DefaultTrashRetention,
+		maxVersions:    DefaultMaxVersions,
 	}
 
 	err = s.storage.Save(context.WithoutCancel(ctx), &space)
@@ -87,6 +96,27 @@ func (s *service) Create(ctx context.Context, cmd *CreateCmd) (*Space, error) {
 		return nil, errs.Internal(fmt.Errorf("failed to Save the space: %w", err))
 	}
 
+	// Ensure that the managers are set only once.
+	saved := make(map[uuid.UUID]struct{}, len(cmd.Managers))
+	for _, userID := range cmd.Managers {
+		if _, ok := saved[userID]; ok {
+			continue
+		}
+
+		saved[userID] = struct{}{}
+
+		err = s.storage.SaveMember(context.WithoutCancel(ctx), &Member{
+			spaceID:   space.id,
+			userID:    userID,
+			role:      RoleManager,
+			createdAt: now,
+			createdBy: cmd.User.ID(),
+		})
+		if err != nil {
+			return nil, errs.Internal(fmt.Errorf("failed to SaveMember: %w", err))
+		}
+	}
+
 	return &space, nil
 }
 
@@ -95,7 +125,17 @@ func (s *service) Delete(ctx context.Context, user *users.User, spaceID uuid.UUI
 		return errs.Unauthorized(fmt.Errorf("%q is not an admin", user.Username()))
 	}
 
-	err := s.storage.Delete(ctx, spaceID)
+	err := s.storage.DeleteAllMembers(ctx, spaceID)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to DeleteAllMembers: %w", err))
+	}
+
+	err = s.storage.DeleteAllGroups(ctx, spaceID)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to DeleteAllGroups: %w", err))
+	}
+
+	err = s.storage.Delete(ctx, spaceID)
 	if err != nil {
 		return errs.Internal(fmt.Errorf("failed to Delete: %w", err))
 	}
@@ -115,8 +155,20 @@ func (s *service) GetByID(ctx context.Context, spaceID uuid.UUID) (*Space, error
 	return res, nil
 }
 
+// GetAllUserSpaces returns the spaces where the user is a member, directly or
+// via one of its groups.
 func (s *service) GetAllUserSpaces(ctx context.Context, userID uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]Space, error) {
-	res, err := s.storage.GetAllUserSpaces(ctx, userID, cmd)
+	userGroups, err := s.groups.GetAllUserGroups(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetAllUserGroups: %w", err)
+	}
+
+	groupIDs := make([]uuid.UUID, len(userGroups))
+	for i, group := range userGroups {
+		groupIDs[i] = group.ID()
+	}
+
+	res, err := s.storage.GetAllUserSpaces(ctx, userID, groupIDs, cmd)
 	if err != nil {
 		return nil, errs.Internal(err)
 	}
@@ -134,60 +186,376 @@ func (s *service) GetUserSpace(ctx context.Context, userID, spaceID uuid.UUID) (
 		return nil, errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
 	}
 
-	if !slices.Contains[[]uuid.UUID, uuid.UUID](space.Owners(), userID) {
-		return nil, errs.Unauthorized(ErrInvalidSpaceAccess)
+	_, err = s.GetUserRole(ctx, userID, spaceID)
+	if err != nil {
+		return nil, err
 	}
 
 	return space, nil
 }
 
-func (s *service) AddOwner(ctx context.Context, cmd *AddOwnerCmd) (*Space, error) {
+// GetUserRole returns the role of the user inside the given space. A direct
+// membership takes precedence over the groups, otherwise the best role given
+// by the user's groups is used. An [errs.ErrUnauthorized] error is returned if
+// the user has no access at all.
+func (s *service) GetUserRole(ctx context.Context, userID, spaceID uuid.UUID) (Role, error) {
+	member, err := s.storage.GetMember(ctx, spaceID, userID)
+	if err == nil {
+		return member.Role(), nil
+	}
+
+	if !errors.Is(err, errNotFound) {
+		return "", errs.Internal(fmt.Errorf("failed to GetMember: %w", err))
+	}
+
+	userGroups, err := s.groups.GetAllUserGroups(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to GetAllUserGroups: %w", err)
+	}
+
+	if len(userGroups) == 0 {
+		return "", errs.Unauthorized(ErrInvalidSpaceAccess)
+	}
+
+	accesses, err := s.storage.GetAllGroups(ctx, spaceID)
+	if err != nil {
+		return "", errs.Internal(fmt.Errorf("failed to GetAllGroups: %w", err))
+	}
+
+	var role Role
+	for _, access := range accesses {
+		for _, group := range userGroups {
+			if access.GroupID() == group.ID() {
+				role = access.Role().Max(role)
+			}
+		}
+	}
+
+	if role == "" {
+		return "", errs.Unauthorized(ErrInvalidSpaceAccess)
+	}
+
+	return role, nil
+}
+
+func (s *service) GetAllMembers(ctx context.Context, user *users.User, spaceID uuid.UUID) ([]Member, error) {
+	if !user.IsAdmin() {
+		_, err := s.GetUserRole(ctx, user.ID(), spaceID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := s.storage.GetAllMembers(ctx, spaceID)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllMembers: %w", err))
+	}
+
+	return res, nil
+}
+
+func (s *service) AddMember(ctx context.Context, cmd *AddMemberCmd) (*Member, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	err = s.ensureCanManage(ctx, cmd.User, cmd.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.storage.GetByID(ctx, cmd.SpaceID)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(err)
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
+	}
+
+	_, err = s.storage.GetMember(ctx, cmd.SpaceID, cmd.Member.ID())
+	if err == nil {
+		return nil, errs.BadRequest(ErrAlreadyMember)
+	}
+
+	if !errors.Is(err, errNotFound) {
+		return nil, errs.Internal(fmt.Errorf("failed to GetMember: %w", err))
+	}
+
+	member := Member{
+		spaceID:   cmd.SpaceID,
+		userID:    cmd.Member.ID(),
+		role:      cmd.Role,
+		createdAt: s.clock.Now(),
+		createdBy: cmd.User.ID(),
+	}
+
+	err = s.storage.SaveMember(ctx, &member)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to SaveMember: %w", err))
+	}
+
+	return &member, nil
+}
+
+func (s *service) SetMemberRole(ctx context.Context, cmd *SetMemberRoleCmd) (*Member, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	err = s.ensureCanManage(ctx, cmd.User, cmd.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.storage.GetMember(ctx, cmd.SpaceID, cmd.MemberID)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(ErrMemberNotFound)
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetMember: %w", err))
+	}
+
+	member.role = cmd.Role
+
+	err = s.storage.PatchMember(ctx, cmd.SpaceID, cmd.MemberID, map[string]any{"role": cmd.Role})
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to patch the member's role field: %w", err))
+	}
+
+	return member, nil
+}
+
+func (s *service) RemoveMember(ctx context.Context, cmd *RemoveMemberCmd) error {
+	err := cmd.Validate()
+	if err != nil {
+		return errs.Validation(err)
+	}
+
+	// Anyone can remove itself from a space but only the admins and the managers
+	// can remove an another user.
+	if cmd.User.ID() != cmd.MemberID {
+		err = s.ensureCanManage(ctx, cmd.User, cmd.SpaceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.storage.DeleteMember(ctx, cmd.SpaceID, cmd.MemberID)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to DeleteMember: %w", err))
+	}
+
+	return nil
+}
+
+// GetAllGroups returns all the groups having an access to the space.
+func (s *service) GetAllGroups(ctx context.Context, user *users.User, spaceID uuid.UUID) ([]GroupAccess, error) {
+	if !user.IsAdmin() {
+		_, err := s.GetUserRole(ctx, user.ID(), spaceID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := s.storage.GetAllGroups(ctx, spaceID)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetAllGroups: %w", err))
+	}
+
+	return res, nil
+}
+
+// AddGroup gives the given role to all the members of a group. The changes
+// in the group membership are applied immediately.
+func (s *service) AddGroup(ctx context.Context, cmd *AddGroupCmd) (*GroupAccess, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	err = s.ensureCanManage(ctx, cmd.User, cmd.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.storage.GetByID(ctx, cmd.SpaceID)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(err)
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
+	}
+
+	_, err = s.groups.GetByID(ctx, cmd.GroupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the group: %w", err)
+	}
+
+	_, err = s.storage.GetGroup(ctx, cmd.SpaceID, cmd.GroupID)
+	if err == nil {
+		return nil, errs.BadRequest(ErrAlreadyGroup)
+	}
+
+	if !errors.Is(err, errNotFound) {
+		return nil, errs.Internal(fmt.Errorf("failed to GetGroup: %w", err))
+	}
+
+	access := GroupAccess{
+		spaceID:   cmd.SpaceID,
+		groupID:   cmd.GroupID,
+		role:      cmd.Role,
+		createdAt: s.clock.Now(),
+		createdBy: cmd.User.ID(),
+	}
+
+	err = s.storage.SaveGroup(ctx, &access)
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to SaveGroup: %w", err))
+	}
+
+	return &access, nil
+}
+
+func (s *service) RemoveGroup(ctx context.Context, cmd *RemoveGroupCmd) error {
+	err := cmd.Validate()
+	if err != nil {
+		return errs.Validation(err)
+	}
+
+	err = s.ensureCanManage(ctx, cmd.User, cmd.SpaceID)
+	if err != nil {
+		return err
+	}
+
+	err = s.storage.DeleteGroup(ctx, cmd.SpaceID, cmd.GroupID)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to DeleteGroup: %w", err))
+	}
+
+	return nil
+}
+
+// RemoveGroupFromAllSpaces removes the group accesses on all the spaces. It is
+// used during the group deletion.
+func (s *service) RemoveGroupFromAllSpaces(ctx context.Context, groupID uuid.UUID) error {
+	err := s.storage.DeleteGroupFromAllSpaces(ctx, groupID)
+	if err != nil {
+		return errs.Internal(fmt.Errorf("failed to DeleteGroupFromAllSpaces: %w", err))
+	}
+
+	return nil
+}
+
+func (s *service) ensureCanManage(ctx context.Context, user *users.User, spaceID uuid.UUID) error {
+	if user.IsAdmin() {
+		return nil
+	}
+
+	role, err := s.GetUserRole(ctx, user.ID(), spaceID)
+	if err != nil {
+		return err
+	}
+
+	if !role.CanManage() {
+		return errs.Unauthorized(ErrNotManager)
+	}
+
+	return nil
+}
+
+func (s *service) SetTrashRetention(ctx context.Context, cmd *SetTrashRetentionCmd) (*Space, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
 	if !cmd.User.IsAdmin() {
 		return nil, errs.ErrUnauthorized
 	}
 
 	space, err := s.storage.GetByID(ctx, cmd.SpaceID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get the space: %w", err)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(err)
 	}
 
-	currentOwners := space.Owners()
-	if slices.Contains[[]uuid.UUID, uuid.UUID](currentOwners, cmd.Owner.ID()) {
-		return space, nil
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
 	}
 
-	space.owners = append(currentOwners, cmd.Owner.ID())
+	space.trashRetention = cmd.Retention
 
-	err = s.storage.Patch(ctx, space.ID(), map[string]any{"owners": space.owners})
+	err = s.storage.Patch(ctx, space.ID(), map[string]any{"trash_retention": int64(cmd.Retention.Seconds())})
 	if err != nil {
-		return nil, fmt.Errorf("failed to patch the space's owners field: %w", err)
+		return nil, errs.Internal(fmt.Errorf("failed to patch the space's trash_retention field: %w", err))
 	}
 
 	return space, nil
 }
 
-func (s *service) RemoveOwner(ctx context.Context, cmd *RemoveOwnerCmd) (*Space, error) {
-	// Anyone can remove itself from a space but only the admin can remove an another user.
-	if !cmd.User.IsAdmin() && cmd.User != cmd.Owner {
+func (s *service) SetVersionsPolicy(ctx context.Context, cmd *SetVersionsPolicyCmd) (*Space, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	if !cmd.User.IsAdmin() {
 		return nil, errs.ErrUnauthorized
 	}
 
 	space, err := s.storage.GetByID(ctx, cmd.SpaceID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get the space: %w", err)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(err)
 	}
 
-	if !slices.Contains(space.Owners(), cmd.Owner.ID()) {
-		return space, nil
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
 	}
 
-	space.owners = slices.DeleteFunc(space.Owners(), func(id uuid.UUID) bool {
-		return id == cmd.Owner.ID()
+	space.maxVersions = cmd.MaxVersions
+	space.versionsRetention = cmd.Retention
+
+	err = s.storage.Patch(ctx, space.ID(), map[string]any{
+		"max_versions":       cmd.MaxVersions,
+		"versions_retention": int64(cmd.Retention.Seconds()),
 	})
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to patch the space's versions policy: %w", err))
+	}
+
+	return space, nil
+}
+
+// SetQuota sets the maximum number of bytes stored inside the space. A zero
+// quota removes the limit.
+func (s *service) SetQuota(ctx context.Context, cmd *SetQuotaCmd) (*Space, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	if !cmd.User.IsAdmin() {
+		return nil, errs.ErrUnauthorized
+	}
+
+	space, err := s.storage.GetByID(ctx, cmd.SpaceID)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(err)
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
+	}
+
+	space.quota = cmd.Quota
 
-	err = s.storage.Patch(ctx, space.ID(), map[string]any{"owners": space.owners})
+	err = s.storage.Patch(ctx, space.ID(), map[string]any{"quota": cmd.Quota})
 	if err != nil {
-		return nil, fmt.Errorf("failed to patch the space's owners field: %w", err)
+		return nil, errs.Internal(fmt.Errorf("failed to patch the space's quota field: %w", err))
 	}
 
 	return space, nil
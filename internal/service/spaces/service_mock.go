@@ -18,24 +18,50 @@ type MockService struct {
 	mock.Mock
 }
 
-// AddOwner provides a mock function with given fields: ctx, cmd
-func (_m *MockService) AddOwner(ctx context.Context, cmd *AddOwnerCmd) (*Space, error) {
+// AddGroup provides a mock function with given fields: ctx, cmd
+func (_m *MockService) AddGroup(ctx context.Context, cmd *AddGroupCmd) (*GroupAccess, error) {
 	ret := _m.Called(ctx, cmd)
 
-	var r0 *Space
+	var r0 *GroupAccess
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *AddOwnerCmd) (*Space, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *AddGroupCmd) (*GroupAccess, error)); ok {
 		return rf(ctx, cmd)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *AddOwnerCmd) *Space); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *AddGroupCmd) *GroupAccess); ok {
 		r0 = rf(ctx, cmd)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*Space)
+			r0 = ret.Get(0).(*GroupAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *AddGroupCmd) error); ok {
+		r1 = rf(ctx, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddMember provides a mock function with given fields: ctx, cmd
+func (_m *MockService) AddMember(ctx context.Context, cmd *AddMemberCmd) (*Member, error) {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 *Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *AddMemberCmd) (*Member, error)); ok {
+		return rf(ctx, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *AddMemberCmd) *Member); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Member)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *AddOwnerCmd) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, *AddMemberCmd) error); ok {
 		r1 = rf(ctx, cmd)
 	} else {
 		r1 = ret.Error(1)
@@ -98,6 +124,58 @@ func (_m *MockService) Delete(ctx context.Context, user *users.User, spaceID uui
 	return r0
 }
 
+// GetAllGroups provides a mock function with given fields: ctx, user, spaceID
+func (_m *MockService) GetAllGroups(ctx context.Context, user *users.User, spaceID uuid.UUID) ([]GroupAccess, error) {
+	ret := _m.Called(ctx, user, spaceID)
+
+	var r0 []GroupAccess
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, uuid.UUID) ([]GroupAccess, error)); ok {
+		return rf(ctx, user, spaceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, uuid.UUID) []GroupAccess); ok {
+		r0 = rf(ctx, user, spaceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]GroupAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *users.User, uuid.UUID) error); ok {
+		r1 = rf(ctx, user, spaceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllMembers provides a mock function with given fields: ctx, user, spaceID
+func (_m *MockService) GetAllMembers(ctx context.Context, user *users.User, spaceID uuid.UUID) ([]Member, error) {
+	ret := _m.Called(ctx, user, spaceID)
+
+	var r0 []Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, uuid.UUID) ([]Member, error)); ok {
+		return rf(ctx, user, spaceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *users.User, uuid.UUID) []Member); ok {
+		r0 = rf(ctx, user, spaceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Member)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *users.User, uuid.UUID) error); ok {
+		r1 = rf(ctx, user, spaceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllSpaces provides a mock function with given fields: ctx, user, cmd
 func (_m *MockService) GetAllSpaces(ctx context.Context, user *users.User, cmd *sqlstorage.PaginateCmd) ([]Space, error) {
 	ret := _m.Called(ctx, user, cmd)
@@ -176,6 +254,30 @@ func (_m *MockService) GetByID(ctx context.Context, spaceID uuid.UUID) (*Space,
 	return r0, r1
 }
 
+// GetUserRole provides a mock function with given fields: ctx, userID, spaceID
+func (_m *MockService) GetUserRole(ctx context.Context, userID uuid.UUID, spaceID uuid.UUID) (Role, error) {
+	ret := _m.Called(ctx, userID, spaceID)
+
+	var r0 Role
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (Role, error)); ok {
+		return rf(ctx, userID, spaceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) Role); ok {
+		r0 = rf(ctx, userID, spaceID)
+	} else {
+		r0 = ret.Get(0).(Role)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, userID, spaceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserSpace provides a mock function with given fields: ctx, userID, spaceID
 func (_m *MockService) GetUserSpace(ctx context.Context, userID uuid.UUID, spaceID uuid.UUID) (*Space, error) {
 	ret := _m.Called(ctx, userID, spaceID)
@@ -202,16 +304,136 @@ func (_m *MockService) GetUserSpace(ctx context.Context, userID uuid.UUID, space
 	return r0, r1
 }
 
-// RemoveOwner provides a mock function with given fields: ctx, cmd
-func (_m *MockService) RemoveOwner(ctx context.Context, cmd *RemoveOwnerCmd) (*Space, error) {
+// RemoveGroup provides a mock function with given fields: ctx, cmd
+func (_m *MockService) RemoveGroup(ctx context.Context, cmd *RemoveGroupCmd) error {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *RemoveGroupCmd) error); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveGroupFromAllSpaces provides a mock function with given fields: ctx, groupID
+func (_m *MockService) RemoveGroupFromAllSpaces(ctx context.Context, groupID uuid.UUID) error {
+	ret := _m.Called(ctx, groupID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, groupID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveMember provides a mock function with given fields: ctx, cmd
+func (_m *MockService) RemoveMember(ctx context.Context, cmd *RemoveMemberCmd) error {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *RemoveMemberCmd) error); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetMemberRole provides a mock function with given fields: ctx, cmd
+func (_m *MockService) SetMemberRole(ctx context.Context, cmd *SetMemberRoleCmd) (*Member, error) {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 *Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *SetMemberRoleCmd) (*Member, error)); ok {
+		return rf(ctx, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *SetMemberRoleCmd) *Member); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Member)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *SetMemberRoleCmd) error); ok {
+		r1 = rf(ctx, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetQuota provides a mock function with given fields: ctx, cmd
+func (_m *MockService) SetQuota(ctx context.Context, cmd *SetQuotaCmd) (*Space, error) {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 *Space
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *SetQuotaCmd) (*Space, error)); ok {
+		return rf(ctx, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *SetQuotaCmd) *Space); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Space)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *SetQuotaCmd) error); ok {
+		r1 = rf(ctx, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetTrashRetention provides a mock function with given fields: ctx, cmd
+func (_m *MockService) SetTrashRetention(ctx context.Context, cmd *SetTrashRetentionCmd) (*Space, error) {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 *Space
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *SetTrashRetentionCmd) (*Space, error)); ok {
+		return rf(ctx, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *SetTrashRetentionCmd) *Space); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Space)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *SetTrashRetentionCmd) error); ok {
+		r1 = rf(ctx, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetVersionsPolicy provides a mock function with given fields: ctx, cmd
+func (_m *MockService) SetVersionsPolicy(ctx context.Context, cmd *SetVersionsPolicyCmd) (*Space, error) {
 	ret := _m.Called(ctx, cmd)
 
 	var r0 *Space
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *RemoveOwnerCmd) (*Space, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *SetVersionsPolicyCmd) (*Space, error)); ok {
 		return rf(ctx, cmd)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *RemoveOwnerCmd) *Space); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *SetVersionsPolicyCmd) *Space); ok {
 		r0 = rf(ctx, cmd)
 	} else {
 		if ret.Get(0) != nil {
@@ -219,7 +441,7 @@ func (_m *MockService) RemoveOwner(ctx context.Context, cmd *RemoveOwnerCmd) (*S
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *RemoveOwnerCmd) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, *SetVersionsPolicyCmd) error); ok {
 		r1 = rf(ctx, cmd)
 	} else {
 		r1 = ret.Error(1)
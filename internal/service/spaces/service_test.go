@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/groups"
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools"
@@ -27,7 +28,8 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		now := time.Now()
@@ -35,7 +37,6 @@ func Test_SpaceService(t *testing.T) {
 		someSpace := NewFakeSpace(t).
 			CreatedBy(user).
 			CreatedAt(now).
-			WithOwners(*user).
 			WithName("Donald's space").
 			Build()
 
@@ -43,12 +44,19 @@ func Test_SpaceService(t *testing.T) {
 		tools.UUIDMock.On("New").Return(someSpace.ID()).Once()
 		tools.ClockMock.On("Now").Return(now).Once()
 		storageMock.On("Save", mock.Anything, someSpace).Return(nil).Once()
+		storageMock.On("SaveMember", mock.Anything, &Member{
+			spaceID:   someSpace.ID(),
+			userID:    user.ID(),
+			role:      RoleManager,
+			createdAt: now,
+			createdBy: user.ID(),
+		}).Return(nil).Once()
 
 		// Run
 		res, err := svc.Create(ctx, &CreateCmd{
-			User:   user,
-			Name:   "Donald's space",
-			Owners: []uuid.UUID{user.ID()},
+			User:     user,
+			Name:     "Donald's space",
+			Managers: []uuid.UUID{user.ID(), user.ID()},
 		})
 
 		// Asserts
@@ -62,16 +70,17 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
 
 		// Run
 		res, err := svc.Create(ctx, &CreateCmd{
-			User:   user,
-			Name:   "",
-			Owners: []uuid.UUID{},
+			User:     user,
+			Name:     "",
+			Managers: []uuid.UUID{},
 		})
 
 		// Asserts
@@ -86,15 +95,16 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		notAnAdminUser := users.NewFakeUser(t).Build()
 
 		res, err := svc.Create(ctx, &CreateCmd{
-			User:   notAnAdminUser,
-			Name:   "Donald's space",
-			Owners: []uuid.UUID{},
+			User:     notAnAdminUser,
+			Name:     "Donald's space",
+			Managers: []uuid.UUID{},
 		})
 		assert.Nil(t, res)
 		require.ErrorIs(t, err, errs.ErrUnauthorized)
@@ -106,7 +116,8 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		now := time.Now()
@@ -124,9 +135,9 @@ func Test_SpaceService(t *testing.T) {
 
 		// Run
 		res, err := svc.Create(ctx, &CreateCmd{
-			User:   user,
-			Name:   "Some space",
-			Owners: []uuid.UUID{},
+			User:     user,
+			Name:     "Some space",
+			Managers: []uuid.UUID{},
 		})
 
 		// Asserts
@@ -141,14 +152,42 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
 		someSpace := NewFakeSpace(t).Build()
 
 		// Mocks
-		storageMock.On("GetAllUserSpaces", mock.Anything, user.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]Space{*someSpace}, nil).Once()
+		groupsMock.On("GetAllUserGroups", mock.Anything, user.ID()).Return([]groups.Group{}, nil).Once()
+		storageMock.On("GetAllUserSpaces", mock.Anything, user.ID(), []uuid.UUID{}, (*sqlstorage.PaginateCmd)(nil)).Return([]Space{*someSpace}, nil).Once()
+
+		// Run
+		res, err := svc.GetAllUserSpaces(ctx, user.ID(), nil)
+
+		// Asserts
+		require.NoError(t, err)
+		assert.EqualValues(t, []Space{*someSpace}, res)
+	})
+
+	t.Run("GetAlluserSpaces with the user groups", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someGroup := groups.NewFakeGroup(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		groupsMock.On("GetAllUserGroups", mock.Anything, user.ID()).Return([]groups.Group{*someGroup}, nil).Once()
+		storageMock.On("GetAllUserSpaces", mock.Anything, user.ID(), []uuid.UUID{someGroup.ID()}, (*sqlstorage.PaginateCmd)(nil)).Return([]Space{*someSpace}, nil).Once()
 
 		// Run
 		res, err := svc.GetAllUserSpaces(ctx, user.ID(), nil)
@@ -164,13 +203,15 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
 
 		// Mocks
-		storageMock.On("GetAllUserSpaces", mock.Anything, user.ID(), (*sqlstorage.PaginateCmd)(nil)).Return(nil, fmt.Errorf("some-error")).Once()
+		groupsMock.On("GetAllUserGroups", mock.Anything, user.ID()).Return([]groups.Group{}, nil).Once()
+		storageMock.On("GetAllUserSpaces", mock.Anything, user.ID(), []uuid.UUID{}, (*sqlstorage.PaginateCmd)(nil)).Return(nil, fmt.Errorf("some-error")).Once()
 
 		// Run
 		res, err := svc.GetAllUserSpaces(ctx, user.ID(), nil)
@@ -187,7 +228,8 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		someSpace := NewFakeSpace(t).Build()
@@ -209,7 +251,8 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		someSpace := NewFakeSpace(t).Build()
@@ -231,7 +274,8 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		someSpace := NewFakeSpace(t).Build()
@@ -254,13 +298,16 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		someSpace := NewFakeSpace(t).Build()
 		user := users.NewFakeUser(t).WithAdminRole().Build()
 
 		// Mocks
+		storageMock.On("DeleteAllMembers", mock.Anything, someSpace.ID()).Return(nil).Once()
+		storageMock.On("DeleteAllGroups", mock.Anything, someSpace.ID()).Return(nil).Once()
 		storageMock.On("Delete", mock.Anything, someSpace.ID()).Return(nil).Once()
 
 		// Run
@@ -276,7 +323,8 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		nontAdminUser := users.NewFakeUser(t).Build() // Not an admin
@@ -297,13 +345,16 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
 		someSpace := NewFakeSpace(t).Build()
 
 		// Mocks
+		storageMock.On("DeleteAllMembers", mock.Anything, someSpace.ID()).Return(nil).Once()
+		storageMock.On("DeleteAllGroups", mock.Anything, someSpace.ID()).Return(nil).Once()
 		storageMock.On("Delete", mock.Anything, someSpace.ID()).Return(fmt.Errorf("some-error"))
 
 		// Run
@@ -320,14 +371,17 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
-		someSpace := NewFakeSpace(t).WithOwners(*user).Build()
+		someSpace := NewFakeSpace(t).Build()
+		someMember := NewFakeMember(t, someSpace, user).Build()
 
 		// Mocks
 		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(someMember, nil).Once()
 
 		// Run
 		res, err := svc.GetUserSpace(ctx, user.ID(), someSpace.ID())
@@ -343,11 +397,12 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
-		someSpace := NewFakeSpace(t).WithOwners(*user).Build()
+		someSpace := NewFakeSpace(t).Build()
 
 		// Mocks
 		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(nil, errNotFound).Once()
@@ -366,11 +421,12 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
-		someSpace := NewFakeSpace(t).WithOwners(*user).Build()
+		someSpace := NewFakeSpace(t).Build()
 
 		// Mocks
 		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(nil, fmt.Errorf("some-error")).Once()
@@ -390,14 +446,16 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
-		user := users.NewFakeUser(t).WithAdminRole().Build()
-		someSpace := NewFakeSpace(t).WithOwners(*user).Build()
+		someSpace := NewFakeSpace(t).Build()
 
 		// Mocks
 		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), uuid.UUID("some-invalid-user-id")).Return(nil, errNotFound).Once()
+		groupsMock.On("GetAllUserGroups", mock.Anything, uuid.UUID("some-invalid-user-id")).Return([]groups.Group{}, nil).Once()
 
 		// Run
 		res, err := svc.GetUserSpace(ctx, uuid.UUID("some-invalid-user-id"), someSpace.ID())
@@ -414,11 +472,12 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
-		someSpace1 := NewFakeSpace(t).WithOwners(*user).Build()
+		someSpace1 := NewFakeSpace(t).Build()
 		someSpace2 := NewFakeSpace(t).Build()
 
 		// Mocks
@@ -439,7 +498,8 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		someNonAdminUser := users.NewFakeUser(t).Build()
@@ -452,326 +512,1356 @@ func Test_SpaceService(t *testing.T) {
 		require.ErrorIs(t, err, errs.ErrUnauthorized)
 	})
 
-	t.Run("RemoveOwner success", func(t *testing.T) {
+	t.Run("GetUserRole success", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
-		user := users.NewFakeUser(t).WithAdminRole().Build()
-		someSpace := NewFakeSpace(t).WithOwners(*user).Build()
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+		someMember := NewFakeMember(t, someSpace, user).WithRole(RoleEditor).Build()
 
 		// Mocks
-		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
-		storageMock.On("Patch", mock.Anything, someSpace.ID(), map[string]interface{}{
-			"owners": Owners{},
-		}).Return(nil).Once()
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(someMember, nil).Once()
 
 		// Run
-		res, err := svc.RemoveOwner(ctx, &RemoveOwnerCmd{
-			User:    user,
-			Owner:   user,
-			SpaceID: someSpace.ID(),
-		})
+		res, err := svc.GetUserRole(ctx, user.ID(), someSpace.ID())
 
 		// Asserts
 		require.NoError(t, err)
+		assert.Equal(t, RoleEditor, res)
+	})
+
+	t.Run("GetUserRole with a user not member", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
-		assert.Empty(t, res.owners)
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(nil, errNotFound).Once()
+		groupsMock.On("GetAllUserGroups", mock.Anything, user.ID()).Return([]groups.Group{}, nil).Once()
+
+		// Run
+		res, err := svc.GetUserRole(ctx, user.ID(), someSpace.ID())
+
+		// Asserts
+		assert.Empty(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrInvalidSpaceAccess)
 	})
 
-	t.Run("RemoveOwner with a non admin user", func(t *testing.T) {
+	t.Run("GetUserRole with a GetMember error", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
-		someNonAdminUser := users.NewFakeUser(t).Build()
-		someOtherUser := users.NewFakeUser(t).Build()
-		someSpace := NewFakeSpace(t).WithOwners(*someNonAdminUser).Build()
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
 
 		// Mocks
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(nil, fmt.Errorf("some-error")).Once()
 
 		// Run
-		res, err := svc.RemoveOwner(ctx, &RemoveOwnerCmd{
-			User:    someNonAdminUser,
-			Owner:   someOtherUser,
-			SpaceID: someSpace.id,
-		})
+		res, err := svc.GetUserRole(ctx, user.ID(), someSpace.ID())
 
 		// Asserts
-		assert.Nil(t, res)
+		assert.Empty(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("GetUserRole with the best role from the user groups", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+		someGroup := groups.NewFakeGroup(t).Build()
+		someOtherGroup := groups.NewFakeGroup(t).Build()
+		someUnrelatedGroup := groups.NewFakeGroup(t).Build()
+		groupAccess := NewFakeGroupAccess(t, someSpace, someGroup).WithRole(RoleEditor).Build()
+		otherGroupAccess := NewFakeGroupAccess(t, someSpace, someOtherGroup).WithRole(RoleViewer).Build()
+		unrelatedGroupAccess := NewFakeGroupAccess(t, someSpace, someUnrelatedGroup).WithRole(RoleManager).Build()
+
+		// Mocks
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(nil, errNotFound).Once()
+		groupsMock.On("GetAllUserGroups", mock.Anything, user.ID()).Return([]groups.Group{*someGroup, *someOtherGroup}, nil).Once()
+		storageMock.On("GetAllGroups", mock.Anything, someSpace.ID()).
+			Return([]GroupAccess{*otherGroupAccess, *unrelatedGroupAccess, *groupAccess}, nil).Once()
+
+		// Run
+		res, err := svc.GetUserRole(ctx, user.ID(), someSpace.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, RoleEditor, res)
+	})
+
+	t.Run("GetUserRole with groups without access to the space", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+		someGroup := groups.NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(nil, errNotFound).Once()
+		groupsMock.On("GetAllUserGroups", mock.Anything, user.ID()).Return([]groups.Group{*someGroup}, nil).Once()
+		storageMock.On("GetAllGroups", mock.Anything, someSpace.ID()).Return([]GroupAccess{}, nil).Once()
+
+		// Run
+		res, err := svc.GetUserRole(ctx, user.ID(), someSpace.ID())
+
+		// Asserts
+		assert.Empty(t, res)
 		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrInvalidSpaceAccess)
 	})
 
-	t.Run("RemoveOwner with a non admin user removing itself", func(t *testing.T) {
+	t.Run("GetUserRole with a GetAllUserGroups error", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
-		someNonAdminUser := users.NewFakeUser(t).Build()
-		someSpace := NewFakeSpace(t).WithOwners(*someNonAdminUser).Build()
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
 
 		// Mocks
-		storageMock.On("GetByID", mock.Anything, someSpace.ID()).
-			Return(someSpace, nil).Once()
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(nil, errNotFound).Once()
+		groupsMock.On("GetAllUserGroups", mock.Anything, user.ID()).Return(nil, errs.Internal(fmt.Errorf("some-error"))).Once()
 
-		storageMock.On("Patch", mock.Anything, someSpace.ID(), map[string]interface{}{
-			"owners": Owners{},
-		}).Return(nil).Once()
+		// Run
+		res, err := svc.GetUserRole(ctx, user.ID(), someSpace.ID())
+
+		// Asserts
+		assert.Empty(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("GetAllMembers success with an admin", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someOtherUser := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+		someMember := NewFakeMember(t, someSpace, someOtherUser).Build()
+
+		// Mocks
+		storageMock.On("GetAllMembers", mock.Anything, someSpace.ID()).Return([]Member{*someMember}, nil).Once()
 
 		// Run
-		res, err := svc.RemoveOwner(ctx, &RemoveOwnerCmd{
-			User:    someNonAdminUser,
-			Owner:   someNonAdminUser,
-			SpaceID: someSpace.id,
-		})
+		res, err := svc.GetAllMembers(ctx, user, someSpace.ID())
 
 		// Asserts
 		require.NoError(t, err)
-		assert.Empty(t, res.owners)
+		assert.Equal(t, []Member{*someMember}, res)
 	})
 
-	t.Run("RemoveOwner with a GetByID error", func(t *testing.T) {
+	t.Run("GetAllMembers with a user not member", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
-		someAdminUser := users.NewFakeUser(t).WithAdminRole().Build()
-		someSpace := NewFakeSpace(t).WithOwners(*someAdminUser).Build()
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
 
 		// Mocks
-		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(nil, errs.ErrInternal).Once()
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(nil, errNotFound).Once()
+		groupsMock.On("GetAllUserGroups", mock.Anything, user.ID()).Return([]groups.Group{}, nil).Once()
 
 		// Run
-		res, err := svc.RemoveOwner(ctx, &RemoveOwnerCmd{
-			User:    someAdminUser,
-			Owner:   someAdminUser,
-			SpaceID: someSpace.ID(),
-		})
+		res, err := svc.GetAllMembers(ctx, user, someSpace.ID())
 
 		// Asserts
 		assert.Nil(t, res)
-		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
 	})
 
-	t.Run("RemoveOwner with a user not present in perms", func(t *testing.T) {
+	t.Run("AddMember success with a manager", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
-		someAdminUser := users.NewFakeUser(t).WithAdminRole().Build()
+		now := time.Now()
+		user := users.NewFakeUser(t).Build()
 		someOtherUser := users.NewFakeUser(t).Build()
-		someSpace := NewFakeSpace(t).WithOwners(*someOtherUser).Build()
+		someSpace := NewFakeSpace(t).Build()
+		userMember := NewFakeMember(t, someSpace, user).WithRole(RoleManager).Build()
 
 		// Mocks
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(userMember, nil).Once()
 		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), someOtherUser.ID()).Return(nil, errNotFound).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("SaveMember", mock.Anything, &Member{
+			spaceID:   someSpace.ID(),
+			userID:    someOtherUser.ID(),
+			role:      RoleViewer,
+			createdAt: now,
+			createdBy: user.ID(),
+		}).Return(nil).Once()
 
 		// Run
-		res, err := svc.RemoveOwner(ctx, &RemoveOwnerCmd{
-			User:    someAdminUser,
-			Owner:   someAdminUser,
+		res, err := svc.AddMember(ctx, &AddMemberCmd{
+			User:    user,
+			Member:  someOtherUser,
 			SpaceID: someSpace.ID(),
+			Role:    RoleViewer,
 		})
 
 		// Asserts
 		require.NoError(t, err)
-		assert.Equal(t, Owners{someOtherUser.ID()}, res.owners) // nothing change
+		assert.Equal(t, RoleViewer, res.Role())
+		assert.Equal(t, someOtherUser.ID(), res.UserID())
 	})
 
-	t.Run("RemoveOwner with a Patch error", func(t *testing.T) {
+	t.Run("AddMember with a validation error", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
-		someSpace := NewFakeSpace(t).WithOwners(*user).Build()
+		someOtherUser := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
 
-		// Mocks
-		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		// Run
+		res, err := svc.AddMember(ctx, &AddMemberCmd{
+			User:    user,
+			Member:  someOtherUser,
+			SpaceID: someSpace.ID(),
+			Role:    Role("owner"),
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
+	t.Run("AddMember with an editor", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someOtherUser := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+		userMember := NewFakeMember(t, someSpace, user).WithRole(RoleEditor).Build()
 
-		storageMock.On("Patch", mock.Anything, someSpace.ID(), map[string]interface{}{"owners": Owners{}}).
-			Return(errs.ErrInternal).Once()
+		// Mocks
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(userMember, nil).Once()
 
 		// Run
-		res, err := svc.RemoveOwner(ctx, &RemoveOwnerCmd{
+		res, err := svc.AddMember(ctx, &AddMemberCmd{
 			User:    user,
-			Owner:   user,
+			Member:  someOtherUser,
 			SpaceID: someSpace.ID(),
+			Role:    RoleViewer,
 		})
 
 		// Asserts
 		assert.Nil(t, res)
-		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrNotManager)
 	})
 
-	t.Run("AddOwner success", func(t *testing.T) {
+	t.Run("AddMember with a space not found", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
 		someOtherUser := users.NewFakeUser(t).Build()
-		someSpace := NewFakeSpace(t).WithOwners(*user).Build()
+		someSpace := NewFakeSpace(t).Build()
 
 		// Mocks
-		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
-		storageMock.On("Patch", mock.Anything, someSpace.ID(), map[string]interface{}{
-			"owners": Owners{user.ID(), someOtherUser.ID()},
-		}).Return(nil).Once()
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(nil, errNotFound).Once()
 
 		// Run
-		res, err := svc.AddOwner(ctx, &AddOwnerCmd{
+		res, err := svc.AddMember(ctx, &AddMemberCmd{
 			User:    user,
-			Owner:   someOtherUser,
+			Member:  someOtherUser,
 			SpaceID: someSpace.ID(),
+			Role:    RoleViewer,
 		})
 
 		// Asserts
-		require.NoError(t, err)
-		assert.Equal(t, Owners{user.ID(), someOtherUser.ID()}, res.owners)
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
 	})
 
-	t.Run("AddOwner with a User not admin", func(t *testing.T) {
+	t.Run("AddMember with a user already member", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
-		someNonAdminUser := users.NewFakeUser(t).Build()
+		user := users.NewFakeUser(t).WithAdminRole().Build()
 		someOtherUser := users.NewFakeUser(t).Build()
 		someSpace := NewFakeSpace(t).Build()
+		someMember := NewFakeMember(t, someSpace, someOtherUser).Build()
 
 		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), someOtherUser.ID()).Return(someMember, nil).Once()
 
 		// Run
-		res, err := svc.AddOwner(ctx, &AddOwnerCmd{
-			User:    someNonAdminUser,
-			Owner:   someOtherUser,
+		res, err := svc.AddMember(ctx, &AddMemberCmd{
+			User:    user,
+			Member:  someOtherUser,
 			SpaceID: someSpace.ID(),
+			Role:    RoleEditor,
 		})
 
 		// Asserts
 		assert.Nil(t, res)
-		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrAlreadyMember)
 	})
 
-	t.Run("AddOwner with a GetByID error", func(t *testing.T) {
+	t.Run("AddMember with a SaveMember error", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
+		now := time.Now()
 		user := users.NewFakeUser(t).WithAdminRole().Build()
 		someOtherUser := users.NewFakeUser(t).Build()
 		someSpace := NewFakeSpace(t).Build()
 
 		// Mocks
-		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(nil, errs.ErrInternal).Once()
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), someOtherUser.ID()).Return(nil, errNotFound).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("SaveMember", mock.Anything, mock.Anything).Return(fmt.Errorf("some-error")).Once()
 
 		// Run
-		res, err := svc.AddOwner(ctx, &AddOwnerCmd{
+		res, err := svc.AddMember(ctx, &AddMemberCmd{
 			User:    user,
-			Owner:   someOtherUser,
+			Member:  someOtherUser,
 			SpaceID: someSpace.ID(),
+			Role:    RoleEditor,
 		})
 
 		// Asserts
 		assert.Nil(t, res)
 		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
 	})
 
-	t.Run("AddOwner with a user already present in perms", func(t *testing.T) {
+	t.Run("SetMemberRole success", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
 		someOtherUser := users.NewFakeUser(t).Build()
-		someSpace := NewFakeSpace(t).WithOwners(*someOtherUser).Build()
+		someSpace := NewFakeSpace(t).Build()
+		someMember := NewFakeMember(t, someSpace, someOtherUser).WithRole(RoleViewer).Build()
 
 		// Mocks
-		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), someOtherUser.ID()).Return(someMember, nil).Once()
+		storageMock.On("PatchMember", mock.Anything, someSpace.ID(), someOtherUser.ID(), map[string]any{"role": RoleEditor}).
+			Return(nil).Once()
 
 		// Run
-		res, err := svc.AddOwner(ctx, &AddOwnerCmd{
-			User:    user,
-			Owner:   someOtherUser,
-			SpaceID: someSpace.ID(),
+		res, err := svc.SetMemberRole(ctx, &SetMemberRoleCmd{
+			User:     user,
+			MemberID: someOtherUser.ID(),
+			SpaceID:  someSpace.ID(),
+			Role:     RoleEditor,
 		})
 
 		// Asserts
 		require.NoError(t, err)
-		assert.NotNil(t, res)
-		assert.Equal(t, Owners{someOtherUser.ID()}, res.owners) // nothing change
+		assert.Equal(t, RoleEditor, res.Role())
 	})
 
-	t.Run("AddOwner with a Patch error", func(t *testing.T) {
+	t.Run("SetMemberRole with a member not found", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
 		someOtherUser := users.NewFakeUser(t).Build()
-		someSpace := NewFakeSpace(t).WithOwners(*someOtherUser).Build()
+		someSpace := NewFakeSpace(t).Build()
 
 		// Mocks
-		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), someOtherUser.ID()).Return(nil, errNotFound).Once()
 
-		storageMock.On("Patch", mock.Anything, someSpace.ID(), map[string]interface{}{
-			"owners": Owners{someOtherUser.ID(), user.ID()},
-		}).Return(errs.ErrInternal).Once()
+		// Run
+		res, err := svc.SetMemberRole(ctx, &SetMemberRoleCmd{
+			User:     user,
+			MemberID: someOtherUser.ID(),
+			SpaceID:  someSpace.ID(),
+			Role:     RoleEditor,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		require.ErrorIs(t, err, ErrMemberNotFound)
+	})
+
+	t.Run("SetMemberRole with a viewer", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+		userMember := NewFakeMember(t, someSpace, user).WithRole(RoleViewer).Build()
+
+		// Mocks
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(userMember, nil).Once()
 
 		// Run
-		res, err := svc.AddOwner(ctx, &AddOwnerCmd{
-			User:    user,
-			Owner:   user,
-			SpaceID: someSpace.ID(),
+		res, err := svc.SetMemberRole(ctx, &SetMemberRoleCmd{
+			User:     user,
+			MemberID: user.ID(),
+			SpaceID:  someSpace.ID(),
+			Role:     RoleManager,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrNotManager)
+	})
+
+	t.Run("SetMemberRole with a PatchMember error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someOtherUser := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+		someMember := NewFakeMember(t, someSpace, someOtherUser).WithRole(RoleViewer).Build()
+
+		// Mocks
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), someOtherUser.ID()).Return(someMember, nil).Once()
+		storageMock.On("PatchMember", mock.Anything, someSpace.ID(), someOtherUser.ID(), map[string]any{"role": RoleEditor}).
+			Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := svc.SetMemberRole(ctx, &SetMemberRoleCmd{
+			User:     user,
+			MemberID: someOtherUser.ID(),
+			SpaceID:  someSpace.ID(),
+			Role:     RoleEditor,
 		})
 
 		// Asserts
 		assert.Nil(t, res)
 		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("RemoveMember success with a manager", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someOtherUser := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+		userMember := NewFakeMember(t, someSpace, user).WithRole(RoleManager).Build()
+
+		// Mocks
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(userMember, nil).Once()
+		storageMock.On("DeleteMember", mock.Anything, someSpace.ID(), someOtherUser.ID()).Return(nil).Once()
+
+		// Run
+		err := svc.RemoveMember(ctx, &RemoveMemberCmd{
+			User:     user,
+			MemberID: someOtherUser.ID(),
+			SpaceID:  someSpace.ID(),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("RemoveMember with a non manager user removing itself", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("DeleteMember", mock.Anything, someSpace.ID(), user.ID()).Return(nil).Once()
+
+		// Run
+		err := svc.RemoveMember(ctx, &RemoveMemberCmd{
+			User:     user,
+			MemberID: user.ID(),
+			SpaceID:  someSpace.ID(),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("RemoveMember with a non manager user", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someOtherUser := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(nil, errNotFound).Once()
+		groupsMock.On("GetAllUserGroups", mock.Anything, user.ID()).Return([]groups.Group{}, nil).Once()
+
+		// Run
+		err := svc.RemoveMember(ctx, &RemoveMemberCmd{
+			User:     user,
+			MemberID: someOtherUser.ID(),
+			SpaceID:  someSpace.ID(),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("RemoveMember with a DeleteMember error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someOtherUser := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("DeleteMember", mock.Anything, someSpace.ID(), someOtherUser.ID()).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		err := svc.RemoveMember(ctx, &RemoveMemberCmd{
+			User:     user,
+			MemberID: someOtherUser.ID(),
+			SpaceID:  someSpace.ID(),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("GetAllGroups success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+		userMember := NewFakeMember(t, someSpace, user).Build()
+		someGroup := groups.NewFakeGroup(t).Build()
+		groupAccess := NewFakeGroupAccess(t, someSpace, someGroup).Build()
+
+		// Mocks
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(userMember, nil).Once()
+		storageMock.On("GetAllGroups", mock.Anything, someSpace.ID()).Return([]GroupAccess{*groupAccess}, nil).Once()
+
+		// Run
+		res, err := svc.GetAllGroups(ctx, user, someSpace.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []GroupAccess{*groupAccess}, res)
+	})
+
+	t.Run("AddGroup success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		now := time.Now()
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+		someGroup := groups.NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		groupsMock.On("GetByID", mock.Anything, someGroup.ID()).Return(someGroup, nil).Once()
+		storageMock.On("GetGroup", mock.Anything, someSpace.ID(), someGroup.ID()).Return(nil, errNotFound).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+		storageMock.On("SaveGroup", mock.Anything, &GroupAccess{
+			spaceID:   someSpace.ID(),
+			groupID:   someGroup.ID(),
+			role:      RoleEditor,
+			createdAt: now,
+			createdBy: user.ID(),
+		}).Return(nil).Once()
+
+		// Run
+		res, err := svc.AddGroup(ctx, &AddGroupCmd{
+			User:    user,
+			GroupID: someGroup.ID(),
+			SpaceID: someSpace.ID(),
+			Role:    RoleEditor,
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, RoleEditor, res.Role())
+		assert.Equal(t, someGroup.ID(), res.GroupID())
+	})
+
+	t.Run("AddGroup with an editor", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+		userMember := NewFakeMember(t, someSpace, user).WithRole(RoleEditor).Build()
+		someGroup := groups.NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetMember", mock.Anything, someSpace.ID(), user.ID()).Return(userMember, nil).Once()
+
+		// Run
+		res, err := svc.AddGroup(ctx, &AddGroupCmd{
+			User:    user,
+			GroupID: someGroup.ID(),
+			SpaceID: someSpace.ID(),
+			Role:    RoleEditor,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		require.ErrorIs(t, err, ErrNotManager)
+	})
+
+	t.Run("AddGroup with a group not found", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+		someGroup := groups.NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		groupsMock.On("GetByID", mock.Anything, someGroup.ID()).Return(nil, errs.NotFound(groups.ErrNotFound)).Once()
+
+		// Run
+		res, err := svc.AddGroup(ctx, &AddGroupCmd{
+			User:    user,
+			GroupID: someGroup.ID(),
+			SpaceID: someSpace.ID(),
+			Role:    RoleEditor,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		require.ErrorIs(t, err, groups.ErrNotFound)
+	})
+
+	t.Run("AddGroup with a group already added", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+		someGroup := groups.NewFakeGroup(t).Build()
+		groupAccess := NewFakeGroupAccess(t, someSpace, someGroup).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		groupsMock.On("GetByID", mock.Anything, someGroup.ID()).Return(someGroup, nil).Once()
+		storageMock.On("GetGroup", mock.Anything, someSpace.ID(), someGroup.ID()).Return(groupAccess, nil).Once()
+
+		// Run
+		res, err := svc.AddGroup(ctx, &AddGroupCmd{
+			User:    user,
+			GroupID: someGroup.ID(),
+			SpaceID: someSpace.ID(),
+			Role:    RoleEditor,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrBadRequest)
+		require.ErrorIs(t, err, ErrAlreadyGroup)
+	})
+
+	t.Run("RemoveGroup success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+		someGroup := groups.NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("DeleteGroup", mock.Anything, someSpace.ID(), someGroup.ID()).Return(nil).Once()
+
+		// Run
+		err := svc.RemoveGroup(ctx, &RemoveGroupCmd{
+			User:    user,
+			GroupID: someGroup.ID(),
+			SpaceID: someSpace.ID(),
+		})
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("RemoveGroup with a DeleteGroup error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+		someGroup := groups.NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("DeleteGroup", mock.Anything, someSpace.ID(), someGroup.ID()).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		err := svc.RemoveGroup(ctx, &RemoveGroupCmd{
+			User:    user,
+			GroupID: someGroup.ID(),
+			SpaceID: someSpace.ID(),
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("RemoveGroupFromAllSpaces success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		someGroup := groups.NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("DeleteGroupFromAllSpaces", mock.Anything, someGroup.ID()).Return(nil).Once()
+
+		// Run
+		err := svc.RemoveGroupFromAllSpaces(ctx, someGroup.ID())
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("RemoveGroupFromAllSpaces with a DeleteGroupFromAllSpaces error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		someGroup := groups.NewFakeGroup(t).Build()
+
+		// Mocks
+		storageMock.On("DeleteGroupFromAllSpaces", mock.Anything, someGroup.ID()).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		err := svc.RemoveGroupFromAllSpaces(ctx, someGroup.ID())
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("SetTrashRetention success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("Patch", mock.Anything, someSpace.ID(), map[string]any{
+			"trash_retention": int64(7 * 24 * 3600),
+		}).Return(nil).Once()
+
+		// Run
+		res, err := svc.SetTrashRetention(ctx, &SetTrashRetentionCmd{
+			User:      user,
+			SpaceID:   someSpace.ID(),
+			Retention: 7 * 24 * time.Hour,
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, 7*24*time.Hour, res.TrashRetention())
+	})
+
+	t.Run("SetTrashRetention with a validation error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Run
+		res, err := svc.SetTrashRetention(ctx, &SetTrashRetentionCmd{
+			User:      user,
+			SpaceID:   someSpace.ID(),
+			Retention: -time.Hour,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
+	t.Run("SetTrashRetention with a non admin user", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Run
+		res, err := svc.SetTrashRetention(ctx, &SetTrashRetentionCmd{
+			User:      user,
+			SpaceID:   someSpace.ID(),
+			Retention: time.Hour,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("SetTrashRetention with a space not found", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(nil, errNotFound).Once()
+
+		// Run
+		res, err := svc.SetTrashRetention(ctx, &SetTrashRetentionCmd{
+			User:      user,
+			SpaceID:   someSpace.ID(),
+			Retention: time.Hour,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("SetTrashRetention with a Patch error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("Patch", mock.Anything, someSpace.ID(), map[string]any{
+			"trash_retention": int64(3600),
+		}).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := svc.SetTrashRetention(ctx, &SetTrashRetentionCmd{
+			User:      user,
+			SpaceID:   someSpace.ID(),
+			Retention: time.Hour,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("SetVersionsPolicy success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("Patch", mock.Anything, someSpace.ID(), map[string]any{
+			"max_versions":       5,
+			"versions_retention": int64(7 * 24 * 3600),
+		}).Return(nil).Once()
+
+		// Run
+		res, err := svc.SetVersionsPolicy(ctx, &SetVersionsPolicyCmd{
+			User:        user,
+			SpaceID:     someSpace.ID(),
+			MaxVersions: 5,
+			Retention:   7 * 24 * time.Hour,
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, 5, res.MaxVersions())
+		assert.Equal(t, 7*24*time.Hour, res.VersionsRetention())
+	})
+
+	t.Run("SetVersionsPolicy with a validation error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Run
+		res, err := svc.SetVersionsPolicy(ctx, &SetVersionsPolicyCmd{
+			User:        user,
+			SpaceID:     someSpace.ID(),
+			MaxVersions: -1,
+			Retention:   time.Hour,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
+	t.Run("SetVersionsPolicy with a non admin user", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Run
+		res, err := svc.SetVersionsPolicy(ctx, &SetVersionsPolicyCmd{
+			User:        user,
+			SpaceID:     someSpace.ID(),
+			MaxVersions: 5,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("SetVersionsPolicy with a space not found", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(nil, errNotFound).Once()
+
+		// Run
+		res, err := svc.SetVersionsPolicy(ctx, &SetVersionsPolicyCmd{
+			User:        user,
+			SpaceID:     someSpace.ID(),
+			MaxVersions: 5,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("SetVersionsPolicy with a Patch error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("Patch", mock.Anything, someSpace.ID(), map[string]any{
+			"max_versions":       5,
+			"versions_retention": int64(0),
+		}).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := svc.SetVersionsPolicy(ctx, &SetVersionsPolicyCmd{
+			User:        user,
+			SpaceID:     someSpace.ID(),
+			MaxVersions: 5,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+	})
+
+	t.Run("SetQuota success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("Patch", mock.Anything, someSpace.ID(), map[string]any{"quota": uint64(1024)}).Return(nil).Once()
+
+		// Run
+		res, err := svc.SetQuota(ctx, &SetQuotaCmd{
+			User:    user,
+			SpaceID: someSpace.ID(),
+			Quota:   1024,
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1024), res.Quota())
+	})
+
+	t.Run("SetQuota with a validation error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+
+		// Run
+		res, err := svc.SetQuota(ctx, &SetQuotaCmd{
+			User:    user,
+			SpaceID: uuid.UUID("some-invalid-id"),
+			Quota:   1024,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
+	t.Run("SetQuota with a non admin user", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Run
+		res, err := svc.SetQuota(ctx, &SetQuotaCmd{
+			User:    user,
+			SpaceID: someSpace.ID(),
+			Quota:   1024,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+
+	t.Run("SetQuota with a space not found", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(nil, errNotFound).Once()
+
+		// Run
+		res, err := svc.SetQuota(ctx, &SetQuotaCmd{
+			User:    user,
+			SpaceID: someSpace.ID(),
+			Quota:   1024,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrNotFound)
+	})
+
+	t.Run("SetQuota with a Patch error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		storageMock := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		someSpace := NewFakeSpace(t).Build()
+
+		// Mocks
+		storageMock.On("GetByID", mock.Anything, someSpace.ID()).Return(someSpace, nil).Once()
+		storageMock.On("Patch", mock.Anything, someSpace.ID(), map[string]any{"quota": uint64(0)}).
+			Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := svc.SetQuota(ctx, &SetQuotaCmd{
+			User:    user,
+			SpaceID: someSpace.ID(),
+			Quota:   0,
+		})
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
 	})
 
 	t.Run("Bootstrap success", func(t *testing.T) {
@@ -780,7 +1870,8 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -808,7 +1899,8 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -830,7 +1922,8 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -853,7 +1946,8 @@ func Test_SpaceService(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := newMockStorage(t)
 		schedulerMock := scheduler.NewMockService(t)
-		svc := newService(tools, storageMock, schedulerMock)
+		groupsMock := groups.NewMockService(t)
+		svc := newService(tools, storageMock, schedulerMock, groupsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -30,6 +30,128 @@ func (_m *mockStorage) Delete(ctx context.Context, spaceID uuid.UUID) error {
 	return r0
 }
 
+// DeleteAllGroups provides a mock function with given fields: ctx, spaceID
+func (_m *mockStorage) DeleteAllGroups(ctx context.Context, spaceID uuid.UUID) error {
+	ret := _m.Called(ctx, spaceID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, spaceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteAllMembers provides a mock function with given fields: ctx, spaceID
+func (_m *mockStorage) DeleteAllMembers(ctx context.Context, spaceID uuid.UUID) error {
+	ret := _m.Called(ctx, spaceID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, spaceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteGroup provides a mock function with given fields: ctx, spaceID, groupID
+func (_m *mockStorage) DeleteGroup(ctx context.Context, spaceID uuid.UUID, groupID uuid.UUID) error {
+	ret := _m.Called(ctx, spaceID, groupID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, spaceID, groupID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteGroupFromAllSpaces provides a mock function with given fields: ctx, groupID
+func (_m *mockStorage) DeleteGroupFromAllSpaces(ctx context.Context, groupID uuid.UUID) error {
+	ret := _m.Called(ctx, groupID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, groupID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteMember provides a mock function with given fields: ctx, spaceID, userID
+func (_m *mockStorage) DeleteMember(ctx context.Context, spaceID uuid.UUID, userID uuid.UUID) error {
+	ret := _m.Called(ctx, spaceID, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, spaceID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAllGroups provides a mock function with given fields: ctx, spaceID
+func (_m *mockStorage) GetAllGroups(ctx context.Context, spaceID uuid.UUID) ([]GroupAccess, error) {
+	ret := _m.Called(ctx, spaceID)
+
+	var r0 []GroupAccess
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]GroupAccess, error)); ok {
+		return rf(ctx, spaceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []GroupAccess); ok {
+		r0 = rf(ctx, spaceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]GroupAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, spaceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllMembers provides a mock function with given fields: ctx, spaceID
+func (_m *mockStorage) GetAllMembers(ctx context.Context, spaceID uuid.UUID) ([]Member, error) {
+	ret := _m.Called(ctx, spaceID)
+
+	var r0 []Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]Member, error)); ok {
+		return rf(ctx, spaceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []Member); ok {
+		r0 = rf(ctx, spaceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Member)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, spaceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllSpaces provides a mock function with given fields: ctx, cmd
 func (_m *mockStorage) GetAllSpaces(ctx context.Context, cmd *sqlstorage.PaginateCmd) ([]Space, error) {
 	ret := _m.Called(ctx, cmd)
@@ -56,25 +178,25 @@ func (_m *mockStorage) GetAllSpaces(ctx context.Context, cmd *sqlstorage.Paginat
 	return r0, r1
 }
 
-// GetAllUserSpaces provides a mock function with given fields: ctx, userID, cmd
-func (_m *mockStorage) GetAllUserSpaces(ctx context.Context, userID uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]Space, error) {
-	ret := _m.Called(ctx, userID, cmd)
+// GetAllUserSpaces provides a mock function with given fields: ctx, userID, groupIDs, cmd
+func (_m *mockStorage) GetAllUserSpaces(ctx context.Context, userID uuid.UUID, groupIDs []uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]Space, error) {
+	ret := _m.Called(ctx, userID, groupIDs, cmd)
 
 	var r0 []Space
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, *sqlstorage.PaginateCmd) ([]Space, error)); ok {
-		return rf(ctx, userID, cmd)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []uuid.UUID, *sqlstorage.PaginateCmd) ([]Space, error)); ok {
+		return rf(ctx, userID, groupIDs, cmd)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, *sqlstorage.PaginateCmd) []Space); ok {
-		r0 = rf(ctx, userID, cmd)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []uuid.UUID, *sqlstorage.PaginateCmd) []Space); ok {
+		r0 = rf(ctx, userID, groupIDs, cmd)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]Space)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, *sqlstorage.PaginateCmd) error); ok {
-		r1 = rf(ctx, userID, cmd)
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, []uuid.UUID, *sqlstorage.PaginateCmd) error); ok {
+		r1 = rf(ctx, userID, groupIDs, cmd)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -108,6 +230,58 @@ func (_m *mockStorage) GetByID(ctx context.Context, id uuid.UUID) (*Space, error
 	return r0, r1
 }
 
+// GetGroup provides a mock function with given fields: ctx, spaceID, groupID
+func (_m *mockStorage) GetGroup(ctx context.Context, spaceID uuid.UUID, groupID uuid.UUID) (*GroupAccess, error) {
+	ret := _m.Called(ctx, spaceID, groupID)
+
+	var r0 *GroupAccess
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (*GroupAccess, error)); ok {
+		return rf(ctx, spaceID, groupID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) *GroupAccess); ok {
+		r0 = rf(ctx, spaceID, groupID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*GroupAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, spaceID, groupID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMember provides a mock function with given fields: ctx, spaceID, userID
+func (_m *mockStorage) GetMember(ctx context.Context, spaceID uuid.UUID, userID uuid.UUID) (*Member, error) {
+	ret := _m.Called(ctx, spaceID, userID)
+
+	var r0 *Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (*Member, error)); ok {
+		return rf(ctx, spaceID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) *Member); ok {
+		r0 = rf(ctx, spaceID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Member)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, spaceID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Patch provides a mock function with given fields: ctx, spaceID, fields
 func (_m *mockStorage) Patch(ctx context.Context, spaceID uuid.UUID, fields map[string]interface{}) error {
 	ret := _m.Called(ctx, spaceID, fields)
@@ -122,6 +296,20 @@ func (_m *mockStorage) Patch(ctx context.Context, spaceID uuid.UUID, fields map[
 	return r0
 }
 
+// PatchMember provides a mock function with given fields: ctx, spaceID, userID, fields
+func (_m *mockStorage) PatchMember(ctx context.Context, spaceID uuid.UUID, userID uuid.UUID, fields map[string]interface{}) error {
+	ret := _m.Called(ctx, spaceID, userID, fields)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, map[string]interface{}) error); ok {
+		r0 = rf(ctx, spaceID, userID, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Save provides a mock function with given fields: ctx, space
 func (_m *mockStorage) Save(ctx context.Context, space *Space) error {
 	ret := _m.Called(ctx, space)
@@ -136,6 +324,34 @@ func (_m *mockStorage) Save(ctx context.Context, space *Space) error {
 	return r0
 }
 
+// SaveGroup provides a mock function with given fields: ctx, access
+func (_m *mockStorage) SaveGroup(ctx context.Context, access *GroupAccess) error {
+	ret := _m.Called(ctx, access)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *GroupAccess) error); ok {
+		r0 = rf(ctx, access)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveMember provides a mock function with given fields: ctx, member
+func (_m *mockStorage) SaveMember(ctx context.Context, member *Member) error {
+	ret := _m.Called(ctx, member)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *Member) error); ok {
+		r0 = rf(ctx, member)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // newMockStorage creates a new instance of mockStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func newMockStorage(t interface {
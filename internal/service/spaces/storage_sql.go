@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/theduckcompany/duckcloud/internal/tools"
@@ -18,7 +19,7 @@ const tableName = "spaces"
 
 var errNotFound = errors.New("not found")
 
-var allFields = []string{"id", "name", "owners", "created_at", "created_by"}
+var allFields = []string{"id", "name", "created_at", "created_by", "trash_retention", "max_versions", "versions_retention", "quota"}
 
 type sqlStorage struct {
 	db    sqlstorage.Querier
@@ -33,7 +34,14 @@ func (s *sqlStorage) Save(ctx context.Context, space *Space) error {
 	_, err := sq.
 		Insert(tableName).
 		Columns(allFields...).
-		Values(space.id, space.name, space.owners, ptr.To(sqlstorage.SQLTime(space.createdAt)), space.createdBy).
+		Values(space.id,
+			space.name,
+			ptr.To(sqlstorage.SQLTime(space.createdAt)),
+			space.createdBy,
+			int64(space.trashRetention.Seconds()),
+			space.maxVersions,
+			int64(space.versionsRetention.Seconds()),
+			space.quota).
 		RunWith(s.db).
 		ExecContext(ctx)
 	if err != nil {
@@ -47,8 +55,21 @@ func (s *sqlStorage) GetAllSpaces(ctx context.Context, cmd *sqlstorage.PaginateC
 	return s.getAllbyKeys(ctx, cmd)
 }
 
-func (s *sqlStorage) GetAllUserSpaces(ctx context.Context, userID uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]Space, error) {
-	return s.getAllbyKeys(ctx, cmd, sq.Like{"owners": fmt.Sprintf("%%%s%%", userID)})
+// GetAllUserSpaces returns the spaces where the user is a member or where one
+// of the given groups have an access.
+func (s *sqlStorage) GetAllUserSpaces(ctx context.Context, userID uuid.UUID, groupIDs []uuid.UUID, cmd *sqlstorage.PaginateCmd) ([]Space, error) {
+	access := sq.Or{sq.Expr("id IN (SELECT space_id FROM "+membersTableName+" WHERE user_id = ?)", userID)}
+
+	if len(groupIDs) > 0 {
+		groupsQuery, args, err := sq.Select("space_id").From(groupsTableName).Where(sq.Eq{"group_id": groupIDs}).ToSql()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build the groups query: %w", err)
+		}
+
+		access = append(access, sq.Expr("id IN ("+groupsQuery+")", args...))
+	}
+
+	return s.getAllbyKeys(ctx, cmd, access)
 }
 
 func (s *sqlStorage) GetByID(ctx context.Context, id uuid.UUID) (*Space, error) {
@@ -115,10 +136,12 @@ func (s *sqlStorage) getByKeys(ctx context.Context, wheres ...any) (*Space, erro
 	}
 
 	var sqlCreatedAt sqlstorage.SQLTime
+	var trashRetention int64
+	var versionsRetention int64
 
 	err := query.
 		RunWith(s.db).
-		ScanContext(ctx, &res.id, &res.name, &res.owners, &sqlCreatedAt, &res.createdBy)
+		ScanContext(ctx, &res.id, &res.name, &sqlCreatedAt, &res.createdBy, &trashRetention, &res.maxVersions, &versionsRetention, &res.quota)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, errNotFound
 	}
@@ -128,6 +151,8 @@ func (s *sqlStorage) getByKeys(ctx context.Context, wheres ...any) (*Space, erro
 	}
 
 	res.createdAt = sqlCreatedAt.Time()
+	res.trashRetention = time.Duration(trashRetention) * time.Second
+	res.versionsRetention = time.Duration(versionsRetention) * time.Second
 
 	return &res, nil
 }
@@ -138,13 +163,17 @@ func (s *sqlStorage) scanRows(rows *sql.Rows) ([]Space, error) {
 	for rows.Next() {
 		var res Space
 		var sqlCreatedAt sqlstorage.SQLTime
+		var trashRetention int64
+		var versionsRetention int64
 
-		err := rows.Scan(&res.id, &res.name, &res.owners, &sqlCreatedAt, &res.createdBy)
+		err := rows.Scan(&res.id, &res.name, &sqlCreatedAt, &res.createdBy, &trashRetention, &res.maxVersions, &versionsRetention, &res.quota)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan a row: %w", err)
 		}
 
 		res.createdAt = sqlCreatedAt.Time()
+		res.trashRetention = time.Duration(trashRetention) * time.Second
+		res.versionsRetention = time.Duration(versionsRetention) * time.Second
 
 		spaces = append(spaces, res)
 	}
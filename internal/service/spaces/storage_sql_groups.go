@@ -0,0 +1,137 @@
+package spaces
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const groupsTableName = "space_groups"
+
+var allGroupFields = []string{"space_id", "group_id", "role", "created_at", "created_by"}
+
+func (s *sqlStorage) SaveGroup(ctx context.Context, access *GroupAccess) error {
+	_, err := sq.
+		Insert(groupsTableName).
+		Columns(allGroupFields...).
+		Values(access.spaceID,
+			access.groupID,
+			access.role,
+			ptr.To(sqlstorage.SQLTime(access.createdAt)),
+			access.createdBy).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) GetGroup(ctx context.Context, spaceID, groupID uuid.UUID) (*GroupAccess, error) {
+	var res GroupAccess
+	var sqlCreatedAt sqlstorage.SQLTime
+
+	err := sq.
+		Select(allGroupFields...).
+		From(groupsTableName).
+		Where(sq.Eq{"space_id": spaceID, "group_id": groupID}).
+		RunWith(s.db).
+		ScanContext(ctx, &res.spaceID, &res.groupID, &res.role, &sqlCreatedAt, &res.createdBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	res.createdAt = sqlCreatedAt.Time()
+
+	return &res, nil
+}
+
+// GetAllGroups returns all the groups having an access to the given space,
+// the oldest first.
+func (s *sqlStorage) GetAllGroups(ctx context.Context, spaceID uuid.UUID) ([]GroupAccess, error) {
+	rows, err := sq.
+		Select(allGroupFields...).
+		From(groupsTableName).
+		Where(sq.Eq{"space_id": spaceID}).
+		OrderBy("created_at", "group_id").
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	accesses := []GroupAccess{}
+
+	for rows.Next() {
+		var res GroupAccess
+		var sqlCreatedAt sqlstorage.SQLTime
+
+		err := rows.Scan(&res.spaceID, &res.groupID, &res.role, &sqlCreatedAt, &res.createdBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		res.createdAt = sqlCreatedAt.Time()
+
+		accesses = append(accesses, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return accesses, nil
+}
+
+func (s *sqlStorage) DeleteGroup(ctx context.Context, spaceID, groupID uuid.UUID) error {
+	_, err := sq.
+		Delete(groupsTableName).
+		Where(sq.Eq{"space_id": spaceID, "group_id": groupID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) DeleteAllGroups(ctx context.Context, spaceID uuid.UUID) error {
+	_, err := sq.
+		Delete(groupsTableName).
+		Where(sq.Eq{"space_id": spaceID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteGroupFromAllSpaces removes the accesses given to the group on all the spaces.
+func (s *sqlStorage) DeleteGroupFromAllSpaces(ctx context.Context, groupID uuid.UUID) error {
+	_, err := sq.
+		Delete(groupsTableName).
+		Where(sq.Eq{"group_id": groupID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
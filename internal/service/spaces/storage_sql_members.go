@@ -0,0 +1,135 @@
+package spaces
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
+
+const membersTableName = "space_members"
+
+var allMemberFields = []string{"space_id", "user_id", "role", "created_at", "created_by"}
+
+func (s *sqlStorage) SaveMember(ctx context.Context, member *Member) error {
+	_, err := sq.
+		Insert(membersTableName).
+		Columns(allMemberFields...).
+		Values(member.spaceID,
+			member.userID,
+			member.role,
+			ptr.To(sqlstorage.SQLTime(member.createdAt)),
+			member.createdBy).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) GetMember(ctx context.Context, spaceID, userID uuid.UUID) (*Member, error) {
+	var res Member
+	var sqlCreatedAt sqlstorage.SQLTime
+
+	err := sq.
+		Select(allMemberFields...).
+		From(membersTableName).
+		Where(sq.Eq{"space_id": spaceID, "user_id": userID}).
+		RunWith(s.db).
+		ScanContext(ctx, &res.spaceID, &res.userID, &res.role, &sqlCreatedAt, &res.createdBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	res.createdAt = sqlCreatedAt.Time()
+
+	return &res, nil
+}
+
+// GetAllMembers returns all the members of the given space, the oldest first.
+func (s *sqlStorage) GetAllMembers(ctx context.Context, spaceID uuid.UUID) ([]Member, error) {
+	rows, err := sq.
+		Select(allMemberFields...).
+		From(membersTableName).
+		Where(sq.Eq{"space_id": spaceID}).
+		OrderBy("created_at", "user_id").
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql error: %w", err)
+	}
+
+	defer rows.Close()
+
+	members := []Member{}
+
+	for rows.Next() {
+		var res Member
+		var sqlCreatedAt sqlstorage.SQLTime
+
+		err := rows.Scan(&res.spaceID, &res.userID, &res.role, &sqlCreatedAt, &res.createdBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a row: %w", err)
+		}
+
+		res.createdAt = sqlCreatedAt.Time()
+
+		members = append(members, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	return members, nil
+}
+
+func (s *sqlStorage) PatchMember(ctx context.Context, spaceID, userID uuid.UUID, fields map[string]any) error {
+	_, err := sq.Update(membersTableName).
+		SetMap(fields).
+		Where(sq.Eq{"space_id": spaceID, "user_id": userID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) DeleteMember(ctx context.Context, spaceID, userID uuid.UUID) error {
+	_, err := sq.
+		Delete(membersTableName).
+		Where(sq.Eq{"space_id": spaceID, "user_id": userID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) DeleteAllMembers(ctx context.Context, spaceID uuid.UUID) error {
+	_, err := sq.
+		Delete(membersTableName).
+		Where(sq.Eq{"space_id": spaceID}).
+		RunWith(s.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("sql error: %w", err)
+	}
+
+	return nil
+}
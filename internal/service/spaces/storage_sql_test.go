@@ -3,12 +3,15 @@ package spaces
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/groups"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
 func TestSpaceSqlstore(t *testing.T) {
@@ -20,9 +23,13 @@ func TestSpaceSqlstore(t *testing.T) {
 	db := sqlstorage.NewTestStorage(t)
 	store := newSqlStorage(db, tools)
 
-	user := users.NewFakeUser(t).Build()
-	space := NewFakeSpace(t).WithOwners(*user).Build()
+	user := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	space := NewFakeSpace(t).Build()
 	space2 := NewFakeSpace(t).Build()
+	member := NewFakeMember(t, space, user).WithRole(RoleEditor).Build()
+	groupUser := users.NewFakeUser(t).BuildAndStore(ctx, db)
+	group := groups.NewFakeGroup(t).WithMembers(*groupUser).BuildAndStore(ctx, db)
+	groupAccess := NewFakeGroupAccess(t, space, group).WithRole(RoleEditor).Build()
 
 	t.Run("Create success", func(t *testing.T) {
 		// Run
@@ -67,9 +74,55 @@ func TestSpaceSqlstore(t *testing.T) {
 		assert.EqualValues(t, []Space{*space, *space2}, res)
 	})
 
+	t.Run("SaveMember success", func(t *testing.T) {
+		// Run
+		err := store.SaveMember(ctx, member)
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("GetMember success", func(t *testing.T) {
+		// Run
+		res, err := store.GetMember(ctx, space.ID(), user.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, member, res)
+	})
+
+	t.Run("GetMember not found", func(t *testing.T) {
+		// Run
+		res, err := store.GetMember(ctx, space2.ID(), user.ID())
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("GetAllMembers success", func(t *testing.T) {
+		// Run
+		res, err := store.GetAllMembers(ctx, space.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []Member{*member}, res)
+	})
+
+	t.Run("PatchMember success", func(t *testing.T) {
+		// Run
+		err := store.PatchMember(ctx, space.ID(), user.ID(), map[string]any{"role": RoleManager})
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetMember(ctx, space.ID(), user.ID())
+		require.NoError(t, err)
+		assert.Equal(t, RoleManager, res.Role())
+	})
+
 	t.Run("GetAllUserSpaces with only personal success", func(t *testing.T) {
 		// Run
-		res, err := store.GetAllUserSpaces(ctx, user.ID(), nil)
+		res, err := store.GetAllUserSpaces(ctx, user.ID(), nil, nil)
 
 		// Asserts
 		require.NoError(t, err)
@@ -87,6 +140,155 @@ func TestSpaceSqlstore(t *testing.T) {
 		assert.Equal(t, "foo", res.name)
 	})
 
+	t.Run("Patch the trash retention success", func(t *testing.T) {
+		// Run
+		err := store.Patch(ctx, space.id, map[string]any{"trash_retention": int64(3600)})
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetByID(ctx, space.id)
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, res.TrashRetention())
+	})
+
+	t.Run("Patch the versions policy success", func(t *testing.T) {
+		// Run
+		err := store.Patch(ctx, space.id, map[string]any{
+			"max_versions":       3,
+			"versions_retention": int64(3600),
+		})
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetByID(ctx, space.id)
+		require.NoError(t, err)
+		assert.Equal(t, 3, res.MaxVersions())
+		assert.Equal(t, time.Hour, res.VersionsRetention())
+	})
+
+	t.Run("Patch the quota success", func(t *testing.T) {
+		// Run
+		err := store.Patch(ctx, space.id, map[string]any{"quota": uint64(1024)})
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetByID(ctx, space.id)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1024), res.Quota())
+	})
+
+	t.Run("DeleteMember success", func(t *testing.T) {
+		// Run
+		err := store.DeleteMember(ctx, space.ID(), user.ID())
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetAllMembers(ctx, space.ID())
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("SaveGroup success", func(t *testing.T) {
+		// Run
+		err := store.SaveGroup(ctx, groupAccess)
+
+		// Asserts
+		require.NoError(t, err)
+	})
+
+	t.Run("GetGroup success", func(t *testing.T) {
+		// Run
+		res, err := store.GetGroup(ctx, space.ID(), group.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, groupAccess, res)
+	})
+
+	t.Run("GetGroup not found", func(t *testing.T) {
+		// Run
+		res, err := store.GetGroup(ctx, space2.ID(), group.ID())
+
+		// Asserts
+		assert.Nil(t, res)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("GetAllGroups success", func(t *testing.T) {
+		// Run
+		res, err := store.GetAllGroups(ctx, space.ID())
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, []GroupAccess{*groupAccess}, res)
+	})
+
+	t.Run("GetAllUserSpaces with a group success", func(t *testing.T) {
+		// Run
+		res, err := store.GetAllUserSpaces(ctx, groupUser.ID(), []uuid.UUID{group.ID()}, nil)
+
+		// Asserts
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		assert.Equal(t, space.ID(), res[0].ID())
+	})
+
+	t.Run("DeleteGroup success", func(t *testing.T) {
+		// Run
+		err := store.DeleteGroup(ctx, space.ID(), group.ID())
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetAllGroups(ctx, space.ID())
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("DeleteAllGroups success", func(t *testing.T) {
+		// Setup
+		err := store.SaveGroup(ctx, groupAccess)
+		require.NoError(t, err)
+
+		// Run
+		err = store.DeleteAllGroups(ctx, space.ID())
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetAllUserSpaces(ctx, groupUser.ID(), []uuid.UUID{group.ID()}, nil)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("DeleteGroupFromAllSpaces success", func(t *testing.T) {
+		// Setup
+		err := store.SaveGroup(ctx, groupAccess)
+		require.NoError(t, err)
+
+		// Run
+		err = store.DeleteGroupFromAllSpaces(ctx, group.ID())
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetAllGroups(ctx, space.ID())
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("DeleteAllMembers success", func(t *testing.T) {
+		// Setup
+		err := store.SaveMember(ctx, member)
+		require.NoError(t, err)
+
+		// Run
+		err = store.DeleteAllMembers(ctx, space.ID())
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetAllUserSpaces(ctx, user.ID(), nil, nil)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
 	t.Run("Delete success", func(t *testing.T) {
 		// Run
 		err := store.Delete(ctx, space.ID())
@@ -13,8 +13,11 @@ type Service interface {
 	Run(ctx context.Context) error
 	RegisterFileUploadTask(ctx context.Context, args *FileUploadArgs) error
 	RegisterFSMoveTask(ctx context.Context, args *FSMoveArgs) error
+	RegisterFSCopyTask(ctx context.Context, args *FSCopyArgs) error
+	RegisterFSEmptyTrashTask(ctx context.Context, args *FSEmptyTrashArgs) error
 	RegisterUserCreateTask(ctx context.Context, args *UserCreateArgs) error
 	RegisterUserDeleteTask(ctx context.Context, args *UserDeleteArgs) error
+	RegisterGroupDeleteTask(ctx context.Context, args *GroupDeleteArgs) error
 	RegisterFSRefreshSizeTask(ctx context.Context, args *FSRefreshSizeArg) error
 	RegisterFSRemoveDuplicateFile(ctx context.Context, args *FSRemoveDuplicateFileArgs) error
 	RegisterSpaceCreateTask(ctx context.Context, args *SpaceCreateArgs) error
@@ -27,27 +27,83 @@ func (a FileUploadArgs) Validate() error {
 type FSMoveArgs struct {
 	SpaceID     uuid.UUID `json:"space"`
 	SourceInode uuid.UUID `json:"source-inode"`
-	TargetPath  string    `json:"target-path"`
-	MovedAt     time.Time `json:"moved-at"`
-	MovedBy     uuid.UUID `json:"moved-by"`
+	// TargetSpaceID is the space containing TargetPath. The SpaceID is used
+	// if it is empty.
+	TargetSpaceID uuid.UUID `json:"target-space"`
+	TargetPath    string    `json:"target-path"`
+	MovedAt       time.Time `json:"moved-at"`
+	MovedBy       uuid.UUID `json:"moved-by"`
 }
 
 func (a FSMoveArgs) Validate() error {
 	return v.ValidateStruct(&a,
 		v.Field(&a.SpaceID, v.Required, is.UUIDv4),
 		v.Field(&a.SourceInode, v.Required, is.UUIDv4),
+		v.Field(&a.TargetSpaceID, is.UUIDv4),
 		v.Field(&a.TargetPath, v.Required),
 		v.Field(&a.MovedAt, v.Required),
 		v.Field(&a.MovedBy, v.Required, is.UUIDv4),
 	)
 }
 
+type FSCopyArgs struct {
+	SpaceID     uuid.UUID `json:"space"`
+	SourceInode uuid.UUID `json:"source-inode"`
+	// TargetSpaceID is the space containing TargetPath. The SpaceID is used
+	// if it is empty.
+	TargetSpaceID uuid.UUID `json:"target-space"`
+	TargetPath    string    `json:"target-path"`
+	CopiedAt      time.Time `json:"copied-at"`
+	CopiedBy      uuid.UUID `json:"copied-by"`
+}
+
+func (a FSCopyArgs) Validate() error {
+	return v.ValidateStruct(&a,
+		v.Field(&a.SpaceID, v.Required, is.UUIDv4),
+		v.Field(&a.SourceInode, v.Required, is.UUIDv4),
+		v.Field(&a.TargetSpaceID, is.UUIDv4),
+		v.Field(&a.TargetPath, v.Required),
+		v.Field(&a.CopiedAt, v.Required),
+		v.Field(&a.CopiedBy, v.Required, is.UUIDv4),
+	)
+}
+
 type FSGCArgs struct{}
 
 func (a FSGCArgs) Validate() error {
 	return v.ValidateStruct(&a)
 }
 
+type FSPruneVersionsArgs struct{}
+
+func (a FSPruneVersionsArgs) Validate() error {
+	return v.ValidateStruct(&a)
+}
+
+type DavSyncPruneArgs struct{}
+
+func (a DavSyncPruneArgs) Validate() error {
+	return v.ValidateStruct(&a)
+}
+
+type FSIndexContentArgs struct{}
+
+func (a FSIndexContentArgs) Validate() error {
+	return v.ValidateStruct(&a)
+}
+
+type FSEmptyTrashArgs struct {
+	SpaceID   uuid.UUID `json:"space-id"`
+	EmptiedAt time.Time `json:"emptied-at"`
+}
+
+func (a FSEmptyTrashArgs) Validate() error {
+	return v.ValidateStruct(&a,
+		v.Field(&a.SpaceID, v.Required, is.UUIDv4),
+		v.Field(&a.EmptiedAt, v.Required),
+	)
+}
+
 type UserCreateArgs struct {
 	UserID uuid.UUID `json:"user-id"`
 }
@@ -68,6 +124,16 @@ func (a UserDeleteArgs) Validate() error {
 	)
 }
 
+type GroupDeleteArgs struct {
+	GroupID uuid.UUID `json:"group-id"`
+}
+
+func (a GroupDeleteArgs) Validate() error {
+	return v.ValidateStruct(&a,
+		v.Field(&a.GroupID, v.Required, is.UUIDv4),
+	)
+}
+
 type FSRefreshSizeArg struct {
 	ModifiedAt time.Time `json:"modified_at"`
 	INode      uuid.UUID `json:"inode"`
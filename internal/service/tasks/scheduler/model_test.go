@@ -32,6 +32,31 @@ func TestSchedulerModels(t *testing.T) {
 		require.EqualError(t, err, "source-inode: must be a valid UUID v4.")
 	})
 
+	t.Run("FSMoveArgs with an invalid target space", func(t *testing.T) {
+		err := FSMoveArgs{
+			SpaceID:       uuid.UUID("a379fef3-ebc3-4069-b1ef-8c67948b3cff"),
+			SourceInode:   uuid.UUID("c9e4c3c5-8a47-4a1e-b5bb-2f4c4fd2ff8c"),
+			TargetSpaceID: uuid.UUID("some-invalid-id"),
+			TargetPath:    "/foo/bar.txt",
+			MovedAt:       time.Now(),
+			MovedBy:       uuid.UUID("74926c6a-1802-45cd-bcb2-2dc0729fa986"),
+		}.Validate()
+
+		require.EqualError(t, err, "target-space: must be a valid UUID v4.")
+	})
+
+	t.Run("FSCopyArgs", func(t *testing.T) {
+		err := FSCopyArgs{
+			SpaceID:     uuid.UUID("a379fef3-ebc3-4069-b1ef-8c67948b3cff"),
+			SourceInode: uuid.UUID("some-invalid-id"),
+			TargetPath:  "/foo/bar.txt",
+			CopiedAt:    time.Now(),
+			CopiedBy:    uuid.UUID("74926c6a-1802-45cd-bcb2-2dc0729fa986"),
+		}.Validate()
+
+		require.EqualError(t, err, "source-inode: must be a valid UUID v4.")
+	})
+
 	t.Run("UserCreateArgs", func(t *testing.T) {
 		err := UserCreateArgs{
 			UserID: uuid.UUID("some-invalid-id"),
@@ -46,6 +71,35 @@ func TestSchedulerModels(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("FSPruneVersionsArgs", func(t *testing.T) {
+		err := FSPruneVersionsArgs{}.Validate()
+
+		require.NoError(t, err)
+	})
+
+	t.Run("DavSyncPruneArgs", func(t *testing.T) {
+		err := DavSyncPruneArgs{}.Validate()
+
+		require.NoError(t, err)
+	})
+
+	t.Run("GroupDeleteArgs", func(t *testing.T) {
+		err := GroupDeleteArgs{
+			GroupID: uuid.UUID("some-invalid-id"),
+		}.Validate()
+
+		require.EqualError(t, err, "group-id: must be a valid UUID v4.")
+	})
+
+	t.Run("FSEmptyTrashArgs", func(t *testing.T) {
+		err := FSEmptyTrashArgs{
+			SpaceID:   uuid.UUID("some-invalid-id"),
+			EmptiedAt: time.Now(),
+		}.Validate()
+
+		require.EqualError(t, err, "space-id: must be a valid UUID v4.")
+	})
+
 	t.Run("FSRemoveDuplicateFileArgs", func(t *testing.T) {
 		err := FSRemoveDuplicateFileArgs{
 			ExistingFileID:  uuid.UUID("some-invalid-id"),
@@ -32,6 +32,21 @@ func (t *TasksService) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to schedule fs-gc task: %w", err)
 	}
 
+	err = t.ensureTaskEvery(ctx, "fs-prune-versions", time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to schedule fs-prune-versions task: %w", err)
+	}
+
+	err = t.ensureTaskEvery(ctx, "fs-index-content", time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed to schedule fs-index-content task: %w", err)
+	}
+
+	err = t.ensureTaskEvery(ctx, "dav-sync-prune", time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to schedule dav-sync-prune task: %w", err)
+	}
+
 	return nil
 }
 
@@ -48,6 +63,12 @@ func (t *TasksService) ensureTaskEvery(ctx context.Context, name string, every t
 	switch name {
 	case "fs-gc":
 		return t.RegisterFSGCTask(ctx)
+	case "fs-prune-versions":
+		return t.RegisterFSPruneVersionsTask(ctx)
+	case "fs-index-content":
+		return t.RegisterFSIndexContentTask(ctx)
+	case "dav-sync-prune":
+		return t.RegisterDavSyncPruneTask(ctx)
 	default:
 		return fmt.Errorf("unhandled task name")
 	}
@@ -57,6 +78,27 @@ func (t *TasksService) RegisterFSGCTask(ctx context.Context) error {
 	return t.registerTask(ctx, 4, "fs-gc", struct{}{})
 }
 
+func (t *TasksService) RegisterFSPruneVersionsTask(ctx context.Context) error {
+	return t.registerTask(ctx, 4, "fs-prune-versions", struct{}{})
+}
+
+func (t *TasksService) RegisterFSIndexContentTask(ctx context.Context) error {
+	return t.registerTask(ctx, 4, "fs-index-content", struct{}{})
+}
+
+func (t *TasksService) RegisterDavSyncPruneTask(ctx context.Context) error {
+	return t.registerTask(ctx, 4, "dav-sync-prune", struct{}{})
+}
+
+func (t *TasksService) RegisterFSEmptyTrashTask(ctx context.Context, args *FSEmptyTrashArgs) error {
+	err := args.Validate()
+	if err != nil {
+		return errs.Validation(err)
+	}
+
+	return t.registerTask(ctx, 3, "fs-empty-trash", args)
+}
+
 func (t *TasksService) RegisterFSRefreshSizeTask(ctx context.Context, args *FSRefreshSizeArg) error {
 	err := args.Validate()
 	if err != nil {
@@ -93,6 +135,15 @@ func (t *TasksService) RegisterFSMoveTask(ctx context.Context, args *FSMoveArgs)
 	return t.registerTask(ctx, 2, "fs-move", args)
 }
 
+func (t *TasksService) RegisterFSCopyTask(ctx context.Context, args *FSCopyArgs) error {
+	err := args.Validate()
+	if err != nil {
+		return errs.Validation(err)
+	}
+
+	return t.registerTask(ctx, 2, "fs-copy", args)
+}
+
 func (t *TasksService) RegisterUserCreateTask(ctx context.Context, args *UserCreateArgs) error {
 	err := args.Validate()
 	if err != nil {
@@ -111,6 +162,15 @@ func (t *TasksService) RegisterUserDeleteTask(ctx context.Context, args *UserDel
 	return t.registerTask(ctx, 1, "user-delete", args)
 }
 
+func (t *TasksService) RegisterGroupDeleteTask(ctx context.Context, args *GroupDeleteArgs) error {
+	err := args.Validate()
+	if err != nil {
+		return errs.Validation(err)
+	}
+
+	return t.registerTask(ctx, 1, "group-delete", args)
+}
+
 func (t *TasksService) RegisterSpaceCreateTask(ctx context.Context, args *SpaceCreateArgs) error {
 	err := args.Validate()
 	if err != nil {
@@ -13,6 +13,34 @@ type MockService struct {
 	mock.Mock
 }
 
+// RegisterFSCopyTask provides a mock function with given fields: ctx, args
+func (_m *MockService) RegisterFSCopyTask(ctx context.Context, args *FSCopyArgs) error {
+	ret := _m.Called(ctx, args)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *FSCopyArgs) error); ok {
+		r0 = rf(ctx, args)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RegisterFSEmptyTrashTask provides a mock function with given fields: ctx, args
+func (_m *MockService) RegisterFSEmptyTrashTask(ctx context.Context, args *FSEmptyTrashArgs) error {
+	ret := _m.Called(ctx, args)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *FSEmptyTrashArgs) error); ok {
+		r0 = rf(ctx, args)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RegisterFSMoveTask provides a mock function with given fields: ctx, args
 func (_m *MockService) RegisterFSMoveTask(ctx context.Context, args *FSMoveArgs) error {
 	ret := _m.Called(ctx, args)
@@ -69,6 +97,20 @@ func (_m *MockService) RegisterFileUploadTask(ctx context.Context, args *FileUpl
 	return r0
 }
 
+// RegisterGroupDeleteTask provides a mock function with given fields: ctx, args
+func (_m *MockService) RegisterGroupDeleteTask(ctx context.Context, args *GroupDeleteArgs) error {
+	ret := _m.Called(ctx, args)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *GroupDeleteArgs) error); ok {
+		r0 = rf(ctx, args)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RegisterSpaceCreateTask provides a mock function with given fields: ctx, args
 func (_m *MockService) RegisterSpaceCreateTask(ctx context.Context, args *SpaceCreateArgs) error {
 	ret := _m.Called(ctx, args)
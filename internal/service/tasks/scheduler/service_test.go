@@ -78,6 +78,105 @@ func TestSchdulerService(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("RegisterFSPruneVersionsTask", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		storageMock := taskstorage.NewMockStorage(t)
+		svc := NewService(storageMock, tools)
+
+		tools.UUIDMock.On("New").Return(uuid.UUID("some-uuid")).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		storageMock.On("Save", mock.Anything, &model.Task{
+			ID:           uuid.UUID("some-uuid"),
+			Priority:     4,
+			Status:       model.Queuing,
+			Name:         "fs-prune-versions",
+			RegisteredAt: now,
+			Args:         json.RawMessage(`{}`),
+		}).Return(nil).Once()
+
+		err := svc.RegisterFSPruneVersionsTask(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("RegisterDavSyncPruneTask", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		storageMock := taskstorage.NewMockStorage(t)
+		svc := NewService(storageMock, tools)
+
+		tools.UUIDMock.On("New").Return(uuid.UUID("some-uuid")).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		storageMock.On("Save", mock.Anything, &model.Task{
+			ID:           uuid.UUID("some-uuid"),
+			Priority:     4,
+			Status:       model.Queuing,
+			Name:         "dav-sync-prune",
+			RegisteredAt: now,
+			Args:         json.RawMessage(`{}`),
+		}).Return(nil).Once()
+
+		err := svc.RegisterDavSyncPruneTask(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("RegisterFSIndexContentTask", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		storageMock := taskstorage.NewMockStorage(t)
+		svc := NewService(storageMock, tools)
+
+		tools.UUIDMock.On("New").Return(uuid.UUID("some-uuid")).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		storageMock.On("Save", mock.Anything, &model.Task{
+			ID:           uuid.UUID("some-uuid"),
+			Priority:     4,
+			Status:       model.Queuing,
+			Name:         "fs-index-content",
+			RegisteredAt: now,
+			Args:         json.RawMessage(`{}`),
+		}).Return(nil).Once()
+
+		err := svc.RegisterFSIndexContentTask(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("RegisterFSEmptyTrashTask", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		storageMock := taskstorage.NewMockStorage(t)
+		svc := NewService(storageMock, tools)
+
+		tools.UUIDMock.On("New").Return(uuid.UUID("some-uuid")).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		storageMock.On("Save", mock.Anything, &model.Task{
+			ID:           uuid.UUID("some-uuid"),
+			Priority:     3,
+			Status:       model.Queuing,
+			Name:         "fs-empty-trash",
+			RegisteredAt: now,
+			Args:         json.RawMessage(`{"space-id":"a379fef3-ebc3-4069-b1ef-8c67948b3cff","emptied-at":"2020-02-12T11:10:00Z"}`),
+		}).Return(nil).Once()
+
+		err := svc.RegisterFSEmptyTrashTask(ctx, &FSEmptyTrashArgs{
+			SpaceID:   uuid.UUID("a379fef3-ebc3-4069-b1ef-8c67948b3cff"),
+			EmptiedAt: now,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("RegisterFSEmptyTrashTask with a validation error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		storageMock := taskstorage.NewMockStorage(t)
+		svc := NewService(storageMock, tools)
+
+		err := svc.RegisterFSEmptyTrashTask(ctx, &FSEmptyTrashArgs{
+			SpaceID:   uuid.UUID("some-invalid-id"),
+			EmptiedAt: now,
+		})
+		require.ErrorIs(t, err, errs.ErrValidation)
+	})
+
 	t.Run("RegisterUserDeleteTask", func(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := taskstorage.NewMockStorage(t)
@@ -101,6 +200,29 @@ func TestSchdulerService(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("RegisterGroupDeleteTask", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		storageMock := taskstorage.NewMockStorage(t)
+		svc := NewService(storageMock, tools)
+
+		tools.UUIDMock.On("New").Return(uuid.UUID("some-uuid")).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		storageMock.On("Save", mock.Anything, &model.Task{
+			ID:           uuid.UUID("some-uuid"),
+			Priority:     1,
+			Status:       model.Queuing,
+			Name:         "group-delete",
+			RegisteredAt: now,
+			Args:         json.RawMessage(`{"group-id":"a379fef3-ebc3-4069-b1ef-8c67948b3cff"}`),
+		}).Return(nil).Once()
+
+		err := svc.RegisterGroupDeleteTask(ctx, &GroupDeleteArgs{
+			GroupID: uuid.UUID("a379fef3-ebc3-4069-b1ef-8c67948b3cff"),
+		})
+		require.NoError(t, err)
+	})
+
 	t.Run("RegisterUserCreateTask", func(t *testing.T) {
 		tools := tools.NewMock(t)
 		storageMock := taskstorage.NewMockStorage(t)
@@ -188,17 +310,61 @@ func TestSchdulerService(t *testing.T) {
 			Status:       model.Queuing,
 			Name:         "fs-move",
 			RegisteredAt: now,
-			Args:         json.RawMessage(`{"space":"a379fef3-ebc3-4069-b1ef-8c67948b3cff","source-inode":"0d76c071-2e8b-4873-92e9-d8be871ef636","target-path":"/foo/bar.txt","moved-at":"2020-02-12T11:10:00Z","moved-by":"74926c6a-1802-45cd-bcb2-2dc0729fa986"}`),
+			Args:         json.RawMessage(`{"space":"a379fef3-ebc3-4069-b1ef-8c67948b3cff","source-inode":"0d76c071-2e8b-4873-92e9-d8be871ef636","target-space":"c1bb5fbb-4ba1-4bd9-9d7c-d85be8a6c6a3","target-path":"/foo/bar.txt","moved-at":"2020-02-12T11:10:00Z","moved-by":"74926c6a-1802-45cd-bcb2-2dc0729fa986"}`),
 		}).Return(nil).Once()
 
 		err := svc.RegisterFSMoveTask(ctx, &FSMoveArgs{
+			SpaceID:       uuid.UUID("a379fef3-ebc3-4069-b1ef-8c67948b3cff"),
+			SourceInode:   uuid.UUID("0d76c071-2e8b-4873-92e9-d8be871ef636"),
+			TargetSpaceID: uuid.UUID("c1bb5fbb-4ba1-4bd9-9d7c-d85be8a6c6a3"),
+			TargetPath:    "/foo/bar.txt",
+			MovedAt:       now,
+			MovedBy:       uuid.UUID("74926c6a-1802-45cd-bcb2-2dc0729fa986"),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("RegisterFSCopyTask", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		storageMock := taskstorage.NewMockStorage(t)
+		svc := NewService(storageMock, tools)
+
+		tools.UUIDMock.On("New").Return(uuid.UUID("some-uuid")).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		storageMock.On("Save", mock.Anything, &model.Task{
+			ID:           uuid.UUID("some-uuid"),
+			Priority:     2,
+			Status:       model.Queuing,
+			Name:         "fs-copy",
+			RegisteredAt: now,
+			Args:         json.RawMessage(`{"space":"a379fef3-ebc3-4069-b1ef-8c67948b3cff","source-inode":"0d76c071-2e8b-4873-92e9-d8be871ef636","target-space":"c1bb5fbb-4ba1-4bd9-9d7c-d85be8a6c6a3","target-path":"/foo/bar.txt","copied-at":"2020-02-12T11:10:00Z","copied-by":"74926c6a-1802-45cd-bcb2-2dc0729fa986"}`),
+		}).Return(nil).Once()
+
+		err := svc.RegisterFSCopyTask(ctx, &FSCopyArgs{
+			SpaceID:       uuid.UUID("a379fef3-ebc3-4069-b1ef-8c67948b3cff"),
+			SourceInode:   uuid.UUID("0d76c071-2e8b-4873-92e9-d8be871ef636"),
+			TargetSpaceID: uuid.UUID("c1bb5fbb-4ba1-4bd9-9d7c-d85be8a6c6a3"),
+			TargetPath:    "/foo/bar.txt",
+			CopiedAt:      now,
+			CopiedBy:      uuid.UUID("74926c6a-1802-45cd-bcb2-2dc0729fa986"),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("RegisterFSCopyTask with an invalid arg", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		storageMock := taskstorage.NewMockStorage(t)
+		svc := NewService(storageMock, tools)
+
+		err := svc.RegisterFSCopyTask(ctx, &FSCopyArgs{
 			SpaceID:     uuid.UUID("a379fef3-ebc3-4069-b1ef-8c67948b3cff"),
-			SourceInode: uuid.UUID("0d76c071-2e8b-4873-92e9-d8be871ef636"),
+			SourceInode: uuid.UUID("some-invalid-id"),
 			TargetPath:  "/foo/bar.txt",
-			MovedAt:     now,
-			MovedBy:     uuid.UUID("74926c6a-1802-45cd-bcb2-2dc0729fa986"),
+			CopiedAt:    now,
+			CopiedBy:    uuid.UUID("74926c6a-1802-45cd-bcb2-2dc0729fa986"),
 		})
-		require.NoError(t, err)
+		require.ErrorIs(t, err, errs.ErrValidation)
 	})
 
 	t.Run("Run success", func(t *testing.T) {
@@ -230,6 +396,36 @@ func TestSchdulerService(t *testing.T) {
 			Args:         json.RawMessage(`{}`),
 		}).Return(nil).Once()
 
+		storageMock.On("GetLastRegisteredTask", mock.Anything, "fs-prune-versions").Return(&model.Task{
+			ID:           uuid.UUID("some-other-uuid"),
+			Priority:     4,
+			Status:       model.Queuing,
+			Name:         "fs-prune-versions",
+			RegisteredAt: now.Add(-time.Minute),
+			Args:         json.RawMessage(`{}`),
+		}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		storageMock.On("GetLastRegisteredTask", mock.Anything, "fs-index-content").Return(&model.Task{
+			ID:           uuid.UUID("some-other-uuid"),
+			Priority:     4,
+			Status:       model.Queuing,
+			Name:         "fs-index-content",
+			RegisteredAt: now.Add(-time.Second),
+			Args:         json.RawMessage(`{}`),
+		}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		storageMock.On("GetLastRegisteredTask", mock.Anything, "dav-sync-prune").Return(&model.Task{
+			ID:           uuid.UUID("some-other-uuid"),
+			Priority:     4,
+			Status:       model.Queuing,
+			Name:         "dav-sync-prune",
+			RegisteredAt: now.Add(-time.Minute),
+			Args:         json.RawMessage(`{}`),
+		}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
 		err := svc.Run(ctx)
 		require.NoError(t, err)
 	})
@@ -253,6 +449,36 @@ func TestSchdulerService(t *testing.T) {
 		// There is 2 seconds since the last "fs-gc" task so there is no need
 		// to push a new task.
 
+		storageMock.On("GetLastRegisteredTask", mock.Anything, "fs-prune-versions").Return(&model.Task{
+			ID:           uuid.UUID("some-other-uuid"),
+			Priority:     4,
+			Status:       model.Queuing,
+			Name:         "fs-prune-versions",
+			RegisteredAt: now.Add(-time.Minute),
+			Args:         json.RawMessage(`{}`),
+		}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		storageMock.On("GetLastRegisteredTask", mock.Anything, "fs-index-content").Return(&model.Task{
+			ID:           uuid.UUID("some-other-uuid"),
+			Priority:     4,
+			Status:       model.Queuing,
+			Name:         "fs-index-content",
+			RegisteredAt: now.Add(-time.Second),
+			Args:         json.RawMessage(`{}`),
+		}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		storageMock.On("GetLastRegisteredTask", mock.Anything, "dav-sync-prune").Return(&model.Task{
+			ID:           uuid.UUID("some-other-uuid"),
+			Priority:     4,
+			Status:       model.Queuing,
+			Name:         "dav-sync-prune",
+			RegisteredAt: now.Add(-time.Minute),
+			Args:         json.RawMessage(`{}`),
+		}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
 		err := svc.Run(ctx)
 		require.NoError(t, err)
 	})
@@ -275,6 +501,36 @@ func TestSchdulerService(t *testing.T) {
 			Args:         json.RawMessage(`{}`),
 		}).Return(nil).Once()
 
+		storageMock.On("GetLastRegisteredTask", mock.Anything, "fs-prune-versions").Return(&model.Task{
+			ID:           uuid.UUID("some-other-uuid"),
+			Priority:     4,
+			Status:       model.Queuing,
+			Name:         "fs-prune-versions",
+			RegisteredAt: now.Add(-time.Minute),
+			Args:         json.RawMessage(`{}`),
+		}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		storageMock.On("GetLastRegisteredTask", mock.Anything, "fs-index-content").Return(&model.Task{
+			ID:           uuid.UUID("some-other-uuid"),
+			Priority:     4,
+			Status:       model.Queuing,
+			Name:         "fs-index-content",
+			RegisteredAt: now.Add(-time.Second),
+			Args:         json.RawMessage(`{}`),
+		}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
+		storageMock.On("GetLastRegisteredTask", mock.Anything, "dav-sync-prune").Return(&model.Task{
+			ID:           uuid.UUID("some-other-uuid"),
+			Priority:     4,
+			Status:       model.Queuing,
+			Name:         "dav-sync-prune",
+			RegisteredAt: now.Add(-time.Minute),
+			Args:         json.RawMessage(`{}`),
+		}, nil).Once()
+		tools.ClockMock.On("Now").Return(now).Once()
+
 		err := svc.Run(ctx)
 		require.NoError(t, err)
 	})
@@ -27,6 +27,7 @@ type Service interface {
 	GetAllWithStatus(ctx context.Context, status Status, cmd *sqlstorage.PaginateCmd) ([]User, error)
 	MarkInitAsFinished(ctx context.Context, userID uuid.UUID) (*User, error)
 	UpdateUserPassword(ctx context.Context, cmd *UpdatePasswordCmd) error
+	SetQuota(ctx context.Context, cmd *SetQuotaCmd) (*User, error)
 }
 
 func Init(
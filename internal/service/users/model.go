@@ -35,6 +35,7 @@ type User struct {
 	password          secret.Text
 	status            Status
 	createdBy         uuid.UUID
+	quota             uint64
 	isAdmin           bool
 }
 
@@ -56,6 +57,10 @@ func (u User) PasswordChangedAt() time.Time { return u.passwordChangedAt }
 func (u User) CreatedAt() time.Time         { return u.createdAt }
 func (u User) CreatedBy() uuid.UUID         { return u.createdBy }
 
+// Quota returns the maximum number of bytes the user can create across all
+// the spaces. Zero means no limit.
+func (u User) Quota() uint64 { return u.quota }
+
 // CreateCmd represents an user creation request.
 type CreateCmd struct {
 	CreatedBy *User
@@ -85,3 +90,16 @@ func (t UpdatePasswordCmd) Validate() error {
 		v.Field(&t.NewPassword, v.Required, v.Length(SecretMinLength, SecretMaxLength)),
 	)
 }
+
+type SetQuotaCmd struct {
+	User   *User
+	UserID uuid.UUID
+	Quota  uint64
+}
+
+func (t SetQuotaCmd) Validate() error {
+	return v.ValidateStruct(&t,
+		v.Field(&t.User, v.Required),
+		v.Field(&t.UserID, v.Required, is.UUIDv4),
+	)
+}
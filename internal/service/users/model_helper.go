@@ -62,6 +62,12 @@ func (f *FakeUserBuilder) WithStatus(status Status) *FakeUserBuilder {
 	return f
 }
 
+func (f *FakeUserBuilder) WithQuota(quota uint64) *FakeUserBuilder {
+	f.user.quota = quota
+
+	return f
+}
+
 func (f *FakeUserBuilder) Build() *User {
 	return f.user
 }
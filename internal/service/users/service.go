@@ -145,6 +145,37 @@ func (s *service) UpdateUserPassword(ctx context.Context, cmd *UpdatePasswordCmd
 	return nil
 }
 
+// SetQuota limits the number of bytes the user can create. Only an admin can
+// change it.
+func (s *service) SetQuota(ctx context.Context, cmd *SetQuotaCmd) (*User, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return nil, errs.Validation(err)
+	}
+
+	if !cmd.User.IsAdmin() {
+		return nil, errs.ErrUnauthorized
+	}
+
+	user, err := s.storage.GetByID(ctx, cmd.UserID)
+	if errors.Is(err, errNotFound) {
+		return nil, errs.NotFound(err)
+	}
+
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to GetByID: %w", err))
+	}
+
+	user.quota = cmd.Quota
+
+	err = s.storage.Patch(ctx, user.ID(), map[string]any{"quota": cmd.Quota})
+	if err != nil {
+		return nil, errs.Internal(fmt.Errorf("failed to patch the user: %w", err))
+	}
+
+	return user, nil
+}
+
 func (s *service) MarkInitAsFinished(ctx context.Context, userID uuid.UUID) (*User, error) {
 	user, err := s.GetByID(ctx, userID)
 	if err != nil {
@@ -228,6 +228,32 @@ func (_m *MockService) MarkInitAsFinished(ctx context.Context, userID uuid.UUID)
 	return r0, r1
 }
 
+// SetQuota provides a mock function with given fields: ctx, cmd
+func (_m *MockService) SetQuota(ctx context.Context, cmd *SetQuotaCmd) (*User, error) {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 *User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *SetQuotaCmd) (*User, error)); ok {
+		return rf(ctx, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *SetQuotaCmd) *User); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *SetQuotaCmd) error); ok {
+		r1 = rf(ctx, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateUserPassword provides a mock function with given fields: ctx, cmd
 func (_m *MockService) UpdateUserPassword(ctx context.Context, cmd *UpdatePasswordCmd) error {
 	ret := _m.Called(ctx, cmd)
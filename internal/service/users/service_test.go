@@ -528,4 +528,126 @@ func Test_Users_Service(t *testing.T) {
 		require.ErrorIs(t, err, errs.ErrInternal)
 		require.ErrorContains(t, err, "some-error")
 	})
+
+	t.Run("SetQuota success", func(t *testing.T) {
+		t.Parallel()
+		tools := tools.NewMock(t)
+		store := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		service := newService(tools, store, schedulerMock)
+
+		// Data
+		admin := NewFakeUser(t).WithAdminRole().Build()
+		user := NewFakeUser(t).Build()
+
+		// Mocks
+		store.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		store.On("Patch", mock.Anything, user.ID(), map[string]any{"quota": uint64(1024)}).Return(nil).Once()
+
+		// Run
+		res, err := service.SetQuota(ctx, &SetQuotaCmd{
+			User:   admin,
+			UserID: user.ID(),
+			Quota:  1024,
+		})
+
+		// Asserts
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1024), res.Quota())
+	})
+
+	t.Run("SetQuota with a validation error", func(t *testing.T) {
+		t.Parallel()
+		tools := tools.NewMock(t)
+		store := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		service := newService(tools, store, schedulerMock)
+
+		// Run
+		res, err := service.SetQuota(ctx, &SetQuotaCmd{
+			User:   NewFakeUser(t).WithAdminRole().Build(),
+			UserID: "some-invalid-id",
+			Quota:  1024,
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrValidation)
+		assert.Nil(t, res)
+	})
+
+	t.Run("SetQuota with a non admin user", func(t *testing.T) {
+		t.Parallel()
+		tools := tools.NewMock(t)
+		store := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		service := newService(tools, store, schedulerMock)
+
+		// Data
+		user := NewFakeUser(t).Build()
+
+		// Run
+		res, err := service.SetQuota(ctx, &SetQuotaCmd{
+			User:   user,
+			UserID: user.ID(),
+			Quota:  1024,
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrUnauthorized)
+		assert.Nil(t, res)
+	})
+
+	t.Run("SetQuota with a user not found", func(t *testing.T) {
+		t.Parallel()
+		tools := tools.NewMock(t)
+		store := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		service := newService(tools, store, schedulerMock)
+
+		// Data
+		admin := NewFakeUser(t).WithAdminRole().Build()
+		user := NewFakeUser(t).Build()
+
+		// Mocks
+		store.On("GetByID", mock.Anything, user.ID()).Return(nil, errNotFound).Once()
+
+		// Run
+		res, err := service.SetQuota(ctx, &SetQuotaCmd{
+			User:   admin,
+			UserID: user.ID(),
+			Quota:  1024,
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		assert.Nil(t, res)
+	})
+
+	t.Run("SetQuota with a Patch error", func(t *testing.T) {
+		t.Parallel()
+		tools := tools.NewMock(t)
+		store := newMockStorage(t)
+		schedulerMock := scheduler.NewMockService(t)
+		service := newService(tools, store, schedulerMock)
+
+		// Data
+		admin := NewFakeUser(t).WithAdminRole().Build()
+		user := NewFakeUser(t).Build()
+
+		// Mocks
+		store.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		store.On("Patch", mock.Anything, user.ID(), map[string]any{"quota": uint64(1024)}).Return(fmt.Errorf("some-error")).Once()
+
+		// Run
+		res, err := service.SetQuota(ctx, &SetQuotaCmd{
+			User:   admin,
+			UserID: user.ID(),
+			Quota:  1024,
+		})
+
+		// Asserts
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "some-error")
+		assert.Nil(t, res)
+	})
 }
@@ -16,7 +16,7 @@ const tableName = "users"
 
 var errNotFound = errors.New("not found")
 
-var allFields = []string{"id", "username", "admin", "status", "password", "password_changed_at", "created_at", "created_by"}
+var allFields = []string{"id", "username", "admin", "status", "password", "password_changed_at", "created_at", "created_by", "quota"}
 
 // sqlStorage use to save/retrieve Users
 type sqlStorage struct {
@@ -40,7 +40,8 @@ func (s *sqlStorage) Save(ctx context.Context, u *User) error {
 			u.password,
 			ptr.To(sqlstorage.SQLTime(u.passwordChangedAt)),
 			ptr.To(sqlstorage.SQLTime(u.createdAt)),
-			u.createdBy).
+			u.createdBy,
+			u.quota).
 		RunWith(s.db).
 		ExecContext(ctx)
 	if err != nil {
@@ -120,7 +121,8 @@ func (s *sqlStorage) getByKeys(ctx context.Context, wheres ...any) (*User, error
 			&res.password,
 			&sqlPasswordChangedAt,
 			&sqlCreatedAt,
-			&res.createdBy)
+			&res.createdBy,
+			&res.quota)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, errNotFound
 	}
@@ -150,7 +152,8 @@ func (s *sqlStorage) scanRows(rows *sql.Rows) ([]User, error) {
 			&res.password,
 			&sqlPasswordChangedAt,
 			&sqlCreatedAt,
-			&res.createdBy)
+			&res.createdBy,
+			&res.quota)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan a row: %w", err)
 		}
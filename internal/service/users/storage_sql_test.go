@@ -70,6 +70,23 @@ func TestUserSqlStorage(t *testing.T) {
 		assert.Equal(t, "new-username", res.username)
 	})
 
+	t.Run("Patch the quota success", func(t *testing.T) {
+		// Restore the old quota
+		t.Cleanup(func() {
+			err := store.Patch(ctx, user.ID(), map[string]any{"quota": user.quota})
+			require.NoError(t, err)
+		})
+
+		// Run
+		err := store.Patch(ctx, user.ID(), map[string]any{"quota": uint64(1024)})
+		require.NoError(t, err)
+
+		// Asserts
+		res, err := store.GetByID(ctx, user.ID())
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1024), res.Quota())
+	})
+
 	t.Run("GetByUsername success", func(t *testing.T) {
 		// Run
 		res, err := store.GetByUsername(ctx, user.Username())
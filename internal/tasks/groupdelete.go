@@ -0,0 +1,47 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/theduckcompany/duckcloud/internal/service/groups"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+)
+
+type GroupDeleteTaskRunner struct {
+	groups groups.Service
+	spaces spaces.Service
+}
+
+func NewGroupDeleteTaskRunner(groups groups.Service, spaces spaces.Service) *GroupDeleteTaskRunner {
+	return &GroupDeleteTaskRunner{groups, spaces}
+}
+
+func (r *GroupDeleteTaskRunner) Name() string { return "group-delete" }
+
+func (r *GroupDeleteTaskRunner) Run(ctx context.Context, rawArgs json.RawMessage) error {
+	var args scheduler.GroupDeleteArgs
+	err := json.Unmarshal(rawArgs, &args)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the args: %w", err)
+	}
+
+	return r.RunArgs(ctx, &args)
+}
+
+func (r *GroupDeleteTaskRunner) RunArgs(ctx context.Context, args *scheduler.GroupDeleteArgs) error {
+	// First remove the accesses given by the group.
+	err := r.spaces.RemoveGroupFromAllSpaces(ctx, args.GroupID)
+	if err != nil {
+		return fmt.Errorf("failed to remove the group from all spaces: %w", err)
+	}
+
+	err = r.groups.HardDelete(ctx, args.GroupID)
+	if err != nil {
+		return fmt.Errorf("failed to hard delete the group: %w", err)
+	}
+
+	return nil
+}
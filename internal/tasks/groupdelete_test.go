@@ -0,0 +1,70 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/groups"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+)
+
+func TestGroupDeleteTask(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Name", func(t *testing.T) {
+		job := NewGroupDeleteTaskRunner(nil, nil)
+		assert.Equal(t, "group-delete", job.Name())
+	})
+
+	t.Run("Run with an invalid json", func(t *testing.T) {
+		groupsMock := groups.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		job := NewGroupDeleteTaskRunner(groupsMock, spacesMock)
+
+		err := job.Run(ctx, json.RawMessage(`{some invalid json}`))
+		require.EqualError(t, err, "failed to unmarshal the args: invalid character 's' looking for beginning of object key string")
+	})
+
+	t.Run("Run success", func(t *testing.T) {
+		groupsMock := groups.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		job := NewGroupDeleteTaskRunner(groupsMock, spacesMock)
+
+		spacesMock.On("RemoveGroupFromAllSpaces", mock.Anything, groups.ExampleFamily.ID()).Return(nil).Once()
+		groupsMock.On("HardDelete", mock.Anything, groups.ExampleFamily.ID()).Return(nil).Once()
+
+		err := job.Run(ctx, json.RawMessage(`{"group-id": "`+string(groups.ExampleFamily.ID())+`"}`))
+		require.NoError(t, err)
+	})
+
+	t.Run("RunArgs with a RemoveGroupFromAllSpaces error", func(t *testing.T) {
+		groupsMock := groups.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		job := NewGroupDeleteTaskRunner(groupsMock, spacesMock)
+
+		spacesMock.On("RemoveGroupFromAllSpaces", mock.Anything, groups.ExampleFamily.ID()).Return(errs.ErrInternal).Once()
+
+		err := job.RunArgs(ctx, &scheduler.GroupDeleteArgs{GroupID: groups.ExampleFamily.ID()})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "failed to remove the group from all spaces")
+	})
+
+	t.Run("RunArgs with a HardDelete error", func(t *testing.T) {
+		groupsMock := groups.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		job := NewGroupDeleteTaskRunner(groupsMock, spacesMock)
+
+		spacesMock.On("RemoveGroupFromAllSpaces", mock.Anything, groups.ExampleFamily.ID()).Return(nil).Once()
+		groupsMock.On("HardDelete", mock.Anything, groups.ExampleFamily.ID()).Return(errs.ErrInternal).Once()
+
+		err := job.RunArgs(ctx, &scheduler.GroupDeleteArgs{GroupID: groups.ExampleFamily.ID()})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "failed to hard delete the group")
+	})
+}
@@ -1,10 +1,13 @@
 package tasks
 
 import (
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/groups"
 	"github.com/theduckcompany/duckcloud/internal/service/oauthconsents"
 	"github.com/theduckcompany/duckcloud/internal/service/oauthsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/runner"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
@@ -17,6 +20,7 @@ type Result struct {
 	UserDeleteTask  runner.TaskRunner `group:"tasks"`
 	UserCreateTask  runner.TaskRunner `group:"tasks"`
 	SpaceCreateTask runner.TaskRunner `group:"tasks"`
+	GroupDeleteTask runner.TaskRunner `group:"tasks"`
 }
 
 func Init(
@@ -27,10 +31,14 @@ func Init(
 	davSessions davsessions.Service,
 	oauthSessions oauthsessions.Service,
 	oauthConsents oauthconsents.Service,
+	shares shares.Service,
+	groups groups.Service,
+	davLocks davlocks.Service,
 ) Result {
 	return Result{
 		UserCreateTask:  NewUserCreateTaskRunner(users, spaces, fs),
-		UserDeleteTask:  NewUserDeleteTaskRunner(users, webSessions, davSessions, oauthSessions, oauthConsents, spaces, fs),
+		UserDeleteTask:  NewUserDeleteTaskRunner(users, webSessions, davSessions, davLocks, oauthSessions, oauthConsents, shares, groups, spaces, fs),
 		SpaceCreateTask: NewSpaceCreateTaskRunner(users, spaces, fs),
+		GroupDeleteTask: NewGroupDeleteTaskRunner(groups, spaces),
 	}
 }
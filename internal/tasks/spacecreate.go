@@ -40,9 +40,9 @@ func (r *SpaceCreateTaskRunner) RunArgs(ctx context.Context, args *scheduler.Spa
 	}
 
 	space, err := r.spaces.Create(ctx, &spaces.CreateCmd{
-		User:   user,
-		Name:   args.Name,
-		Owners: args.Owners,
+		User:     user,
+		Name:     args.Name,
+		Managers: args.Owners,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create the space: %w", err)
@@ -43,9 +43,9 @@ func TestSpaceCreateTask(t *testing.T) {
 		usersMock.On("GetByID", mock.Anything, uuid.UUID("059d78af-e675-498e-8b77-d4b2b4b9d4e7")).
 			Return(&users.ExampleAlice, nil).Once()
 		spacesMock.On("Create", mock.Anything, &spaces.CreateCmd{
-			User:   &users.ExampleAlice,
-			Name:   "Personal",
-			Owners: []uuid.UUID{"059d78af-e675-498e-8b77-d4b2b4b9d4e7"},
+			User:     &users.ExampleAlice,
+			Name:     "Personal",
+			Managers: []uuid.UUID{"059d78af-e675-498e-8b77-d4b2b4b9d4e7"},
 		}).Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
 		fsMock.On("CreateFS", mock.Anything, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace).
 			Return(&dfs.ExampleAliceRoot, nil).Once()
@@ -80,9 +80,9 @@ func TestSpaceCreateTask(t *testing.T) {
 		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).
 			Return(&users.ExampleAlice, nil).Once()
 		spacesMock.On("Create", mock.Anything, &spaces.CreateCmd{
-			User:   &users.ExampleAlice,
-			Name:   "Personal",
-			Owners: []uuid.UUID{users.ExampleAlice.ID()},
+			User:     &users.ExampleAlice,
+			Name:     "Personal",
+			Managers: []uuid.UUID{users.ExampleAlice.ID()},
 		}).Return(nil, errs.ErrInternal).Once()
 
 		err := job.RunArgs(ctx, &scheduler.SpaceCreateArgs{
@@ -102,9 +102,9 @@ func TestSpaceCreateTask(t *testing.T) {
 		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).
 			Return(&users.ExampleAlice, nil).Once()
 		spacesMock.On("Create", mock.Anything, &spaces.CreateCmd{
-			User:   &users.ExampleAlice,
-			Name:   "Personal",
-			Owners: []uuid.UUID{users.ExampleAlice.ID()},
+			User:     &users.ExampleAlice,
+			Name:     "Personal",
+			Managers: []uuid.UUID{users.ExampleAlice.ID()},
 		}).Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
 		fsMock.On("CreateFS", mock.Anything, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace).
 			Return(nil, errs.ErrInternal).Once()
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/groups"
 	"github.com/theduckcompany/duckcloud/internal/service/oauthconsents"
 	"github.com/theduckcompany/duckcloud/internal/service/oauthsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
@@ -19,8 +22,11 @@ type UserDeleteTaskRunner struct {
 	users         users.Service
 	webSessions   websessions.Service
 	davSessions   davsessions.Service
+	davLocks      davlocks.Service
 	oauthSessions oauthsessions.Service
 	oauthConsents oauthconsents.Service
+	shares        shares.Service
+	groups        groups.Service
 	spaces        spaces.Service
 	fs            dfs.Service
 }
@@ -29,8 +35,11 @@ func NewUserDeleteTaskRunner(
 	users users.Service,
 	webSessions websessions.Service,
 	davSessions davsessions.Service,
+	davLocks davlocks.Service,
 	oauthSessions oauthsessions.Service,
 	oauthConsents oauthconsents.Service,
+	shares shares.Service,
+	groups groups.Service,
 	spaces spaces.Service,
 	fs dfs.Service,
 ) *UserDeleteTaskRunner {
@@ -38,8 +47,11 @@ func NewUserDeleteTaskRunner(
 		users,
 		webSessions,
 		davSessions,
+		davLocks,
 		oauthSessions,
 		oauthConsents,
+		shares,
+		groups,
 		spaces,
 		fs,
 	}
@@ -74,21 +86,41 @@ func (r *UserDeleteTaskRunner) RunArgs(ctx context.Context, args *scheduler.User
 		return fmt.Errorf("failed to delete all dav sessions: %w", err)
 	}
 
+	err = r.davLocks.DeleteAll(ctx, args.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to delete all dav locks: %w", err)
+	}
+
 	err = r.oauthSessions.DeleteAllForUser(ctx, args.UserID)
 	if err != nil {
 		return fmt.Errorf("failed to delete all oauth sessions: %w", err)
 	}
 
+	err = r.shares.DeleteAll(ctx, args.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to delete all shares: %w", err)
+	}
+
+	err = r.fs.DeleteAllUserGrants(ctx, args.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to delete all folder grants: %w", err)
+	}
+
+	err = r.groups.RemoveFromAllGroups(ctx, args.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to remove the user from all groups: %w", err)
+	}
+
 	userSpaces, err := r.spaces.GetAllUserSpaces(ctx, args.UserID, nil)
 	if err != nil {
 		return fmt.Errorf("failed to GetAllUserSpaces: %w", err)
 	}
 
 	for _, space := range userSpaces {
-		_, err := r.spaces.RemoveOwner(ctx, &spaces.RemoveOwnerCmd{
-			User:    user,
-			Owner:   user,
-			SpaceID: space.ID(),
+		err := r.spaces.RemoveMember(ctx, &spaces.RemoveMemberCmd{
+			User:     user,
+			MemberID: user.ID(),
+			SpaceID:  space.ID(),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to remove the user %q from the space %q: %w", user.ID(), space.ID(), err)
@@ -9,10 +9,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/groups"
 	"github.com/theduckcompany/duckcloud/internal/service/oauthconsents"
 	"github.com/theduckcompany/duckcloud/internal/service/oauthsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
@@ -26,7 +29,7 @@ func TestUserDeleteTask(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("Name", func(t *testing.T) {
-		job := NewUserDeleteTaskRunner(nil, nil, nil, nil, nil, nil, nil)
+		job := NewUserDeleteTaskRunner(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 		assert.Equal(t, "user-delete", job.Name())
 	})
 
@@ -34,23 +37,30 @@ func TestUserDeleteTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		webSessionsMock := websessions.NewMockService(t)
 		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
 		oauthSessionsMock := oauthsessions.NewMockService(t)
 		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		fsMock := dfs.NewMockService(t)
-		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, oauthSessionsMock, oauthConsentMock, spacesMock, fsMock)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
 
 		usersMock.On("GetByID", mock.Anything, uuid.UUID("b13c77ab-02fa-48a0-aad4-2079b6894d7b")).Return(&users.ExampleDeletingAlice, nil).Once()
 
 		webSessionsMock.On("DeleteAll", mock.Anything, uuid.UUID("b13c77ab-02fa-48a0-aad4-2079b6894d7b")).Return(nil).Once()
 		davSessionsMock.On("DeleteAll", mock.Anything, uuid.UUID("b13c77ab-02fa-48a0-aad4-2079b6894d7b")).Return(nil).Once()
+		davLocksMock.On("DeleteAll", mock.Anything, uuid.UUID("b13c77ab-02fa-48a0-aad4-2079b6894d7b")).Return(nil).Once()
 		oauthSessionsMock.On("DeleteAllForUser", mock.Anything, uuid.UUID("b13c77ab-02fa-48a0-aad4-2079b6894d7b")).Return(nil).Once()
+		sharesMock.On("DeleteAll", mock.Anything, uuid.UUID("b13c77ab-02fa-48a0-aad4-2079b6894d7b")).Return(nil).Once()
+		fsMock.On("DeleteAllUserGrants", mock.Anything, uuid.UUID("b13c77ab-02fa-48a0-aad4-2079b6894d7b")).Return(nil).Once()
+		groupsMock.On("RemoveFromAllGroups", mock.Anything, uuid.UUID("b13c77ab-02fa-48a0-aad4-2079b6894d7b")).Return(nil).Once()
 		spacesMock.On("GetAllUserSpaces", mock.Anything, uuid.UUID("b13c77ab-02fa-48a0-aad4-2079b6894d7b"), (*sqlstorage.PaginateCmd)(nil)).Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
-		spacesMock.On("RemoveOwner", mock.Anything, &spaces.RemoveOwnerCmd{
-			User:    &users.ExampleDeletingAlice,
-			Owner:   &users.ExampleDeletingAlice,
-			SpaceID: spaces.ExampleAlicePersonalSpace.ID(),
-		}).Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		spacesMock.On("RemoveMember", mock.Anything, &spaces.RemoveMemberCmd{
+			User:     &users.ExampleDeletingAlice,
+			MemberID: users.ExampleDeletingAlice.ID(),
+			SpaceID:  spaces.ExampleAlicePersonalSpace.ID(),
+		}).Return(nil).Once()
 
 		oauthConsentMock.On("DeleteAll", mock.Anything, uuid.UUID("b13c77ab-02fa-48a0-aad4-2079b6894d7b")).Return(nil).Once()
 		usersMock.On("HardDelete", mock.Anything, uuid.UUID("b13c77ab-02fa-48a0-aad4-2079b6894d7b")).Return(nil).Once()
@@ -63,11 +73,14 @@ func TestUserDeleteTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		webSessionsMock := websessions.NewMockService(t)
 		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
 		oauthSessionsMock := oauthsessions.NewMockService(t)
 		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		fsMock := dfs.NewMockService(t)
-		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, oauthSessionsMock, oauthConsentMock, spacesMock, fsMock)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
 
 		err := job.Run(ctx, json.RawMessage(`some-invalid-json`))
 		require.ErrorContains(t, err, "failed to unmarshal the args")
@@ -77,23 +90,30 @@ func TestUserDeleteTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		webSessionsMock := websessions.NewMockService(t)
 		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
 		oauthSessionsMock := oauthsessions.NewMockService(t)
 		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		fsMock := dfs.NewMockService(t)
-		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, oauthSessionsMock, oauthConsentMock, spacesMock, fsMock)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
 
 		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(&users.ExampleDeletingAlice, nil).Once()
 
 		webSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		davSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davLocksMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		oauthSessionsMock.On("DeleteAllForUser", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		sharesMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		fsMock.On("DeleteAllUserGrants", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		groupsMock.On("RemoveFromAllGroups", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleDeletingAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
-		spacesMock.On("RemoveOwner", mock.Anything, &spaces.RemoveOwnerCmd{
-			User:    &users.ExampleDeletingAlice,
-			Owner:   &users.ExampleDeletingAlice,
-			SpaceID: spaces.ExampleAlicePersonalSpace.ID(),
-		}).Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		spacesMock.On("RemoveMember", mock.Anything, &spaces.RemoveMemberCmd{
+			User:     &users.ExampleDeletingAlice,
+			MemberID: users.ExampleDeletingAlice.ID(),
+			SpaceID:  spaces.ExampleAlicePersonalSpace.ID(),
+		}).Return(nil).Once()
 
 		oauthConsentMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		usersMock.On("HardDelete", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
@@ -106,11 +126,14 @@ func TestUserDeleteTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		webSessionsMock := websessions.NewMockService(t)
 		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
 		oauthSessionsMock := oauthsessions.NewMockService(t)
 		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		fsMock := dfs.NewMockService(t)
-		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, oauthSessionsMock, oauthConsentMock, spacesMock, fsMock)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
 
 		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil, errs.ErrInternal).Once()
 
@@ -122,11 +145,14 @@ func TestUserDeleteTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		webSessionsMock := websessions.NewMockService(t)
 		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
 		oauthSessionsMock := oauthsessions.NewMockService(t)
 		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		fsMock := dfs.NewMockService(t)
-		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, oauthSessionsMock, oauthConsentMock, spacesMock, fsMock)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
 
 		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(&users.ExampleDeletingAlice, nil).Once()
 
@@ -141,11 +167,14 @@ func TestUserDeleteTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		webSessionsMock := websessions.NewMockService(t)
 		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
 		oauthSessionsMock := oauthsessions.NewMockService(t)
 		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		fsMock := dfs.NewMockService(t)
-		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, oauthSessionsMock, oauthConsentMock, spacesMock, fsMock)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
 
 		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(&users.ExampleDeletingAlice, nil).Once()
 
@@ -157,42 +186,158 @@ func TestUserDeleteTask(t *testing.T) {
 		require.EqualError(t, err, "failed to delete all dav sessions: some-error")
 	})
 
+	t.Run("with a dav locks deletion error", func(t *testing.T) {
+		usersMock := users.NewMockService(t)
+		webSessionsMock := websessions.NewMockService(t)
+		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
+		oauthSessionsMock := oauthsessions.NewMockService(t)
+		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
+
+		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(&users.ExampleDeletingAlice, nil).Once()
+
+		// For each users remove all the data
+		webSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davLocksMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(fmt.Errorf("some-error")).Once()
+
+		err := job.RunArgs(ctx, &scheduler.UserDeleteArgs{UserID: users.ExampleDeletingAlice.ID()})
+		require.EqualError(t, err, "failed to delete all dav locks: some-error")
+	})
+
 	t.Run("with a oauth session deletion error", func(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		webSessionsMock := websessions.NewMockService(t)
 		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
 		oauthSessionsMock := oauthsessions.NewMockService(t)
 		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		fsMock := dfs.NewMockService(t)
-		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, oauthSessionsMock, oauthConsentMock, spacesMock, fsMock)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
 
 		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(&users.ExampleDeletingAlice, nil).Once()
 
 		// For each users remove all the data
 		webSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		davSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davLocksMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		oauthSessionsMock.On("DeleteAllForUser", mock.Anything, users.ExampleDeletingAlice.ID()).Return(fmt.Errorf("some-error")).Once()
 
 		err := job.RunArgs(ctx, &scheduler.UserDeleteArgs{UserID: users.ExampleDeletingAlice.ID()})
 		require.EqualError(t, err, "failed to delete all oauth sessions: some-error")
 	})
 
+	t.Run("RunArgs with a shares DeleteAll error", func(t *testing.T) {
+		usersMock := users.NewMockService(t)
+		webSessionsMock := websessions.NewMockService(t)
+		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
+		oauthSessionsMock := oauthsessions.NewMockService(t)
+		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
+
+		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(&users.ExampleDeletingAlice, nil).Once()
+
+		// For each users remove all the data
+		webSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davLocksMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		oauthSessionsMock.On("DeleteAllForUser", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		sharesMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(fmt.Errorf("some-error")).Once()
+
+		err := job.RunArgs(ctx, &scheduler.UserDeleteArgs{UserID: users.ExampleDeletingAlice.ID()})
+		require.EqualError(t, err, "failed to delete all shares: some-error")
+	})
+
+	t.Run("RunArgs with a DeleteAllUserGrants error", func(t *testing.T) {
+		usersMock := users.NewMockService(t)
+		webSessionsMock := websessions.NewMockService(t)
+		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
+		oauthSessionsMock := oauthsessions.NewMockService(t)
+		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
+
+		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(&users.ExampleDeletingAlice, nil).Once()
+
+		// For each users remove all the data
+		webSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davLocksMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		oauthSessionsMock.On("DeleteAllForUser", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		sharesMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		fsMock.On("DeleteAllUserGrants", mock.Anything, users.ExampleDeletingAlice.ID()).Return(fmt.Errorf("some-error")).Once()
+
+		err := job.RunArgs(ctx, &scheduler.UserDeleteArgs{UserID: users.ExampleDeletingAlice.ID()})
+		require.EqualError(t, err, "failed to delete all folder grants: some-error")
+	})
+
+	t.Run("RunArgs with a RemoveFromAllGroups error", func(t *testing.T) {
+		usersMock := users.NewMockService(t)
+		webSessionsMock := websessions.NewMockService(t)
+		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
+		oauthSessionsMock := oauthsessions.NewMockService(t)
+		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
+
+		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(&users.ExampleDeletingAlice, nil).Once()
+
+		webSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davLocksMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		oauthSessionsMock.On("DeleteAllForUser", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		sharesMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		fsMock.On("DeleteAllUserGrants", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		groupsMock.On("RemoveFromAllGroups", mock.Anything, users.ExampleDeletingAlice.ID()).Return(errs.ErrInternal).Once()
+
+		err := job.RunArgs(ctx, &scheduler.UserDeleteArgs{UserID: users.ExampleDeletingAlice.ID()})
+		require.ErrorIs(t, err, errs.ErrInternal)
+		require.ErrorContains(t, err, "failed to remove the user from all groups")
+	})
+
 	t.Run("RunArgs with a GetAllUserSpaces error", func(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		webSessionsMock := websessions.NewMockService(t)
 		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
 		oauthSessionsMock := oauthsessions.NewMockService(t)
 		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		fsMock := dfs.NewMockService(t)
-		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, oauthSessionsMock, oauthConsentMock, spacesMock, fsMock)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
 
 		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(&users.ExampleDeletingAlice, nil).Once()
 
 		webSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		davSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davLocksMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		oauthSessionsMock.On("DeleteAllForUser", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		sharesMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		fsMock.On("DeleteAllUserGrants", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		groupsMock.On("RemoveFromAllGroups", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleDeletingAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).Return(nil, errs.ErrInternal).Once()
 
 		err := job.RunArgs(ctx, &scheduler.UserDeleteArgs{UserID: users.ExampleDeletingAlice.ID()})
@@ -203,23 +348,30 @@ func TestUserDeleteTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		webSessionsMock := websessions.NewMockService(t)
 		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
 		oauthSessionsMock := oauthsessions.NewMockService(t)
 		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		fsMock := dfs.NewMockService(t)
-		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, oauthSessionsMock, oauthConsentMock, spacesMock, fsMock)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
 
 		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(&users.ExampleDeletingAlice, nil).Once()
 
 		webSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		davSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davLocksMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		oauthSessionsMock.On("DeleteAllForUser", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		sharesMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		fsMock.On("DeleteAllUserGrants", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		groupsMock.On("RemoveFromAllGroups", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleDeletingAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
-		spacesMock.On("RemoveOwner", mock.Anything, &spaces.RemoveOwnerCmd{
-			User:    &users.ExampleDeletingAlice,
-			Owner:   &users.ExampleDeletingAlice,
-			SpaceID: spaces.ExampleAlicePersonalSpace.ID(),
-		}).Return(nil, errs.ErrBadRequest).Once()
+		spacesMock.On("RemoveMember", mock.Anything, &spaces.RemoveMemberCmd{
+			User:     &users.ExampleDeletingAlice,
+			MemberID: users.ExampleDeletingAlice.ID(),
+			SpaceID:  spaces.ExampleAlicePersonalSpace.ID(),
+		}).Return(errs.ErrBadRequest).Once()
 
 		err := job.RunArgs(ctx, &scheduler.UserDeleteArgs{UserID: users.ExampleDeletingAlice.ID()})
 		assert.ErrorIs(t, err, errs.ErrBadRequest)
@@ -229,24 +381,31 @@ func TestUserDeleteTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		webSessionsMock := websessions.NewMockService(t)
 		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
 		oauthSessionsMock := oauthsessions.NewMockService(t)
 		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		fsMock := dfs.NewMockService(t)
-		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, oauthSessionsMock, oauthConsentMock, spacesMock, fsMock)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
 
 		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(&users.ExampleDeletingAlice, nil).Once()
 
 		// For each users remove all the data
 		webSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		davSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davLocksMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		oauthSessionsMock.On("DeleteAllForUser", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		sharesMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		fsMock.On("DeleteAllUserGrants", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		groupsMock.On("RemoveFromAllGroups", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleDeletingAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
-		spacesMock.On("RemoveOwner", mock.Anything, &spaces.RemoveOwnerCmd{
-			User:    &users.ExampleDeletingAlice,
-			Owner:   &users.ExampleDeletingAlice,
-			SpaceID: spaces.ExampleAlicePersonalSpace.ID(),
-		}).Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		spacesMock.On("RemoveMember", mock.Anything, &spaces.RemoveMemberCmd{
+			User:     &users.ExampleDeletingAlice,
+			MemberID: users.ExampleDeletingAlice.ID(),
+			SpaceID:  spaces.ExampleAlicePersonalSpace.ID(),
+		}).Return(nil).Once()
 
 		oauthConsentMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(fmt.Errorf("some-error")).Once()
 
@@ -258,24 +417,31 @@ func TestUserDeleteTask(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		webSessionsMock := websessions.NewMockService(t)
 		davSessionsMock := davsessions.NewMockService(t)
+		davLocksMock := davlocks.NewMockService(t)
 		oauthSessionsMock := oauthsessions.NewMockService(t)
 		oauthConsentMock := oauthconsents.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		groupsMock := groups.NewMockService(t)
 		spacesMock := spaces.NewMockService(t)
 		fsMock := dfs.NewMockService(t)
-		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, oauthSessionsMock, oauthConsentMock, spacesMock, fsMock)
+		job := NewUserDeleteTaskRunner(usersMock, webSessionsMock, davSessionsMock, davLocksMock, oauthSessionsMock, oauthConsentMock, sharesMock, groupsMock, spacesMock, fsMock)
 
 		usersMock.On("GetByID", mock.Anything, users.ExampleDeletingAlice.ID()).Return(&users.ExampleDeletingAlice, nil).Once()
 
 		// For each users remove all the data
 		webSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		davSessionsMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		davLocksMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		oauthSessionsMock.On("DeleteAllForUser", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		sharesMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		fsMock.On("DeleteAllUserGrants", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
+		groupsMock.On("RemoveFromAllGroups", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleDeletingAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
-		spacesMock.On("RemoveOwner", mock.Anything, &spaces.RemoveOwnerCmd{
-			User:    &users.ExampleDeletingAlice,
-			Owner:   &users.ExampleDeletingAlice,
-			SpaceID: spaces.ExampleAlicePersonalSpace.ID(),
-		}).Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		spacesMock.On("RemoveMember", mock.Anything, &spaces.RemoveMemberCmd{
+			User:     &users.ExampleDeletingAlice,
+			MemberID: users.ExampleDeletingAlice.ID(),
+			SpaceID:  spaces.ExampleAlicePersonalSpace.ID(),
+		}).Return(nil).Once()
 
 		oauthConsentMock.On("DeleteAll", mock.Anything, users.ExampleDeletingAlice.ID()).Return(nil).Once()
 		usersMock.On("HardDelete", mock.Anything, users.ExampleDeletingAlice.ID()).Return(fmt.Errorf("some-error")).Once()
@@ -0,0 +1,47 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Transactor is implemented by the Querier able to start a transaction.
+type Transactor interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+func (c *Client) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return c.db.BeginTx(ctx, opts)
+}
+
+// RunInTx runs fn inside a transaction. The transaction is committed if fn
+// succeed and rolled back otherwise.
+//
+// If db is already a transaction fn is run directly inside it. All the
+// queries made by fn must use the given Querier: the pool only have a single
+// connection.
+func RunInTx(ctx context.Context, db Querier, fn func(tx Querier) error) error {
+	transactor, ok := db.(Transactor)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := transactor.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin the transaction: %w", err)
+	}
+
+	err = fn(tx)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit the transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package sqlstorage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunInTx(t *testing.T) {
+	ctx := context.Background()
+
+	db := NewTestStorage(t)
+	_, err := db.ExecContext(ctx, "CREATE TABLE tx_test (id TEXT NOT NULL)")
+	require.NoError(t, err)
+
+	count := func(t *testing.T) int {
+		t.Helper()
+
+		var res int
+		err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM tx_test").Scan(&res)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	t.Run("success", func(t *testing.T) {
+		err := RunInTx(ctx, db, func(tx Querier) error {
+			_, err := tx.ExecContext(ctx, "INSERT INTO tx_test(id) VALUES ('a')")
+			return err
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, count(t))
+	})
+
+	t.Run("with an error rollback all the writes", func(t *testing.T) {
+		err := RunInTx(ctx, db, func(tx Querier) error {
+			_, err := tx.ExecContext(ctx, "INSERT INTO tx_test(id) VALUES ('b')")
+			require.NoError(t, err)
+
+			return errors.New("some-error")
+		})
+		require.EqualError(t, err, "some-error")
+
+		assert.Equal(t, 1, count(t))
+	})
+
+	t.Run("inside a transaction reuse the transaction", func(t *testing.T) {
+		err := RunInTx(ctx, db, func(tx Querier) error {
+			return RunInTx(ctx, tx, func(nested Querier) error {
+				assert.Equal(t, tx, nested)
+
+				_, err := nested.ExecContext(ctx, "INSERT INTO tx_test(id) VALUES ('c')")
+				return err
+			})
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, count(t))
+	})
+}
@@ -7,12 +7,15 @@ import (
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 	"github.com/theduckcompany/duckcloud/internal/service/config"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/groups"
 	"github.com/theduckcompany/duckcloud/internal/service/masterkey"
 	"github.com/theduckcompany/duckcloud/internal/service/oauthconsents"
 	"github.com/theduckcompany/duckcloud/internal/service/oauthsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/stats"
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/runner"
@@ -34,8 +37,10 @@ type Server struct {
 	// Services
 	ConfigSvc        config.Service
 	SpacesSvc        spaces.Service
+	GroupsSvc        groups.Service
 	SchedulerSvc     scheduler.Service
 	DavSessionsSvc   davsessions.Service
+	DavLocksSvc      davlocks.Service
 	WebSessionsSvc   websessions.Service
 	OauthSessionsSvc oauthsessions.Service
 	OauthConsentsSvc oauthconsents.Service
@@ -45,6 +50,7 @@ type Server struct {
 	RunnerSvc        runner.Service
 	MasterKeySvc     masterkey.Service
 	StatsSvc         stats.Service
+	SharesSvc        shares.Service
 
 	User *users.User
 }
@@ -62,9 +68,10 @@ func NewServer(t *testing.T) *Server {
 
 	configSvc := config.Init(db)
 	schedulerSvc := scheduler.Init(db, tools)
-	spacesSvc := spaces.Init(tools, db, schedulerSvc)
+	groupsSvc := groups.Init(tools, db, schedulerSvc)
+	spacesSvc := spaces.Init(tools, db, schedulerSvc, groupsSvc)
 	webSessionsSvc := websessions.Init(tools, db)
-	davSessionsSvc := davsessions.Init(db, spacesSvc, tools)
+	sharesSvc := shares.Init(db, spacesSvc, tools)
 	oauthSessionsSvc := oauthsessions.Init(tools, db)
 	oauthConsentsSvc := oauthconsents.Init(tools, db)
 	usersSvc := users.Init(tools, db, schedulerSvc)
@@ -76,17 +83,24 @@ func NewServer(t *testing.T) *Server {
 	filesInit, err := files.Init(masterKeySvc, "/", afs, tools, db)
 	require.NoError(t, err)
 
-	dfsInit, err := dfs.Init(db, spacesSvc, filesInit.Service, schedulerSvc, usersSvc, tools, statsSvc)
+	dfsInit, err := dfs.Init(db, spacesSvc, filesInit.Service, schedulerSvc, usersSvc, tools, statsSvc, sharesSvc)
 	require.NoError(t, err)
 
-	tasks := tasks.Init(dfsInit.Service, spacesSvc, usersSvc, webSessionsSvc, davSessionsSvc, oauthSessionsSvc, oauthConsentsSvc)
+	davSessionsSvc := davsessions.Init(db, spacesSvc, dfsInit.Service, tools)
+	davLocksSvc := davlocks.Init(tools, db)
+
+	tasks := tasks.Init(dfsInit.Service, spacesSvc, usersSvc, webSessionsSvc, davSessionsSvc, oauthSessionsSvc, oauthConsentsSvc, sharesSvc, groupsSvc, davLocksSvc)
 
 	runnerSvc := runner.Init(
 		[]runner.TaskRunner{
 			dfsInit.FSGCTask,
+			dfsInit.FSEmptyTrashTask,
 			dfsInit.FSMoveTask,
+			dfsInit.FSCopyTask,
 			dfsInit.FSRefreshSizeTask,
 			dfsInit.FSRemoveDuplicateFilesRunner,
+			dfsInit.FSPruneVersionsTask,
+			dfsInit.FSIndexContentTask,
 			tasks.UserCreateTask,
 			tasks.UserDeleteTask,
 			tasks.SpaceCreateTask,
@@ -115,13 +129,16 @@ func NewServer(t *testing.T) *Server {
 		// Services
 		ConfigSvc:        configSvc,
 		SpacesSvc:        spacesSvc,
+		GroupsSvc:        groupsSvc,
 		SchedulerSvc:     schedulerSvc,
 		DavSessionsSvc:   davSessionsSvc,
+		DavLocksSvc:      davLocksSvc,
 		WebSessionsSvc:   webSessionsSvc,
 		OauthSessionsSvc: oauthSessionsSvc,
 		OauthConsentsSvc: oauthConsentsSvc,
 		MasterKeySvc:     masterKeySvc,
 		StatsSvc:         statsSvc,
+		SharesSvc:        sharesSvc,
 
 		Files:     filesInit.Service,
 		DFSSvc:    dfsInit.Service,
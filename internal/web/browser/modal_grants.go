@@ -0,0 +1,227 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/logger"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/router"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+	"github.com/theduckcompany/duckcloud/internal/web/auth"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	"github.com/theduckcompany/duckcloud/internal/web/html/templates/browser"
+)
+
+// grantsModalHandler shares a folder with some users without making them
+// members of the space. Only the space managers can use it, the permissions are
+// checked by the dfs service.
+type grantsModalHandler struct {
+	auth   *auth.Authenticator
+	spaces spaces.Service
+	users  users.Service
+	html   html.Writer
+	uuid   uuid.Service
+	fs     dfs.Service
+}
+
+func newGrantsModalHandler(
+	auth *auth.Authenticator,
+	spaces spaces.Service,
+	users users.Service,
+	html html.Writer,
+	uuid uuid.Service,
+	fs dfs.Service,
+) *grantsModalHandler {
+	return &grantsModalHandler{auth, spaces, users, html, uuid, fs}
+}
+
+func (h *grantsModalHandler) Register(r chi.Router, mids *router.Middlewares) {
+	if mids != nil {
+		r = r.With(mids.Defaults()...)
+	}
+
+	r.Get("/browser/grants", h.getGrantsModal)
+	r.Post("/browser/grants", h.createGrant)
+	r.Post("/browser/grants/delete", h.deleteGrant)
+}
+
+func (h *grantsModalHandler) getGrantsModal(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	target, abort := h.getTarget(w, r, user)
+	if abort {
+		return
+	}
+
+	h.renderGrantsModal(w, r, http.StatusOK, user, &browser.GrantsTemplate{Target: target})
+}
+
+func (h *grantsModalHandler) createGrant(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	target, abort := h.getTarget(w, r, user)
+	if abort {
+		return
+	}
+
+	userID, err := h.uuid.Parse(r.FormValue("userID"))
+	if err != nil {
+		h.renderGrantsModal(w, r, http.StatusUnprocessableEntity, user, &browser.GrantsTemplate{
+			Error:  ptr.To("invalid user"),
+			Target: target,
+		})
+		return
+	}
+
+	grantee, err := h.users.GetByID(r.Context(), userID)
+	if errors.Is(err, errs.ErrNotFound) {
+		h.renderGrantsModal(w, r, http.StatusUnprocessableEntity, user, &browser.GrantsTemplate{
+			Error:  ptr.To("invalid user"),
+			Target: target,
+		})
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetByID: %w", err))
+		return
+	}
+
+	_, err = h.fs.CreateGrant(r.Context(), &dfs.CreateGrantCmd{
+		Path:       target,
+		User:       grantee,
+		Permission: dfs.Permission(r.FormValue("permission")),
+		CreatedBy:  user,
+	})
+	if errors.Is(err, errs.ErrValidation) || errors.Is(err, errs.ErrBadRequest) {
+		h.renderGrantsModal(w, r, http.StatusUnprocessableEntity, user, &browser.GrantsTemplate{
+			Error:  ptr.To(err.Error()),
+			Target: target,
+		})
+		return
+	}
+
+	if err != nil {
+		h.writeGrantsError(w, r, fmt.Errorf("failed to CreateGrant: %w", err))
+		return
+	}
+
+	h.renderGrantsModal(w, r, http.StatusOK, user, &browser.GrantsTemplate{Target: target})
+}
+
+func (h *grantsModalHandler) deleteGrant(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	target, abort := h.getTarget(w, r, user)
+	if abort {
+		return
+	}
+
+	grantID, err := h.uuid.Parse(r.FormValue("grantID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err = h.fs.DeleteGrant(r.Context(), user, grantID)
+	if err != nil {
+		h.writeGrantsError(w, r, fmt.Errorf("failed to DeleteGrant: %w", err))
+		return
+	}
+
+	h.renderGrantsModal(w, r, http.StatusOK, user, &browser.GrantsTemplate{Target: target})
+}
+
+func (h *grantsModalHandler) renderGrantsModal(w http.ResponseWriter, r *http.Request, status int, user *users.User, tmpl *browser.GrantsTemplate) {
+	grants, err := h.fs.GetINodeGrants(r.Context(), user, tmpl.Target)
+	if err != nil {
+		h.writeGrantsError(w, r, fmt.Errorf("failed to GetINodeGrants: %w", err))
+		return
+	}
+
+	members, err := h.spaces.GetAllMembers(r.Context(), user, tmpl.Target.Space().ID())
+	if err != nil {
+		h.writeGrantsError(w, r, fmt.Errorf("failed to GetAllMembers: %w", err))
+		return
+	}
+
+	allUsers, err := h.users.GetAll(r.Context(), nil)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetAll users: %w", err))
+		return
+	}
+
+	tmpl.Grants = grants
+	tmpl.Users = make(map[uuid.UUID]users.User, len(allUsers))
+	tmpl.Candidates = []users.User{}
+
+	for _, u := range allUsers {
+		tmpl.Users[u.ID()] = u
+
+		// The members already have an access to the whole space.
+		isMember := slices.ContainsFunc(members, func(m spaces.Member) bool { return m.UserID() == u.ID() })
+		isGranted := slices.ContainsFunc(grants, func(g dfs.Grant) bool { return g.UserID() == u.ID() })
+		if !isMember && !isGranted && u.Status() == users.Active {
+			tmpl.Candidates = append(tmpl.Candidates, u)
+		}
+	}
+
+	h.html.WriteHTMLTemplate(w, r, status, tmpl)
+}
+
+func (h *grantsModalHandler) getTarget(w http.ResponseWriter, r *http.Request, user *users.User) (*dfs.PathCmd, bool) {
+	filePath := r.FormValue("path")
+	if len(filePath) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, true
+	}
+
+	spaceID, err := h.uuid.Parse(r.FormValue("spaceID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, true
+	}
+
+	space, err := h.spaces.GetUserSpace(r.Context(), user.ID(), spaceID)
+	if errors.Is(err, errs.ErrNotFound) || errors.Is(err, errs.ErrUnauthorized) {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, true
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserSpace: %w", err))
+		return nil, true
+	}
+
+	return dfs.NewPathCmd(space, filePath), false
+}
+
+func (h *grantsModalHandler) writeGrantsError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errs.ErrUnauthorized):
+		w.WriteHeader(http.StatusForbidden)
+		logger.LogEntrySetError(r.Context(), err)
+	case errors.Is(err, errs.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		logger.LogEntrySetError(r.Context(), err)
+	default:
+		h.html.WriteHTMLErrorPage(w, r, err)
+	}
+}
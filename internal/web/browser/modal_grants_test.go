@@ -0,0 +1,265 @@
+package browser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/service/websessions"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+	"github.com/theduckcompany/duckcloud/internal/web/auth"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	"github.com/theduckcompany/duckcloud/internal/web/html/templates/browser"
+)
+
+func Test_GrantsModalHandler(t *testing.T) {
+	spaceID := spaces.ExampleAlicePersonalSpace.ID()
+	target := dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a")
+
+	t.Run("getGrantsModal success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newGrantsModalHandler(auth, spacesMock, usersMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("GetINodeGrants", mock.Anything, &users.ExampleAlice, target).Return([]dfs.Grant{}, nil).Once()
+		spacesMock.On("GetAllMembers", mock.Anything, &users.ExampleAlice, spaceID).
+			Return([]spaces.Member{spaces.ExampleAliceManager}, nil).Once()
+		usersMock.On("GetAll", mock.Anything, (*sqlstorage.PaginateCmd)(nil)).
+			Return([]users.User{users.ExampleAlice, users.ExampleBob}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.GrantsTemplate{
+			Error:  nil,
+			Target: target,
+			Grants: []dfs.Grant{},
+			Users: map[uuid.UUID]users.User{
+				users.ExampleAlice.ID(): users.ExampleAlice,
+				users.ExampleBob.ID():   users.ExampleBob,
+			},
+			Candidates: []users.User{users.ExampleBob},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/grants?path=/dir-a&spaceID="+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getGrantsModal with a user not manager", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newGrantsModalHandler(auth, spacesMock, usersMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.BobWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleBob.ID()).Return(&users.ExampleBob, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleBob.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("GetINodeGrants", mock.Anything, &users.ExampleBob, target).
+			Return(nil, errs.Unauthorized(spaces.ErrNotManager)).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/grants?path=/dir-a&spaceID="+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Result().StatusCode)
+	})
+
+	t.Run("createGrant success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newGrantsModalHandler(auth, spacesMock, usersMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(users.ExampleBob.ID())).Return(users.ExampleBob.ID(), nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleBob.ID()).Return(&users.ExampleBob, nil).Once()
+
+		fsMock.On("CreateGrant", mock.Anything, &dfs.CreateGrantCmd{
+			Path:       target,
+			User:       &users.ExampleBob,
+			Permission: dfs.PermissionWrite,
+			CreatedBy:  &users.ExampleAlice,
+		}).Return(&dfs.ExampleBobWriteGrant, nil).Once()
+
+		fsMock.On("GetINodeGrants", mock.Anything, &users.ExampleAlice, target).
+			Return([]dfs.Grant{dfs.ExampleBobWriteGrant}, nil).Once()
+		spacesMock.On("GetAllMembers", mock.Anything, &users.ExampleAlice, spaceID).
+			Return([]spaces.Member{spaces.ExampleAliceManager}, nil).Once()
+		usersMock.On("GetAll", mock.Anything, (*sqlstorage.PaginateCmd)(nil)).
+			Return([]users.User{users.ExampleAlice, users.ExampleBob}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.GrantsTemplate{
+			Error:  nil,
+			Target: target,
+			Grants: []dfs.Grant{dfs.ExampleBobWriteGrant},
+			Users: map[uuid.UUID]users.User{
+				users.ExampleAlice.ID(): users.ExampleAlice,
+				users.ExampleBob.ID():   users.ExampleBob,
+			},
+			Candidates: []users.User{},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/grants", strings.NewReader(url.Values{
+			"path":       []string{"/dir-a"},
+			"spaceID":    []string{string(spaceID)},
+			"userID":     []string{string(users.ExampleBob.ID())},
+			"permission": []string{"write"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("createGrant with an already granted user", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newGrantsModalHandler(auth, spacesMock, usersMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(users.ExampleBob.ID())).Return(users.ExampleBob.ID(), nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleBob.ID()).Return(&users.ExampleBob, nil).Once()
+
+		fsMock.On("CreateGrant", mock.Anything, &dfs.CreateGrantCmd{
+			Path:       target,
+			User:       &users.ExampleBob,
+			Permission: dfs.PermissionRead,
+			CreatedBy:  &users.ExampleAlice,
+		}).Return(nil, errs.BadRequest(dfs.ErrAlreadyGranted)).Once()
+
+		fsMock.On("GetINodeGrants", mock.Anything, &users.ExampleAlice, target).
+			Return([]dfs.Grant{dfs.ExampleBobReadGrant}, nil).Once()
+		spacesMock.On("GetAllMembers", mock.Anything, &users.ExampleAlice, spaceID).
+			Return([]spaces.Member{spaces.ExampleAliceManager}, nil).Once()
+		usersMock.On("GetAll", mock.Anything, (*sqlstorage.PaginateCmd)(nil)).
+			Return([]users.User{users.ExampleAlice, users.ExampleBob}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusUnprocessableEntity, &browser.GrantsTemplate{
+			Error:  ptr.To(errs.BadRequest(dfs.ErrAlreadyGranted).Error()),
+			Target: target,
+			Grants: []dfs.Grant{dfs.ExampleBobReadGrant},
+			Users: map[uuid.UUID]users.User{
+				users.ExampleAlice.ID(): users.ExampleAlice,
+				users.ExampleBob.ID():   users.ExampleBob,
+			},
+			Candidates: []users.User{},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/grants", strings.NewReader(url.Values{
+			"path":       []string{"/dir-a"},
+			"spaceID":    []string{string(spaceID)},
+			"userID":     []string{string(users.ExampleBob.ID())},
+			"permission": []string{"read"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("deleteGrant success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newGrantsModalHandler(auth, spacesMock, usersMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(dfs.ExampleBobReadGrant.ID())).Return(dfs.ExampleBobReadGrant.ID(), nil).Once()
+		fsMock.On("DeleteGrant", mock.Anything, &users.ExampleAlice, dfs.ExampleBobReadGrant.ID()).Return(nil).Once()
+
+		fsMock.On("GetINodeGrants", mock.Anything, &users.ExampleAlice, target).Return([]dfs.Grant{}, nil).Once()
+		spacesMock.On("GetAllMembers", mock.Anything, &users.ExampleAlice, spaceID).
+			Return([]spaces.Member{spaces.ExampleAliceManager}, nil).Once()
+		usersMock.On("GetAll", mock.Anything, (*sqlstorage.PaginateCmd)(nil)).
+			Return([]users.User{users.ExampleAlice, users.ExampleBob}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.GrantsTemplate{
+			Error:  nil,
+			Target: target,
+			Grants: []dfs.Grant{},
+			Users: map[uuid.UUID]users.User{
+				users.ExampleAlice.ID(): users.ExampleAlice,
+				users.ExampleBob.ID():   users.ExampleBob,
+			},
+			Candidates: []users.User{users.ExampleBob},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/grants/delete", strings.NewReader(url.Values{
+			"path":    []string{"/dir-a"},
+			"spaceID": []string{string(spaceID)},
+			"grantID": []string{string(dfs.ExampleBobReadGrant.ID())},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+}
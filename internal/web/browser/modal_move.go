@@ -7,8 +7,10 @@ import (
 	"path"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
 	"github.com/theduckcompany/duckcloud/internal/tools/router"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
@@ -19,17 +21,25 @@ import (
 )
 
 type moveModalCmd struct {
+	User     *users.User
 	Src      *dfs.PathCmd
 	Dst      *dfs.PathCmd
 	ErrorMsg string
 }
 
+// moveModalHandler handles the modal used to select a destination folder.
+//
+// The same modal is used to move and to copy an element, depending on the
+// action given at the creation. The destination can be in any space of the
+// user.
 type moveModalHandler struct {
 	auth   *auth.Authenticator
 	spaces spaces.Service
 	html   html.Writer
 	uuid   uuid.Service
 	fs     dfs.Service
+	locks  davlocks.Service
+	action string
 }
 
 func newMoveModalHandler(
@@ -38,8 +48,20 @@ func newMoveModalHandler(
 	html html.Writer,
 	uuid uuid.Service,
 	fs dfs.Service,
+	locks davlocks.Service,
 ) *moveModalHandler {
-	return &moveModalHandler{auth, spaces, html, uuid, fs}
+	return &moveModalHandler{auth, spaces, html, uuid, fs, locks, browser.MoveAction}
+}
+
+func newCopyModalHandler(
+	auth *auth.Authenticator,
+	spaces spaces.Service,
+	html html.Writer,
+	uuid uuid.Service,
+	fs dfs.Service,
+	locks davlocks.Service,
+) *moveModalHandler {
+	return &moveModalHandler{auth, spaces, html, uuid, fs, locks, browser.CopyAction}
 }
 
 func (h *moveModalHandler) Register(r chi.Router, mids *router.Middlewares) {
@@ -47,17 +69,17 @@ func (h *moveModalHandler) Register(r chi.Router, mids *router.Middlewares) {
 		r = r.With(mids.Defaults()...)
 	}
 
-	r.Get("/browser/move", h.getMoveModal)
-	r.Post("/browser/move", h.handleMoveReq)
+	r.Get("/browser/"+h.action, h.getMoveModal)
+	r.Post("/browser/"+h.action, h.handleMoveReq)
 }
 
 func (h *moveModalHandler) getMoveModal(w http.ResponseWriter, r *http.Request) {
-	_, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
 	if abort {
 		return
 	}
 
-	srcPath, dstPath, abort := h.getMoveParams(w, r)
+	srcPath, dstPath, abort := h.getMoveParams(w, r, user)
 	if abort {
 		return
 	}
@@ -65,6 +87,7 @@ func (h *moveModalHandler) getMoveModal(w http.ResponseWriter, r *http.Request)
 	lastElem := r.URL.Query().Get("last")
 	if lastElem != "" {
 		h.renderMoreContent(w, r, &moveModalCmd{
+			User:     user,
 			ErrorMsg: "",
 			Src:      srcPath,
 			Dst:      dstPath,
@@ -73,6 +96,7 @@ func (h *moveModalHandler) getMoveModal(w http.ResponseWriter, r *http.Request)
 	}
 
 	h.renderMoveModal(w, r, &moveModalCmd{
+		User:     user,
 		ErrorMsg: "",
 		Src:      srcPath,
 		Dst:      dstPath,
@@ -100,16 +124,24 @@ func (h *moveModalHandler) renderMoveModal(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	userSpaces, err := h.spaces.GetAllUserSpaces(r.Context(), cmd.User.ID(), nil)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetAllUserSpaces: %w", err))
+		return
+	}
+
 	folderContent := make(map[dfs.PathCmd]dfs.INode, len(childs))
 	for _, child := range childs {
 		folderContent[*dfs.NewPathCmd(cmd.Dst.Space(), path.Join(cmd.Dst.Path(), child.Name()))] = child
 	}
 
 	h.html.WriteHTMLTemplate(w, r, status, &browser.MoveTemplate{
+		Action:        h.action,
 		SrcPath:       cmd.Src,
 		SrcInode:      srcInode,
 		DstPath:       cmd.Dst,
 		FolderContent: folderContent,
+		Spaces:        userSpaces,
 		PageSize:      PageSize,
 	})
 }
@@ -130,6 +162,7 @@ func (h *moveModalHandler) renderMoreContent(w http.ResponseWriter, r *http.Requ
 	}
 
 	h.html.WriteHTMLTemplate(w, r, http.StatusOK, &browser.MoveRowsTemplate{
+		Action:        h.action,
 		SrcPath:       cmd.Src,
 		DstPath:       cmd.Dst,
 		FolderContent: folderContent,
@@ -145,18 +178,58 @@ func (h *moveModalHandler) handleMoveReq(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	srcPath, dstPath, abort := h.getMoveParams(w, r)
+	srcPath, dstPath, abort := h.getMoveParams(w, r, user)
 	if abort {
 		return
 	}
 
-	err := h.fs.Move(ctx, &dfs.MoveCmd{
-		Src:     srcPath,
-		Dst:     dfs.NewPathCmd(dstPath.Space(), path.Join(dstPath.Path(), path.Base(srcPath.Path()))),
-		MovedBy: user,
-	})
-	if errors.Is(err, errs.ErrValidation) {
+	target := dfs.NewPathCmd(dstPath.Space(), path.Join(dstPath.Path(), path.Base(srcPath.Path())))
+
+	// A copy leaves the source untouched.
+	modified := []*dfs.PathCmd{target}
+	if h.action == browser.MoveAction {
+		modified = append(modified, srcPath)
+	}
+
+	err := confirmUnlocked(ctx, h.locks, user, modified...)
+	if errors.Is(err, davlocks.ErrLocked) {
+		w.WriteHeader(http.StatusLocked)
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, err)
+		return
+	}
+
+	ok, err := checkPreconditions(ctx, h.fs, r.Header, srcPath)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, err)
+		return
+	}
+
+	if !ok {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	switch h.action {
+	case browser.CopyAction:
+		err = h.fs.Copy(ctx, &dfs.CopyCmd{
+			Src:      srcPath,
+			Dst:      target,
+			CopiedBy: user,
+		})
+	default:
+		err = h.fs.Move(ctx, &dfs.MoveCmd{
+			Src:     srcPath,
+			Dst:     target,
+			MovedBy: user,
+		})
+	}
+	if errors.Is(err, errs.ErrValidation) || errors.Is(err, errs.ErrBadRequest) {
 		h.renderMoveModal(w, r, &moveModalCmd{
+			User:     user,
 			ErrorMsg: err.Error(),
 			Src:      srcPath,
 			Dst:      dstPath,
@@ -165,16 +238,25 @@ func (h *moveModalHandler) handleMoveReq(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err != nil {
-		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to move the file: %w", err))
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to %s the file: %w", h.action, err))
 		return
 	}
 
+	if h.action == browser.MoveAction {
+		// The WebDAV locks are not moved with the resource.
+		err = h.locks.RemoveAll(ctx, srcPath)
+		if err != nil {
+			h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to remove the locks: %w", err))
+			return
+		}
+	}
+
 	w.Header().Add("HX-Trigger", "refreshPage")
 	w.Header().Add("HX-Reswap", "none")
 	w.WriteHeader(http.StatusOK)
 }
 
-func (h *moveModalHandler) getMoveParams(w http.ResponseWriter, r *http.Request) (*dfs.PathCmd, *dfs.PathCmd, bool) {
+func (h *moveModalHandler) getMoveParams(w http.ResponseWriter, r *http.Request, user *users.User) (*dfs.PathCmd, *dfs.PathCmd, bool) {
 	srcPath := r.FormValue("srcPath")
 	if len(srcPath) == 0 {
 		w.WriteHeader(http.StatusNotFound)
@@ -193,14 +275,35 @@ func (h *moveModalHandler) getMoveParams(w http.ResponseWriter, r *http.Request)
 		return nil, nil, true
 	}
 
-	space, err := h.spaces.GetByID(r.Context(), spaceID)
+	space, err := h.spaces.GetUserSpace(r.Context(), user.ID(), spaceID)
+	if errors.Is(err, errs.ErrNotFound) || errors.Is(err, errs.ErrUnauthorized) {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, true
+	}
+
 	if err != nil {
-		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to get the space: %w", err))
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserSpace: %w", err))
 		return nil, nil, true
 	}
 
+	// The destination is in the source space unless an other space is given.
+	dstSpace := space
+	if rawDstSpaceID := r.FormValue("dstSpaceID"); rawDstSpaceID != "" && rawDstSpaceID != string(space.ID()) {
+		dstSpaceID, err := h.uuid.Parse(rawDstSpaceID)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, nil, true
+		}
+
+		dstSpace, err = h.spaces.GetUserSpace(r.Context(), user.ID(), dstSpaceID)
+		if err != nil {
+			h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserSpace: %w", err))
+			return nil, nil, true
+		}
+	}
+
 	srcCmd := dfs.NewPathCmd(space, srcPath)
-	dstCmd := dfs.NewPathCmd(space, dstPath)
+	dstCmd := dfs.NewPathCmd(dstSpace, dstPath)
 
 	return srcCmd, dstCmd, false
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
@@ -32,7 +33,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -40,7 +42,7 @@ func Test_MoveModalHandler(t *testing.T) {
 
 		// Get the space
 		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
-		spacesMock.On("GetByID", mock.Anything, uuid.UUID("some-space-id")).
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
 			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
 
 		fsMock.On("ListDir", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
@@ -50,11 +52,16 @@ func Test_MoveModalHandler(t *testing.T) {
 		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg")).
 			Return(&dfs.ExampleAliceFile, nil).Once()
 
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
+
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.MoveTemplate{
+			Action:        browser.MoveAction,
 			SrcPath:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
 			SrcInode:      &dfs.ExampleAliceFile,
 			DstPath:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
 			FolderContent: map[dfs.PathCmd]dfs.INode{*dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.txt"): dfs.ExampleAliceFile2},
+			Spaces:        []spaces.Space{spaces.ExampleAlicePersonalSpace},
 			PageSize:      PageSize,
 		})
 
@@ -79,7 +86,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -87,7 +95,7 @@ func Test_MoveModalHandler(t *testing.T) {
 
 		// Get the space
 		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
-		spacesMock.On("GetByID", mock.Anything, uuid.UUID("some-space-id")).
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
 			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
 
 		fsMock.On("ListDir", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
@@ -95,6 +103,7 @@ func Test_MoveModalHandler(t *testing.T) {
 			Return([]dfs.INode{dfs.ExampleAliceFile2}, nil).Once()
 
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.MoveRowsTemplate{
+			Action:        browser.MoveAction,
 			SrcPath:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
 			DstPath:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
 			FolderContent: map[dfs.PathCmd]dfs.INode{*dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.txt"): dfs.ExampleAliceFile2},
@@ -123,7 +132,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(nil, websessions.ErrMissingSessionToken).Once()
@@ -154,7 +164,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -188,7 +199,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -196,11 +208,9 @@ func Test_MoveModalHandler(t *testing.T) {
 
 		// Get the space
 		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
-		spacesMock.On("GetByID", mock.Anything, uuid.UUID("some-space-id")).
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
 			Return(nil, errs.ErrNotFound).Once()
 
-		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to get the space: %w", errs.ErrNotFound))
-
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/browser/move", nil)
 		r.URL.RawQuery = url.Values{
@@ -212,6 +222,10 @@ func Test_MoveModalHandler(t *testing.T) {
 		srv := chi.NewRouter()
 		handler.Register(srv, nil)
 		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
 	})
 
 	t.Run("getMoveModa/getMoveReq with a missing dstPath arg", func(t *testing.T) {
@@ -222,7 +236,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -253,7 +268,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -284,7 +300,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/browser/move", nil)
@@ -296,17 +313,23 @@ func Test_MoveModalHandler(t *testing.T) {
 		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg")).
 			Return(&dfs.ExampleAliceFile, nil).Once()
 
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
+
 		htmlMock.On("WriteHTMLTemplate", w, r, http.StatusOK, &browser.MoveTemplate{
+			Action:        browser.MoveAction,
 			SrcPath:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
 			SrcInode:      &dfs.ExampleAliceFile,
 			DstPath:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
 			FolderContent: map[dfs.PathCmd]dfs.INode{*dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.txt"): dfs.ExampleAliceFile2},
+			Spaces:        []spaces.Space{spaces.ExampleAlicePersonalSpace},
 			PageSize:      PageSize,
 		})
 
 		handler.renderMoveModal(w, r, &moveModalCmd{
-			Src: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
-			Dst: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
+			User: &users.ExampleAlice,
+			Src:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+			Dst:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
 		})
 
 		res := w.Result()
@@ -322,7 +345,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/browser/move", nil)
@@ -334,8 +358,9 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock.On("WriteHTMLErrorPage", w, r, fmt.Errorf("failed to list dir for elem /bar: %w", errs.ErrInternal))
 
 		handler.renderMoveModal(w, r, &moveModalCmd{
-			Src: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
-			Dst: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
+			User: &users.ExampleAlice,
+			Src:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+			Dst:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
 		})
 
 		res := w.Result()
@@ -351,7 +376,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/browser/move", nil)
@@ -366,8 +392,9 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock.On("WriteHTMLErrorPage", w, r, fmt.Errorf("failed to get the source file /foo/file.jpg: %w", errs.ErrNotFound))
 
 		handler.renderMoveModal(w, r, &moveModalCmd{
-			Src: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
-			Dst: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
+			User: &users.ExampleAlice,
+			Src:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+			Dst:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
 		})
 
 		res := w.Result()
@@ -383,7 +410,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/browser/move", nil)
@@ -395,8 +423,9 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock.On("WriteHTMLErrorPage", w, r, fmt.Errorf("failed to ListDir: %w", errs.ErrInternal))
 
 		handler.renderMoreContent(w, r, &moveModalCmd{
-			Src: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
-			Dst: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
+			User: &users.ExampleAlice,
+			Src:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+			Dst:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
 		}, "some-file-name.jpg")
 
 		res := w.Result()
@@ -412,7 +441,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -420,14 +450,24 @@ func Test_MoveModalHandler(t *testing.T) {
 
 		// Get the space
 		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
-		spacesMock.On("GetByID", mock.Anything, uuid.UUID("some-space-id")).
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
 			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
 
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.jpg"),
+		}).Return(nil).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+		}).Return(nil).Once()
 		fsMock.On("Move", mock.Anything, &dfs.MoveCmd{
 			Src:     dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
 			Dst:     dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.jpg"),
 			MovedBy: &users.ExampleAlice,
 		}).Return(nil).Once()
+		locksMock.On("RemoveAll", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg")).
+			Return(nil).Once()
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodPost, "/browser/move", nil)
@@ -448,6 +488,154 @@ func Test_MoveModalHandler(t *testing.T) {
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	})
 
+	t.Run("handleMoveReq with a RemoveAll error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space
+		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.jpg"),
+		}).Return(nil).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+		}).Return(nil).Once()
+		fsMock.On("Move", mock.Anything, &dfs.MoveCmd{
+			Src:     dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+			Dst:     dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.jpg"),
+			MovedBy: &users.ExampleAlice,
+		}).Return(nil).Once()
+		locksMock.On("RemoveAll", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg")).
+			Return(fmt.Errorf("some-error")).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/move", nil)
+		r.URL.RawQuery = url.Values{
+			"srcPath": []string{"/foo/file.jpg"},
+			"dstPath": []string{"/bar/"},
+			"spaceID": []string{"some-space-id"},
+		}.Encode()
+
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to remove the locks: %w", fmt.Errorf("some-error"))).Once()
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("handleMoveReq to an other space success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the spaces
+		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		tools.UUIDMock.On("Parse", "some-other-space-id").Return(uuid.UUID("some-other-space-id"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-other-space-id")).
+			Return(&spaces.ExampleAliceBobSharedSpace, nil).Once()
+
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAliceBobSharedSpace, "/bar/file.jpg"),
+		}).Return(nil).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+		}).Return(nil).Once()
+		fsMock.On("Move", mock.Anything, &dfs.MoveCmd{
+			Src:     dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+			Dst:     dfs.NewPathCmd(&spaces.ExampleAliceBobSharedSpace, "/bar/file.jpg"),
+			MovedBy: &users.ExampleAlice,
+		}).Return(nil).Once()
+		locksMock.On("RemoveAll", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg")).
+			Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/move", nil)
+		r.URL.RawQuery = url.Values{
+			"srcPath":    []string{"/foo/file.jpg"},
+			"dstPath":    []string{"/bar/"},
+			"spaceID":    []string{"some-space-id"},
+			"dstSpaceID": []string{"some-other-space-id"},
+		}.Encode()
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, "refreshPage", res.Header.Get("HX-Trigger"))
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("handleMoveReq to a space not owned by the user", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the spaces
+		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		tools.UUIDMock.On("Parse", "some-other-space-id").Return(uuid.UUID("some-other-space-id"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-other-space-id")).
+			Return(nil, errs.ErrNotFound).Once()
+
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to GetUserSpace: %w", errs.ErrNotFound))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/move", nil)
+		r.URL.RawQuery = url.Values{
+			"srcPath":    []string{"/foo/file.jpg"},
+			"dstPath":    []string{"/bar/"},
+			"spaceID":    []string{"some-space-id"},
+			"dstSpaceID": []string{"some-other-space-id"},
+		}.Encode()
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
 	t.Run("handleMoveReq with a move error", func(t *testing.T) {
 		tools := tools.NewMock(t)
 		webSessionsMock := websessions.NewMockService(t)
@@ -456,7 +644,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -464,9 +653,17 @@ func Test_MoveModalHandler(t *testing.T) {
 
 		// Get the space
 		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
-		spacesMock.On("GetByID", mock.Anything, uuid.UUID("some-space-id")).
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
 			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
 
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.jpg"),
+		}).Return(nil).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+		}).Return(nil).Once()
 		fsMock.On("Move", mock.Anything, &dfs.MoveCmd{
 			Src:     dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
 			Dst:     dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.jpg"),
@@ -496,7 +693,8 @@ func Test_MoveModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -504,9 +702,17 @@ func Test_MoveModalHandler(t *testing.T) {
 
 		// Get the space
 		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
-		spacesMock.On("GetByID", mock.Anything, uuid.UUID("some-space-id")).
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
 			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
 
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.jpg"),
+		}).Return(nil).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+		}).Return(nil).Once()
 		fsMock.On("Move", mock.Anything, &dfs.MoveCmd{
 			Src:     dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
 			Dst:     dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.jpg"),
@@ -520,11 +726,16 @@ func Test_MoveModalHandler(t *testing.T) {
 		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg")).
 			Return(&dfs.ExampleAliceFile, nil).Once()
 
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
+
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusUnprocessableEntity, &browser.MoveTemplate{
+			Action:        browser.MoveAction,
 			SrcPath:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
 			SrcInode:      &dfs.ExampleAliceFile,
 			DstPath:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
 			FolderContent: map[dfs.PathCmd]dfs.INode{*dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.txt"): dfs.ExampleAliceFile2},
+			Spaces:        []spaces.Space{spaces.ExampleAlicePersonalSpace},
 			PageSize:      PageSize,
 		})
 
@@ -536,6 +747,305 @@ func Test_MoveModalHandler(t *testing.T) {
 			"spaceID": []string{"some-space-id"},
 		}.Encode()
 
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+	t.Run("handleMoveReq with a locked file", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space
+		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.jpg"),
+		}).Return(nil).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+		}).Return(errs.BadRequest(davlocks.ErrLocked)).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/move", nil)
+		r.URL.RawQuery = url.Values{
+			"srcPath": []string{"/foo/file.jpg"},
+			"dstPath": []string{"/bar/"},
+			"spaceID": []string{"some-space-id"},
+		}.Encode()
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusLocked, res.StatusCode)
+	})
+
+	t.Run("handleMoveReq with a failed If-Match", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := newMoveModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space
+		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.jpg"),
+		}).Return(nil).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+		}).Return(nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/move", nil)
+		r.Header.Set("If-Match", `"some-old-etag"`)
+		r.URL.RawQuery = url.Values{
+			"srcPath": []string{"/foo/file.jpg"},
+			"dstPath": []string{"/bar/"},
+			"spaceID": []string{"some-space-id"},
+		}.Encode()
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	})
+}
+
+func Test_CopyModalHandler(t *testing.T) {
+	t.Run("getMoveModal success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := newCopyModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space
+		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("ListDir", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
+			&sqlstorage.PaginateCmd{StartAfter: map[string]string{"name": ""}, Limit: PageSize}).
+			Return([]dfs.INode{dfs.ExampleAliceFile2}, nil).Once()
+
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.MoveTemplate{
+			Action:        browser.CopyAction,
+			SrcPath:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+			SrcInode:      &dfs.ExampleAliceFile,
+			DstPath:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/"),
+			FolderContent: map[dfs.PathCmd]dfs.INode{*dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.txt"): dfs.ExampleAliceFile2},
+			Spaces:        []spaces.Space{spaces.ExampleAlicePersonalSpace},
+			PageSize:      PageSize,
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/copy", nil)
+		r.URL.RawQuery = url.Values{
+			"srcPath": []string{"/foo/file.jpg"},
+			"dstPath": []string{"/bar/"},
+			"spaceID": []string{"some-space-id"},
+		}.Encode()
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("handleMoveReq success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := newCopyModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space
+		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.jpg"),
+		}).Return(nil).Once()
+		fsMock.On("Copy", mock.Anything, &dfs.CopyCmd{
+			Src:      dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file.jpg"),
+			Dst:      dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar/file.jpg"),
+			CopiedBy: &users.ExampleAlice,
+		}).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/copy", nil)
+		r.URL.RawQuery = url.Values{
+			"srcPath": []string{"/foo/file.jpg"},
+			"dstPath": []string{"/bar/"},
+			"spaceID": []string{"some-space-id"},
+		}.Encode()
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, "none", res.Header.Get("HX-Reswap"))
+		assert.Equal(t, "refreshPage", res.Header.Get("HX-Trigger"))
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("handleMoveReq from a space the user isn't a member of", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := newCopyModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space
+		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
+			Return(nil, errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/copy", nil)
+		r.URL.RawQuery = url.Values{
+			"srcPath":    []string{"/foo/file.jpg"},
+			"dstPath":    []string{"/bar/"},
+			"spaceID":    []string{"some-space-id"},
+			"dstSpaceID": []string{string(spaces.ExampleAlicePersonalSpace.ID())},
+		}.Encode()
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("handleMoveReq with a copy inside itself", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := newCopyModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space
+		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("some-space-id")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		copyErr := errs.BadRequest(dfs.ErrInvalidPath, "can't copy \"/foo\" inside itself")
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar/foo"),
+		}).Return(nil).Once()
+		fsMock.On("Copy", mock.Anything, &dfs.CopyCmd{
+			Src:      dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+			Dst:      dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar/foo"),
+			CopiedBy: &users.ExampleAlice,
+		}).Return(copyErr).Once()
+
+		// Render the modal again with the error
+		fsMock.On("ListDir", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+			&sqlstorage.PaginateCmd{StartAfter: map[string]string{"name": ""}, Limit: PageSize}).
+			Return([]dfs.INode{}, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo")).
+			Return(&dfs.ExampleAliceDir, nil).Once()
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusUnprocessableEntity, &browser.MoveTemplate{
+			Action:        browser.CopyAction,
+			SrcPath:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+			SrcInode:      &dfs.ExampleAliceDir,
+			DstPath:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+			FolderContent: map[dfs.PathCmd]dfs.INode{},
+			Spaces:        []spaces.Space{spaces.ExampleAlicePersonalSpace},
+			PageSize:      PageSize,
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/copy", nil)
+		r.URL.RawQuery = url.Values{
+			"srcPath": []string{"/foo"},
+			"dstPath": []string{"/foo/bar"},
+			"spaceID": []string{"some-space-id"},
+		}.Encode()
+
 		srv := chi.NewRouter()
 		handler.Register(srv, nil)
 		srv.ServeHTTP(w, r)
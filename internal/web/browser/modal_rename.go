@@ -7,6 +7,7 @@ import (
 	"path"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
@@ -24,6 +25,7 @@ type renameModalHandler struct {
 	html   html.Writer
 	uuid   uuid.Service
 	fs     dfs.Service
+	locks  davlocks.Service
 }
 
 func newRenameModalHandler(
@@ -32,8 +34,9 @@ func newRenameModalHandler(
 	html html.Writer,
 	uuid uuid.Service,
 	fs dfs.Service,
+	locks davlocks.Service,
 ) *renameModalHandler {
-	return &renameModalHandler{auth, spaces, html, uuid, fs}
+	return &renameModalHandler{auth, spaces, html, uuid, fs, locks}
 }
 
 func (h *renameModalHandler) Register(r chi.Router, mids *router.Middlewares) {
@@ -98,7 +101,7 @@ func (h *renameModalHandler) renderRenameModal(w http.ResponseWriter, r *http.Re
 func (h *renameModalHandler) handleRenameReq(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	_, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
 	if abort {
 		return
 	}
@@ -113,9 +116,39 @@ func (h *renameModalHandler) handleRenameReq(w http.ResponseWriter, r *http.Requ
 	inode, err := h.fs.Get(ctx, targetPath)
 	if errors.Is(err, errs.ErrNotFound) {
 		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to get the file: %w", err))
+		return
+	}
+
+	newPath := dfs.NewPathCmd(space, path.Join(path.Dir(filePath), r.FormValue("name")))
+
+	err = confirmUnlocked(ctx, h.locks, user, targetPath, newPath)
+	if errors.Is(err, davlocks.ErrLocked) {
+		w.WriteHeader(http.StatusLocked)
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, err)
+		return
 	}
 
-	_, err = h.fs.Rename(ctx, inode, r.FormValue("name"))
+	ok, err := checkPreconditions(ctx, h.fs, r.Header, targetPath)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, err)
+		return
+	}
+
+	if !ok {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	_, err = h.fs.Rename(ctx, user, inode, r.FormValue("name"))
 	if errors.Is(err, errs.ErrValidation) {
 		h.renderRenameModal(w, r, &browser.RenameTemplate{
 			Error:               ptr.To(err.Error()),
@@ -128,6 +161,7 @@ func (h *renameModalHandler) handleRenameReq(w http.ResponseWriter, r *http.Requ
 
 	if err != nil {
 		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to rename the file: %w", err))
+		return
 	}
 
 	w.Header().Add("HX-Trigger", "refreshPage")
@@ -12,6 +12,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
@@ -34,7 +35,8 @@ func Test_RenameModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -74,7 +76,8 @@ func Test_RenameModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(nil, websessions.ErrMissingSessionToken).Once()
@@ -105,7 +108,8 @@ func Test_RenameModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -136,7 +140,8 @@ func Test_RenameModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -169,7 +174,8 @@ func Test_RenameModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -181,7 +187,16 @@ func Test_RenameModalHandler(t *testing.T) {
 
 		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar.jpg")).Return(&dfs.ExampleAliceFile, nil).Once()
 
-		fsMock.On("Rename", mock.Anything, &dfs.ExampleAliceFile, "new-name.jpg").Return(&dfs.ExampleAliceFile, nil).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar.jpg"),
+		}).Return(nil).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new-name.jpg"),
+		}).Return(nil).Once()
+
+		fsMock.On("Rename", mock.Anything, &users.ExampleAlice, &dfs.ExampleAliceFile, "new-name.jpg").Return(&dfs.ExampleAliceFile, nil).Once()
 
 		w := httptest.NewRecorder()
 		form := url.Values{}
@@ -209,7 +224,8 @@ func Test_RenameModalHandler(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+		locksMock := davlocks.NewMockService(t)
+		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -221,7 +237,16 @@ func Test_RenameModalHandler(t *testing.T) {
 
 		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar.jpg")).Return(&dfs.ExampleAliceFile, nil).Once()
 
-		fsMock.On("Rename", mock.Anything, &dfs.ExampleAliceFile, "new-name").Return(nil, errs.Validation(errors.New("some-error"))).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar.jpg"),
+		}).Return(nil).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new-name"),
+		}).Return(nil).Once()
+
+		fsMock.On("Rename", mock.Anything, &users.ExampleAlice, &dfs.ExampleAliceFile, "new-name").Return(nil, errs.Validation(errors.New("some-error"))).Once()
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusUnprocessableEntity, &browser.RenameTemplate{
 			Error:               ptr.To("validation: some-error"),
 			Target:              dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar.jpg"),
@@ -245,4 +270,94 @@ func Test_RenameModalHandler(t *testing.T) {
 		defer res.Body.Close()
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	})
+	t.Run("handleRenameReq with a locked file", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
+		spacesMock.On("GetByID", mock.Anything, uuid.UUID("some-space-id")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar.jpg")).Return(&dfs.ExampleAliceFile, nil).Once()
+
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar.jpg"),
+		}).Return(errs.BadRequest(davlocks.ErrLocked)).Once()
+
+		w := httptest.NewRecorder()
+		form := url.Values{}
+		form.Add("path", "/foo/bar.jpg")
+		form.Add("name", "new-name.jpg")
+		form.Add("spaceID", "some-space-id")
+		r := httptest.NewRequest(http.MethodPost, "/browser/rename", strings.NewReader(form.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusLocked, res.StatusCode)
+	})
+
+	t.Run("handleRenameReq with a failed If-Match", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := newRenameModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", "some-space-id").Return(uuid.UUID("some-space-id"), nil).Once()
+		spacesMock.On("GetByID", mock.Anything, uuid.UUID("some-space-id")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar.jpg")).Return(&dfs.ExampleAliceFile, nil).Twice()
+
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar.jpg"),
+		}).Return(nil).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/new-name.jpg"),
+		}).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		form := url.Values{}
+		form.Add("path", "/foo/bar.jpg")
+		form.Add("name", "new-name.jpg")
+		form.Add("spaceID", "some-space-id")
+		r := httptest.NewRequest(http.MethodPost, "/browser/rename", strings.NewReader(form.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Add("If-Match", `"some-old-etag"`)
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	})
 }
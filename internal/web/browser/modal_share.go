@@ -0,0 +1,301 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/router"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+	"github.com/theduckcompany/duckcloud/internal/web/auth"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	"github.com/theduckcompany/duckcloud/internal/web/html/templates/browser"
+)
+
+const (
+	shareDateFormat = "2006-01-02"
+	// shareSizeUnit is the unit used by the size limit field: MB
+	shareSizeUnit = 1000 * 1000
+)
+
+type shareModalHandler struct {
+	auth   *auth.Authenticator
+	spaces spaces.Service
+	html   html.Writer
+	uuid   uuid.Service
+	fs     dfs.Service
+	shares shares.Service
+}
+
+func newShareModalHandler(
+	auth *auth.Authenticator,
+	spaces spaces.Service,
+	html html.Writer,
+	uuid uuid.Service,
+	fs dfs.Service,
+	shares shares.Service,
+) *shareModalHandler {
+	return &shareModalHandler{auth, spaces, html, uuid, fs, shares}
+}
+
+func (h *shareModalHandler) Register(r chi.Router, mids *router.Middlewares) {
+	if mids != nil {
+		r = r.With(mids.Defaults()...)
+	}
+
+	r.Get("/browser/share", h.getShareModal)
+	r.Post("/browser/share", h.createShare)
+	r.Post("/browser/share/delete", h.deleteShare)
+}
+
+func (h *shareModalHandler) getShareModal(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	target, inode, abort := h.getTarget(w, r, user)
+	if abort {
+		return
+	}
+
+	h.renderShareModal(w, r, user, http.StatusOK, &browser.ShareTemplate{
+		Error:    nil,
+		Target:   target,
+		INode:    inode,
+		NewShare: nil,
+		Shares:   nil, // Filled by renderShareModal
+	})
+}
+
+func (h *shareModalHandler) createShare(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	target, inode, abort := h.getTarget(w, r, user)
+	if abort {
+		return
+	}
+
+	cmd, err := h.parseCreateForm(r)
+	if err != nil {
+		h.renderShareModal(w, r, user, http.StatusUnprocessableEntity, &browser.ShareTemplate{
+			Error:    ptr.To(err.Error()),
+			Target:   target,
+			INode:    inode,
+			NewShare: nil,
+			Shares:   nil, // Filled by renderShareModal
+		})
+		return
+	}
+
+	cmd.Space = target.Space()
+	cmd.INode = inode
+	cmd.CreatedBy = user
+
+	share, err := h.shares.Create(r.Context(), cmd)
+	if errors.Is(err, errs.ErrUnauthorized) {
+		h.renderShareModal(w, r, user, http.StatusForbidden, &browser.ShareTemplate{
+			Error:    ptr.To("Only the editors and the managers can share the space content"),
+			Target:   target,
+			INode:    inode,
+			NewShare: nil,
+			Shares:   nil, // Filled by renderShareModal
+		})
+		return
+	}
+
+	if errors.Is(err, errs.ErrValidation) {
+		h.renderShareModal(w, r, user, http.StatusUnprocessableEntity, &browser.ShareTemplate{
+			Error:    ptr.To(err.Error()),
+			Target:   target,
+			INode:    inode,
+			NewShare: nil,
+			Shares:   nil, // Filled by renderShareModal
+		})
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to create the share: %w", err))
+		return
+	}
+
+	h.renderShareModal(w, r, user, http.StatusOK, &browser.ShareTemplate{
+		Error:    nil,
+		Target:   target,
+		INode:    inode,
+		NewShare: share,
+		Shares:   nil, // Filled by renderShareModal
+	})
+}
+
+func (h *shareModalHandler) deleteShare(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	target, inode, abort := h.getTarget(w, r, user)
+	if abort {
+		return
+	}
+
+	shareID, err := h.uuid.Parse(r.FormValue("shareID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err = h.shares.Delete(r.Context(), &shares.DeleteCmd{
+		User:    user,
+		ShareID: shareID,
+	})
+	if errors.Is(err, errs.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, errs.ErrUnauthorized) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to delete the share: %w", err))
+		return
+	}
+
+	h.renderShareModal(w, r, user, http.StatusOK, &browser.ShareTemplate{
+		Error:    nil,
+		Target:   target,
+		INode:    inode,
+		NewShare: nil,
+		Shares:   nil, // Filled by renderShareModal
+	})
+}
+
+// renderShareModal writes the modal with the existing shares. The shares,
+// and so their tokens and their delete buttons, are only listed for the users
+// allowed to manage them.
+func (h *shareModalHandler) renderShareModal(w http.ResponseWriter, r *http.Request, user *users.User, status int, tmpl *browser.ShareTemplate) {
+	role, err := h.spaces.GetUserRole(r.Context(), user.ID(), tmpl.Target.Space().ID())
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserRole: %w", err))
+		return
+	}
+
+	if role.CanWrite() {
+		res, err := h.shares.GetAllForINode(r.Context(), tmpl.INode.ID(), nil)
+		if err != nil {
+			h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetAllForINode: %w", err))
+			return
+		}
+
+		tmpl.Shares = res
+	}
+
+	h.html.WriteHTMLTemplate(w, r, status, tmpl)
+}
+
+func (h *shareModalHandler) parseCreateForm(r *http.Request) (*shares.CreateCmd, error) {
+	cmd := shares.CreateCmd{
+		Kind:     shares.Kind(r.FormValue("kind")),
+		Password: secret.NewText(r.FormValue("password")),
+	}
+
+	if cmd.Kind == "" {
+		cmd.Kind = shares.DownloadKind
+	}
+
+	if rawDate := r.FormValue("expiresAt"); rawDate != "" {
+		date, err := time.Parse(shareDateFormat, rawDate)
+		if err != nil {
+			return nil, errors.New("invalid expiration date")
+		}
+
+		// The link stays valid the whole day.
+		cmd.ExpiresAt = ptr.To(date.Add(24 * time.Hour))
+	}
+
+	if rawMax := r.FormValue("maxDownloads"); rawMax != "" {
+		maxDownloads, err := strconv.Atoi(rawMax)
+		if err != nil {
+			return nil, errors.New("invalid download limit")
+		}
+
+		cmd.MaxDownloads = maxDownloads
+	}
+
+	if rawMax := r.FormValue("maxUploads"); rawMax != "" {
+		maxUploads, err := strconv.Atoi(rawMax)
+		if err != nil {
+			return nil, errors.New("invalid file limit")
+		}
+
+		cmd.MaxUploads = maxUploads
+	}
+
+	if rawMax := r.FormValue("maxUploadSize"); rawMax != "" {
+		maxUploadSize, err := strconv.ParseUint(rawMax, 10, 64)
+		if err != nil {
+			return nil, errors.New("invalid size limit")
+		}
+
+		cmd.MaxUploadSize = maxUploadSize * shareSizeUnit
+	}
+
+	return &cmd, nil
+}
+
+func (h *shareModalHandler) getTarget(w http.ResponseWriter, r *http.Request, user *users.User) (*dfs.PathCmd, *dfs.INode, bool) {
+	filePath := r.FormValue("path")
+	if len(filePath) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, true
+	}
+
+	spaceID, err := h.uuid.Parse(r.FormValue("spaceID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, true
+	}
+
+	space, err := h.spaces.GetUserSpace(r.Context(), user.ID(), spaceID)
+	if errors.Is(err, errs.ErrNotFound) || errors.Is(err, errs.ErrUnauthorized) {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, true
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserSpace: %w", err))
+		return nil, nil, true
+	}
+
+	target := dfs.NewPathCmd(space, filePath)
+
+	inode, err := h.fs.Get(r.Context(), target)
+	if errors.Is(err, errs.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, true
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to get the file %q: %w", filePath, err))
+		return nil, nil, true
+	}
+
+	return target, inode, false
+}
@@ -0,0 +1,381 @@
+package browser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/service/websessions"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/web/auth"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	"github.com/theduckcompany/duckcloud/internal/web/html/templates/browser"
+)
+
+func Test_ShareModalHandler(t *testing.T) {
+	spaceID := spaces.ExampleAlicePersonalSpace.ID()
+
+	t.Run("getShareModal success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newShareModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, sharesMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaceID).Return(spaces.RoleManager, nil).Once()
+		sharesMock.On("GetAllForINode", mock.Anything, dfs.ExampleAliceFile.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]shares.Share{shares.ExampleAliceFileShare}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.ShareTemplate{
+			Error:    nil,
+			Target:   dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+			INode:    &dfs.ExampleAliceFile,
+			NewShare: nil,
+			Shares:   []shares.Share{shares.ExampleAliceFileShare},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/share?path=/foo/bar&spaceID="+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getShareModal with a viewer role doesn't list the shares", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newShareModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, sharesMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaceID).Return(spaces.RoleViewer, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.ShareTemplate{
+			Error:    nil,
+			Target:   dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+			INode:    &dfs.ExampleAliceFile,
+			NewShare: nil,
+			Shares:   nil,
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/share?path=/foo/bar&spaceID="+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getShareModal with a space not owned", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newShareModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, sharesMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(nil, errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/share?path=/foo/bar&spaceID="+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("createShare success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newShareModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, sharesMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo")).
+			Return(&dfs.ExampleAliceDir, nil).Once()
+		sharesMock.On("Create", mock.Anything, &shares.CreateCmd{
+			Space:        &spaces.ExampleAlicePersonalSpace,
+			INode:        &dfs.ExampleAliceDir,
+			CreatedBy:    &users.ExampleAlice,
+			ExpiresAt:    ptr.To(time.Date(2030, time.January, 2, 0, 0, 0, 0, time.UTC)),
+			Kind:         shares.DownloadKind,
+			Password:     secret.NewText("some-password"),
+			MaxDownloads: 10,
+		}).Return(&shares.ExampleAliceProtectedDirShare, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaceID).Return(spaces.RoleManager, nil).Once()
+		sharesMock.On("GetAllForINode", mock.Anything, dfs.ExampleAliceDir.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]shares.Share{shares.ExampleAliceProtectedDirShare}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.ShareTemplate{
+			Error:    nil,
+			Target:   dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+			INode:    &dfs.ExampleAliceDir,
+			NewShare: &shares.ExampleAliceProtectedDirShare,
+			Shares:   []shares.Share{shares.ExampleAliceProtectedDirShare},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/share", strings.NewReader(url.Values{
+			"spaceID":      []string{string(spaceID)},
+			"path":         []string{"/foo"},
+			"expiresAt":    []string{"2030-01-01"},
+			"password":     []string{"some-password"},
+			"maxDownloads": []string{"10"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("createShare with an upload share", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newShareModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, sharesMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo")).
+			Return(&dfs.ExampleAliceDir, nil).Once()
+		sharesMock.On("Create", mock.Anything, &shares.CreateCmd{
+			Space:         &spaces.ExampleAlicePersonalSpace,
+			INode:         &dfs.ExampleAliceDir,
+			CreatedBy:     &users.ExampleAlice,
+			ExpiresAt:     nil,
+			Kind:          shares.UploadKind,
+			Password:      secret.Empty,
+			MaxUploads:    20,
+			MaxUploadSize: 100_000_000,
+		}).Return(&shares.ExampleAliceUploadShare, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaceID).Return(spaces.RoleManager, nil).Once()
+		sharesMock.On("GetAllForINode", mock.Anything, dfs.ExampleAliceDir.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]shares.Share{shares.ExampleAliceUploadShare}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.ShareTemplate{
+			Error:    nil,
+			Target:   dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+			INode:    &dfs.ExampleAliceDir,
+			NewShare: &shares.ExampleAliceUploadShare,
+			Shares:   []shares.Share{shares.ExampleAliceUploadShare},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/share", strings.NewReader(url.Values{
+			"spaceID":       []string{string(spaceID)},
+			"path":          []string{"/foo"},
+			"kind":          []string{"upload"},
+			"maxUploads":    []string{"20"},
+			"maxUploadSize": []string{"100"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("createShare with a viewer role", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newShareModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, sharesMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo")).
+			Return(&dfs.ExampleAliceDir, nil).Once()
+		sharesMock.On("Create", mock.Anything, &shares.CreateCmd{
+			Space:     &spaces.ExampleAlicePersonalSpace,
+			INode:     &dfs.ExampleAliceDir,
+			CreatedBy: &users.ExampleAlice,
+			Kind:      shares.DownloadKind,
+		}).Return(nil, errs.Unauthorized(shares.ErrReadOnlyRole)).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaceID).Return(spaces.RoleViewer, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusForbidden, &browser.ShareTemplate{
+			Error:    ptr.To("Only the editors and the managers can share the space content"),
+			Target:   dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+			INode:    &dfs.ExampleAliceDir,
+			NewShare: nil,
+			Shares:   nil,
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/share", strings.NewReader(url.Values{
+			"spaceID": []string{string(spaceID)},
+			"path":    []string{"/foo"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("createShare with an invalid download limit", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newShareModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, sharesMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo")).
+			Return(&dfs.ExampleAliceDir, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaceID).Return(spaces.RoleManager, nil).Once()
+		sharesMock.On("GetAllForINode", mock.Anything, dfs.ExampleAliceDir.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]shares.Share{}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusUnprocessableEntity, &browser.ShareTemplate{
+			Error:    ptr.To("invalid download limit"),
+			Target:   dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+			INode:    &dfs.ExampleAliceDir,
+			NewShare: nil,
+			Shares:   []shares.Share{},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/share", strings.NewReader(url.Values{
+			"spaceID":      []string{string(spaceID)},
+			"path":         []string{"/foo"},
+			"maxDownloads": []string{"foo"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("deleteShare success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newShareModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock, sharesMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		tools.UUIDMock.On("Parse", string(shares.ExampleAliceFileShare.ID())).
+			Return(shares.ExampleAliceFileShare.ID(), nil).Once()
+		sharesMock.On("Delete", mock.Anything, &shares.DeleteCmd{
+			User:    &users.ExampleAlice,
+			ShareID: shares.ExampleAliceFileShare.ID(),
+		}).Return(nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaceID).Return(spaces.RoleManager, nil).Once()
+		sharesMock.On("GetAllForINode", mock.Anything, dfs.ExampleAliceFile.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]shares.Share{}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.ShareTemplate{
+			Error:    nil,
+			Target:   dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+			INode:    &dfs.ExampleAliceFile,
+			NewShare: nil,
+			Shares:   []shares.Share{},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/share/delete", strings.NewReader(url.Values{
+			"spaceID": []string{string(spaceID)},
+			"path":    []string{"/foo/bar"},
+			"shareID": []string{string(shares.ExampleAliceFileShare.ID())},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,196 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/router"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+	"github.com/theduckcompany/duckcloud/internal/web/auth"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	"github.com/theduckcompany/duckcloud/internal/web/html/templates/browser"
+)
+
+type versionsModalHandler struct {
+	auth   *auth.Authenticator
+	spaces spaces.Service
+	html   html.Writer
+	uuid   uuid.Service
+	fs     dfs.Service
+}
+
+func newVersionsModalHandler(
+	auth *auth.Authenticator,
+	spaces spaces.Service,
+	html html.Writer,
+	uuid uuid.Service,
+	fs dfs.Service,
+) *versionsModalHandler {
+	return &versionsModalHandler{auth, spaces, html, uuid, fs}
+}
+
+func (h *versionsModalHandler) Register(r chi.Router, mids *router.Middlewares) {
+	if mids != nil {
+		r = r.With(mids.Defaults()...)
+	}
+
+	r.Get("/browser/versions", h.getVersionsModal)
+	r.Get("/browser/versions/download", h.downloadVersion)
+	r.Post("/browser/versions/restore", h.restoreVersion)
+}
+
+func (h *versionsModalHandler) getVersionsModal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	target, inode, abort := h.getTargetFile(w, r, user)
+	if abort {
+		return
+	}
+
+	versions, err := h.fs.ListVersions(ctx, inode)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to ListVersions: %w", err))
+		return
+	}
+
+	h.html.WriteHTMLTemplate(w, r, http.StatusOK, &browser.VersionsTemplate{
+		Target:   target,
+		INode:    inode,
+		Versions: versions,
+	})
+}
+
+func (h *versionsModalHandler) downloadVersion(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	_, inode, abort := h.getTargetFile(w, r, user)
+	if abort {
+		return
+	}
+
+	version, abort := h.getVersion(w, r, inode)
+	if abort {
+		return
+	}
+
+	file, err := h.fs.DownloadVersion(r.Context(), version)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to DownloadVersion: %w", err))
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", inode.Name()))
+	http.ServeContent(w, r, inode.Name(), version.ModifiedAt(), file)
+}
+
+func (h *versionsModalHandler) restoreVersion(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	_, inode, abort := h.getTargetFile(w, r, user)
+	if abort {
+		return
+	}
+
+	version, abort := h.getVersion(w, r, inode)
+	if abort {
+		return
+	}
+
+	_, err := h.fs.RestoreVersion(r.Context(), &dfs.RestoreVersionCmd{
+		INode:      inode,
+		VersionID:  version.ID(),
+		RestoredBy: user,
+	})
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to RestoreVersion: %w", err))
+		return
+	}
+
+	w.Header().Add("HX-Trigger", "refreshPage")
+	w.Header().Add("HX-Reswap", "none")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *versionsModalHandler) getTargetFile(w http.ResponseWriter, r *http.Request, user *users.User) (*dfs.PathCmd, *dfs.INode, bool) {
+	filePath := r.FormValue("path")
+	if len(filePath) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, true
+	}
+
+	spaceID, err := h.uuid.Parse(r.FormValue("spaceID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, true
+	}
+
+	space, err := h.spaces.GetUserSpace(r.Context(), user.ID(), spaceID)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserSpace: %w", err))
+		return nil, nil, true
+	}
+
+	if space == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, true
+	}
+
+	target := dfs.NewPathCmd(space, filePath)
+
+	inode, err := h.fs.Get(r.Context(), target)
+	if errors.Is(err, errs.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, true
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to get the file %q: %w", filePath, err))
+		return nil, nil, true
+	}
+
+	if inode.IsDir() {
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, nil, true
+	}
+
+	return target, inode, false
+}
+
+func (h *versionsModalHandler) getVersion(w http.ResponseWriter, r *http.Request, inode *dfs.INode) (*dfs.FileVersion, bool) {
+	versionID, err := h.uuid.Parse(r.FormValue("versionID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, true
+	}
+
+	version, err := h.fs.GetVersion(r.Context(), inode, versionID)
+	if errors.Is(err, errs.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, true
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetVersion: %w", err))
+		return nil, true
+	}
+
+	return version, false
+}
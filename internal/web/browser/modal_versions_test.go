@@ -0,0 +1,320 @@
+package browser
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/service/websessions"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/web/auth"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	"github.com/theduckcompany/duckcloud/internal/web/html/templates/browser"
+)
+
+func Test_VersionsModalHandler(t *testing.T) {
+	spaceID := spaces.ExampleAlicePersonalSpace.ID()
+	versionID := dfs.ExampleAliceFileVersion.ID()
+
+	t.Run("getVersionsModal success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newVersionsModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		fsMock.On("ListVersions", mock.Anything, &dfs.ExampleAliceFile).
+			Return([]dfs.FileVersion{dfs.ExampleAliceFileVersion}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.VersionsTemplate{
+			Target:   dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+			INode:    &dfs.ExampleAliceFile,
+			Versions: []dfs.FileVersion{dfs.ExampleAliceFileVersion},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/versions?path=/foo/bar&spaceID="+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getVersionsModal with a directory", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newVersionsModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo")).
+			Return(&dfs.ExampleAliceDir, nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/versions?path=/foo&spaceID="+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("getVersionsModal with a file not found", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newVersionsModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(nil, errs.ErrNotFound).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/versions?path=/foo/bar&spaceID="+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("getVersionsModal with a ListVersions error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newVersionsModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		fsMock.On("ListVersions", mock.Anything, &dfs.ExampleAliceFile).
+			Return(nil, fmt.Errorf("some-error")).Once()
+
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to ListVersions: %w", fmt.Errorf("some-error"))).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/versions?path=/foo/bar&spaceID="+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("downloadVersion success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newVersionsModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(versionID)).Return(versionID, nil).Once()
+		fsMock.On("GetVersion", mock.Anything, &dfs.ExampleAliceFile, versionID).
+			Return(&dfs.ExampleAliceFileVersion, nil).Once()
+
+		afs := afero.NewMemMapFs()
+		file, err := afero.TempFile(afs, t.TempDir(), "")
+		require.NoError(t, err)
+
+		fsMock.On("DownloadVersion", mock.Anything, &dfs.ExampleAliceFileVersion).Return(file, nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/versions/download?path=/foo/bar&spaceID="+string(spaceID)+"&versionID="+string(versionID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, `attachment; filename="foo.pdf"`, res.Header.Get("Content-Disposition"))
+	})
+
+	t.Run("downloadVersion with an unknown version", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newVersionsModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(versionID)).Return(versionID, nil).Once()
+		fsMock.On("GetVersion", mock.Anything, &dfs.ExampleAliceFile, versionID).
+			Return(nil, errs.ErrNotFound).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/versions/download?path=/foo/bar&spaceID="+string(spaceID)+"&versionID="+string(versionID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("restoreVersion success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newVersionsModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(versionID)).Return(versionID, nil).Once()
+		fsMock.On("GetVersion", mock.Anything, &dfs.ExampleAliceFile, versionID).
+			Return(&dfs.ExampleAliceFileVersion, nil).Once()
+		fsMock.On("RestoreVersion", mock.Anything, &dfs.RestoreVersionCmd{
+			INode:      &dfs.ExampleAliceFile,
+			VersionID:  versionID,
+			RestoredBy: &users.ExampleAlice,
+		}).Return(&dfs.ExampleAliceFile, nil).Once()
+
+		form := url.Values{
+			"path":      []string{"/foo/bar"},
+			"spaceID":   []string{string(spaceID)},
+			"versionID": []string{string(versionID)},
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/versions/restore", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "refreshPage", res.Header.Get("HX-Trigger"))
+	})
+
+	t.Run("restoreVersion with a RestoreVersion error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newVersionsModalHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(versionID)).Return(versionID, nil).Once()
+		fsMock.On("GetVersion", mock.Anything, &dfs.ExampleAliceFile, versionID).
+			Return(&dfs.ExampleAliceFileVersion, nil).Once()
+		fsMock.On("RestoreVersion", mock.Anything, &dfs.RestoreVersionCmd{
+			INode:      &dfs.ExampleAliceFile,
+			VersionID:  versionID,
+			RestoredBy: &users.ExampleAlice,
+		}).Return(nil, fmt.Errorf("some-error")).Once()
+
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to RestoreVersion: %w", fmt.Errorf("some-error"))).Once()
+
+		form := url.Values{
+			"path":      []string{"/foo/bar"},
+			"spaceID":   []string{string(spaceID)},
+			"versionID": []string{string(versionID)},
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/versions/restore", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+}
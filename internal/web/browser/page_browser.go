@@ -1,20 +1,20 @@
 package browser
 
 import (
-	"archive/zip"
 	"context"
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"net/http"
 	"path"
-	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools"
@@ -33,34 +33,48 @@ const (
 	PageSize       = 30
 )
 
-var ErrInvalidSpaceID = errors.New("invalid spaceID")
+var (
+	ErrInvalidSpaceID     = errors.New("invalid spaceID")
+	errPreconditionFailed = errors.New("precondition failed")
+)
 
 type BrowserPage struct {
-	html       html.Writer
-	spaces     spaces.Service
-	files      files.Service
-	uuid       uuid.Service
-	auth       *auth.Authenticator
-	fs         dfs.Service
-	uploadLock *sync.Mutex
+	html   html.Writer
+	spaces spaces.Service
+	users  users.Service
+	files  files.Service
+	uuid   uuid.Service
+	auth   *auth.Authenticator
+	fs     dfs.Service
+	shares shares.Service
+	locks  davlocks.Service
+
+	// createDirLock serializes the creation of the parent directories by the
+	// parallel uploads.
+	createDirLock sync.Mutex
 }
 
 func NewBrowserPage(
 	tools tools.Tools,
 	html html.Writer,
 	spaces spaces.Service,
+	users users.Service,
 	files files.Service,
 	auth *auth.Authenticator,
 	fs dfs.Service,
+	shares shares.Service,
+	locks davlocks.Service,
 ) *BrowserPage {
 	return &BrowserPage{
-		html:       html,
-		spaces:     spaces,
-		files:      files,
-		uuid:       tools.UUID(),
-		auth:       auth,
-		fs:         fs,
-		uploadLock: new(sync.Mutex),
+		html:   html,
+		spaces: spaces,
+		users:  users,
+		files:  files,
+		uuid:   tools.UUID(),
+		auth:   auth,
+		fs:     fs,
+		shares: shares,
+		locks:  locks,
 	}
 }
 
@@ -77,8 +91,16 @@ func (h *BrowserPage) Register(r chi.Router, mids *router.Middlewares) {
 	r.Delete("/browser/{spaceID}/*", h.deleteAll)
 
 	newCreateDirModalHandler(h.auth, h.spaces, h.html, h.uuid, h.fs).Register(r, mids)
-	newRenameModalHandler(h.auth, h.spaces, h.html, h.uuid, h.fs).Register(r, mids)
-	newMoveModalHandler(h.auth, h.spaces, h.html, h.uuid, h.fs).Register(r, mids)
+	newRenameModalHandler(h.auth, h.spaces, h.html, h.uuid, h.fs, h.locks).Register(r, mids)
+	newMoveModalHandler(h.auth, h.spaces, h.html, h.uuid, h.fs, h.locks).Register(r, mids)
+	newCopyModalHandler(h.auth, h.spaces, h.html, h.uuid, h.fs, h.locks).Register(r, mids)
+	newVersionsModalHandler(h.auth, h.spaces, h.html, h.uuid, h.fs).Register(r, mids)
+	newTrashPageHandler(h.auth, h.spaces, h.html, h.uuid, h.fs).Register(r, mids)
+	newSearchPageHandler(h.auth, h.spaces, h.html, h.uuid, h.fs).Register(r, mids)
+	newShareModalHandler(h.auth, h.spaces, h.html, h.uuid, h.fs, h.shares).Register(r, mids)
+	newGrantsModalHandler(h.auth, h.spaces, h.users, h.html, h.uuid, h.fs).Register(r, mids)
+	newSharedPageHandler(h.auth, h.spaces, h.files, h.html, h.uuid, h.fs).Register(r, mids)
+	newSharePageHandler(h.spaces, h.users, h.files, h.html, h.fs, h.shares, h.lauchUpload).Register(r, mids)
 }
 
 func (h *BrowserPage) redirectDefaultBrowser(w http.ResponseWriter, r *http.Request) {
@@ -117,7 +139,7 @@ func (h *BrowserPage) getBrowserContent(w http.ResponseWriter, r *http.Request)
 
 	lastElem := r.URL.Query().Get("last")
 	if lastElem != "" {
-		h.renderMoreDirContent(w, r, path, lastElem)
+		h.renderMoreDirContent(w, r, user, path, lastElem)
 		return
 	}
 
@@ -130,65 +152,71 @@ func (h *BrowserPage) upload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	reader, err := r.MultipartReader()
+	form, err := readUploadForm(r)
 	if err != nil {
-		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to get mutlipart reader: %w", err))
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to parse form: %w", err))
 		return
 	}
 
-	var name, rawSpaceID, rootPath, relPath []byte
+	if form.file == nil {
+		return
+	}
+	defer form.file.Close()
 
-	for {
-		p, err := reader.NextPart()
-		if errors.Is(err, io.EOF) {
-			break
-		}
+	spaceID, err := h.uuid.Parse(form.spaceID)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("invalid space id %q", form.spaceID))
+		return
+	}
 
-		if p.FileName() != "" {
-			spaceID, err := h.uuid.Parse(string(rawSpaceID))
-			if err != nil {
-				h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("invalid space id %q", rawSpaceID))
-				return
-			}
-
-			cmd := lauchUploadCmd{
-				user:       user,
-				name:       string(name),
-				spaceID:    spaceID,
-				relPath:    string(relPath),
-				rootPath:   string(rootPath),
-				fileReader: p,
-			}
-
-			defer p.Close()
-			err = h.lauchUpload(r.Context(), &cmd)
-			if err != nil {
-				logger.LogEntrySetError(r.Context(), fmt.Errorf("upload error: %w", err))
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+	space, err := h.spaces.GetUserSpace(r.Context(), user.ID(), spaceID)
+	if err != nil {
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("upload error: failed to GetUserSpace: %w", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
-		switch p.FormName() {
-		case "name":
-			name, err = io.ReadAll(p)
-		// case "type":
-		// 	ftype, err = io.ReadAll(p)
-		case "rootPath":
-			rootPath, err = io.ReadAll(p)
-		case "spaceID":
-			rawSpaceID, err = io.ReadAll(p)
-		case "relativePath":
-			relPath, err = io.ReadAll(p)
-		}
-		if err != nil {
-			h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to parse form: %w", err))
-			return
-		}
+	err = h.lauchUpload(r.Context(), &lauchUploadCmd{
+		user:       user,
+		space:      space,
+		name:       form.name,
+		relPath:    form.relPath,
+		rootPath:   form.rootPath,
+		fileReader: form.file,
+		modifiedAt: form.modifiedAt,
+		conditions: r.Header,
+	})
+	if errors.Is(err, errPreconditionFailed) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		w.Write([]byte("The file has been modified in the meantime"))
+		return
+	}
+
+	if errors.Is(err, dfs.ErrQuotaExceeded) {
+		w.WriteHeader(http.StatusInsufficientStorage)
+		w.Write([]byte("The storage quota is exceeded"))
+		return
+	}
+
+	if errors.Is(err, dfs.ErrReadOnly) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("You have a read only access to this space"))
+		return
+	}
+
+	if errors.Is(err, davlocks.ErrLocked) {
+		w.WriteHeader(http.StatusLocked)
+		w.Write([]byte("The file is locked by an another client"))
+		return
+	}
+
+	if err != nil {
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("upload error: %w", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
+
+	w.WriteHeader(http.StatusOK)
 }
 
 func (h *BrowserPage) deleteAll(w http.ResponseWriter, r *http.Request) {
@@ -202,7 +230,34 @@ func (h *BrowserPage) deleteAll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.fs.Remove(r.Context(), path)
+	err := confirmUnlocked(r.Context(), h.locks, user, path)
+	if errors.Is(err, davlocks.ErrLocked) {
+		w.WriteHeader(http.StatusLocked)
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, err)
+		return
+	}
+
+	ok, err := checkPreconditions(r.Context(), h.fs, r.Header, path)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, err)
+		return
+	}
+
+	if !ok {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	err = h.fs.Remove(r.Context(), user, path)
+	if errors.Is(err, errs.ErrUnauthorized) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
 	if err != nil {
 		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to fs.Remove: %w", err))
 		return
@@ -214,17 +269,22 @@ func (h *BrowserPage) deleteAll(w http.ResponseWriter, r *http.Request) {
 type lauchUploadCmd struct {
 	fileReader io.Reader
 	user       *users.User
+	space      *spaces.Space
 	name       string
-	spaceID    uuid.UUID
 	rootPath   string
 	relPath    string
+	modifiedAt time.Time
+	// createOnly makes the upload fail with dfs.ErrAlreadyExists instead of
+	// replacing an existing file.
+	createOnly bool
+	// conditions are the headers of the conditional request, evaluated
+	// against the existing file once it's locked. A failed condition returns
+	// errPreconditionFailed.
+	conditions http.Header
 }
 
 func (h *BrowserPage) lauchUpload(ctx context.Context, cmd *lauchUploadCmd) error {
-	space, err := h.spaces.GetUserSpace(ctx, cmd.user.ID(), cmd.spaceID)
-	if err != nil {
-		return fmt.Errorf("failed to GetByID: %w", err)
-	}
+	space := cmd.space
 
 	var fullPath string
 	if cmd.relPath == "null" || cmd.relPath == "" {
@@ -237,24 +297,42 @@ func (h *BrowserPage) lauchUpload(ctx context.Context, cmd *lauchUploadCmd) erro
 		fullPath = fullPath[1:]
 	}
 
-	// TODO: Replace this lock by a webdav lock implementation.
-	h.uploadLock.Lock()
+	filePath := dfs.NewPathCmd(space, fullPath)
 
-	dirPath := path.Dir(fullPath)
-	_, err = h.fs.CreateDir(ctx, &dfs.CreateDirCmd{
-		Path:      dfs.NewPathCmd(space, dirPath),
-		CreatedBy: cmd.user,
+	// The file stays locked during all the upload so the WebDAV clients can't
+	// modify it in the meantime. A lock already taken by a WebDAV client on
+	// the file or one of its parents makes the upload fail.
+	fileLock, err := h.locks.Create(ctx, &davlocks.CreateCmd{
+		User:  cmd.user,
+		Path:  filePath,
+		Scope: davlocks.ScopeExclusive,
+		Depth: davlocks.DepthZero,
 	})
-	if err != nil && !errors.Is(err, dfs.ErrAlreadyExists) {
-		h.uploadLock.Unlock()
-		return fmt.Errorf("failed to create the directory %q: %w", dirPath, err)
+	if err != nil {
+		return fmt.Errorf("failed to lock the file: %w", err)
+	}
+	defer h.unlock(ctx, cmd.user, filePath, fileLock)
+
+	ok, err := checkPreconditions(ctx, h.fs, cmd.conditions, filePath)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errPreconditionFailed
+	}
+
+	err = h.createParentDir(ctx, cmd.user, filePath)
+	if err != nil {
+		return err
 	}
-	h.uploadLock.Unlock()
 
 	err = h.fs.Upload(ctx, &dfs.UploadCmd{
-		Path:       dfs.NewPathCmd(space, fullPath),
+		Path:       filePath,
 		Content:    cmd.fileReader,
 		UploadedBy: cmd.user,
+		ModifiedAt: cmd.modifiedAt,
+		CreateOnly: cmd.createOnly,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to Upload file: %w", err)
@@ -263,7 +341,39 @@ func (h *BrowserPage) lauchUpload(ctx context.Context, cmd *lauchUploadCmd) erro
 	return nil
 }
 
-func (h BrowserPage) getPathFromURL(w http.ResponseWriter, r *http.Request, user *users.User) *dfs.PathCmd {
+// createParentDir creates the parent directory of the file if it doesn't exists yet.
+//
+// Several uploads in the same folder can run in parallel so the creation is
+// serialized with createDirLock.
+func (h *BrowserPage) createParentDir(ctx context.Context, user *users.User, filePath *dfs.PathCmd) error {
+	h.createDirLock.Lock()
+	defer h.createDirLock.Unlock()
+
+	dirPath := path.Dir(filePath.Path())
+	_, err := h.fs.CreateDir(ctx, &dfs.CreateDirCmd{
+		Path:      dfs.NewPathCmd(filePath.Space(), dirPath),
+		CreatedBy: user,
+	})
+	if err != nil && !errors.Is(err, dfs.ErrAlreadyExists) {
+		return fmt.Errorf("failed to create the directory %q: %w", dirPath, err)
+	}
+
+	return nil
+}
+
+func (h *BrowserPage) unlock(ctx context.Context, user *users.User, cmd *dfs.PathCmd, lock *davlocks.Lock) {
+	// The lock must be released even if the request have been canceled.
+	err := h.locks.Unlock(context.WithoutCancel(ctx), &davlocks.UnlockCmd{
+		User:  user,
+		Path:  cmd,
+		Token: lock.Token(),
+	})
+	if err != nil {
+		logger.LogEntrySetError(ctx, fmt.Errorf("failed to unlock %q: %w", cmd.Path(), err))
+	}
+}
+
+func (h *BrowserPage) getPathFromURL(w http.ResponseWriter, r *http.Request, user *users.User) *dfs.PathCmd {
 	// no need to check elems len as the url format force a len of 3 minimum
 	spaceID, err := h.uuid.Parse(chi.URLParam(r, "spaceID"))
 	if err != nil {
@@ -326,18 +436,38 @@ func (h *BrowserPage) renderBrowserContent(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	role, err := h.spaces.GetUserRole(r.Context(), user.ID(), cmd.Space().ID())
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserRole: %w", err))
+		return
+	}
+
+	usage, err := getSpaceUsage(r.Context(), h.fs, cmd.Space())
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, err)
+		return
+	}
+
 	w.Header().Set("HX-Push-Url", path.Join("/browser", string(cmd.Space().ID()), cmd.Path()))
 
 	h.html.WriteHTMLTemplate(w, r, http.StatusOK, &browser.ContentTemplate{
 		Folder:        cmd,
 		Inodes:        dirContent,
 		CurrentSpace:  cmd.Space(),
+		SpaceUsage:    usage,
 		AllSpaces:     spaces,
+		Role:          role,
 		ContentTarget: "body",
 	})
 }
 
-func (h *BrowserPage) renderMoreDirContent(w http.ResponseWriter, r *http.Request, folderPath *dfs.PathCmd, lastElem string) {
+func (h *BrowserPage) renderMoreDirContent(w http.ResponseWriter, r *http.Request, user *users.User, folderPath *dfs.PathCmd, lastElem string) {
+	role, err := h.spaces.GetUserRole(r.Context(), user.ID(), folderPath.Space().ID())
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserRole: %w", err))
+		return
+	}
+
 	dirContent, err := h.fs.ListDir(r.Context(), folderPath, &sqlstorage.PaginateCmd{
 		StartAfter: map[string]string{"name": lastElem},
 		Limit:      PageSize,
@@ -350,6 +480,7 @@ func (h *BrowserPage) renderMoreDirContent(w http.ResponseWriter, r *http.Reques
 	h.html.WriteHTMLTemplate(w, r, http.StatusOK, &browser.RowsTemplate{
 		Inodes:        dirContent,
 		Folder:        folderPath,
+		Role:          role,
 		ContentTarget: "body",
 	})
 }
@@ -377,7 +508,7 @@ func (h *BrowserPage) download(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if inode.IsDir() {
-		h.serveFolderContent(w, r, pathCmd)
+		serveFolderContent(w, r, h.html, h.fs, pathCmd)
 	} else {
 		fileMeta, _ := h.files.GetMetadata(r.Context(), *inode.FileID())
 
@@ -392,66 +523,3 @@ func (h *BrowserPage) download(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
-
-func (h *BrowserPage) serveFolderContent(w http.ResponseWriter, r *http.Request, cmd *dfs.PathCmd) {
-	var err error
-
-	_, dir := path.Split(cmd.Path())
-
-	w.Header().Set("Content-Type", "application/zip")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", dir))
-	w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate")
-
-	writer := zip.NewWriter(w)
-
-	dfs.Walk(r.Context(), h.fs, cmd, func(ctx context.Context, p string, i *dfs.INode) error {
-		header := &zip.FileHeader{
-			Method:             zip.Deflate,
-			Comment:            "From DuckCloud with love",
-			Name:               i.Name(),
-			UncompressedSize64: i.Size(),
-			Modified:           i.LastModifiedAt(),
-		}
-
-		if i.IsDir() {
-			header.SetMode(0o755 | fs.ModeDir)
-		} else {
-			header.SetMode(0o644)
-		}
-
-		header.Name, err = filepath.Rel(cmd.Path(), p)
-		if err != nil {
-			return fmt.Errorf("failed to find the relative path: %w", err)
-		}
-
-		if i.IsDir() {
-			header.Name += "/"
-		}
-
-		headerWriter, err := writer.CreateHeader(header)
-		if err != nil {
-			return fmt.Errorf("failed to create the zip header: %w", err)
-		}
-
-		if i.IsDir() {
-			return nil
-		}
-
-		file, err := h.fs.Download(ctx, dfs.NewPathCmd(cmd.Space(), path.Join(p, i.Name())))
-		if err != nil {
-			return fmt.Errorf("failed to download for zip: %w", err)
-		}
-
-		_, err = io.Copy(headerWriter, file)
-
-		return err
-	})
-
-	err = writer.Close()
-	if err != nil {
-		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to Close the zip file: %w", err))
-		return
-	}
-
-	w.WriteHeader(http.StatusOK)
-}
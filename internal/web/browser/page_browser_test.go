@@ -9,14 +9,17 @@ import (
 	"net/http/httptest"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/service/websessions"
@@ -39,7 +42,9 @@ func Test_Browser_Page(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := NewBrowserPage(tools, htmlMock, spacesMock, filesMock, auth, fsMock)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -69,7 +74,9 @@ func Test_Browser_Page(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := NewBrowserPage(tools, htmlMock, spacesMock, filesMock, auth, fsMock)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(nil, websessions.ErrMissingSessionToken).Once()
@@ -95,7 +102,9 @@ func Test_Browser_Page(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := NewBrowserPage(tools, htmlMock, spacesMock, filesMock, auth, fsMock)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -103,6 +112,8 @@ func Test_Browser_Page(t *testing.T) {
 
 		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
 			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace, spaces.ExampleAliceBobSharedSpace}, nil).Once()
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.RoleManager, nil).Once()
 
 		// Get the space from the url
 		tools.UUIDMock.On("Parse", "d09f29f9-5131-4aa4-b69c-7717124b213e").Return(uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e"), nil).Once()
@@ -117,11 +128,15 @@ func Test_Browser_Page(t *testing.T) {
 			Limit:      PageSize,
 		}).Return([]dfs.INode{dfs.ExampleAliceFile}, nil).Once()
 
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/")).Return(&dfs.ExampleAliceRoot, nil).Once()
+
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.ContentTemplate{
 			Folder:        dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
 			Inodes:        []dfs.INode{dfs.ExampleAliceFile},
 			CurrentSpace:  &spaces.ExampleAlicePersonalSpace,
+			SpaceUsage:    dfs.ExampleAliceRoot.Size(),
 			AllSpaces:     []spaces.Space{spaces.ExampleAlicePersonalSpace, spaces.ExampleAliceBobSharedSpace},
+			Role:          spaces.RoleManager,
 			ContentTarget: "body",
 		}).Once()
 
@@ -146,7 +161,9 @@ func Test_Browser_Page(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := NewBrowserPage(tools, htmlMock, spacesMock, filesMock, auth, fsMock)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -157,6 +174,9 @@ func Test_Browser_Page(t *testing.T) {
 		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e")).
 			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
 
+		spacesMock.On("GetUserRole", mock.Anything, users.ExampleAlice.ID(), spaces.ExampleAlicePersonalSpace.ID()).
+			Return(spaces.RoleViewer, nil).Once()
+
 		fsMock.On("ListDir", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"), &sqlstorage.PaginateCmd{
 			StartAfter: map[string]string{"name": "some-filename"},
 			Limit:      PageSize,
@@ -165,6 +185,7 @@ func Test_Browser_Page(t *testing.T) {
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.RowsTemplate{
 			Folder:        dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
 			Inodes:        []dfs.INode{dfs.ExampleAliceFile},
+			Role:          spaces.RoleViewer,
 			ContentTarget: "body",
 		}).Once()
 
@@ -189,7 +210,9 @@ func Test_Browser_Page(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := NewBrowserPage(tools, htmlMock, spacesMock, filesMock, auth, fsMock)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -231,7 +254,9 @@ func Test_Browser_Page(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := NewBrowserPage(tools, htmlMock, spacesMock, filesMock, auth, fsMock)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(nil, websessions.ErrMissingSessionToken).Once()
@@ -259,7 +284,9 @@ func Test_Browser_Page(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := NewBrowserPage(tools, htmlMock, spacesMock, filesMock, auth, fsMock)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -290,7 +317,9 @@ func Test_Browser_Page(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := NewBrowserPage(tools, htmlMock, spacesMock, filesMock, auth, fsMock)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -324,7 +353,9 @@ func Test_Browser_Page(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := NewBrowserPage(tools, htmlMock, spacesMock, filesMock, auth, fsMock)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -360,7 +391,9 @@ func Test_Browser_Page(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := NewBrowserPage(tools, htmlMock, spacesMock, filesMock, auth, fsMock)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
 
 		content := "Hello, World!"
 
@@ -373,6 +406,20 @@ func Test_Browser_Page(t *testing.T) {
 		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e")).
 			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
 
+		filePath := dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar/hello.txt")
+		fileLock := davlocks.NewFakeLock(t, filePath, &users.ExampleAlice).WithDepth(davlocks.DepthZero).Build()
+		locksMock.On("Create", mock.Anything, &davlocks.CreateCmd{
+			User:  &users.ExampleAlice,
+			Path:  filePath,
+			Scope: davlocks.ScopeExclusive,
+			Depth: davlocks.DepthZero,
+		}).Return(fileLock, nil).Once()
+		locksMock.On("Unlock", mock.Anything, &davlocks.UnlockCmd{
+			User:  &users.ExampleAlice,
+			Path:  filePath,
+			Token: fileLock.Token(),
+		}).Return(nil).Once()
+
 		fsMock.On("CreateDir", mock.Anything, &dfs.CreateDirCmd{
 			Path:      dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo/bar"),
 			CreatedBy: &users.ExampleAlice,
@@ -415,6 +462,190 @@ func Test_Browser_Page(t *testing.T) {
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	})
 
+	t.Run("upload file with a quota exceeded", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		filesMock := files.NewMockService(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", "d09f29f9-5131-4aa4-b69c-7717124b213e").Return(uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e"), nil).Once()
+
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		filePath := dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar/hello.txt")
+		fileLock := davlocks.NewFakeLock(t, filePath, &users.ExampleAlice).WithDepth(davlocks.DepthZero).Build()
+		locksMock.On("Create", mock.Anything, &davlocks.CreateCmd{
+			User:  &users.ExampleAlice,
+			Path:  filePath,
+			Scope: davlocks.ScopeExclusive,
+			Depth: davlocks.DepthZero,
+		}).Return(fileLock, nil).Once()
+		locksMock.On("Unlock", mock.Anything, &davlocks.UnlockCmd{
+			User:  &users.ExampleAlice,
+			Path:  filePath,
+			Token: fileLock.Token(),
+		}).Return(nil).Once()
+
+		fsMock.On("CreateDir", mock.Anything, &dfs.CreateDirCmd{
+			Path:      dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo/bar"),
+			CreatedBy: &users.ExampleAlice,
+		}).Return(&dfs.ExampleAliceDir, nil).Once()
+		fsMock.On("Upload", mock.Anything, mock.Anything).
+			Return(errs.BadRequest(dfs.ErrQuotaExceeded, "the space quota is exceeded")).Once()
+
+		buf := bytes.NewBuffer(nil)
+		form := multipart.NewWriter(buf)
+		form.WriteField("name", "hello.txt")
+		form.WriteField("rootPath", "/foo/bar")
+		form.WriteField("spaceID", "d09f29f9-5131-4aa4-b69c-7717124b213e")
+		writer, err := form.CreateFormFile("file", "hello.txt")
+		require.NoError(t, err)
+		_, err = writer.Write([]byte("Hello, World!"))
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/upload", buf)
+		r.Header.Set("Content-Type", form.FormDataContentType())
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusInsufficientStorage, res.StatusCode)
+	})
+
+	t.Run("upload file with a file locked by an another client", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		filesMock := files.NewMockService(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", "d09f29f9-5131-4aa4-b69c-7717124b213e").Return(uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e"), nil).Once()
+
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		locksMock.On("Create", mock.Anything, &davlocks.CreateCmd{
+			User:  &users.ExampleAlice,
+			Path:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar/hello.txt"),
+			Scope: davlocks.ScopeExclusive,
+			Depth: davlocks.DepthZero,
+		}).Return(nil, errs.BadRequest(davlocks.ErrLocked)).Once()
+
+		buf := bytes.NewBuffer(nil)
+		form := multipart.NewWriter(buf)
+		form.WriteField("name", "hello.txt")
+		form.WriteField("rootPath", "/foo/bar")
+		form.WriteField("spaceID", "d09f29f9-5131-4aa4-b69c-7717124b213e")
+		writer, err := form.CreateFormFile("file", "hello.txt")
+		require.NoError(t, err)
+		_, err = writer.Write([]byte("Hello, World!"))
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/upload", buf)
+		r.Header.Set("Content-Type", form.FormDataContentType())
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusLocked, res.StatusCode)
+	})
+
+	t.Run("upload file with a failed If-Match", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		filesMock := files.NewMockService(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", "d09f29f9-5131-4aa4-b69c-7717124b213e").Return(uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e"), nil).Once()
+
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		filePath := dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar/hello.txt")
+		fileLock := davlocks.NewFakeLock(t, filePath, &users.ExampleAlice).WithDepth(davlocks.DepthZero).Build()
+		locksMock.On("Create", mock.Anything, &davlocks.CreateCmd{
+			User:  &users.ExampleAlice,
+			Path:  filePath,
+			Scope: davlocks.ScopeExclusive,
+			Depth: davlocks.DepthZero,
+		}).Return(fileLock, nil).Once()
+		locksMock.On("Unlock", mock.Anything, &davlocks.UnlockCmd{
+			User:  &users.ExampleAlice,
+			Path:  filePath,
+			Token: fileLock.Token(),
+		}).Return(nil).Once()
+
+		// The file have been modified since the client read it.
+		fsMock.On("Get", mock.Anything, filePath).Return(&dfs.ExampleAliceFile, nil).Once()
+
+		buf := bytes.NewBuffer(nil)
+		form := multipart.NewWriter(buf)
+		form.WriteField("name", "hello.txt")
+		form.WriteField("rootPath", "/foo/bar")
+		form.WriteField("spaceID", "d09f29f9-5131-4aa4-b69c-7717124b213e")
+		writer, err := form.CreateFormFile("file", "hello.txt")
+		require.NoError(t, err)
+		_, err = writer.Write([]byte("Hello, World!"))
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/upload", buf)
+		r.Header.Set("Content-Type", form.FormDataContentType())
+		r.Header.Set("If-Match", `"some-old-etag"`)
+
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	})
+
 	t.Run("upload space success", func(t *testing.T) {
 		tools := tools.NewMock(t)
 		webSessionsMock := websessions.NewMockService(t)
@@ -424,7 +655,9 @@ func Test_Browser_Page(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := NewBrowserPage(tools, htmlMock, spacesMock, filesMock, auth, fsMock)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
 
 		content := "Hello, World!"
 
@@ -437,6 +670,20 @@ func Test_Browser_Page(t *testing.T) {
 		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e")).
 			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
 
+		filePath := dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar/baz/hello.txt")
+		fileLock := davlocks.NewFakeLock(t, filePath, &users.ExampleAlice).WithDepth(davlocks.DepthZero).Build()
+		locksMock.On("Create", mock.Anything, &davlocks.CreateCmd{
+			User:  &users.ExampleAlice,
+			Path:  filePath,
+			Scope: davlocks.ScopeExclusive,
+			Depth: davlocks.DepthZero,
+		}).Return(fileLock, nil).Once()
+		locksMock.On("Unlock", mock.Anything, &davlocks.UnlockCmd{
+			User:  &users.ExampleAlice,
+			Path:  filePath,
+			Token: fileLock.Token(),
+		}).Return(nil).Once()
+
 		fsMock.On("CreateDir", mock.Anything, &dfs.CreateDirCmd{
 			Path:      dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "foo/bar/baz"),
 			CreatedBy: &users.ExampleAlice,
@@ -449,6 +696,7 @@ func Test_Browser_Page(t *testing.T) {
 
 				require.Equal(t, "/foo/bar/baz/hello.txt", cmd.Path.Path())
 				require.Equal(t, &users.ExampleAlice, cmd.UploadedBy)
+				require.Equal(t, time.Unix(1426154400, 0).UTC(), cmd.ModifiedAt)
 
 				uploaded, err := io.ReadAll(cmd.Content)
 				require.NoError(t, err)
@@ -462,6 +710,7 @@ func Test_Browser_Page(t *testing.T) {
 		form.WriteField("rootPath", "/foo/bar")
 		form.WriteField("spaceID", "d09f29f9-5131-4aa4-b69c-7717124b213e")
 		form.WriteField("relativePath", "/baz/hello.txt")
+		form.WriteField("lastModified", "1426154400000")
 		writer, err := form.CreateFormFile("file", "hello.txt")
 		require.NoError(t, err)
 
@@ -492,7 +741,9 @@ func Test_Browser_Page(t *testing.T) {
 		filesMock := files.NewMockService(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		fsMock := dfs.NewMockService(t)
-		handler := NewBrowserPage(tools, htmlMock, spacesMock, filesMock, auth, fsMock)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
 
 		// Authentication
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
@@ -503,7 +754,11 @@ func Test_Browser_Page(t *testing.T) {
 		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e")).
 			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
 
-		fsMock.On("Remove", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).Return(nil).Once()
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+		}).Return(nil).Once()
+		fsMock.On("Remove", mock.Anything, &users.ExampleAlice, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).Return(nil).Once()
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodDelete, "/browser/d09f29f9-5131-4aa4-b69c-7717124b213e/foo/bar", nil)
@@ -518,4 +773,122 @@ func Test_Browser_Page(t *testing.T) {
 		require.NoError(t, err)
 		assert.Empty(t, body)
 	})
+
+	t.Run("deleteAll with a read only access", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		filesMock := files.NewMockService(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", "d09f29f9-5131-4aa4-b69c-7717124b213e").Return(uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+		}).Return(nil).Once()
+		fsMock.On("Remove", mock.Anything, &users.ExampleAlice, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(errs.Unauthorized(dfs.ErrReadOnly)).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/browser/d09f29f9-5131-4aa4-b69c-7717124b213e/foo/bar", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+	t.Run("deleteAll with a locked file", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		filesMock := files.NewMockService(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", "d09f29f9-5131-4aa4-b69c-7717124b213e").Return(uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+		}).Return(errs.BadRequest(davlocks.ErrLocked)).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/browser/d09f29f9-5131-4aa4-b69c-7717124b213e/foo/bar", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusLocked, res.StatusCode)
+	})
+
+	t.Run("deleteAll with a failed If-Unmodified-Since", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		filesMock := files.NewMockService(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		locksMock := davlocks.NewMockService(t)
+		handler := NewBrowserPage(tools, htmlMock, spacesMock, usersMock, filesMock, auth, fsMock, sharesMock, locksMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", "d09f29f9-5131-4aa4-b69c-7717124b213e").Return(uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e"), nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), uuid.UUID("d09f29f9-5131-4aa4-b69c-7717124b213e")).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		locksMock.On("Confirm", mock.Anything, &davlocks.ConfirmCmd{
+			User: &users.ExampleAlice,
+			Path: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+		}).Return(nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/browser/d09f29f9-5131-4aa4-b69c-7717124b213e/foo/bar", nil)
+		r.Header.Set("If-Unmodified-Since", dfs.ExampleAliceFile.LastModifiedAt().Add(-time.Hour).UTC().Format(http.TimeFormat))
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	})
 }
@@ -0,0 +1,222 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/logger"
+	"github.com/theduckcompany/duckcloud/internal/tools/router"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+	"github.com/theduckcompany/duckcloud/internal/web/auth"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	"github.com/theduckcompany/duckcloud/internal/web/html/templates/browser"
+)
+
+const (
+	searchDateFormat = "2006-01-02"
+	searchSizeUnit   = 1000 * 1000 // MB
+)
+
+type searchPageHandler struct {
+	auth   *auth.Authenticator
+	spaces spaces.Service
+	html   html.Writer
+	uuid   uuid.Service
+	fs     dfs.Service
+}
+
+func newSearchPageHandler(
+	auth *auth.Authenticator,
+	spaces spaces.Service,
+	html html.Writer,
+	uuid uuid.Service,
+	fs dfs.Service,
+) *searchPageHandler {
+	return &searchPageHandler{auth, spaces, html, uuid, fs}
+}
+
+func (h *searchPageHandler) Register(r chi.Router, mids *router.Middlewares) {
+	if mids != nil {
+		r = r.With(mids.Defaults()...)
+	}
+
+	r.Get("/search/{spaceID}", h.getSearchContent)
+}
+
+func (h *searchPageHandler) getSearchContent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	space := h.getSpaceFromURL(w, r, user)
+	if space == nil {
+		return
+	}
+
+	query := r.URL.Query()
+	filters := browser.SearchFilters{
+		Query:     query.Get("q"),
+		SpaceID:   query.Get("space"),
+		MimeType:  query.Get("mimetype"),
+		MinSize:   query.Get("minSize"),
+		MaxSize:   query.Get("maxSize"),
+		After:     query.Get("after"),
+		Before:    query.Get("before"),
+		InContent: query.Get("content"),
+	}
+	lastElem := query.Get("last")
+
+	results := []dfs.SearchResult{}
+	if filters.Query != "" {
+		cmd, err := h.parseFilters(user, &filters)
+		if err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			logger.LogEntrySetError(ctx, err)
+			return
+		}
+
+		results, err = h.fs.Search(ctx, cmd, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"c.path": lastElem},
+			Limit:      PageSize,
+		})
+		if errors.Is(err, errs.ErrValidation) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			logger.LogEntrySetError(ctx, err)
+			return
+		}
+
+		if errors.Is(err, errs.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			logger.LogEntrySetError(ctx, err)
+			return
+		}
+
+		if err != nil {
+			h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to Search: %w", err))
+			return
+		}
+	}
+
+	allSpaces, err := h.spaces.GetAllUserSpaces(ctx, user.ID(), nil)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetAllUserSpaces: %w", err))
+		return
+	}
+
+	if lastElem != "" {
+		h.html.WriteHTMLTemplate(w, r, http.StatusOK, (&browser.SearchTemplate{
+			CurrentSpace: space,
+			AllSpaces:    allSpaces,
+			Filters:      filters,
+			Results:      results,
+		}).Rows())
+		return
+	}
+
+	usage, err := getSpaceUsage(ctx, h.fs, space)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, err)
+		return
+	}
+
+	h.html.WriteHTMLTemplate(w, r, http.StatusOK, &browser.SearchTemplate{
+		Folder:       dfs.NewPathCmd(space, "/"),
+		CurrentSpace: space,
+		SpaceUsage:   usage,
+		AllSpaces:    allSpaces,
+		Filters:      filters,
+		Results:      results,
+	})
+}
+
+// parseFilters converts the raw form values into a [dfs.SearchCmd]. The sizes
+// are given in MB and the dates are full days.
+func (h *searchPageHandler) parseFilters(user *users.User, filters *browser.SearchFilters) (*dfs.SearchCmd, error) {
+	cmd := dfs.SearchCmd{
+		User:      user,
+		Query:     filters.Query,
+		MimeType:  filters.MimeType,
+		InContent: filters.InContent != "",
+	}
+
+	if filters.SpaceID != "" {
+		spaceID, err := h.uuid.Parse(filters.SpaceID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid space %q: %w", filters.SpaceID, err)
+		}
+
+		cmd.SpaceID = spaceID
+	}
+
+	if filters.MinSize != "" {
+		minSize, err := strconv.ParseUint(filters.MinSize, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min size %q: %w", filters.MinSize, err)
+		}
+
+		cmd.MinSize = minSize * searchSizeUnit
+	}
+
+	if filters.MaxSize != "" {
+		maxSize, err := strconv.ParseUint(filters.MaxSize, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max size %q: %w", filters.MaxSize, err)
+		}
+
+		cmd.MaxSize = maxSize * searchSizeUnit
+	}
+
+	if filters.After != "" {
+		after, err := time.Parse(searchDateFormat, filters.After)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q: %w", filters.After, err)
+		}
+
+		cmd.ModifiedAfter = after
+	}
+
+	if filters.Before != "" {
+		before, err := time.Parse(searchDateFormat, filters.Before)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q: %w", filters.Before, err)
+		}
+
+		// Include the whole day.
+		cmd.ModifiedBefore = before.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return &cmd, nil
+}
+
+func (h *searchPageHandler) getSpaceFromURL(w http.ResponseWriter, r *http.Request, user *users.User) *spaces.Space {
+	spaceID, err := h.uuid.Parse(chi.URLParam(r, "spaceID"))
+	if err != nil {
+		http.Redirect(w, r, "/browser", http.StatusFound)
+		return nil
+	}
+
+	space, err := h.spaces.GetUserSpace(r.Context(), user.ID(), spaceID)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserSpace: %w", err))
+		return nil
+	}
+
+	if space == nil {
+		http.Redirect(w, r, "/browser", http.StatusFound)
+		return nil
+	}
+
+	return space
+}
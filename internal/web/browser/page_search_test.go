@@ -0,0 +1,281 @@
+package browser
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/service/websessions"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+	"github.com/theduckcompany/duckcloud/internal/web/auth"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	"github.com/theduckcompany/duckcloud/internal/web/html/templates/browser"
+)
+
+func Test_SearchPageHandler(t *testing.T) {
+	spaceID := spaces.ExampleAlicePersonalSpace.ID()
+
+	t.Run("getSearchContent success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newSearchPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Twice()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("Search", mock.Anything, &dfs.SearchCmd{
+			User:           &users.ExampleAlice,
+			Query:          "foo",
+			SpaceID:        spaceID,
+			MimeType:       "image/*",
+			MinSize:        1000 * 1000,
+			MaxSize:        10 * 1000 * 1000,
+			ModifiedAfter:  time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+			ModifiedBefore: time.Date(2024, time.January, 3, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond),
+			InContent:      true,
+		}, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"c.path": ""},
+			Limit:      PageSize,
+		}).Return([]dfs.SearchResult{dfs.ExampleAliceFileSearchResult}, nil).Once()
+
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/")).Return(&dfs.ExampleAliceRoot, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.SearchTemplate{
+			Folder:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+			CurrentSpace: &spaces.ExampleAlicePersonalSpace,
+			SpaceUsage:   dfs.ExampleAliceRoot.Size(),
+			AllSpaces:    []spaces.Space{spaces.ExampleAlicePersonalSpace},
+			Filters: browser.SearchFilters{
+				Query:     "foo",
+				SpaceID:   string(spaceID),
+				MimeType:  "image/*",
+				MinSize:   "1",
+				MaxSize:   "10",
+				After:     "2024-01-02",
+				Before:    "2024-01-02",
+				InContent: "on",
+			},
+			Results: []dfs.SearchResult{dfs.ExampleAliceFileSearchResult},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/search/"+string(spaceID)+
+			"?q=foo&space="+string(spaceID)+"&mimetype=image/*&minSize=1&maxSize=10&after=2024-01-02&before=2024-01-02&content=on", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getSearchContent without query", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newSearchPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/")).Return(&dfs.ExampleAliceRoot, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.SearchTemplate{
+			Folder:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+			CurrentSpace: &spaces.ExampleAlicePersonalSpace,
+			SpaceUsage:   dfs.ExampleAliceRoot.Size(),
+			AllSpaces:    []spaces.Space{spaces.ExampleAlicePersonalSpace},
+			Filters:      browser.SearchFilters{},
+			Results:      []dfs.SearchResult{},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/search/"+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getSearchContent with a last element", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newSearchPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("Search", mock.Anything, &dfs.SearchCmd{
+			User:  &users.ExampleAlice,
+			Query: "foo",
+		}, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"c.path": "/bar"},
+			Limit:      PageSize,
+		}).Return([]dfs.SearchResult{dfs.ExampleAliceFileSearchResult}, nil).Once()
+
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.SearchRowsTemplate{
+			CurrentSpace: &spaces.ExampleAlicePersonalSpace,
+			Filters:      browser.SearchFilters{Query: "foo"},
+			SpaceNames:   map[uuid.UUID]string{spaceID: spaces.ExampleAlicePersonalSpace.Name()},
+			Results:      []dfs.SearchResult{dfs.ExampleAliceFileSearchResult},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/search/"+string(spaceID)+"?q=foo&last=/bar", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getSearchContent with an invalid size", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newSearchPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/search/"+string(spaceID)+"?q=foo&minSize=-3", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	})
+
+	t.Run("getSearchContent with a validation error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newSearchPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("Search", mock.Anything, &dfs.SearchCmd{
+			User:    &users.ExampleAlice,
+			Query:   "foo",
+			MinSize: 10 * 1000 * 1000,
+			MaxSize: 1000 * 1000,
+		}, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"c.path": ""},
+			Limit:      PageSize,
+		}).Return(nil, errs.Validation(fmt.Errorf("some-error"))).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/search/"+string(spaceID)+"?q=foo&minSize=10&maxSize=1", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	})
+
+	t.Run("getSearchContent with a Search error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newSearchPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("Search", mock.Anything, &dfs.SearchCmd{
+			User:  &users.ExampleAlice,
+			Query: "foo",
+		}, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"c.path": ""},
+			Limit:      PageSize,
+		}).Return(nil, fmt.Errorf("some-error")).Once()
+
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to Search: %w", fmt.Errorf("some-error"))).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/search/"+string(spaceID)+"?q=foo", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,510 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/logger"
+	"github.com/theduckcompany/duckcloud/internal/tools/router"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	sharestmpl "github.com/theduckcompany/duckcloud/internal/web/html/templates/shares"
+)
+
+const (
+	// shareKeyCookie keeps the unlock key of a protected share, never its
+	// password.
+	shareKeyCookie = "share_key"
+	// shareDownloadCookie keeps the key of the last download counted for a
+	// shared file.
+	shareDownloadCookie = "share_download"
+	// maxNameAttempts is the number of suffixes tried to find an available
+	// name for a file uploaded through an upload link.
+	maxNameAttempts = 100
+)
+
+var (
+	errUploadTooLarge   = errors.New("upload size limit exceeded")
+	errNoAvailableName  = errors.New("no available name")
+	errInvalidFileName  = errors.New("invalid file name")
+	errNotAnUploadShare = errors.New("not an upload share")
+)
+
+type lauchUploadFunc func(ctx context.Context, cmd *lauchUploadCmd) error
+
+// sharePageHandler serves the public links. None of its routes require an
+// authenticated user.
+type sharePageHandler struct {
+	spaces      spaces.Service
+	users       users.Service
+	files       files.Service
+	html        html.Writer
+	fs          dfs.Service
+	shares      shares.Service
+	lauchUpload lauchUploadFunc
+}
+
+func newSharePageHandler(
+	spaces spaces.Service,
+	users users.Service,
+	files files.Service,
+	html html.Writer,
+	fs dfs.Service,
+	shares shares.Service,
+	lauchUpload lauchUploadFunc,
+) *sharePageHandler {
+	return &sharePageHandler{spaces, users, files, html, fs, shares, lauchUpload}
+}
+
+func (h *sharePageHandler) Register(r chi.Router, mids *router.Middlewares) {
+	if mids != nil {
+		r = r.With(mids.Defaults()...)
+	}
+
+	r.Get("/s/{token}", h.getShare)
+	r.Get("/s/{token}/*", h.getShare)
+	r.Post("/s/{token}", h.unlockShare)
+	r.Post("/s/{token}/upload", h.uploadToShare)
+}
+
+func (h *sharePageHandler) getShare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	token := chi.URLParam(r, "token")
+
+	share, err := h.shares.Open(ctx, &shares.OpenCmd{
+		Token:     secret.NewText(token),
+		UnlockKey: getShareUnlockKey(r),
+	})
+	if h.handleOpenError(w, r, token, err) {
+		return
+	}
+
+	root, err := h.getShareRoot(ctx, share)
+	if errors.Is(err, errs.ErrNotFound) {
+		h.html.WriteHTMLTemplate(w, r, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{})
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to get the shared inode: %w", err))
+		return
+	}
+
+	// CleanPath removes any ".." so the target can't be outside of the share.
+	relPath := dfs.CleanPath(chi.URLParam(r, "*"))
+
+	if share.Kind() == shares.UploadKind {
+		if relPath != "/" {
+			h.html.WriteHTMLTemplate(w, r, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{})
+			return
+		}
+
+		h.renderUploadPage(w, r, share, root)
+		return
+	}
+
+	target := dfs.NewPathCmd(root.Space(), path.Join(root.Path(), relPath))
+
+	inode, err := h.fs.Get(ctx, target)
+	if errors.Is(err, errs.ErrNotFound) {
+		h.html.WriteHTMLTemplate(w, r, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{})
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to get the file: %w", err))
+		return
+	}
+
+	switch {
+	case !inode.IsDir():
+		h.serveFile(w, r, share, target, inode)
+	case r.FormValue("download") != "":
+		if h.registerDownload(w, r, share) {
+			return
+		}
+
+		serveFolderContent(w, r, h.html, h.fs, target)
+	default:
+		h.serveFolderListing(w, r, root, relPath, target)
+	}
+}
+
+func (h *sharePageHandler) unlockShare(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	password := secret.NewText(r.FormValue("password"))
+
+	unlockKey, err := h.shares.Unlock(r.Context(), &shares.OpenCmd{
+		Token:     secret.NewText(token),
+		Password:  password,
+		UnlockKey: secret.Empty,
+	})
+	if errors.Is(err, errs.ErrUnauthorized) {
+		h.html.WriteHTMLTemplate(w, r, http.StatusBadRequest, &sharestmpl.PasswordPageTemplate{
+			Token: token,
+			Error: "Invalid password",
+		})
+		return
+	}
+
+	if h.handleOpenError(w, r, token, err) {
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     shareKeyCookie,
+		Value:    unlockKey.Raw(),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Path:     path.Join("/s", token),
+	})
+
+	http.Redirect(w, r, path.Join("/s", token), http.StatusFound)
+}
+
+// uploadToShare receives a file sent to an upload link. The response is read
+// by the upload widget so the errors are written as plain text.
+func (h *sharePageHandler) uploadToShare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	share, err := h.shares.Open(ctx, &shares.OpenCmd{
+		Token:     secret.NewText(chi.URLParam(r, "token")),
+		UnlockKey: getShareUnlockKey(r),
+	})
+	if err == nil && share.Kind() != shares.UploadKind {
+		err = errs.NotFound(errNotAnUploadShare)
+	}
+
+	if err != nil {
+		h.writeUploadError(w, r, err)
+		return
+	}
+
+	root, err := h.getShareRoot(ctx, share)
+	if err != nil {
+		h.writeUploadError(w, r, err)
+		return
+	}
+
+	// The files are uploaded in the name of the link creator.
+	user, err := h.users.GetByID(ctx, share.CreatedBy())
+	if err != nil {
+		h.writeUploadError(w, r, fmt.Errorf("failed to get the share creator: %w", err))
+		return
+	}
+
+	form, err := readUploadForm(r)
+	if err != nil || form.file == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Invalid upload"))
+		return
+	}
+	defer form.file.Close()
+
+	// Only the file name is kept, the visitor can't choose where the file is
+	// written.
+	name := path.Base(dfs.CleanPath(form.name))
+	if name == "/" {
+		h.writeUploadError(w, r, errs.BadRequest(errInvalidFileName))
+		return
+	}
+
+	reader := &uploadLimitReader{r: form.file, limit: 0, read: 0}
+	if share.MaxUploadSize() > 0 {
+		reader.limit = share.MaxUploadSize() - min(share.MaxUploadSize(), share.UploadedSize())
+	}
+
+	// An other upload can take the available name before this one. The upload
+	// is done in create only mode so it fails instead of replacing the file
+	// and a new name is searched as long as the content is not consumed.
+	wantedName := name
+	for attempt := 0; ; attempt++ {
+		name, err = findAvailableName(ctx, h.fs, root, wantedName)
+		if err != nil {
+			h.writeUploadError(w, r, err)
+			return
+		}
+
+		err = h.lauchUpload(ctx, &lauchUploadCmd{
+			user:       user,
+			space:      root.Space(),
+			name:       name,
+			rootPath:   root.Path(),
+			relPath:    "",
+			fileReader: reader,
+			modifiedAt: form.modifiedAt,
+			createOnly: true,
+		})
+		if errors.Is(err, dfs.ErrAlreadyExists) && reader.read == 0 && attempt < maxNameAttempts {
+			continue
+		}
+
+		if err != nil {
+			h.writeUploadError(w, r, err)
+			return
+		}
+
+		break
+	}
+
+	err = h.shares.RegisterUpload(ctx, share, reader.read)
+	if err != nil {
+		// An other upload has reached the limits in the meantime.
+		_ = h.fs.Remove(ctx, user, dfs.NewPathCmd(root.Space(), path.Join(root.Path(), name)))
+		h.writeUploadError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *sharePageHandler) writeUploadError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errUploadTooLarge), errors.Is(err, shares.ErrUploadLimitReached):
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		w.Write([]byte("The upload limit of this link is reached"))
+	case errors.Is(err, dfs.ErrQuotaExceeded):
+		w.WriteHeader(http.StatusInsufficientStorage)
+		w.Write([]byte("The storage quota is exceeded"))
+	case errors.Is(err, shares.ErrInvalidPassword):
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("This link is protected by a password"))
+	case errors.Is(err, errs.ErrUnauthorized):
+		// The link creator has lost its write access to the folder.
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("This link doesn't allow to add files anymore"))
+	case errors.Is(err, dfs.ErrAlreadyExists):
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte("A file with the same name was added in the meantime"))
+	case errors.Is(err, errs.ErrNotFound), errors.Is(err, errs.ErrValidation):
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("This link is not available"))
+	case errors.Is(err, davlocks.ErrLocked):
+		w.WriteHeader(http.StatusLocked)
+		w.Write([]byte("The file is locked by an another client"))
+	case errors.Is(err, errs.ErrBadRequest):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Invalid upload"))
+	default:
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("share upload error: %w", err))
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (h *sharePageHandler) renderUploadPage(w http.ResponseWriter, r *http.Request, share *shares.Share, root *dfs.PathCmd) {
+	tmpl := &sharestmpl.UploadPageTemplate{
+		ExpiresAt:        share.ExpiresAt(),
+		Token:            chi.URLParam(r, "token"),
+		Name:             path.Base(root.Path()),
+		RemainingUploads: 0,
+		RemainingSize:    0,
+	}
+
+	if share.MaxUploads() > 0 {
+		tmpl.RemainingUploads = share.MaxUploads() - share.Uploads()
+	}
+
+	if share.MaxUploadSize() > 0 {
+		tmpl.RemainingSize = share.MaxUploadSize() - share.UploadedSize()
+	}
+
+	h.html.WriteHTMLTemplate(w, r, http.StatusOK, tmpl)
+}
+
+func (h *sharePageHandler) serveFile(w http.ResponseWriter, r *http.Request, share *shares.Share, target *dfs.PathCmd, inode *dfs.INode) {
+	// Every request counts as a download. Only the range requests resuming a
+	// download counted recently for this visitor are free.
+	if !h.isResumedDownload(r, share, inode) {
+		if h.registerDownload(w, r, share) {
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     shareDownloadCookie,
+			Value:    h.shares.NewDownloadKey(share, inode.ID()).Raw(),
+			Secure:   true,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+			Path:     r.URL.EscapedPath(),
+			MaxAge:   int(shares.DownloadKeyTTL.Seconds()),
+		})
+	}
+
+	fileMeta, _ := h.files.GetMetadata(r.Context(), *inode.FileID())
+
+	file, err := h.fs.Download(r.Context(), target)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to Download: %w", err))
+		return
+	}
+	defer file.Close()
+
+	serveContent(w, r, inode, file, fileMeta)
+}
+
+func (h *sharePageHandler) serveFolderListing(w http.ResponseWriter, r *http.Request, root *dfs.PathCmd, relPath string, target *dfs.PathCmd) {
+	lastElem := r.URL.Query().Get("last")
+
+	inodes, err := h.fs.ListDir(r.Context(), target, &sqlstorage.PaginateCmd{
+		StartAfter: map[string]string{"name": lastElem},
+		Limit:      PageSize,
+	})
+	if err != nil && !errors.Is(err, io.EOF) {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to ListDir: %w", err))
+		return
+	}
+
+	tmpl := &sharestmpl.FolderPageTemplate{
+		Token:  chi.URLParam(r, "token"),
+		Name:   path.Base(root.Path()),
+		Path:   relPath,
+		Inodes: inodes,
+	}
+
+	if lastElem != "" {
+		h.html.WriteHTMLTemplate(w, r, http.StatusOK, tmpl.Rows())
+		return
+	}
+
+	h.html.WriteHTMLTemplate(w, r, http.StatusOK, tmpl)
+}
+
+// registerDownload increments the download counter of the share. It returns
+// true if the request must be aborted.
+func (h *sharePageHandler) registerDownload(w http.ResponseWriter, r *http.Request, share *shares.Share) bool {
+	err := h.shares.RegisterDownload(r.Context(), share)
+	if errors.Is(err, errs.ErrNotFound) {
+		h.html.WriteHTMLTemplate(w, r, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{})
+		return true
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to RegisterDownload: %w", err))
+		return true
+	}
+
+	return false
+}
+
+// handleOpenError writes the response for a share which can't be opened. It
+// returns true if the request must be aborted.
+func (h *sharePageHandler) handleOpenError(w http.ResponseWriter, r *http.Request, token string, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, errs.ErrNotFound), errors.Is(err, errs.ErrValidation):
+		h.html.WriteHTMLTemplate(w, r, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{})
+	case errors.Is(err, errs.ErrUnauthorized):
+		h.html.WriteHTMLTemplate(w, r, http.StatusUnauthorized, &sharestmpl.PasswordPageTemplate{
+			Token: token,
+			Error: "",
+		})
+	default:
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to open the share: %w", err))
+	}
+
+	return true
+}
+
+func (h *sharePageHandler) getShareRoot(ctx context.Context, share *shares.Share) (*dfs.PathCmd, error) {
+	space, err := h.spaces.GetByID(ctx, share.SpaceID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetByID: %w", err)
+	}
+
+	return h.fs.GetPathByID(ctx, space, share.INodeID())
+}
+
+// isResumedDownload returns true for a range request which doesn't start at
+// the first byte and which comes with a valid download key. The suffix ranges
+// can read the whole file so they are never considered as resumed.
+func (h *sharePageHandler) isResumedDownload(r *http.Request, share *shares.Share, inode *dfs.INode) bool {
+	ranges, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes=")
+	if !ok {
+		return false
+	}
+
+	for _, spec := range strings.Split(ranges, ",") {
+		start, _, _ := strings.Cut(strings.TrimSpace(spec), "-")
+
+		offset, err := strconv.ParseUint(start, 10, 64)
+		if err != nil || offset == 0 {
+			return false
+		}
+	}
+
+	cookie, err := r.Cookie(shareDownloadCookie)
+	if err != nil {
+		return false
+	}
+
+	return h.shares.IsValidDownloadKey(share, inode.ID(), secret.NewText(cookie.Value))
+}
+
+func getShareUnlockKey(r *http.Request) secret.Text {
+	cookie, err := r.Cookie(shareKeyCookie)
+	if err != nil {
+		return secret.Empty
+	}
+
+	return secret.NewText(cookie.Value)
+}
+
+// findAvailableName returns a name not used inside the folder. A file sent
+// through an upload link must never overwrite an existing file so a suffix
+// is added if needed: "photo.jpg" becomes "photo (1).jpg".
+func findAvailableName(ctx context.Context, fs dfs.Service, dir *dfs.PathCmd, name string) (string, error) {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+
+	for i := 1; i <= maxNameAttempts; i++ {
+		_, err := fs.Get(ctx, dfs.NewPathCmd(dir.Space(), path.Join(dir.Path(), candidate)))
+		if errors.Is(err, errs.ErrNotFound) {
+			return candidate, nil
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("failed to Get %q: %w", candidate, err)
+		}
+
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+
+	return "", errs.BadRequest(errNoAvailableName)
+}
+
+// uploadLimitReader counts the bytes read and fails with errUploadTooLarge
+// once the limit is exceeded. A zero limit means no limit.
+type uploadLimitReader struct {
+	r     io.Reader
+	limit uint64
+	read  uint64
+}
+
+func (l *uploadLimitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += uint64(n)
+
+	if l.limit > 0 && l.read > l.limit {
+		return n, errUploadTooLarge
+	}
+
+	return n, err
+}
@@ -0,0 +1,823 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/secret"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	sharestmpl "github.com/theduckcompany/duckcloud/internal/web/html/templates/shares"
+)
+
+func Test_SharePageHandler(t *testing.T) {
+	fileToken := shares.ExampleAliceFileShare.Token().Raw()
+	dirToken := shares.ExampleAliceProtectedDirShare.Token().Raw()
+	uploadToken := shares.ExampleAliceUploadShare.Token().Raw()
+
+	newUploadForm := func(t *testing.T, name string, content string) (io.Reader, string) {
+		t.Helper()
+
+		buf := bytes.NewBuffer(nil)
+		form := multipart.NewWriter(buf)
+		form.WriteField("name", name)
+		writer, err := form.CreateFormFile("file", name)
+		require.NoError(t, err)
+		_, err = writer.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+
+		return buf, form.FormDataContentType()
+	}
+
+	t.Run("getShare with a file", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(fileToken),
+			UnlockKey: secret.Empty,
+		}).Return(&shares.ExampleAliceFileShare, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceFile.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"), nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		sharesMock.On("RegisterDownload", mock.Anything, &shares.ExampleAliceFileShare).Return(nil).Once()
+		sharesMock.On("NewDownloadKey", &shares.ExampleAliceFileShare, dfs.ExampleAliceFile.ID()).
+			Return(secret.NewText("some-download-key")).Once()
+		filesMock.On("GetMetadata", mock.Anything, *dfs.ExampleAliceFile.FileID()).Return(&files.ExampleFile1, nil).Once()
+
+		afs := afero.NewMemMapFs()
+		file, err := afero.TempFile(afs, t.TempDir(), "")
+		require.NoError(t, err)
+
+		fsMock.On("Download", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).Return(file, nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/"+fileToken, nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.Len(t, res.Cookies(), 1)
+		assert.Equal(t, "share_download", res.Cookies()[0].Name)
+		assert.Equal(t, "some-download-key", res.Cookies()[0].Value)
+	})
+
+	t.Run("getShare with a download limit reached", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(fileToken),
+			UnlockKey: secret.Empty,
+		}).Return(&shares.ExampleAliceFileShare, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceFile.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"), nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		sharesMock.On("RegisterDownload", mock.Anything, &shares.ExampleAliceFileShare).
+			Return(errs.NotFound(shares.ErrDownloadLimitReached)).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/"+fileToken, nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getShare with a range request from the first byte after the download limit", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(fileToken),
+			UnlockKey: secret.Empty,
+		}).Return(&shares.ExampleAliceFileShare, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceFile.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"), nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		sharesMock.On("RegisterDownload", mock.Anything, &shares.ExampleAliceFileShare).
+			Return(errs.NotFound(shares.ErrDownloadLimitReached)).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/"+fileToken, nil)
+		r.Header.Set("Range", "bytes=0-")
+		// Even with a valid download key, a request from the first byte is a
+		// new download.
+		r.AddCookie(&http.Cookie{Name: "share_download", Value: "some-download-key"})
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getShare with a range request with an invalid download key", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(fileToken),
+			UnlockKey: secret.Empty,
+		}).Return(&shares.ExampleAliceFileShare, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceFile.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"), nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		sharesMock.On("IsValidDownloadKey", &shares.ExampleAliceFileShare, dfs.ExampleAliceFile.ID(), secret.NewText("some-invalid-key")).
+			Return(false).Once()
+		sharesMock.On("RegisterDownload", mock.Anything, &shares.ExampleAliceFileShare).
+			Return(errs.NotFound(shares.ErrDownloadLimitReached)).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/"+fileToken, nil)
+		r.Header.Set("Range", "bytes=5-")
+		r.AddCookie(&http.Cookie{Name: "share_download", Value: "some-invalid-key"})
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getShare with a range request with a suffix range request", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(fileToken),
+			UnlockKey: secret.Empty,
+		}).Return(&shares.ExampleAliceFileShare, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceFile.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"), nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		sharesMock.On("RegisterDownload", mock.Anything, &shares.ExampleAliceFileShare).
+			Return(errs.NotFound(shares.ErrDownloadLimitReached)).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/"+fileToken, nil)
+		// A suffix range can read the whole file, it's always a new download.
+		r.Header.Set("Range", "bytes=-500")
+		r.AddCookie(&http.Cookie{Name: "share_download", Value: "some-download-key"})
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getShare with a range request resuming a counted download", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(fileToken),
+			UnlockKey: secret.Empty,
+		}).Return(&shares.ExampleAliceFileShare, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceFile.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"), nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		sharesMock.On("IsValidDownloadKey", &shares.ExampleAliceFileShare, dfs.ExampleAliceFile.ID(), secret.NewText("some-download-key")).
+			Return(true).Once()
+		filesMock.On("GetMetadata", mock.Anything, *dfs.ExampleAliceFile.FileID()).Return(&files.ExampleFile1, nil).Once()
+
+		afs := afero.NewMemMapFs()
+		file, err := afero.TempFile(afs, t.TempDir(), "")
+		require.NoError(t, err)
+		_, err = file.WriteString("some-content")
+		require.NoError(t, err)
+		_, err = file.Seek(0, io.SeekStart)
+		require.NoError(t, err)
+
+		fsMock.On("Download", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).Return(file, nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/"+fileToken, nil)
+		r.Header.Set("Range", "bytes=5-")
+		r.AddCookie(&http.Cookie{Name: "share_download", Value: "some-download-key"})
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusPartialContent, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(body))
+	})
+
+	t.Run("getShare with an unknown token", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText("some-token"),
+			UnlockKey: secret.Empty,
+		}).Return(nil, errs.ErrNotFound).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/some-token", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getShare with a password required", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(dirToken),
+			UnlockKey: secret.Empty,
+		}).Return(nil, errs.Unauthorized(shares.ErrInvalidPassword)).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusUnauthorized, &sharestmpl.PasswordPageTemplate{
+			Token: dirToken,
+			Error: "",
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/"+dirToken, nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getShare with a folder and the password cookie", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(dirToken),
+			UnlockKey: secret.NewText("some-unlock-key"),
+		}).Return(&shares.ExampleAliceProtectedDirShare, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceDir.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"), nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar")).
+			Return(&dfs.ExampleAliceDir, nil).Once()
+		fsMock.On("ListDir", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"), &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"name": ""},
+			Limit:      PageSize,
+		}).Return([]dfs.INode{dfs.ExampleAliceFile}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &sharestmpl.FolderPageTemplate{
+			Token:  dirToken,
+			Name:   "foo",
+			Path:   "/bar",
+			Inodes: []dfs.INode{dfs.ExampleAliceFile},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/"+dirToken+"/bar", nil)
+		r.AddCookie(&http.Cookie{Name: shareKeyCookie, Value: "some-unlock-key"})
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getShare doesn't allow to escape the shared folder", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(dirToken),
+			UnlockKey: secret.Empty,
+		}).Return(&shares.ExampleAliceProtectedDirShare, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceDir.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"), nil).Once()
+		// The ".." are removed and the path stays inside "/foo".
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/secret")).
+			Return(nil, errs.ErrNotFound).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/"+dirToken+"/../secret", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getShare with a deleted target", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(fileToken),
+			UnlockKey: secret.Empty,
+		}).Return(&shares.ExampleAliceFileShare, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceFile.ID()).
+			Return(nil, errs.NotFound(dfs.ErrNotFound)).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/"+fileToken, nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("unlockShare success", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Unlock", mock.Anything, &shares.OpenCmd{
+			Token:    secret.NewText(dirToken),
+			Password: secret.NewText("some-password"),
+		}).Return(secret.NewText("some-unlock-key"), nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/s/"+dirToken, strings.NewReader(url.Values{
+			"password": []string{"some-password"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+		assert.Equal(t, "/s/"+dirToken, res.Header.Get("Location"))
+		require.Len(t, res.Cookies(), 1)
+		assert.Equal(t, shareKeyCookie, res.Cookies()[0].Name)
+		assert.Equal(t, "some-unlock-key", res.Cookies()[0].Value)
+		assert.Equal(t, "/s/"+dirToken, res.Cookies()[0].Path)
+	})
+
+	t.Run("unlockShare with an invalid password", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Unlock", mock.Anything, &shares.OpenCmd{
+			Token:    secret.NewText(dirToken),
+			Password: secret.NewText("invalid"),
+		}).Return(secret.Empty, errs.Unauthorized(shares.ErrInvalidPassword)).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusBadRequest, &sharestmpl.PasswordPageTemplate{
+			Token: dirToken,
+			Error: "Invalid password",
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/s/"+dirToken, strings.NewReader(url.Values{
+			"password": []string{"invalid"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Empty(t, res.Cookies())
+	})
+
+	t.Run("getShare with an upload share", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(uploadToken),
+			UnlockKey: secret.Empty,
+		}).Return(&shares.ExampleAliceUploadShare, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceDir.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"), nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &sharestmpl.UploadPageTemplate{
+			ExpiresAt:        shares.ExampleAliceUploadShare.ExpiresAt(),
+			Token:            uploadToken,
+			Name:             "foo",
+			RemainingUploads: 18,
+			RemainingSize:    100*1024*1024 - 2048,
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/"+uploadToken, nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getShare doesn't list the content of an upload share", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(uploadToken),
+			UnlockKey: secret.Empty,
+		}).Return(&shares.ExampleAliceUploadShare, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceDir.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"), nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusNotFound, &sharestmpl.UnavailablePageTemplate{}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/s/"+uploadToken+"/bar.txt", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("uploadToShare success", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+
+		var uploadCmd *lauchUploadCmd
+		lauchUpload := func(_ context.Context, cmd *lauchUploadCmd) error {
+			uploadCmd = cmd
+			content, err := io.ReadAll(cmd.fileReader)
+			require.NoError(t, err)
+			assert.Equal(t, "Hello, World!", string(content))
+			return nil
+		}
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, lauchUpload)
+
+		share := shares.ExampleAliceUploadShare
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(uploadToken),
+			UnlockKey: secret.Empty,
+		}).Return(&share, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceDir.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"), nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// The name is already taken
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/hello.txt")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/hello (1).txt")).
+			Return(nil, errs.ErrNotFound).Once()
+
+		sharesMock.On("RegisterUpload", mock.Anything, &share, uint64(13)).Return(nil).Once()
+
+		body, contentType := newUploadForm(t, "../../hello.txt", "Hello, World!")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/s/"+uploadToken+"/upload", body)
+		r.Header.Set("Content-Type", contentType)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		require.NotNil(t, uploadCmd)
+		assert.Equal(t, &users.ExampleAlice, uploadCmd.user)
+		assert.Equal(t, &spaces.ExampleAlicePersonalSpace, uploadCmd.space)
+		assert.Equal(t, "/foo", uploadCmd.rootPath)
+		assert.Equal(t, "hello (1).txt", uploadCmd.name)
+		assert.Empty(t, uploadCmd.relPath)
+		assert.True(t, uploadCmd.createOnly)
+	})
+
+	t.Run("uploadToShare with a name taken by an other upload", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+
+		names := []string{}
+		lauchUpload := func(_ context.Context, cmd *lauchUploadCmd) error {
+			names = append(names, cmd.name)
+			if len(names) == 1 {
+				return fmt.Errorf("failed to Upload file: %w", errs.BadRequest(dfs.ErrAlreadyExists))
+			}
+
+			_, err := io.ReadAll(cmd.fileReader)
+			return err
+		}
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, lauchUpload)
+
+		share := shares.ExampleAliceUploadShare
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(uploadToken),
+			UnlockKey: secret.Empty,
+		}).Return(&share, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceDir.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"), nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// The name is free for the first attempt then taken for the second one
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/hello.txt")).
+			Return(nil, errs.ErrNotFound).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/hello.txt")).
+			Return(&dfs.ExampleAliceFile, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/hello (1).txt")).
+			Return(nil, errs.ErrNotFound).Once()
+
+		sharesMock.On("RegisterUpload", mock.Anything, &share, uint64(13)).Return(nil).Once()
+
+		body, contentType := newUploadForm(t, "hello.txt", "Hello, World!")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/s/"+uploadToken+"/upload", body)
+		r.Header.Set("Content-Type", contentType)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []string{"hello.txt", "hello (1).txt"}, names)
+	})
+
+	t.Run("uploadToShare with a creator without write access", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+
+		lauchUpload := func(_ context.Context, cmd *lauchUploadCmd) error {
+			return fmt.Errorf("failed to Upload file: %w", errs.Unauthorized(dfs.ErrReadOnly))
+		}
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, lauchUpload)
+
+		share := shares.ExampleAliceUploadShare
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(uploadToken),
+			UnlockKey: secret.Empty,
+		}).Return(&share, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceDir.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"), nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/hello.txt")).
+			Return(nil, errs.ErrNotFound).Once()
+
+		body, contentType := newUploadForm(t, "hello.txt", "Hello, World!")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/s/"+uploadToken+"/upload", body)
+		r.Header.Set("Content-Type", contentType)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("uploadToShare with a download share", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, nil)
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(dirToken),
+			UnlockKey: secret.Empty,
+		}).Return(&shares.ExampleAliceProtectedDirShare, nil).Once()
+
+		body, contentType := newUploadForm(t, "hello.txt", "Hello, World!")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/s/"+dirToken+"/upload", body)
+		r.Header.Set("Content-Type", contentType)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("uploadToShare with a file too large", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+
+		lauchUpload := func(_ context.Context, cmd *lauchUploadCmd) error {
+			_, err := io.ReadAll(cmd.fileReader)
+			return err
+		}
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, lauchUpload)
+
+		share := shares.NewFakeShare(t).
+			WithINode(&dfs.ExampleAliceDir).
+			WithUploadLimits(0, 10).
+			WithUploads(1, 5).
+			CreatedBy(&users.ExampleAlice).
+			Build()
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     share.Token(),
+			UnlockKey: secret.Empty,
+		}).Return(share, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceDir.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"), nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/hello.txt")).
+			Return(nil, errs.ErrNotFound).Once()
+
+		body, contentType := newUploadForm(t, "hello.txt", "Hello, World!")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/s/"+share.Token().Raw()+"/upload", body)
+		r.Header.Set("Content-Type", contentType)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+	})
+
+	t.Run("uploadToShare with the limit reached by an other upload", func(t *testing.T) {
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		fsMock := dfs.NewMockService(t)
+		sharesMock := shares.NewMockService(t)
+
+		lauchUpload := func(_ context.Context, cmd *lauchUploadCmd) error {
+			_, err := io.ReadAll(cmd.fileReader)
+			return err
+		}
+		handler := newSharePageHandler(spacesMock, usersMock, filesMock, htmlMock, fsMock, sharesMock, lauchUpload)
+
+		share := shares.ExampleAliceUploadShare
+
+		sharesMock.On("Open", mock.Anything, &shares.OpenCmd{
+			Token:     secret.NewText(uploadToken),
+			UnlockKey: secret.Empty,
+		}).Return(&share, nil).Once()
+		spacesMock.On("GetByID", mock.Anything, spaces.ExampleAlicePersonalSpace.ID()).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+		fsMock.On("GetPathByID", mock.Anything, &spaces.ExampleAlicePersonalSpace, dfs.ExampleAliceDir.ID()).
+			Return(dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"), nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/hello.txt")).
+			Return(nil, errs.ErrNotFound).Once()
+		sharesMock.On("RegisterUpload", mock.Anything, &share, uint64(13)).
+			Return(errs.NotFound(shares.ErrUploadLimitReached)).Once()
+		fsMock.On("Remove", mock.Anything, &users.ExampleAlice, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/hello.txt")).
+			Return(nil).Once()
+
+		body, contentType := newUploadForm(t, "hello.txt", "Hello, World!")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/s/"+uploadToken+"/upload", body)
+		r.Header.Set("Content-Type", contentType)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+	})
+}
@@ -0,0 +1,239 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/router"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+	"github.com/theduckcompany/duckcloud/internal/web/auth"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	"github.com/theduckcompany/duckcloud/internal/web/html/templates/browser"
+)
+
+type sharedPageHandler struct {
+	auth   *auth.Authenticator
+	spaces spaces.Service
+	files  files.Service
+	html   html.Writer
+	uuid   uuid.Service
+	fs     dfs.Service
+}
+
+func newSharedPageHandler(
+	auth *auth.Authenticator,
+	spaces spaces.Service,
+	files files.Service,
+	html html.Writer,
+	uuid uuid.Service,
+	fs dfs.Service,
+) *sharedPageHandler {
+	return &sharedPageHandler{auth, spaces, files, html, uuid, fs}
+}
+
+func (h *sharedPageHandler) Register(r chi.Router, mids *router.Middlewares) {
+	if mids != nil {
+		r = r.With(mids.Defaults()...)
+	}
+
+	r.Get("/browser/shared", h.getSharedList)
+	r.Get("/browser/shared/{grantID}", h.getSharedContent)
+	r.Get("/browser/shared/{grantID}/*", h.getSharedContent)
+	r.Post("/browser/shared/{grantID}/delete", h.leaveSharedFolder)
+}
+
+func (h *sharedPageHandler) getSharedList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	grants, err := h.fs.GetUserGrants(ctx, user)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserGrants: %w", err))
+		return
+	}
+
+	items := make([]browser.SharedItem, 0, len(grants))
+	for _, grant := range grants {
+		root, err := h.fs.GetGrantPath(ctx, &grant)
+		if errors.Is(err, errs.ErrNotFound) {
+			// The shared folder is inside the trash.
+			continue
+		}
+
+		if err != nil {
+			h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetGrantPath: %w", err))
+			return
+		}
+
+		items = append(items, browser.SharedItem{
+			Grant:     grant,
+			Name:      path.Base(root.Path()),
+			SpaceName: root.Space().Name(),
+		})
+	}
+
+	allSpaces, err := h.spaces.GetAllUserSpaces(ctx, user.ID(), nil)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetAllUserSpaces: %w", err))
+		return
+	}
+
+	h.html.WriteHTMLTemplate(w, r, http.StatusOK, &browser.SharedTemplate{
+		Folder:       nil,
+		CurrentSpace: nil,
+		AllSpaces:    allSpaces,
+		Items:        items,
+	})
+}
+
+func (h *sharedPageHandler) getSharedContent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	grant, root, abort := h.getGrantFromURL(w, r, user)
+	if abort {
+		return
+	}
+
+	// The cleaning removes all the ".." so the target can't be outside the
+	// shared folder.
+	relPath := dfs.CleanPath(chi.URLParam(r, "*"))
+	target := dfs.NewPathCmd(root.Space(), path.Join(root.Path(), relPath))
+
+	inode, err := h.fs.Get(ctx, target)
+	if errors.Is(err, errs.ErrNotFound) {
+		http.Redirect(w, r, path.Join("/browser/shared", string(grant.ID())), http.StatusFound)
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to fs.Get: %w", err))
+		return
+	}
+
+	if !inode.IsDir() {
+		fileMeta, _ := h.files.GetMetadata(ctx, *inode.FileID())
+		file, err := h.fs.Download(ctx, target)
+		if err != nil {
+			h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to Download: %w", err))
+			return
+		}
+		defer file.Close()
+
+		serveContent(w, r, inode, file, fileMeta)
+		return
+	}
+
+	lastElem := r.URL.Query().Get("last")
+
+	dirContent, err := h.fs.ListDir(ctx, target, &sqlstorage.PaginateCmd{
+		StartAfter: map[string]string{"name": lastElem},
+		Limit:      PageSize,
+	})
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to ListDir: %w", err))
+		return
+	}
+
+	if lastElem != "" {
+		h.html.WriteHTMLTemplate(w, r, http.StatusOK, &browser.SharedRowsTemplate{
+			Grant:  grant,
+			Path:   relPath,
+			Inodes: dirContent,
+		})
+		return
+	}
+
+	allSpaces, err := h.spaces.GetAllUserSpaces(ctx, user.ID(), nil)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetAllUserSpaces: %w", err))
+		return
+	}
+
+	w.Header().Set("HX-Push-Url", path.Join("/browser/shared", string(grant.ID()), relPath))
+
+	h.html.WriteHTMLTemplate(w, r, http.StatusOK, &browser.SharedFolderTemplate{
+		Folder:       nil,
+		CurrentSpace: nil,
+		AllSpaces:    allSpaces,
+		Grant:        grant,
+		Name:         path.Base(root.Path()),
+		Path:         relPath,
+		Inodes:       dirContent,
+	})
+}
+
+func (h *sharedPageHandler) leaveSharedFolder(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	grantID, err := h.uuid.Parse(chi.URLParam(r, "grantID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err = h.fs.DeleteGrant(r.Context(), user, grantID)
+	if errors.Is(err, errs.ErrUnauthorized) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to DeleteGrant: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *sharedPageHandler) getGrantFromURL(w http.ResponseWriter, r *http.Request, user *users.User) (*dfs.Grant, *dfs.PathCmd, bool) {
+	grantID, err := h.uuid.Parse(chi.URLParam(r, "grantID"))
+	if err != nil {
+		http.Redirect(w, r, "/browser/shared", http.StatusFound)
+		return nil, nil, true
+	}
+
+	grant, err := h.fs.GetUserGrant(r.Context(), user.ID(), grantID)
+	if errors.Is(err, errs.ErrNotFound) {
+		http.Redirect(w, r, "/browser/shared", http.StatusFound)
+		return nil, nil, true
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserGrant: %w", err))
+		return nil, nil, true
+	}
+
+	root, err := h.fs.GetGrantPath(r.Context(), grant)
+	if errors.Is(err, errs.ErrNotFound) {
+		http.Redirect(w, r, "/browser/shared", http.StatusFound)
+		return nil, nil, true
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetGrantPath: %w", err))
+		return nil, nil, true
+	}
+
+	return grant, root, false
+}
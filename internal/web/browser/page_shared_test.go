@@ -0,0 +1,241 @@
+package browser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/service/websessions"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/web/auth"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	"github.com/theduckcompany/duckcloud/internal/web/html/templates/browser"
+)
+
+func Test_SharedPageHandler(t *testing.T) {
+	grantID := dfs.ExampleBobReadGrant.ID()
+	grantRoot := dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a")
+
+	t.Run("getSharedList success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newSharedPageHandler(auth, spacesMock, filesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.BobWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleBob.ID()).Return(&users.ExampleBob, nil).Once()
+
+		fsMock.On("GetUserGrants", mock.Anything, &users.ExampleBob).
+			Return([]dfs.Grant{dfs.ExampleBobReadGrant}, nil).Once()
+		fsMock.On("GetGrantPath", mock.Anything, &dfs.ExampleBobReadGrant).Return(grantRoot, nil).Once()
+
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleBob.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleBobPersonalSpace}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.SharedTemplate{
+			Folder:       nil,
+			CurrentSpace: nil,
+			AllSpaces:    []spaces.Space{spaces.ExampleBobPersonalSpace},
+			Items: []browser.SharedItem{{
+				Grant:     dfs.ExampleBobReadGrant,
+				Name:      "dir-a",
+				SpaceName: spaces.ExampleAlicePersonalSpace.Name(),
+			}},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/shared", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getSharedList with a shared folder in the trash", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newSharedPageHandler(auth, spacesMock, filesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.BobWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleBob.ID()).Return(&users.ExampleBob, nil).Once()
+
+		fsMock.On("GetUserGrants", mock.Anything, &users.ExampleBob).
+			Return([]dfs.Grant{dfs.ExampleBobReadGrant}, nil).Once()
+		fsMock.On("GetGrantPath", mock.Anything, &dfs.ExampleBobReadGrant).Return(nil, errs.NotFound(dfs.ErrNotFound)).Once()
+
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleBob.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleBobPersonalSpace}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.SharedTemplate{
+			Folder:       nil,
+			CurrentSpace: nil,
+			AllSpaces:    []spaces.Space{spaces.ExampleBobPersonalSpace},
+			Items:        []browser.SharedItem{},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/shared", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getSharedContent success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newSharedPageHandler(auth, spacesMock, filesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.BobWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleBob.ID()).Return(&users.ExampleBob, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(grantID)).Return(grantID, nil).Once()
+		fsMock.On("GetUserGrant", mock.Anything, users.ExampleBob.ID(), grantID).Return(&dfs.ExampleBobReadGrant, nil).Once()
+		fsMock.On("GetGrantPath", mock.Anything, &dfs.ExampleBobReadGrant).Return(grantRoot, nil).Once()
+
+		target := dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a/foo")
+		fsMock.On("Get", mock.Anything, target).Return(&dfs.ExampleAliceDir, nil).Once()
+		fsMock.On("ListDir", mock.Anything, target, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"name": ""},
+			Limit:      PageSize,
+		}).Return([]dfs.INode{dfs.ExampleAliceFile2}, nil).Once()
+
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleBob.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleBobPersonalSpace}, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.SharedFolderTemplate{
+			Folder:       nil,
+			CurrentSpace: nil,
+			AllSpaces:    []spaces.Space{spaces.ExampleBobPersonalSpace},
+			Grant:        &dfs.ExampleBobReadGrant,
+			Name:         "dir-a",
+			Path:         "/foo",
+			Inodes:       []dfs.INode{dfs.ExampleAliceFile2},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/shared/"+string(grantID)+"/foo", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		assert.Equal(t, "/browser/shared/"+string(grantID)+"/foo", w.Header().Get("HX-Push-Url"))
+	})
+
+	t.Run("getSharedContent with a path outside the shared folder", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newSharedPageHandler(auth, spacesMock, filesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.BobWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleBob.ID()).Return(&users.ExampleBob, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(grantID)).Return(grantID, nil).Once()
+		fsMock.On("GetUserGrant", mock.Anything, users.ExampleBob.ID(), grantID).Return(&dfs.ExampleBobReadGrant, nil).Once()
+		fsMock.On("GetGrantPath", mock.Anything, &dfs.ExampleBobReadGrant).Return(grantRoot, nil).Once()
+
+		// The ".." are removed, the path stays inside the shared folder.
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a/foo.pdf")).
+			Return(nil, errs.NotFound(dfs.ErrNotFound)).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/shared/"+string(grantID)+"/../../foo.pdf", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+		assert.Equal(t, "/browser/shared/"+string(grantID), res.Header.Get("Location"))
+	})
+
+	t.Run("getSharedContent with a grant not found", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newSharedPageHandler(auth, spacesMock, filesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(grantID)).Return(grantID, nil).Once()
+		fsMock.On("GetUserGrant", mock.Anything, users.ExampleAlice.ID(), grantID).Return(nil, errs.NotFound(dfs.ErrNotFound)).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/browser/shared/"+string(grantID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+		assert.Equal(t, "/browser/shared", res.Header.Get("Location"))
+	})
+
+	t.Run("leaveSharedFolder success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		filesMock := files.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newSharedPageHandler(auth, spacesMock, filesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.BobWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleBob.ID()).Return(&users.ExampleBob, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(grantID)).Return(grantID, nil).Once()
+		fsMock.On("DeleteGrant", mock.Anything, &users.ExampleBob, grantID).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/browser/shared/"+string(grantID)+"/delete", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+}
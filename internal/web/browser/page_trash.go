@@ -0,0 +1,200 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/router"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+	"github.com/theduckcompany/duckcloud/internal/web/auth"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	"github.com/theduckcompany/duckcloud/internal/web/html/templates/browser"
+)
+
+type trashPageHandler struct {
+	auth   *auth.Authenticator
+	spaces spaces.Service
+	html   html.Writer
+	uuid   uuid.Service
+	fs     dfs.Service
+}
+
+func newTrashPageHandler(
+	auth *auth.Authenticator,
+	spaces spaces.Service,
+	html html.Writer,
+	uuid uuid.Service,
+	fs dfs.Service,
+) *trashPageHandler {
+	return &trashPageHandler{auth, spaces, html, uuid, fs}
+}
+
+func (h *trashPageHandler) Register(r chi.Router, mids *router.Middlewares) {
+	if mids != nil {
+		r = r.With(mids.Defaults()...)
+	}
+
+	r.Get("/trash/{spaceID}", h.getTrashContent)
+	r.Post("/trash/{spaceID}/restore/{inodeID}", h.restore)
+	r.Post("/trash/{spaceID}/empty", h.emptyTrash)
+}
+
+func (h *trashPageHandler) getTrashContent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	space := h.getSpaceFromURL(w, r, user)
+	if space == nil {
+		return
+	}
+
+	lastElem := r.URL.Query().Get("last")
+
+	items, err := h.getTrashItems(r, space, lastElem)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, err)
+		return
+	}
+
+	if lastElem != "" {
+		h.html.WriteHTMLTemplate(w, r, http.StatusOK, &browser.TrashRowsTemplate{
+			Space: space,
+			Items: items,
+		})
+		return
+	}
+
+	allSpaces, err := h.spaces.GetAllUserSpaces(ctx, user.ID(), nil)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetAllUserSpaces: %w", err))
+		return
+	}
+
+	usage, err := getSpaceUsage(ctx, h.fs, space)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, err)
+		return
+	}
+
+	h.html.WriteHTMLTemplate(w, r, http.StatusOK, &browser.TrashTemplate{
+		Folder:       dfs.NewPathCmd(space, "/"),
+		CurrentSpace: space,
+		SpaceUsage:   usage,
+		AllSpaces:    allSpaces,
+		Items:        items,
+	})
+}
+
+func (h *trashPageHandler) getTrashItems(r *http.Request, space *spaces.Space, lastElem string) ([]browser.TrashItem, error) {
+	inodes, err := h.fs.ListDeleted(r.Context(), space, &sqlstorage.PaginateCmd{
+		StartAfter: map[string]string{"id": lastElem},
+		Limit:      PageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListDeleted: %w", err)
+	}
+
+	items := make([]browser.TrashItem, len(inodes))
+	for i, inode := range inodes {
+		originalPath, err := h.fs.GetOriginalPath(r.Context(), &inode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to GetOriginalPath for %q: %w", inode.ID(), err)
+		}
+
+		items[i] = browser.TrashItem{INode: inode, OriginalPath: originalPath}
+	}
+
+	return items, nil
+}
+
+func (h *trashPageHandler) restore(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	space := h.getSpaceFromURL(w, r, user)
+	if space == nil {
+		return
+	}
+
+	inodeID, err := h.uuid.Parse(chi.URLParam(r, "inodeID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	_, err = h.fs.Restore(r.Context(), &dfs.RestoreCmd{
+		Space:      space,
+		INodeID:    inodeID,
+		RestoredBy: user,
+	})
+	if errors.Is(err, errs.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to Restore: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *trashPageHandler) emptyTrash(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	space := h.getSpaceFromURL(w, r, user)
+	if space == nil {
+		return
+	}
+
+	err := h.fs.EmptyTrash(r.Context(), user, space)
+	if errors.Is(err, errs.ErrUnauthorized) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to EmptyTrash: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *trashPageHandler) getSpaceFromURL(w http.ResponseWriter, r *http.Request, user *users.User) *spaces.Space {
+	spaceID, err := h.uuid.Parse(chi.URLParam(r, "spaceID"))
+	if err != nil {
+		http.Redirect(w, r, "/browser", http.StatusFound)
+		return nil
+	}
+
+	space, err := h.spaces.GetUserSpace(r.Context(), user.ID(), spaceID)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserSpace: %w", err))
+		return nil
+	}
+
+	if space == nil {
+		http.Redirect(w, r, "/browser", http.StatusFound)
+		return nil
+	}
+
+	return space
+}
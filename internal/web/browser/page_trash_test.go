@@ -0,0 +1,366 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/service/websessions"
+	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+	"github.com/theduckcompany/duckcloud/internal/web/auth"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+	"github.com/theduckcompany/duckcloud/internal/web/html/templates/browser"
+)
+
+func Test_TrashPageHandler(t *testing.T) {
+	spaceID := spaces.ExampleAlicePersonalSpace.ID()
+
+	t.Run("getTrashContent success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newTrashPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("ListDeleted", mock.Anything, &spaces.ExampleAlicePersonalSpace, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      PageSize,
+		}).Return([]dfs.INode{dfs.ExampleAliceFile}, nil).Once()
+		fsMock.On("GetOriginalPath", mock.Anything, &dfs.ExampleAliceFile).Return("/foo", nil).Once()
+
+		spacesMock.On("GetAllUserSpaces", mock.Anything, users.ExampleAlice.ID(), (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{spaces.ExampleAlicePersonalSpace}, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/")).Return(&dfs.ExampleAliceRoot, nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.TrashTemplate{
+			Folder:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+			CurrentSpace: &spaces.ExampleAlicePersonalSpace,
+			SpaceUsage:   dfs.ExampleAliceRoot.Size(),
+			AllSpaces:    []spaces.Space{spaces.ExampleAlicePersonalSpace},
+			Items:        []browser.TrashItem{{INode: dfs.ExampleAliceFile, OriginalPath: "/foo"}},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/trash/"+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getTrashContent with a last element", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newTrashPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("ListDeleted", mock.Anything, &spaces.ExampleAlicePersonalSpace, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": "some-id"},
+			Limit:      PageSize,
+		}).Return([]dfs.INode{dfs.ExampleAliceFile}, nil).Once()
+		fsMock.On("GetOriginalPath", mock.Anything, &dfs.ExampleAliceFile).Return("/foo", nil).Once()
+
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &browser.TrashRowsTemplate{
+			Space: &spaces.ExampleAlicePersonalSpace,
+			Items: []browser.TrashItem{{INode: dfs.ExampleAliceFile, OriginalPath: "/foo"}},
+		}).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/trash/"+string(spaceID)+"?last=some-id", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("getTrashContent with an unauthenticated user", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newTrashPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(nil, websessions.ErrMissingSessionToken).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/trash/"+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+		assert.Equal(t, "/login", res.Header.Get("Location"))
+	})
+
+	t.Run("getTrashContent with a space not found", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newTrashPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(nil, nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/trash/"+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+		assert.Equal(t, "/browser", res.Header.Get("Location"))
+	})
+
+	t.Run("getTrashContent with a ListDeleted error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newTrashPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("ListDeleted", mock.Anything, &spaces.ExampleAlicePersonalSpace, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"id": ""},
+			Limit:      PageSize,
+		}).Return(nil, fmt.Errorf("some-error")).Once()
+
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to ListDeleted: %w", fmt.Errorf("some-error"))).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/trash/"+string(spaceID), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("restore success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newTrashPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(dfs.ExampleAliceFile.ID())).Return(dfs.ExampleAliceFile.ID(), nil).Once()
+		fsMock.On("Restore", mock.Anything, &dfs.RestoreCmd{
+			Space:      &spaces.ExampleAlicePersonalSpace,
+			INodeID:    dfs.ExampleAliceFile.ID(),
+			RestoredBy: &users.ExampleAlice,
+		}).Return(&dfs.ExampleAliceFile, nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/trash/"+string(spaceID)+"/restore/"+string(dfs.ExampleAliceFile.ID()), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("restore with an inode not found", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newTrashPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		tools.UUIDMock.On("Parse", string(dfs.ExampleAliceFile.ID())).Return(dfs.ExampleAliceFile.ID(), nil).Once()
+		fsMock.On("Restore", mock.Anything, &dfs.RestoreCmd{
+			Space:      &spaces.ExampleAlicePersonalSpace,
+			INodeID:    dfs.ExampleAliceFile.ID(),
+			RestoredBy: &users.ExampleAlice,
+		}).Return(nil, errs.NotFound(errors.New("not in the trash"))).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/trash/"+string(spaceID)+"/restore/"+string(dfs.ExampleAliceFile.ID()), nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("restore with an invalid inode id", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newTrashPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		tools.UUIDMock.On("Parse", "invalid").Return(uuid.UUID(""), errors.New("invalid")).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/trash/"+string(spaceID)+"/restore/invalid", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("emptyTrash success", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newTrashPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("EmptyTrash", mock.Anything, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/trash/"+string(spaceID)+"/empty", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("emptyTrash with an error", func(t *testing.T) {
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := newTrashPageHandler(auth, spacesMock, htmlMock, tools.UUID(), fsMock)
+
+		// Authentication
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(&websessions.AliceWebSessionExample, nil).Once()
+		usersMock.On("GetByID", mock.Anything, users.ExampleAlice.ID()).Return(&users.ExampleAlice, nil).Once()
+
+		// Get the space from the url
+		tools.UUIDMock.On("Parse", string(spaceID)).Return(spaceID, nil).Once()
+		spacesMock.On("GetUserSpace", mock.Anything, users.ExampleAlice.ID(), spaceID).
+			Return(&spaces.ExampleAlicePersonalSpace, nil).Once()
+
+		fsMock.On("EmptyTrash", mock.Anything, &users.ExampleAlice, &spaces.ExampleAlicePersonalSpace).Return(fmt.Errorf("some-error")).Once()
+
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to EmptyTrash: %w", fmt.Errorf("some-error"))).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/trash/"+string(spaceID)+"/empty", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+}
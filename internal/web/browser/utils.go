@@ -1,18 +1,32 @@
 package browser
 
 import (
+	"archive/zip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"mime/multipart"
 	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/theduckcompany/duckcloud/internal/service/davlocks"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/files"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
 )
 
 func serveContent(w http.ResponseWriter, r *http.Request, inode *dfs.INode, file io.ReadSeeker, fileMeta *files.FileMeta) {
+	w.Header().Set("ETag", inode.ETag())
 	if fileMeta != nil {
-		w.Header().Set("ETag", fmt.Sprintf("W/%q", fileMeta.Checksum()))
 		w.Header().Set("Content-Type", fileMeta.MimeType())
 	}
 
@@ -21,3 +35,241 @@ func serveContent(w http.ResponseWriter, r *http.Request, inode *dfs.INode, file
 
 	http.ServeContent(w, r, inode.Name(), inode.LastModifiedAt(), file)
 }
+
+// getSpaceUsage returns the number of bytes used by the given space.
+func getSpaceUsage(ctx context.Context, fs dfs.Service, space *spaces.Space) (uint64, error) {
+	root, err := fs.Get(ctx, dfs.NewPathCmd(space, "/"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the space root: %w", err)
+	}
+
+	return root.Size(), nil
+}
+
+// confirmUnlocked returns a [davlocks.ErrLocked] error if one of the paths, or
+// one of their descendants, is locked by a WebDAV client. The browser never
+// submits a lock token so any lock prevents the modification.
+func confirmUnlocked(ctx context.Context, locks davlocks.Service, user *users.User, paths ...*dfs.PathCmd) error {
+	for _, p := range paths {
+		err := locks.Confirm(ctx, &davlocks.ConfirmCmd{
+			User:   user,
+			Path:   p,
+			Tokens: nil,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to Confirm %q: %w", p.Path(), err)
+		}
+	}
+
+	return nil
+}
+
+// checkPreconditions evaluates the If-Match, If-Unmodified-Since and
+// If-None-Match headers of a write request against the file at cmd, as
+// described by the RFC 7232 section 6. It returns false if the request must
+// fail with a 412 status.
+//
+// The file is only fetched if one of those headers is set.
+func checkPreconditions(ctx context.Context, ffs dfs.Service, header http.Header, cmd *dfs.PathCmd) (bool, error) {
+	ifMatch := header.Get("If-Match")
+	ifUnmodifiedSince := header.Get("If-Unmodified-Since")
+	ifNoneMatch := header.Get("If-None-Match")
+
+	if ifMatch == "" && ifUnmodifiedSince == "" && ifNoneMatch == "" {
+		return true, nil
+	}
+
+	inode, err := ffs.Get(ctx, cmd)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return false, fmt.Errorf("failed to Get %q: %w", cmd.Path(), err)
+	}
+
+	if ifMatch != "" {
+		if inode == nil || !matchETags(ifMatch, inode.ETag(), false) {
+			return false, nil
+		}
+	} else if ifUnmodifiedSince != "" && inode != nil {
+		t, err := http.ParseTime(ifUnmodifiedSince)
+		// The dates with a wrong format must be ignored.
+		if err == nil && inode.LastModifiedAt().Truncate(time.Second).After(t) {
+			return false, nil
+		}
+	}
+
+	if ifNoneMatch != "" && inode != nil && matchETags(ifNoneMatch, inode.ETag(), true) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// matchETags returns true if the etag is part of the comma separated list
+// of entity tags given by hdr. The "*" value matches any existing file. The
+// weak tags only match with the weak comparison.
+func matchETags(hdr string, etag string, weak bool) bool {
+	if strings.TrimSpace(hdr) == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(hdr, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+
+			tag = tag[2:]
+		}
+
+		if tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// serveFolderContent writes a zip archive with all the content of the folder.
+func serveFolderContent(w http.ResponseWriter, r *http.Request, htmlWriter html.Writer, ffs dfs.Service, cmd *dfs.PathCmd) {
+	var err error
+
+	_, dir := path.Split(cmd.Path())
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", dir))
+	w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate")
+
+	writer := zip.NewWriter(w)
+
+	dfs.Walk(r.Context(), ffs, cmd, func(ctx context.Context, p string, i *dfs.INode) error {
+		header := &zip.FileHeader{
+			Method:             zip.Deflate,
+			Comment:            "From DuckCloud with love",
+			Name:               i.Name(),
+			UncompressedSize64: i.Size(),
+			Modified:           i.LastModifiedAt(),
+		}
+
+		if i.IsDir() {
+			header.SetMode(0o755 | fs.ModeDir)
+		} else {
+			header.SetMode(0o644)
+		}
+
+		header.Name, err = filepath.Rel(cmd.Path(), p)
+		if err != nil {
+			return fmt.Errorf("failed to find the relative path: %w", err)
+		}
+
+		// The folder itself is the archive.
+		if header.Name == "." {
+			return nil
+		}
+
+		if i.IsDir() {
+			header.Name += "/"
+		}
+
+		headerWriter, err := writer.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to create the zip header: %w", err)
+		}
+
+		if i.IsDir() {
+			return nil
+		}
+
+		file, err := ffs.Download(ctx, dfs.NewPathCmd(cmd.Space(), p))
+		if err != nil {
+			return fmt.Errorf("failed to download for zip: %w", err)
+		}
+		defer file.Close()
+
+		_, err = io.Copy(headerWriter, file)
+
+		return err
+	})
+
+	err = writer.Close()
+	if err != nil {
+		htmlWriter.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to Close the zip file: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// uploadForm contains the fields sent by the upload widget along with a file.
+type uploadForm struct {
+	file     *multipart.Part
+	name     string
+	spaceID  string
+	rootPath string
+	relPath  string
+
+	// modifiedAt is the modification time of the file on the user's device.
+	// It's zero if the widget didn't send it.
+	modifiedAt time.Time
+}
+
+// readUploadForm reads the multipart form until the file part. The upload
+// widget sends all the other fields before the file so the file content can
+// be streamed. The file is nil if the form doesn't contain any file.
+func readUploadForm(r *http.Request) (*uploadForm, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mutlipart reader: %w", err)
+	}
+
+	var res uploadForm
+
+	for {
+		p, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return &res, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the next part: %w", err)
+		}
+
+		if p.FileName() != "" {
+			res.file = p
+			return &res, nil
+		}
+
+		var field *string
+		switch p.FormName() {
+		case "name":
+			field = &res.name
+		case "rootPath":
+			field = &res.rootPath
+		case "spaceID":
+			field = &res.spaceID
+		case "relativePath":
+			field = &res.relPath
+		case "lastModified":
+			value, err := io.ReadAll(p)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %q: %w", p.FormName(), err)
+			}
+
+			// An invalid value is ignored, the upload time is used instead.
+			ms, err := strconv.ParseInt(string(value), 10, 64)
+			if err == nil && ms > 0 {
+				res.modifiedAt = time.UnixMilli(ms).UTC()
+			}
+			continue
+		default:
+			continue
+		}
+
+		value, err := io.ReadAll(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q: %w", p.FormName(), err)
+		}
+
+		*field = string(value)
+	}
+}
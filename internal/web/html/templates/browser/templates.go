@@ -4,22 +4,31 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 )
 
 type ContentTemplate struct {
 	Folder        *dfs.PathCmd
 	CurrentSpace  *spaces.Space
+	SpaceUsage    uint64
 	ContentTarget string
 	Inodes        []dfs.INode
 	AllSpaces     []spaces.Space
+	Role          spaces.Role
 }
 
 func (t *ContentTemplate) Template() string { return "browser/page" }
 
+func (t *ContentTemplate) UsagePercent() int {
+	return usagePercent(t.SpaceUsage, t.CurrentSpace.Quota())
+}
+
 func (t *ContentTemplate) Breadcrumb() *BreadCrumbTemplate {
 	basePath := path.Join("/browser/", string(t.Folder.Space().ID()))
 
@@ -58,6 +67,7 @@ func (t *ContentTemplate) Rows() *RowsTemplate {
 	return &RowsTemplate{
 		Folder:        t.Folder,
 		Inodes:        t.Inodes,
+		Role:          t.Role,
 		ContentTarget: t.ContentTarget,
 	}
 }
@@ -79,10 +89,45 @@ type RenameTemplate struct {
 
 func (t *RenameTemplate) Template() string { return "browser/modal_rename" }
 
+type VersionsTemplate struct {
+	Target   *dfs.PathCmd
+	INode    *dfs.INode
+	Versions []dfs.FileVersion
+}
+
+func (t *VersionsTemplate) Template() string { return "browser/modal_versions" }
+
+type ShareTemplate struct {
+	Error    *string
+	Target   *dfs.PathCmd
+	INode    *dfs.INode
+	NewShare *shares.Share
+	Shares   []shares.Share
+}
+
+func (t *ShareTemplate) Template() string { return "browser/modal_share" }
+
+func (t *ShareTemplate) ShareURL(share shares.Share) string {
+	return "/s/" + share.Token().Raw()
+}
+
+type GrantsTemplate struct {
+	Error      *string
+	Target     *dfs.PathCmd
+	Grants     []dfs.Grant
+	Users      map[uuid.UUID]users.User
+	Candidates []users.User
+}
+
+func (t *GrantsTemplate) Template() string { return "browser/modal_grants" }
+
+func (t *GrantsTemplate) Permissions() []dfs.Permission { return dfs.Permissions }
+
 type RowsTemplate struct {
 	Folder        *dfs.PathCmd
 	ContentTarget string
 	Inodes        []dfs.INode
+	Role          spaces.Role
 }
 
 func (t *RowsTemplate) Template() string { return "browser/rows" }
@@ -100,25 +145,34 @@ type BreadCrumbElement struct {
 	Href string
 }
 
+// The actions made with the move modal.
+const (
+	MoveAction = "move"
+	CopyAction = "copy"
+)
+
 type MoveTemplate struct {
+	Action        string
 	SrcPath       *dfs.PathCmd
 	SrcInode      *dfs.INode
 	DstPath       *dfs.PathCmd
 	FolderContent map[dfs.PathCmd]dfs.INode
+	Spaces        []spaces.Space
 	PageSize      int
 }
 
 func (t *MoveTemplate) Breadcrumb() *BreadCrumbTemplate {
 	vals := url.Values{
-		"srcPath": []string{t.SrcPath.Path()},
-		"dstPath": []string{"/"},
-		"spaceID": []string{string(t.SrcPath.Space().ID())},
+		"srcPath":    []string{t.SrcPath.Path()},
+		"dstPath":    []string{"/"},
+		"spaceID":    []string{string(t.SrcPath.Space().ID())},
+		"dstSpaceID": []string{string(t.DstPath.Space().ID())},
 	}
 
-	basePath := url.URL{Path: "/browser/move", RawQuery: vals.Encode()}
+	basePath := url.URL{Path: "/browser/" + t.Action, RawQuery: vals.Encode()}
 
 	elements := []BreadCrumbElement{{
-		Name: t.SrcPath.Space().Name(),
+		Name: t.DstPath.Space().Name(),
 		Href: basePath.String(),
 	}}
 
@@ -135,12 +189,13 @@ func (t *MoveTemplate) Breadcrumb() *BreadCrumbTemplate {
 		dstPath = path.Join(dstPath, elem)
 
 		vals := url.Values{
-			"srcPath": []string{t.SrcPath.Path()},
-			"dstPath": []string{dstPath},
-			"spaceID": []string{string(t.SrcPath.Space().ID())},
+			"srcPath":    []string{t.SrcPath.Path()},
+			"dstPath":    []string{dstPath},
+			"spaceID":    []string{string(t.SrcPath.Space().ID())},
+			"dstSpaceID": []string{string(t.DstPath.Space().ID())},
 		}
 
-		basePath := url.URL{Path: "/browser/move", RawQuery: vals.Encode()}
+		basePath := url.URL{Path: "/browser/" + t.Action, RawQuery: vals.Encode()}
 
 		elements = append(elements, BreadCrumbElement{
 			Name: elem,
@@ -159,6 +214,7 @@ func (t *MoveTemplate) Template() string { return "browser/modal_move" }
 
 func (t *MoveTemplate) MoveRows() *MoveRowsTemplate {
 	return &MoveRowsTemplate{
+		Action:        t.Action,
 		SrcPath:       t.SrcPath,
 		DstPath:       t.DstPath,
 		FolderContent: t.FolderContent,
@@ -167,6 +223,7 @@ func (t *MoveTemplate) MoveRows() *MoveRowsTemplate {
 }
 
 type MoveRowsTemplate struct {
+	Action        string
 	SrcPath       *dfs.PathCmd
 	DstPath       *dfs.PathCmd
 	FolderContent map[dfs.PathCmd]dfs.INode
@@ -174,3 +231,228 @@ type MoveRowsTemplate struct {
 }
 
 func (t *MoveRowsTemplate) Template() string { return "browser/modal_move_rows" }
+
+type TrashItem struct {
+	INode        dfs.INode
+	OriginalPath string
+}
+
+type TrashTemplate struct {
+	Folder       *dfs.PathCmd
+	CurrentSpace *spaces.Space
+	SpaceUsage   uint64
+	AllSpaces    []spaces.Space
+	Items        []TrashItem
+}
+
+func (t *TrashTemplate) Template() string { return "browser/trash" }
+
+func (t *TrashTemplate) UsagePercent() int {
+	return usagePercent(t.SpaceUsage, t.CurrentSpace.Quota())
+}
+
+func (t *TrashTemplate) RetentionDays() int {
+	return int(t.CurrentSpace.TrashRetention() / (24 * time.Hour))
+}
+
+func (t *TrashTemplate) Rows() *TrashRowsTemplate {
+	return &TrashRowsTemplate{
+		Space: t.CurrentSpace,
+		Items: t.Items,
+	}
+}
+
+type TrashRowsTemplate struct {
+	Space *spaces.Space
+	Items []TrashItem
+}
+
+func (t *TrashRowsTemplate) Template() string { return "browser/trash_rows" }
+
+type SearchTemplate struct {
+	Folder       *dfs.PathCmd
+	CurrentSpace *spaces.Space
+	SpaceUsage   uint64
+	AllSpaces    []spaces.Space
+	Filters      SearchFilters
+	Results      []dfs.SearchResult
+}
+
+func (t *SearchTemplate) Template() string { return "browser/search" }
+
+func (t *SearchTemplate) UsagePercent() int {
+	return usagePercent(t.SpaceUsage, t.CurrentSpace.Quota())
+}
+
+func (t *SearchTemplate) MimeTypeOptions() []SearchOption {
+	return []SearchOption{
+		{Label: "Any type", Value: ""},
+		{Label: "Images", Value: "image/*"},
+		{Label: "Videos", Value: "video/*"},
+		{Label: "Audio", Value: "audio/*"},
+		{Label: "Text", Value: "text/*"},
+		{Label: "PDF", Value: "application/pdf"},
+	}
+}
+
+func (t *SearchTemplate) Rows() *SearchRowsTemplate {
+	spaceNames := make(map[uuid.UUID]string, len(t.AllSpaces))
+	for _, space := range t.AllSpaces {
+		spaceNames[space.ID()] = space.Name()
+	}
+
+	return &SearchRowsTemplate{
+		CurrentSpace: t.CurrentSpace,
+		Filters:      t.Filters,
+		SpaceNames:   spaceNames,
+		Results:      t.Results,
+	}
+}
+
+// SearchFilters contains the raw values of the search form. They are sent back
+// into the form and into the pagination links.
+type SearchFilters struct {
+	Query    string
+	SpaceID  string
+	MimeType string
+	MinSize  string
+	MaxSize  string
+	After    string
+	Before   string
+	// InContent is not empty to also search inside the documents.
+	InContent string
+}
+
+// URL returns the url of the search page for the given filters. The results
+// start after the given path if last is not empty.
+func (f SearchFilters) URL(currentSpace *spaces.Space, last string) string {
+	vals := url.Values{}
+
+	for key, val := range map[string]string{
+		"q":        f.Query,
+		"space":    f.SpaceID,
+		"mimetype": f.MimeType,
+		"minSize":  f.MinSize,
+		"maxSize":  f.MaxSize,
+		"after":    f.After,
+		"before":   f.Before,
+		"content":  f.InContent,
+		"last":     last,
+	} {
+		if val != "" {
+			vals.Set(key, val)
+		}
+	}
+
+	res := url.URL{Path: "/search/" + string(currentSpace.ID()), RawQuery: vals.Encode()}
+
+	return res.String()
+}
+
+type SearchOption struct {
+	Label string
+	Value string
+}
+
+type SearchRowsTemplate struct {
+	CurrentSpace *spaces.Space
+	Filters      SearchFilters
+	SpaceNames   map[uuid.UUID]string
+	Results      []dfs.SearchResult
+}
+
+func (t *SearchRowsTemplate) Template() string { return "browser/search_rows" }
+
+type SharedItem struct {
+	Grant     dfs.Grant
+	Name      string
+	SpaceName string
+}
+
+// SharedTemplate lists the folders shared with the user.
+//
+// The page is not linked to a space so Folder and CurrentSpace are always nil.
+type SharedTemplate struct {
+	Folder       *dfs.PathCmd
+	CurrentSpace *spaces.Space
+	AllSpaces    []spaces.Space
+	Items        []SharedItem
+}
+
+func (t *SharedTemplate) Template() string { return "browser/page_shared" }
+
+// SharedFolderTemplate displays the content of a folder shared with the user.
+//
+// Path is relative to the shared folder. The layout fields Folder and
+// CurrentSpace are always nil.
+type SharedFolderTemplate struct {
+	Folder       *dfs.PathCmd
+	CurrentSpace *spaces.Space
+	AllSpaces    []spaces.Space
+	Grant        *dfs.Grant
+	Name         string
+	Path         string
+	Inodes       []dfs.INode
+}
+
+func (t *SharedFolderTemplate) Template() string { return "browser/page_shared_folder" }
+
+func (t *SharedFolderTemplate) Breadcrumb() *BreadCrumbTemplate {
+	basePath := path.Join("/browser/shared", string(t.Grant.ID()))
+
+	elements := []BreadCrumbElement{{
+		Name: t.Name,
+		Href: basePath,
+	}}
+
+	fullPath := strings.Trim(t.Path, "/")
+
+	if fullPath == "" {
+		return &BreadCrumbTemplate{
+			Parents:    []BreadCrumbElement{},
+			CurrentDir: elements[0],
+			Target:     "body",
+		}
+	}
+
+	for _, elem := range strings.Split(fullPath, "/") {
+		basePath = path.Join(basePath, elem)
+
+		elements = append(elements, BreadCrumbElement{
+			Name: elem,
+			Href: basePath,
+		})
+	}
+
+	return &BreadCrumbTemplate{
+		Parents:    elements[:len(elements)-1],
+		CurrentDir: elements[len(elements)-1],
+		Target:     "body",
+	}
+}
+
+func (t *SharedFolderTemplate) Rows() *SharedRowsTemplate {
+	return &SharedRowsTemplate{
+		Grant:  t.Grant,
+		Path:   t.Path,
+		Inodes: t.Inodes,
+	}
+}
+
+type SharedRowsTemplate struct {
+	Grant  *dfs.Grant
+	Path   string
+	Inodes []dfs.INode
+}
+
+func (t *SharedRowsTemplate) Template() string { return "browser/shared_rows" }
+
+// usagePercent returns the share of the quota used, capped at 100. A zero
+// quota means no limit and always returns 0.
+func usagePercent(usage, quota uint64) int {
+	if quota == 0 {
+		return 0
+	}
+
+	return int(min(100, usage*100/quota))
+}
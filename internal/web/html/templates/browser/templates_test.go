@@ -9,7 +9,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/service/shares"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 	"github.com/theduckcompany/duckcloud/internal/web/html"
@@ -54,12 +56,33 @@ func Test_Templates(t *testing.T) {
 				FieldValueSelection: 0,
 			},
 		},
+		{
+			Name:   "modal_versions",
+			Layout: false,
+			Template: &VersionsTemplate{
+				Target:   dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/bar"),
+				INode:    &dfs.ExampleAliceFile,
+				Versions: []dfs.FileVersion{dfs.ExampleAliceFileVersion},
+			},
+		},
+		{
+			Name:   "ShareTemplate",
+			Layout: false,
+			Template: &ShareTemplate{
+				Error:    ptr.To("some-error"),
+				Target:   dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a"),
+				INode:    &dfs.ExampleAliceDir,
+				NewShare: &shares.ExampleAliceProtectedDirShare,
+				Shares:   []shares.Share{shares.ExampleAliceProtectedDirShare, shares.ExampleAliceFileShare, shares.ExampleAliceUploadShare},
+			},
+		},
 		{
 			Name:   "rows",
 			Layout: false,
 			Template: &RowsTemplate{
 				Folder:        dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
 				Inodes:        []dfs.INode{dfs.ExampleAliceFile, dfs.ExampleAliceFile2},
+				Role:          spaces.RoleEditor,
 				ContentTarget: "body",
 			},
 		},
@@ -80,12 +103,36 @@ func Test_Templates(t *testing.T) {
 				Inodes:       []dfs.INode{dfs.ExampleAliceFile, dfs.ExampleAliceFile2},
 				CurrentSpace: &spaces.ExampleAlicePersonalSpace,
 				AllSpaces:    []spaces.Space{spaces.ExampleAlicePersonalSpace, spaces.ExampleAliceBobSharedSpace},
+				Role:         spaces.RoleManager,
+			},
+		},
+		{
+			Name:   "content with a viewer role",
+			Layout: true,
+			Template: &ContentTemplate{
+				Folder:       dfs.NewPathCmd(&spaces.ExampleAliceBobSharedSpace, "/foo"),
+				Inodes:       []dfs.INode{dfs.ExampleAliceFile, dfs.ExampleAliceFile2},
+				CurrentSpace: &spaces.ExampleAliceBobSharedSpace,
+				AllSpaces:    []spaces.Space{spaces.ExampleAlicePersonalSpace, spaces.ExampleAliceBobSharedSpace},
+				Role:         spaces.RoleViewer,
+			},
+		},
+		{
+			Name:   "content with a quota",
+			Layout: true,
+			Template: &ContentTemplate{
+				Folder:       dfs.NewPathCmd(spaces.NewFakeSpace(t).WithQuota(1000).Build(), "/"),
+				Inodes:       []dfs.INode{},
+				CurrentSpace: spaces.NewFakeSpace(t).WithQuota(1000).Build(),
+				SpaceUsage:   950,
+				AllSpaces:    []spaces.Space{spaces.ExampleAlicePersonalSpace},
 			},
 		},
 		{
 			Name:   "move modal",
 			Layout: false,
 			Template: &MoveTemplate{
+				Action:   MoveAction,
 				SrcPath:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
 				SrcInode: &dfs.ExampleAliceDir,
 				DstPath:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar"),
@@ -96,10 +143,40 @@ func Test_Templates(t *testing.T) {
 				PageSize: 10,
 			},
 		},
+		{
+			Name:   "move modal to an other space",
+			Layout: false,
+			Template: &MoveTemplate{
+				Action:   MoveAction,
+				SrcPath:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+				SrcInode: &dfs.ExampleAliceDir,
+				DstPath:  dfs.NewPathCmd(&spaces.ExampleAliceBobSharedSpace, "/bar"),
+				FolderContent: map[dfs.PathCmd]dfs.INode{
+					*dfs.NewPathCmd(&spaces.ExampleAliceBobSharedSpace, "/bar/file1.jpg"): dfs.ExampleAliceFile,
+				},
+				Spaces:   []spaces.Space{spaces.ExampleAlicePersonalSpace, spaces.ExampleAliceBobSharedSpace},
+				PageSize: 10,
+			},
+		},
+		{
+			Name:   "copy modal",
+			Layout: false,
+			Template: &MoveTemplate{
+				Action:   CopyAction,
+				SrcPath:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
+				SrcInode: &dfs.ExampleAliceDir,
+				DstPath:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar"),
+				FolderContent: map[dfs.PathCmd]dfs.INode{
+					*dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo/file1.jpg"): dfs.ExampleAliceFile,
+				},
+				PageSize: 10,
+			},
+		},
 		{
 			Name:   "move rows",
 			Layout: false,
 			Template: &MoveRowsTemplate{
+				Action:  MoveAction,
 				SrcPath: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
 				DstPath: dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/bar"),
 				FolderContent: map[dfs.PathCmd]dfs.INode{
@@ -109,6 +186,125 @@ func Test_Templates(t *testing.T) {
 				PageSize: 10,
 			},
 		},
+		{
+			Name:   "trash",
+			Layout: true,
+			Template: &TrashTemplate{
+				Folder:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+				CurrentSpace: &spaces.ExampleAlicePersonalSpace,
+				AllSpaces:    []spaces.Space{spaces.ExampleAlicePersonalSpace, spaces.ExampleAliceBobSharedSpace},
+				Items: []TrashItem{
+					{INode: dfs.ExampleAliceFile, OriginalPath: "/"},
+					{INode: dfs.ExampleAliceDir, OriginalPath: "/foo"},
+				},
+			},
+		},
+		{
+			Name:   "trash rows",
+			Layout: false,
+			Template: &TrashRowsTemplate{
+				Space: &spaces.ExampleAlicePersonalSpace,
+				Items: []TrashItem{
+					{INode: dfs.ExampleAliceFile, OriginalPath: "/"},
+				},
+			},
+		},
+		{
+			Name:   "search",
+			Layout: true,
+			Template: &SearchTemplate{
+				Folder:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+				CurrentSpace: &spaces.ExampleAlicePersonalSpace,
+				AllSpaces:    []spaces.Space{spaces.ExampleAlicePersonalSpace, spaces.ExampleAliceBobSharedSpace},
+				Filters: SearchFilters{
+					Query:     "foo",
+					SpaceID:   string(spaces.ExampleAlicePersonalSpace.ID()),
+					MimeType:  "image/*",
+					MinSize:   "1",
+					After:     "2024-01-02",
+					InContent: "on",
+				},
+				Results: []dfs.SearchResult{dfs.ExampleAliceDirSearchResult, dfs.ExampleAliceFileSearchResult},
+			},
+		},
+		{
+			Name:   "search without results",
+			Layout: true,
+			Template: &SearchTemplate{
+				Folder:       dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/"),
+				CurrentSpace: &spaces.ExampleAlicePersonalSpace,
+				AllSpaces:    []spaces.Space{spaces.ExampleAlicePersonalSpace},
+				Filters:      SearchFilters{Query: "foo"},
+				Results:      []dfs.SearchResult{},
+			},
+		},
+		{
+			Name:   "search rows",
+			Layout: false,
+			Template: &SearchRowsTemplate{
+				CurrentSpace: &spaces.ExampleAlicePersonalSpace,
+				Filters:      SearchFilters{Query: "foo"},
+				SpaceNames:   map[uuid.UUID]string{spaces.ExampleAlicePersonalSpace.ID(): spaces.ExampleAlicePersonalSpace.Name()},
+				Results:      []dfs.SearchResult{dfs.ExampleAliceFileSearchResult},
+			},
+		},
+		{
+			Name:   "modal_grants",
+			Layout: false,
+			Template: &GrantsTemplate{
+				Error:      ptr.To("some-error"),
+				Target:     dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/dir-a"),
+				Grants:     []dfs.Grant{dfs.ExampleBobReadGrant},
+				Users:      map[uuid.UUID]users.User{users.ExampleBob.ID(): users.ExampleBob},
+				Candidates: []users.User{users.ExampleAlice},
+			},
+		},
+		{
+			Name:   "shared",
+			Layout: true,
+			Template: &SharedTemplate{
+				Folder:       nil,
+				CurrentSpace: nil,
+				AllSpaces:    []spaces.Space{spaces.ExampleBobPersonalSpace},
+				Items: []SharedItem{{
+					Grant:     dfs.ExampleBobReadGrant,
+					Name:      "dir-a",
+					SpaceName: spaces.ExampleAlicePersonalSpace.Name(),
+				}},
+			},
+		},
+		{
+			Name:   "shared without items",
+			Layout: true,
+			Template: &SharedTemplate{
+				Folder:       nil,
+				CurrentSpace: nil,
+				AllSpaces:    []spaces.Space{spaces.ExampleBobPersonalSpace},
+				Items:        []SharedItem{},
+			},
+		},
+		{
+			Name:   "shared folder",
+			Layout: true,
+			Template: &SharedFolderTemplate{
+				Folder:       nil,
+				CurrentSpace: nil,
+				AllSpaces:    []spaces.Space{spaces.ExampleBobPersonalSpace},
+				Grant:        &dfs.ExampleBobReadGrant,
+				Name:         "dir-a",
+				Path:         "/foo",
+				Inodes:       []dfs.INode{dfs.ExampleAliceFile, dfs.ExampleAliceDir},
+			},
+		},
+		{
+			Name:   "shared rows",
+			Layout: false,
+			Template: &SharedRowsTemplate{
+				Grant:  &dfs.ExampleBobReadGrant,
+				Path:   "/",
+				Inodes: []dfs.INode{dfs.ExampleAliceFile},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -146,12 +342,12 @@ func TestMoveTemplateBreadcrumb(t *testing.T) {
 			ExpectedParents: []BreadCrumbElement{
 				{
 					Name: spaces.ExampleAlicePersonalSpace.Name(),
-					Href: "/browser/move?dstPath=%2F&spaceID=e97b60f7-add2-43e1-a9bd-e2dac9ce69ec&srcPath=%2Ffoo",
+					Href: "/browser/move?dstPath=%2F&dstSpaceID=e97b60f7-add2-43e1-a9bd-e2dac9ce69ec&spaceID=e97b60f7-add2-43e1-a9bd-e2dac9ce69ec&srcPath=%2Ffoo",
 				},
 			},
 			ExpectedCurrent: BreadCrumbElement{
 				Name: "bar",
-				Href: "/browser/move?dstPath=%2Fbar&spaceID=e97b60f7-add2-43e1-a9bd-e2dac9ce69ec&srcPath=%2Ffoo",
+				Href: "/browser/move?dstPath=%2Fbar&dstSpaceID=e97b60f7-add2-43e1-a9bd-e2dac9ce69ec&spaceID=e97b60f7-add2-43e1-a9bd-e2dac9ce69ec&srcPath=%2Ffoo",
 			},
 		},
 		{
@@ -160,7 +356,21 @@ func TestMoveTemplateBreadcrumb(t *testing.T) {
 			ExpectedParents: []BreadCrumbElement{},
 			ExpectedCurrent: BreadCrumbElement{
 				Name: spaces.ExampleAlicePersonalSpace.Name(),
-				Href: "/browser/move?dstPath=%2F&spaceID=e97b60f7-add2-43e1-a9bd-e2dac9ce69ec&srcPath=%2Ffoo",
+				Href: "/browser/move?dstPath=%2F&dstSpaceID=e97b60f7-add2-43e1-a9bd-e2dac9ce69ec&spaceID=e97b60f7-add2-43e1-a9bd-e2dac9ce69ec&srcPath=%2Ffoo",
+			},
+		},
+		{
+			Name: "Other space",
+			Path: dfs.NewPathCmd(&spaces.ExampleAliceBobSharedSpace, "/bar"),
+			ExpectedParents: []BreadCrumbElement{
+				{
+					Name: spaces.ExampleAliceBobSharedSpace.Name(),
+					Href: "/browser/move?dstPath=%2F&dstSpaceID=c8943050-6bc5-4641-a4ba-672c1f03b4cd&spaceID=e97b60f7-add2-43e1-a9bd-e2dac9ce69ec&srcPath=%2Ffoo",
+				},
+			},
+			ExpectedCurrent: BreadCrumbElement{
+				Name: "bar",
+				Href: "/browser/move?dstPath=%2Fbar&dstSpaceID=c8943050-6bc5-4641-a4ba-672c1f03b4cd&spaceID=e97b60f7-add2-43e1-a9bd-e2dac9ce69ec&srcPath=%2Ffoo",
 			},
 		},
 	}
@@ -168,6 +378,7 @@ func TestMoveTemplateBreadcrumb(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.Name, func(t *testing.T) {
 			moveTemplate := MoveTemplate{
+				Action:   MoveAction,
 				SrcPath:  dfs.NewPathCmd(&spaces.ExampleAlicePersonalSpace, "/foo"),
 				SrcInode: &dfs.ExampleAliceDir,
 				DstPath:  test.Path, // The breadcrumb is created based on the destination path.
@@ -185,3 +396,16 @@ func TestMoveTemplateBreadcrumb(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchFiltersURL(t *testing.T) {
+	filters := SearchFilters{
+		Query:     "foo bar",
+		MimeType:  "image/*",
+		After:     "2024-01-02",
+		InContent: "on",
+	}
+
+	assert.Equal(t,
+		"/search/"+string(spaces.ExampleAlicePersonalSpace.ID())+"?after=2024-01-02&content=on&last=%2Ffoo%2Fbar.jpg&mimetype=image%2F%2A&q=foo+bar",
+		filters.URL(&spaces.ExampleAlicePersonalSpace, "/foo/bar.jpg"))
+}
@@ -13,10 +13,22 @@ type ContentTemplate struct {
 	WebSessions    []websessions.Session
 	Devices        []davsessions.DavSession
 	Spaces         map[uuid.UUID]spaces.Space
+	Usage          uint64
+	Quota          uint64
 }
 
 func (t *ContentTemplate) Template() string { return "settings/security/page" }
 
+// UsagePercent returns the percentage of the user quota used. It returns 0
+// for a user without quota.
+func (t *ContentTemplate) UsagePercent() int {
+	if t.Quota == 0 {
+		return 0
+	}
+
+	return int(min(100, t.Usage*100/t.Quota))
+}
+
 type PasswordFormTemplate struct {
 	Error string
 }
@@ -24,8 +36,16 @@ type PasswordFormTemplate struct {
 func (t *PasswordFormTemplate) Template() string { return "settings/security/password-form" }
 
 type WebdavFormTemplate struct {
-	Error  error
-	Spaces []spaces.Space
+	Error         error
+	Spaces        []spaces.Space
+	SharedFolders []SharedFolder
+}
+
+// SharedFolder is a folder shared with the user without being a member of its
+// space.
+type SharedFolder struct {
+	GrantID uuid.UUID
+	Name    string
 }
 
 func (t *WebdavFormTemplate) Template() string { return "settings/security/webdav-form" }
@@ -33,10 +33,12 @@ func Test_Templates(t *testing.T) {
 				IsAdmin:        false,
 				CurrentSession: &websessions.AliceWebSessionExample,
 				WebSessions:    []websessions.Session{websessions.AliceWebSessionExample},
-				Devices:        []davsessions.DavSession{davsessions.ExampleAliceSession},
+				Devices:        []davsessions.DavSession{davsessions.ExampleAliceSession, davsessions.ExampleAliceSession2},
 				Spaces: map[uuid.UUID]spaces.Space{
 					spaces.ExampleAlicePersonalSpace.ID(): spaces.ExampleAlicePersonalSpace,
 				},
+				Usage: 950,
+				Quota: 1000,
 			},
 		},
 		{
@@ -54,6 +56,17 @@ func Test_Templates(t *testing.T) {
 				Spaces: []spaces.Space{spaces.ExampleBobPersonalSpace},
 			},
 		},
+		{
+			Name:   "WebdavFormTemplate with shared folders",
+			Layout: false,
+			Template: &WebdavFormTemplate{
+				Error:  nil,
+				Spaces: []spaces.Space{spaces.ExampleBobPersonalSpace},
+				SharedFolders: []SharedFolder{
+					{GrantID: uuid.UUID("6f7e2c2c-d4a7-4f53-8e5c-7e6fcda3a0d1"), Name: "dir-a"},
+				},
+			},
+		},
 		{
 			Name:   "WebdavResultTemplate",
 			Layout: false,
@@ -1,6 +1,8 @@
 package spaces
 
 import (
+	"time"
+
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
@@ -9,11 +11,87 @@ import (
 type ContentTemplate struct {
 	IsAdmin bool
 	Spaces  []spaces.Space
-	Users   map[uuid.UUID]users.User
+	Usages  map[uuid.UUID]uint64
 }
 
 func (t *ContentTemplate) Template() string { return "settings/spaces/page" }
 
+func (t *ContentTemplate) TrashRetentionOptions() []TrashRetentionOption {
+	return []TrashRetentionOption{
+		{Label: "Disabled", Days: 0},
+		{Label: "7 days", Days: 7},
+		{Label: "30 days", Days: 30},
+		{Label: "90 days", Days: 90},
+		{Label: "1 year", Days: 365},
+	}
+}
+
+type TrashRetentionOption struct {
+	Label string
+	Days  int
+}
+
+func (o TrashRetentionOption) Duration() time.Duration {
+	return time.Duration(o.Days) * 24 * time.Hour
+}
+
+func (t *ContentTemplate) MaxVersionsOptions() []MaxVersionsOption {
+	return []MaxVersionsOption{
+		{Label: "Unlimited", Count: 0},
+		{Label: "1 version", Count: 1},
+		{Label: "5 versions", Count: 5},
+		{Label: "10 versions", Count: 10},
+		{Label: "50 versions", Count: 50},
+	}
+}
+
+type MaxVersionsOption struct {
+	Label string
+	Count int
+}
+
+func (t *ContentTemplate) VersionsRetentionOptions() []TrashRetentionOption {
+	return []TrashRetentionOption{
+		{Label: "Forever", Days: 0},
+		{Label: "7 days", Days: 7},
+		{Label: "30 days", Days: 30},
+		{Label: "90 days", Days: 90},
+		{Label: "1 year", Days: 365},
+	}
+}
+
+func (t *ContentTemplate) QuotaOptions() []QuotaOption {
+	return []QuotaOption{
+		{Label: "Unlimited", GB: 0},
+		{Label: "1 GB", GB: 1},
+		{Label: "5 GB", GB: 5},
+		{Label: "10 GB", GB: 10},
+		{Label: "50 GB", GB: 50},
+		{Label: "100 GB", GB: 100},
+		{Label: "500 GB", GB: 500},
+		{Label: "1 TB", GB: 1000},
+	}
+}
+
+// UsagePercent returns the percentage of the quota used by the space. It
+// returns 0 for a space without quota.
+func (t *ContentTemplate) UsagePercent(space spaces.Space) int {
+	if space.Quota() == 0 {
+		return 0
+	}
+
+	return int(min(100, t.Usages[space.ID()]*100/space.Quota()))
+}
+
+type QuotaOption struct {
+	Label string
+	GB    uint64
+}
+
+func (o QuotaOption) Bytes() uint64 {
+	return o.GB * 1000 * 1000 * 1000
+}
+
 type CreateSpaceModal struct {
 	IsAdmin   bool
 	Selection UserSelectionTemplate
@@ -27,3 +105,16 @@ type UserSelectionTemplate struct {
 }
 
 func (t *UserSelectionTemplate) Template() string { return "settings/spaces/user_selection" }
+
+type MembersModal struct {
+	Error   *string
+	Space   *spaces.Space
+	Members []spaces.Member
+	Users   map[uuid.UUID]users.User
+	// Candidates are the users which can be added to the space.
+	Candidates []users.User
+}
+
+func (t *MembersModal) Template() string { return "settings/spaces/modal_members" }
+
+func (t *MembersModal) Roles() []spaces.Role { return spaces.Roles }
@@ -8,6 +8,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/spaces"
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 	"github.com/theduckcompany/duckcloud/internal/web/html"
 )
 
@@ -29,6 +33,31 @@ func Test_Templates(t *testing.T) {
 				IsAdmin: true,
 			},
 		},
+		{
+			Name:   "ContentTemplate with quota",
+			Layout: true,
+			Template: &ContentTemplate{
+				IsAdmin: true,
+				Spaces:  []spaces.Space{*spaces.NewFakeSpace(t).WithQuota(1000).Build()},
+				Usages:  map[uuid.UUID]uint64{},
+			},
+		},
+		{
+			Name:   "MembersModal",
+			Layout: false,
+			Template: &MembersModal{
+				Error: ptr.To("some-error"),
+				Space: &spaces.ExampleAliceBobSharedSpace,
+				Members: []spaces.Member{
+					spaces.ExampleAliceManager,
+					spaces.ExampleBobSharedSpaceViewer,
+				},
+				Users: map[uuid.UUID]users.User{
+					users.ExampleAlice.ID(): users.ExampleAlice,
+				},
+				Candidates: []users.User{users.ExampleBob},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -1,16 +1,52 @@
 package users
 
-import "github.com/theduckcompany/duckcloud/internal/service/users"
+import (
+	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
+)
 
 type ContentTemplate struct {
 	Error   error
 	Current *users.User
 	Users   []users.User
+	Usages  map[uuid.UUID]uint64
 	IsAdmin bool
 }
 
 func (t *ContentTemplate) Template() string { return "settings/users/page" }
 
+func (t *ContentTemplate) QuotaOptions() []QuotaOption {
+	return []QuotaOption{
+		{Label: "Unlimited", GB: 0},
+		{Label: "1 GB", GB: 1},
+		{Label: "5 GB", GB: 5},
+		{Label: "10 GB", GB: 10},
+		{Label: "50 GB", GB: 50},
+		{Label: "100 GB", GB: 100},
+		{Label: "500 GB", GB: 500},
+		{Label: "1 TB", GB: 1000},
+	}
+}
+
+// UsagePercent returns the percentage of the quota used by the user. It
+// returns 0 for a user without quota.
+func (t *ContentTemplate) UsagePercent(user users.User) int {
+	if user.Quota() == 0 {
+		return 0
+	}
+
+	return int(min(100, t.Usages[user.ID()]*100/user.Quota()))
+}
+
+type QuotaOption struct {
+	Label string
+	GB    uint64
+}
+
+func (o QuotaOption) Bytes() uint64 {
+	return o.GB * 1000 * 1000 * 1000
+}
+
 type RegistrationFormTemplate struct {
 	Error error
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
+	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 	"github.com/theduckcompany/duckcloud/internal/web/html"
 )
 
@@ -34,6 +35,17 @@ func Test_Templates(t *testing.T) {
 				Error:   nil,
 			},
 		},
+		{
+			Name:   "ContentTemplate with quotas",
+			Layout: true,
+			Template: &ContentTemplate{
+				IsAdmin: true,
+				Current: &users.ExampleAlice,
+				Users:   []users.User{*users.NewFakeUser(t).WithQuota(1000).Build()},
+				Usages:  map[uuid.UUID]uint64{},
+				Error:   nil,
+			},
+		},
 		{
 			Name:   "RegistrationFormTemplate",
 			Layout: false,
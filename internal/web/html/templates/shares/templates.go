@@ -0,0 +1,89 @@
+package shares
+
+import (
+	"path"
+	"time"
+
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+)
+
+type PasswordPageTemplate struct {
+	Token string
+	Error string
+}
+
+func (t *PasswordPageTemplate) Template() string { return "shares/page_password" }
+
+type UnavailablePageTemplate struct{}
+
+func (t *UnavailablePageTemplate) Template() string { return "shares/page_unavailable" }
+
+// UploadPageTemplate lets an anonymous visitor upload files into a folder
+// without displaying its content.
+type UploadPageTemplate struct {
+	ExpiresAt *time.Time
+	Token     string
+	// Name is the name of the shared folder.
+	Name string
+	// RemainingUploads is the number of files still accepted or 0 if there
+	// is no limit.
+	RemainingUploads int
+	// RemainingSize is the number of bytes still accepted or 0 if there is
+	// no limit.
+	RemainingSize uint64
+}
+
+func (t *UploadPageTemplate) Template() string { return "shares/page_upload" }
+
+func (t *UploadPageTemplate) UploadURL() string {
+	return path.Join("/s", t.Token, "upload")
+}
+
+type FolderPageTemplate struct {
+	Token string
+	// Name is the name of the shared folder.
+	Name string
+	// Path is the path of the displayed folder, relative to the shared folder.
+	Path   string
+	Inodes []dfs.INode
+}
+
+func (t *FolderPageTemplate) Template() string { return "shares/page_folder" }
+
+func (t *FolderPageTemplate) Title() string {
+	if t.Path == "/" {
+		return t.Name
+	}
+
+	return path.Base(t.Path)
+}
+
+// ParentURL returns the url of the parent folder or an empty string for the
+// shared folder.
+func (t *FolderPageTemplate) ParentURL() string {
+	if t.Path == "/" {
+		return ""
+	}
+
+	return path.Join("/s", t.Token, path.Dir(t.Path))
+}
+
+func (t *FolderPageTemplate) DownloadURL() string {
+	return path.Join("/s", t.Token, t.Path) + "?download=zip"
+}
+
+func (t *FolderPageTemplate) Rows() *FolderRowsTemplate {
+	return &FolderRowsTemplate{
+		Token:  t.Token,
+		Path:   t.Path,
+		Inodes: t.Inodes,
+	}
+}
+
+type FolderRowsTemplate struct {
+	Token  string
+	Path   string
+	Inodes []dfs.INode
+}
+
+func (t *FolderRowsTemplate) Template() string { return "shares/rows" }
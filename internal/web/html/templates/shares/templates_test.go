@@ -0,0 +1,111 @@
+package shares
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
+	"github.com/theduckcompany/duckcloud/internal/web/html"
+)
+
+func Test_Templates(t *testing.T) {
+	renderer := html.NewRenderer(html.Config{
+		PrettyRender: false,
+		HotReload:    false,
+	})
+
+	tests := []struct {
+		Template html.Templater
+		Name     string
+		Layout   bool
+	}{
+		{
+			Name:   "PasswordPageTemplate",
+			Layout: true,
+			Template: &PasswordPageTemplate{
+				Token: "some-token",
+				Error: "some-error",
+			},
+		},
+		{
+			Name:     "UnavailablePageTemplate",
+			Layout:   true,
+			Template: &UnavailablePageTemplate{},
+		},
+		{
+			Name:   "UploadPageTemplate",
+			Layout: true,
+			Template: &UploadPageTemplate{
+				ExpiresAt:        ptr.To(time.Now()),
+				Token:            "some-token",
+				Name:             "dir-a",
+				RemainingUploads: 3,
+				RemainingSize:    1024,
+			},
+		},
+		{
+			Name:   "FolderPageTemplate",
+			Layout: true,
+			Template: &FolderPageTemplate{
+				Token:  "some-token",
+				Name:   "dir-a",
+				Path:   "/foo",
+				Inodes: []dfs.INode{dfs.ExampleAliceFile, dfs.ExampleAliceDir},
+			},
+		},
+		{
+			Name:   "FolderRowsTemplate",
+			Layout: false,
+			Template: &FolderRowsTemplate{
+				Token:  "some-token",
+				Path:   "/",
+				Inodes: []dfs.INode{dfs.ExampleAliceFile, dfs.ExampleAliceDir},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/foo", nil)
+
+			if !test.Layout {
+				r.Header.Add("HX-Boosted", "true")
+			}
+
+			renderer.WriteHTMLTemplate(w, r, http.StatusOK, test.Template)
+
+			if !assert.Equal(t, http.StatusOK, w.Code) {
+				res := w.Result()
+				res.Body.Close()
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				t.Log(string(body))
+			}
+		})
+	}
+}
+
+func TestFolderPageTemplate(t *testing.T) {
+	t.Run("at the root of the share", func(t *testing.T) {
+		tmpl := FolderPageTemplate{Token: "some-token", Name: "dir-a", Path: "/"}
+
+		assert.Equal(t, "dir-a", tmpl.Title())
+		assert.Empty(t, tmpl.ParentURL())
+		assert.Equal(t, "/s/some-token?download=zip", tmpl.DownloadURL())
+	})
+
+	t.Run("inside a sub folder", func(t *testing.T) {
+		tmpl := FolderPageTemplate{Token: "some-token", Name: "dir-a", Path: "/foo/bar"}
+
+		assert.Equal(t, "bar", tmpl.Title())
+		assert.Equal(t, "/s/some-token/foo", tmpl.ParentURL())
+		assert.Equal(t, "/s/some-token/foo/bar?download=zip", tmpl.DownloadURL())
+	})
+}
@@ -4,14 +4,19 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/service/websessions"
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
 	"github.com/theduckcompany/duckcloud/internal/tools/router"
 	"github.com/theduckcompany/duckcloud/internal/tools/secret"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
@@ -21,6 +26,8 @@ import (
 	"github.com/theduckcompany/duckcloud/internal/web/html/templates/settings/security"
 )
 
+const davSessionDateFormat = "2006-01-02"
+
 type securityCmd struct {
 	User    *users.User
 	Session *websessions.Session
@@ -39,6 +46,7 @@ type SecurityPage struct {
 	spaces      spaces.Service
 	uuid        uuid.Service
 	users       users.Service
+	fs          dfs.Service
 }
 
 func NewSecurityPage(
@@ -49,6 +57,7 @@ func NewSecurityPage(
 	spaces spaces.Service,
 	users users.Service,
 	authent *auth.Authenticator,
+	fs dfs.Service,
 ) *SecurityPage {
 	return &SecurityPage{
 		auth:        authent,
@@ -58,6 +67,7 @@ func NewSecurityPage(
 		spaces:      spaces,
 		uuid:        tools.UUID(),
 		users:       users,
+		fs:          fs,
 	}
 }
 
@@ -102,19 +112,51 @@ func (h *SecurityPage) createDavSession(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	spaceID, err := h.uuid.Parse(r.FormValue("space"))
-	if err != nil {
-		h.renderWebDAVForm(w, r, &webdavFormCmd{User: user, Error: errors.New("invalid space id")})
-		return
-	}
-
-	newSession, secret, err := h.davSessions.Create(r.Context(), &davsessions.CreateCmd{
+	cmd := davsessions.CreateCmd{
 		UserID:   user.ID(),
 		Name:     r.FormValue("name"),
 		Username: user.Username(),
-		SpaceID:  spaceID,
-	})
-	if errors.Is(err, errs.ErrValidation) {
+		ReadOnly: r.FormValue("readOnly") == "on",
+		RootPath: r.FormValue("rootPath"),
+	}
+
+	if rawDate := r.FormValue("expiresAt"); rawDate != "" {
+		date, err := time.Parse(davSessionDateFormat, rawDate)
+		if err != nil {
+			h.renderWebDAVForm(w, r, &webdavFormCmd{User: user, Error: errors.New("invalid expiration date")})
+			return
+		}
+
+		// The session stays valid the whole day.
+		cmd.ExpiresAt = ptr.To(date.Add(24 * time.Hour))
+	}
+
+	// The select mixes the spaces and the folders shared with the user, those last
+	// ones are prefixed by "grant/". The "all" value gives an access to all the
+	// spaces.
+	target := r.FormValue("space")
+	if target == "all" {
+		cmd.AllSpaces = true
+	} else if grantID, ok := strings.CutPrefix(target, "grant/"); ok {
+		id, err := h.uuid.Parse(grantID)
+		if err != nil {
+			h.renderWebDAVForm(w, r, &webdavFormCmd{User: user, Error: errors.New("invalid shared folder id")})
+			return
+		}
+
+		cmd.GrantID = &id
+	} else {
+		spaceID, err := h.uuid.Parse(target)
+		if err != nil {
+			h.renderWebDAVForm(w, r, &webdavFormCmd{User: user, Error: errors.New("invalid space id")})
+			return
+		}
+
+		cmd.SpaceID = spaceID
+	}
+
+	newSession, secret, err := h.davSessions.Create(r.Context(), &cmd)
+	if errors.Is(err, errs.ErrValidation) || errors.Is(err, errs.ErrBadRequest) {
 		h.renderWebDAVForm(w, r, &webdavFormCmd{User: user, Error: err})
 		return
 	}
@@ -273,8 +315,16 @@ func (h *SecurityPage) renderSecurityPage(w http.ResponseWriter, r *http.Request
 		spacesMap[space.ID()] = space
 	}
 
+	usage, err := h.fs.GetUserUsage(ctx, cmd.User)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to fs.GetUserUsage: %w", err))
+		return
+	}
+
 	h.html.WriteHTMLTemplate(w, r, http.StatusOK, &security.ContentTemplate{
 		IsAdmin:        cmd.User.IsAdmin(),
+		Usage:          usage,
+		Quota:          cmd.User.Quota(),
 		CurrentSession: cmd.Session,
 		WebSessions:    webSessions,
 		Devices:        davSessions,
@@ -283,19 +333,47 @@ func (h *SecurityPage) renderSecurityPage(w http.ResponseWriter, r *http.Request
 }
 
 func (h *SecurityPage) renderWebDAVForm(w http.ResponseWriter, r *http.Request, cmd *webdavFormCmd) {
-	spaces, err := h.spaces.GetAllUserSpaces(r.Context(), cmd.User.ID(), nil)
+	ctx := r.Context()
+
+	spaces, err := h.spaces.GetAllUserSpaces(ctx, cmd.User.ID(), nil)
 	if err != nil {
 		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetAllUserSpaces: %w", err))
 		return
 	}
 
+	grants, err := h.fs.GetUserGrants(ctx, cmd.User)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetUserGrants: %w", err))
+		return
+	}
+
+	sharedFolders := make([]security.SharedFolder, 0, len(grants))
+	for _, grant := range grants {
+		root, err := h.fs.GetGrantPath(ctx, &grant)
+		if errors.Is(err, errs.ErrNotFound) {
+			// The shared folder is inside the trash.
+			continue
+		}
+
+		if err != nil {
+			h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetGrantPath: %w", err))
+			return
+		}
+
+		sharedFolders = append(sharedFolders, security.SharedFolder{
+			GrantID: grant.ID(),
+			Name:    path.Base(root.Path()),
+		})
+	}
+
 	status := http.StatusOK
 	if cmd.Error != nil {
 		status = http.StatusUnprocessableEntity
 	}
 
 	h.html.WriteHTMLTemplate(w, r, status, &security.WebdavFormTemplate{
-		Error:  cmd.Error,
-		Spaces: spaces,
+		Error:         cmd.Error,
+		Spaces:        spaces,
+		SharedFolders: sharedFolders,
 	})
 }
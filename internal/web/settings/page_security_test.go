@@ -8,16 +8,19 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/theduckcompany/duckcloud/internal/service/davsessions"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/service/websessions"
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
 	"github.com/theduckcompany/duckcloud/internal/tools/secret"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
@@ -39,7 +42,8 @@ func Test_SecurityPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
 
 		// Data
 		user := users.NewFakeUser(t).Build()
@@ -55,12 +59,14 @@ func Test_SecurityPage(t *testing.T) {
 		davSessionsMock.On("GetAllForUser", mock.Anything, user.ID(), &sqlstorage.PaginateCmd{Limit: 20}).Return([]davsessions.DavSession{*davSession}, nil).Once()
 		spacesMock.On("GetAllUserSpaces", mock.Anything, user.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]spaces.Space{*space}, nil).Once()
 
+		fsMock.On("GetUserUsage", mock.Anything, user).Return(uint64(42), nil).Once()
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &security.ContentTemplate{
 			IsAdmin:        user.IsAdmin(),
 			CurrentSession: webSession,
 			WebSessions:    []websessions.Session{*webSession},
 			Devices:        []davsessions.DavSession{*davSession},
 			Spaces:         map[uuid.UUID]spaces.Space{space.ID(): *space},
+			Usage:          42,
 		}).Once()
 
 		// Run
@@ -76,6 +82,42 @@ func Test_SecurityPage(t *testing.T) {
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	})
 
+	t.Run("getSecurityPage with a GetUserUsage error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		davSessionsMock := davsessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+
+		webSessionsMock.On("GetAllForUser", mock.Anything, user.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]websessions.Session{*webSession}, nil).Once()
+		davSessionsMock.On("GetAllForUser", mock.Anything, user.ID(), &sqlstorage.PaginateCmd{Limit: 20}).Return([]davsessions.DavSession{}, nil).Once()
+		spacesMock.On("GetAllUserSpaces", mock.Anything, user.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]spaces.Space{}, nil).Once()
+
+		fsMock.On("GetUserUsage", mock.Anything, user).Return(uint64(0), errs.ErrInternal).Once()
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to fs.GetUserUsage: %w", errs.ErrInternal)).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/settings/security", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
 	t.Run("getSecurityPage redirect to login if not authenticated", func(t *testing.T) {
 		t.Parallel()
 
@@ -86,7 +128,8 @@ func Test_SecurityPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
 
 		// Data
 
@@ -117,7 +160,8 @@ func Test_SecurityPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
 
 		// Data
 		user := users.NewFakeUser(t).Build()
@@ -164,6 +208,187 @@ func Test_SecurityPage(t *testing.T) {
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	})
 
+	t.Run("createDavSession with a scope success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		davSessionsMock := davsessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).CreatedBy(user).Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+
+		newSessionSecret := "some-secret"
+		newDavSession := davsessions.NewFakeSession(t).
+			CreatedBy(user).
+			WithSpace(space).
+			WithPassword(newSessionSecret).
+			WithName("some dav-session name").
+			WithReadOnly().
+			WithRootPath("/Movies").
+			Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", string(space.ID())).Return(space.ID(), nil).Once()
+		davSessionsMock.On("Create", mock.Anything, &davsessions.CreateCmd{
+			UserID:   user.ID(),
+			Name:     "some dav-session name",
+			Username: user.Username(),
+			SpaceID:  space.ID(),
+			ReadOnly: true,
+			RootPath: "/Movies",
+			// The session stays valid the whole day.
+			ExpiresAt: ptr.To(time.Date(2030, time.January, 2, 0, 0, 0, 0, time.UTC)),
+		}).Return(newDavSession, newSessionSecret, nil).Once()
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusCreated, &security.WebdavResultTemplate{
+			NewSession: newDavSession,
+			Secret:     newSessionSecret,
+		}).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/security/webdav", strings.NewReader(url.Values{
+			"space":     []string{string(space.ID())},
+			"name":      []string{"some dav-session name"},
+			"readOnly":  []string{"on"},
+			"rootPath":  []string{"/Movies"},
+			"expiresAt": []string{"2030-01-01"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Assert
+		res := w.Result()
+		res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("createDavSession with a shared folder success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		davSessionsMock := davsessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		grant := dfs.NewFakeGrant(t, &dfs.ExampleAliceDir, user).Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+
+		newSessionSecret := "some-secret"
+		newDavSession := davsessions.NewFakeSession(t).
+			CreatedBy(user).
+			WithGrant(grant).
+			WithPassword(newSessionSecret).
+			WithName("some dav-session name").
+			Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", string(grant.ID())).Return(grant.ID(), nil).Once()
+		davSessionsMock.On("Create", mock.Anything, &davsessions.CreateCmd{
+			UserID:   user.ID(),
+			Name:     "some dav-session name",
+			Username: user.Username(),
+			GrantID:  ptr.To(grant.ID()),
+		}).Return(newDavSession, newSessionSecret, nil).Once()
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusCreated, &security.WebdavResultTemplate{
+			NewSession: newDavSession,
+			Secret:     newSessionSecret,
+		}).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/security/webdav", strings.NewReader(url.Values{
+			"space": []string{"grant/" + string(grant.ID())},
+			"name":  []string{"some dav-session name"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Assert
+		res := w.Result()
+		res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("createDavSession with all the spaces success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		davSessionsMock := davsessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+
+		newSessionSecret := "some-secret"
+		newDavSession := davsessions.NewFakeSession(t).
+			CreatedBy(user).
+			WithAllSpaces().
+			WithPassword(newSessionSecret).
+			WithName("some dav-session name").
+			Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		davSessionsMock.On("Create", mock.Anything, &davsessions.CreateCmd{
+			UserID:    user.ID(),
+			Name:      "some dav-session name",
+			Username:  user.Username(),
+			AllSpaces: true,
+		}).Return(newDavSession, newSessionSecret, nil).Once()
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusCreated, &security.WebdavResultTemplate{
+			NewSession: newDavSession,
+			Secret:     newSessionSecret,
+		}).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/security/webdav", strings.NewReader(url.Values{
+			"space": []string{"all"},
+			"name":  []string{"some dav-session name"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Assert
+		res := w.Result()
+		res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
 	t.Run("deleteWebSession success", func(t *testing.T) {
 		t.Parallel()
 
@@ -174,7 +399,8 @@ func Test_SecurityPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -195,12 +421,14 @@ func Test_SecurityPage(t *testing.T) {
 		webSessionsMock.On("GetAllForUser", mock.Anything, user.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]websessions.Session{*webSession}, nil).Once()
 		davSessionsMock.On("GetAllForUser", mock.Anything, user.ID(), &sqlstorage.PaginateCmd{Limit: 20}).Return([]davsessions.DavSession{*davSession}, nil).Once()
 		spacesMock.On("GetAllUserSpaces", mock.Anything, user.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]spaces.Space{*space}, nil).Once()
+		fsMock.On("GetUserUsage", mock.Anything, user).Return(uint64(42), nil).Once()
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &security.ContentTemplate{
 			IsAdmin:        user.IsAdmin(),
 			CurrentSession: webSession,
 			WebSessions:    []websessions.Session{*webSession},
 			Devices:        []davsessions.DavSession{*davSession},
 			Spaces:         map[uuid.UUID]spaces.Space{space.ID(): *space},
+			Usage:          42,
 		}).Once()
 
 		// Run
@@ -226,7 +454,8 @@ func Test_SecurityPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
 
 		// Authentication
 		// Data
@@ -249,12 +478,14 @@ func Test_SecurityPage(t *testing.T) {
 		webSessionsMock.On("GetAllForUser", mock.Anything, user.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]websessions.Session{*webSession}, nil).Once()
 		davSessionsMock.On("GetAllForUser", mock.Anything, user.ID(), &sqlstorage.PaginateCmd{Limit: 20}).Return([]davsessions.DavSession{*davSession}, nil).Once()
 		spacesMock.On("GetAllUserSpaces", mock.Anything, user.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]spaces.Space{*space}, nil).Once()
+		fsMock.On("GetUserUsage", mock.Anything, user).Return(uint64(42), nil).Once()
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &security.ContentTemplate{
 			IsAdmin:        user.IsAdmin(),
 			CurrentSession: webSession,
 			WebSessions:    []websessions.Session{*webSession},
 			Devices:        []davsessions.DavSession{*davSession},
 			Spaces:         map[uuid.UUID]spaces.Space{space.ID(): *space},
+			Usage:          42,
 		}).Once()
 
 		// Run
@@ -280,7 +511,8 @@ func Test_SecurityPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -302,12 +534,14 @@ func Test_SecurityPage(t *testing.T) {
 		webSessionsMock.On("GetAllForUser", mock.Anything, user.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]websessions.Session{*webSession}, nil).Once()
 		davSessionsMock.On("GetAllForUser", mock.Anything, user.ID(), &sqlstorage.PaginateCmd{Limit: 20}).Return([]davsessions.DavSession{}, nil).Once()
 		spacesMock.On("GetAllUserSpaces", mock.Anything, user.ID(), (*sqlstorage.PaginateCmd)(nil)).Return([]spaces.Space{}, nil).Once()
+		fsMock.On("GetUserUsage", mock.Anything, user).Return(uint64(42), nil).Once()
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &security.ContentTemplate{
 			IsAdmin:        user.IsAdmin(),
 			CurrentSession: webSession,
 			WebSessions:    []websessions.Session{*webSession},
 			Devices:        []davsessions.DavSession{},
 			Spaces:         map[uuid.UUID]spaces.Space{},
+			Usage:          42,
 		}).Once()
 
 		// Run
@@ -338,7 +572,8 @@ func Test_SecurityPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -383,7 +618,8 @@ func Test_SecurityPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -427,7 +663,8 @@ func Test_SecurityPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -470,7 +707,8 @@ func Test_SecurityPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -519,7 +757,8 @@ func Test_SecurityPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -556,7 +795,8 @@ func Test_SecurityPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSecurityPage(tools, htmlMock, webSessionsMock, davSessionsMock, spacesMock, usersMock, auth, fsMock)
 
 		// Data
 
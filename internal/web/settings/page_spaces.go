@@ -1,15 +1,22 @@
 package settings
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
 	"github.com/theduckcompany/duckcloud/internal/tools/logger"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
 	"github.com/theduckcompany/duckcloud/internal/tools/router"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 	"github.com/theduckcompany/duckcloud/internal/web/auth"
@@ -20,6 +27,7 @@ import (
 type SpacesPage struct {
 	html      html.Writer
 	spaces    spaces.Service
+	fs        dfs.Service
 	users     users.Service
 	scheduler scheduler.Service
 	auth      *auth.Authenticator
@@ -32,11 +40,13 @@ func NewSpacesPage(
 	users users.Service,
 	authent *auth.Authenticator,
 	scheduler scheduler.Service,
+	fs dfs.Service,
 	tools tools.Tools,
 ) *SpacesPage {
 	return &SpacesPage{
 		html:      html,
 		spaces:    spaces,
+		fs:        fs,
 		users:     users,
 		scheduler: scheduler,
 		auth:      authent,
@@ -52,6 +62,13 @@ func (h *SpacesPage) Register(r chi.Router, mids *router.Middlewares) {
 	r.Get("/settings/spaces/new", h.getCreateSpaceModal)
 	r.Post("/settings/spaces/create", h.createSpace)
 	r.Post("/settings/spaces/{spaceID}/delete", h.deleteSpace)
+	r.Post("/settings/spaces/{spaceID}/trash-retention", h.setTrashRetention)
+	r.Post("/settings/spaces/{spaceID}/versions-policy", h.setVersionsPolicy)
+	r.Post("/settings/spaces/{spaceID}/quota", h.setQuota)
+	r.Get("/settings/spaces/{spaceID}/members", h.getMembersModal)
+	r.Post("/settings/spaces/{spaceID}/members", h.addMember)
+	r.Post("/settings/spaces/{spaceID}/members/{userID}/role", h.setMemberRole)
+	r.Post("/settings/spaces/{spaceID}/members/{userID}/delete", h.removeMember)
 }
 
 func (h *SpacesPage) getContent(w http.ResponseWriter, r *http.Request) {
@@ -70,27 +87,32 @@ func (h *SpacesPage) getContent(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *SpacesPage) renderContent(w http.ResponseWriter, r *http.Request, user *users.User) {
-	usersArray, err := h.users.GetAll(r.Context(), nil)
+	spaces, err := h.spaces.GetAllSpaces(r.Context(), user, nil)
 	if err != nil {
-		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetAllUsers: %w", err))
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetAllSpaces: %w", err))
 		return
 	}
 
-	usersMap := make(map[uuid.UUID]users.User, len(usersArray))
-	for _, u := range usersArray {
-		usersMap[u.ID()] = u
-	}
+	usages := make(map[uuid.UUID]uint64, len(spaces))
+	for i := range spaces {
+		root, err := h.fs.Get(r.Context(), dfs.NewPathCmd(&spaces[i], "/"))
+		if errors.Is(err, errs.ErrNotFound) {
+			// The space is still being created.
+			continue
+		}
 
-	spaces, err := h.spaces.GetAllSpaces(r.Context(), user, nil)
-	if err != nil {
-		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetAllSpaces: %w", err))
-		return
+		if err != nil {
+			h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to get the root of the space %q: %w", spaces[i].ID(), err))
+			return
+		}
+
+		usages[spaces[i].ID()] = root.Size()
 	}
 
 	h.html.WriteHTMLTemplate(w, r, http.StatusOK, &spacestmpl.ContentTemplate{
 		IsAdmin: user.IsAdmin(),
 		Spaces:  spaces,
-		Users:   usersMap,
+		Usages:  usages,
 	})
 }
 
@@ -116,6 +138,113 @@ func (h *SpacesPage) deleteSpace(w http.ResponseWriter, r *http.Request) {
 	h.renderContent(w, r, user)
 }
 
+func (h *SpacesPage) setTrashRetention(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AdminOnly)
+	if abort {
+		return
+	}
+
+	spaceID, err := h.uuid.Parse(chi.URLParam(r, "spaceID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("spaceID %q not found", chi.URLParam(r, "spaceID")))
+		return
+	}
+
+	days, err := strconv.Atoi(r.FormValue("retention"))
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("invalid retention %q: %w", r.FormValue("retention"), err))
+		return
+	}
+
+	_, err = h.spaces.SetTrashRetention(r.Context(), &spaces.SetTrashRetentionCmd{
+		User:      user,
+		SpaceID:   spaceID,
+		Retention: time.Duration(days) * 24 * time.Hour,
+	})
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to SetTrashRetention: %w", err))
+		return
+	}
+
+	h.renderContent(w, r, user)
+}
+
+func (h *SpacesPage) setVersionsPolicy(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AdminOnly)
+	if abort {
+		return
+	}
+
+	spaceID, err := h.uuid.Parse(chi.URLParam(r, "spaceID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("spaceID %q not found", chi.URLParam(r, "spaceID")))
+		return
+	}
+
+	maxVersions, err := strconv.Atoi(r.FormValue("maxVersions"))
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("invalid max versions %q: %w", r.FormValue("maxVersions"), err))
+		return
+	}
+
+	days, err := strconv.Atoi(r.FormValue("retention"))
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("invalid retention %q: %w", r.FormValue("retention"), err))
+		return
+	}
+
+	_, err = h.spaces.SetVersionsPolicy(r.Context(), &spaces.SetVersionsPolicyCmd{
+		User:        user,
+		SpaceID:     spaceID,
+		MaxVersions: maxVersions,
+		Retention:   time.Duration(days) * 24 * time.Hour,
+	})
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to SetVersionsPolicy: %w", err))
+		return
+	}
+
+	h.renderContent(w, r, user)
+}
+
+func (h *SpacesPage) setQuota(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AdminOnly)
+	if abort {
+		return
+	}
+
+	spaceID, err := h.uuid.Parse(chi.URLParam(r, "spaceID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("spaceID %q not found", chi.URLParam(r, "spaceID")))
+		return
+	}
+
+	quota, err := strconv.ParseUint(r.FormValue("quota"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("invalid quota %q: %w", r.FormValue("quota"), err))
+		return
+	}
+
+	_, err = h.spaces.SetQuota(r.Context(), &spaces.SetQuotaCmd{
+		User:    user,
+		SpaceID: spaceID,
+		Quota:   quota,
+	})
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to SetQuota: %w", err))
+		return
+	}
+
+	h.renderContent(w, r, user)
+}
+
 func (h *SpacesPage) getCreateSpaceModal(w http.ResponseWriter, r *http.Request) {
 	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AdminOnly)
 	if abort {
@@ -172,3 +301,218 @@ func (h *SpacesPage) renderCreateSpaceModal(w http.ResponseWriter, r *http.Reque
 		},
 	})
 }
+
+// The members routes are available to any user because the space managers
+// can also manage the members. The permissions are checked by the service.
+
+func (h *SpacesPage) getMembersModal(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	space, abort := h.getSpace(w, r)
+	if abort {
+		return
+	}
+
+	h.renderMembersModal(w, r, http.StatusOK, user, &spacestmpl.MembersModal{Space: space})
+}
+
+func (h *SpacesPage) addMember(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	space, abort := h.getSpace(w, r)
+	if abort {
+		return
+	}
+
+	userID, err := h.uuid.Parse(r.FormValue("userID"))
+	if err != nil {
+		h.renderMembersModal(w, r, http.StatusUnprocessableEntity, user, &spacestmpl.MembersModal{
+			Error: ptr.To("invalid user"),
+			Space: space,
+		})
+		return
+	}
+
+	member, err := h.users.GetByID(r.Context(), userID)
+	if errors.Is(err, errs.ErrNotFound) {
+		h.renderMembersModal(w, r, http.StatusUnprocessableEntity, user, &spacestmpl.MembersModal{
+			Error: ptr.To("invalid user"),
+			Space: space,
+		})
+		return
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetByID: %w", err))
+		return
+	}
+
+	_, err = h.spaces.AddMember(r.Context(), &spaces.AddMemberCmd{
+		User:    user,
+		Member:  member,
+		SpaceID: space.ID(),
+		Role:    spaces.Role(r.FormValue("role")),
+	})
+	if errors.Is(err, errs.ErrValidation) || errors.Is(err, errs.ErrBadRequest) {
+		h.renderMembersModal(w, r, http.StatusUnprocessableEntity, user, &spacestmpl.MembersModal{
+			Error: ptr.To(err.Error()),
+			Space: space,
+		})
+		return
+	}
+
+	if err != nil {
+		h.writeMembersError(w, r, fmt.Errorf("failed to AddMember: %w", err))
+		return
+	}
+
+	h.renderMembersModal(w, r, http.StatusOK, user, &spacestmpl.MembersModal{Space: space})
+}
+
+func (h *SpacesPage) setMemberRole(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	space, abort := h.getSpace(w, r)
+	if abort {
+		return
+	}
+
+	memberID, err := h.uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("userID %q not found", chi.URLParam(r, "userID")))
+		return
+	}
+
+	_, err = h.spaces.SetMemberRole(r.Context(), &spaces.SetMemberRoleCmd{
+		User:     user,
+		MemberID: memberID,
+		SpaceID:  space.ID(),
+		Role:     spaces.Role(r.FormValue("role")),
+	})
+	if errors.Is(err, errs.ErrValidation) {
+		h.renderMembersModal(w, r, http.StatusUnprocessableEntity, user, &spacestmpl.MembersModal{
+			Error: ptr.To(err.Error()),
+			Space: space,
+		})
+		return
+	}
+
+	if err != nil {
+		h.writeMembersError(w, r, fmt.Errorf("failed to SetMemberRole: %w", err))
+		return
+	}
+
+	h.renderMembersModal(w, r, http.StatusOK, user, &spacestmpl.MembersModal{Space: space})
+}
+
+func (h *SpacesPage) removeMember(w http.ResponseWriter, r *http.Request) {
+	user, _, abort := h.auth.GetUserAndSession(w, r, auth.AnyUser)
+	if abort {
+		return
+	}
+
+	space, abort := h.getSpace(w, r)
+	if abort {
+		return
+	}
+
+	memberID, err := h.uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("userID %q not found", chi.URLParam(r, "userID")))
+		return
+	}
+
+	err = h.spaces.RemoveMember(r.Context(), &spaces.RemoveMemberCmd{
+		User:     user,
+		MemberID: memberID,
+		SpaceID:  space.ID(),
+	})
+	if err != nil {
+		h.writeMembersError(w, r, fmt.Errorf("failed to RemoveMember: %w", err))
+		return
+	}
+
+	if memberID == user.ID() && !user.IsAdmin() {
+		// The user has left the space and can't see its members anymore.
+		w.Header().Set("HX-Redirect", "/browser")
+		return
+	}
+
+	h.renderMembersModal(w, r, http.StatusOK, user, &spacestmpl.MembersModal{Space: space})
+}
+
+func (h *SpacesPage) getSpace(w http.ResponseWriter, r *http.Request) (*spaces.Space, bool) {
+	spaceID, err := h.uuid.Parse(chi.URLParam(r, "spaceID"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("spaceID %q not found", chi.URLParam(r, "spaceID")))
+		return nil, true
+	}
+
+	space, err := h.spaces.GetByID(r.Context(), spaceID)
+	if errors.Is(err, errs.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("space %q not found", spaceID))
+		return nil, true
+	}
+
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetByID: %w", err))
+		return nil, true
+	}
+
+	return space, false
+}
+
+func (h *SpacesPage) renderMembersModal(w http.ResponseWriter, r *http.Request, status int, user *users.User, tmpl *spacestmpl.MembersModal) {
+	members, err := h.spaces.GetAllMembers(r.Context(), user, tmpl.Space.ID())
+	if err != nil {
+		h.writeMembersError(w, r, fmt.Errorf("failed to GetAllMembers: %w", err))
+		return
+	}
+
+	allUsers, err := h.users.GetAll(r.Context(), nil)
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to GetAll users: %w", err))
+		return
+	}
+
+	tmpl.Members = members
+	tmpl.Users = make(map[uuid.UUID]users.User, len(allUsers))
+	tmpl.Candidates = []users.User{}
+
+	for _, u := range allUsers {
+		tmpl.Users[u.ID()] = u
+
+		isMember := slices.ContainsFunc(members, func(m spaces.Member) bool { return m.UserID() == u.ID() })
+		if !isMember && u.Status() == users.Active {
+			tmpl.Candidates = append(tmpl.Candidates, u)
+		}
+	}
+
+	h.html.WriteHTMLTemplate(w, r, status, tmpl)
+}
+
+func (h *SpacesPage) writeMembersError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errs.ErrUnauthorized):
+		w.WriteHeader(http.StatusForbidden)
+		logger.LogEntrySetError(r.Context(), err)
+	case errors.Is(err, errs.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		logger.LogEntrySetError(r.Context(), err)
+	default:
+		h.html.WriteHTMLErrorPage(w, r, err)
+	}
+}
@@ -7,16 +7,19 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/spaces"
 	"github.com/theduckcompany/duckcloud/internal/service/tasks/scheduler"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/service/websessions"
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/ptr"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
 	"github.com/theduckcompany/duckcloud/internal/web/auth"
@@ -37,11 +40,11 @@ func Test_SpacesPage(t *testing.T) {
 		schedulerMock := scheduler.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, tools)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
-		user2 := users.NewFakeUser(t).Build()
 		space := spaces.NewFakeSpace(t).CreatedBy(user).Build()
 		space2 := spaces.NewFakeSpace(t).Build()
 		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
@@ -49,12 +52,15 @@ func Test_SpacesPage(t *testing.T) {
 		// Mocks
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
 		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
-		usersMock.On("GetAll", mock.Anything, (*sqlstorage.PaginateCmd)(nil)).Return([]users.User{*user, *user2}, nil).Once()
 		spacesMock.On("GetAllSpaces", mock.Anything, user, (*sqlstorage.PaginateCmd)(nil)).Return([]spaces.Space{*space, *space2}, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(space, "/")).
+			Return(dfs.NewFakeINode(t).WithSpace(space).IsRootDirectory().Build(), nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(space2, "/")).
+			Return(dfs.NewFakeINode(t).WithSpace(space2).IsRootDirectory().Build(), nil).Once()
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &spacestmpl.ContentTemplate{
 			IsAdmin: true,
 			Spaces:  []spaces.Space{*space, *space2},
-			Users:   map[uuid.UUID]users.User{user.ID(): *user, user2.ID(): *user2},
+			Usages:  map[uuid.UUID]uint64{space.ID(): 42, space2.ID(): 42},
 		})
 
 		// Run
@@ -80,7 +86,8 @@ func Test_SpacesPage(t *testing.T) {
 		schedulerMock := scheduler.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, tools)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
 
 		// Data
 
@@ -111,7 +118,8 @@ func Test_SpacesPage(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		schedulerMock := scheduler.NewMockService(t)
-		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, tools)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).Build() // NOTE: is not an admin
@@ -135,7 +143,7 @@ func Test_SpacesPage(t *testing.T) {
 		assert.Equal(t, "/settings", res.Header.Get("Location"))
 	})
 
-	t.Run("getContent with a users.GetAll error", func(t *testing.T) {
+	t.Run("getContent with a spaces.GetAllSpaces error", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
@@ -145,7 +153,8 @@ func Test_SpacesPage(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		schedulerMock := scheduler.NewMockService(t)
-		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, tools)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -154,9 +163,10 @@ func Test_SpacesPage(t *testing.T) {
 		// Mocks
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
 		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
-		usersMock.On("GetAll", mock.Anything, (*sqlstorage.PaginateCmd)(nil)).Return(nil, errs.ErrInternal).Once()
 
-		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to GetAllUsers: %w", errs.ErrInternal))
+		spacesMock.On("GetAllSpaces", mock.Anything, user, (*sqlstorage.PaginateCmd)(nil)).
+			Return(nil, errs.ErrInternal).Once()
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to GetAllSpaces: %w", errs.ErrInternal))
 
 		// Run
 		w := httptest.NewRecorder()
@@ -171,7 +181,7 @@ func Test_SpacesPage(t *testing.T) {
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	})
 
-	t.Run("getContent with a spaces.GetAllSpaces error", func(t *testing.T) {
+	t.Run("getContent with a fs.Get error", func(t *testing.T) {
 		t.Parallel()
 
 		tools := tools.NewMock(t)
@@ -181,20 +191,23 @@ func Test_SpacesPage(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		schedulerMock := scheduler.NewMockService(t)
-		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, tools)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
 		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		space := spaces.NewFakeSpace(t).Build()
 
 		// Mocks
 		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
 		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
 
-		usersMock.On("GetAll", mock.Anything, (*sqlstorage.PaginateCmd)(nil)).Return([]users.User{*user}, nil).Once()
 		spacesMock.On("GetAllSpaces", mock.Anything, user, (*sqlstorage.PaginateCmd)(nil)).
-			Return(nil, errs.ErrInternal).Once()
-		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to GetAllSpaces: %w", errs.ErrInternal))
+			Return([]spaces.Space{*space}, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(space, "/")).Return(nil, errs.ErrInternal).Once()
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything,
+			fmt.Errorf("failed to get the root of the space %q: %w", space.ID(), errs.ErrInternal)).Once()
 
 		// Run
 		w := httptest.NewRecorder()
@@ -219,7 +232,8 @@ func Test_SpacesPage(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		schedulerMock := scheduler.NewMockService(t)
-		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, tools)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -232,8 +246,6 @@ func Test_SpacesPage(t *testing.T) {
 		tools.UUIDMock.On("Parse", someSpaceID).Return(uuid.UUID(someSpaceID), nil).Once()
 		spacesMock.On("Delete", mock.Anything, user, uuid.UUID(someSpaceID)).Return(nil).Once()
 
-		usersMock.On("GetAll", mock.Anything, (*sqlstorage.PaginateCmd)(nil)).
-			Return([]users.User{*user}, nil).Once()
 		spacesMock.On("GetAllSpaces", mock.Anything, user, (*sqlstorage.PaginateCmd)(nil)).
 			Return(nil, errs.ErrInternal).Once()
 		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to GetAllSpaces: %w", errs.ErrInternal))
@@ -261,7 +273,8 @@ func Test_SpacesPage(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		schedulerMock := scheduler.NewMockService(t)
-		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, tools)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -295,7 +308,8 @@ func Test_SpacesPage(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		schedulerMock := scheduler.NewMockService(t)
-		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, tools)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -322,6 +336,410 @@ func Test_SpacesPage(t *testing.T) {
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	})
 
+	t.Run("setTrashRetention success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		schedulerMock := scheduler.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		space := spaces.NewFakeSpace(t).WithTrashRetention(7 * 24 * time.Hour).Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", string(space.ID())).Return(space.ID(), nil).Once()
+		spacesMock.On("SetTrashRetention", mock.Anything, &spaces.SetTrashRetentionCmd{
+			User:      user,
+			SpaceID:   space.ID(),
+			Retention: 7 * 24 * time.Hour,
+		}).Return(space, nil).Once()
+
+		spacesMock.On("GetAllSpaces", mock.Anything, user, (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{*space}, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(space, "/")).
+			Return(dfs.NewFakeINode(t).WithSpace(space).IsRootDirectory().Build(), nil).Once()
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &spacestmpl.ContentTemplate{
+			IsAdmin: true,
+			Spaces:  []spaces.Space{*space},
+			Usages:  map[uuid.UUID]uint64{space.ID(): 42},
+		}).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+string(space.ID())+"/trash-retention", strings.NewReader(url.Values{
+			"retention": []string{"7"},
+		}.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("setTrashRetention with an invalid retention", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		schedulerMock := scheduler.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		someSpaceID := "some-space-id"
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", someSpaceID).Return(uuid.UUID(someSpaceID), nil).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+someSpaceID+"/trash-retention", strings.NewReader(url.Values{
+			"retention": []string{"not-a-number"},
+		}.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	})
+
+	t.Run("setTrashRetention with a SetTrashRetention error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		schedulerMock := scheduler.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		someSpaceID := "some-space-id"
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", someSpaceID).Return(uuid.UUID(someSpaceID), nil).Once()
+		spacesMock.On("SetTrashRetention", mock.Anything, &spaces.SetTrashRetentionCmd{
+			User:      user,
+			SpaceID:   uuid.UUID(someSpaceID),
+			Retention: 0,
+		}).Return(nil, errs.ErrInternal).Once()
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to SetTrashRetention: %w", errs.ErrInternal)).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+someSpaceID+"/trash-retention", strings.NewReader(url.Values{
+			"retention": []string{"0"},
+		}.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("setVersionsPolicy success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		schedulerMock := scheduler.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		space := spaces.NewFakeSpace(t).WithVersionsPolicy(5, 30*24*time.Hour).Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", string(space.ID())).Return(space.ID(), nil).Once()
+		spacesMock.On("SetVersionsPolicy", mock.Anything, &spaces.SetVersionsPolicyCmd{
+			User:        user,
+			SpaceID:     space.ID(),
+			MaxVersions: 5,
+			Retention:   30 * 24 * time.Hour,
+		}).Return(space, nil).Once()
+
+		spacesMock.On("GetAllSpaces", mock.Anything, user, (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{*space}, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(space, "/")).
+			Return(dfs.NewFakeINode(t).WithSpace(space).IsRootDirectory().Build(), nil).Once()
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &spacestmpl.ContentTemplate{
+			IsAdmin: true,
+			Spaces:  []spaces.Space{*space},
+			Usages:  map[uuid.UUID]uint64{space.ID(): 42},
+		}).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+string(space.ID())+"/versions-policy", strings.NewReader(url.Values{
+			"maxVersions": []string{"5"},
+			"retention":   []string{"30"},
+		}.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("setVersionsPolicy with an invalid max versions", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		schedulerMock := scheduler.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		someSpaceID := "some-space-id"
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", someSpaceID).Return(uuid.UUID(someSpaceID), nil).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+someSpaceID+"/versions-policy", strings.NewReader(url.Values{
+			"maxVersions": []string{"not-a-number"},
+			"retention":   []string{"0"},
+		}.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	})
+
+	t.Run("setVersionsPolicy with a SetVersionsPolicy error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		schedulerMock := scheduler.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		someSpaceID := "some-space-id"
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", someSpaceID).Return(uuid.UUID(someSpaceID), nil).Once()
+		spacesMock.On("SetVersionsPolicy", mock.Anything, &spaces.SetVersionsPolicyCmd{
+			User:        user,
+			SpaceID:     uuid.UUID(someSpaceID),
+			MaxVersions: 10,
+			Retention:   0,
+		}).Return(nil, errs.ErrInternal).Once()
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to SetVersionsPolicy: %w", errs.ErrInternal)).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+someSpaceID+"/versions-policy", strings.NewReader(url.Values{
+			"maxVersions": []string{"10"},
+			"retention":   []string{"0"},
+		}.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
+	t.Run("setQuota success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		schedulerMock := scheduler.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		space := spaces.NewFakeSpace(t).WithQuota(1000000000).Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", string(space.ID())).Return(space.ID(), nil).Once()
+		spacesMock.On("SetQuota", mock.Anything, &spaces.SetQuotaCmd{
+			User:    user,
+			SpaceID: space.ID(),
+			Quota:   1000000000,
+		}).Return(space, nil).Once()
+
+		spacesMock.On("GetAllSpaces", mock.Anything, user, (*sqlstorage.PaginateCmd)(nil)).
+			Return([]spaces.Space{*space}, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(space, "/")).
+			Return(dfs.NewFakeINode(t).WithSpace(space).IsRootDirectory().Build(), nil).Once()
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &spacestmpl.ContentTemplate{
+			IsAdmin: true,
+			Spaces:  []spaces.Space{*space},
+			Usages:  map[uuid.UUID]uint64{space.ID(): 42},
+		}).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+string(space.ID())+"/quota", strings.NewReader(url.Values{
+			"quota": []string{"1000000000"},
+		}.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("setQuota with an invalid quota", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		schedulerMock := scheduler.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		someSpaceID := "some-space-id"
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", someSpaceID).Return(uuid.UUID(someSpaceID), nil).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+someSpaceID+"/quota", strings.NewReader(url.Values{
+			"quota": []string{"not-a-number"},
+		}.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	})
+
+	t.Run("setQuota with a SetQuota error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		schedulerMock := scheduler.NewMockService(t)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		someSpaceID := "some-space-id"
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", someSpaceID).Return(uuid.UUID(someSpaceID), nil).Once()
+		spacesMock.On("SetQuota", mock.Anything, &spaces.SetQuotaCmd{
+			User:    user,
+			SpaceID: uuid.UUID(someSpaceID),
+			Quota:   0,
+		}).Return(nil, errs.ErrInternal).Once()
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to SetQuota: %w", errs.ErrInternal)).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+someSpaceID+"/quota", strings.NewReader(url.Values{
+			"quota": []string{"0"},
+		}.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
+
 	t.Run("getCreateSpaceModal success", func(t *testing.T) {
 		t.Parallel()
 
@@ -332,7 +750,8 @@ func Test_SpacesPage(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		schedulerMock := scheduler.NewMockService(t)
-		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, tools)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -375,7 +794,8 @@ func Test_SpacesPage(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		schedulerMock := scheduler.NewMockService(t)
-		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, tools)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).Build() // NOTE: Not an admin
@@ -408,7 +828,8 @@ func Test_SpacesPage(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		schedulerMock := scheduler.NewMockService(t)
-		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, tools)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -443,14 +864,14 @@ func Test_SpacesPage(t *testing.T) {
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
 		schedulerMock := scheduler.NewMockService(t)
-		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, tools)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
 		user2 := users.NewFakeUser(t).Build()
 		space := spaces.NewFakeSpace(t).
 			WithName("some-space-name").
-			WithOwners(*user, *user2).
 			Build()
 		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
 
@@ -466,16 +887,14 @@ func Test_SpacesPage(t *testing.T) {
 		}).Return(nil).Once()
 
 		// Render the page
-		usersMock.On("GetAll", mock.Anything, (*sqlstorage.PaginateCmd)(nil)).Return([]users.User{*user, *user2}, nil).Once()
 		spacesMock.On("GetAllSpaces", mock.Anything, user, (*sqlstorage.PaginateCmd)(nil)).Return([]spaces.Space{*space}, nil).Once()
+		fsMock.On("Get", mock.Anything, dfs.NewPathCmd(space, "/")).
+			Return(dfs.NewFakeINode(t).WithSpace(space).IsRootDirectory().Build(), nil).Once()
 
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &spacestmpl.ContentTemplate{
 			IsAdmin: true,
 			Spaces:  []spaces.Space{*space},
-			Users: map[uuid.UUID]users.User{
-				user.ID():  *user,
-				user2.ID(): *user2,
-			},
+			Usages:  map[uuid.UUID]uint64{space.ID(): 42},
 		})
 
 		// Run
@@ -494,4 +913,300 @@ func Test_SpacesPage(t *testing.T) {
 		defer res.Body.Close()
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	})
+
+	t.Run("getMembersModal success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		user2 := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).CreatedBy(user).Build()
+		member := spaces.NewFakeMember(t, space, user).WithRole(spaces.RoleManager).Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", string(space.ID())).Return(space.ID(), nil).Once()
+		spacesMock.On("GetByID", mock.Anything, space.ID()).Return(space, nil).Once()
+		spacesMock.On("GetAllMembers", mock.Anything, user, space.ID()).Return([]spaces.Member{*member}, nil).Once()
+		usersMock.On("GetAll", mock.Anything, (*sqlstorage.PaginateCmd)(nil)).Return([]users.User{*user, *user2}, nil).Once()
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &spacestmpl.MembersModal{
+			Space:      space,
+			Members:    []spaces.Member{*member},
+			Users:      map[uuid.UUID]users.User{user.ID(): *user, user2.ID(): *user2},
+			Candidates: []users.User{*user2},
+		}).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/settings/spaces/"+string(space.ID())+"/members", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("getMembersModal with a user not member of the space", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", string(space.ID())).Return(space.ID(), nil).Once()
+		spacesMock.On("GetByID", mock.Anything, space.ID()).Return(space, nil).Once()
+		spacesMock.On("GetAllMembers", mock.Anything, user, space.ID()).
+			Return(nil, errs.Unauthorized(spaces.ErrInvalidSpaceAccess)).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/settings/spaces/"+string(space.ID())+"/members", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("addMember success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		user2 := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).CreatedBy(user).Build()
+		member := spaces.NewFakeMember(t, space, user).WithRole(spaces.RoleManager).Build()
+		member2 := spaces.NewFakeMember(t, space, user2).WithRole(spaces.RoleEditor).Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", string(space.ID())).Return(space.ID(), nil).Once()
+		spacesMock.On("GetByID", mock.Anything, space.ID()).Return(space, nil).Once()
+		tools.UUIDMock.On("Parse", string(user2.ID())).Return(user2.ID(), nil).Once()
+		usersMock.On("GetByID", mock.Anything, user2.ID()).Return(user2, nil).Once()
+		spacesMock.On("AddMember", mock.Anything, &spaces.AddMemberCmd{
+			User:    user,
+			Member:  user2,
+			SpaceID: space.ID(),
+			Role:    spaces.RoleEditor,
+		}).Return(member2, nil).Once()
+		spacesMock.On("GetAllMembers", mock.Anything, user, space.ID()).Return([]spaces.Member{*member, *member2}, nil).Once()
+		usersMock.On("GetAll", mock.Anything, (*sqlstorage.PaginateCmd)(nil)).Return([]users.User{*user, *user2}, nil).Once()
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &spacestmpl.MembersModal{
+			Space:      space,
+			Members:    []spaces.Member{*member, *member2},
+			Users:      map[uuid.UUID]users.User{user.ID(): *user, user2.ID(): *user2},
+			Candidates: []users.User{},
+		}).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+string(space.ID())+"/members", strings.NewReader(url.Values{
+			"userID": []string{string(user2.ID())},
+			"role":   []string{"editor"},
+		}.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("addMember with a user already member", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).CreatedBy(user).Build()
+		member := spaces.NewFakeMember(t, space, user).WithRole(spaces.RoleManager).Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Twice()
+		tools.UUIDMock.On("Parse", string(space.ID())).Return(space.ID(), nil).Once()
+		spacesMock.On("GetByID", mock.Anything, space.ID()).Return(space, nil).Once()
+		tools.UUIDMock.On("Parse", string(user.ID())).Return(user.ID(), nil).Once()
+		spacesMock.On("AddMember", mock.Anything, &spaces.AddMemberCmd{
+			User:    user,
+			Member:  user,
+			SpaceID: space.ID(),
+			Role:    spaces.RoleViewer,
+		}).Return(nil, errs.BadRequest(spaces.ErrAlreadyMember)).Once()
+		spacesMock.On("GetAllMembers", mock.Anything, user, space.ID()).Return([]spaces.Member{*member}, nil).Once()
+		usersMock.On("GetAll", mock.Anything, (*sqlstorage.PaginateCmd)(nil)).Return([]users.User{*user}, nil).Once()
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusUnprocessableEntity, &spacestmpl.MembersModal{
+			Error:      ptr.To(errs.BadRequest(spaces.ErrAlreadyMember).Error()),
+			Space:      space,
+			Members:    []spaces.Member{*member},
+			Users:      map[uuid.UUID]users.User{user.ID(): *user},
+			Candidates: []users.User{},
+		}).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+string(space.ID())+"/members", strings.NewReader(url.Values{
+			"userID": []string{string(user.ID())},
+			"role":   []string{"viewer"},
+		}.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("setMemberRole with a user not manager", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		user2 := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", string(space.ID())).Return(space.ID(), nil).Once()
+		spacesMock.On("GetByID", mock.Anything, space.ID()).Return(space, nil).Once()
+		tools.UUIDMock.On("Parse", string(user2.ID())).Return(user2.ID(), nil).Once()
+		spacesMock.On("SetMemberRole", mock.Anything, &spaces.SetMemberRoleCmd{
+			User:     user,
+			MemberID: user2.ID(),
+			SpaceID:  space.ID(),
+			Role:     spaces.RoleManager,
+		}).Return(nil, errs.Unauthorized(spaces.ErrNotManager)).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+string(space.ID())+"/members/"+string(user2.ID())+"/role", strings.NewReader(url.Values{
+			"role": []string{"manager"},
+		}.Encode()))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("removeMember to leave the space", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		spacesMock := spaces.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		schedulerMock := scheduler.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewSpacesPage(htmlMock, spacesMock, usersMock, auth, schedulerMock, fsMock, tools)
+
+		// Data
+		user := users.NewFakeUser(t).Build()
+		space := spaces.NewFakeSpace(t).Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", string(space.ID())).Return(space.ID(), nil).Once()
+		spacesMock.On("GetByID", mock.Anything, space.ID()).Return(space, nil).Once()
+		tools.UUIDMock.On("Parse", string(user.ID())).Return(user.ID(), nil).Once()
+		spacesMock.On("RemoveMember", mock.Anything, &spaces.RemoveMemberCmd{
+			User:     user,
+			MemberID: user.ID(),
+			SpaceID:  space.ID(),
+		}).Return(nil).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/spaces/"+string(space.ID())+"/members/"+string(user.ID())+"/delete", nil)
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "/browser", res.Header.Get("HX-Redirect"))
+	})
 }
@@ -4,12 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/service/websessions"
 	"github.com/theduckcompany/duckcloud/internal/tools"
 	"github.com/theduckcompany/duckcloud/internal/tools/errs"
+	"github.com/theduckcompany/duckcloud/internal/tools/logger"
 	"github.com/theduckcompany/duckcloud/internal/tools/router"
 	"github.com/theduckcompany/duckcloud/internal/tools/secret"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
@@ -29,6 +32,7 @@ type UsersPage struct {
 	html  html.Writer
 	users users.Service
 	auth  *auth.Authenticator
+	fs    dfs.Service
 	uuid  uuid.Service
 }
 
@@ -37,11 +41,13 @@ func NewUsersPage(
 	html html.Writer,
 	users users.Service,
 	authent *auth.Authenticator,
+	fs dfs.Service,
 ) *UsersPage {
 	return &UsersPage{
 		html:  html,
 		users: users,
 		auth:  authent,
+		fs:    fs,
 		uuid:  tools.UUID(),
 	}
 }
@@ -54,6 +60,7 @@ func (h *UsersPage) Register(r chi.Router, mids *router.Middlewares) {
 	r.Post("/settings/users", h.createUser)
 	r.Get("/settings/users/new", h.getUsersRegistrationForm)
 	r.Post("/settings/users/{userID}/delete", h.deleteUser)
+	r.Post("/settings/users/{userID}/quota", h.setQuota)
 }
 
 func (h *UsersPage) getUsers(w http.ResponseWriter, r *http.Request) {
@@ -118,6 +125,38 @@ func (h *UsersPage) deleteUser(w http.ResponseWriter, r *http.Request) {
 	h.renderUsers(w, r, renderUsersCmd{User: user, Session: session, Error: nil})
 }
 
+func (h *UsersPage) setQuota(w http.ResponseWriter, r *http.Request) {
+	user, session, abort := h.auth.GetUserAndSession(w, r, auth.AdminOnly)
+	if abort {
+		return
+	}
+
+	userID, err := h.uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		h.renderUsers(w, r, renderUsersCmd{User: user, Session: session, Error: errors.New("invalid user id")})
+		return
+	}
+
+	quota, err := strconv.ParseUint(r.FormValue("quota"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		logger.LogEntrySetError(r.Context(), fmt.Errorf("invalid quota %q: %w", r.FormValue("quota"), err))
+		return
+	}
+
+	_, err = h.users.SetQuota(r.Context(), &users.SetQuotaCmd{
+		User:   user,
+		UserID: userID,
+		Quota:  quota,
+	})
+	if err != nil {
+		h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to users.SetQuota: %w", err))
+		return
+	}
+
+	h.renderUsers(w, r, renderUsersCmd{User: user, Session: session, Error: nil})
+}
+
 func (h *UsersPage) renderUsersRegistrationForm(w http.ResponseWriter, r *http.Request, err error) {
 	status := http.StatusOK
 	if err != nil {
@@ -141,6 +180,15 @@ func (h *UsersPage) renderUsers(w http.ResponseWriter, r *http.Request, cmd rend
 		return
 	}
 
+	usages := make(map[uuid.UUID]uint64, len(allUsers))
+	for i := range allUsers {
+		usages[allUsers[i].ID()], err = h.fs.GetUserUsage(ctx, &allUsers[i])
+		if err != nil {
+			h.html.WriteHTMLErrorPage(w, r, fmt.Errorf("failed to fs.GetUserUsage: %w", err))
+			return
+		}
+	}
+
 	status := http.StatusOK
 	if cmd.Error != nil {
 		status = http.StatusUnprocessableEntity
@@ -150,6 +198,7 @@ func (h *UsersPage) renderUsers(w http.ResponseWriter, r *http.Request, cmd rend
 		IsAdmin: cmd.User.IsAdmin(),
 		Current: cmd.User,
 		Users:   allUsers,
+		Usages:  usages,
 		Error:   cmd.Error,
 	})
 }
@@ -1,6 +1,7 @@
 package settings
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -10,9 +11,11 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/theduckcompany/duckcloud/internal/service/dfs"
 	"github.com/theduckcompany/duckcloud/internal/service/users"
 	"github.com/theduckcompany/duckcloud/internal/service/websessions"
 	"github.com/theduckcompany/duckcloud/internal/tools"
+	"github.com/theduckcompany/duckcloud/internal/tools/errs"
 	"github.com/theduckcompany/duckcloud/internal/tools/secret"
 	"github.com/theduckcompany/duckcloud/internal/tools/sqlstorage"
 	"github.com/theduckcompany/duckcloud/internal/tools/uuid"
@@ -32,7 +35,8 @@ func Test_UsersPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewUsersPage(tools, htmlMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewUsersPage(tools, htmlMock, usersMock, auth, fsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -46,10 +50,13 @@ func Test_UsersPage(t *testing.T) {
 			StartAfter: map[string]string{"username": ""},
 			Limit:      20,
 		}).Return([]users.User{*user, *user2}, nil).Once()
+		fsMock.On("GetUserUsage", mock.Anything, user).Return(uint64(42), nil).Once()
+		fsMock.On("GetUserUsage", mock.Anything, user2).Return(uint64(0), nil).Once()
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &userstmpl.ContentTemplate{
 			IsAdmin: user.IsAdmin(),
 			Current: user,
 			Users:   []users.User{*user, *user2},
+			Usages:  map[uuid.UUID]uint64{user.ID(): 42, user2.ID(): 0},
 			Error:   nil,
 		}).Once()
 
@@ -74,7 +81,8 @@ func Test_UsersPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewUsersPage(tools, htmlMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewUsersPage(tools, htmlMock, usersMock, auth, fsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -94,10 +102,13 @@ func Test_UsersPage(t *testing.T) {
 			Limit:      20,
 		}).Return([]users.User{*user, *user2}, nil).Once()
 
+		fsMock.On("GetUserUsage", mock.Anything, user).Return(uint64(42), nil).Once()
+		fsMock.On("GetUserUsage", mock.Anything, user2).Return(uint64(0), nil).Once()
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &userstmpl.ContentTemplate{
 			IsAdmin: user.IsAdmin(),
 			Current: user,
 			Users:   []users.User{*user, *user2},
+			Usages:  map[uuid.UUID]uint64{user.ID(): 42, user2.ID(): 0},
 			Error:   nil,
 		}).Once()
 
@@ -122,7 +133,8 @@ func Test_UsersPage(t *testing.T) {
 		usersMock := users.NewMockService(t)
 		htmlMock := html.NewMockWriter(t)
 		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
-		handler := NewUsersPage(tools, htmlMock, usersMock, auth)
+		fsMock := dfs.NewMockService(t)
+		handler := NewUsersPage(tools, htmlMock, usersMock, auth, fsMock)
 
 		// Data
 		user := users.NewFakeUser(t).WithAdminRole().Build()
@@ -147,10 +159,13 @@ func Test_UsersPage(t *testing.T) {
 			StartAfter: map[string]string{"username": ""},
 			Limit:      20,
 		}).Return([]users.User{*user, *newUser}, nil).Once()
+		fsMock.On("GetUserUsage", mock.Anything, user).Return(uint64(42), nil).Once()
+		fsMock.On("GetUserUsage", mock.Anything, newUser).Return(uint64(0), nil).Once()
 		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &userstmpl.ContentTemplate{
 			IsAdmin: user.IsAdmin(),
 			Current: user,
 			Users:   []users.User{*user, *newUser},
+			Usages:  map[uuid.UUID]uint64{user.ID(): 42, newUser.ID(): 0},
 			Error:   nil,
 		}).Once()
 
@@ -171,4 +186,134 @@ func Test_UsersPage(t *testing.T) {
 		defer res.Body.Close()
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	})
+
+	t.Run("setQuota success", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewUsersPage(tools, htmlMock, usersMock, auth, fsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		user2 := users.NewFakeUser(t).WithQuota(1000000000).Build()
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", string(user2.ID())).Return(user2.ID(), nil).Once()
+		usersMock.On("SetQuota", mock.Anything, &users.SetQuotaCmd{
+			User:   user,
+			UserID: user2.ID(),
+			Quota:  1000000000,
+		}).Return(user2, nil).Once()
+		usersMock.On("GetAll", mock.Anything, &sqlstorage.PaginateCmd{
+			StartAfter: map[string]string{"username": ""},
+			Limit:      20,
+		}).Return([]users.User{*user, *user2}, nil).Once()
+		fsMock.On("GetUserUsage", mock.Anything, user).Return(uint64(42), nil).Once()
+		fsMock.On("GetUserUsage", mock.Anything, user2).Return(uint64(0), nil).Once()
+		htmlMock.On("WriteHTMLTemplate", mock.Anything, mock.Anything, http.StatusOK, &userstmpl.ContentTemplate{
+			IsAdmin: user.IsAdmin(),
+			Current: user,
+			Users:   []users.User{*user, *user2},
+			Usages:  map[uuid.UUID]uint64{user.ID(): 42, user2.ID(): 0},
+			Error:   nil,
+		}).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/users/"+string(user2.ID())+"/quota", strings.NewReader(url.Values{
+			"quota": []string{"1000000000"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("setQuota with an invalid quota", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewUsersPage(tools, htmlMock, usersMock, auth, fsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		someUserID := "some-user-id"
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", someUserID).Return(uuid.UUID(someUserID), nil).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/users/"+someUserID+"/quota", strings.NewReader(url.Values{
+			"quota": []string{"not-a-number"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+
+		// Asserts
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	})
+
+	t.Run("setQuota with a SetQuota error", func(t *testing.T) {
+		t.Parallel()
+
+		tools := tools.NewMock(t)
+		webSessionsMock := websessions.NewMockService(t)
+		usersMock := users.NewMockService(t)
+		htmlMock := html.NewMockWriter(t)
+		auth := auth.NewAuthenticator(webSessionsMock, usersMock, htmlMock)
+		fsMock := dfs.NewMockService(t)
+		handler := NewUsersPage(tools, htmlMock, usersMock, auth, fsMock)
+
+		// Data
+		user := users.NewFakeUser(t).WithAdminRole().Build()
+		webSession := websessions.NewFakeSession(t).CreatedBy(user).Build()
+		someUserID := "some-user-id"
+
+		// Mocks
+		webSessionsMock.On("GetFromReq", mock.Anything, mock.Anything).Return(webSession, nil).Once()
+		usersMock.On("GetByID", mock.Anything, user.ID()).Return(user, nil).Once()
+		tools.UUIDMock.On("Parse", someUserID).Return(uuid.UUID(someUserID), nil).Once()
+		usersMock.On("SetQuota", mock.Anything, &users.SetQuotaCmd{
+			User:   user,
+			UserID: uuid.UUID(someUserID),
+			Quota:  0,
+		}).Return(nil, errs.ErrInternal).Once()
+		htmlMock.On("WriteHTMLErrorPage", mock.Anything, mock.Anything, fmt.Errorf("failed to users.SetQuota: %w", errs.ErrInternal)).Once()
+
+		// Run
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/settings/users/"+someUserID+"/quota", strings.NewReader(url.Values{
+			"quota": []string{"0"},
+		}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv := chi.NewRouter()
+		handler.Register(srv, nil)
+		srv.ServeHTTP(w, r)
+	})
 }